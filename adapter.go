@@ -2,10 +2,64 @@ package rel
 
 import (
 	"context"
+	"database/sql"
+	"reflect"
+)
+
+// Capabilities stores features supported by an adapter as a flag.
+type Capabilities int
+
+// Is returns true if all of the capabilities is supported.
+func (c Capabilities) Is(capabilities Capabilities) bool {
+	return (c & capabilities) == capabilities
+}
+
+// String representation of the capability.
+func (c Capabilities) String() string {
+	switch c {
+	case TransactionCapability:
+		return "transaction"
+	case SavepointCapability:
+		return "savepoint"
+	case JoinCapability:
+		return "join"
+	case GroupCapability:
+		return "group"
+	case TwoPhaseCommitCapability:
+		return "two-phase commit"
+	case TransactionalDDLCapability:
+		return "transactional ddl"
+	case WindowCapability:
+		return "window function"
+	case OnConflictCapability:
+		return "on conflict"
+	default:
+		return ""
+	}
+}
+
+const (
+	// TransactionCapability adapter supports transaction.
+	TransactionCapability Capabilities = 1 << iota
+	// SavepointCapability adapter supports nested transaction using savepoint.
+	SavepointCapability
+	// JoinCapability adapter supports join query.
+	JoinCapability
+	// GroupCapability adapter supports group query.
+	GroupCapability
+	// TwoPhaseCommitCapability adapter supports preparing transaction for two-phase commit.
+	TwoPhaseCommitCapability
+	// TransactionalDDLCapability adapter is able to apply schema changes inside transaction.
+	TransactionalDDLCapability
+	// WindowCapability adapter supports window function, which is used by partition query.
+	WindowCapability
+	// OnConflictCapability adapter is able to resolve conflict with existing record when inserting records.
+	OnConflictCapability
 )
 
 // Adapter interface
 type Adapter interface {
+	Capabilities() Capabilities
 	Ping(ctx context.Context) error
 	Aggregate(ctx context.Context, query Query, mode string, field string, loggers ...Logger) (int, error)
 	Query(ctx context.Context, query Query, loggers ...Logger) (Cursor, error)
@@ -18,3 +72,124 @@ type Adapter interface {
 	Commit(ctx context.Context) error
 	Rollback(ctx context.Context) error
 }
+
+// StatsAdapter is optional interface implemented by adapter that maintains connection pool.
+type StatsAdapter interface {
+	Stats() sql.DBStats
+}
+
+// TwoPhaseCommitAdapter is optional interface implemented by adapter that supports two-phase commit.
+// Prepare is called on transaction adapter, it prepares current transaction using the given id and ends it.
+// The prepared transaction can be committed or rolled back later by any adapter using the same id.
+type TwoPhaseCommitAdapter interface {
+	Prepare(ctx context.Context, id string) error
+	CommitPrepared(ctx context.Context, id string) error
+	RollbackPrepared(ctx context.Context, id string) error
+}
+
+// AdapterMiddleware decorates an adapter to add cross-cutting behaviour such as metrics, retries or caching.
+// Decorator that overrides Begin should return the adapter returned by inner Begin as is,
+// the transaction adapter will be decorated again using the same middlewares.
+type AdapterMiddleware func(Adapter) Adapter
+
+// AsAdapter finds the first adapter in the chain of decorated adapters that is assignable to the value pointed by target, and sets target to it.
+// The chain is followed using Unwrap method of decorator, which allows optional interface of the underlying adapter to be used through middlewares.
+// It panics if target is not a non-nil pointer.
+//
+// Example:
+//	var adapter migrator.Adapter
+//	if rel.AsAdapter(repo.Adapter(), &adapter) {
+//		adapter.Apply(ctx, migration)
+//	}
+func AsAdapter(adapter Adapter, target interface{}) bool {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		panic("rel: target must be a non-nil pointer")
+	}
+
+	rt := rv.Type().Elem()
+	for adapter != nil {
+		if reflect.TypeOf(adapter).AssignableTo(rt) {
+			rv.Elem().Set(reflect.ValueOf(adapter))
+			return true
+		}
+
+		unwrapper, ok := adapter.(interface{ Unwrap() Adapter })
+		if !ok {
+			return false
+		}
+
+		adapter = unwrapper.Unwrap()
+	}
+
+	return false
+}
+
+type wrappedAdapter struct {
+	Adapter
+	middlewares []AdapterMiddleware
+}
+
+// Begin begins a new transaction and decorates transaction adapter using the same middlewares.
+func (wa wrappedAdapter) Begin(ctx context.Context) (Adapter, error) {
+	adapter, err := wa.Adapter.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return WrapAdapter(adapter, wa.middlewares...), nil
+}
+
+// Stats returns connection pool statistics of the decorated adapter.
+func (wa wrappedAdapter) Stats() sql.DBStats {
+	if sa, ok := wa.Adapter.(StatsAdapter); ok {
+		return sa.Stats()
+	}
+
+	return sql.DBStats{}
+}
+
+// Prepare current transaction of the decorated adapter.
+func (wa wrappedAdapter) Prepare(ctx context.Context, id string) error {
+	if tpc, ok := wa.Adapter.(TwoPhaseCommitAdapter); ok {
+		return tpc.Prepare(ctx, id)
+	}
+
+	return NotSupportedError{Capability: TwoPhaseCommitCapability}
+}
+
+// CommitPrepared commits prepared transaction using the decorated adapter.
+func (wa wrappedAdapter) CommitPrepared(ctx context.Context, id string) error {
+	if tpc, ok := wa.Adapter.(TwoPhaseCommitAdapter); ok {
+		return tpc.CommitPrepared(ctx, id)
+	}
+
+	return NotSupportedError{Capability: TwoPhaseCommitCapability}
+}
+
+// RollbackPrepared rolls back prepared transaction using the decorated adapter.
+func (wa wrappedAdapter) RollbackPrepared(ctx context.Context, id string) error {
+	if tpc, ok := wa.Adapter.(TwoPhaseCommitAdapter); ok {
+		return tpc.RollbackPrepared(ctx, id)
+	}
+
+	return NotSupportedError{Capability: TwoPhaseCommitCapability}
+}
+
+// Unwrap returns the adapter decorated by middlewares.
+func (wa wrappedAdapter) Unwrap() Adapter {
+	return wa.Adapter
+}
+
+// WrapAdapter decorates adapter using middlewares.
+// The first middleware will be the outermost decorator, thus it's executed first.
+func WrapAdapter(adapter Adapter, middlewares ...AdapterMiddleware) Adapter {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		adapter = middlewares[i](adapter)
+	}
+
+	return wrappedAdapter{
+		Adapter:     adapter,
+		middlewares: middlewares,
+	}
+}
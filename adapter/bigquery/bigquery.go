@@ -0,0 +1,114 @@
+// Package bigquery wraps bigquery database/sql driver as a read-only adapter for REL.
+//
+// The driver itself is not imported by this package, import it in the main package instead:
+//	import _ "github.com/viant/bigquery"
+//
+// Only read operations (Find, FindAll, Count, Aggregate and Preload) are supported,
+// any mutation or transaction will returns ReadOnlyError.
+//
+// Usage:
+//	// open bigquery connection.
+//	adapter, err := bigquery.Open("bigquery://my-project/my_dataset")
+//	if err != nil {
+//		panic(err)
+//	}
+//	defer adapter.Close()
+//
+//	// initialize REL's repo.
+//	repo := rel.New(adapter)
+package bigquery
+
+import (
+	"context"
+	db "database/sql"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/adapter/sql"
+)
+
+// ReadOnlyError returned when performing mutation or transaction using bigquery adapter.
+type ReadOnlyError struct {
+	Operation string
+}
+
+// Error message.
+func (roe ReadOnlyError) Error() string {
+	return "bigquery: " + roe.Operation + " is not allowed on read-only adapter"
+}
+
+// Adapter definition for bigquery database.
+type Adapter struct {
+	*sql.Adapter
+}
+
+var _ rel.Adapter = (*Adapter)(nil)
+
+// New bigquery adapter using existing connection.
+func New(database *db.DB) *Adapter {
+	return &Adapter{
+		Adapter: &sql.Adapter{
+			Config: &sql.Config{
+				Placeholder: "?",
+				EscapeChar:  "`",
+				NoSemicolon: true,
+				ErrorFunc:   errorFunc,
+			},
+			DB: database,
+		},
+	}
+}
+
+// Open bigquery connection using dsn.
+func Open(dsn string) (*Adapter, error) {
+	database, err := db.Open("bigquery", dsn)
+	return New(database), err
+}
+
+// Capabilities of the adapter, bigquery adapter doesn't support transaction.
+func (adapter *Adapter) Capabilities() rel.Capabilities {
+	return rel.JoinCapability | rel.GroupCapability
+}
+
+// Insert is not allowed and always returns ReadOnlyError.
+func (adapter *Adapter) Insert(ctx context.Context, query rel.Query, modifies map[string]rel.Modify, loggers ...rel.Logger) (interface{}, error) {
+	return nil, ReadOnlyError{Operation: "insert"}
+}
+
+// InsertAll is not allowed and always returns ReadOnlyError.
+func (adapter *Adapter) InsertAll(ctx context.Context, query rel.Query, fields []string, bulkModifies []map[string]rel.Modify, loggers ...rel.Logger) ([]interface{}, error) {
+	return nil, ReadOnlyError{Operation: "insert"}
+}
+
+// Update is not allowed and always returns ReadOnlyError.
+func (adapter *Adapter) Update(ctx context.Context, query rel.Query, modifies map[string]rel.Modify, loggers ...rel.Logger) (int, error) {
+	return 0, ReadOnlyError{Operation: "update"}
+}
+
+// Delete is not allowed and always returns ReadOnlyError.
+func (adapter *Adapter) Delete(ctx context.Context, query rel.Query, loggers ...rel.Logger) (int, error) {
+	return 0, ReadOnlyError{Operation: "delete"}
+}
+
+// Exec is not allowed and always returns ReadOnlyError.
+func (adapter *Adapter) Exec(ctx context.Context, statement string, args []interface{}, loggers ...rel.Logger) (int64, int64, error) {
+	return 0, 0, ReadOnlyError{Operation: "exec"}
+}
+
+// Begin is not allowed and always returns ReadOnlyError.
+func (adapter *Adapter) Begin(ctx context.Context) (rel.Adapter, error) {
+	return nil, ReadOnlyError{Operation: "transaction"}
+}
+
+// Commit is not allowed and always returns ReadOnlyError.
+func (adapter *Adapter) Commit(ctx context.Context) error {
+	return ReadOnlyError{Operation: "transaction"}
+}
+
+// Rollback is not allowed and always returns ReadOnlyError.
+func (adapter *Adapter) Rollback(ctx context.Context) error {
+	return ReadOnlyError{Operation: "transaction"}
+}
+
+func errorFunc(err error) error {
+	return err
+}
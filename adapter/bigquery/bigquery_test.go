@@ -0,0 +1,62 @@
+package bigquery
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/adapter/sql"
+	"github.com/Fs02/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+var ctx = context.TODO()
+
+func TestNew_config(t *testing.T) {
+	var (
+		adapter = New(nil)
+	)
+
+	qs, args := sql.NewBuilder(adapter.Config).Find(rel.From("events").Where(where.Eq("type", "click")).Limit(10))
+	assert.Equal(t, "SELECT * FROM `events` WHERE `type`=? LIMIT 10", qs)
+	assert.Equal(t, []interface{}{"click"}, args)
+
+	qs, args = sql.NewBuilder(adapter.Config).Aggregate(rel.From("events").Group("type"), "count", "id")
+	assert.Equal(t, "SELECT count(`id`) AS count,`type` FROM `events` GROUP BY `type`", qs)
+	assert.Nil(t, args)
+}
+
+func TestAdapter_readOnly(t *testing.T) {
+	var (
+		adapter  = New(nil)
+		repo     = rel.New(adapter)
+		modifies = map[string]rel.Modify{"type": rel.Set("type", "click")}
+	)
+
+	_, err := adapter.Insert(ctx, rel.From("events"), modifies)
+	assert.Equal(t, ReadOnlyError{Operation: "insert"}, err)
+
+	_, err = adapter.InsertAll(ctx, rel.From("events"), []string{"type"}, []map[string]rel.Modify{modifies})
+	assert.Equal(t, ReadOnlyError{Operation: "insert"}, err)
+
+	_, err = adapter.Update(ctx, rel.From("events"), modifies)
+	assert.Equal(t, ReadOnlyError{Operation: "update"}, err)
+
+	_, err = adapter.Delete(ctx, rel.From("events"))
+	assert.Equal(t, ReadOnlyError{Operation: "delete"}, err)
+
+	_, _, err = adapter.Exec(ctx, "DELETE FROM events", nil)
+	assert.Equal(t, ReadOnlyError{Operation: "exec"}, err)
+
+	_, err = adapter.Begin(ctx)
+	assert.Equal(t, ReadOnlyError{Operation: "transaction"}, err)
+	assert.Equal(t, ReadOnlyError{Operation: "transaction"}, adapter.Commit(ctx))
+	assert.Equal(t, ReadOnlyError{Operation: "transaction"}, adapter.Rollback(ctx))
+
+	err = repo.Transaction(ctx, func(rel.Repository) error { return nil })
+	assert.Equal(t, rel.NotSupportedError{Capability: rel.TransactionCapability}, err)
+}
+
+func TestReadOnlyError(t *testing.T) {
+	assert.Equal(t, "bigquery: insert is not allowed on read-only adapter", ReadOnlyError{Operation: "insert"}.Error())
+}
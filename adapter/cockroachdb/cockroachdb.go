@@ -0,0 +1,159 @@
+// Package cockroachdb wraps postgres (pq) driver as an adapter for CockroachDB.
+//
+// Usage:
+//	// open cockroachdb connection.
+//	adapter, err := cockroachdb.Open("postgres://root@localhost:26257/rel_test?sslmode=disable")
+//	if err != nil {
+//		panic(err)
+//	}
+//	defer adapter.Close()
+//
+//	// initialize REL's repo.
+//	repo := rel.New(adapter)
+//
+//	// run transaction that is retried automatically on serialization error.
+//	err = cockroachdb.Transaction(ctx, repo, func(repo rel.Repository) error {
+//		return repo.Update(ctx, &book)
+//	})
+package cockroachdb
+
+import (
+	"context"
+	db "database/sql"
+	"errors"
+	"time"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/adapter/postgres"
+	"github.com/lib/pq"
+)
+
+const (
+	restartSavepoint = "cockroach_restart"
+)
+
+var (
+	// DefaultMaxRetries is the maximum number of retries used by Transaction when rel.Retry option is not specified.
+	DefaultMaxRetries = 10
+)
+
+// Adapter definition for cockroachdb database.
+type Adapter struct {
+	*postgres.Adapter
+}
+
+var _ rel.Adapter = (*Adapter)(nil)
+
+// New cockroachdb adapter using existing connection.
+func New(database *db.DB) *Adapter {
+	adapter := postgres.New(database)
+	adapter.Config.ErrorFunc = errorFunc
+	// schema changes inside transaction may be committed partially when one of them fails.
+	adapter.Config.Capabilities &^= rel.TwoPhaseCommitCapability | rel.TransactionalDDLCapability
+
+	return &Adapter{
+		Adapter: adapter,
+	}
+}
+
+// Open cockroachdb connection using dsn.
+func Open(dsn string) (*Adapter, error) {
+	database, err := db.Open("postgres", dsn)
+	return New(database), err
+}
+
+// Begin begins a new transaction.
+func (adapter *Adapter) Begin(ctx context.Context) (rel.Adapter, error) {
+	newAdapter, err := adapter.Adapter.Begin(ctx)
+
+	return &Adapter{
+		Adapter: newAdapter.(*postgres.Adapter),
+	}, err
+}
+
+// Transaction performs transaction with given function argument.
+// Whenever cockroachdb reports a retryable error, the function will be executed again
+// inside the same transaction using cockroach_restart savepoint protocol.
+// The function is retried up to DefaultMaxRetries times or the number specified using rel.Retry,
+// and it waits between retries using backoff specified by rel.RetryBackoff.
+func Transaction(ctx context.Context, repo rel.Repository, fn func(rel.Repository) error, opts ...rel.TransactionOption) error {
+	options := rel.TransactionOptions{MaxRetries: DefaultMaxRetries}
+	for i := range opts {
+		opts[i](&options)
+	}
+
+	// retry is performed using savepoint instead of restarting the whole transaction.
+	opts = append(opts[:len(opts):len(opts)], rel.Retry(0))
+
+	return repo.Transaction(ctx, func(repo rel.Repository) error {
+		var adapter *Adapter
+		if !rel.AsAdapter(repo.Adapter(), &adapter) {
+			return errors.New("cockroachdb: repository is not using cockroachdb adapter")
+		}
+
+		if _, _, err := adapter.Exec(ctx, "SAVEPOINT "+restartSavepoint+";", nil); err != nil {
+			return err
+		}
+
+		for attempt := 0; ; attempt++ {
+			err := fn(repo)
+			if err == nil {
+				if _, _, err = adapter.Exec(ctx, "RELEASE SAVEPOINT "+restartSavepoint+";", nil); err == nil {
+					return nil
+				}
+			}
+
+			if !IsRetryable(err) || attempt >= options.MaxRetries {
+				return err
+			}
+
+			if _, _, err := adapter.Exec(ctx, "ROLLBACK TO SAVEPOINT "+restartSavepoint+";", nil); err != nil {
+				return err
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(options.Backoff(attempt)):
+			}
+		}
+	}, opts...)
+}
+
+// IsRetryable returns true if error can be resolved by retrying the transaction.
+func IsRetryable(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "40001"
+}
+
+func errorFunc(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	switch pqErr.Code {
+	case "40001", "40P01":
+		return rel.SerializationError{Err: err}
+	case "23505":
+		return rel.ConstraintError{
+			Key:  pqErr.Constraint,
+			Type: rel.UniqueConstraint,
+			Err:  err,
+		}
+	case "23503":
+		return rel.ConstraintError{
+			Key:  pqErr.Constraint,
+			Type: rel.ForeignKeyConstraint,
+			Err:  err,
+		}
+	case "23514":
+		return rel.ConstraintError{
+			Key:  pqErr.Constraint,
+			Type: rel.CheckConstraint,
+			Err:  err,
+		}
+	default:
+		return err
+	}
+}
@@ -0,0 +1,215 @@
+package cockroachdb
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Fs02/go-paranoid"
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/adapter/specs"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var ctx = context.TODO()
+
+func init() {
+	adapter, err := Open(dsn())
+	paranoid.Panic(err, "failed to open database connection")
+	defer adapter.Close()
+
+	_, _, err = adapter.Exec(ctx, `DROP TABLE IF EXISTS extras;`, nil)
+	paranoid.Panic(err, "failed dropping extras table")
+	_, _, err = adapter.Exec(ctx, `DROP TABLE IF EXISTS addresses;`, nil)
+	paranoid.Panic(err, "failed dropping addresses table")
+	_, _, err = adapter.Exec(ctx, `DROP TABLE IF EXISTS users;`, nil)
+	paranoid.Panic(err, "failed dropping users table")
+
+	_, _, err = adapter.Exec(ctx, `CREATE TABLE users (
+		id SERIAL NOT NULL PRIMARY KEY,
+		slug VARCHAR(30) DEFAULT NULL,
+		name VARCHAR(30) NOT NULL DEFAULT '',
+		gender VARCHAR(10) NOT NULL DEFAULT '',
+		age INT NOT NULL DEFAULT 0,
+		note varchar(50),
+		created_at TIMESTAMPTZ,
+		updated_at TIMESTAMPTZ,
+		UNIQUE(slug)
+	);`, nil)
+	paranoid.Panic(err, "failed creating users table")
+
+	_, _, err = adapter.Exec(ctx, `CREATE TABLE addresses (
+		id SERIAL NOT NULL PRIMARY KEY,
+		user_id INTEGER REFERENCES users(id),
+		name VARCHAR(60) NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ,
+		updated_at TIMESTAMPTZ
+	);`, nil)
+	paranoid.Panic(err, "failed creating addresses table")
+
+	_, _, err = adapter.Exec(ctx, `CREATE TABLE extras (
+		id SERIAL NOT NULL PRIMARY KEY,
+		slug VARCHAR(30) DEFAULT NULL UNIQUE,
+		user_id INTEGER REFERENCES users(id),
+		score INTEGER DEFAULT 0 CHECK (score>=0 AND score<=100)
+	);`, nil)
+	paranoid.Panic(err, "failed creating extras table")
+}
+
+func dsn() string {
+	if os.Getenv("COCKROACHDB_DATABASE") != "" {
+		return os.Getenv("COCKROACHDB_DATABASE") + "?sslmode=disable"
+	}
+
+	return "postgres://root@localhost:9930/rel_test?sslmode=disable"
+}
+
+func TestAdapter_specs(t *testing.T) {
+	adapter, err := Open(dsn())
+	paranoid.Panic(err, "failed to open database connection")
+	defer adapter.Close()
+
+	repo := rel.New(adapter)
+
+	// Query Specs
+	specs.Query(t, repo)
+	specs.QueryJoin(t, repo)
+	specs.QueryNotFound(t, repo)
+
+	// Preload specs
+	specs.PreloadHasMany(t, repo)
+	specs.PreloadHasManyWithQuery(t, repo)
+	specs.PreloadHasManySlice(t, repo)
+	specs.PreloadHasManyWithLimit(t, repo)
+	specs.PreloadEager(t, repo)
+	specs.PreloadHasOne(t, repo)
+	specs.PreloadHasOneWithQuery(t, repo)
+	specs.PreloadHasOneSlice(t, repo)
+	specs.PreloadBelongsTo(t, repo)
+	specs.PreloadBelongsToWithQuery(t, repo)
+	specs.PreloadBelongsToSlice(t, repo)
+
+	// Aggregate Specs
+	specs.Aggregate(t, repo)
+
+	// Insert Specs
+	specs.Insert(t, repo)
+	specs.InsertHasMany(t, repo)
+	specs.InsertHasOne(t, repo)
+	specs.InsertBelongsTo(t, repo)
+	specs.Inserts(t, repo)
+	specs.InsertAll(t, repo)
+
+	// Update Specs
+	specs.Update(t, repo)
+	specs.UpdateNotFound(t, repo)
+	specs.UpdateHasManyInsert(t, repo)
+	specs.UpdateHasManyUpdate(t, repo)
+	specs.UpdateHasManyReplace(t, repo)
+	specs.UpdateHasManyDiff(t, repo)
+	specs.UpdateHasOneInsert(t, repo)
+	specs.UpdateHasOneUpdate(t, repo)
+	specs.UpdateBelongsToInsert(t, repo)
+	specs.UpdateBelongsToUpdate(t, repo)
+	specs.UpdateAtomic(t, repo)
+	specs.Updates(t, repo)
+
+	// Delete specs
+	specs.Delete(t, repo)
+	specs.DeleteAll(t, repo)
+
+	// Constraint specs
+	specs.UniqueConstraint(t, repo)
+	specs.ForeignKeyConstraint(t, repo)
+	specs.CheckConstraint(t, repo)
+}
+
+func TestTransaction(t *testing.T) {
+	adapter, err := Open(dsn())
+	paranoid.Panic(err, "failed to open database connection")
+	defer adapter.Close()
+
+	var (
+		calls = 0
+		repo  = rel.New(adapter)
+	)
+
+	err = Transaction(ctx, repo, func(repo rel.Repository) error {
+		calls++
+		if calls == 1 {
+			_, _, err := repo.Adapter().(*Adapter).Exec(ctx, "SELECT crdb_internal.force_retry('1s':::INTERVAL);", nil)
+			return err
+		}
+
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestTransaction_maxRetries(t *testing.T) {
+	adapter, err := Open(dsn())
+	paranoid.Panic(err, "failed to open database connection")
+	defer adapter.Close()
+
+	var (
+		calls = 0
+		repo  = rel.New(adapter)
+	)
+
+	err = Transaction(ctx, repo, func(repo rel.Repository) error {
+		calls++
+		_, _, err := repo.Adapter().(*Adapter).Exec(ctx, "SELECT crdb_internal.force_retry('1h':::INTERVAL);", nil)
+		return err
+	}, rel.Retry(2), rel.RetryBackoff(time.Millisecond, time.Millisecond))
+
+	assert.True(t, IsRetryable(err))
+	assert.Equal(t, 3, calls)
+}
+
+func TestTransaction_error(t *testing.T) {
+	adapter, err := Open(dsn())
+	paranoid.Panic(err, "failed to open database connection")
+	defer adapter.Close()
+
+	var (
+		repo    = rel.New(adapter)
+		errTest = errors.New("test")
+	)
+
+	assert.Equal(t, errTest, Transaction(ctx, repo, func(repo rel.Repository) error {
+		return errTest
+	}))
+}
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, IsRetryable(&pq.Error{Code: "40001"}))
+	assert.False(t, IsRetryable(&pq.Error{Code: "23505"}))
+	assert.False(t, IsRetryable(errors.New("error")))
+}
+
+func TestErrorFunc(t *testing.T) {
+	var (
+		errUnique     = &pq.Error{Code: "23505", Constraint: "users_slug_key"}
+		errForeignKey = &pq.Error{Code: "23503", Constraint: "fk_user_id_ref_users"}
+		errCheck      = &pq.Error{Code: "23514", Constraint: "check_score"}
+		errRetry      = &pq.Error{Code: "40001", Message: "restart transaction"}
+		errOther      = errors.New("error")
+	)
+
+	assert.Nil(t, errorFunc(nil))
+	assert.Equal(t, rel.ConstraintError{Key: "users_slug_key", Type: rel.UniqueConstraint, Err: errUnique}, errorFunc(errUnique))
+	assert.Equal(t, rel.ConstraintError{Key: "fk_user_id_ref_users", Type: rel.ForeignKeyConstraint, Err: errForeignKey}, errorFunc(errForeignKey))
+	assert.Equal(t, rel.ConstraintError{Key: "check_score", Type: rel.CheckConstraint, Err: errCheck}, errorFunc(errCheck))
+	assert.Equal(t, rel.SerializationError{Err: errRetry}, errorFunc(errRetry))
+	assert.Equal(t, errOther, errorFunc(errOther))
+}
+
+func TestAdapter_Capabilities(t *testing.T) {
+	assert.False(t, New(nil).Capabilities().Is(rel.TwoPhaseCommitCapability))
+	assert.False(t, New(nil).Capabilities().Is(rel.TransactionalDDLCapability))
+}
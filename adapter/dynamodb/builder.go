@@ -0,0 +1,311 @@
+package dynamodb
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/adapter/sql"
+)
+
+// Builder generates PartiQL statement for DynamoDB.
+type Builder struct{}
+
+// Find generates statement for select.
+func (b Builder) Find(query rel.Query) (string, []interface{}, error) {
+	var (
+		buffer sql.Buffer
+	)
+
+	if err := b.validate(query); err != nil {
+		return "", nil, err
+	}
+
+	buffer.WriteString("SELECT ")
+	if len(query.SelectQuery.Fields) == 0 {
+		buffer.WriteByte('*')
+	} else {
+		for i, field := range query.SelectQuery.Fields {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+
+			buffer.WriteString(b.escape(field))
+		}
+	}
+
+	buffer.WriteString(" FROM ")
+	buffer.WriteString(b.escape(query.Table))
+
+	if err := b.where(&buffer, query.WhereQuery); err != nil {
+		return "", nil, err
+	}
+
+	if len(query.SortQuery) > 0 {
+		buffer.WriteString(" ORDER BY ")
+		for i, sort := range query.SortQuery {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+
+			buffer.WriteString(b.escape(sort.Field))
+			if sort.Asc() {
+				buffer.WriteString(" ASC")
+			} else {
+				buffer.WriteString(" DESC")
+			}
+		}
+	}
+
+	return buffer.String(), buffer.Arguments, nil
+}
+
+// Insert generates statement for insert.
+// Insertion will fail if an item with the same primary key already exists.
+func (b Builder) Insert(table string, modifies map[string]rel.Modify) (string, []interface{}, error) {
+	var (
+		buffer sql.Buffer
+		fields = sortedFields(modifies)
+	)
+
+	buffer.WriteString("INSERT INTO ")
+	buffer.WriteString(b.escape(table))
+	buffer.WriteString(" VALUE {")
+
+	for i, field := range fields {
+		mod := modifies[field]
+		if mod.Type != rel.ChangeSetOp {
+			return "", nil, rel.NotSupportedError{Operation: "non set modification on insert"}
+		}
+
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+
+		buffer.WriteByte('\'')
+		buffer.WriteString(field)
+		buffer.WriteString("':?")
+		buffer.Append(mod.Value)
+	}
+
+	buffer.WriteByte('}')
+
+	return buffer.String(), buffer.Arguments, nil
+}
+
+// Update generates statement for update.
+// Filter must contains primary key, other conditions will be used as condition of the write.
+func (b Builder) Update(table string, modifies map[string]rel.Modify, filter rel.FilterQuery) (string, []interface{}, error) {
+	var (
+		buffer sql.Buffer
+	)
+
+	buffer.WriteString("UPDATE ")
+	buffer.WriteString(b.escape(table))
+
+	for _, field := range sortedFields(modifies) {
+		mod := modifies[field]
+		buffer.WriteString(" SET ")
+
+		switch mod.Type {
+		case rel.ChangeSetOp:
+			buffer.WriteString(b.escape(mod.Field))
+			buffer.WriteString("=?")
+			buffer.Append(mod.Value)
+		case rel.ChangeIncOp:
+			buffer.WriteString(b.escape(mod.Field))
+			buffer.WriteByte('=')
+			buffer.WriteString(b.escape(mod.Field))
+			buffer.WriteString("+?")
+			buffer.Append(mod.Value)
+		case rel.ChangeFragmentOp:
+			buffer.WriteString(mod.Field)
+			buffer.Append(mod.Value.([]interface{})...)
+		}
+	}
+
+	if err := b.where(&buffer, filter); err != nil {
+		return "", nil, err
+	}
+
+	return buffer.String(), buffer.Arguments, nil
+}
+
+// Delete generates statement for delete.
+// Filter must contains primary key, other conditions will be used as condition of the write.
+func (b Builder) Delete(table string, filter rel.FilterQuery) (string, []interface{}, error) {
+	var (
+		buffer sql.Buffer
+	)
+
+	buffer.WriteString("DELETE FROM ")
+	buffer.WriteString(b.escape(table))
+
+	if err := b.where(&buffer, filter); err != nil {
+		return "", nil, err
+	}
+
+	return buffer.String(), buffer.Arguments, nil
+}
+
+func (b Builder) validate(query rel.Query) error {
+	switch {
+	case len(query.JoinQuery) > 0:
+		return rel.NotSupportedError{Operation: "join"}
+	case len(query.GroupQuery.Fields) > 0:
+		return rel.NotSupportedError{Operation: "group"}
+	case query.SelectQuery.OnlyDistinct:
+		return rel.NotSupportedError{Operation: "distinct"}
+	case query.LockQuery != "":
+		return rel.NotSupportedError{Operation: "lock"}
+	}
+
+	return nil
+}
+
+func (b Builder) where(buffer *sql.Buffer, filter rel.FilterQuery) error {
+	if filter.None() {
+		return nil
+	}
+
+	buffer.WriteString(" WHERE ")
+	return b.filter(buffer, filter)
+}
+
+func (b Builder) filter(buffer *sql.Buffer, filter rel.FilterQuery) error {
+	switch filter.Type {
+	case rel.FilterAndOp:
+		return b.build(buffer, "AND", filter.Inner)
+	case rel.FilterOrOp:
+		return b.build(buffer, "OR", filter.Inner)
+	case rel.FilterNotOp:
+		buffer.WriteString("NOT ")
+		return b.build(buffer, "AND", filter.Inner)
+	case rel.FilterEqOp:
+		b.comparison(buffer, filter.Field, "=", filter.Value)
+	case rel.FilterNeOp:
+		b.comparison(buffer, filter.Field, "<>", filter.Value)
+	case rel.FilterLtOp:
+		b.comparison(buffer, filter.Field, "<", filter.Value)
+	case rel.FilterLteOp:
+		b.comparison(buffer, filter.Field, "<=", filter.Value)
+	case rel.FilterGtOp:
+		b.comparison(buffer, filter.Field, ">", filter.Value)
+	case rel.FilterGteOp:
+		b.comparison(buffer, filter.Field, ">=", filter.Value)
+	case rel.FilterNilOp:
+		buffer.WriteString(b.escape(filter.Field))
+		buffer.WriteString(" IS MISSING")
+	case rel.FilterNotNilOp:
+		buffer.WriteString(b.escape(filter.Field))
+		buffer.WriteString(" IS NOT MISSING")
+	case rel.FilterInOp, rel.FilterNinOp:
+		b.inclusion(buffer, filter)
+	case rel.FilterLikeOp:
+		var (
+			pattern, _ = filter.Value.(string)
+			prefix     = strings.TrimSuffix(pattern, "%")
+		)
+
+		if strings.ContainsAny(prefix, "%_") {
+			return rel.NotSupportedError{Operation: "like other than prefix matching"}
+		}
+
+		// pattern without wildcard only matches the exact value.
+		if prefix == pattern {
+			b.comparison(buffer, filter.Field, "=", pattern)
+			break
+		}
+
+		buffer.WriteString("begins_with(")
+		buffer.WriteString(b.escape(filter.Field))
+		buffer.WriteString(",?)")
+		buffer.Append(prefix)
+	case rel.FilterNotLikeOp:
+		return rel.NotSupportedError{Operation: "not like"}
+	case rel.FilterFragmentOp:
+		buffer.WriteString(filter.Field)
+		buffer.Append(filter.Value.([]interface{})...)
+	}
+
+	return nil
+}
+
+func (b Builder) build(buffer *sql.Buffer, op string, inner []rel.FilterQuery) error {
+	var (
+		length = len(inner)
+	)
+
+	if length > 1 {
+		buffer.WriteByte('(')
+	}
+
+	for i, c := range inner {
+		if err := b.filter(buffer, c); err != nil {
+			return err
+		}
+
+		if i < length-1 {
+			buffer.WriteByte(' ')
+			buffer.WriteString(op)
+			buffer.WriteByte(' ')
+		}
+	}
+
+	if length > 1 {
+		buffer.WriteByte(')')
+	}
+
+	return nil
+}
+
+func (b Builder) comparison(buffer *sql.Buffer, field string, op string, value interface{}) {
+	buffer.WriteString(b.escape(field))
+	buffer.WriteString(op)
+	buffer.WriteByte('?')
+	buffer.Append(value)
+}
+
+func (b Builder) inclusion(buffer *sql.Buffer, filter rel.FilterQuery) {
+	var (
+		values = filter.Value.([]interface{})
+	)
+
+	if filter.Type == rel.FilterNinOp {
+		buffer.WriteString("NOT ")
+	}
+
+	buffer.WriteString(b.escape(filter.Field))
+	buffer.WriteString(" IN [")
+
+	for i := range values {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+
+		buffer.WriteByte('?')
+	}
+
+	buffer.WriteByte(']')
+	buffer.Append(values...)
+}
+
+func (b Builder) escape(field string) string {
+	if len(field) > 0 && field[0] == sql.UnescapeCharacter {
+		return field[1:]
+	}
+
+	return "\"" + field + "\""
+}
+
+// sortedFields returns fields of modifies in sorted order, so the generated statement is deterministic.
+func sortedFields(modifies map[string]rel.Modify) []string {
+	fields := make([]string, 0, len(modifies))
+	for field := range modifies {
+		fields = append(fields, field)
+	}
+
+	sort.Strings(fields)
+
+	return fields
+}
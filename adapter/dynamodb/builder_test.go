@@ -0,0 +1,179 @@
+package dynamodb
+
+import (
+	"testing"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder_Find(t *testing.T) {
+	var (
+		query = rel.From("users")
+	)
+
+	tests := []struct {
+		Statement string
+		Args      []interface{}
+		Query     rel.Query
+	}{
+		{
+			`SELECT * FROM "users"`,
+			nil,
+			query,
+		},
+		{
+			`SELECT "id","name" FROM "users"`,
+			nil,
+			query.Select("id", "name"),
+		},
+		{
+			`SELECT * FROM "users" WHERE "id"=?`,
+			[]interface{}{1},
+			query.Where(where.Eq("id", 1)),
+		},
+		{
+			`SELECT * FROM "users" WHERE ("tenant"=? AND "age">? AND "age"<=?)`,
+			[]interface{}{"a", 10, 20},
+			query.Where(where.Eq("tenant", "a"), where.Gt("age", 10), where.Lte("age", 20)),
+		},
+		{
+			`SELECT * FROM "users" WHERE ("id"=? OR "id"<>?)`,
+			[]interface{}{1, 2},
+			query.Where(where.Eq("id", 1).OrNe("id", 2)),
+		},
+		{
+			`SELECT * FROM "users" WHERE ("id" IN [?,?] AND NOT "status" IN [?])`,
+			[]interface{}{1, 2, "banned"},
+			query.Where(where.In("id", 1, 2), where.Nin("status", "banned")),
+		},
+		{
+			`SELECT * FROM "users" WHERE ("deleted_at" IS MISSING AND "name" IS NOT MISSING)`,
+			nil,
+			query.Where(where.Nil("deleted_at"), where.NotNil("name")),
+		},
+		{
+			`SELECT * FROM "users" WHERE begins_with("name",?)`,
+			[]interface{}{"foo"},
+			query.Where(where.Like("name", "foo%")),
+		},
+		{
+			`SELECT * FROM "users" WHERE "name"=?`,
+			[]interface{}{"foo"},
+			query.Where(where.Like("name", "foo")),
+		},
+		{
+			`SELECT * FROM "users" WHERE contains("tags",?)`,
+			[]interface{}{"go"},
+			query.Where(where.Fragment(`contains("tags",?)`, "go")),
+		},
+		{
+			`SELECT * FROM "users" WHERE "id"=? ORDER BY "created_at" ASC,"name" DESC`,
+			[]interface{}{1},
+			query.Where(where.Eq("id", 1)).SortAsc("created_at").SortDesc("name"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Statement, func(t *testing.T) {
+			statement, args, err := Builder{}.Find(test.Query)
+			assert.Nil(t, err)
+			assert.Equal(t, test.Statement, statement)
+			assert.Equal(t, test.Args, args)
+		})
+	}
+}
+
+func TestBuilder_Find_notSupported(t *testing.T) {
+	var (
+		query = rel.From("users")
+	)
+
+	tests := []struct {
+		Err   error
+		Query rel.Query
+	}{
+		{
+			rel.NotSupportedError{Operation: "join"},
+			query.Join("transactions"),
+		},
+		{
+			rel.NotSupportedError{Operation: "group"},
+			query.Group("gender"),
+		},
+		{
+			rel.NotSupportedError{Operation: "distinct"},
+			query.Distinct(),
+		},
+		{
+			rel.NotSupportedError{Operation: "lock"},
+			query.Lock(rel.ForUpdate()),
+		},
+		{
+			rel.NotSupportedError{Operation: "like other than prefix matching"},
+			query.Where(where.Like("name", "%foo%")),
+		},
+		{
+			rel.NotSupportedError{Operation: "not like"},
+			query.Where(where.NotLike("name", "foo%")),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Err.Error(), func(t *testing.T) {
+			_, _, err := Builder{}.Find(test.Query)
+			assert.Equal(t, test.Err, err)
+		})
+	}
+}
+
+func TestBuilder_Insert(t *testing.T) {
+	var (
+		modifies = map[string]rel.Modify{
+			"id":   rel.Set("id", 1),
+			"name": rel.Set("name", "foo"),
+			"age":  rel.Set("age", 10),
+		}
+		statement, args, err = Builder{}.Insert("users", modifies)
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, `INSERT INTO "users" VALUE {'age':?,'id':?,'name':?}`, statement)
+	assert.Equal(t, []interface{}{10, 1, "foo"}, args)
+}
+
+func TestBuilder_Insert_notSupported(t *testing.T) {
+	var (
+		modifies = map[string]rel.Modify{
+			"id":    rel.Set("id", 1),
+			"count": rel.Inc("count"),
+		}
+		_, _, err = Builder{}.Insert("users", modifies)
+	)
+
+	assert.Equal(t, rel.NotSupportedError{Operation: "non set modification on insert"}, err)
+}
+
+func TestBuilder_Update(t *testing.T) {
+	var (
+		modifies = map[string]rel.Modify{
+			"count": rel.Inc("count"),
+		}
+		statement, args, err = Builder{}.Update("users", modifies, where.Eq("id", 1).AndEq("version", 2))
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, `UPDATE "users" SET "count"="count"+? WHERE ("id"=? AND "version"=?)`, statement)
+	assert.Equal(t, []interface{}{1, 1, 2}, args)
+}
+
+func TestBuilder_Delete(t *testing.T) {
+	var (
+		statement, args, err = Builder{}.Delete("users", where.Eq("id", 1))
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, `DELETE FROM "users" WHERE "id"=?`, statement)
+	assert.Equal(t, []interface{}{1}, args)
+}
@@ -0,0 +1,262 @@
+// Package dynamodb wraps DynamoDB PartiQL database/sql driver (godynamo) as an adapter for REL.
+//
+// Every statement is generated as PartiQL, DynamoDB will then decide whether to
+// use GetItem, Query or Scan based on the key conditions of the where clause.
+// Operation that can't be expressed in DynamoDB such as join and group will
+// return rel.NotSupportedError.
+//
+// Usage:
+//	// open dynamodb connection.
+//	adapter, err := dynamodb.Open("Region=us-east-1;AkId=xxx;SecretKey=xxx")
+//	if err != nil {
+//		panic(err)
+//	}
+//	defer adapter.Close()
+//
+//	// initialize REL's repo.
+//	repo := rel.New(adapter)
+package dynamodb
+
+import (
+	"context"
+	db "database/sql"
+	"strings"
+	"time"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/adapter/sql"
+)
+
+// Adapter definition for dynamodb database.
+type Adapter struct {
+	DB *db.DB
+	// PrimaryField is the partition key used to returns the id of inserted item, default to id.
+	PrimaryField string
+}
+
+var _ rel.Adapter = (*Adapter)(nil)
+
+// New dynamodb adapter using existing connection.
+func New(database *db.DB) *Adapter {
+	return &Adapter{
+		DB:           database,
+		PrimaryField: "id",
+	}
+}
+
+// Open dynamodb connection using dsn.
+func Open(dsn string) (*Adapter, error) {
+	database, err := db.Open("godynamo", dsn)
+	return New(database), err
+}
+
+// Close database connection.
+func (adapter *Adapter) Close() error {
+	return adapter.DB.Close()
+}
+
+// Capabilities of the adapter, dynamodb doesn't support transaction, join nor group.
+func (adapter *Adapter) Capabilities() rel.Capabilities {
+	return 0
+}
+
+// Ping database.
+func (adapter *Adapter) Ping(ctx context.Context) error {
+	return adapter.DB.PingContext(ctx)
+}
+
+// Aggregate record using given query.
+// Only count is supported, and it's calculated by reading all matching items.
+func (adapter *Adapter) Aggregate(ctx context.Context, query rel.Query, mode string, field string, loggers ...rel.Logger) (int, error) {
+	if mode != "count" {
+		return 0, rel.NotSupportedError{Operation: "aggregate " + mode}
+	}
+
+	query.SelectQuery = rel.SelectQuery{}
+	cur, err := adapter.Query(ctx, query, loggers...)
+	if err != nil {
+		return 0, err
+	}
+
+	defer cur.Close()
+
+	var (
+		count = 0
+	)
+
+	for cur.Next() {
+		count++
+	}
+
+	if err := cur.(*Cursor).Err(); err != nil {
+		return 0, errorFunc(err)
+	}
+
+	return count, nil
+}
+
+// Query performs query operation.
+// Limit and offset is applied while reading the result.
+func (adapter *Adapter) Query(ctx context.Context, query rel.Query, loggers ...rel.Logger) (rel.Cursor, error) {
+	statement, args, err := Builder{}.Find(query)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	rows, err := adapter.DB.QueryContext(ctx, statement, args...)
+	rel.LogFunc(ctx, loggers, func() string { return statement }, time.Since(start), err)
+
+	if err != nil {
+		return nil, errorFunc(err)
+	}
+
+	return &Cursor{
+		Cursor: sql.Cursor{Rows: rows},
+		limit:  int(query.LimitQuery),
+		offset: int(query.OffsetQuery),
+	}, nil
+}
+
+// Exec performs exec operation.
+func (adapter *Adapter) Exec(ctx context.Context, statement string, args []interface{}, loggers ...rel.Logger) (int64, error) {
+	start := time.Now()
+	res, err := adapter.DB.ExecContext(ctx, statement, args...)
+	rel.LogFunc(ctx, loggers, func() string { return statement }, time.Since(start), err)
+
+	if err != nil {
+		return 0, errorFunc(err)
+	}
+
+	rowCount, _ := res.RowsAffected()
+
+	return rowCount, nil
+}
+
+// Insert inserts an item to database and returns its primary key.
+// Primary key must be provided by the application.
+func (adapter *Adapter) Insert(ctx context.Context, query rel.Query, modifies map[string]rel.Modify, loggers ...rel.Logger) (interface{}, error) {
+	mod, ok := modifies[adapter.PrimaryField]
+	if !ok || mod.Value == nil {
+		return nil, rel.NotSupportedError{Operation: "insert without " + adapter.PrimaryField}
+	}
+
+	statement, args, err := Builder{}.Insert(query.Table, modifies)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = adapter.Exec(ctx, statement, args, loggers...)
+
+	return mod.Value, err
+}
+
+// InsertAll inserts all items to database one by one and returns its primary keys.
+func (adapter *Adapter) InsertAll(ctx context.Context, query rel.Query, fields []string, bulkModifies []map[string]rel.Modify, loggers ...rel.Logger) ([]interface{}, error) {
+	var (
+		ids = make([]interface{}, len(bulkModifies))
+	)
+
+	for i := range bulkModifies {
+		id, err := adapter.Insert(ctx, query, bulkModifies[i], loggers...)
+		if err != nil {
+			return nil, err
+		}
+
+		ids[i] = id
+	}
+
+	return ids, nil
+}
+
+// Update updates an item in database.
+// Update that doesn't satisfy the conditions will be reported as zero updated item.
+func (adapter *Adapter) Update(ctx context.Context, query rel.Query, modifies map[string]rel.Modify, loggers ...rel.Logger) (int, error) {
+	statement, args, err := Builder{}.Update(query.Table, modifies, query.WhereQuery)
+	if err != nil {
+		return 0, err
+	}
+
+	updatedCount, err := adapter.Exec(ctx, statement, args, loggers...)
+	if isConditionalCheckFailed(err) {
+		return 0, nil
+	}
+
+	return int(updatedCount), err
+}
+
+// Delete deletes an item that match the query.
+// Delete that doesn't satisfy the conditions will be reported as zero deleted item.
+func (adapter *Adapter) Delete(ctx context.Context, query rel.Query, loggers ...rel.Logger) (int, error) {
+	statement, args, err := Builder{}.Delete(query.Table, query.WhereQuery)
+	if err != nil {
+		return 0, err
+	}
+
+	deletedCount, err := adapter.Exec(ctx, statement, args, loggers...)
+	if isConditionalCheckFailed(err) {
+		return 0, nil
+	}
+
+	return int(deletedCount), err
+}
+
+// Begin is not supported.
+func (adapter *Adapter) Begin(ctx context.Context) (rel.Adapter, error) {
+	return nil, rel.NotSupportedError{Operation: "transaction"}
+}
+
+// Commit is not supported.
+func (adapter *Adapter) Commit(ctx context.Context) error {
+	return rel.NotSupportedError{Operation: "transaction"}
+}
+
+// Rollback is not supported.
+func (adapter *Adapter) Rollback(ctx context.Context) error {
+	return rel.NotSupportedError{Operation: "transaction"}
+}
+
+// Cursor used for retrieving result.
+type Cursor struct {
+	sql.Cursor
+	limit  int
+	offset int
+	count  int
+}
+
+// Next prepares the next result, it skips items until offset is reached and stops once limit is reached.
+func (c *Cursor) Next() bool {
+	for c.offset > 0 {
+		if !c.Cursor.Next() {
+			return false
+		}
+
+		c.offset--
+	}
+
+	if c.limit > 0 && c.count >= c.limit {
+		return false
+	}
+
+	c.count++
+	return c.Cursor.Next()
+}
+
+func isConditionalCheckFailed(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "ConditionalCheckFailed")
+}
+
+func errorFunc(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if strings.Contains(err.Error(), "DuplicateItem") {
+		return rel.ConstraintError{
+			Type: rel.PrimaryKeyConstraint,
+			Err:  err,
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,64 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Fs02/rel"
+	"github.com/stretchr/testify/assert"
+)
+
+var ctx = context.TODO()
+
+func TestAdapter_Aggregate_notSupported(t *testing.T) {
+	var (
+		adapter = &Adapter{PrimaryField: "id"}
+	)
+
+	_, err := adapter.Aggregate(ctx, rel.From("users"), "sum", "age")
+	assert.Equal(t, rel.NotSupportedError{Operation: "aggregate sum"}, err)
+}
+
+func TestAdapter_Query_notSupported(t *testing.T) {
+	var (
+		adapter = &Adapter{PrimaryField: "id"}
+	)
+
+	_, err := adapter.Query(ctx, rel.From("users").Join("addresses"))
+	assert.Equal(t, rel.NotSupportedError{Operation: "join"}, err)
+}
+
+func TestAdapter_Insert_withoutPrimaryKey(t *testing.T) {
+	var (
+		adapter  = &Adapter{PrimaryField: "id"}
+		modifies = map[string]rel.Modify{
+			"name": rel.Set("name", "foo"),
+		}
+	)
+
+	_, err := adapter.Insert(ctx, rel.From("users"), modifies)
+	assert.Equal(t, rel.NotSupportedError{Operation: "insert without id"}, err)
+}
+
+func TestAdapter_transaction(t *testing.T) {
+	var (
+		adapter = &Adapter{PrimaryField: "id"}
+	)
+
+	_, err := adapter.Begin(ctx)
+	assert.Equal(t, rel.NotSupportedError{Operation: "transaction"}, err)
+	assert.Equal(t, rel.NotSupportedError{Operation: "transaction"}, adapter.Commit(ctx))
+	assert.Equal(t, rel.NotSupportedError{Operation: "transaction"}, adapter.Rollback(ctx))
+}
+
+func TestErrorFunc(t *testing.T) {
+	var (
+		errDuplicate = errors.New("DuplicateItemException: Duplicate primary key exists in table")
+		errOther     = errors.New("ValidationException")
+	)
+
+	assert.Nil(t, errorFunc(nil))
+	assert.Equal(t, rel.ConstraintError{Type: rel.PrimaryKeyConstraint, Err: errDuplicate}, errorFunc(errDuplicate))
+	assert.Equal(t, errOther, errorFunc(errOther))
+}
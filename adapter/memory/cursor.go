@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"strconv"
+
+	"github.com/Fs02/rel"
+)
+
+type cursor struct {
+	fields []string
+	rows   []row
+	index  int
+}
+
+var _ rel.Cursor = (*cursor)(nil)
+
+func newCursor(fields []string, rows []row) *cursor {
+	return &cursor{
+		fields: fields,
+		rows:   rows,
+		index:  -1,
+	}
+}
+
+// Close cursor.
+func (c *cursor) Close() error {
+	return nil
+}
+
+// Fields returned in the result.
+func (c *cursor) Fields() ([]string, error) {
+	return c.fields, nil
+}
+
+// Next prepares the next row to be scanned.
+func (c *cursor) Next() bool {
+	c.index++
+	return c.index < len(c.rows)
+}
+
+// Scan current row into dest.
+func (c *cursor) Scan(dest ...interface{}) error {
+	if c.index < 0 || c.index >= len(c.rows) {
+		return errors.New("memory: Scan called without calling Next")
+	}
+
+	if len(dest) != len(c.fields) {
+		return errors.New("memory: expected " + strconv.Itoa(len(c.fields)) + " destination arguments in Scan")
+	}
+
+	for i, field := range c.fields {
+		if err := assign(dest[i], c.rows[c.index][field]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NopScanner for this adapter.
+func (c *cursor) NopScanner() interface{} {
+	return &sql.RawBytes{}
+}
+
+func assign(dest interface{}, value interface{}) error {
+	switch d := dest.(type) {
+	case *sql.RawBytes:
+		return nil
+	case sql.Scanner:
+		return d.Scan(value)
+	}
+
+	var (
+		rv = reflect.ValueOf(dest)
+	)
+
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("memory: destination must be a non nil pointer")
+	}
+
+	rv = rv.Elem()
+	if value == nil {
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	}
+
+	if rv.Kind() != reflect.Ptr {
+		return rel.Nullable(dest).(sql.Scanner).Scan(value)
+	}
+
+	ptr := reflect.New(rv.Type().Elem())
+	if err := rel.Nullable(ptr.Interface()).(sql.Scanner).Scan(value); err != nil {
+		return err
+	}
+
+	rv.Set(ptr)
+	return nil
+}
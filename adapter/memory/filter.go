@@ -0,0 +1,257 @@
+package memory
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Fs02/rel"
+)
+
+func match(r row, filter rel.FilterQuery) (bool, error) {
+	switch filter.Type {
+	case rel.FilterAndOp:
+		return matchAll(r, filter.Inner)
+	case rel.FilterOrOp:
+		for _, inner := range filter.Inner {
+			if ok, err := match(r, inner); ok || err != nil {
+				return ok, err
+			}
+		}
+
+		return len(filter.Inner) == 0, nil
+	case rel.FilterNotOp:
+		ok, err := matchAll(r, filter.Inner)
+		return !ok, err
+	case rel.FilterEqOp, rel.FilterNeOp, rel.FilterLtOp, rel.FilterLteOp, rel.FilterGtOp, rel.FilterGteOp:
+		return matchComparison(r, filter)
+	case rel.FilterNilOp:
+		return r[unqualify(filter.Field)] == nil, nil
+	case rel.FilterNotNilOp:
+		return r[unqualify(filter.Field)] != nil, nil
+	case rel.FilterInOp, rel.FilterNinOp:
+		return matchInclusion(r, filter)
+	case rel.FilterLikeOp, rel.FilterNotLikeOp:
+		return matchLike(r, filter)
+	default:
+		return false, rel.NotSupportedError{Operation: "fragment filter"}
+	}
+}
+
+func matchAll(r row, filters []rel.FilterQuery) (bool, error) {
+	for _, inner := range filters {
+		if ok, err := match(r, inner); !ok || err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func matchComparison(r row, filter rel.FilterQuery) (bool, error) {
+	value, err := normalize(filter.Value)
+	if err != nil {
+		return false, err
+	}
+
+	c, ok := compare(r[unqualify(filter.Field)], value)
+	if !ok {
+		return false, nil
+	}
+
+	switch filter.Type {
+	case rel.FilterEqOp:
+		return c == 0, nil
+	case rel.FilterNeOp:
+		return c != 0, nil
+	case rel.FilterLtOp:
+		return c < 0, nil
+	case rel.FilterLteOp:
+		return c <= 0, nil
+	case rel.FilterGtOp:
+		return c > 0, nil
+	default:
+		return c >= 0, nil
+	}
+}
+
+func matchInclusion(r row, filter rel.FilterQuery) (bool, error) {
+	var (
+		field     = r[unqualify(filter.Field)]
+		values, _ = filter.Value.([]interface{})
+		include   = filter.Type == rel.FilterInOp
+	)
+
+	if field == nil {
+		return false, nil
+	}
+
+	for _, v := range values {
+		value, err := normalize(v)
+		if err != nil {
+			return false, err
+		}
+
+		if c, ok := compare(field, value); ok && c == 0 {
+			return include, nil
+		}
+	}
+
+	return !include, nil
+}
+
+func matchLike(r row, filter rel.FilterQuery) (bool, error) {
+	var (
+		field, ok  = r[unqualify(filter.Field)].(string)
+		pattern, _ = filter.Value.(string)
+	)
+
+	if !ok {
+		return false, nil
+	}
+
+	re, err := likeRegexp(pattern)
+	if err != nil {
+		return false, err
+	}
+
+	return re.MatchString(field) == (filter.Type == rel.FilterLikeOp), nil
+}
+
+func likeRegexp(pattern string) (*regexp.Regexp, error) {
+	var (
+		expr strings.Builder
+	)
+
+	expr.WriteString("^")
+	for _, c := range pattern {
+		switch c {
+		case '%':
+			expr.WriteString(".*")
+		case '_':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	expr.WriteString("$")
+
+	return regexp.Compile(expr.String())
+}
+
+// compare two normalized values, returns false if values are not comparable.
+func compare(a, b interface{}) (int, bool) {
+	switch av := a.(type) {
+	case int64:
+		switch bv := b.(type) {
+		case int64:
+			return compareInt(av, bv), true
+		case float64:
+			return compareFloat(float64(av), bv), true
+		}
+	case float64:
+		switch bv := b.(type) {
+		case int64:
+			return compareFloat(av, float64(bv)), true
+		case float64:
+			return compareFloat(av, bv), true
+		}
+	case string:
+		if bv, ok := b.(string); ok {
+			return strings.Compare(av, bv), true
+		}
+	case []byte:
+		if bv, ok := b.([]byte); ok {
+			return bytes.Compare(av, bv), true
+		}
+	case bool:
+		if bv, ok := b.(bool); ok {
+			return compareBool(av, bv), true
+		}
+	case time.Time:
+		if bv, ok := b.(time.Time); ok {
+			switch {
+			case av.Before(bv):
+				return -1, true
+			case av.After(bv):
+				return 1, true
+			default:
+				return 0, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// order two normalized values for sorting, nil is always placed first.
+func order(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	c, _ := compare(a, b)
+	return c
+}
+
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case b:
+		return -1
+	default:
+		return 1
+	}
+}
+
+func add(a, b interface{}) (interface{}, error) {
+	switch av := a.(type) {
+	case nil:
+		return b, nil
+	case int64:
+		switch bv := b.(type) {
+		case int64:
+			return av + bv, nil
+		case float64:
+			return float64(av) + bv, nil
+		}
+	case float64:
+		switch bv := b.(type) {
+		case int64:
+			return av + float64(bv), nil
+		case float64:
+			return av + bv, nil
+		}
+	}
+
+	return nil, rel.NotSupportedError{Operation: "non numeric increment"}
+}
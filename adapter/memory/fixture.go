@@ -0,0 +1,156 @@
+package memory
+
+import (
+	"encoding/csv"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Fs02/rel"
+	"gopkg.in/yaml.v2"
+)
+
+// Load rows into table, rows without id will be assigned an auto increment id.
+func (adapter *Adapter) Load(table string, rows ...map[string]interface{}) error {
+	adapter.mutex.Lock()
+	defer adapter.mutex.Unlock()
+
+	var (
+		t = adapter.table(table)
+	)
+
+	for _, r := range rows {
+		var (
+			modifies = make(map[string]rel.Modify, len(r))
+		)
+
+		for field, value := range r {
+			modifies[field] = rel.Set(field, value)
+		}
+
+		if _, err := t.insert(modifies); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LoadCSV loads rows from csv into table.
+// The first line is used as header, and each value will be parsed as int, float, bool or RFC3339 time when possible.
+// Empty value will be loaded as nil.
+func (adapter *Adapter) LoadCSV(table string, r io.Reader) error {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil || len(records) == 0 {
+		return err
+	}
+
+	var (
+		header = records[0]
+		rows   = make([]map[string]interface{}, len(records)-1)
+	)
+
+	for i, record := range records[1:] {
+		rows[i] = make(map[string]interface{}, len(header))
+		for j, field := range header {
+			rows[i][field] = parseValue(record[j])
+		}
+	}
+
+	return adapter.Load(table, rows...)
+}
+
+// LoadYAML loads rows from yaml document that maps table name to list of rows.
+//	users:
+//	  - id: 1
+//	    name: John Doe
+//	  - name: Jane Doe
+func (adapter *Adapter) LoadYAML(r io.Reader) error {
+	var (
+		tables yaml.MapSlice
+	)
+
+	if err := yaml.NewDecoder(r).Decode(&tables); err != nil && err != io.EOF {
+		return err
+	}
+
+	for _, item := range tables {
+		var (
+			table, _   = item.Key.(string)
+			records, _ = item.Value.([]interface{})
+			rows       = make([]map[string]interface{}, len(records))
+		)
+
+		for i, record := range records {
+			fields, _ := record.(yaml.MapSlice)
+
+			rows[i] = make(map[string]interface{}, len(fields))
+			for _, field := range fields {
+				name, _ := field.Key.(string)
+				rows[i][name] = field.Value
+			}
+		}
+
+		if err := adapter.Load(table, rows...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LoadFiles loads fixtures from csv or yaml files.
+// Table name of csv file is taken from the file name without extension, eg: users.csv will be loaded into users table.
+func (adapter *Adapter) LoadFiles(filenames ...string) error {
+	for _, filename := range filenames {
+		if err := adapter.loadFile(filename); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (adapter *Adapter) loadFile(filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	switch ext := filepath.Ext(filename); ext {
+	case ".csv":
+		return adapter.LoadCSV(strings.TrimSuffix(filepath.Base(filename), ext), file)
+	case ".yml", ".yaml":
+		return adapter.LoadYAML(file)
+	default:
+		return rel.NotSupportedError{Operation: "loading " + ext + " fixture"}
+	}
+}
+
+func parseValue(str string) interface{} {
+	if str == "" {
+		return nil
+	}
+
+	if i, err := strconv.ParseInt(str, 10, 64); err == nil {
+		return i
+	}
+
+	if f, err := strconv.ParseFloat(str, 64); err == nil {
+		return f
+	}
+
+	if b, err := strconv.ParseBool(str); err == nil {
+		return b
+	}
+
+	if t, err := time.Parse(time.RFC3339, str); err == nil {
+		return t
+	}
+
+	return str
+}
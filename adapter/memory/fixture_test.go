@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/adapter/specs"
+	"github.com/Fs02/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdapter_Load(t *testing.T) {
+	var (
+		adapter = New()
+		repo    = rel.New(adapter)
+		user    specs.User
+	)
+
+	assert.Nil(t, adapter.Load("users", map[string]interface{}{"id": 5, "name": "John Doe"}))
+	assert.Nil(t, repo.Find(ctx, &user, where.Eq("id", 5)))
+	assert.Equal(t, "John Doe", user.Name)
+
+	assert.Equal(t, rel.ConstraintError{Key: "id", Type: rel.PrimaryKeyConstraint}, adapter.Load("users", map[string]interface{}{"id": 5}))
+}
+
+func TestAdapter_LoadFiles_csv(t *testing.T) {
+	var (
+		adapter = New()
+		repo    = rel.New(adapter)
+		users   []specs.User
+	)
+
+	assert.Nil(t, adapter.LoadFiles("testdata/users.csv"))
+	assert.Nil(t, repo.FindAll(ctx, &users, rel.Select().SortAsc("id")))
+	assert.Len(t, users, 2)
+	assert.Equal(t, int64(1), users[0].ID)
+	assert.Equal(t, "John Doe", users[0].Name)
+	assert.Equal(t, 20, users[0].Age)
+	assert.Nil(t, users[0].Note)
+	assert.True(t, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).Equal(users[0].CreatedAt))
+	assert.Equal(t, "vip", *users[1].Note)
+
+	user := specs.User{Name: "New"}
+	repo.MustInsert(ctx, &user)
+	assert.Equal(t, int64(3), user.ID)
+}
+
+func TestAdapter_LoadFiles_yaml(t *testing.T) {
+	var (
+		adapter = New()
+		repo    = rel.New(adapter)
+		user    specs.User
+	)
+
+	assert.Nil(t, adapter.LoadFiles("testdata/fixtures.yml"))
+	assert.Nil(t, repo.Find(ctx, &user, where.Eq("id", 2)))
+	assert.Equal(t, "Jane Doe", user.Name)
+	assert.Equal(t, "vip", *user.Note)
+
+	repo.MustPreload(ctx, &user, "addresses")
+	assert.Len(t, user.Addresses, 0)
+
+	repo.MustFind(ctx, &user, where.Eq("id", 1))
+	repo.MustPreload(ctx, &user, "addresses")
+	assert.Len(t, user.Addresses, 1)
+	assert.Equal(t, "Home", user.Addresses[0].Name)
+}
+
+func TestAdapter_LoadFiles_error(t *testing.T) {
+	var (
+		adapter = New()
+	)
+
+	assert.NotNil(t, adapter.LoadFiles("testdata/unknown.csv"))
+	assert.Equal(t, rel.NotSupportedError{Operation: "loading .go fixture"}, adapter.LoadFiles("fixture.go"))
+	assert.NotNil(t, adapter.LoadCSV("users", strings.NewReader("id,name\n1")))
+	assert.NotNil(t, adapter.LoadYAML(strings.NewReader("users: [")))
+}
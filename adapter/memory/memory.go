@@ -0,0 +1,227 @@
+// Package memory implements an in-memory adapter for REL.
+//
+// This adapter stores records as rows in memory, and evaluates filters, sorts, limits and aggregations the same way as sql database would.
+// It's intended to be used for functional tests where repository behaviour need to be exercised without a real database.
+//
+// Every insert without id will be assigned an auto increment id, and transaction is implemented using snapshot of tables
+// that replaces the parent tables on commit.
+//
+// Usage:
+//	// initialize in-memory adapter.
+//	adapter := memory.New()
+//
+//	// initialize REL's repo.
+//	repo := rel.New(adapter)
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/Fs02/rel"
+)
+
+const (
+	primaryField = "id"
+)
+
+// Adapter definition for in-memory database.
+type Adapter struct {
+	mutex  *sync.RWMutex
+	tables map[string]*table
+	parent *Adapter
+}
+
+var _ rel.Adapter = (*Adapter)(nil)
+
+// New in-memory adapter.
+func New() *Adapter {
+	return &Adapter{
+		mutex:  &sync.RWMutex{},
+		tables: make(map[string]*table),
+	}
+}
+
+// Capabilities of the adapter, in-memory adapter supports transaction and nested transaction.
+func (adapter *Adapter) Capabilities() rel.Capabilities {
+	return rel.TransactionCapability | rel.SavepointCapability
+}
+
+// Ping always returns nil.
+func (adapter *Adapter) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Aggregate over rows that matches the query.
+func (adapter *Adapter) Aggregate(ctx context.Context, query rel.Query, mode string, field string, loggers ...rel.Logger) (int, error) {
+	if err := validate(query); err != nil {
+		return 0, err
+	}
+
+	adapter.mutex.RLock()
+	defer adapter.mutex.RUnlock()
+
+	rows, err := adapter.tables[query.Table].filter(query.WhereQuery)
+	if err != nil {
+		return 0, err
+	}
+
+	return aggregate(rows, mode, field)
+}
+
+// Query rows that matches the query.
+func (adapter *Adapter) Query(ctx context.Context, query rel.Query, loggers ...rel.Logger) (rel.Cursor, error) {
+	if err := validate(query); err != nil {
+		return nil, err
+	}
+
+	adapter.mutex.RLock()
+	defer adapter.mutex.RUnlock()
+
+	var (
+		table     = adapter.tables[query.Table]
+		rows, err = table.filter(query.WhereQuery)
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		fields = table.fields(query.SelectQuery.Fields)
+	)
+
+	sortRows(rows, query.SortQuery)
+	rows = project(rows, fields, query.SelectQuery.OnlyDistinct)
+	rows = limitOffset(rows, query.LimitQuery, query.OffsetQuery)
+
+	return newCursor(fields, rows), nil
+}
+
+// Insert a row and returns its id.
+func (adapter *Adapter) Insert(ctx context.Context, query rel.Query, modifies map[string]rel.Modify, loggers ...rel.Logger) (interface{}, error) {
+	adapter.mutex.Lock()
+	defer adapter.mutex.Unlock()
+
+	return adapter.table(query.Table).insert(modifies)
+}
+
+// InsertAll inserts multiple rows and returns its ids.
+func (adapter *Adapter) InsertAll(ctx context.Context, query rel.Query, fields []string, bulkModifies []map[string]rel.Modify, loggers ...rel.Logger) ([]interface{}, error) {
+	adapter.mutex.Lock()
+	defer adapter.mutex.Unlock()
+
+	var (
+		table    = adapter.table(query.Table)
+		snapshot = table.clone()
+		ids      = make([]interface{}, len(bulkModifies))
+	)
+
+	for i := range bulkModifies {
+		id, err := table.insert(bulkModifies[i])
+		if err != nil {
+			*table = *snapshot
+			return nil, err
+		}
+
+		ids[i] = id
+	}
+
+	return ids, nil
+}
+
+// Update rows that matches the query.
+func (adapter *Adapter) Update(ctx context.Context, query rel.Query, modifies map[string]rel.Modify, loggers ...rel.Logger) (int, error) {
+	adapter.mutex.Lock()
+	defer adapter.mutex.Unlock()
+
+	return adapter.table(query.Table).update(query.WhereQuery, modifies)
+}
+
+// Delete rows that matches the query.
+func (adapter *Adapter) Delete(ctx context.Context, query rel.Query, loggers ...rel.Logger) (int, error) {
+	adapter.mutex.Lock()
+	defer adapter.mutex.Unlock()
+
+	return adapter.table(query.Table).delete(query.WhereQuery)
+}
+
+// Begin a transaction by taking snapshot of current tables.
+func (adapter *Adapter) Begin(ctx context.Context) (rel.Adapter, error) {
+	adapter.mutex.RLock()
+	defer adapter.mutex.RUnlock()
+
+	return &Adapter{
+		mutex:  &sync.RWMutex{},
+		tables: cloneTables(adapter.tables),
+		parent: adapter,
+	}, nil
+}
+
+// Commit replaces parent tables with current transaction snapshot.
+func (adapter *Adapter) Commit(ctx context.Context) error {
+	if adapter.parent == nil {
+		return errors.New("unable to commit outside transaction")
+	}
+
+	adapter.mutex.RLock()
+	defer adapter.mutex.RUnlock()
+
+	adapter.parent.mutex.Lock()
+	defer adapter.parent.mutex.Unlock()
+
+	adapter.parent.tables = cloneTables(adapter.tables)
+
+	return nil
+}
+
+// Rollback discards current transaction snapshot.
+func (adapter *Adapter) Rollback(ctx context.Context) error {
+	if adapter.parent == nil {
+		return errors.New("unable to rollback outside transaction")
+	}
+
+	adapter.mutex.Lock()
+	defer adapter.mutex.Unlock()
+
+	adapter.parent.mutex.RLock()
+	defer adapter.parent.mutex.RUnlock()
+
+	adapter.tables = cloneTables(adapter.parent.tables)
+
+	return nil
+}
+
+func (adapter *Adapter) table(name string) *table {
+	t, ok := adapter.tables[name]
+	if !ok {
+		t = &table{}
+		adapter.tables[name] = t
+	}
+
+	return t
+}
+
+func cloneTables(tables map[string]*table) map[string]*table {
+	var (
+		result = make(map[string]*table, len(tables))
+	)
+
+	for name, t := range tables {
+		result[name] = t.clone()
+	}
+
+	return result
+}
+
+func validate(query rel.Query) error {
+	switch {
+	case len(query.JoinQuery) > 0:
+		return rel.NotSupportedError{Operation: "join"}
+	case len(query.GroupQuery.Fields) > 0:
+		return rel.NotSupportedError{Operation: "group"}
+	}
+
+	return nil
+}
@@ -0,0 +1,277 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/adapter/specs"
+	"github.com/Fs02/rel/sort"
+	"github.com/Fs02/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+var ctx = context.TODO()
+
+func TestAdapter_specs(t *testing.T) {
+	repo := rel.New(New())
+
+	// Query Specs
+	specs.QueryNotFound(t, repo)
+
+	// Preload specs
+	specs.PreloadHasMany(t, repo)
+	specs.PreloadHasManyWithQuery(t, repo)
+	specs.PreloadHasManySlice(t, repo)
+	specs.PreloadHasManyWithLimit(t, repo)
+	specs.PreloadEager(t, repo)
+	specs.PreloadHasOne(t, repo)
+	specs.PreloadHasOneWithQuery(t, repo)
+	specs.PreloadHasOneSlice(t, repo)
+	specs.PreloadBelongsTo(t, repo)
+	specs.PreloadBelongsToWithQuery(t, repo)
+	specs.PreloadBelongsToSlice(t, repo)
+
+	// Insert Specs
+	specs.Insert(t, repo)
+	specs.InsertHasMany(t, repo)
+	specs.InsertHasOne(t, repo)
+	specs.InsertBelongsTo(t, repo)
+	specs.Inserts(t, repo)
+	specs.InsertAll(t, repo)
+
+	// Update Specs
+	specs.Update(t, repo)
+	specs.UpdateNotFound(t, repo)
+	specs.UpdateHasManyInsert(t, repo)
+	specs.UpdateHasManyUpdate(t, repo)
+	specs.UpdateHasManyReplace(t, repo)
+	specs.UpdateHasManyDiff(t, repo)
+	specs.UpdateHasOneInsert(t, repo)
+	specs.UpdateHasOneUpdate(t, repo)
+	specs.UpdateBelongsToInsert(t, repo)
+	specs.UpdateBelongsToUpdate(t, repo)
+	specs.UpdateAtomic(t, repo)
+	specs.Updates(t, repo)
+
+	// Delete specs
+	specs.Delete(t, repo)
+	specs.DeleteAll(t, repo)
+}
+
+func TestAdapter_Query(t *testing.T) {
+	var (
+		repo = rel.New(New())
+		note = "note"
+	)
+
+	repo.MustInsert(ctx, &specs.User{Name: "name1", Gender: "male", Age: 10, Note: &note})
+	repo.MustInsert(ctx, &specs.User{Name: "name2", Gender: "male", Age: 20})
+	repo.MustInsert(ctx, &specs.User{Name: "name3", Gender: "female", Age: 30})
+	repo.MustInsert(ctx, &specs.User{Name: "name4", Gender: "female", Age: 40})
+
+	tests := []struct {
+		query rel.Querier
+		names []string
+	}{
+		{query: where.Eq("id", 1), names: []string{"name1"}},
+		{query: rel.Where(where.Eq("name", "name2")), names: []string{"name2"}},
+		{query: rel.Where(where.Eq("gender", "male"), where.Eq("age", 20)), names: []string{"name2"}},
+		{query: rel.Where(where.Eq("id", 1)).OrWhere(where.Eq("name", "name3")), names: []string{"name1", "name3"}},
+		{query: rel.Where(where.Ne("gender", "male")), names: []string{"name3", "name4"}},
+		{query: rel.Where(where.Gt("age", 30)), names: []string{"name4"}},
+		{query: rel.Where(where.Gte("age", 30)), names: []string{"name3", "name4"}},
+		{query: rel.Where(where.Lt("age", 20)), names: []string{"name1"}},
+		{query: rel.Where(where.Lte("age", 20)), names: []string{"name1", "name2"}},
+		{query: rel.Where(where.Nil("note")), names: []string{"name2", "name3", "name4"}},
+		{query: rel.Where(where.NotNil("note")), names: []string{"name1"}},
+		{query: rel.Where(where.In("id", 1, 2)), names: []string{"name1", "name2"}},
+		{query: rel.Where(where.Nin("id", 1, 2)), names: []string{"name3", "name4"}},
+		{query: rel.Where(where.Like("name", "name_")), names: []string{"name1", "name2", "name3", "name4"}},
+		{query: rel.Where(where.NotLike("name", "%1")), names: []string{"name2", "name3", "name4"}},
+		{query: rel.Where(where.Not(where.Eq("gender", "male"), where.Eq("age", 10))), names: []string{"name2", "name3", "name4"}},
+		{query: sort.Desc("age"), names: []string{"name4", "name3", "name2", "name1"}},
+		{query: rel.Select().SortAsc("gender").SortDesc("age"), names: []string{"name4", "name3", "name2", "name1"}},
+		{query: rel.Select().SortAsc("id").Limit(2).Offset(1), names: []string{"name2", "name3"}},
+		{query: rel.Select("name").Where(where.Eq("id", 1)), names: []string{"name1"}},
+	}
+
+	for _, test := range tests {
+		t.Run("FindAll", func(t *testing.T) {
+			var (
+				users []specs.User
+				names []string
+				err   = repo.FindAll(ctx, &users, test.query)
+			)
+
+			for _, user := range users {
+				names = append(names, user.Name)
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.names, names)
+		})
+	}
+}
+
+func TestAdapter_Query_distinct(t *testing.T) {
+	var (
+		repo    = rel.New(New())
+		genders []struct{ Gender string }
+	)
+
+	repo.MustInsert(ctx, &specs.User{Name: "name1", Gender: "male"})
+	repo.MustInsert(ctx, &specs.User{Name: "name2", Gender: "male"})
+	repo.MustInsert(ctx, &specs.User{Name: "name3", Gender: "female"})
+
+	assert.Nil(t, repo.FindAll(ctx, &genders, rel.Select("gender").From("users").Distinct().SortAsc("gender")))
+	assert.Equal(t, []struct{ Gender string }{{Gender: "female"}, {Gender: "male"}}, genders)
+}
+
+func TestAdapter_Query_notSupported(t *testing.T) {
+	var (
+		repo  = rel.New(New())
+		users []specs.User
+	)
+
+	assert.Equal(t, rel.NotSupportedError{Capability: rel.JoinCapability}, repo.FindAll(ctx, &users, rel.Join("addresses")))
+	assert.Equal(t, rel.NotSupportedError{Capability: rel.GroupCapability}, repo.FindAll(ctx, &users, rel.Select("gender").Group("gender")))
+
+	_, err := New().Query(ctx, rel.From("users").Join("addresses"))
+	assert.Equal(t, rel.NotSupportedError{Operation: "join"}, err)
+
+	repo.MustInsert(ctx, &specs.User{Name: "name1"})
+	assert.Equal(t, rel.NotSupportedError{Operation: "fragment filter"}, repo.FindAll(ctx, &users, where.Fragment("id > 0")))
+}
+
+func TestAdapter_Aggregate(t *testing.T) {
+	var (
+		repo = rel.New(New())
+	)
+
+	repo.MustInsert(ctx, &specs.User{Name: "name1", Age: 10})
+	repo.MustInsert(ctx, &specs.User{Name: "name2", Age: 20})
+	repo.MustInsert(ctx, &specs.User{Name: "name3", Age: 45})
+
+	tests := []struct {
+		mode   string
+		result int
+	}{
+		{mode: "count", result: 3},
+		{mode: "sum", result: 75},
+		{mode: "avg", result: 25},
+		{mode: "max", result: 45},
+		{mode: "min", result: 10},
+	}
+
+	for _, test := range tests {
+		t.Run(test.mode, func(t *testing.T) {
+			result, err := repo.Aggregate(ctx, rel.From("users"), test.mode, "age")
+			assert.Nil(t, err)
+			assert.Equal(t, test.result, result)
+		})
+	}
+
+	count, err := repo.Count(ctx, "users", where.Gt("age", 15))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+
+	_, err = repo.Aggregate(ctx, rel.From("users"), "median", "age")
+	assert.Equal(t, rel.NotSupportedError{Operation: "aggregate median"}, err)
+}
+
+func TestAdapter_Insert_primaryKey(t *testing.T) {
+	var (
+		adapter = New()
+		query   = rel.From("users")
+	)
+
+	id, err := adapter.Insert(ctx, query, map[string]rel.Modify{"id": rel.Set("id", 10)})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), id)
+
+	_, err = adapter.Insert(ctx, query, map[string]rel.Modify{"id": rel.Set("id", 10)})
+	assert.Equal(t, rel.ConstraintError{Key: "id", Type: rel.PrimaryKeyConstraint}, err)
+
+	id, err = adapter.Insert(ctx, query, map[string]rel.Modify{"name": rel.Set("name", "name")})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(11), id)
+}
+
+func TestAdapter_InsertAll_rollback(t *testing.T) {
+	var (
+		adapter = New()
+		query   = rel.From("users")
+	)
+
+	_, err := adapter.InsertAll(ctx, query, []string{"id"}, []map[string]rel.Modify{
+		{"id": rel.Set("id", 1)},
+		{"id": rel.Set("id", 1)},
+	})
+	assert.Equal(t, rel.ConstraintError{Key: "id", Type: rel.PrimaryKeyConstraint}, err)
+
+	count, err := adapter.Aggregate(ctx, query, "count", "*")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestAdapter_Transaction(t *testing.T) {
+	var (
+		repo = rel.New(New())
+		user = specs.User{Name: "name1"}
+	)
+
+	assert.Nil(t, repo.Transaction(ctx, func(repo rel.Repository) error {
+		return repo.Insert(ctx, &user)
+	}))
+	assert.Nil(t, repo.Find(ctx, &specs.User{}, where.Eq("id", user.ID)))
+
+	assert.Equal(t, rel.NotFoundError{}, repo.Transaction(ctx, func(repo rel.Repository) error {
+		repo.MustUpdate(ctx, &user, rel.Set("name", "updated"))
+		repo.MustDelete(ctx, &user)
+		return repo.Find(ctx, &specs.User{}, where.Eq("id", user.ID))
+	}))
+	assert.Nil(t, repo.Find(ctx, &user, where.Eq("id", user.ID)))
+	assert.Equal(t, "name1", user.Name)
+}
+
+func TestAdapter_Transaction_nested(t *testing.T) {
+	var (
+		repo    = rel.New(New())
+		user    = specs.User{Name: "name1"}
+		address = specs.Address{Name: "address1"}
+	)
+
+	assert.Nil(t, repo.Transaction(ctx, func(repo rel.Repository) error {
+		repo.MustInsert(ctx, &user)
+
+		assert.Equal(t, rel.NotFoundError{}, repo.Transaction(ctx, func(repo rel.Repository) error {
+			repo.MustInsert(ctx, &address)
+			return rel.NotFoundError{}
+		}))
+
+		return nil
+	}))
+
+	assert.Nil(t, repo.Find(ctx, &specs.User{}, where.Eq("id", user.ID)))
+	assert.Equal(t, rel.NotFoundError{}, repo.Find(ctx, &specs.Address{}, where.Eq("id", address.ID)))
+}
+
+func TestAdapter_Commit_outsideTransaction(t *testing.T) {
+	assert.NotNil(t, New().Commit(ctx))
+	assert.NotNil(t, New().Rollback(ctx))
+}
+
+func TestAdapter_timeValue(t *testing.T) {
+	var (
+		repo      = rel.New(New())
+		createdAt = time.Now().Truncate(time.Second)
+		user      = specs.User{Name: "name1", CreatedAt: createdAt}
+		result    specs.User
+	)
+
+	repo.MustInsert(ctx, &user)
+	assert.Nil(t, repo.Find(ctx, &result, where.Lte("created_at", createdAt)))
+	assert.True(t, createdAt.Equal(result.CreatedAt))
+}
@@ -0,0 +1,383 @@
+package memory
+
+import (
+	"database/sql/driver"
+	"sort"
+	"strings"
+
+	"github.com/Fs02/rel"
+)
+
+type row map[string]interface{}
+
+type table struct {
+	columns   []string
+	rows      []row
+	increment int64
+}
+
+func (t *table) clone() *table {
+	var (
+		result = &table{
+			columns:   append([]string(nil), t.columns...),
+			rows:      make([]row, len(t.rows)),
+			increment: t.increment,
+		}
+	)
+
+	for i := range t.rows {
+		result.rows[i] = make(row, len(t.rows[i]))
+		for field, value := range t.rows[i] {
+			result.rows[i][field] = value
+		}
+	}
+
+	return result
+}
+
+func (t *table) fields(selected []string) []string {
+	if len(selected) == 0 {
+		if t == nil {
+			return nil
+		}
+
+		return t.columns
+	}
+
+	var (
+		fields = make([]string, 0, len(selected))
+	)
+
+	for _, field := range selected {
+		if strings.HasSuffix(field, "*") {
+			if t != nil {
+				fields = append(fields, t.columns...)
+			}
+		} else {
+			fields = append(fields, unqualify(field))
+		}
+	}
+
+	return fields
+}
+
+func (t *table) addColumn(field string) {
+	for i := range t.columns {
+		if t.columns[i] == field {
+			return
+		}
+	}
+
+	t.columns = append(t.columns, field)
+}
+
+func (t *table) filter(filter rel.FilterQuery) ([]row, error) {
+	if t == nil {
+		return nil, nil
+	}
+
+	var (
+		rows []row
+	)
+
+	for i := range t.rows {
+		ok, err := match(t.rows[i], filter)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			rows = append(rows, t.rows[i])
+		}
+	}
+
+	return rows, nil
+}
+
+func (t *table) insert(modifies map[string]rel.Modify) (interface{}, error) {
+	var (
+		r = make(row, len(modifies)+1)
+	)
+
+	for field, mod := range modifies {
+		if mod.Type != rel.ChangeSetOp {
+			return nil, rel.NotSupportedError{Operation: "non set modification on insert"}
+		}
+
+		value, err := normalize(mod.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		r[field] = value
+	}
+
+	if id, ok := r[primaryField].(int64); ok {
+		if t.find(primaryField, id) >= 0 {
+			return nil, rel.ConstraintError{
+				Key:  primaryField,
+				Type: rel.PrimaryKeyConstraint,
+			}
+		}
+
+		if id > t.increment {
+			t.increment = id
+		}
+	} else if r[primaryField] == nil {
+		t.increment++
+		r[primaryField] = t.increment
+	}
+
+	t.addColumn(primaryField)
+	for field := range modifies {
+		t.addColumn(field)
+	}
+
+	t.rows = append(t.rows, r)
+
+	return r[primaryField], nil
+}
+
+func (t *table) update(filter rel.FilterQuery, modifies map[string]rel.Modify) (int, error) {
+	rows, err := t.filter(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	for field := range modifies {
+		t.addColumn(field)
+	}
+
+	for _, r := range rows {
+		for field, mod := range modifies {
+			value, err := normalize(mod.Value)
+			if err != nil {
+				return 0, err
+			}
+
+			switch mod.Type {
+			case rel.ChangeSetOp:
+				r[field] = value
+			case rel.ChangeIncOp:
+				if r[field], err = add(r[field], value); err != nil {
+					return 0, err
+				}
+			default:
+				return 0, rel.NotSupportedError{Operation: "fragment modification"}
+			}
+		}
+	}
+
+	return len(rows), nil
+}
+
+func (t *table) delete(filter rel.FilterQuery) (int, error) {
+	var (
+		rows    = make([]row, 0, len(t.rows))
+		deleted = 0
+	)
+
+	for i := range t.rows {
+		ok, err := match(t.rows[i], filter)
+		if err != nil {
+			return 0, err
+		}
+
+		if ok {
+			deleted++
+		} else {
+			rows = append(rows, t.rows[i])
+		}
+	}
+
+	t.rows = rows
+
+	return deleted, nil
+}
+
+func (t *table) find(field string, value interface{}) int {
+	for i := range t.rows {
+		if c, ok := compare(t.rows[i][field], value); ok && c == 0 {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func sortRows(rows []row, sorts []rel.SortQuery) {
+	if len(sorts) == 0 {
+		return
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		for _, s := range sorts {
+			var (
+				field = unqualify(s.Field)
+				c     = order(rows[i][field], rows[j][field])
+			)
+
+			if c != 0 {
+				if s.Desc() {
+					return c > 0
+				}
+
+				return c < 0
+			}
+		}
+
+		return false
+	})
+}
+
+// project copies selected fields of rows, so the result is not affected by the next modification.
+func project(rows []row, fields []string, distinct bool) []row {
+	var (
+		result = make([]row, 0, len(rows))
+	)
+
+	for i := range rows {
+		var (
+			r = make(row, len(fields))
+		)
+
+		for _, field := range fields {
+			r[field] = rows[i][field]
+		}
+
+		if distinct && contains(result, r, fields) {
+			continue
+		}
+
+		result = append(result, r)
+	}
+
+	return result
+}
+
+func contains(rows []row, r row, fields []string) bool {
+	for i := range rows {
+		equal := true
+		for _, field := range fields {
+			if order(rows[i][field], r[field]) != 0 {
+				equal = false
+				break
+			}
+		}
+
+		if equal {
+			return true
+		}
+	}
+
+	return false
+}
+
+func limitOffset(rows []row, limit rel.Limit, offset rel.Offset) []row {
+	if offset > 0 {
+		if int(offset) >= len(rows) {
+			return nil
+		}
+
+		rows = rows[offset:]
+	}
+
+	if limit > 0 && int(limit) < len(rows) {
+		rows = rows[:limit]
+	}
+
+	return rows
+}
+
+func aggregate(rows []row, mode string, field string) (int, error) {
+	var (
+		count  int
+		result interface{}
+	)
+
+	field = unqualify(field)
+
+	for _, r := range rows {
+		if field == "*" {
+			count++
+			continue
+		}
+
+		value := r[field]
+		if value == nil {
+			continue
+		}
+
+		count++
+
+		switch mode {
+		case "sum", "avg":
+			var err error
+			if result, err = add(result, value); err != nil {
+				return 0, err
+			}
+		case "max":
+			if c, ok := compare(value, result); result == nil || (ok && c > 0) {
+				result = value
+			}
+		case "min":
+			if c, ok := compare(value, result); result == nil || (ok && c < 0) {
+				result = value
+			}
+		}
+	}
+
+	switch mode {
+	case "count":
+		return count, nil
+	case "sum", "max", "min":
+		return toInt(result), nil
+	case "avg":
+		if count == 0 {
+			return 0, nil
+		}
+
+		return int(toFloat(result) / float64(count)), nil
+	default:
+		return 0, rel.NotSupportedError{Operation: "aggregate " + mode}
+	}
+}
+
+func toInt(value interface{}) int {
+	switch v := value.(type) {
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	default:
+		return 0
+	}
+}
+
+func toFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	default:
+		return 0
+	}
+}
+
+func unqualify(field string) string {
+	if i := strings.LastIndexByte(field, '.'); i >= 0 {
+		return field[i+1:]
+	}
+
+	return field
+}
+
+func normalize(value interface{}) (interface{}, error) {
+	value, err := driver.DefaultParameterConverter.ConvertValue(value)
+	if b, ok := value.([]byte); ok {
+		value = append([]byte(nil), b...)
+	}
+
+	return value, err
+}
@@ -0,0 +1,370 @@
+// Package metrics implements adapter middleware that collects metrics of every operation of REL.
+//
+// Collector counts operations and errors, and records latency histogram labeled by operation and table.
+// Connection pool statistics of the decorated adapter are exported as gauges.
+// Metrics are written using Prometheus text exposition format, so collector can be served as scrape endpoint
+// next to promhttp handler without depending on Prometheus client library.
+//
+// Usage:
+//	// initialize collector and REL's repo.
+//	collector := metrics.New()
+//	repo := rel.New(rel.WrapAdapter(adapter, collector.Middleware))
+//
+//	// or collect metrics of repository operations using instrumentation.
+//	repo.Instrumentation(collector.Instrument)
+//
+//	// expose metrics to Prometheus.
+//	http.Handle("/metrics/rel", collector)
+//	http.Handle("/metrics", promhttp.Handler())
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Fs02/rel"
+)
+
+// DefaultBuckets of latency histogram in seconds.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Collector collects metrics of operations executed through its middleware.
+type Collector struct {
+	Namespace string
+	Buckets   []float64
+
+	mutex  sync.Mutex
+	series map[label]*series
+	pool   rel.StatsAdapter
+}
+
+type label struct {
+	operation string
+	table     string
+}
+
+type series struct {
+	count    uint64
+	errors   uint64
+	sum      float64
+	observed []uint64
+}
+
+// New collector using rel namespace and default buckets.
+func New() *Collector {
+	return &Collector{
+		Namespace: "rel",
+		Buckets:   DefaultBuckets,
+		series:    make(map[label]*series),
+	}
+}
+
+// Middleware decorates adapter to collect metrics of its operations.
+// Connection pool statistics are read from the first decorated adapter that maintains connection pool.
+func (c *Collector) Middleware(adapter rel.Adapter) rel.Adapter {
+	c.mutex.Lock()
+	if sa, ok := adapter.(rel.StatsAdapter); ok && c.pool == nil {
+		c.pool = sa
+	}
+	c.mutex.Unlock()
+
+	return &measuredAdapter{
+		Adapter:   adapter,
+		collector: c,
+	}
+}
+
+// Instrument is rel.Instrumenter that collects metrics of every operation of the repository, message is used as the table label.
+func (c *Collector) Instrument(ctx context.Context, op string, message string) func(err error) {
+	start := time.Now()
+	return func(err error) {
+		c.Observe(op, message, start, err)
+	}
+}
+
+// Observe operation on a table that was started at the given time.
+func (c *Collector) Observe(operation string, table string, start time.Time, err error) {
+	var (
+		duration = time.Since(start).Seconds()
+		key      = label{operation: operation, table: table}
+	)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	s, ok := c.series[key]
+	if !ok {
+		s = &series{observed: make([]uint64, len(c.Buckets))}
+		c.series[key] = s
+	}
+
+	s.count++
+	s.sum += duration
+	if err != nil {
+		s.errors++
+	}
+
+	for i, bound := range c.Buckets {
+		if duration <= bound {
+			s.observed[i]++
+		}
+	}
+}
+
+// ServeHTTP writes collected metrics as scrape response.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.WriteTo(w)
+}
+
+// WriteTo writes collected metrics using Prometheus text exposition format.
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	var (
+		buf  = bufio.NewWriter(w)
+		cw   = &countWriter{Writer: buf}
+		keys = make([]label, 0, len(c.series))
+	)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for key := range c.series {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].operation != keys[j].operation {
+			return keys[i].operation < keys[j].operation
+		}
+
+		return keys[i].table < keys[j].table
+	})
+
+	c.header(cw, "operations_total", "counter", "Total number of executed operations.")
+	for _, key := range keys {
+		c.sample(cw, "operations_total", key.labels(), float64(c.series[key].count))
+	}
+
+	c.header(cw, "operation_errors_total", "counter", "Total number of operations that returned error.")
+	for _, key := range keys {
+		c.sample(cw, "operation_errors_total", key.labels(), float64(c.series[key].errors))
+	}
+
+	c.header(cw, "operation_duration_seconds", "histogram", "Latency of operations in seconds.")
+	for _, key := range keys {
+		var (
+			s      = c.series[key]
+			labels = key.labels()
+		)
+
+		for i, bound := range c.Buckets {
+			c.sample(cw, "operation_duration_seconds_bucket", labels+`,le="`+format(bound)+`"`, float64(s.observed[i]))
+		}
+
+		c.sample(cw, "operation_duration_seconds_bucket", labels+`,le="+Inf"`, float64(s.count))
+		c.sample(cw, "operation_duration_seconds_sum", labels, s.sum)
+		c.sample(cw, "operation_duration_seconds_count", labels, float64(s.count))
+	}
+
+	if c.pool != nil {
+		c.writePool(cw, c.pool.Stats())
+	}
+
+	if cw.err == nil {
+		cw.err = buf.Flush()
+	}
+
+	return cw.n, cw.err
+}
+
+func (c *Collector) writePool(w io.Writer, stats sql.DBStats) {
+	gauges := []struct {
+		name  string
+		kind  string
+		help  string
+		value float64
+	}{
+		{"pool_max_open_connections", "gauge", "Maximum number of open connections to the database.", float64(stats.MaxOpenConnections)},
+		{"pool_open_connections", "gauge", "Number of established connections both in use and idle.", float64(stats.OpenConnections)},
+		{"pool_in_use_connections", "gauge", "Number of connections currently in use.", float64(stats.InUse)},
+		{"pool_idle_connections", "gauge", "Number of idle connections.", float64(stats.Idle)},
+		{"pool_wait_count_total", "counter", "Total number of connections waited for.", float64(stats.WaitCount)},
+		{"pool_wait_duration_seconds_total", "counter", "Total time blocked waiting for a new connection in seconds.", stats.WaitDuration.Seconds()},
+	}
+
+	for _, g := range gauges {
+		c.header(w, g.name, g.kind, g.help)
+		c.sample(w, g.name, "", g.value)
+	}
+}
+
+func (c *Collector) header(w io.Writer, name string, kind string, help string) {
+	name = c.name(name)
+	io.WriteString(w, "# HELP "+name+" "+help+"\n# TYPE "+name+" "+kind+"\n")
+}
+
+func (c *Collector) sample(w io.Writer, name string, labels string, value float64) {
+	name = c.name(name)
+	if labels != "" {
+		name += "{" + labels + "}"
+	}
+
+	io.WriteString(w, name+" "+format(value)+"\n")
+}
+
+func (c *Collector) name(name string) string {
+	if c.Namespace == "" {
+		return name
+	}
+
+	return c.Namespace + "_" + name
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func (l label) labels() string {
+	return `operation="` + escaper.Replace(l.operation) + `",table="` + escaper.Replace(l.table) + `"`
+}
+
+func format(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// countWriter counts written bytes and keeps the first error, so write errors can be checked once.
+type countWriter struct {
+	io.Writer
+	n   int64
+	err error
+}
+
+func (cw *countWriter) Write(p []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+
+	n, err := cw.Writer.Write(p)
+	cw.n += int64(n)
+	cw.err = err
+
+	return n, err
+}
+
+type measuredAdapter struct {
+	rel.Adapter
+	collector *Collector
+}
+
+// Unwrap returns the measured adapter.
+func (ma *measuredAdapter) Unwrap() rel.Adapter {
+	return ma.Adapter
+}
+
+func (ma *measuredAdapter) Aggregate(ctx context.Context, query rel.Query, mode string, field string, loggers ...rel.Logger) (int, error) {
+	finish := ma.collector.Instrument(ctx, "aggregate", query.Table)
+
+	result, err := ma.Adapter.Aggregate(ctx, query, mode, field, loggers...)
+	finish(err)
+
+	return result, err
+}
+
+// Query returns cursor that observes the operation when it's closed, so latency includes fetching rows.
+func (ma *measuredAdapter) Query(ctx context.Context, query rel.Query, loggers ...rel.Logger) (rel.Cursor, error) {
+	finish := ma.collector.Instrument(ctx, "query", query.Table)
+
+	cur, err := ma.Adapter.Query(ctx, query, loggers...)
+	if err != nil {
+		finish(err)
+		return nil, err
+	}
+
+	return &measuredCursor{Cursor: cur, finish: finish}, nil
+}
+
+func (ma *measuredAdapter) Insert(ctx context.Context, query rel.Query, modifies map[string]rel.Modify, loggers ...rel.Logger) (interface{}, error) {
+	finish := ma.collector.Instrument(ctx, "insert", query.Table)
+
+	id, err := ma.Adapter.Insert(ctx, query, modifies, loggers...)
+	finish(err)
+
+	return id, err
+}
+
+func (ma *measuredAdapter) InsertAll(ctx context.Context, query rel.Query, fields []string, bulkModifies []map[string]rel.Modify, loggers ...rel.Logger) ([]interface{}, error) {
+	finish := ma.collector.Instrument(ctx, "insert_all", query.Table)
+
+	ids, err := ma.Adapter.InsertAll(ctx, query, fields, bulkModifies, loggers...)
+	finish(err)
+
+	return ids, err
+}
+
+func (ma *measuredAdapter) Update(ctx context.Context, query rel.Query, modifies map[string]rel.Modify, loggers ...rel.Logger) (int, error) {
+	finish := ma.collector.Instrument(ctx, "update", query.Table)
+
+	updated, err := ma.Adapter.Update(ctx, query, modifies, loggers...)
+	finish(err)
+
+	return updated, err
+}
+
+func (ma *measuredAdapter) Delete(ctx context.Context, query rel.Query, loggers ...rel.Logger) (int, error) {
+	finish := ma.collector.Instrument(ctx, "delete", query.Table)
+
+	deleted, err := ma.Adapter.Delete(ctx, query, loggers...)
+	finish(err)
+
+	return deleted, err
+}
+
+// Begin returns transaction adapter as is, it's decorated again by rel.WrapAdapter.
+func (ma *measuredAdapter) Begin(ctx context.Context) (rel.Adapter, error) {
+	finish := ma.collector.Instrument(ctx, "begin", "")
+
+	adapter, err := ma.Adapter.Begin(ctx)
+	finish(err)
+
+	return adapter, err
+}
+
+func (ma *measuredAdapter) Commit(ctx context.Context) error {
+	finish := ma.collector.Instrument(ctx, "commit", "")
+
+	err := ma.Adapter.Commit(ctx)
+	finish(err)
+
+	return err
+}
+
+func (ma *measuredAdapter) Rollback(ctx context.Context) error {
+	finish := ma.collector.Instrument(ctx, "rollback", "")
+
+	err := ma.Adapter.Rollback(ctx)
+	finish(err)
+
+	return err
+}
+
+type measuredCursor struct {
+	rel.Cursor
+	finish func(error)
+	closed bool
+}
+
+func (mc *measuredCursor) Close() error {
+	err := mc.Cursor.Close()
+	if !mc.closed {
+		mc.closed = true
+		mc.finish(err)
+	}
+
+	return err
+}
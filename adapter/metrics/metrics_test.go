@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/adapter/sqlite3"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+type book struct {
+	ID    int
+	Title string
+}
+
+func open(t *testing.T, collector *Collector) (*sqlite3.Adapter, rel.Repository) {
+	adapter, err := sqlite3.Open("file:metrics?mode=memory&cache=shared")
+	assert.Nil(t, err)
+
+	_, _, err = adapter.Exec(context.TODO(), "CREATE TABLE IF NOT EXISTS books (id INTEGER PRIMARY KEY AUTOINCREMENT, title TEXT);", nil)
+	assert.Nil(t, err)
+
+	repo := rel.New(rel.WrapAdapter(adapter, collector.Middleware))
+	repo.SetLogger()
+
+	return adapter, repo
+}
+
+func TestCollector(t *testing.T) {
+	var (
+		collector     = New()
+		adapter, repo = open(t, collector)
+		ctx           = context.TODO()
+		records       = []book{{Title: "REL for dummies"}, {Title: "REL for experts"}}
+		buf           bytes.Buffer
+	)
+
+	defer adapter.Close()
+
+	assert.Nil(t, repo.Transaction(ctx, func(repo rel.Repository) error {
+		return repo.InsertAll(ctx, &records)
+	}))
+	assert.Nil(t, repo.FindAll(ctx, &records))
+	assert.Nil(t, repo.FindAll(ctx, &records))
+	assert.NotNil(t, repo.FindAll(ctx, &records, rel.From("missing")))
+
+	n, err := collector.WriteTo(&buf)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+
+	output := buf.String()
+	for _, line := range []string{
+		"# TYPE rel_operations_total counter",
+		`rel_operations_total{operation="begin",table=""} 1`,
+		`rel_operations_total{operation="commit",table=""} 1`,
+		`rel_operations_total{operation="insert_all",table="books"} 1`,
+		`rel_operations_total{operation="query",table="books"} 2`,
+		`rel_operations_total{operation="query",table="missing"} 1`,
+		`rel_operation_errors_total{operation="query",table="books"} 0`,
+		`rel_operation_errors_total{operation="query",table="missing"} 1`,
+		"# TYPE rel_operation_duration_seconds histogram",
+		`rel_operation_duration_seconds_bucket{operation="query",table="books",le="+Inf"} 2`,
+		`rel_operation_duration_seconds_count{operation="query",table="books"} 2`,
+		"# TYPE rel_pool_open_connections gauge",
+		"rel_pool_max_open_connections 0",
+		"rel_pool_wait_count_total 0",
+	} {
+		assert.Contains(t, output, line+"\n")
+	}
+
+	assert.True(t, strings.Index(output, `operation="begin"`) < strings.Index(output, `operation="query"`))
+}
+
+func TestCollector_Observe(t *testing.T) {
+	var (
+		collector = New()
+		buf       bytes.Buffer
+	)
+
+	collector.Namespace = "app"
+	collector.Buckets = []float64{0.5, 60}
+
+	collector.Observe("update", `"books"`, time.Now().Add(-time.Second), nil)
+	collector.Observe("update", `"books"`, time.Now(), errors.New("error"))
+
+	_, err := collector.WriteTo(&buf)
+	assert.Nil(t, err)
+	assert.Contains(t, buf.String(), `app_operation_duration_seconds_bucket{operation="update",table="\"books\"",le="0.5"} 1`+"\n")
+	assert.Contains(t, buf.String(), `app_operation_duration_seconds_bucket{operation="update",table="\"books\"",le="60"} 2`+"\n")
+	assert.Contains(t, buf.String(), `app_operation_errors_total{operation="update",table="\"books\""} 1`+"\n")
+	assert.NotContains(t, buf.String(), "app_pool_")
+}
+
+func TestCollector_ServeHTTP(t *testing.T) {
+	var (
+		collector = New()
+		rec       = httptest.NewRecorder()
+	)
+
+	collector.Observe("delete", "books", time.Now(), nil)
+	collector.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, 200, rec.Code)
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), `rel_operations_total{operation="delete",table="books"} 1`)
+}
+
+func TestCollector_Instrument(t *testing.T) {
+	var (
+		collector  = New()
+		adapter, _ = open(t, New())
+		repo       = rel.New(adapter)
+		buf        bytes.Buffer
+	)
+
+	defer adapter.Close()
+
+	repo.SetLogger()
+	repo.Instrumentation(collector.Instrument)
+
+	_, err := repo.Count(context.TODO(), "books", rel.From("missing"))
+	assert.NotNil(t, err)
+
+	_, err = collector.WriteTo(&buf)
+	assert.Nil(t, err)
+	assert.Contains(t, buf.String(), `rel_operations_total{operation="aggregate",table="missing"} 1`+"\n")
+}
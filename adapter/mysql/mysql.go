@@ -1,5 +1,9 @@
 // Package mysql wraps mysql driver as an adapter for REL.
 //
+// InsertAll bulk loads records using LOAD DATA LOCAL INFILE only when every record has explicit id,
+// since ids assigned by auto increment can't be retrieved reliably after LOAD DATA.
+// Records that rely on auto increment id are always inserted using INSERT statement regardless of BulkLoadThreshold.
+//
 // Usage:
 //	// open mysql connection.
 //	adapter, err := mysql.Open("root@(127.0.0.1:3306)/rel_test?charset=utf8&parseTime=True&loc=Local")
@@ -10,14 +14,28 @@
 //
 //	// initialize REL's repo.
 //	repo := rel.New(adapter)
+//
+//	// insert or update existing user with the same unique key using ON DUPLICATE KEY UPDATE.
+//	err = repo.Insert(ctx, &user, rel.NewStructset(&user, false), rel.OnConflictReplace())
 package mysql
 
 import (
+	"bytes"
+	"context"
 	db "database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/Fs02/rel"
 	"github.com/Fs02/rel/adapter/sql"
+	"github.com/Fs02/rel/migrator"
+	"github.com/go-sql-driver/mysql"
 )
 
 // Adapter definition for mysql database.
@@ -27,10 +45,33 @@ type Adapter struct {
 
 var _ rel.Adapter = (*Adapter)(nil)
 
+// New mysql adapter using existing connection.
+// The connection must be opened using clientFoundRows=true, this allows not found record check when updating a record.
+// Use it together with sql.Connector to connect using rotating credential.
+func New(database *db.DB) *Adapter {
+	return &Adapter{
+		Adapter: &sql.Adapter{
+			Config: &sql.Config{
+				Placeholder:       "?",
+				EscapeChar:        "`",
+				DropIndexOnTable:  true,
+				IncrementFunc:     incrementFunc,
+				IsolationFunc:     isolationFunc,
+				ErrorFunc:         errorFunc,
+				DumpStructureFunc: dumpStructureFunc,
+				InspectTablesFunc: inspectTablesFunc,
+				LockFunc:          lockFunc,
+				OnConflictFunc:    onConflictFunc,
+				BulkLoadThreshold: sql.DefaultBulkLoadThreshold,
+				Capabilities:      rel.OnConflictCapability,
+			},
+			DB: database,
+		},
+	}
+}
+
 // Open mysql connection using dsn.
 func Open(dsn string) (*Adapter, error) {
-	var err error
-
 	// force clientFoundRows=true
 	// this allows not found record check when updating a record.
 	if strings.ContainsRune(dsn, '?') {
@@ -39,36 +80,329 @@ func Open(dsn string) (*Adapter, error) {
 		dsn += "?clientFoundRows=true"
 	}
 
-	adapter := &Adapter{
-		Adapter: &sql.Adapter{
-			Config: &sql.Config{
-				Placeholder:   "?",
-				EscapeChar:    "`",
-				IncrementFunc: incrementFunc,
-				ErrorFunc:     errorFunc,
-			},
-		},
+	database, err := db.Open("mysql", dsn)
+	return New(database), err
+}
+
+// InsertAll inserts multiple records to database and returns its ids.
+// Records are inserted using LOAD DATA LOCAL INFILE when the number of records reach BulkLoadThreshold and every record has explicit id,
+// since ids of records inserted by LOAD DATA can't be retrieved reliably, records without id are always inserted using INSERT statement. This requires local_infile to be enabled on the server,
+// set BulkLoadThreshold to zero when it's disabled. Records are loaded inside a transaction, and it returns error without inserting any record
+// when any of the record is skipped, since LOAD DATA LOCAL reports duplicate key and invalid value as warnings instead of error.
+// Records inserted with on conflict option are inserted using ON DUPLICATE KEY UPDATE without returning ids,
+// since mysql doesn't report which of the records conflicts with existing record.
+func (adapter *Adapter) InsertAll(ctx context.Context, query rel.Query, fields []string, bulkModifies []map[string]rel.Modify, loggers ...rel.Logger) ([]interface{}, error) {
+	if query.OnConflictQuery != "" {
+		_, err := adapter.Adapter.InsertAll(ctx, query, fields, bulkModifies, loggers...)
+		return nil, err
 	}
-	adapter.DB, err = db.Open("mysql", dsn)
 
-	return adapter, err
+	if !containsString(fields, "id") || !sql.BulkLoadable(adapter.Config.BulkLoadThreshold, fields, bulkModifies) {
+		return adapter.Adapter.InsertAll(ctx, query, fields, bulkModifies, loggers...)
+	}
+
+	txAdapter, err := adapter.Adapter.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := txAdapter.(*sql.Adapter)
+	if err := loadData(ctx, tx, query.Table, fields, bulkModifies, loggers); err != nil {
+		tx.Rollback(ctx)
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	ids := make([]interface{}, len(bulkModifies))
+	for i := range ids {
+		ids[i] = bulkModifies[i]["id"].Value
+	}
+
+	return ids, nil
 }
 
-func incrementFunc(adapter sql.Adapter) int {
+// loadData loads records using LOAD DATA LOCAL INFILE inside the transaction,
+// the first warning is returned as error, and it returns error when the number of inserted records doesn't match.
+func loadData(ctx context.Context, tx *sql.Adapter, table string, fields []string, bulkModifies []map[string]rel.Modify, loggers []rel.Logger) error {
+	var (
+		name = "rel_" + strconv.FormatUint(atomic.AddUint64(&readerSequence, 1), 10)
+		data = encodeRows(fields, bulkModifies)
+	)
+
+	mysql.RegisterReaderHandler(name, func() io.Reader {
+		return bytes.NewReader(data)
+	})
+	defer mysql.DeregisterReaderHandler(name)
+
+	_, count, err := tx.Exec(ctx, loadDataStatement(name, table, fields), nil, loggers...)
+	if err != nil {
+		return err
+	}
+
+	var (
+		level   string
+		code    int
+		message string
+	)
+
+	switch err := tx.Tx.QueryRowContext(ctx, "SHOW WARNINGS LIMIT 1;").Scan(&level, &code, &message); err {
+	case nil:
+		return errorFunc(fmt.Errorf("Error %d: %s", code, message))
+	case db.ErrNoRows:
+	default:
+		return err
+	}
+
+	if count != int64(len(bulkModifies)) {
+		return fmt.Errorf("mysql: load data inserted %d of %d records", count, len(bulkModifies))
+	}
+
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for i := range values {
+		if values[i] == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Begin begins a new transaction.
+func (adapter *Adapter) Begin(ctx context.Context) (rel.Adapter, error) {
+	newAdapter, err := adapter.Adapter.Begin(ctx)
+
+	return &Adapter{
+		Adapter: newAdapter.(*sql.Adapter),
+	}, err
+}
+
+var readerSequence uint64
+
+// onConflictFunc renders ON DUPLICATE KEY UPDATE clause, id is assigned using LAST_INSERT_ID,
+// so id of the existing record is returned when the inserted record conflicts with it.
+func onConflictFunc(onConflict rel.OnConflict, fields []string) string {
+	var (
+		buffer strings.Builder
+	)
+
+	buffer.WriteString(" ON DUPLICATE KEY UPDATE `id`=LAST_INSERT_ID(`id`)")
+
+	if onConflict == rel.OnConflictReplace() {
+		for i := range fields {
+			if fields[i] == "id" {
+				continue
+			}
+
+			buffer.WriteString(",`")
+			buffer.WriteString(fields[i])
+			buffer.WriteString("`=VALUES(`")
+			buffer.WriteString(fields[i])
+			buffer.WriteString("`)")
+		}
+	}
+
+	return buffer.String()
+}
+
+func loadDataStatement(name string, table string, fields []string) string {
+	var (
+		buffer strings.Builder
+	)
+
+	buffer.WriteString("LOAD DATA LOCAL INFILE 'Reader::")
+	buffer.WriteString(name)
+	buffer.WriteString("' INTO TABLE `")
+	buffer.WriteString(table)
+	buffer.WriteString("` CHARACTER SET utf8mb4 (")
+
+	for i := range fields {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+
+		buffer.WriteByte('`')
+		buffer.WriteString(fields[i])
+		buffer.WriteByte('`')
+	}
+
+	buffer.WriteString(");")
+
+	return buffer.String()
+}
+
+// encodeRows encodes records using default LOAD DATA format, which is tab separated fields and newline separated rows.
+func encodeRows(fields []string, bulkModifies []map[string]rel.Modify) []byte {
+	var (
+		buffer bytes.Buffer
+	)
+
+	for _, modifies := range bulkModifies {
+		for i, field := range fields {
+			if i > 0 {
+				buffer.WriteByte('\t')
+			}
+
+			encodeValue(&buffer, modifies[field].Value)
+		}
+
+		buffer.WriteByte('\n')
+	}
+
+	return buffer.Bytes()
+}
+
+func encodeValue(buffer *bytes.Buffer, value interface{}) {
+	switch v := value.(type) {
+	case nil:
+		buffer.WriteString("\\N")
+	case bool:
+		if v {
+			buffer.WriteByte('1')
+		} else {
+			buffer.WriteByte('0')
+		}
+	case time.Time:
+		buffer.WriteString(v.Format("2006-01-02 15:04:05.999999"))
+	case []byte:
+		escape(buffer, string(v))
+	case string:
+		escape(buffer, v)
+	default:
+		escape(buffer, fmt.Sprint(v))
+	}
+}
+
+func escape(buffer *bytes.Buffer, s string) {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			buffer.WriteString("\\\\")
+		case '\t':
+			buffer.WriteString("\\t")
+		case '\n':
+			buffer.WriteString("\\n")
+		case '\r':
+			buffer.WriteString("\\r")
+		case 0:
+			buffer.WriteString("\\0")
+		default:
+			buffer.WriteByte(s[i])
+		}
+	}
+}
+
+func incrementFunc(ctx context.Context, adapter sql.Adapter) (int, error) {
 	var variable string
 	var increment int
 	var err error
 	if adapter.Tx != nil {
-		err = adapter.Tx.QueryRow("SHOW VARIABLES LIKE 'auto_increment_increment';").Scan(&variable, &increment)
+		err = adapter.Tx.QueryRowContext(ctx, "SHOW VARIABLES LIKE 'auto_increment_increment';").Scan(&variable, &increment)
 	} else {
-		err = adapter.DB.QueryRow("SHOW VARIABLES LIKE 'auto_increment_increment';").Scan(&variable, &increment)
+		err = adapter.DB.QueryRowContext(ctx, "SHOW VARIABLES LIKE 'auto_increment_increment';").Scan(&variable, &increment)
+	}
+
+	return increment, err
+}
+
+// isolationFunc returns statement to set isolation level of the next transaction,
+// since mysql driver doesn't support isolation level when beginning transaction.
+func isolationFunc(level db.IsolationLevel) string {
+	switch level {
+	case db.LevelReadUncommitted, db.LevelReadCommitted, db.LevelRepeatableRead, db.LevelSerializable:
+		return "SET TRANSACTION ISOLATION LEVEL " + strings.ToUpper(level.String()) + ";"
+	default:
+		return ""
+	}
+}
+
+var autoIncrement = regexp.MustCompile(` AUTO_INCREMENT=\d+`)
+
+// lockFunc acquires user level lock using a dedicated connection.
+// User level lock is global to the server, so the name is prefixed by the name of current database.
+func lockFunc(ctx context.Context, adapter sql.Adapter, name string) (func() error, error) {
+	conn, err := adapter.DB.Conn(ctx)
+	if err != nil {
+		return nil, errorFunc(err)
+	}
+
+	var locked db.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(CONCAT(DATABASE(), '.', ?), -1);", name).Scan(&locked); err != nil {
+		conn.Close()
+		return nil, errorFunc(err)
 	}
 
+	if locked.Int64 != 1 {
+		conn.Close()
+		return nil, errors.New("mysql: failed to acquire lock " + name)
+	}
+
+	return func() error {
+		_, err := conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(CONCAT(DATABASE(), '.', ?));", name)
+		if cerr := conn.Close(); err == nil {
+			err = cerr
+		}
+
+		return errorFunc(err)
+	}, nil
+}
+
+// inspectTablesFunc lists columns and indexes of every tables in the current database using information_schema.
+func inspectTablesFunc(ctx context.Context, adapter sql.Adapter) ([]migrator.TableInfo, error) {
+	return sql.Inspect(ctx, adapter,
+		"SELECT TABLE_NAME, COLUMN_NAME FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE() ORDER BY TABLE_NAME, ORDINAL_POSITION;",
+		"SELECT TABLE_NAME, INDEX_NAME, COLUMN_NAME FROM information_schema.STATISTICS WHERE TABLE_SCHEMA = DATABASE() ORDER BY TABLE_NAME, INDEX_NAME, SEQ_IN_INDEX;",
+	)
+}
+
+// dumpStructureFunc returns create statement of every tables using SHOW CREATE TABLE.
+// Foreign key checks is disabled while loading the structure, since tables are sorted by its name.
+func dumpStructureFunc(ctx context.Context, adapter sql.Adapter) (string, error) {
+	rows, err := adapter.DB.QueryContext(ctx, "SHOW FULL TABLES WHERE Table_type = 'BASE TABLE';")
 	if err != nil {
-		panic(err)
+		return "", err
+	}
+
+	var (
+		tables []string
+	)
+
+	for rows.Next() {
+		var table, typ string
+		if err := rows.Scan(&table, &typ); err != nil {
+			rows.Close()
+			return "", err
+		}
+
+		tables = append(tables, table)
 	}
 
-	return increment
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	var (
+		statements = []string{"SET FOREIGN_KEY_CHECKS = 0;\n"}
+	)
+
+	for _, table := range tables {
+		var name, statement string
+		if err := adapter.DB.QueryRowContext(ctx, "SHOW CREATE TABLE `"+table+"`;").Scan(&name, &statement); err != nil {
+			return "", err
+		}
+
+		statements = append(statements, autoIncrement.ReplaceAllString(statement, "")+";\n")
+	}
+
+	statements = append(statements, "SET FOREIGN_KEY_CHECKS = 1;\n")
+
+	return strings.Join(statements, "\n"), nil
 }
 
 func errorFunc(err error) error {
@@ -87,18 +421,26 @@ func errorFunc(err error) error {
 	}
 
 	switch msg[:errCodeIndex] {
+	case "Error 1213":
+		return rel.SerializationError{Err: err}
 	case "Error 1062":
 		return rel.ConstraintError{
 			Key:  sql.ExtractString(msg, "key '", "'"),
 			Type: rel.UniqueConstraint,
 			Err:  err,
 		}
-	case "Error 1452":
+	case "Error 1451", "Error 1452":
 		return rel.ConstraintError{
 			Key:  sql.ExtractString(msg, "CONSTRAINT `", "`"),
 			Type: rel.ForeignKeyConstraint,
 			Err:  err,
 		}
+	case "Error 3819":
+		return rel.ConstraintError{
+			Key:  sql.ExtractString(msg, "Check constraint '", "'"),
+			Type: rel.CheckConstraint,
+			Err:  err,
+		}
 	default:
 		return err
 	}
@@ -2,12 +2,17 @@ package mysql
 
 import (
 	"context"
+	db "database/sql"
+	"errors"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	paranoid "github.com/Fs02/go-paranoid"
 	"github.com/Fs02/rel"
 	"github.com/Fs02/rel/adapter/specs"
+	"github.com/Fs02/rel/migrator"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/stretchr/testify/assert"
 )
@@ -81,6 +86,8 @@ func TestAdapter_specs(t *testing.T) {
 	specs.PreloadHasMany(t, repo)
 	specs.PreloadHasManyWithQuery(t, repo)
 	specs.PreloadHasManySlice(t, repo)
+	specs.PreloadHasManyWithLimit(t, repo)
+	specs.PreloadEager(t, repo)
 	specs.PreloadHasOne(t, repo)
 	specs.PreloadHasOneWithQuery(t, repo)
 	specs.PreloadHasOneSlice(t, repo)
@@ -98,6 +105,7 @@ func TestAdapter_specs(t *testing.T) {
 	specs.InsertBelongsTo(t, repo)
 	specs.Inserts(t, repo)
 	specs.InsertAll(t, repo)
+	specs.InsertOnConflict(t, repo)
 
 	// Update Specs
 	specs.Update(t, repo)
@@ -105,6 +113,7 @@ func TestAdapter_specs(t *testing.T) {
 	specs.UpdateHasManyInsert(t, repo)
 	specs.UpdateHasManyUpdate(t, repo)
 	specs.UpdateHasManyReplace(t, repo)
+	specs.UpdateHasManyDiff(t, repo)
 	specs.UpdateHasOneInsert(t, repo)
 	specs.UpdateHasOneUpdate(t, repo)
 	specs.UpdateBelongsToInsert(t, repo)
@@ -187,3 +196,134 @@ func TestAdapter_Exec_error(t *testing.T) {
 	_, _, err = adapter.Exec(ctx, "error", nil)
 	assert.NotNil(t, err)
 }
+
+func TestAdapter_DumpStructure(t *testing.T) {
+	adapter, err := Open(dsn())
+	assert.Nil(t, err)
+	defer adapter.Close()
+
+	_, _, err = adapter.Exec(ctx, "DROP TABLE IF EXISTS structures;", nil)
+	assert.Nil(t, err)
+	_, _, err = adapter.Exec(ctx, "CREATE TABLE structures (id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY, name VARCHAR(255));", nil)
+	assert.Nil(t, err)
+	_, _, err = adapter.Exec(ctx, "INSERT INTO structures (name) VALUES ('REL');", nil)
+	assert.Nil(t, err)
+
+	structure, err := adapter.DumpStructure(ctx)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(structure, "SET FOREIGN_KEY_CHECKS = 0;\n"))
+	assert.Contains(t, structure, "CREATE TABLE `structures`")
+	assert.NotContains(t, structure, "AUTO_INCREMENT=")
+
+	_, _, err = adapter.Exec(ctx, "DROP TABLE structures;", nil)
+	assert.Nil(t, err)
+}
+
+func TestAdapter_InspectTables(t *testing.T) {
+	adapter, err := Open(dsn())
+	assert.Nil(t, err)
+	defer adapter.Close()
+
+	_, _, err = adapter.Exec(ctx, "DROP TABLE IF EXISTS inspects;", nil)
+	assert.Nil(t, err)
+	_, _, err = adapter.Exec(ctx, "CREATE TABLE inspects (id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY, name VARCHAR(255), INDEX inspects_name (name));", nil)
+	assert.Nil(t, err)
+
+	tables, err := adapter.InspectTables(ctx)
+	assert.Nil(t, err)
+
+	found := false
+	for _, table := range tables {
+		if table.Name == "inspects" {
+			found = true
+			assert.Equal(t, []string{"id", "name"}, table.Columns)
+			assert.Contains(t, table.Indexes, migrator.IndexInfo{Name: "inspects_name", Columns: []string{"name"}})
+		}
+	}
+
+	assert.True(t, found)
+
+	_, _, err = adapter.Exec(ctx, "DROP TABLE inspects;", nil)
+	assert.Nil(t, err)
+}
+
+func TestAdapter_Lock(t *testing.T) {
+	adapter, err := Open(dsn())
+	assert.Nil(t, err)
+	defer adapter.Close()
+
+	unlock, err := adapter.Lock(ctx, "schema_migrations")
+	assert.Nil(t, err)
+
+	// lock is held by another connection.
+	timeout, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+
+	_, err = adapter.Lock(timeout, "schema_migrations")
+	assert.NotNil(t, err)
+
+	// different name is not blocked.
+	unlockOther, err := adapter.Lock(ctx, "analytics_schema_migrations")
+	assert.Nil(t, err)
+	assert.Nil(t, unlockOther())
+
+	assert.Nil(t, unlock())
+
+	unlock, err = adapter.Lock(ctx, "schema_migrations")
+	assert.Nil(t, err)
+	assert.Nil(t, unlock())
+}
+
+func TestErrorFunc(t *testing.T) {
+	var (
+		errUnique      = errors.New("Error 1062: Duplicate entry 'foo' for key 'slug'")
+		errForeignKey  = errors.New("Error 1452: Cannot add or update a child row: a foreign key constraint fails (`rel_test`.`extras`, CONSTRAINT `extras_user_id_fk` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`))")
+		errParentInUse = errors.New("Error 1451: Cannot delete or update a parent row: a foreign key constraint fails (`rel_test`.`extras`, CONSTRAINT `extras_user_id_fk` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`))")
+		errCheck       = errors.New("Error 3819: Check constraint 'extras_score_check' is violated.")
+		errDeadlock    = errors.New("Error 1213: Deadlock found when trying to get lock; try restarting transaction")
+		errOther       = errors.New("Error 1146: Table 'rel_test.foo' doesn't exist")
+	)
+
+	assert.Nil(t, errorFunc(nil))
+	assert.Equal(t, rel.ConstraintError{Key: "slug", Type: rel.UniqueConstraint, Err: errUnique}, errorFunc(errUnique))
+	assert.Equal(t, rel.ConstraintError{Key: "extras_user_id_fk", Type: rel.ForeignKeyConstraint, Err: errForeignKey}, errorFunc(errForeignKey))
+	assert.Equal(t, rel.ConstraintError{Key: "extras_user_id_fk", Type: rel.ForeignKeyConstraint, Err: errParentInUse}, errorFunc(errParentInUse))
+	assert.Equal(t, rel.ConstraintError{Key: "extras_score_check", Type: rel.CheckConstraint, Err: errCheck}, errorFunc(errCheck))
+	assert.Equal(t, rel.SerializationError{Err: errDeadlock}, errorFunc(errDeadlock))
+	assert.Equal(t, errOther, errorFunc(errOther))
+}
+
+func TestLoadDataStatement(t *testing.T) {
+	assert.Equal(t, "LOAD DATA LOCAL INFILE 'Reader::rel_1' INTO TABLE `users` CHARACTER SET utf8mb4 (`name`,`age`);",
+		loadDataStatement("rel_1", "users", []string{"name", "age"}))
+}
+
+func TestOnConflictFunc(t *testing.T) {
+	assert.Equal(t, " ON DUPLICATE KEY UPDATE `id`=LAST_INSERT_ID(`id`)",
+		onConflictFunc(rel.OnConflictIgnore(), []string{"id", "name"}))
+	assert.Equal(t, " ON DUPLICATE KEY UPDATE `id`=LAST_INSERT_ID(`id`),`name`=VALUES(`name`),`age`=VALUES(`age`)",
+		onConflictFunc(rel.OnConflictReplace(), []string{"id", "name", "age"}))
+}
+
+func TestEncodeRows(t *testing.T) {
+	var (
+		fields       = []string{"name", "active", "note", "created_at", "age"}
+		bulkModifies = []map[string]rel.Modify{
+			{
+				"name":       rel.Set("name", "a\tb\nc\\d"),
+				"active":     rel.Set("active", true),
+				"note":       rel.Set("note", nil),
+				"created_at": rel.Set("created_at", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)),
+				"age":        rel.Set("age", 10),
+			},
+		}
+	)
+
+	assert.Equal(t, "a\\tb\\nc\\\\d\t1\t\\N\t2020-01-02 03:04:05\t10\n", string(encodeRows(fields, bulkModifies)))
+}
+
+func TestIsolationFunc(t *testing.T) {
+	assert.Equal(t, "SET TRANSACTION ISOLATION LEVEL READ COMMITTED;", isolationFunc(db.LevelReadCommitted))
+	assert.Equal(t, "SET TRANSACTION ISOLATION LEVEL SERIALIZABLE;", isolationFunc(db.LevelSerializable))
+	assert.Equal(t, "", isolationFunc(db.LevelSnapshot))
+}
@@ -0,0 +1,197 @@
+// Package oracle wraps oracle (godror) driver as an adapter for REL.
+//
+// The driver itself is not imported by this package, import it in the main package instead:
+//
+//	import _ "github.com/godror/godror"
+//
+// Identifiers are not quoted, thus unquoted table and column names created in oracle are matched case insensitively.
+// Pagination uses OFFSET .. FETCH NEXT clause which is supported since Oracle 12c.
+//
+// Usage:
+//
+//	// open oracle connection.
+//	adapter, err := oracle.Open(`user="rel" password="rel" connectString="localhost:1521/XEPDB1"`)
+//	if err != nil {
+//		panic(err)
+//	}
+//	defer adapter.Close()
+//
+//	// initialize REL's repo.
+//	repo := rel.New(adapter)
+package oracle
+
+import (
+	"context"
+	db "database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/adapter/sql"
+)
+
+// Adapter definition for oracle database.
+type Adapter struct {
+	*sql.Adapter
+	savepoint int
+}
+
+var _ rel.Adapter = (*Adapter)(nil)
+
+// New oracle adapter using existing connection.
+func New(database *db.DB) *Adapter {
+	return &Adapter{
+		Adapter: &sql.Adapter{
+			Config: &sql.Config{
+				Placeholder: ":",
+				Ordinal:     true,
+				NoSemicolon: true,
+				OffsetFetch: true,
+				ErrorFunc:   errorFunc,
+			},
+			DB: database,
+		},
+	}
+}
+
+// Open oracle connection using dsn.
+func Open(dsn string) (*Adapter, error) {
+	database, err := db.Open("godror", dsn)
+	return New(database), err
+}
+
+// Insert inserts a record to database and returns its id.
+func (adapter *Adapter) Insert(ctx context.Context, query rel.Query, modifies map[string]rel.Modify, loggers ...rel.Logger) (interface{}, error) {
+	var (
+		id              int64
+		statement, args = sql.NewBuilder(adapter.Config).Returning("id").Insert(query.Table, modifies)
+	)
+
+	statement += " INTO " + adapter.Config.Placeholder + strconv.Itoa(len(args)+1)
+	args = append(args, db.Out{Dest: &id})
+
+	_, _, err := adapter.Exec(ctx, statement, args, loggers...)
+
+	return id, err
+}
+
+// InsertAll inserts multiple records to database and returns its ids.
+// Records are inserted one by one since returning generated ids is only supported for single row insert.
+func (adapter *Adapter) InsertAll(ctx context.Context, query rel.Query, fields []string, bulkModifies []map[string]rel.Modify, loggers ...rel.Logger) ([]interface{}, error) {
+	var (
+		ids = make([]interface{}, len(bulkModifies))
+	)
+
+	for i, modifies := range bulkModifies {
+		id, err := adapter.Insert(ctx, query, modifies, loggers...)
+		if err != nil {
+			return nil, err
+		}
+
+		ids[i] = id
+	}
+
+	return ids, nil
+}
+
+// Begin begins a new transaction.
+func (adapter *Adapter) Begin(ctx context.Context) (rel.Adapter, error) {
+	if adapter.Tx == nil {
+		options := rel.TransactionOptionsFrom(ctx)
+		tx, err := adapter.DB.BeginTx(ctx, &db.TxOptions{Isolation: options.Isolation, ReadOnly: options.ReadOnly})
+
+		return &Adapter{
+			Adapter: &sql.Adapter{
+				Config: adapter.Config,
+				Tx:     tx,
+			},
+		}, err
+	}
+
+	var (
+		savepoint = adapter.savepoint + 1
+		_, _, err = adapter.Exec(ctx, "SAVEPOINT s"+strconv.Itoa(savepoint), nil)
+	)
+
+	return &Adapter{
+		Adapter: &sql.Adapter{
+			Config: adapter.Config,
+			Tx:     adapter.Tx,
+		},
+		savepoint: savepoint,
+	}, err
+}
+
+// Commit commits current transaction.
+// Oracle doesn't support releasing savepoint, thus committing nested transaction is a no-op.
+func (adapter *Adapter) Commit(ctx context.Context) error {
+	if adapter.savepoint > 0 {
+		return nil
+	}
+
+	return adapter.Adapter.Commit(ctx)
+}
+
+// Rollback revert current transaction.
+func (adapter *Adapter) Rollback(ctx context.Context) error {
+	if adapter.savepoint > 0 {
+		_, _, err := adapter.Exec(ctx, "ROLLBACK TO SAVEPOINT s"+strconv.Itoa(adapter.savepoint), nil)
+		return err
+	}
+
+	return adapter.Adapter.Rollback(ctx)
+}
+
+func errorFunc(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var (
+		msg = err.Error()
+	)
+
+	switch {
+	case strings.Contains(msg, "ORA-08177"), strings.Contains(msg, "ORA-00060"):
+		return rel.SerializationError{Err: err}
+	case strings.Contains(msg, "ORA-00001"):
+		return rel.ConstraintError{
+			Key:  constraintKey(msg),
+			Type: rel.UniqueConstraint,
+			Err:  err,
+		}
+	case strings.Contains(msg, "ORA-02291"), strings.Contains(msg, "ORA-02292"):
+		return rel.ConstraintError{
+			Key:  constraintKey(msg),
+			Type: rel.ForeignKeyConstraint,
+			Err:  err,
+		}
+	case strings.Contains(msg, "ORA-02290"):
+		return rel.ConstraintError{
+			Key:  constraintKey(msg),
+			Type: rel.CheckConstraint,
+			Err:  err,
+		}
+	case strings.Contains(msg, "ORA-01400"):
+		return rel.ConstraintError{
+			Key:  constraintKey(msg),
+			Type: rel.NotNullConstraint,
+			Err:  err,
+		}
+	default:
+		return err
+	}
+}
+
+// constraintKey extracts the last part of qualified name in parentheses, eg: (REL.USERS_SLUG) or ("REL"."USERS"."NAME").
+func constraintKey(msg string) string {
+	var (
+		name = sql.ExtractString(msg, "(", ")")
+	)
+
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+
+	return strings.Trim(name, "\"")
+}
@@ -0,0 +1,63 @@
+package oracle
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/adapter/sql"
+	"github.com/Fs02/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+var ctx = context.TODO()
+
+func TestNew_config(t *testing.T) {
+	var (
+		adapter = New(nil)
+	)
+
+	qs, args := sql.NewBuilder(adapter.Config).Find(rel.From("users").Where(where.Eq("id", 1)).SortAsc("id").Offset(10).Limit(5))
+	assert.Equal(t, "SELECT * FROM users WHERE id=:1 ORDER BY id ASC OFFSET 10 ROWS FETCH NEXT 5 ROWS ONLY", qs)
+	assert.Equal(t, []interface{}{1}, args)
+
+	qs, args = sql.NewBuilder(adapter.Config).Returning("id").Insert("users", map[string]rel.Modify{"name": rel.Set("name", "foo")})
+	assert.Equal(t, "INSERT INTO users (name) VALUES (:1) RETURNING id", qs)
+	assert.Equal(t, []interface{}{"foo"}, args)
+}
+
+func TestAdapter_Commit_savepoint(t *testing.T) {
+	var (
+		adapter = &Adapter{Adapter: New(nil).Adapter, savepoint: 1}
+	)
+
+	assert.Nil(t, adapter.Commit(ctx))
+}
+
+func TestAdapter_Commit_outsideTransaction(t *testing.T) {
+	assert.Equal(t, errors.New("unable to commit outside transaction"), New(nil).Commit(ctx))
+}
+
+func TestErrorFunc(t *testing.T) {
+	var (
+		errUnique    = errors.New("ORA-00001: unique constraint (REL.USERS_SLUG) violated")
+		errParentKey = errors.New("ORA-02291: integrity constraint (REL.ADDRESSES_USER_ID_FK) violated - parent key not found")
+		errChildKey  = errors.New("ORA-02292: integrity constraint (REL.ADDRESSES_USER_ID_FK) violated - child record found")
+		errCheck     = errors.New("ORA-02290: check constraint (REL.EXTRAS_SCORE_CHECK) violated")
+		errNotNull   = errors.New("ORA-01400: cannot insert NULL into (\"REL\".\"USERS\".\"NAME\")")
+		errSerialize = errors.New("ORA-08177: can't serialize access for this transaction")
+		errDeadlock  = errors.New("ORA-00060: deadlock detected while waiting for resource")
+		errOther     = errors.New("ORA-00942: table or view does not exist")
+	)
+
+	assert.Nil(t, errorFunc(nil))
+	assert.Equal(t, errOther, errorFunc(errOther))
+	assert.Equal(t, rel.ConstraintError{Key: "USERS_SLUG", Type: rel.UniqueConstraint, Err: errUnique}, errorFunc(errUnique))
+	assert.Equal(t, rel.ConstraintError{Key: "ADDRESSES_USER_ID_FK", Type: rel.ForeignKeyConstraint, Err: errParentKey}, errorFunc(errParentKey))
+	assert.Equal(t, rel.ConstraintError{Key: "ADDRESSES_USER_ID_FK", Type: rel.ForeignKeyConstraint, Err: errChildKey}, errorFunc(errChildKey))
+	assert.Equal(t, rel.ConstraintError{Key: "EXTRAS_SCORE_CHECK", Type: rel.CheckConstraint, Err: errCheck}, errorFunc(errCheck))
+	assert.Equal(t, rel.ConstraintError{Key: "NAME", Type: rel.NotNullConstraint, Err: errNotNull}, errorFunc(errNotNull))
+	assert.Equal(t, rel.SerializationError{Err: errSerialize}, errorFunc(errSerialize))
+	assert.Equal(t, rel.SerializationError{Err: errDeadlock}, errorFunc(errDeadlock))
+}
@@ -0,0 +1,35 @@
+package pgx
+
+import (
+	"github.com/jackc/pgx/v4"
+)
+
+// Cursor used for retrieving result.
+type Cursor struct {
+	pgx.Rows
+}
+
+// Close rows.
+func (c *Cursor) Close() error {
+	c.Rows.Close()
+	return nil
+}
+
+// Fields returned in the result.
+func (c *Cursor) Fields() ([]string, error) {
+	var (
+		descriptions = c.FieldDescriptions()
+		fields       = make([]string, len(descriptions))
+	)
+
+	for i := range descriptions {
+		fields[i] = string(descriptions[i].Name)
+	}
+
+	return fields, nil
+}
+
+// NopScanner for this adapter, nil destination is skipped by pgx.
+func (c *Cursor) NopScanner() interface{} {
+	return nil
+}
@@ -0,0 +1,47 @@
+package pgx
+
+import (
+	"context"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// Listen for notification on the channel using dedicated connection.
+// It blocks until the context is canceled or fn returns error.
+func (adapter *Adapter) Listen(ctx context.Context, channel string, fn func(*pgconn.Notification) error) error {
+	conn, err := adapter.Pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Release()
+
+	var (
+		identifier = pgx.Identifier{channel}.Sanitize()
+	)
+
+	if _, err := conn.Exec(ctx, "LISTEN "+identifier+";"); err != nil {
+		return err
+	}
+
+	// the connection will be reused by the pool, stop listening when it's still usable.
+	defer conn.Exec(context.Background(), "UNLISTEN "+identifier+";")
+
+	for {
+		notification, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(notification); err != nil {
+			return err
+		}
+	}
+}
+
+// Notify sends notification with payload to the channel.
+func (adapter *Adapter) Notify(ctx context.Context, channel string, payload string) error {
+	_, err := adapter.Exec(ctx, "SELECT pg_notify($1, $2);", []interface{}{channel, payload})
+	return err
+}
@@ -0,0 +1,363 @@
+// Package pgx wraps native pgx connection pool as an adapter for REL.
+//
+// Unlike postgres adapter, it doesn't use database/sql and communicates using binary protocol.
+// It also gives access to batch and listen/notify.
+//
+// Usage:
+//	// open pgx connection pool.
+//	adapter, err := pgx.Open(ctx, "postgres://postgres@localhost/rel_test?sslmode=disable")
+//	if err != nil {
+//		panic(err)
+//	}
+//	defer adapter.Close()
+//
+//	// initialize REL's repo.
+//	repo := rel.New(adapter)
+package pgx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Fs02/rel"
+	relsql "github.com/Fs02/rel/adapter/sql"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+var isolationLevels = map[sql.IsolationLevel]pgx.TxIsoLevel{
+	sql.LevelDefault:         "",
+	sql.LevelReadUncommitted: pgx.ReadUncommitted,
+	sql.LevelReadCommitted:   pgx.ReadCommitted,
+	sql.LevelRepeatableRead:  pgx.RepeatableRead,
+	sql.LevelSerializable:    pgx.Serializable,
+}
+
+type querier interface {
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+// Adapter definition for pgx.
+type Adapter struct {
+	Config *relsql.Config
+	Pool   *pgxpool.Pool
+	Tx     pgx.Tx
+}
+
+var _ rel.Adapter = (*Adapter)(nil)
+
+// New pgx adapter using existing connection pool.
+func New(pool *pgxpool.Pool) *Adapter {
+	return &Adapter{
+		Config: &relsql.Config{
+			Placeholder:         "$",
+			EscapeChar:          "\"",
+			Ordinal:             true,
+			InsertDefaultValues: true,
+			ErrorFunc:           errorFunc,
+		},
+		Pool: pool,
+	}
+}
+
+// Open pgx connection pool using dsn.
+func Open(ctx context.Context, dsn string) (*Adapter, error) {
+	pool, err := pgxpool.Connect(ctx, dsn)
+	return New(pool), err
+}
+
+// Close connection pool.
+func (adapter *Adapter) Close() error {
+	adapter.Pool.Close()
+	return nil
+}
+
+// Capabilities of the adapter.
+func (adapter *Adapter) Capabilities() rel.Capabilities {
+	return rel.TransactionCapability | rel.SavepointCapability | rel.JoinCapability | rel.GroupCapability | rel.TwoPhaseCommitCapability | rel.WindowCapability
+}
+
+// Stats returns connection pool statistics.
+// Adapter that is used inside transaction returns zero value since it doesn't own the connection pool.
+func (adapter *Adapter) Stats() sql.DBStats {
+	if adapter.Pool == nil {
+		return sql.DBStats{}
+	}
+
+	stat := adapter.Pool.Stat()
+
+	return sql.DBStats{
+		MaxOpenConnections: int(stat.MaxConns()),
+		OpenConnections:    int(stat.TotalConns()),
+		InUse:              int(stat.AcquiredConns()),
+		Idle:               int(stat.IdleConns()),
+		WaitCount:          stat.EmptyAcquireCount(),
+		WaitDuration:       stat.AcquireDuration(),
+	}
+}
+
+// Ping database.
+func (adapter *Adapter) Ping(ctx context.Context) error {
+	return adapter.Pool.Ping(ctx)
+}
+
+// Aggregate record using given query.
+func (adapter *Adapter) Aggregate(ctx context.Context, query rel.Query, mode string, field string, loggers ...rel.Logger) (int, error) {
+	var (
+		out             sql.NullInt64
+		statement, args = relsql.NewBuilder(adapter.Config).Aggregate(query, mode, field)
+		start           = time.Now()
+		err             = adapter.querier().QueryRow(ctx, statement, args...).Scan(&out)
+	)
+
+	rel.LogFunc(ctx, loggers, func() string { return statement }, time.Since(start), err)
+
+	return int(out.Int64), adapter.Config.ErrorFunc(err)
+}
+
+// Query performs query operation.
+func (adapter *Adapter) Query(ctx context.Context, query rel.Query, loggers ...rel.Logger) (rel.Cursor, error) {
+	var (
+		statement, args = relsql.NewBuilder(adapter.Config).Find(query)
+		rows, err       = adapter.query(ctx, statement, args, loggers)
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cursor{Rows: rows}, nil
+}
+
+// Exec performs exec operation and returns the number of affected rows.
+func (adapter *Adapter) Exec(ctx context.Context, statement string, args []interface{}, loggers ...rel.Logger) (int64, error) {
+	var (
+		start    = time.Now()
+		tag, err = adapter.querier().Exec(ctx, statement, args...)
+	)
+
+	rel.LogFunc(ctx, loggers, func() string { return statement }, time.Since(start), err)
+
+	return tag.RowsAffected(), adapter.Config.ErrorFunc(err)
+}
+
+// Insert inserts a record to database and returns its id.
+func (adapter *Adapter) Insert(ctx context.Context, query rel.Query, modifies map[string]rel.Modify, loggers ...rel.Logger) (interface{}, error) {
+	var (
+		id              int64
+		statement, args = relsql.NewBuilder(adapter.Config).Returning("id").Insert(query.Table, modifies)
+		start           = time.Now()
+		err             = adapter.querier().QueryRow(ctx, statement, args...).Scan(&id)
+	)
+
+	rel.LogFunc(ctx, loggers, func() string { return statement }, time.Since(start), err)
+
+	return id, adapter.Config.ErrorFunc(err)
+}
+
+// InsertAll inserts multiple records to database and returns its ids.
+func (adapter *Adapter) InsertAll(ctx context.Context, query rel.Query, fields []string, bulkModifies []map[string]rel.Modify, loggers ...rel.Logger) ([]interface{}, error) {
+	var (
+		ids             []interface{}
+		statement, args = relsql.NewBuilder(adapter.Config).Returning("id").InsertAll(query.Table, fields, bulkModifies)
+		rows, err       = adapter.query(ctx, statement, args, loggers)
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, adapter.Config.ErrorFunc(rows.Err())
+}
+
+// Update updates a record in database.
+func (adapter *Adapter) Update(ctx context.Context, query rel.Query, modifies map[string]rel.Modify, loggers ...rel.Logger) (int, error) {
+	var (
+		statement, args   = relsql.NewBuilder(adapter.Config).Update(query.Table, modifies, query.WhereQuery)
+		updatedCount, err = adapter.Exec(ctx, statement, args, loggers...)
+	)
+
+	return int(updatedCount), err
+}
+
+// Delete deletes all results that match the query.
+func (adapter *Adapter) Delete(ctx context.Context, query rel.Query, loggers ...rel.Logger) (int, error) {
+	var (
+		statement, args   = relsql.NewBuilder(adapter.Config).Delete(query.Table, query.WhereQuery)
+		deletedCount, err = adapter.Exec(ctx, statement, args, loggers...)
+	)
+
+	return int(deletedCount), err
+}
+
+// SendBatch sends all queued queries in a single round trip.
+func (adapter *Adapter) SendBatch(ctx context.Context, batch *pgx.Batch) pgx.BatchResults {
+	return adapter.querier().SendBatch(ctx, batch)
+}
+
+// Begin begins a new transaction.
+// Nested transaction is implemented by pgx using savepoint.
+func (adapter *Adapter) Begin(ctx context.Context) (rel.Adapter, error) {
+	var (
+		tx      pgx.Tx
+		err     error
+		options = rel.TransactionOptionsFrom(ctx)
+	)
+
+	isoLevel, supported := isolationLevels[options.Isolation]
+
+	switch {
+	case adapter.Tx != nil && options.Isolation != sql.LevelDefault:
+		err = errors.New("pgx: isolation level can't be changed inside transaction")
+	case adapter.Tx != nil:
+		tx, err = adapter.Tx.Begin(ctx)
+	case !supported:
+		err = errors.New("pgx: isolation level " + options.Isolation.String() + " is not supported")
+	default:
+		txOptions := pgx.TxOptions{IsoLevel: isoLevel}
+		if options.ReadOnly {
+			txOptions.AccessMode = pgx.ReadOnly
+		}
+
+		tx, err = adapter.Pool.BeginTx(ctx, txOptions)
+	}
+
+	if err == nil && options.StatementTimeout > 0 {
+		// SET LOCAL only lasts until the end of current transaction.
+		if _, err = tx.Exec(ctx, "SET LOCAL statement_timeout = "+strconv.FormatInt(int64(options.StatementTimeout/time.Millisecond), 10)); err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}
+
+	return &Adapter{
+		Config: adapter.Config,
+		Tx:     tx,
+	}, err
+}
+
+// Prepare current transaction for two-phase commit using PREPARE TRANSACTION.
+// Requires max_prepared_transactions to be configured in the database server.
+func (adapter *Adapter) Prepare(ctx context.Context, id string) error {
+	if adapter.Tx == nil {
+		return errors.New("unable to prepare outside transaction")
+	}
+
+	if _, err := adapter.Exec(ctx, "PREPARE TRANSACTION "+quoteLiteral(id), nil); err != nil {
+		return err
+	}
+
+	// prepared transaction is no longer in progress, commit only releases the connection.
+	return adapter.Commit(ctx)
+}
+
+// CommitPrepared commits transaction that was prepared using the given id.
+func (adapter *Adapter) CommitPrepared(ctx context.Context, id string) error {
+	_, err := adapter.Exec(ctx, "COMMIT PREPARED "+quoteLiteral(id), nil)
+	return err
+}
+
+// RollbackPrepared rolls back transaction that was prepared using the given id.
+func (adapter *Adapter) RollbackPrepared(ctx context.Context, id string) error {
+	_, err := adapter.Exec(ctx, "ROLLBACK PREPARED "+quoteLiteral(id), nil)
+	return err
+}
+
+// Commit commits current transaction.
+func (adapter *Adapter) Commit(ctx context.Context) error {
+	if adapter.Tx == nil {
+		return errors.New("unable to commit outside transaction")
+	}
+
+	return adapter.Config.ErrorFunc(adapter.Tx.Commit(ctx))
+}
+
+// Rollback revert current transaction.
+func (adapter *Adapter) Rollback(ctx context.Context) error {
+	if adapter.Tx == nil {
+		return errors.New("unable to rollback outside transaction")
+	}
+
+	return adapter.Config.ErrorFunc(adapter.Tx.Rollback(ctx))
+}
+
+func (adapter *Adapter) querier() querier {
+	if adapter.Tx != nil {
+		return adapter.Tx
+	}
+
+	return adapter.Pool
+}
+
+func (adapter *Adapter) query(ctx context.Context, statement string, args []interface{}, loggers []rel.Logger) (pgx.Rows, error) {
+	var (
+		start     = time.Now()
+		rows, err = adapter.querier().Query(ctx, statement, args...)
+	)
+
+	rel.LogFunc(ctx, loggers, func() string { return statement }, time.Since(start), err)
+
+	return rows, adapter.Config.ErrorFunc(err)
+}
+
+func quoteLiteral(literal string) string {
+	return "'" + strings.Replace(literal, "'", "''", -1) + "'"
+}
+
+func errorFunc(err error) error {
+	var (
+		pgErr *pgconn.PgError
+	)
+
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch pgErr.Code {
+	case "40001", "40P01":
+		return rel.SerializationError{Err: err}
+	case "23505":
+		return rel.ConstraintError{
+			Key:  pgErr.ConstraintName,
+			Type: rel.UniqueConstraint,
+			Err:  err,
+		}
+	case "23503":
+		return rel.ConstraintError{
+			Key:  pgErr.ConstraintName,
+			Type: rel.ForeignKeyConstraint,
+			Err:  err,
+		}
+	case "23514":
+		return rel.ConstraintError{
+			Key:  pgErr.ConstraintName,
+			Type: rel.CheckConstraint,
+			Err:  err,
+		}
+	case "23502":
+		return rel.ConstraintError{
+			Key:  pgErr.ColumnName,
+			Type: rel.NotNullConstraint,
+			Err:  err,
+		}
+	default:
+		return err
+	}
+}
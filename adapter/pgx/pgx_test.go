@@ -0,0 +1,228 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Fs02/go-paranoid"
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/adapter/specs"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+var ctx = context.TODO()
+
+func init() {
+	adapter, err := Open(ctx, dsn())
+	paranoid.Panic(err, "failed to open database connection")
+	defer adapter.Close()
+
+	_, err = adapter.Exec(ctx, `DROP TABLE IF EXISTS extras;`, nil)
+	paranoid.Panic(err, "failed dropping extras table")
+	_, err = adapter.Exec(ctx, `DROP TABLE IF EXISTS addresses;`, nil)
+	paranoid.Panic(err, "failed dropping addresses table")
+	_, err = adapter.Exec(ctx, `DROP TABLE IF EXISTS users;`, nil)
+	paranoid.Panic(err, "failed dropping users table")
+
+	_, err = adapter.Exec(ctx, `CREATE TABLE users (
+		id SERIAL NOT NULL PRIMARY KEY,
+		slug VARCHAR(30) DEFAULT NULL,
+		name VARCHAR(30) NOT NULL DEFAULT '',
+		gender VARCHAR(10) NOT NULL DEFAULT '',
+		age INT NOT NULL DEFAULT 0,
+		note varchar(50),
+		created_at TIMESTAMPTZ,
+		updated_at TIMESTAMPTZ,
+		UNIQUE(slug)
+	);`, nil)
+	paranoid.Panic(err, "failed creating users table")
+
+	_, err = adapter.Exec(ctx, `CREATE TABLE addresses (
+		id SERIAL NOT NULL PRIMARY KEY,
+		user_id INTEGER REFERENCES users(id),
+		name VARCHAR(60) NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ,
+		updated_at TIMESTAMPTZ
+	);`, nil)
+	paranoid.Panic(err, "failed creating addresses table")
+
+	_, err = adapter.Exec(ctx, `CREATE TABLE extras (
+		id SERIAL NOT NULL PRIMARY KEY,
+		slug VARCHAR(30) DEFAULT NULL UNIQUE,
+		user_id INTEGER REFERENCES users(id),
+		score INTEGER DEFAULT 0 CHECK (score>=0 AND score<=100)
+	);`, nil)
+	paranoid.Panic(err, "failed creating extras table")
+
+	// hack to make sure location it has the same location object as returned by pgx.
+	time.Local, err = time.LoadLocation("Asia/Jakarta")
+	paranoid.Panic(err, "failed loading time location")
+}
+
+func dsn() string {
+	if os.Getenv("POSTGRESQL_DATABASE") != "" {
+		return os.Getenv("POSTGRESQL_DATABASE") + "?sslmode=disable&timezone=Asia/Jakarta"
+	}
+
+	return "postgres://rel@localhost:9920/rel_test?sslmode=disable&timezone=Asia/Jakarta"
+}
+
+func TestAdapter_specs(t *testing.T) {
+	adapter, err := Open(ctx, dsn())
+	paranoid.Panic(err, "failed to open database connection")
+	defer adapter.Close()
+
+	repo := rel.New(adapter)
+
+	// Query Specs
+	specs.Query(t, repo)
+	specs.QueryJoin(t, repo)
+	specs.QueryNotFound(t, repo)
+
+	// Preload specs
+	specs.PreloadHasMany(t, repo)
+	specs.PreloadHasOne(t, repo)
+	specs.PreloadBelongsTo(t, repo)
+
+	// Aggregate Specs
+	specs.Aggregate(t, repo)
+
+	// Insert Specs
+	specs.Insert(t, repo)
+	specs.InsertHasMany(t, repo)
+	specs.InsertHasOne(t, repo)
+	specs.InsertBelongsTo(t, repo)
+	specs.Inserts(t, repo)
+	specs.InsertAll(t, repo)
+
+	// Update Specs
+	specs.Update(t, repo)
+	specs.UpdateNotFound(t, repo)
+	specs.UpdateAtomic(t, repo)
+	specs.Updates(t, repo)
+
+	// Delete specs
+	specs.Delete(t, repo)
+	specs.DeleteAll(t, repo)
+
+	// Constraint specs
+	specs.UniqueConstraint(t, repo)
+	specs.ForeignKeyConstraint(t, repo)
+	specs.CheckConstraint(t, repo)
+}
+
+func TestAdapter_Transaction(t *testing.T) {
+	adapter, err := Open(ctx, dsn())
+	paranoid.Panic(err, "failed to open database connection")
+	defer adapter.Close()
+
+	assert.NotNil(t, adapter.Commit(ctx))
+	assert.NotNil(t, adapter.Rollback(ctx))
+
+	tx, err := adapter.Begin(ctx)
+	assert.Nil(t, err)
+
+	nested, err := tx.Begin(ctx)
+	assert.Nil(t, err)
+	assert.Nil(t, nested.Rollback(ctx))
+	assert.Nil(t, tx.Commit(ctx))
+}
+
+func TestAdapter_SendBatch(t *testing.T) {
+	adapter, err := Open(ctx, dsn())
+	paranoid.Panic(err, "failed to open database connection")
+	defer adapter.Close()
+
+	var (
+		batch = &pgx.Batch{}
+		one   int
+		two   int
+	)
+
+	batch.Queue("SELECT 1;")
+	batch.Queue("SELECT 2;")
+
+	results := adapter.SendBatch(ctx, batch)
+	assert.Nil(t, results.QueryRow().Scan(&one))
+	assert.Nil(t, results.QueryRow().Scan(&two))
+	assert.Nil(t, results.Close())
+	assert.Equal(t, 1, one)
+	assert.Equal(t, 2, two)
+}
+
+func TestAdapter_ListenNotify(t *testing.T) {
+	adapter, err := Open(ctx, dsn())
+	paranoid.Panic(err, "failed to open database connection")
+	defer adapter.Close()
+
+	var (
+		errDone     = errors.New("done")
+		payload     = make(chan string, 1)
+		ctx, cancel = context.WithTimeout(ctx, 5*time.Second)
+	)
+
+	defer cancel()
+
+	go func() {
+		for {
+			if err := adapter.Notify(ctx, "rel_test", "hello"); err != nil {
+				return
+			}
+
+			time.Sleep(100 * time.Millisecond)
+		}
+	}()
+
+	assert.Equal(t, errDone, adapter.Listen(ctx, "rel_test", func(n *pgconn.Notification) error {
+		payload <- n.Payload
+		return errDone
+	}))
+	assert.Equal(t, "hello", <-payload)
+}
+
+func TestAdapter_Stats(t *testing.T) {
+	adapter, err := Open(ctx, dsn())
+	paranoid.Panic(err, "failed to open database connection")
+	defer adapter.Close()
+
+	assert.NotZero(t, adapter.Stats().MaxOpenConnections)
+	assert.Zero(t, (&Adapter{}).Stats().MaxOpenConnections)
+}
+
+func TestErrorFunc(t *testing.T) {
+	var (
+		errUnique     = &pgconn.PgError{Code: "23505", ConstraintName: "users_slug_key"}
+		errForeignKey = &pgconn.PgError{Code: "23503", ConstraintName: "extras_user_id_fkey"}
+		errCheck      = &pgconn.PgError{Code: "23514", ConstraintName: "extras_score_check"}
+		errNotNull    = &pgconn.PgError{Code: "23502", ColumnName: "name"}
+		errSerialize  = &pgconn.PgError{Code: "40001"}
+		errDeadlock   = &pgconn.PgError{Code: "40P01"}
+		errOther      = &pgconn.PgError{Code: "42P01"}
+		errPlain      = errors.New("plain")
+	)
+
+	assert.Nil(t, errorFunc(nil))
+	assert.Equal(t, rel.ConstraintError{Key: "users_slug_key", Type: rel.UniqueConstraint, Err: errUnique}, errorFunc(errUnique))
+	assert.Equal(t, rel.ConstraintError{Key: "extras_user_id_fkey", Type: rel.ForeignKeyConstraint, Err: errForeignKey}, errorFunc(errForeignKey))
+	assert.Equal(t, rel.ConstraintError{Key: "extras_score_check", Type: rel.CheckConstraint, Err: errCheck}, errorFunc(errCheck))
+	assert.Equal(t, rel.ConstraintError{Key: "name", Type: rel.NotNullConstraint, Err: errNotNull}, errorFunc(errNotNull))
+	assert.Equal(t, rel.SerializationError{Err: errSerialize}, errorFunc(errSerialize))
+	assert.Equal(t, rel.SerializationError{Err: errDeadlock}, errorFunc(errDeadlock))
+	assert.Equal(t, errOther, errorFunc(errOther))
+	assert.Equal(t, errPlain, errorFunc(errPlain))
+}
+
+func TestAdapter_Prepare_outsideTransaction(t *testing.T) {
+	adapter := &Adapter{}
+	assert.Equal(t, errors.New("unable to prepare outside transaction"), adapter.Prepare(ctx, "tx-1"))
+}
+
+func TestQuoteLiteral(t *testing.T) {
+	assert.Equal(t, "'tx-1'", quoteLiteral("tx-1"))
+	assert.Equal(t, "'tx''1'", quoteLiteral("tx'1"))
+}
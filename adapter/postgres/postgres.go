@@ -15,10 +15,18 @@ package postgres
 import (
 	"context"
 	db "database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"hash/fnv"
+	"reflect"
+	"strconv"
 	"time"
 
 	"github.com/Fs02/rel"
 	"github.com/Fs02/rel/adapter/sql"
+	"github.com/Fs02/rel/migrator"
+	"github.com/lib/pq"
 )
 
 // Adapter definition for postgrees database.
@@ -28,24 +36,35 @@ type Adapter struct {
 
 var _ rel.Adapter = (*Adapter)(nil)
 
-// Open postgrees connection using dsn.
-func Open(dsn string) (*Adapter, error) {
-	var err error
-
-	adapter := &Adapter{
+// New postgres adapter using existing connection.
+// Use it together with sql.Connector to connect using rotating credential.
+func New(database *db.DB) *Adapter {
+	return &Adapter{
 		Adapter: &sql.Adapter{
 			Config: &sql.Config{
-				Placeholder:         "$",
-				EscapeChar:          "\"",
-				Ordinal:             true,
-				InsertDefaultValues: true,
-				ErrorFunc:           errorFunc,
+				Placeholder:          "$",
+				EscapeChar:           "\"",
+				Ordinal:              true,
+				InsertDefaultValues:  true,
+				ErrorFunc:            errorFunc,
+				ArgumentFunc:         argumentFunc,
+				StatementTimeoutFunc: statementTimeoutFunc,
+				MapColumnFunc:        mapColumnFunc,
+				InspectTablesFunc:    inspectTablesFunc,
+				LockFunc:             lockFunc,
+				BulkLoadThreshold:    sql.DefaultBulkLoadThreshold,
+				InArrayThreshold:     sql.DefaultInArrayThreshold,
+				Capabilities:         rel.TwoPhaseCommitCapability | rel.TransactionalDDLCapability | rel.WindowCapability,
 			},
+			DB: database,
 		},
 	}
-	adapter.DB, err = db.Open("postgres", dsn)
+}
 
-	return adapter, err
+// Open postgrees connection using dsn.
+func Open(dsn string) (*Adapter, error) {
+	database, err := db.Open("postgres", dsn)
+	return New(database), err
 }
 
 // Insert inserts a record to database and returns its id.
@@ -65,7 +84,12 @@ func (adapter *Adapter) Insert(ctx context.Context, query rel.Query, modifies ma
 }
 
 // InsertAll inserts multiple records to database and returns its ids.
+// Records are inserted using COPY when the number of records reach BulkLoadThreshold.
 func (adapter *Adapter) InsertAll(ctx context.Context, query rel.Query, fields []string, bulkModifies []map[string]rel.Modify, loggers ...rel.Logger) ([]interface{}, error) {
+	if sql.BulkLoadable(adapter.Config.BulkLoadThreshold, fields, bulkModifies) {
+		return adapter.copyIn(ctx, query.Table, fields, bulkModifies, loggers)
+	}
+
 	var (
 		ids             []interface{}
 		statement, args = sql.NewBuilder(adapter.Config).Returning("id").InsertAll(query.Table, fields, bulkModifies)
@@ -84,6 +108,129 @@ func (adapter *Adapter) InsertAll(ctx context.Context, query rel.Query, fields [
 	return ids, err
 }
 
+// copyIn inserts records using COPY FROM STDIN.
+// Since COPY doesn't return the inserted rows, ids are allocated beforehand from the table's serial sequence.
+func (adapter *Adapter) copyIn(ctx context.Context, table string, fields []string, bulkModifies []map[string]rel.Modify, loggers []rel.Logger) ([]interface{}, error) {
+	var (
+		err     error
+		tx      = adapter.Tx
+		ids     []interface{}
+		columns = fields
+	)
+
+	if tx == nil {
+		if tx, err = adapter.DB.BeginTx(ctx, nil); err != nil {
+			return nil, err
+		}
+
+		defer tx.Rollback()
+	}
+
+	if !containsString(fields, "id") {
+		if ids, err = allocateIDs(ctx, tx, table, len(bulkModifies), loggers); err != nil {
+			return nil, adapter.Config.ErrorFunc(err)
+		}
+
+		columns = append([]string{"id"}, fields...)
+	}
+
+	var (
+		statement = pq.CopyIn(table, columns...)
+		start     = time.Now()
+	)
+
+	if err = copyRows(ctx, tx, statement, fields, bulkModifies, ids, adapter.Config.ArgumentFunc); err == nil && adapter.Tx == nil {
+		err = tx.Commit()
+	}
+
+	rel.LogFunc(ctx, loggers, func() string { return statement }, time.Since(start), err)
+
+	if err != nil {
+		return nil, adapter.Config.ErrorFunc(err)
+	}
+
+	if ids == nil {
+		ids = make([]interface{}, len(bulkModifies))
+		for i := range bulkModifies {
+			ids[i] = bulkModifies[i]["id"].Value
+		}
+	}
+
+	return ids, nil
+}
+
+func allocateIDs(ctx context.Context, tx *db.Tx, table string, n int, loggers []rel.Logger) ([]interface{}, error) {
+	var (
+		ids       = make([]interface{}, 0, n)
+		statement = "SELECT nextval(pg_get_serial_sequence($1, 'id')) FROM generate_series(1, $2);"
+		start     = time.Now()
+		rows, err = tx.QueryContext(ctx, statement, table, n)
+	)
+
+	rel.LogFunc(ctx, loggers, func() string { return statement }, time.Since(start), err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func copyRows(ctx context.Context, tx *db.Tx, statement string, fields []string, bulkModifies []map[string]rel.Modify, ids []interface{}, argumentFunc func(interface{}) interface{}) error {
+	stmt, err := tx.PrepareContext(ctx, statement)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	var (
+		offset = 0
+		args   = make([]interface{}, len(fields)+1)
+	)
+
+	if ids == nil {
+		offset = 1
+	}
+
+	for i, modifies := range bulkModifies {
+		if ids != nil {
+			args[0] = ids[i]
+		}
+
+		for j, field := range fields {
+			args[j+1] = argumentFunc(modifies[field].Value)
+		}
+
+		if _, err := stmt.ExecContext(ctx, args[offset:]...); err != nil {
+			return err
+		}
+	}
+
+	_, err = stmt.ExecContext(ctx)
+	return err
+}
+
+func containsString(values []string, value string) bool {
+	for i := range values {
+		if values[i] == value {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (adapter *Adapter) query(ctx context.Context, statement string, args []interface{}, loggers []rel.Logger) (*db.Rows, error) {
 	var (
 		err   error
@@ -97,9 +244,9 @@ func (adapter *Adapter) query(ctx context.Context, statement string, args []inte
 		rows, err = adapter.DB.QueryContext(ctx, statement, args...)
 	}
 
-	go rel.Log(loggers, statement, time.Since(start), err)
+	rel.LogFunc(ctx, loggers, func() string { return statement }, time.Since(start), err)
 
-	return rows, errorFunc(err)
+	return rows, adapter.Config.ErrorFunc(err)
 }
 
 // Begin begins a new transaction.
@@ -111,16 +258,159 @@ func (adapter *Adapter) Begin(ctx context.Context) (rel.Adapter, error) {
 	}, err
 }
 
+// Prepare current transaction for two-phase commit using PREPARE TRANSACTION.
+// The transaction is dissociated from the connection afterwards, thus the connection is released.
+// Requires max_prepared_transactions to be configured in the database server.
+func (adapter *Adapter) Prepare(ctx context.Context, id string) error {
+	if adapter.Tx == nil {
+		return errors.New("unable to prepare outside transaction")
+	}
+
+	if _, _, err := adapter.Exec(ctx, "PREPARE TRANSACTION "+pq.QuoteLiteral(id)+";", nil); err != nil {
+		return err
+	}
+
+	// prepared transaction is no longer in progress, commit only releases the connection.
+	return adapter.Commit(ctx)
+}
+
+// CommitPrepared commits transaction that was prepared using the given id.
+func (adapter *Adapter) CommitPrepared(ctx context.Context, id string) error {
+	_, _, err := adapter.Exec(ctx, "COMMIT PREPARED "+pq.QuoteLiteral(id)+";", nil)
+	return err
+}
+
+// RollbackPrepared rolls back transaction that was prepared using the given id.
+func (adapter *Adapter) RollbackPrepared(ctx context.Context, id string) error {
+	_, _, err := adapter.Exec(ctx, "ROLLBACK PREPARED "+pq.QuoteLiteral(id)+";", nil)
+	return err
+}
+
+// argumentFunc binds slice as postgres array and map as json.
+// Slice and array of bytes are bound as bytes, and error of encoding map is returned when the statement is executed.
+func argumentFunc(value interface{}) interface{} {
+	switch value.(type) {
+	case nil, []byte, driver.Valuer:
+		return value
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return rv.Bytes()
+		}
+
+		return pq.Array(value)
+	case reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			return b
+		}
+
+		return pq.Array(value)
+	case reflect.Map:
+		b, err := json.Marshal(value)
+		if err != nil {
+			return argumentError{err: err}
+		}
+
+		return string(b)
+	}
+
+	return value
+}
+
+// argumentError is bound in place of argument that can't be encoded, so the error is returned by the driver.
+type argumentError struct {
+	err error
+}
+
+// Value returns the encoding error.
+func (ae argumentError) Value() (driver.Value, error) {
+	return nil, ae.err
+}
+
+// statementTimeoutFunc returns statement to set statement timeout, it only lasts until the end of current transaction.
+func statementTimeoutFunc(timeout time.Duration) string {
+	return "SET LOCAL statement_timeout = " + strconv.FormatInt(int64(timeout/time.Millisecond), 10) + ";"
+}
+
+// mapColumnFunc maps column to postgres type, postgres doesn't support unsigned integer.
+func mapColumnFunc(column *migrator.Column) string {
+	switch column.Type {
+	case migrator.ID:
+		return "SERIAL NOT NULL PRIMARY KEY"
+	case migrator.BigID:
+		return "BIGSERIAL NOT NULL PRIMARY KEY"
+	case migrator.Bool:
+		return "BOOLEAN"
+	case migrator.Int:
+		return "INTEGER"
+	case migrator.BigInt:
+		return "BIGINT"
+	case migrator.Text:
+		return "TEXT"
+	case migrator.DateTime:
+		return "TIMESTAMPTZ"
+	}
+
+	c := *column
+	c.Unsigned = false
+	return sql.MapColumn(&c)
+}
+
+// inspectTablesFunc lists columns of every tables in the current schema using information_schema, and its indexes using pg_index.
+func inspectTablesFunc(ctx context.Context, adapter sql.Adapter) ([]migrator.TableInfo, error) {
+	return sql.Inspect(ctx, adapter,
+		"SELECT table_name, column_name FROM information_schema.columns WHERE table_schema = current_schema() ORDER BY table_name, ordinal_position;",
+		"SELECT t.relname, i.relname, a.attname FROM pg_index x JOIN pg_class t ON t.oid = x.indrelid JOIN pg_class i ON i.oid = x.indexrelid JOIN pg_namespace n ON n.oid = t.relnamespace JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = ANY(x.indkey) WHERE n.nspname = current_schema() ORDER BY t.relname, i.relname, array_position(x.indkey::int2[], a.attnum);",
+	)
+}
+
+// lockFunc acquires session level advisory lock using a dedicated connection, the key of the lock is hash of the name.
+func lockFunc(ctx context.Context, adapter sql.Adapter, name string) (func() error, error) {
+	conn, err := adapter.DB.Conn(ctx)
+	if err != nil {
+		return nil, errorFunc(err)
+	}
+
+	hash := fnv.New64a()
+	hash.Write([]byte(name))
+	key := int64(hash.Sum64())
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1);", key); err != nil {
+		conn.Close()
+		return nil, errorFunc(err)
+	}
+
+	return func() error {
+		_, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1);", key)
+		if cerr := conn.Close(); err == nil {
+			err = cerr
+		}
+
+		return errorFunc(err)
+	}, nil
+}
+
 func errorFunc(err error) error {
 	if err == nil {
 		return nil
 	}
 
 	var (
+		pqErr          *pq.Error
 		msg            = err.Error()
 		constraintType = sql.ExtractString(msg, "violates ", " constraint")
 	)
 
+	// serialization_failure and deadlock_detected.
+	if errors.As(err, &pqErr) && (pqErr.Code == "40001" || pqErr.Code == "40P01") {
+		return rel.SerializationError{Err: err}
+	}
+
 	switch constraintType {
 	case "unique":
 		return rel.ConstraintError{
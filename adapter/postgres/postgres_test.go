@@ -2,6 +2,9 @@ package postgres
 
 import (
 	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"os"
 	"testing"
 	"time"
@@ -9,7 +12,9 @@ import (
 	"github.com/Fs02/go-paranoid"
 	"github.com/Fs02/rel"
 	"github.com/Fs02/rel/adapter/specs"
-	_ "github.com/lib/pq"
+	"github.com/Fs02/rel/migrator"
+	"github.com/Fs02/rel/where"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -86,6 +91,8 @@ func TestAdapter_specs(t *testing.T) {
 	specs.PreloadHasMany(t, repo)
 	specs.PreloadHasManyWithQuery(t, repo)
 	specs.PreloadHasManySlice(t, repo)
+	specs.PreloadHasManyWithLimit(t, repo)
+	specs.PreloadEager(t, repo)
 	specs.PreloadHasOne(t, repo)
 	specs.PreloadHasOneWithQuery(t, repo)
 	specs.PreloadHasOneSlice(t, repo)
@@ -110,6 +117,7 @@ func TestAdapter_specs(t *testing.T) {
 	specs.UpdateHasManyInsert(t, repo)
 	specs.UpdateHasManyUpdate(t, repo)
 	specs.UpdateHasManyReplace(t, repo)
+	specs.UpdateHasManyDiff(t, repo)
 	specs.UpdateHasOneInsert(t, repo)
 	specs.UpdateHasOneUpdate(t, repo)
 	specs.UpdateBelongsToInsert(t, repo)
@@ -146,6 +154,31 @@ func TestAdapter_specs(t *testing.T) {
 // 	assert.NotNil(t, err)
 // }
 
+func TestAdapter_InsertAll_copy(t *testing.T) {
+	adapter, err := Open(dsn())
+	paranoid.Panic(err, "failed to open database connection")
+	defer adapter.Close()
+
+	adapter.Config.BulkLoadThreshold = 2
+
+	var (
+		fields       = []string{"name", "age"}
+		bulkModifies = []map[string]rel.Modify{
+			{"name": rel.Set("name", "copy1"), "age": rel.Set("age", 10)},
+			{"name": rel.Set("name", "copy2"), "age": rel.Set("age", 20)},
+		}
+	)
+
+	ids, err := adapter.InsertAll(ctx, rel.From("users"), fields, bulkModifies)
+	assert.Nil(t, err)
+	assert.Len(t, ids, 2)
+	assert.Equal(t, ids[0].(int64)+1, ids[1])
+
+	count, err := adapter.Aggregate(ctx, rel.From("users").Where(where.Eq("id", ids[1]).AndEq("name", "copy2")), "count", "*")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+}
+
 func TestAdapter_Transaction_commitError(t *testing.T) {
 	adapter, err := Open(dsn())
 	paranoid.Panic(err, "failed to open database connection")
@@ -181,3 +214,156 @@ func TestAdapter_Exec_error(t *testing.T) {
 	_, _, err = adapter.Exec(ctx, "error", nil)
 	assert.NotNil(t, err)
 }
+
+func TestAdapter_Apply_concurrentIndex(t *testing.T) {
+	adapter, err := Open(dsn())
+	paranoid.Panic(err, "failed to open database connection")
+	defer adapter.Close()
+
+	m := migrator.New(rel.New(adapter))
+	m.Register(20200829084000, "create_indexed_users", func(schema *migrator.Schema) {
+		schema.CreateTable("indexed_users", func(t *migrator.Table) {
+			t.ID("id")
+			t.String("email")
+			t.DateTime("deleted_at")
+		}, migrator.Optional(true))
+	}, func(schema *migrator.Schema) {
+		schema.DropTable("indexed_users")
+	})
+	m.Register(20200829084100, "add_email_index_to_indexed_users", func(schema *migrator.Schema) {
+		schema.CreateUniqueIndex("indexed_users", "indexed_users_email", []string{"lower(email)"}, migrator.Concurrent(true), migrator.Where("deleted_at IS NULL"))
+	}, nil)
+
+	// concurrent index can't be created inside transaction.
+	assert.Nil(t, m.Migrate(ctx))
+	assert.Nil(t, m.MigrateTo(ctx, 0))
+}
+
+func TestAdapter_Apply_key(t *testing.T) {
+	adapter, err := Open(dsn())
+	paranoid.Panic(err, "failed to open database connection")
+	defer adapter.Close()
+
+	var (
+		repo   = rel.New(adapter)
+		schema migrator.Schema
+		drop   migrator.Schema
+	)
+
+	schema.CreateTable("key_authors", func(t *migrator.Table) {
+		t.ID("id")
+	})
+	schema.CreateTable("key_books", func(t *migrator.Table) {
+		t.ID("id")
+		t.Int("author_id")
+		t.Int("stock")
+		t.Check("stock >= 0", migrator.Name("key_books_stock_check"))
+	})
+	schema.AlterTable("key_books", func(t *migrator.AlterTable) {
+		t.ForeignKey("author_id", "key_authors", "id", migrator.Name("key_books_author_fk"), migrator.OnDelete("CASCADE"))
+	})
+
+	assert.Nil(t, schema.Apply(ctx, repo))
+
+	_, _, err = adapter.Exec(ctx, "INSERT INTO key_books (author_id, stock) VALUES (1, 0);", nil)
+	assert.NotNil(t, err)
+
+	drop.AlterTable("key_books", func(t *migrator.AlterTable) {
+		t.DropKey("key_books_author_fk")
+	})
+	drop.DropTable("key_books")
+	drop.DropTable("key_authors")
+
+	assert.Nil(t, drop.Apply(ctx, repo))
+}
+
+func TestAdapter_Lock(t *testing.T) {
+	adapter, err := Open(dsn())
+	assert.Nil(t, err)
+	defer adapter.Close()
+
+	unlock, err := adapter.Lock(ctx, "schema_migrations")
+	assert.Nil(t, err)
+
+	// lock is held by another connection.
+	timeout, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+
+	_, err = adapter.Lock(timeout, "schema_migrations")
+	assert.NotNil(t, err)
+
+	// different name is not blocked.
+	unlockOther, err := adapter.Lock(ctx, "analytics_schema_migrations")
+	assert.Nil(t, err)
+	assert.Nil(t, unlockOther())
+
+	assert.Nil(t, unlock())
+
+	unlock, err = adapter.Lock(ctx, "schema_migrations")
+	assert.Nil(t, err)
+	assert.Nil(t, unlock())
+}
+
+func TestArgumentFunc(t *testing.T) {
+	var (
+		tags = []string{"a", "b"}
+	)
+
+	assert.Nil(t, argumentFunc(nil))
+	assert.Equal(t, 1, argumentFunc(1))
+	assert.Equal(t, []byte("bytes"), argumentFunc([]byte("bytes")))
+	assert.Equal(t, pq.Array(tags), argumentFunc(tags))
+	assert.Equal(t, []byte(`{"a":1}`), argumentFunc(json.RawMessage(`{"a":1}`)))
+	assert.Equal(t, []byte{1, 2}, argumentFunc([2]byte{1, 2}))
+	assert.Equal(t, pq.Array([2]int{1, 2}), argumentFunc([2]int{1, 2}))
+	assert.Equal(t, `{"a":1}`, argumentFunc(map[string]int{"a": 1}))
+
+	_, err := argumentFunc(map[string]interface{}{"a": make(chan int)}).(driver.Valuer).Value()
+	assert.NotNil(t, err)
+}
+
+func TestErrorFunc(t *testing.T) {
+	var (
+		errSerialize = &pq.Error{Code: "40001"}
+		errDeadlock  = &pq.Error{Code: "40P01"}
+	)
+
+	assert.Nil(t, errorFunc(nil))
+	assert.Equal(t, rel.SerializationError{Err: errSerialize}, errorFunc(errSerialize))
+	assert.Equal(t, rel.SerializationError{Err: errDeadlock}, errorFunc(errDeadlock))
+}
+
+func TestStatementTimeoutFunc(t *testing.T) {
+	assert.Equal(t, "SET LOCAL statement_timeout = 1500;", statementTimeoutFunc(1500*time.Millisecond))
+}
+
+func TestMapColumnFunc(t *testing.T) {
+	tests := []struct {
+		result string
+		column migrator.Column
+	}{
+		{"SERIAL NOT NULL PRIMARY KEY", migrator.Column{Type: migrator.ID}},
+		{"BIGSERIAL NOT NULL PRIMARY KEY", migrator.Column{Type: migrator.BigID}},
+		{"BOOLEAN", migrator.Column{Type: migrator.Bool}},
+		{"INTEGER", migrator.Column{Type: migrator.Int, Limit: 11, Unsigned: true}},
+		{"BIGINT", migrator.Column{Type: migrator.BigInt}},
+		{"TEXT", migrator.Column{Type: migrator.Text, Limit: 1000}},
+		{"TIMESTAMPTZ", migrator.Column{Type: migrator.DateTime}},
+		{"VARCHAR(100)", migrator.Column{Type: migrator.String, Limit: 100}},
+		{"DECIMAL(6,2)", migrator.Column{Type: migrator.Decimal, Precision: 6, Scale: 2, Unsigned: true}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.result, func(t *testing.T) {
+			assert.Equal(t, test.result, mapColumnFunc(&test.column))
+		})
+	}
+}
+
+func TestAdapter_Prepare_outsideTransaction(t *testing.T) {
+	adapter := New(nil)
+	assert.Equal(t, errors.New("unable to prepare outside transaction"), adapter.Prepare(ctx, "tx-1"))
+	assert.True(t, adapter.Capabilities().Is(rel.TwoPhaseCommitCapability))
+	assert.True(t, adapter.Capabilities().Is(rel.TransactionalDDLCapability))
+	assert.True(t, adapter.Capabilities().Is(rel.WindowCapability))
+}
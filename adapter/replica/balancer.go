@@ -0,0 +1,89 @@
+package replica
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Balancer selects replica to be used for read operation.
+type Balancer interface {
+	// Pick index of replica to be used.
+	Pick(replicas int) int
+	// Observe duration and error of operation executed using the replica.
+	Observe(replica int, duration time.Duration, err error)
+}
+
+// RoundRobinBalancer selects replica in turn.
+type RoundRobinBalancer struct {
+	counter uint64
+}
+
+var _ Balancer = (*RoundRobinBalancer)(nil)
+
+// Pick next replica.
+func (rrb *RoundRobinBalancer) Pick(replicas int) int {
+	return int((atomic.AddUint64(&rrb.counter, 1) - 1) % uint64(replicas))
+}
+
+// Observe does nothing.
+func (rrb *RoundRobinBalancer) Observe(replica int, duration time.Duration, err error) {
+}
+
+// LatencyBalancer selects replica with the lowest moving average latency.
+// Replica that hasn't been observed will be picked first, and error is counted as ErrorPenalty latency.
+type LatencyBalancer struct {
+	ErrorPenalty time.Duration
+	mutex        sync.Mutex
+	latencies    []time.Duration
+}
+
+var _ Balancer = (*LatencyBalancer)(nil)
+
+// Pick replica with the lowest latency.
+func (lb *LatencyBalancer) Pick(replicas int) int {
+	lb.mutex.Lock()
+	defer lb.mutex.Unlock()
+
+	lb.grow(replicas)
+
+	var (
+		index = 0
+	)
+
+	for i := 1; i < replicas; i++ {
+		if lb.latencies[i] < lb.latencies[index] {
+			index = i
+		}
+	}
+
+	return index
+}
+
+// Observe latency of the replica.
+func (lb *LatencyBalancer) Observe(replica int, duration time.Duration, err error) {
+	lb.mutex.Lock()
+	defer lb.mutex.Unlock()
+
+	lb.grow(replica + 1)
+
+	if err != nil {
+		duration = lb.ErrorPenalty
+		if duration == 0 {
+			duration = time.Second
+		}
+	}
+
+	if lb.latencies[replica] == 0 {
+		lb.latencies[replica] = duration
+	} else {
+		// exponentially weighted moving average.
+		lb.latencies[replica] = (lb.latencies[replica]*4 + duration) / 5
+	}
+}
+
+func (lb *LatencyBalancer) grow(replicas int) {
+	for len(lb.latencies) < replicas {
+		lb.latencies = append(lb.latencies, 0)
+	}
+}
@@ -0,0 +1,40 @@
+package replica
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoundRobinBalancer(t *testing.T) {
+	var (
+		balancer = &RoundRobinBalancer{}
+	)
+
+	assert.Equal(t, 0, balancer.Pick(3))
+	assert.Equal(t, 1, balancer.Pick(3))
+	assert.Equal(t, 2, balancer.Pick(3))
+	assert.Equal(t, 0, balancer.Pick(3))
+}
+
+func TestLatencyBalancer(t *testing.T) {
+	var (
+		balancer = &LatencyBalancer{}
+	)
+
+	assert.Equal(t, 0, balancer.Pick(3))
+	balancer.Observe(0, 10*time.Millisecond, nil)
+
+	assert.Equal(t, 1, balancer.Pick(3))
+	balancer.Observe(1, 5*time.Millisecond, nil)
+
+	assert.Equal(t, 2, balancer.Pick(3))
+	balancer.Observe(2, 20*time.Millisecond, nil)
+
+	assert.Equal(t, 1, balancer.Pick(3))
+	balancer.Observe(1, 0, errors.New("connection reset"))
+
+	assert.Equal(t, 0, balancer.Pick(3))
+}
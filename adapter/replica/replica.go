@@ -0,0 +1,152 @@
+// Package replica implements read/write splitting adapter for REL.
+//
+// Reads are executed on one of the replicas selected by the balancer, while writes, locking reads
+// and every operation inside transaction are executed on the primary.
+// Use ReadFromPrimary query to read your own writes from the primary.
+//
+// Usage:
+//	// open primary and replica connections.
+//	primary, _ := postgres.Open("postgres://postgres@primary/rel_test?sslmode=disable")
+//	replica1, _ := postgres.Open("postgres://postgres@replica1/rel_test?sslmode=disable")
+//	replica2, _ := postgres.Open("postgres://postgres@replica2/rel_test?sslmode=disable")
+//
+//	// initialize REL's repo.
+//	repo := rel.New(replica.New(primary, replica1, replica2))
+//
+//	// read from primary.
+//	repo.Find(ctx, &book, where.Eq("id", 1), rel.ReadFromPrimary(true))
+package replica
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Fs02/rel"
+)
+
+// Adapter definition for read/write splitting adapter.
+type Adapter struct {
+	Primary  rel.Adapter
+	Replicas []rel.Adapter
+	Balancer Balancer
+}
+
+var _ rel.Adapter = (*Adapter)(nil)
+
+// New read/write splitting adapter that uses round-robin balancer.
+func New(primary rel.Adapter, replicas ...rel.Adapter) *Adapter {
+	return &Adapter{
+		Primary:  primary,
+		Replicas: replicas,
+		Balancer: &RoundRobinBalancer{},
+	}
+}
+
+// Capabilities of the primary adapter.
+func (adapter *Adapter) Capabilities() rel.Capabilities {
+	return adapter.Primary.Capabilities()
+}
+
+// Ping primary and all of the replicas.
+func (adapter *Adapter) Ping(ctx context.Context) error {
+	if err := adapter.Primary.Ping(ctx); err != nil {
+		return err
+	}
+
+	for _, replica := range adapter.Replicas {
+		if err := replica.Ping(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Stats returns connection pool statistics of the primary.
+func (adapter *Adapter) Stats() sql.DBStats {
+	if sa, ok := adapter.Primary.(rel.StatsAdapter); ok {
+		return sa.Stats()
+	}
+
+	return sql.DBStats{}
+}
+
+// Aggregate using one of the replicas.
+func (adapter *Adapter) Aggregate(ctx context.Context, query rel.Query, mode string, field string, loggers ...rel.Logger) (int, error) {
+	if adapter.usePrimary(query) {
+		return adapter.Primary.Aggregate(ctx, query, mode, field, loggers...)
+	}
+
+	var (
+		index       = adapter.Balancer.Pick(len(adapter.Replicas))
+		start       = time.Now()
+		result, err = adapter.Replicas[index].Aggregate(ctx, query, mode, field, loggers...)
+	)
+
+	adapter.Balancer.Observe(index, time.Since(start), err)
+
+	return result, err
+}
+
+// Query using one of the replicas.
+func (adapter *Adapter) Query(ctx context.Context, query rel.Query, loggers ...rel.Logger) (rel.Cursor, error) {
+	if adapter.usePrimary(query) {
+		return adapter.Primary.Query(ctx, query, loggers...)
+	}
+
+	var (
+		index       = adapter.Balancer.Pick(len(adapter.Replicas))
+		start       = time.Now()
+		cursor, err = adapter.Replicas[index].Query(ctx, query, loggers...)
+	)
+
+	adapter.Balancer.Observe(index, time.Since(start), err)
+
+	return cursor, err
+}
+
+// Insert using primary.
+func (adapter *Adapter) Insert(ctx context.Context, query rel.Query, modifies map[string]rel.Modify, loggers ...rel.Logger) (interface{}, error) {
+	return adapter.Primary.Insert(ctx, query, modifies, loggers...)
+}
+
+// InsertAll using primary.
+func (adapter *Adapter) InsertAll(ctx context.Context, query rel.Query, fields []string, bulkModifies []map[string]rel.Modify, loggers ...rel.Logger) ([]interface{}, error) {
+	return adapter.Primary.InsertAll(ctx, query, fields, bulkModifies, loggers...)
+}
+
+// Update using primary.
+func (adapter *Adapter) Update(ctx context.Context, query rel.Query, modifies map[string]rel.Modify, loggers ...rel.Logger) (int, error) {
+	return adapter.Primary.Update(ctx, query, modifies, loggers...)
+}
+
+// Delete using primary.
+func (adapter *Adapter) Delete(ctx context.Context, query rel.Query, loggers ...rel.Logger) (int, error) {
+	return adapter.Primary.Delete(ctx, query, loggers...)
+}
+
+// Begin transaction using primary.
+// The returned adapter is the primary transaction adapter, thus every read inside transaction will use primary.
+// Read only transaction is started using one of the replica instead.
+func (adapter *Adapter) Begin(ctx context.Context) (rel.Adapter, error) {
+	if len(adapter.Replicas) > 0 && rel.TransactionOptionsFrom(ctx).ReadOnly {
+		return adapter.Replicas[adapter.Balancer.Pick(len(adapter.Replicas))].Begin(ctx)
+	}
+
+	return adapter.Primary.Begin(ctx)
+}
+
+// Commit transaction using primary.
+func (adapter *Adapter) Commit(ctx context.Context) error {
+	return adapter.Primary.Commit(ctx)
+}
+
+// Rollback transaction using primary.
+func (adapter *Adapter) Rollback(ctx context.Context) error {
+	return adapter.Primary.Rollback(ctx)
+}
+
+func (adapter *Adapter) usePrimary(query rel.Query) bool {
+	return len(adapter.Replicas) == 0 || bool(query.ReadFromPrimaryQuery) || query.LockQuery != ""
+}
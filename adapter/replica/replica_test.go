@@ -0,0 +1,130 @@
+package replica
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/adapter/memory"
+	"github.com/Fs02/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+var ctx = context.TODO()
+
+type User struct {
+	ID   int64
+	Name string
+}
+
+func setup() (*memory.Adapter, *memory.Adapter, *memory.Adapter, rel.Repository) {
+	var (
+		primary  = memory.New()
+		replica1 = memory.New()
+		replica2 = memory.New()
+	)
+
+	primary.Load("users", map[string]interface{}{"id": 1, "name": "primary"})
+	replica1.Load("users", map[string]interface{}{"id": 1, "name": "replica1"})
+	replica2.Load("users", map[string]interface{}{"id": 1, "name": "replica2"})
+
+	return primary, replica1, replica2, rel.New(New(primary, replica1, replica2))
+}
+
+func TestAdapter_read(t *testing.T) {
+	var (
+		_, _, _, repo = setup()
+		user          User
+	)
+
+	assert.Nil(t, repo.Find(ctx, &user))
+	assert.Equal(t, "replica1", user.Name)
+
+	assert.Nil(t, repo.Find(ctx, &user))
+	assert.Equal(t, "replica2", user.Name)
+
+	assert.Nil(t, repo.Find(ctx, &user))
+	assert.Equal(t, "replica1", user.Name)
+
+	count, err := repo.Count(ctx, "users")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestAdapter_readFromPrimary(t *testing.T) {
+	var (
+		_, _, _, repo = setup()
+		user          User
+	)
+
+	assert.Nil(t, repo.Find(ctx, &user, rel.ReadFromPrimary(true)))
+	assert.Equal(t, "primary", user.Name)
+
+	assert.Nil(t, repo.Find(ctx, &user, rel.ForUpdate()))
+	assert.Equal(t, "primary", user.Name)
+}
+
+func TestAdapter_write(t *testing.T) {
+	var (
+		primary, replica1, replica2, repo = setup()
+		user                              = User{Name: "new"}
+	)
+
+	assert.Nil(t, repo.Insert(ctx, &user))
+	assert.Nil(t, repo.Update(ctx, &user, rel.Set("name", "updated")))
+
+	count, _ := rel.New(primary).Count(ctx, "users", where.Eq("name", "updated"))
+	assert.Equal(t, 1, count)
+
+	count, _ = rel.New(replica1).Count(ctx, "users")
+	assert.Equal(t, 1, count)
+
+	count, _ = rel.New(replica2).Count(ctx, "users")
+	assert.Equal(t, 1, count)
+
+	assert.Nil(t, repo.Delete(ctx, &user))
+	count, _ = rel.New(primary).Count(ctx, "users")
+	assert.Equal(t, 1, count)
+}
+
+func TestAdapter_transaction(t *testing.T) {
+	var (
+		_, _, _, repo = setup()
+		user          User
+	)
+
+	assert.Nil(t, repo.Transaction(ctx, func(repo rel.Repository) error {
+		return repo.Find(ctx, &user)
+	}))
+	assert.Equal(t, "primary", user.Name)
+}
+
+func TestAdapter_transactionReadOnly(t *testing.T) {
+	var (
+		_, _, _, repo = setup()
+		user          User
+	)
+
+	assert.Nil(t, repo.Transaction(ctx, func(repo rel.Repository) error {
+		return repo.Find(ctx, &user)
+	}, rel.ReadOnly()))
+	assert.Contains(t, []string{"replica1", "replica2"}, user.Name)
+}
+
+func TestAdapter_withoutReplica(t *testing.T) {
+	var (
+		primary = memory.New()
+		repo    = rel.New(New(primary))
+		user    User
+	)
+
+	primary.Load("users", map[string]interface{}{"id": 1, "name": "primary"})
+
+	assert.Nil(t, repo.Ping(ctx))
+	assert.Nil(t, repo.Find(ctx, &user))
+	assert.Equal(t, "primary", user.Name)
+	assert.Equal(t, primary.Capabilities(), repo.Adapter().Capabilities())
+	assert.Zero(t, repo.Stats())
+	assert.NotNil(t, repo.Adapter().Commit(ctx))
+	assert.NotNil(t, repo.Adapter().Rollback(ctx))
+}
@@ -0,0 +1,282 @@
+// Package retry implements resilience adapter for REL.
+//
+// Reads are retried with exponential backoff when the underlying adapter returns transient error such as connection reset.
+// When retries are exhausted, adapter switches to the standby adapter if available, and every following operation is executed on the standby
+// until the primary responds to ping, which is checked at most once every FailbackInterval.
+// Each retry, failover and failback is reported to the loggers and the instrumenter, writes and operations inside transaction are never retried.
+//
+// Usage:
+//	// open primary and standby connections.
+//	primary, _ := postgres.Open("postgres://postgres@primary/rel_test?sslmode=disable")
+//	standby, _ := postgres.Open("postgres://postgres@standby/rel_test?sslmode=disable")
+//
+//	// initialize REL's repo.
+//	adapter := retry.New(primary)
+//	adapter.Standby = standby
+//	repo := rel.New(adapter)
+package retry
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Fs02/rel"
+)
+
+// Adapter definition for retry adapter.
+// Instrumenter observes retry, failover and failback operation, message of retry is the attempt number.
+type Adapter struct {
+	Primary          rel.Adapter
+	Standby          rel.Adapter
+	MaxRetries       int
+	Backoff          func(attempt int) time.Duration
+	Transient        func(err error) bool
+	FailbackInterval time.Duration
+	Instrumenter     rel.Instrumenter
+
+	mutex    sync.RWMutex
+	failover bool
+	checked  time.Time
+}
+
+var _ rel.Adapter = (*Adapter)(nil)
+
+// New retry adapter that retries reads up to three times with exponential backoff,
+// and checks the primary every 30 seconds after failover.
+func New(primary rel.Adapter) *Adapter {
+	return &Adapter{
+		Primary:          primary,
+		MaxRetries:       3,
+		Backoff:          ExponentialBackoff(10 * time.Millisecond),
+		Transient:        IsTransient,
+		FailbackInterval: 30 * time.Second,
+	}
+}
+
+// ExponentialBackoff returns backoff function that doubles the base duration every attempt.
+func ExponentialBackoff(base time.Duration) func(int) time.Duration {
+	return func(attempt int) time.Duration {
+		return base << uint(attempt)
+	}
+}
+
+// IsTransient returns true if error is caused by broken connection.
+// Timeout and context error are not transient, since it's usually caused by slow query instead of unhealthy database.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return !netErr.Timeout()
+	}
+
+	msg := err.Error()
+	return strings.Contains(msg, "connection reset") ||
+		strings.Contains(msg, "connection refused") ||
+		strings.Contains(msg, "broken pipe")
+}
+
+// Active returns adapter currently in use.
+func (adapter *Adapter) Active() rel.Adapter {
+	active, _ := adapter.active()
+	return active
+}
+
+// Failback switches back to the primary adapter.
+func (adapter *Adapter) Failback() {
+	adapter.mutex.Lock()
+	adapter.failover = false
+	adapter.mutex.Unlock()
+}
+
+// Capabilities of the active adapter.
+func (adapter *Adapter) Capabilities() rel.Capabilities {
+	return adapter.Active().Capabilities()
+}
+
+// Ping the active adapter.
+func (adapter *Adapter) Ping(ctx context.Context) error {
+	return adapter.retry(ctx, nil, func(active rel.Adapter) error {
+		return active.Ping(ctx)
+	})
+}
+
+// Stats returns connection pool statistics of the active adapter.
+func (adapter *Adapter) Stats() sql.DBStats {
+	if sa, ok := adapter.Active().(rel.StatsAdapter); ok {
+		return sa.Stats()
+	}
+
+	return sql.DBStats{}
+}
+
+// Aggregate using the active adapter, retried on transient error.
+func (adapter *Adapter) Aggregate(ctx context.Context, query rel.Query, mode string, field string, loggers ...rel.Logger) (int, error) {
+	var (
+		result int
+	)
+
+	err := adapter.retry(ctx, loggers, func(active rel.Adapter) error {
+		var err error
+		result, err = active.Aggregate(ctx, query, mode, field, loggers...)
+		return err
+	})
+
+	return result, err
+}
+
+// Query using the active adapter, retried on transient error.
+func (adapter *Adapter) Query(ctx context.Context, query rel.Query, loggers ...rel.Logger) (rel.Cursor, error) {
+	var (
+		cursor rel.Cursor
+	)
+
+	err := adapter.retry(ctx, loggers, func(active rel.Adapter) error {
+		var err error
+		cursor, err = active.Query(ctx, query, loggers...)
+		return err
+	})
+
+	return cursor, err
+}
+
+// Insert using the active adapter.
+func (adapter *Adapter) Insert(ctx context.Context, query rel.Query, modifies map[string]rel.Modify, loggers ...rel.Logger) (interface{}, error) {
+	return adapter.current(ctx, loggers).Insert(ctx, query, modifies, loggers...)
+}
+
+// InsertAll using the active adapter.
+func (adapter *Adapter) InsertAll(ctx context.Context, query rel.Query, fields []string, bulkModifies []map[string]rel.Modify, loggers ...rel.Logger) ([]interface{}, error) {
+	return adapter.current(ctx, loggers).InsertAll(ctx, query, fields, bulkModifies, loggers...)
+}
+
+// Update using the active adapter.
+func (adapter *Adapter) Update(ctx context.Context, query rel.Query, modifies map[string]rel.Modify, loggers ...rel.Logger) (int, error) {
+	return adapter.current(ctx, loggers).Update(ctx, query, modifies, loggers...)
+}
+
+// Delete using the active adapter.
+func (adapter *Adapter) Delete(ctx context.Context, query rel.Query, loggers ...rel.Logger) (int, error) {
+	return adapter.current(ctx, loggers).Delete(ctx, query, loggers...)
+}
+
+// Begin transaction using the active adapter, retried on transient error.
+// The returned adapter is the transaction adapter of the active adapter, thus operations inside transaction are never retried.
+func (adapter *Adapter) Begin(ctx context.Context) (rel.Adapter, error) {
+	var (
+		tx rel.Adapter
+	)
+
+	err := adapter.retry(ctx, nil, func(active rel.Adapter) error {
+		var err error
+		tx, err = active.Begin(ctx)
+		return err
+	})
+
+	return tx, err
+}
+
+// Commit transaction using the active adapter.
+func (adapter *Adapter) Commit(ctx context.Context) error {
+	return adapter.Active().Commit(ctx)
+}
+
+// Rollback transaction using the active adapter.
+func (adapter *Adapter) Rollback(ctx context.Context) error {
+	return adapter.Active().Rollback(ctx)
+}
+
+func (adapter *Adapter) retry(ctx context.Context, loggers []rel.Logger, fn func(rel.Adapter) error) error {
+	adapter.failback(ctx, loggers)
+
+	var (
+		active, failover = adapter.active()
+		err              = fn(active)
+	)
+
+	for attempt := 0; attempt < adapter.MaxRetries && adapter.Transient(err); attempt++ {
+		backoff := adapter.Backoff(attempt)
+		rel.Log(loggers, "RETRY "+strconv.Itoa(attempt+1), backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		finish := adapter.Instrumenter.Observe(ctx, "retry", strconv.Itoa(attempt+1))
+		err = fn(active)
+		finish(err)
+	}
+
+	if adapter.Transient(err) && !failover && adapter.Standby != nil {
+		adapter.mutex.Lock()
+		adapter.failover = true
+		adapter.checked = time.Now()
+		adapter.mutex.Unlock()
+
+		rel.Log(loggers, "FAILOVER", 0, err)
+
+		finish := adapter.Instrumenter.Observe(ctx, "failover", "")
+		err = fn(adapter.Standby)
+		finish(err)
+	}
+
+	return err
+}
+
+// current returns the active adapter after checking whether it can fail back to the primary.
+func (adapter *Adapter) current(ctx context.Context, loggers []rel.Logger) rel.Adapter {
+	adapter.failback(ctx, loggers)
+	return adapter.Active()
+}
+
+// failback switches back to the primary when it responds to ping, the primary is checked at most once every FailbackInterval.
+func (adapter *Adapter) failback(ctx context.Context, loggers []rel.Logger) {
+	if adapter.FailbackInterval <= 0 {
+		return
+	}
+
+	adapter.mutex.Lock()
+	if !adapter.failover || time.Since(adapter.checked) < adapter.FailbackInterval {
+		adapter.mutex.Unlock()
+		return
+	}
+
+	adapter.checked = time.Now()
+	adapter.mutex.Unlock()
+
+	finish := adapter.Instrumenter.Observe(ctx, "failback", "")
+	err := adapter.Primary.Ping(ctx)
+	finish(err)
+
+	if err == nil {
+		adapter.Failback()
+		rel.Log(loggers, "FAILBACK", 0, nil)
+	}
+}
+
+func (adapter *Adapter) active() (rel.Adapter, bool) {
+	adapter.mutex.RLock()
+	defer adapter.mutex.RUnlock()
+
+	if adapter.failover {
+		return adapter.Standby, true
+	}
+
+	return adapter.Primary, false
+}
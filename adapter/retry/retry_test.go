@@ -0,0 +1,279 @@
+package retry
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/adapter/memory"
+	"github.com/stretchr/testify/assert"
+)
+
+var ctx = context.TODO()
+
+type User struct {
+	ID   int64
+	Name string
+}
+
+type flakyAdapter struct {
+	*memory.Adapter
+	failures int
+	err      error
+	calls    int
+}
+
+func (fa *flakyAdapter) fail() error {
+	fa.calls++
+	if fa.failures != 0 {
+		fa.failures--
+		return fa.err
+	}
+
+	return nil
+}
+
+func (fa *flakyAdapter) Query(ctx context.Context, query rel.Query, loggers ...rel.Logger) (rel.Cursor, error) {
+	if err := fa.fail(); err != nil {
+		return nil, err
+	}
+
+	return fa.Adapter.Query(ctx, query, loggers...)
+}
+
+func (fa *flakyAdapter) Ping(ctx context.Context) error {
+	if err := fa.fail(); err != nil {
+		return err
+	}
+
+	return fa.Adapter.Ping(ctx)
+}
+
+func (fa *flakyAdapter) Insert(ctx context.Context, query rel.Query, modifies map[string]rel.Modify, loggers ...rel.Logger) (interface{}, error) {
+	if err := fa.fail(); err != nil {
+		return nil, err
+	}
+
+	return fa.Adapter.Insert(ctx, query, modifies, loggers...)
+}
+
+func newFlaky(name string, failures int, err error) *flakyAdapter {
+	adapter := memory.New()
+	adapter.Load("users", map[string]interface{}{"id": 1, "name": name})
+
+	return &flakyAdapter{Adapter: adapter, failures: failures, err: err}
+}
+
+func newAdapter(primary rel.Adapter) *Adapter {
+	adapter := New(primary)
+	adapter.Backoff = func(int) time.Duration { return 0 }
+	return adapter
+}
+
+func TestIsTransient(t *testing.T) {
+	assert.False(t, IsTransient(nil))
+	assert.True(t, IsTransient(driver.ErrBadConn))
+	assert.True(t, IsTransient(errors.New("read tcp: connection reset by peer")))
+	assert.False(t, IsTransient(rel.NotFoundError{}))
+	assert.False(t, IsTransient(context.DeadlineExceeded))
+	assert.False(t, IsTransient(context.Canceled))
+	assert.False(t, IsTransient(&net.OpError{Op: "read", Err: timeoutError{}}))
+	assert.True(t, IsTransient(&net.OpError{Op: "read", Err: errors.New("connection reset by peer")}))
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(10 * time.Millisecond)
+	assert.Equal(t, 10*time.Millisecond, backoff(0))
+	assert.Equal(t, 40*time.Millisecond, backoff(2))
+}
+
+func TestAdapter_retry(t *testing.T) {
+	var (
+		primary  = newFlaky("primary", 2, driver.ErrBadConn)
+		adapter  = newAdapter(primary)
+		repo     = rel.New(adapter)
+		user     User
+		statuses []string
+	)
+
+	repo.SetLogger(func(statement string, _ time.Duration, err error) {
+		statuses = append(statuses, statement)
+	})
+
+	assert.Nil(t, repo.Find(ctx, &user))
+	assert.Equal(t, "primary", user.Name)
+	assert.Equal(t, 3, primary.calls)
+	assert.Equal(t, []string{"RETRY 1", "RETRY 2"}, statuses[:2])
+}
+
+func TestAdapter_retryInstrumenter(t *testing.T) {
+	var (
+		primary    = newFlaky("primary", 2, driver.ErrBadConn)
+		adapter    = newAdapter(primary)
+		user       User
+		operations []string
+	)
+
+	adapter.Instrumenter = func(ctx context.Context, op string, message string) func(error) {
+		return func(err error) {
+			operations = append(operations, op+" "+message)
+		}
+	}
+
+	assert.Nil(t, rel.New(adapter).Find(ctx, &user))
+	assert.Equal(t, []string{"retry 1", "retry 2"}, operations)
+}
+
+func TestAdapter_retryExhausted(t *testing.T) {
+	var (
+		primary = newFlaky("primary", -1, driver.ErrBadConn)
+		adapter = newAdapter(primary)
+		repo    = rel.New(adapter)
+		user    User
+	)
+
+	assert.Equal(t, driver.ErrBadConn, repo.Find(ctx, &user))
+	assert.Equal(t, 4, primary.calls)
+}
+
+func TestAdapter_notTransient(t *testing.T) {
+	var (
+		err     = errors.New("syntax error")
+		primary = newFlaky("primary", 1, err)
+		adapter = newAdapter(primary)
+		repo    = rel.New(adapter)
+		user    User
+	)
+
+	assert.Equal(t, err, repo.Find(ctx, &user))
+	assert.Equal(t, 1, primary.calls)
+}
+
+func TestAdapter_writeNotRetried(t *testing.T) {
+	var (
+		primary = newFlaky("primary", 1, driver.ErrBadConn)
+		adapter = newAdapter(primary)
+		repo    = rel.New(adapter)
+		user    = User{Name: "new"}
+	)
+
+	assert.Equal(t, driver.ErrBadConn, repo.Insert(ctx, &user))
+	assert.Equal(t, 1, primary.calls)
+}
+
+func TestAdapter_failover(t *testing.T) {
+	var (
+		primary  = newFlaky("primary", -1, driver.ErrBadConn)
+		standby  = newFlaky("standby", 0, nil)
+		adapter  = newAdapter(primary)
+		repo     = rel.New(adapter)
+		user     User
+		statuses []string
+	)
+
+	adapter.Standby = standby
+	repo.SetLogger(func(statement string, _ time.Duration, err error) {
+		statuses = append(statuses, statement)
+	})
+
+	assert.Nil(t, repo.Find(ctx, &user))
+	assert.Equal(t, "standby", user.Name)
+	assert.Equal(t, standby, adapter.Active())
+	assert.Equal(t, []string{"RETRY 1", "RETRY 2", "RETRY 3", "FAILOVER"}, statuses[:4])
+
+	assert.Nil(t, repo.Insert(ctx, &User{Name: "new"}))
+	assert.Equal(t, 4, primary.calls)
+	assert.Equal(t, 2, standby.calls)
+
+	adapter.Failback()
+	assert.Equal(t, primary, adapter.Active())
+}
+
+func TestAdapter_failback(t *testing.T) {
+	var (
+		primary  = newFlaky("primary", 4, driver.ErrBadConn)
+		standby  = newFlaky("standby", 0, nil)
+		adapter  = newAdapter(primary)
+		repo     = rel.New(adapter)
+		user     User
+		statuses []string
+	)
+
+	adapter.Standby = standby
+	adapter.FailbackInterval = time.Nanosecond
+	repo.SetLogger(func(statement string, _ time.Duration, err error) {
+		statuses = append(statuses, statement)
+	})
+
+	assert.Nil(t, repo.Find(ctx, &user))
+	assert.Equal(t, "standby", user.Name)
+	assert.Equal(t, standby, adapter.Active())
+
+	time.Sleep(time.Millisecond)
+
+	assert.Nil(t, repo.Find(ctx, &user))
+	assert.Equal(t, "primary", user.Name)
+	assert.Equal(t, primary, adapter.Active())
+	assert.Contains(t, statuses, "FAILBACK")
+}
+
+func TestAdapter_failbackUnhealthy(t *testing.T) {
+	var (
+		primary = newFlaky("primary", -1, driver.ErrBadConn)
+		standby = newFlaky("standby", 0, nil)
+		adapter = newAdapter(primary)
+		repo    = rel.New(adapter)
+	)
+
+	adapter.Standby = standby
+	adapter.FailbackInterval = time.Hour
+
+	assert.Nil(t, repo.Find(ctx, &User{}))
+	assert.Equal(t, standby, adapter.Active())
+
+	// primary is not checked before the interval.
+	assert.Nil(t, repo.Insert(ctx, &User{Name: "new"}))
+	assert.Equal(t, 4, primary.calls)
+	assert.Equal(t, standby, adapter.Active())
+}
+
+func TestAdapter_contextCanceled(t *testing.T) {
+	var (
+		primary     = newFlaky("primary", -1, driver.ErrBadConn)
+		adapter     = New(primary)
+		repo        = rel.New(adapter)
+		user        User
+		ctx, cancel = context.WithCancel(ctx)
+	)
+
+	cancel()
+	assert.Equal(t, context.Canceled, repo.Find(ctx, &user))
+	assert.Equal(t, 1, primary.calls)
+}
+
+func TestAdapter_transaction(t *testing.T) {
+	var (
+		primary = newFlaky("primary", 0, nil)
+		adapter = newAdapter(primary)
+		repo    = rel.New(adapter)
+	)
+
+	assert.Nil(t, repo.Transaction(ctx, func(repo rel.Repository) error {
+		return repo.Insert(ctx, &User{Name: "new"})
+	}))
+
+	assert.Equal(t, 2, rel.New(primary.Adapter).MustCount(ctx, "users"))
+	assert.Equal(t, primary.Capabilities(), adapter.Capabilities())
+	assert.Nil(t, adapter.Ping(ctx))
+	assert.Equal(t, 0, adapter.Stats().OpenConnections)
+}
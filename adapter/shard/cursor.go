@@ -0,0 +1,294 @@
+package shard
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Fs02/rel"
+)
+
+type cursor struct {
+	fields []string
+	rows   [][]interface{}
+	index  int
+}
+
+var _ rel.Cursor = (*cursor)(nil)
+
+// merge reads all rows from shard cursors, then sorts and applies offset and limit of the original query.
+func merge(cursors []rel.Cursor, query rel.Query) (*cursor, error) {
+	defer closeAll(cursors)
+
+	var (
+		fields []string
+		rows   [][]interface{}
+	)
+
+	for _, cur := range cursors {
+		curFields, err := cur.Fields()
+		if err != nil {
+			return nil, err
+		}
+
+		if fields == nil {
+			fields = curFields
+		}
+
+		// each shard may return the fields in different order.
+		var (
+			positions = make([]int, len(curFields))
+		)
+
+		for i := range curFields {
+			if positions[i] = indexOf(fields, curFields[i]); positions[i] < 0 {
+				return nil, errors.New("shard: field " + curFields[i] + " is not returned by every shard")
+			}
+		}
+
+		for cur.Next() {
+			var (
+				row  = make([]interface{}, len(fields))
+				dest = make([]interface{}, len(curFields))
+			)
+
+			for i := range dest {
+				dest[i] = &row[positions[i]]
+			}
+
+			if err := cur.Scan(dest...); err != nil {
+				return nil, err
+			}
+
+			rows = append(rows, row)
+		}
+	}
+
+	if err := sortRows(rows, fields, query.SortQuery); err != nil {
+		return nil, err
+	}
+
+	if offset := int(query.OffsetQuery); offset > 0 {
+		if offset > len(rows) {
+			offset = len(rows)
+		}
+
+		rows = rows[offset:]
+	}
+
+	if limit := int(query.LimitQuery); limit > 0 && limit < len(rows) {
+		rows = rows[:limit]
+	}
+
+	return &cursor{
+		fields: fields,
+		rows:   rows,
+		index:  -1,
+	}, nil
+}
+
+// Close cursor.
+func (c *cursor) Close() error {
+	return nil
+}
+
+// Fields returned in the result.
+func (c *cursor) Fields() ([]string, error) {
+	return c.fields, nil
+}
+
+// Next prepares the next row to be scanned.
+func (c *cursor) Next() bool {
+	c.index++
+	return c.index < len(c.rows)
+}
+
+// Scan current row into dest.
+func (c *cursor) Scan(dest ...interface{}) error {
+	if c.index < 0 || c.index >= len(c.rows) {
+		return errors.New("shard: Scan called without calling Next")
+	}
+
+	if len(dest) != len(c.fields) {
+		return errors.New("shard: expected " + strconv.Itoa(len(c.fields)) + " destination arguments in Scan")
+	}
+
+	for i := range c.fields {
+		if err := assign(dest[i], c.rows[c.index][i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NopScanner for this adapter.
+func (c *cursor) NopScanner() interface{} {
+	return &sql.RawBytes{}
+}
+
+func closeAll(cursors []rel.Cursor) {
+	for _, cur := range cursors {
+		cur.Close()
+	}
+}
+
+func sortRows(rows [][]interface{}, fields []string, sorts []rel.SortQuery) error {
+	if len(sorts) == 0 {
+		return nil
+	}
+
+	var (
+		indexes = make([]int, len(sorts))
+	)
+
+	for i := range sorts {
+		indexes[i] = fieldIndex(fields, sorts[i].Field)
+		if indexes[i] < 0 {
+			return rel.NotSupportedError{Operation: "sort by unselected field " + sorts[i].Field + " across shards"}
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		for k, s := range sorts {
+			if c := order(rows[i][indexes[k]], rows[j][indexes[k]]); c != 0 {
+				return (c < 0) == s.Asc()
+			}
+		}
+
+		return false
+	})
+
+	return nil
+}
+
+func fieldIndex(fields []string, field string) int {
+	if i := strings.LastIndexByte(field, '.'); i >= 0 {
+		field = field[i+1:]
+	}
+
+	return indexOf(fields, field)
+}
+
+func indexOf(fields []string, field string) int {
+	for i := range fields {
+		if fields[i] == field {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func order(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	switch av := a.(type) {
+	case int64:
+		switch bv := b.(type) {
+		case int64:
+			switch {
+			case av < bv:
+				return -1
+			case av > bv:
+				return 1
+			default:
+				return 0
+			}
+		case float64:
+			return compareFloat(float64(av), bv)
+		}
+	case float64:
+		switch bv := b.(type) {
+		case int64:
+			return compareFloat(av, float64(bv))
+		case float64:
+			return compareFloat(av, bv)
+		}
+	case string:
+		if bv, ok := b.(string); ok {
+			return strings.Compare(av, bv)
+		}
+	case []byte:
+		if bv, ok := b.([]byte); ok {
+			return bytes.Compare(av, bv)
+		}
+	case bool:
+		if bv, ok := b.(bool); ok && av != bv {
+			if bv {
+				return -1
+			}
+
+			return 1
+		}
+	case time.Time:
+		if bv, ok := b.(time.Time); ok {
+			switch {
+			case av.Before(bv):
+				return -1
+			case av.After(bv):
+				return 1
+			}
+		}
+	}
+
+	return 0
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func assign(dest interface{}, value interface{}) error {
+	switch d := dest.(type) {
+	case *sql.RawBytes:
+		return nil
+	case sql.Scanner:
+		return d.Scan(value)
+	}
+
+	var (
+		rv = reflect.ValueOf(dest)
+	)
+
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("shard: destination must be a non nil pointer")
+	}
+
+	rv = rv.Elem()
+	if value == nil {
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	}
+
+	if rv.Kind() != reflect.Ptr {
+		return rel.Nullable(dest).(sql.Scanner).Scan(value)
+	}
+
+	ptr := reflect.New(rv.Type().Elem())
+	if err := rel.Nullable(ptr.Interface()).(sql.Scanner).Scan(value); err != nil {
+		return err
+	}
+
+	rv.Set(ptr)
+	return nil
+}
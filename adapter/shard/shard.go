@@ -0,0 +1,382 @@
+// Package shard implements key-based sharding adapter for REL.
+//
+// Every operation is routed to one of the shards based on shard key extracted from where equality condition or inserted values.
+// Queries without shard key will be executed on every shards, and the results are merged, group and distinct query are not supported.
+// Transaction is started on every shard and committed one by one, thus it's not atomic across shards.
+//
+// Usage:
+//	repo := rel.New(shard.New("tenant_id", shard1, shard2, shard3))
+//
+//	// executed on a single shard.
+//	repo.FindAll(ctx, &books, where.Eq("tenant_id", 1))
+//
+//	// executed on every shard.
+//	repo.FindAll(ctx, &books, where.Gt("price", 100))
+package shard
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/Fs02/rel"
+)
+
+// ErrMissingShardKey returned when inserting a record without shard key.
+var ErrMissingShardKey = errors.New("shard: missing shard key")
+
+// Adapter definition for sharding adapter.
+type Adapter struct {
+	Key       string
+	Shards    []rel.Adapter
+	ShardFunc func(key interface{}, shards int) int
+}
+
+var _ rel.Adapter = (*Adapter)(nil)
+
+// New sharding adapter using hash of key value to select the shard.
+func New(key string, shards ...rel.Adapter) *Adapter {
+	return &Adapter{
+		Key:       key,
+		Shards:    shards,
+		ShardFunc: Hash,
+	}
+}
+
+// Hash shard key using fnv hash.
+func Hash(key interface{}, shards int) int {
+	h := fnv.New32a()
+	fmt.Fprint(h, key)
+	return int(h.Sum32() % uint32(shards))
+}
+
+// Capabilities supported by all of the shards.
+func (adapter *Adapter) Capabilities() rel.Capabilities {
+	var (
+		capabilities = ^rel.Capabilities(0)
+	)
+
+	for _, shard := range adapter.Shards {
+		capabilities &= shard.Capabilities()
+	}
+
+	return capabilities
+}
+
+// Ping all shards.
+func (adapter *Adapter) Ping(ctx context.Context) error {
+	for _, shard := range adapter.Shards {
+		if err := shard.Ping(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Stats returns sum of connection pool statistics of all shards.
+func (adapter *Adapter) Stats() sql.DBStats {
+	var (
+		result sql.DBStats
+	)
+
+	for _, shard := range adapter.Shards {
+		if sa, ok := shard.(rel.StatsAdapter); ok {
+			stats := sa.Stats()
+			result.MaxOpenConnections += stats.MaxOpenConnections
+			result.OpenConnections += stats.OpenConnections
+			result.InUse += stats.InUse
+			result.Idle += stats.Idle
+			result.WaitCount += stats.WaitCount
+			result.WaitDuration += stats.WaitDuration
+			result.MaxIdleClosed += stats.MaxIdleClosed
+			result.MaxLifetimeClosed += stats.MaxLifetimeClosed
+		}
+	}
+
+	return result
+}
+
+// Aggregate on the shard that matches shard key, or combine aggregation of all shards.
+// Only count, sum, max and min can be combined, shards without any non null value are skipped when combining max and min.
+func (adapter *Adapter) Aggregate(ctx context.Context, query rel.Query, mode string, field string, loggers ...rel.Logger) (int, error) {
+	if shard, ok := adapter.route(query.WhereQuery); ok {
+		return shard.Aggregate(ctx, query, mode, field, loggers...)
+	}
+
+	var (
+		result int
+		found  bool
+	)
+
+	switch mode {
+	case "count", "sum", "max", "min":
+	default:
+		return 0, rel.NotSupportedError{Operation: "aggregate " + mode + " across shards"}
+	}
+
+	for _, shard := range adapter.Shards {
+		// aggregate result of shard without any non null value is zero,
+		// thus it's skipped to not be compared as the max or min value.
+		if mode == "max" || mode == "min" {
+			count, err := shard.Aggregate(ctx, query, "count", field, loggers...)
+			if err != nil {
+				return 0, err
+			}
+
+			if count == 0 {
+				continue
+			}
+		}
+
+		value, err := shard.Aggregate(ctx, query, mode, field, loggers...)
+		if err != nil {
+			return 0, err
+		}
+
+		switch {
+		case !found:
+			result = value
+		case mode == "count", mode == "sum":
+			result += value
+		case mode == "max" && value > result, mode == "min" && value < result:
+			result = value
+		}
+
+		found = true
+	}
+
+	return result, nil
+}
+
+// Query on the shard that matches shard key, or query all shards and merge the results.
+func (adapter *Adapter) Query(ctx context.Context, query rel.Query, loggers ...rel.Logger) (rel.Cursor, error) {
+	if shard, ok := adapter.route(query.WhereQuery); ok {
+		return shard.Query(ctx, query, loggers...)
+	}
+
+	// rows of every shard can't be re-aggregated or de-duplicated after merged.
+	switch {
+	case len(query.GroupQuery.Fields) > 0:
+		return nil, rel.NotSupportedError{Operation: "group across shards"}
+	case query.SelectQuery.OnlyDistinct:
+		return nil, rel.NotSupportedError{Operation: "distinct across shards"}
+	}
+
+	var (
+		shardQuery = query
+		cursors    = make([]rel.Cursor, 0, len(adapter.Shards))
+	)
+
+	// offset can only be applied after results are merged.
+	if query.LimitQuery > 0 {
+		shardQuery.LimitQuery += rel.Limit(query.OffsetQuery)
+	}
+	shardQuery.OffsetQuery = 0
+
+	for _, shard := range adapter.Shards {
+		cursor, err := shard.Query(ctx, shardQuery, loggers...)
+		if err != nil {
+			closeAll(cursors)
+			return nil, err
+		}
+
+		cursors = append(cursors, cursor)
+	}
+
+	return merge(cursors, query)
+}
+
+// Insert on the shard that matches shard key.
+func (adapter *Adapter) Insert(ctx context.Context, query rel.Query, modifies map[string]rel.Modify, loggers ...rel.Logger) (interface{}, error) {
+	shard, err := adapter.routeModifies(modifies)
+	if err != nil {
+		return nil, err
+	}
+
+	return shard.Insert(ctx, query, modifies, loggers...)
+}
+
+// InsertAll groups records by shard and inserts it on the respective shards.
+func (adapter *Adapter) InsertAll(ctx context.Context, query rel.Query, fields []string, bulkModifies []map[string]rel.Modify, loggers ...rel.Logger) ([]interface{}, error) {
+	var (
+		ids     = make([]interface{}, len(bulkModifies))
+		groups  = make(map[int][]map[string]rel.Modify)
+		indexes = make(map[int][]int)
+		order   []int
+	)
+
+	for i := range bulkModifies {
+		value, ok := adapter.keyFromModifies(bulkModifies[i])
+		if !ok {
+			return nil, ErrMissingShardKey
+		}
+
+		index := adapter.ShardFunc(value, len(adapter.Shards))
+		if _, ok := groups[index]; !ok {
+			order = append(order, index)
+		}
+
+		groups[index] = append(groups[index], bulkModifies[i])
+		indexes[index] = append(indexes[index], i)
+	}
+
+	for _, index := range order {
+		result, err := adapter.Shards[index].InsertAll(ctx, query, fields, groups[index], loggers...)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, id := range result {
+			ids[indexes[index][i]] = id
+		}
+	}
+
+	return ids, nil
+}
+
+// Update on the shard that matches shard key, or update all shards.
+func (adapter *Adapter) Update(ctx context.Context, query rel.Query, modifies map[string]rel.Modify, loggers ...rel.Logger) (int, error) {
+	if shard, ok := adapter.route(query.WhereQuery); ok {
+		return shard.Update(ctx, query, modifies, loggers...)
+	}
+
+	var (
+		result int
+	)
+
+	for _, shard := range adapter.Shards {
+		count, err := shard.Update(ctx, query, modifies, loggers...)
+		if err != nil {
+			return result, err
+		}
+
+		result += count
+	}
+
+	return result, nil
+}
+
+// Delete on the shard that matches shard key, or delete on all shards.
+func (adapter *Adapter) Delete(ctx context.Context, query rel.Query, loggers ...rel.Logger) (int, error) {
+	if shard, ok := adapter.route(query.WhereQuery); ok {
+		return shard.Delete(ctx, query, loggers...)
+	}
+
+	var (
+		result int
+	)
+
+	for _, shard := range adapter.Shards {
+		count, err := shard.Delete(ctx, query, loggers...)
+		if err != nil {
+			return result, err
+		}
+
+		result += count
+	}
+
+	return result, nil
+}
+
+// Begin transaction on every shard.
+func (adapter *Adapter) Begin(ctx context.Context) (rel.Adapter, error) {
+	var (
+		shards = make([]rel.Adapter, len(adapter.Shards))
+	)
+
+	for i, shard := range adapter.Shards {
+		tx, err := shard.Begin(ctx)
+		if err != nil {
+			for j := 0; j < i; j++ {
+				_ = shards[j].Rollback(ctx)
+			}
+
+			return nil, err
+		}
+
+		shards[i] = tx
+	}
+
+	return &Adapter{
+		Key:       adapter.Key,
+		Shards:    shards,
+		ShardFunc: adapter.ShardFunc,
+	}, nil
+}
+
+// Commit transaction on every shard.
+// When a shard fails to commit, transaction of the remaining shards are rolled back, while the committed shards stay committed.
+func (adapter *Adapter) Commit(ctx context.Context) error {
+	for i, shard := range adapter.Shards {
+		if err := shard.Commit(ctx); err != nil {
+			for _, remaining := range adapter.Shards[i+1:] {
+				_ = remaining.Rollback(ctx)
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Rollback transaction on every shard.
+func (adapter *Adapter) Rollback(ctx context.Context) error {
+	var (
+		result error
+	)
+
+	for _, shard := range adapter.Shards {
+		if err := shard.Rollback(ctx); err != nil && result == nil {
+			result = err
+		}
+	}
+
+	return result
+}
+
+func (adapter *Adapter) route(filter rel.FilterQuery) (rel.Adapter, bool) {
+	value, ok := adapter.keyFromFilter(filter)
+	if !ok {
+		return nil, false
+	}
+
+	return adapter.Shards[adapter.ShardFunc(value, len(adapter.Shards))], true
+}
+
+func (adapter *Adapter) routeModifies(modifies map[string]rel.Modify) (rel.Adapter, error) {
+	value, ok := adapter.keyFromModifies(modifies)
+	if !ok {
+		return nil, ErrMissingShardKey
+	}
+
+	return adapter.Shards[adapter.ShardFunc(value, len(adapter.Shards))], nil
+}
+
+func (adapter *Adapter) keyFromFilter(filter rel.FilterQuery) (interface{}, bool) {
+	switch filter.Type {
+	case rel.FilterEqOp:
+		if filter.Field == adapter.Key {
+			return filter.Value, true
+		}
+	case rel.FilterAndOp:
+		for _, inner := range filter.Inner {
+			if value, ok := adapter.keyFromFilter(inner); ok {
+				return value, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func (adapter *Adapter) keyFromModifies(modifies map[string]rel.Modify) (interface{}, bool) {
+	if mod, ok := modifies[adapter.Key]; ok && mod.Type == rel.ChangeSetOp && mod.Value != nil {
+		return mod.Value, true
+	}
+
+	return nil, false
+}
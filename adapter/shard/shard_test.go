@@ -0,0 +1,302 @@
+package shard
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/adapter/memory"
+	"github.com/Fs02/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+var ctx = context.TODO()
+
+type Book struct {
+	ID    int64
+	Title string
+}
+
+type User struct {
+	ID       int64
+	TenantID int
+	Name     string
+}
+
+func setup() (*memory.Adapter, *memory.Adapter, rel.Repository) {
+	var (
+		shard0  = memory.New()
+		shard1  = memory.New()
+		adapter = New("tenant_id", shard0, shard1)
+	)
+
+	adapter.ShardFunc = func(key interface{}, shards int) int {
+		return key.(int) % shards
+	}
+
+	shard0.Load("users",
+		map[string]interface{}{"id": 1, "tenant_id": 2, "name": "c"},
+		map[string]interface{}{"id": 3, "tenant_id": 2, "name": "a"},
+	)
+	shard1.Load("users",
+		map[string]interface{}{"id": 2, "tenant_id": 1, "name": "b"},
+		map[string]interface{}{"id": 4, "tenant_id": 3, "name": "d"},
+	)
+
+	return shard0, shard1, rel.New(adapter)
+}
+
+func TestHash(t *testing.T) {
+	assert.Equal(t, Hash(1, 4), Hash(1, 4))
+	assert.True(t, Hash("tenant", 4) < 4)
+}
+
+func TestAdapter_queryWithShardKey(t *testing.T) {
+	var (
+		_, _, repo = setup()
+		users      []User
+	)
+
+	assert.Nil(t, repo.FindAll(ctx, &users, where.Eq("tenant_id", 2).AndEq("name", "a")))
+	assert.Equal(t, []User{{ID: 3, TenantID: 2, Name: "a"}}, users)
+}
+
+func TestAdapter_queryFanOut(t *testing.T) {
+	var (
+		_, _, repo = setup()
+		users      []User
+	)
+
+	assert.Nil(t, repo.FindAll(ctx, &users, rel.NewSortAsc("name")))
+	assert.Equal(t, []User{
+		{ID: 3, TenantID: 2, Name: "a"},
+		{ID: 2, TenantID: 1, Name: "b"},
+		{ID: 1, TenantID: 2, Name: "c"},
+		{ID: 4, TenantID: 3, Name: "d"},
+	}, users)
+
+	assert.Nil(t, repo.FindAll(ctx, &users, rel.NewSortDesc("name"), rel.Offset(1), rel.Limit(2)))
+	assert.Equal(t, []User{
+		{ID: 1, TenantID: 2, Name: "c"},
+		{ID: 2, TenantID: 1, Name: "b"},
+	}, users)
+}
+
+func TestAdapter_queryFanOutUnselectedSort(t *testing.T) {
+	var (
+		_, _, repo = setup()
+		users      []User
+	)
+
+	assert.Equal(t, rel.NotSupportedError{Operation: "sort by unselected field age across shards"},
+		repo.FindAll(ctx, &users, rel.Select("id", "name"), rel.NewSortAsc("age")))
+}
+
+func TestAdapter_aggregate(t *testing.T) {
+	var (
+		_, _, repo = setup()
+	)
+
+	count, err := repo.Count(ctx, "users")
+	assert.Nil(t, err)
+	assert.Equal(t, 4, count)
+
+	count, err = repo.Count(ctx, "users", where.Eq("tenant_id", 2))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+
+	max, err := repo.Aggregate(ctx, rel.From("users"), "max", "id")
+	assert.Nil(t, err)
+	assert.Equal(t, 4, max)
+
+	min, err := repo.Aggregate(ctx, rel.From("users"), "min", "id")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, min)
+
+	_, err = repo.Aggregate(ctx, rel.From("users"), "avg", "id")
+	assert.Equal(t, rel.NotSupportedError{Operation: "aggregate avg across shards"}, err)
+}
+
+func TestAdapter_aggregateEmptyShard(t *testing.T) {
+	var (
+		_, _, repo = setup()
+	)
+
+	min, err := repo.Aggregate(ctx, rel.From("users").Where(where.Gt("id", 3)), "min", "id")
+	assert.Nil(t, err)
+	assert.Equal(t, 4, min)
+
+	max, err := repo.Aggregate(ctx, rel.From("users").Where(where.Lt("id", 2)), "max", "id")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, max)
+
+	max, err = repo.Aggregate(ctx, rel.From("users").Where(where.Gt("id", 4)), "max", "id")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, max)
+}
+
+func TestOrder_int64(t *testing.T) {
+	assert.Equal(t, -1, order(int64(math.MinInt64), int64(1)))
+	assert.Equal(t, 1, order(int64(math.MaxInt64), int64(-1)))
+	assert.Equal(t, 0, order(int64(1<<62), int64(1<<62)))
+}
+
+func TestAdapter_insert(t *testing.T) {
+	var (
+		shard0, shard1, repo = setup()
+		user                 = User{ID: 5, TenantID: 4, Name: "e"}
+	)
+
+	assert.Nil(t, repo.Insert(ctx, &user))
+
+	count, _ := rel.New(shard0).Count(ctx, "users")
+	assert.Equal(t, 3, count)
+
+	count, _ = rel.New(shard1).Count(ctx, "users")
+	assert.Equal(t, 2, count)
+}
+
+func TestAdapter_insertMissingShardKey(t *testing.T) {
+	var (
+		_, _, repo = setup()
+	)
+
+	assert.Equal(t, ErrMissingShardKey, repo.Insert(ctx, &Book{Title: "rel"}))
+}
+
+func TestAdapter_insertAll(t *testing.T) {
+	var (
+		shard0, shard1, repo = setup()
+		users                = []User{
+			{ID: 5, TenantID: 1, Name: "e"},
+			{ID: 6, TenantID: 2, Name: "f"},
+			{ID: 7, TenantID: 3, Name: "g"},
+		}
+	)
+
+	assert.Nil(t, repo.InsertAll(ctx, &users))
+
+	count, _ := rel.New(shard0).Count(ctx, "users", where.Eq("id", users[1].ID).AndEq("name", "f"))
+	assert.Equal(t, 1, count)
+
+	count, _ = rel.New(shard1).Count(ctx, "users", where.Eq("id", users[2].ID).AndEq("name", "g"))
+	assert.Equal(t, 1, count)
+
+	count, _ = rel.New(shard0).Count(ctx, "users")
+	assert.Equal(t, 3, count)
+
+	count, _ = rel.New(shard1).Count(ctx, "users")
+	assert.Equal(t, 4, count)
+}
+
+func TestAdapter_updateAndDelete(t *testing.T) {
+	var (
+		shard0, shard1, repo = setup()
+		adapter              = New("tenant_id", shard0, shard1)
+	)
+
+	adapter.ShardFunc = func(key interface{}, shards int) int {
+		return key.(int) % shards
+	}
+
+	updated, err := adapter.Update(ctx, rel.From("users").Where(where.Eq("tenant_id", 2)), map[string]rel.Modify{"name": rel.Set("name", "x")})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, updated)
+
+	count, _ := rel.New(shard0).Count(ctx, "users", where.Eq("name", "x"))
+	assert.Equal(t, 2, count)
+
+	assert.Nil(t, repo.DeleteAll(ctx, rel.From("users")))
+
+	count, _ = rel.New(shard0).Count(ctx, "users")
+	assert.Equal(t, 0, count)
+
+	count, _ = rel.New(shard1).Count(ctx, "users")
+	assert.Equal(t, 0, count)
+}
+
+func TestAdapter_transaction(t *testing.T) {
+	var (
+		shard0, shard1, repo = setup()
+		errRollback          = errors.New("rollback")
+	)
+
+	err := repo.Transaction(ctx, func(repo rel.Repository) error {
+		repo.MustDeleteAll(ctx, rel.From("users"))
+		return errRollback
+	})
+
+	assert.Equal(t, errRollback, err)
+
+	count, _ := rel.New(shard0).Count(ctx, "users")
+	assert.Equal(t, 2, count)
+
+	count, _ = rel.New(shard1).Count(ctx, "users")
+	assert.Equal(t, 2, count)
+
+	assert.Nil(t, repo.Transaction(ctx, func(repo rel.Repository) error {
+		repo.MustDeleteAll(ctx, rel.From("users").Where(where.Eq("tenant_id", 1)))
+		return nil
+	}))
+
+	count, _ = rel.New(shard1).Count(ctx, "users")
+	assert.Equal(t, 1, count)
+}
+
+type txAdapter struct {
+	rel.Adapter
+	commitErr  error
+	rolledBack bool
+}
+
+func (ta *txAdapter) Commit(ctx context.Context) error {
+	return ta.commitErr
+}
+
+func (ta *txAdapter) Rollback(ctx context.Context) error {
+	ta.rolledBack = true
+	return nil
+}
+
+func TestAdapter_commitError(t *testing.T) {
+	var (
+		errCommit = errors.New("commit")
+		committed = &txAdapter{}
+		failed    = &txAdapter{commitErr: errCommit}
+		remaining = &txAdapter{}
+		adapter   = New("tenant_id", committed, failed, remaining)
+	)
+
+	assert.Equal(t, errCommit, adapter.Commit(ctx))
+	assert.False(t, committed.rolledBack)
+	assert.False(t, failed.rolledBack)
+	assert.True(t, remaining.rolledBack)
+}
+
+func TestAdapter_queryFanOutGroupAndDistinct(t *testing.T) {
+	var (
+		adapter = New("tenant_id", memory.New(), memory.New())
+	)
+
+	_, err := adapter.Query(ctx, rel.From("users").Select("status").Group("status"))
+	assert.Equal(t, rel.NotSupportedError{Operation: "group across shards"}, err)
+
+	_, err = adapter.Query(ctx, rel.From("users").Select("status").Distinct())
+	assert.Equal(t, rel.NotSupportedError{Operation: "distinct across shards"}, err)
+
+	cur, err := adapter.Query(ctx, rel.From("users").Select("status").Distinct().Where(where.Eq("tenant_id", 1)))
+	assert.Nil(t, err)
+	assert.Nil(t, cur.Close())
+}
+
+func TestAdapter_capabilitiesAndPing(t *testing.T) {
+	var (
+		adapter = New("tenant_id", memory.New(), memory.New())
+	)
+
+	assert.Equal(t, memory.New().Capabilities(), adapter.Capabilities())
+	assert.Nil(t, adapter.Ping(ctx))
+	assert.Equal(t, 0, adapter.Stats().OpenConnections)
+}
@@ -0,0 +1,227 @@
+// Package spanner wraps Google Cloud Spanner database/sql driver as an adapter for REL.
+//
+// The driver itself is not imported by this package, import it in the main package instead:
+//	import _ "github.com/googleapis/go-sql-spanner"
+//
+// Writes are performed using DML statements instead of mutation API, since mutation is only available
+// through Spanner client library and not through database/sql driver.
+// Each DML statement is executed immediately, and its changes are only visible to other transactions after commit.
+// Spanner doesn't generate primary key, so it needs to be assigned before inserting a record.
+// Spanner requires WHERE clause on every update and delete statement, thus update or delete without filter is executed with WHERE true.
+//
+// Usage:
+//	// open spanner connection.
+//	adapter, err := spanner.Open("projects/my-project/instances/my-instance/databases/rel_test")
+//	if err != nil {
+//		panic(err)
+//	}
+//	defer adapter.Close()
+//
+//	// initialize REL's repo.
+//	repo := rel.New(adapter)
+//
+//	// run read-only transaction that reads from a consistent snapshot.
+//	err = repo.Transaction(ctx, func(repo rel.Repository) error {
+//		return repo.FindAll(ctx, &books)
+//	}, rel.ReadOnly())
+package spanner
+
+import (
+	"context"
+	db "database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/adapter/sql"
+)
+
+var (
+	// ErrNestedTransaction returned when beginning a transaction inside another transaction.
+	ErrNestedTransaction = errors.New("spanner: nested transaction is not supported")
+
+	// ErrIsolationLevel returned when beginning a transaction with isolation level other than serializable.
+	ErrIsolationLevel = errors.New("spanner: isolation level is not supported")
+)
+
+// Adapter definition for spanner database.
+type Adapter struct {
+	*sql.Adapter
+}
+
+var _ rel.Adapter = (*Adapter)(nil)
+
+// New spanner adapter using existing connection.
+func New(database *db.DB) *Adapter {
+	return &Adapter{
+		Adapter: &sql.Adapter{
+			Config: &sql.Config{
+				Placeholder:      "@p",
+				Ordinal:          true,
+				EscapeChar:       "`",
+				NoSemicolon:      true,
+				ReturningKeyword: "THEN RETURN",
+				ErrorFunc:        errorFunc,
+			},
+			DB: database,
+		},
+	}
+}
+
+// Open spanner connection using dsn.
+func Open(dsn string) (*Adapter, error) {
+	database, err := db.Open("spanner", dsn)
+	return New(database), err
+}
+
+// Capabilities of the adapter, spanner doesn't support savepoint.
+func (adapter *Adapter) Capabilities() rel.Capabilities {
+	return rel.TransactionCapability | rel.JoinCapability | rel.GroupCapability
+}
+
+// Insert inserts a record to database and returns its id.
+func (adapter *Adapter) Insert(ctx context.Context, query rel.Query, modifies map[string]rel.Modify, loggers ...rel.Logger) (interface{}, error) {
+	var (
+		id              interface{}
+		statement, args = sql.NewBuilder(adapter.Config).Returning("id").Insert(query.Table, modifies)
+		rows, err       = adapter.query(ctx, statement, args, loggers)
+	)
+
+	if err == nil {
+		defer rows.Close()
+		if rows.Next() {
+			err = rows.Scan(&id)
+		}
+	}
+
+	return id, err
+}
+
+// InsertAll inserts multiple records to database and returns its ids.
+func (adapter *Adapter) InsertAll(ctx context.Context, query rel.Query, fields []string, bulkModifies []map[string]rel.Modify, loggers ...rel.Logger) ([]interface{}, error) {
+	var (
+		ids             []interface{}
+		statement, args = sql.NewBuilder(adapter.Config).Returning("id").InsertAll(query.Table, fields, bulkModifies)
+		rows, err       = adapter.query(ctx, statement, args, loggers)
+	)
+
+	if err == nil {
+		defer rows.Close()
+		for rows.Next() {
+			var id interface{}
+			if err = rows.Scan(&id); err != nil {
+				break
+			}
+
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, err
+}
+
+// Update updates a record in database, update without filter is executed with WHERE true.
+func (adapter *Adapter) Update(ctx context.Context, query rel.Query, modifies map[string]rel.Modify, loggers ...rel.Logger) (int, error) {
+	return adapter.Adapter.Update(ctx, whereAll(query), modifies, loggers...)
+}
+
+// Delete deletes all results that match the query, delete without filter is executed with WHERE true.
+func (adapter *Adapter) Delete(ctx context.Context, query rel.Query, loggers ...rel.Logger) (int, error) {
+	return adapter.Adapter.Delete(ctx, whereAll(query), loggers...)
+}
+
+func whereAll(query rel.Query) rel.Query {
+	if query.WhereQuery.None() {
+		query.WhereQuery = rel.FilterFragment("true")
+	}
+
+	return query
+}
+
+func (adapter *Adapter) query(ctx context.Context, statement string, args []interface{}, loggers []rel.Logger) (*db.Rows, error) {
+	var (
+		err   error
+		rows  *db.Rows
+		start = time.Now()
+	)
+
+	if adapter.Tx != nil {
+		rows, err = adapter.Tx.QueryContext(ctx, statement, args...)
+	} else {
+		rows, err = adapter.DB.QueryContext(ctx, statement, args...)
+	}
+
+	rel.LogFunc(ctx, loggers, func() string { return statement }, time.Since(start), err)
+
+	return rows, adapter.Config.ErrorFunc(err)
+}
+
+// Begin begins a new transaction.
+// Spanner doesn't support savepoint, thus nested transaction will returns ErrNestedTransaction.
+// Read-only option begins a read-only transaction, and only serializable isolation level is supported.
+func (adapter *Adapter) Begin(ctx context.Context) (rel.Adapter, error) {
+	if adapter.Tx != nil {
+		return nil, ErrNestedTransaction
+	}
+
+	options := rel.TransactionOptionsFrom(ctx)
+	if options.Isolation != db.LevelDefault && options.Isolation != db.LevelSerializable {
+		return nil, ErrIsolationLevel
+	}
+
+	tx, err := adapter.DB.BeginTx(ctx, &db.TxOptions{Isolation: options.Isolation, ReadOnly: options.ReadOnly})
+
+	return &Adapter{
+		Adapter: &sql.Adapter{
+			Config: adapter.Config,
+			Tx:     tx,
+		},
+	}, err
+}
+
+// ReadOnlyTransaction performs read-only transaction with given function argument.
+// All reads inside the function will be using the same consistent snapshot without acquiring any lock.
+// It's a shorthand for repo.Transaction(ctx, fn, rel.ReadOnly()).
+func ReadOnlyTransaction(ctx context.Context, repo rel.Repository, fn func(rel.Repository) error) error {
+	return repo.Transaction(ctx, fn, rel.ReadOnly())
+}
+
+func errorFunc(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var (
+		msg = err.Error()
+	)
+
+	switch {
+	case strings.Contains(msg, "Unique index violation"):
+		return rel.ConstraintError{
+			Key:  sql.ExtractString(msg, "on index ", " "),
+			Type: rel.UniqueConstraint,
+			Err:  err,
+		}
+	case strings.Contains(msg, "already exists"):
+		return rel.ConstraintError{
+			Key:  sql.ExtractString(msg, "in table ", " "),
+			Type: rel.PrimaryKeyConstraint,
+			Err:  err,
+		}
+	case strings.Contains(msg, "Foreign key constraint"):
+		return rel.ConstraintError{
+			Key:  sql.ExtractString(msg, "Foreign key constraint `", "`"),
+			Type: rel.ForeignKeyConstraint,
+			Err:  err,
+		}
+	case strings.Contains(msg, "Check constraint"):
+		return rel.ConstraintError{
+			Key:  sql.ExtractString(msg, "`.`", "`"),
+			Type: rel.CheckConstraint,
+			Err:  err,
+		}
+	default:
+		return err
+	}
+}
@@ -0,0 +1,99 @@
+package spanner
+
+import (
+	"context"
+	db "database/sql"
+	"errors"
+	"testing"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/adapter/sql"
+	"github.com/Fs02/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+var ctx = context.TODO()
+
+func TestNew_config(t *testing.T) {
+	var (
+		adapter = New(nil)
+	)
+
+	qs, args := sql.NewBuilder(adapter.Config).Find(rel.From("users").Where(where.Eq("id", 1)).Limit(10))
+	assert.Equal(t, "SELECT * FROM `users` WHERE `id`=@p1 LIMIT 10", qs)
+	assert.Equal(t, []interface{}{1}, args)
+
+	qs, args = sql.NewBuilder(adapter.Config).Returning("id").Insert("users", map[string]rel.Modify{"id": rel.Set("id", 1)})
+	assert.Equal(t, "INSERT INTO `users` (`id`) VALUES (@p1) THEN RETURN `id`", qs)
+	assert.Equal(t, []interface{}{1}, args)
+}
+
+func TestWhereAll(t *testing.T) {
+	var (
+		adapter = New(nil)
+	)
+
+	qs, args := sql.NewBuilder(adapter.Config).Delete("users", whereAll(rel.From("users")).WhereQuery)
+	assert.Equal(t, "DELETE FROM `users` WHERE true", qs)
+	assert.Nil(t, args)
+
+	qs, args = sql.NewBuilder(adapter.Config).Update("users", map[string]rel.Modify{"name": rel.Set("name", "rel")}, whereAll(rel.From("users")).WhereQuery)
+	assert.Equal(t, "UPDATE `users` SET `name`=@p1 WHERE true", qs)
+	assert.Equal(t, []interface{}{"rel"}, args)
+
+	qs, args = sql.NewBuilder(adapter.Config).Delete("users", whereAll(rel.From("users").Where(where.Eq("id", 1))).WhereQuery)
+	assert.Equal(t, "DELETE FROM `users` WHERE `id`=@p1", qs)
+	assert.Equal(t, []interface{}{1}, args)
+}
+
+func TestAdapter_Begin_nested(t *testing.T) {
+	var (
+		adapter = New(nil)
+	)
+
+	adapter.Tx = &db.Tx{}
+
+	_, err := adapter.Begin(ctx)
+	assert.Equal(t, ErrNestedTransaction, err)
+}
+
+func TestReadOnlyTransaction_nested(t *testing.T) {
+	var (
+		adapter = New(nil)
+	)
+
+	adapter.Tx = &db.Tx{}
+
+	err := ReadOnlyTransaction(ctx, rel.New(adapter), func(rel.Repository) error {
+		return nil
+	})
+	assert.Equal(t, ErrNestedTransaction, err)
+}
+
+func TestAdapter_Begin_isolation(t *testing.T) {
+	var (
+		adapter = New(nil)
+	)
+
+	err := rel.New(adapter).Transaction(ctx, func(rel.Repository) error {
+		return nil
+	}, rel.Isolation(db.LevelReadCommitted))
+	assert.Equal(t, ErrIsolationLevel, err)
+}
+
+func TestErrorFunc(t *testing.T) {
+	var (
+		errUnique     = errors.New("spanner: code = \"AlreadyExists\", desc = \"Unique index violation on index users_slug at index key [foo,1]. It conflicts with row [1] in table users.\"")
+		errPrimaryKey = errors.New("spanner: code = \"AlreadyExists\", desc = \"Row [1] in table users already exists\"")
+		errForeignKey = errors.New("spanner: code = \"FailedPrecondition\", desc = \"Foreign key constraint `FK_addresses_users` is violated on table `addresses`. Cannot find referenced values in users(id).\"")
+		errCheck      = errors.New("spanner: code = \"OutOfRange\", desc = \"Check constraint `extras`.`score_range` is violated for key (1)\"")
+		errOther      = errors.New("spanner: code = \"NotFound\", desc = \"Table not found: users\"")
+	)
+
+	assert.Nil(t, errorFunc(nil))
+	assert.Equal(t, errOther, errorFunc(errOther))
+	assert.Equal(t, rel.ConstraintError{Key: "users_slug", Type: rel.UniqueConstraint, Err: errUnique}, errorFunc(errUnique))
+	assert.Equal(t, rel.ConstraintError{Key: "users", Type: rel.PrimaryKeyConstraint, Err: errPrimaryKey}, errorFunc(errPrimaryKey))
+	assert.Equal(t, rel.ConstraintError{Key: "FK_addresses_users", Type: rel.ForeignKeyConstraint, Err: errForeignKey}, errorFunc(errForeignKey))
+	assert.Equal(t, rel.ConstraintError{Key: "score_range", Type: rel.CheckConstraint, Err: errCheck}, errorFunc(errCheck))
+}
@@ -231,3 +231,52 @@ func InsertAll(t *testing.T, repo rel.Repository) {
 		})
 	}
 }
+
+// InsertOnConflict tests insert specifications with on conflict option.
+func InsertOnConflict(t *testing.T, repo rel.Repository) {
+	var (
+		user = User{Name: "insert on conflict", Age: 20}
+	)
+
+	repo.MustInsert(ctx, &user)
+
+	t.Run("Ignore", func(t *testing.T) {
+		var (
+			queried  User
+			conflict = User{ID: user.ID, Name: "ignored", Age: 21}
+		)
+
+		assert.Nil(t, repo.Insert(ctx, &conflict, rel.NewStructset(&conflict, false), rel.OnConflictIgnore()))
+		assert.Equal(t, user.ID, conflict.ID)
+
+		repo.MustFind(ctx, &queried, where.Eq("id", user.ID))
+		assert.Equal(t, "insert on conflict", queried.Name)
+		assert.Equal(t, 20, queried.Age)
+	})
+
+	t.Run("Replace", func(t *testing.T) {
+		var (
+			queried  User
+			conflict = User{ID: user.ID, Name: "replaced", Age: 22}
+		)
+
+		assert.Nil(t, repo.Insert(ctx, &conflict, rel.NewStructset(&conflict, false), rel.OnConflictReplace()))
+		assert.Equal(t, user.ID, conflict.ID)
+
+		repo.MustFind(ctx, &queried, where.Eq("id", user.ID))
+		assert.Equal(t, "replaced", queried.Name)
+		assert.Equal(t, 22, queried.Age)
+	})
+
+	t.Run("InsertAll", func(t *testing.T) {
+		var (
+			queried []User
+			users   = []User{{ID: user.ID, Name: "replaced all", Age: 23}, {Name: "inserted all", Age: 24}}
+		)
+
+		assert.Nil(t, repo.InsertAll(ctx, &users, rel.OnConflictReplace()))
+
+		repo.MustFindAll(ctx, &queried, where.Like("name", "% all"))
+		assert.Len(t, queried, 2)
+	})
+}
@@ -76,6 +76,46 @@ func PreloadHasManySlice(t *testing.T, repo rel.Repository) {
 	assert.Equal(t, users, result)
 }
 
+// PreloadHasManyWithLimit tests specification for preloading has many association with limit for each record.
+func PreloadHasManyWithLimit(t *testing.T, repo rel.Repository) {
+	var (
+		result []User
+		users  = []User{
+			createPreloadUser(repo),
+			createPreloadUser(repo),
+		}
+	)
+
+	err := repo.FindAll(ctx, &result, where.In("id", users[0].ID, users[1].ID), rel.NewSortAsc("id"))
+	assert.Nil(t, err)
+
+	err = repo.Preload(ctx, &result, "addresses", rel.PreloadLimit(2), rel.NewSortDesc("id"))
+	assert.Nil(t, err)
+	assert.Len(t, result, 2)
+
+	for i := range users {
+		assert.Equal(t, []Address{users[i].Addresses[2], users[i].Addresses[1]}, result[i].Addresses)
+	}
+}
+
+// PreloadEager tests specification for loading belongs to association along with the records.
+func PreloadEager(t *testing.T, repo rel.Repository) {
+	var (
+		result []Address
+		user   = createPreloadUser(repo)
+	)
+
+	err := repo.FindAll(ctx, &result, rel.Eager("user"), where.Eq("addresses.user_id", user.ID), rel.NewSortAsc("addresses.id"))
+	assert.Nil(t, err)
+	assert.Len(t, result, len(user.Addresses))
+
+	for i := range result {
+		assert.Equal(t, user.Addresses[i].ID, result[i].ID)
+		assert.Equal(t, user.ID, result[i].User.ID)
+		assert.Equal(t, user.Name, result[i].User.Name)
+	}
+}
+
 // PreloadHasOne tests specification for preloading has one association.
 func PreloadHasOne(t *testing.T, repo rel.Repository) {
 	var (
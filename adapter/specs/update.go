@@ -166,6 +166,46 @@ func UpdateHasManyReplace(t *testing.T, repo rel.Repository) {
 	assert.Equal(t, result, user)
 }
 
+// UpdateHasManyDiff tests specification for updating a record and updating has many association, only missing records are deleted.
+func UpdateHasManyDiff(t *testing.T, repo rel.Repository) {
+	var (
+		result User
+		user   = User{
+			Name: "update init",
+			Addresses: []Address{
+				{Name: "old address"},
+				{Name: "removed address"},
+			},
+		}
+	)
+
+	repo.MustInsert(ctx, &user)
+
+	var (
+		id = user.Addresses[0].ID
+	)
+
+	user.Addresses = []Address{
+		{ID: id, UserID: &user.ID, Name: "primary"},
+		{Name: "work"},
+	}
+
+	err := repo.Update(ctx, &user)
+	assert.Nil(t, err)
+
+	assert.Len(t, user.Addresses, 2)
+	assert.Equal(t, id, user.Addresses[0].ID)
+	assert.NotEqual(t, 0, user.Addresses[1].ID)
+	assert.Equal(t, user.ID, *user.Addresses[1].UserID)
+	assert.Equal(t, "primary", user.Addresses[0].Name)
+	assert.Equal(t, "work", user.Addresses[1].Name)
+
+	repo.MustFind(ctx, &result, where.Eq("id", user.ID))
+	repo.MustPreload(ctx, &result, "addresses", rel.NewSortAsc("id"))
+
+	assert.Equal(t, result, user)
+}
+
 // UpdateHasOneInsert tests specification for updating a record and inserting has many association.
 func UpdateHasOneInsert(t *testing.T, repo rel.Repository) {
 	var (
@@ -5,28 +5,81 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/migrator"
 )
 
+// DefaultBulkLoadThreshold is the minimum number of records to be inserted using bulk load.
+const DefaultBulkLoadThreshold = 1000
+
+// DefaultInArrayThreshold is the minimum number of values of in filter to be passed as a single array argument.
+const DefaultInArrayThreshold = 100
+
 // Config holds configuration for adapter.
+// BulkLoadThreshold is only used by adapter that supports bulk load, zero value disables bulk load.
+// InArrayThreshold builds in filter that has more values than the threshold using ANY/ALL with a single array argument instead of a placeholder for every value,
+// it's only used by adapter that supports array argument, zero value disables it.
+// MapColumnFunc maps column of schema migration to sql type, MapColumn is used when it's not configured.
+// DumpStructureFunc returns statements that create the structure of the database, dumping structure is not supported when it's not configured.
+// InspectTablesFunc returns tables of the database along with its columns and indexes, see Inspect.
+// LockFunc acquires a lock that is held across connections and instances, such as advisory lock, see Lock.
+// ExplainThreshold enables logging execution plan of read statement that takes longer than the threshold, it's intended for development.
+// ExplainAnalyze explains slow statement using EXPLAIN ANALYZE, ExplainFunc returns the explain keyword used by the database, see Explain.
+// DropIndexOnTable appends table name to drop index statement, which is required by mysql.
 type Config struct {
-	Placeholder         string
-	Ordinal             bool
-	InsertDefaultValues bool
-	EscapeChar          string
-	ErrorFunc           func(error) error
-	IncrementFunc       func(Adapter) int
+	Placeholder          string
+	Ordinal              bool
+	InsertDefaultValues  bool
+	NoSemicolon          bool
+	OffsetFetch          bool
+	DropIndexOnTable     bool
+	ExplainAnalyze       bool
+	EscapeChar           string
+	ReturningKeyword     string
+	BulkLoadThreshold    int
+	InArrayThreshold     int
+	ExplainThreshold     time.Duration
+	ErrorFunc            func(error) error
+	IncrementFunc        func(context.Context, Adapter) (int, error)
+	ArgumentFunc         func(interface{}) interface{}
+	IsolationFunc        func(sql.IsolationLevel) string
+	StatementTimeoutFunc func(time.Duration) string
+	MapColumnFunc        func(*migrator.Column) string
+	DumpStructureFunc    func(context.Context, Adapter) (string, error)
+	InspectTablesFunc    func(context.Context, Adapter) ([]migrator.TableInfo, error)
+	LockFunc             func(context.Context, Adapter, string) (func() error, error)
+	ExplainFunc          func(analyze bool) string
+	OnConflictFunc       func(onConflict rel.OnConflict, fields []string) string
+	Capabilities         rel.Capabilities
+}
+
+// PoolConfig holds configuration of connection pool.
+// Zero value means the setting will be left as it is.
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
 }
 
 // Adapter definition for database database.
 type Adapter struct {
-	Config    *Config
-	DB        *sql.DB
-	Tx        *sql.Tx
-	savepoint int
+	Config      *Config
+	DB          *sql.DB
+	Tx          *sql.Tx
+	hooks       *rel.PoolHooks
+	releaseConn func()
+	savepoint   int
+}
+
+type executor interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 }
 
 var _ rel.Adapter = (*Adapter)(nil)
@@ -36,6 +89,47 @@ func (adapter *Adapter) Close() error {
 	return adapter.DB.Close()
 }
 
+// SetPool configures connection pool of the adapter.
+// Adapter that is used inside transaction doesn't own the connection pool, thus it's a no-op.
+func (adapter *Adapter) SetPool(pool PoolConfig) {
+	if adapter.DB == nil {
+		return
+	}
+
+	if pool.MaxOpenConns != 0 {
+		adapter.DB.SetMaxOpenConns(pool.MaxOpenConns)
+	}
+
+	if pool.MaxIdleConns != 0 {
+		adapter.DB.SetMaxIdleConns(pool.MaxIdleConns)
+	}
+
+	if pool.ConnMaxLifetime != 0 {
+		adapter.DB.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	}
+}
+
+// SetPoolHooks configures hooks that are called on connection lifecycle events, see rel.PoolHooks.
+// Statement outside transaction is executed using connection that is explicitly acquired from the pool when hooks are configured.
+func (adapter *Adapter) SetPoolHooks(hooks rel.PoolHooks) {
+	adapter.hooks = &hooks
+}
+
+// Stats returns connection pool statistics.
+// Adapter that is used inside transaction returns zero value since it doesn't own the connection pool.
+func (adapter *Adapter) Stats() sql.DBStats {
+	if adapter.DB == nil {
+		return sql.DBStats{}
+	}
+
+	return adapter.DB.Stats()
+}
+
+// Capabilities of the adapter, it supports transaction, savepoint, join and group in addition to capabilities specified in config.
+func (adapter *Adapter) Capabilities() rel.Capabilities {
+	return rel.TransactionCapability | rel.SavepointCapability | rel.JoinCapability | rel.GroupCapability | adapter.Config.Capabilities
+}
+
 // Ping database.
 func (adapter *Adapter) Ping(ctx context.Context) error {
 	return adapter.DB.PingContext(ctx)
@@ -50,13 +144,17 @@ func (adapter *Adapter) Aggregate(ctx context.Context, query rel.Query, mode str
 	)
 
 	start := time.Now()
-	if adapter.Tx != nil {
-		err = adapter.Tx.QueryRowContext(ctx, statement, args...).Scan(&out)
+	if exec, release, aerr := adapter.executor(ctx); aerr != nil {
+		err = aerr
 	} else {
-		err = adapter.DB.QueryRowContext(ctx, statement, args...).Scan(&out)
+		err = exec.QueryRowContext(ctx, statement, args...).Scan(&out)
+		release()
 	}
 
-	go rel.Log(loggers, statement, time.Since(start), err)
+	duration := time.Since(start)
+	rel.LogFunc(ctx, loggers, func() string {
+		return adapter.explainSlow(ctx, statement, args, duration, err)
+	}, duration, err)
 
 	return int(out.Int64), err
 }
@@ -70,15 +168,20 @@ func (adapter *Adapter) Query(ctx context.Context, query rel.Query, loggers ...r
 	)
 
 	start := time.Now()
-	if adapter.Tx != nil {
-		rows, err = adapter.Tx.QueryContext(ctx, statement, args...)
-	} else {
-		rows, err = adapter.DB.QueryContext(ctx, statement, args...)
+	exec, release, err := adapter.executor(ctx)
+	if err == nil {
+		if rows, err = exec.QueryContext(ctx, statement, args...); err != nil {
+			release()
+			release = nil
+		}
 	}
 
-	go rel.Log(loggers, statement, time.Since(start), err)
+	duration := time.Since(start)
+	rel.LogFunc(ctx, loggers, func() string {
+		return adapter.explainSlow(ctx, statement, args, duration, err)
+	}, duration, err)
 
-	return &Cursor{rows}, adapter.Config.ErrorFunc(err)
+	return &Cursor{Rows: rows, release: release}, adapter.Config.ErrorFunc(err)
 }
 
 // Exec performs exec operation.
@@ -89,13 +192,14 @@ func (adapter *Adapter) Exec(ctx context.Context, statement string, args []inter
 	)
 
 	start := time.Now()
-	if adapter.Tx != nil {
-		res, err = adapter.Tx.ExecContext(ctx, statement, args...)
+	if exec, release, aerr := adapter.executor(ctx); aerr != nil {
+		err = aerr
 	} else {
-		res, err = adapter.DB.ExecContext(ctx, statement, args...)
+		res, err = exec.ExecContext(ctx, statement, args...)
+		release()
 	}
 
-	go rel.Log(loggers, statement, time.Since(start), err)
+	rel.LogFunc(ctx, loggers, func() string { return statement }, time.Since(start), err)
 
 	if err != nil {
 		return 0, 0, adapter.Config.ErrorFunc(err)
@@ -110,7 +214,7 @@ func (adapter *Adapter) Exec(ctx context.Context, statement string, args []inter
 // Insert inserts a record to database and returns its id.
 func (adapter *Adapter) Insert(ctx context.Context, query rel.Query, modifies map[string]rel.Modify, loggers ...rel.Logger) (interface{}, error) {
 	var (
-		statement, args = NewBuilder(adapter.Config).Insert(query.Table, modifies)
+		statement, args = NewBuilder(adapter.Config).OnConflict(query.OnConflictQuery).Insert(query.Table, modifies)
 		id, _, err      = adapter.Exec(ctx, statement, args, loggers...)
 	)
 
@@ -119,7 +223,7 @@ func (adapter *Adapter) Insert(ctx context.Context, query rel.Query, modifies ma
 
 // InsertAll inserts all record to database and returns its ids.
 func (adapter *Adapter) InsertAll(ctx context.Context, query rel.Query, fields []string, bulkModifies []map[string]rel.Modify, loggers ...rel.Logger) ([]interface{}, error) {
-	statement, args := NewBuilder(adapter.Config).InsertAll(query.Table, fields, bulkModifies)
+	statement, args := NewBuilder(adapter.Config).OnConflict(query.OnConflictQuery).InsertAll(query.Table, fields, bulkModifies)
 	id, _, err := adapter.Exec(ctx, statement, args, loggers...)
 	if err != nil {
 		return nil, err
@@ -131,7 +235,9 @@ func (adapter *Adapter) InsertAll(ctx context.Context, query rel.Query, fields [
 	)
 
 	if adapter.Config.IncrementFunc != nil {
-		inc = adapter.Config.IncrementFunc(*adapter)
+		if inc, err = adapter.Config.IncrementFunc(ctx, *adapter); err != nil {
+			return nil, adapter.Config.ErrorFunc(err)
+		}
 	}
 
 	if inc < 0 {
@@ -166,27 +272,245 @@ func (adapter *Adapter) Delete(ctx context.Context, query rel.Query, loggers ...
 	return int(deletedCount), err
 }
 
+// Apply schema migration.
+func (adapter *Adapter) Apply(ctx context.Context, migration migrator.Migration) error {
+	var (
+		statements []string
+	)
+
+	switch v := migration.(type) {
+	case migrator.Table:
+		statements = NewBuilder(adapter.Config).Table(v)
+	case migrator.Index:
+		statements = []string{NewBuilder(adapter.Config).Index(v)}
+	default:
+		return fmt.Errorf("sql: unsupported migration %T", migration)
+	}
+
+	for _, statement := range statements {
+		if _, _, err := adapter.Exec(ctx, statement, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DumpStructure returns statements that create the structure of the database.
+func (adapter *Adapter) DumpStructure(ctx context.Context) (string, error) {
+	if adapter.Config.DumpStructureFunc == nil {
+		return "", migrator.ErrNotSupported
+	}
+
+	return adapter.Config.DumpStructureFunc(ctx, *adapter)
+}
+
+// LoadStructure executes statements returned by DumpStructure.
+// Statements are executed using a single connection, so session settings are kept until the structure is loaded.
+func (adapter *Adapter) LoadStructure(ctx context.Context, structure string) error {
+	var (
+		exec = adapter.DB.ExecContext
+	)
+
+	if adapter.Tx != nil {
+		exec = adapter.Tx.ExecContext
+	} else {
+		conn, release, err := adapter.acquire(ctx)
+		if err != nil {
+			return adapter.Config.ErrorFunc(err)
+		}
+
+		defer release()
+		exec = conn.ExecContext
+	}
+
+	for _, statement := range SplitStatements(structure) {
+		if _, err := exec(ctx, statement); err != nil {
+			return adapter.Config.ErrorFunc(err)
+		}
+	}
+
+	return nil
+}
+
+// SplitStatements splits structure into statements, each statement must be terminated by semicolon at the end of the line.
+func SplitStatements(structure string) []string {
+	var (
+		statements []string
+	)
+
+	for _, statement := range strings.Split(structure, ";\n") {
+		if statement = strings.TrimSpace(statement); statement != "" {
+			statements = append(statements, strings.TrimSuffix(statement, ";")+";")
+		}
+	}
+
+	return statements
+}
+
+// InspectTables returns tables of the database along with its columns and indexes.
+func (adapter *Adapter) InspectTables(ctx context.Context) ([]migrator.TableInfo, error) {
+	if adapter.Config.InspectTablesFunc == nil {
+		return nil, migrator.ErrNotSupported
+	}
+
+	return adapter.Config.InspectTablesFunc(ctx, *adapter)
+}
+
+// Lock acquires a lock identified by name that is held across connections and instances until the returned function is called.
+// It's used by migrator to avoid concurrent instances applying the same migrations.
+func (adapter *Adapter) Lock(ctx context.Context, name string) (func() error, error) {
+	if adapter.Config.LockFunc == nil {
+		return nil, migrator.ErrNotSupported
+	}
+
+	return adapter.Config.LockFunc(ctx, *adapter, name)
+}
+
+// Inspect returns tables of the database using two queries.
+// The first query must return table and column name ordered by table, and the second query must return table, index and column name ordered by table, index and position of the column.
+// Column of an index may be null when the index is defined using expression.
+func Inspect(ctx context.Context, adapter Adapter, columnsQuery string, indexesQuery string) ([]migrator.TableInfo, error) {
+	var (
+		tables []migrator.TableInfo
+		index  = make(map[string]int)
+	)
+
+	rows, err := adapter.DB.QueryContext(ctx, columnsQuery)
+	if err != nil {
+		return nil, adapter.Config.ErrorFunc(err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			return nil, err
+		}
+
+		i, ok := index[table]
+		if !ok {
+			i = len(tables)
+			index[table] = i
+			tables = append(tables, migrator.TableInfo{Name: table})
+		}
+
+		tables[i].Columns = append(tables[i].Columns, column)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = adapter.DB.QueryContext(ctx, indexesQuery)
+	if err != nil {
+		return nil, adapter.Config.ErrorFunc(err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			table, name string
+			column      sql.NullString
+		)
+
+		if err := rows.Scan(&table, &name, &column); err != nil {
+			return nil, err
+		}
+
+		i, ok := index[table]
+		if !ok {
+			continue
+		}
+
+		indexes := tables[i].Indexes
+		if n := len(indexes); n == 0 || indexes[n-1].Name != name {
+			indexes = append(indexes, migrator.IndexInfo{Name: name})
+		}
+
+		indexes[len(indexes)-1].Columns = append(indexes[len(indexes)-1].Columns, column.String)
+		tables[i].Indexes = indexes
+	}
+
+	return tables, rows.Err()
+}
+
 // Begin begins a new transaction.
+// Isolation level requested by transaction options is passed to the driver,
+// unless IsolationFunc is configured, then the returned statement is executed before the transaction begins.
+// Statement timeout is applied using statement returned by StatementTimeoutFunc, and ignored when it's not configured.
 func (adapter *Adapter) Begin(ctx context.Context) (rel.Adapter, error) {
 	var (
 		tx        *sql.Tx
+		release   func()
 		savepoint int
 		err       error
+		options   = rel.TransactionOptionsFrom(ctx)
 	)
 
-	if adapter.Tx != nil {
+	if adapter.Tx != nil && options.Isolation != sql.LevelDefault {
+		err = errors.New("sql: isolation level can't be changed inside transaction")
+	} else if adapter.Tx != nil {
 		tx = adapter.Tx
 		savepoint = adapter.savepoint + 1
 		_, _, err = adapter.Exec(ctx, "SAVEPOINT s"+strconv.Itoa(savepoint)+";", []interface{}{})
+	} else if options.Isolation != sql.LevelDefault && adapter.Config.IsolationFunc != nil {
+		release, tx, err = adapter.beginIsolated(ctx, options)
+	} else if adapter.hooks != nil {
+		release, tx, err = adapter.beginConn(ctx, "", &sql.TxOptions{Isolation: options.Isolation, ReadOnly: options.ReadOnly})
 	} else {
-		tx, err = adapter.DB.BeginTx(ctx, nil)
+		tx, err = adapter.DB.BeginTx(ctx, &sql.TxOptions{Isolation: options.Isolation, ReadOnly: options.ReadOnly})
+	}
+
+	txAdapter := &Adapter{
+		Config:      adapter.Config,
+		Tx:          tx,
+		releaseConn: release,
+		savepoint:   savepoint,
+	}
+
+	if err == nil && options.StatementTimeout > 0 && adapter.Config.StatementTimeoutFunc != nil {
+		if _, _, err = txAdapter.Exec(ctx, adapter.Config.StatementTimeoutFunc(options.StatementTimeout), nil); err != nil {
+			_ = txAdapter.Rollback(ctx)
+		}
 	}
 
-	return &Adapter{
-		Config:    adapter.Config,
-		Tx:        tx,
-		savepoint: savepoint,
-	}, err
+	return txAdapter, adapter.Config.ErrorFunc(err)
+}
+
+// beginIsolated begins transaction on a dedicated connection, so the isolation statement only applies to the transaction.
+func (adapter *Adapter) beginIsolated(ctx context.Context, options rel.TransactionOptions) (func(), *sql.Tx, error) {
+	statement := adapter.Config.IsolationFunc(options.Isolation)
+	if statement == "" {
+		return nil, nil, errors.New("sql: isolation level " + options.Isolation.String() + " is not supported")
+	}
+
+	return adapter.beginConn(ctx, statement, &sql.TxOptions{ReadOnly: options.ReadOnly})
+}
+
+// beginConn begins transaction on a dedicated connection after executing the statement if it's not empty.
+func (adapter *Adapter) beginConn(ctx context.Context, statement string, options *sql.TxOptions) (func(), *sql.Tx, error) {
+	conn, release, err := adapter.acquire(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if statement != "" {
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			release()
+			return nil, nil, err
+		}
+	}
+
+	tx, err := conn.BeginTx(ctx, options)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+
+	return release, tx, nil
 }
 
 // Commit commits current transaction.
@@ -199,6 +523,7 @@ func (adapter *Adapter) Commit(ctx context.Context) error {
 		_, _, err = adapter.Exec(ctx, "RELEASE SAVEPOINT s"+strconv.Itoa(adapter.savepoint)+";", []interface{}{})
 	} else {
 		err = adapter.Tx.Commit()
+		adapter.release()
 	}
 
 	return adapter.Config.ErrorFunc(err)
@@ -214,11 +539,64 @@ func (adapter *Adapter) Rollback(ctx context.Context) error {
 		_, _, err = adapter.Exec(ctx, "ROLLBACK TO SAVEPOINT s"+strconv.Itoa(adapter.savepoint)+";", []interface{}{})
 	} else {
 		err = adapter.Tx.Rollback()
+		adapter.release()
 	}
 
 	return adapter.Config.ErrorFunc(err)
 }
 
+// release dedicated connection back to the pool.
+func (adapter *Adapter) release() {
+	if adapter.releaseConn != nil {
+		adapter.releaseConn()
+		adapter.releaseConn = nil
+	}
+}
+
+// acquire a dedicated connection from the pool, the returned function closes the connection and returns it to the pool.
+func (adapter *Adapter) acquire(ctx context.Context) (*sql.Conn, func(), error) {
+	var (
+		conn *sql.Conn
+		err  error
+	)
+
+	if adapter.hooks == nil {
+		if conn, err = adapter.DB.Conn(ctx); err != nil {
+			return nil, nil, err
+		}
+
+		return conn, func() { conn.Close() }, nil
+	}
+
+	done, err := adapter.hooks.Acquire(ctx, adapter.DB.Stats(), func() error {
+		conn, err = adapter.DB.Conn(ctx)
+		return err
+	})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return conn, func() {
+		conn.Close()
+		done()
+	}, nil
+}
+
+// executor returns the transaction, the connection pool, or a dedicated connection when pool hooks are configured.
+// The returned function must be called once the statement is finished.
+func (adapter *Adapter) executor(ctx context.Context) (executor, func(), error) {
+	if adapter.Tx != nil {
+		return adapter.Tx, func() {}, nil
+	}
+
+	if adapter.hooks == nil {
+		return adapter.DB, func() {}, nil
+	}
+
+	return adapter.acquire(ctx)
+}
+
 // New initialize adapter without db.
 func New(config *Config) *Adapter {
 	adapter := &Adapter{
@@ -4,9 +4,13 @@ import (
 	"context"
 	db "database/sql"
 	"errors"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/migrator"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/assert"
 )
@@ -19,7 +23,7 @@ func open(t *testing.T) *Adapter {
 			EscapeChar:          "`",
 			InsertDefaultValues: true,
 			ErrorFunc:           func(err error) error { return err },
-			IncrementFunc:       func(Adapter) int { return -1 },
+			IncrementFunc:       func(context.Context, Adapter) (int, error) { return -1, nil },
 		}
 		adapter = New(config)
 	)
@@ -46,6 +50,122 @@ func TestNew(t *testing.T) {
 	assert.NotNil(t, New(nil))
 }
 
+func TestAdapter_Capabilities(t *testing.T) {
+	var (
+		adapter = New(&Config{Capabilities: rel.WindowCapability})
+	)
+
+	assert.True(t, adapter.Capabilities().Is(rel.TransactionCapability|rel.SavepointCapability|rel.JoinCapability|rel.GroupCapability))
+	assert.True(t, adapter.Capabilities().Is(rel.WindowCapability))
+	assert.False(t, adapter.Capabilities().Is(rel.TwoPhaseCommitCapability))
+}
+
+func TestAdapter_SetPool(t *testing.T) {
+	var (
+		adapter = open(t)
+		repo    = rel.New(adapter)
+	)
+
+	defer adapter.Close()
+
+	adapter.SetPool(PoolConfig{MaxOpenConns: 5, MaxIdleConns: 2, ConnMaxLifetime: time.Minute})
+	assert.Equal(t, 5, repo.Stats().MaxOpenConnections)
+
+	tx, err := adapter.Begin(context.TODO())
+	assert.Nil(t, err)
+	assert.Equal(t, db.DBStats{}, tx.(*Adapter).Stats())
+	assert.NotPanics(t, func() {
+		tx.(*Adapter).SetPool(PoolConfig{MaxOpenConns: 1})
+	})
+	assert.Nil(t, tx.Rollback(context.TODO()))
+}
+
+type poolEvents struct {
+	mutex  sync.Mutex
+	events []string
+}
+
+func (pe *poolEvents) record(event string) {
+	pe.mutex.Lock()
+	defer pe.mutex.Unlock()
+
+	pe.events = append(pe.events, event)
+}
+
+func (pe *poolEvents) hooks() rel.PoolHooks {
+	return rel.PoolHooks{
+		OnAcquire:   func(context.Context, time.Duration) { pe.record("acquire") },
+		OnRelease:   func(context.Context, time.Duration) { pe.record("release") },
+		OnWait:      func(context.Context, time.Duration) { pe.record("wait") },
+		OnExhausted: func(context.Context, db.DBStats) { pe.record("exhausted") },
+	}
+}
+
+func TestAdapter_SetPoolHooks(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = open(t)
+		repo    = rel.New(adapter)
+		pe      poolEvents
+	)
+
+	defer adapter.Close()
+
+	adapter.SetPoolHooks(pe.hooks())
+
+	assert.Nil(t, repo.Insert(ctx, &Name{Name: "hooks"}))
+	assert.Equal(t, []string{"acquire", "release"}, pe.events)
+
+	cur, err := adapter.Query(ctx, rel.From("names"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"acquire", "release", "acquire"}, pe.events)
+	assert.Nil(t, cur.Close())
+	assert.Equal(t, []string{"acquire", "release", "acquire", "release"}, pe.events)
+
+	pe.events = nil
+	assert.Nil(t, repo.Transaction(ctx, func(repo rel.Repository) error {
+		_, err := repo.Count(ctx, "names")
+		return err
+	}))
+	assert.Equal(t, []string{"acquire", "release"}, pe.events)
+
+	pe.events = nil
+	_, err = adapter.Query(ctx, rel.Query{})
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{"acquire", "release"}, pe.events)
+}
+
+func TestAdapter_SetPoolHooks_exhausted(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = open(t)
+		pe      poolEvents
+	)
+
+	defer adapter.Close()
+
+	adapter.SetPool(PoolConfig{MaxOpenConns: 1})
+	adapter.SetPoolHooks(pe.hooks())
+
+	tx, err := adapter.Begin(ctx)
+	assert.Nil(t, err)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_ = tx.Commit(ctx)
+	}()
+
+	count, err := adapter.Aggregate(ctx, rel.From("names"), "count", "id")
+	assert.Nil(t, err)
+	assert.True(t, count >= 0)
+
+	pe.mutex.Lock()
+	defer pe.mutex.Unlock()
+	assert.Equal(t, []string{"acquire", "exhausted"}, pe.events[:2])
+	assert.Contains(t, pe.events, "wait")
+	assert.Len(t, pe.events, 6)
+}
+
 func TestAdapter_Ping(t *testing.T) {
 	var (
 		adapter = open(t)
@@ -223,6 +343,139 @@ func TestAdapter_Transaction_rollback(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestAdapter_Transaction_isolation(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = open(t)
+		repo    = rel.New(adapter)
+	)
+
+	defer adapter.Close()
+
+	err := repo.Transaction(ctx, func(repo rel.Repository) error {
+		_, err := repo.Count(ctx, "names")
+		return err
+	}, rel.Isolation(db.LevelSerializable))
+
+	assert.Nil(t, err)
+
+	err = repo.Transaction(ctx, func(repo rel.Repository) error {
+		return repo.Transaction(ctx, func(repo rel.Repository) error {
+			return nil
+		}, rel.Isolation(db.LevelSerializable))
+	})
+
+	assert.Equal(t, errors.New("sql: isolation level can't be changed inside transaction"), err)
+}
+
+func TestAdapter_Transaction_readOnly(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = open(t)
+		repo    = rel.New(adapter)
+	)
+
+	defer adapter.Close()
+
+	err := repo.Transaction(ctx, func(repo rel.Repository) error {
+		_, err := repo.Count(ctx, "names")
+		return err
+	}, rel.ReadOnly())
+
+	assert.Nil(t, err)
+}
+
+func TestAdapter_Transaction_timeout(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = open(t)
+		repo    = rel.New(adapter)
+	)
+
+	defer adapter.Close()
+
+	err := repo.Transaction(ctx, func(repo rel.Repository) error {
+		return nil
+	}, rel.Timeout(time.Nanosecond))
+
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestAdapter_Transaction_statementTimeout(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = open(t)
+		repo    = rel.New(adapter)
+		timeout time.Duration
+	)
+
+	defer adapter.Close()
+
+	adapter.Config.StatementTimeoutFunc = func(t time.Duration) string {
+		timeout = t
+		return "PRAGMA busy_timeout = " + strconv.FormatInt(int64(t/time.Millisecond), 10) + ";"
+	}
+
+	err := repo.Transaction(ctx, func(repo rel.Repository) error {
+		_, err := repo.Count(ctx, "names")
+		return err
+	}, rel.StatementTimeout(time.Second))
+
+	assert.Nil(t, err)
+	assert.Equal(t, time.Second, timeout)
+
+	adapter.Config.StatementTimeoutFunc = func(t time.Duration) string {
+		return "SET LOCAL statement_timeout = 1000;"
+	}
+
+	err = repo.Transaction(ctx, func(repo rel.Repository) error {
+		return nil
+	}, rel.StatementTimeout(time.Second))
+
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, adapter.Stats().InUse)
+}
+
+func TestAdapter_Transaction_isolationFunc(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = open(t)
+		repo    = rel.New(adapter)
+	)
+
+	defer adapter.Close()
+
+	adapter.Config.IsolationFunc = func(level db.IsolationLevel) string {
+		if level == db.LevelReadUncommitted {
+			return "PRAGMA read_uncommitted = 1;"
+		}
+
+		return ""
+	}
+
+	err := repo.Transaction(ctx, func(repo rel.Repository) error {
+		assert.Equal(t, 1, adapter.Stats().InUse)
+		_, err := repo.Count(ctx, "names")
+		return err
+	}, rel.Isolation(db.LevelReadUncommitted))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0, adapter.Stats().InUse)
+
+	err = repo.Transaction(ctx, func(repo rel.Repository) error {
+		return errors.New("rollback")
+	}, rel.Isolation(db.LevelReadUncommitted))
+
+	assert.Equal(t, errors.New("rollback"), err)
+	assert.Equal(t, 0, adapter.Stats().InUse)
+
+	err = repo.Transaction(ctx, func(repo rel.Repository) error {
+		return nil
+	}, rel.Isolation(db.LevelSerializable))
+
+	assert.Equal(t, errors.New("sql: isolation level Serializable is not supported"), err)
+}
+
 func TestAdapter_Transaction_nestedCommit(t *testing.T) {
 	var (
 		ctx     = context.TODO()
@@ -280,6 +533,21 @@ func TestAdapter_InsertAll_error(t *testing.T) {
 	assert.Nil(t, ids)
 }
 
+func TestAdapter_InsertAll_incrementError(t *testing.T) {
+	var (
+		adapter = open(t)
+		err     = errors.New("error")
+		names   = []Name{{Name: "Luffy"}, {Name: "Zoro"}}
+	)
+	defer adapter.Close()
+
+	adapter.Config.IncrementFunc = func(context.Context, Adapter) (int, error) {
+		return 0, err
+	}
+
+	assert.Equal(t, err, rel.New(adapter).InsertAll(context.TODO(), &names))
+}
+
 func TestAdapter_Transaction_commitError(t *testing.T) {
 	var (
 		adapter = open(t)
@@ -310,3 +578,254 @@ func TestAdapter_Exec_error(t *testing.T) {
 	_, _, err := adapter.Exec(context.TODO(), "error", nil)
 	assert.NotNil(t, err)
 }
+
+func TestAdapter_Apply(t *testing.T) {
+	var (
+		schema  migrator.Schema
+		adapter = open(t)
+		repo    = rel.New(adapter)
+	)
+
+	defer adapter.Close()
+
+	schema.CreateTable("products", func(t *migrator.Table) {
+		t.Column("id", "INTEGER PRIMARY KEY")
+		t.String("name", migrator.Required(true))
+		t.Bool("available", migrator.Default(true))
+	}, migrator.Optional(true))
+	schema.AddColumn("products", "price", migrator.Int, migrator.Default(0))
+	schema.RenameTable("products", "items")
+
+	assert.Nil(t, schema.Apply(context.TODO(), repo))
+
+	_, _, err := adapter.Exec(context.TODO(), "INSERT INTO items (name) VALUES ('book');", nil)
+	assert.Nil(t, err)
+
+	var drop migrator.Schema
+	drop.DropTable("items")
+	assert.Nil(t, drop.Apply(context.TODO(), repo))
+}
+
+func TestAdapter_Apply_index(t *testing.T) {
+	var (
+		schema  migrator.Schema
+		adapter = open(t)
+		repo    = rel.New(adapter)
+	)
+
+	defer adapter.Close()
+
+	schema.CreateTable("accounts", func(t *migrator.Table) {
+		t.Column("id", "INTEGER PRIMARY KEY")
+		t.String("email")
+		t.DateTime("deleted_at")
+	})
+	schema.CreateUniqueIndex("accounts", "accounts_email", []string{"lower(email)"}, migrator.Where("deleted_at IS NULL"))
+
+	assert.Nil(t, schema.Apply(context.TODO(), repo))
+
+	_, _, err := adapter.Exec(context.TODO(), "INSERT INTO accounts (email, deleted_at) VALUES ('kia@example.com', '2020-01-01 00:00:00'), ('KIA@example.com', NULL);", nil)
+	assert.Nil(t, err)
+
+	_, _, err = adapter.Exec(context.TODO(), "INSERT INTO accounts (email) VALUES ('Kia@example.com');", nil)
+	assert.NotNil(t, err)
+
+	var drop migrator.Schema
+	drop.DropIndex("accounts", "accounts_email")
+	drop.DropTable("accounts")
+	assert.Nil(t, drop.Apply(context.TODO(), repo))
+}
+
+func TestAdapter_Apply_key(t *testing.T) {
+	var (
+		schema  migrator.Schema
+		adapter = open(t)
+		repo    = rel.New(adapter)
+	)
+
+	defer adapter.Close()
+
+	schema.CreateTable("carts", func(t *migrator.Table) {
+		t.Column("id", "INTEGER")
+		t.PrimaryKey("id")
+	})
+	schema.CreateTable("cart_items", func(t *migrator.Table) {
+		t.Column("cart_id", "INTEGER")
+		t.String("sku")
+		t.Int("quantity")
+		t.ForeignKey("cart_id", "carts", "id", migrator.OnDelete("CASCADE"))
+		t.Unique([]string{"cart_id", "sku"})
+		t.Check("quantity > 0", migrator.Name("quantity_check"))
+	})
+
+	assert.Nil(t, schema.Apply(context.TODO(), repo))
+
+	_, _, err := adapter.Exec(context.TODO(), "INSERT INTO carts (id) VALUES (1);", nil)
+	assert.Nil(t, err)
+
+	_, _, err = adapter.Exec(context.TODO(), "INSERT INTO cart_items (cart_id, sku, quantity) VALUES (1, 'book', 1);", nil)
+	assert.Nil(t, err)
+
+	_, _, err = adapter.Exec(context.TODO(), "INSERT INTO cart_items (cart_id, sku, quantity) VALUES (1, 'book', 1);", nil)
+	assert.NotNil(t, err)
+
+	_, _, err = adapter.Exec(context.TODO(), "INSERT INTO cart_items (cart_id, sku, quantity) VALUES (1, 'pen', 0);", nil)
+	assert.NotNil(t, err)
+
+	var drop migrator.Schema
+	drop.DropTable("cart_items")
+	drop.DropTable("carts")
+	assert.Nil(t, drop.Apply(context.TODO(), repo))
+}
+
+func TestAdapter_Apply_error(t *testing.T) {
+	var (
+		schema  migrator.Schema
+		adapter = open(t)
+	)
+
+	defer adapter.Close()
+
+	schema.DropTable("unknown_table")
+
+	assert.NotNil(t, adapter.Apply(context.TODO(), schema.Migrations[0]))
+	assert.EqualError(t, adapter.Apply(context.TODO(), nil), "sql: unsupported migration <nil>")
+}
+
+func TestAdapter_DumpStructure(t *testing.T) {
+	var (
+		adapter = open(t)
+	)
+
+	defer adapter.Close()
+
+	_, err := adapter.DumpStructure(context.TODO())
+	assert.Equal(t, migrator.ErrNotSupported, err)
+
+	adapter.Config.DumpStructureFunc = func(ctx context.Context, adapter Adapter) (string, error) {
+		return "CREATE TABLE names (id INTEGER PRIMARY KEY);\n", nil
+	}
+
+	structure, err := adapter.DumpStructure(context.TODO())
+	assert.Nil(t, err)
+	assert.Equal(t, "CREATE TABLE names (id INTEGER PRIMARY KEY);\n", structure)
+}
+
+func TestAdapter_InspectTables(t *testing.T) {
+	var (
+		adapter = open(t)
+	)
+
+	defer adapter.Close()
+
+	_, err := adapter.InspectTables(context.TODO())
+	assert.Equal(t, migrator.ErrNotSupported, err)
+
+	adapter.Config.InspectTablesFunc = func(ctx context.Context, adapter Adapter) ([]migrator.TableInfo, error) {
+		return Inspect(ctx, adapter,
+			"SELECT 'names', 'id' UNION ALL SELECT 'names', 'name';",
+			"SELECT 'names', 'names_name', 'name' UNION ALL SELECT 'names', 'names_lower', NULL UNION ALL SELECT 'others', 'others_id', 'id';",
+		)
+	}
+
+	tables, err := adapter.InspectTables(context.TODO())
+	assert.Nil(t, err)
+	assert.Equal(t, []migrator.TableInfo{
+		{
+			Name:    "names",
+			Columns: []string{"id", "name"},
+			Indexes: []migrator.IndexInfo{
+				{Name: "names_name", Columns: []string{"name"}},
+				{Name: "names_lower", Columns: []string{""}},
+			},
+		},
+	}, tables)
+
+	_, err = Inspect(context.TODO(), *adapter, "error", "")
+	assert.NotNil(t, err)
+}
+
+func TestAdapter_Lock(t *testing.T) {
+	var (
+		adapter  = open(t)
+		unlocked = false
+	)
+
+	defer adapter.Close()
+
+	_, err := adapter.Lock(context.TODO(), "schema_migrations")
+	assert.Equal(t, migrator.ErrNotSupported, err)
+
+	adapter.Config.LockFunc = func(ctx context.Context, adapter Adapter, name string) (func() error, error) {
+		assert.Equal(t, "schema_migrations", name)
+		return func() error {
+			unlocked = true
+			return nil
+		}, nil
+	}
+
+	unlock, err := adapter.Lock(context.TODO(), "schema_migrations")
+	assert.Nil(t, err)
+	assert.Nil(t, unlock())
+	assert.True(t, unlocked)
+}
+
+func TestAdapter_LoadStructure(t *testing.T) {
+	var (
+		adapter   = open(t)
+		structure = `CREATE TABLE structures (
+	id INTEGER PRIMARY KEY,
+	name STRING
+);
+
+INSERT INTO structures (name) VALUES ('a;b');
+`
+	)
+
+	defer adapter.Close()
+
+	assert.Nil(t, adapter.LoadStructure(context.TODO(), structure))
+
+	var name string
+	assert.Nil(t, adapter.DB.QueryRow("SELECT name FROM structures;").Scan(&name))
+	assert.Equal(t, "a;b", name)
+
+	_, _, err := adapter.Exec(context.TODO(), "DROP TABLE structures;", nil)
+	assert.Nil(t, err)
+}
+
+func TestAdapter_LoadStructure_transaction(t *testing.T) {
+	var (
+		adapter = open(t)
+	)
+
+	defer adapter.Close()
+
+	txAdapter, err := adapter.Begin(context.TODO())
+	assert.Nil(t, err)
+
+	assert.Nil(t, txAdapter.(*Adapter).LoadStructure(context.TODO(), "CREATE TABLE structures (id INTEGER PRIMARY KEY);\n"))
+	assert.Nil(t, txAdapter.Rollback(context.TODO()))
+
+	_, _, err = adapter.Exec(context.TODO(), "SELECT * FROM structures;", nil)
+	assert.NotNil(t, err)
+}
+
+func TestAdapter_LoadStructure_error(t *testing.T) {
+	var (
+		adapter = open(t)
+	)
+
+	defer adapter.Close()
+
+	assert.NotNil(t, adapter.LoadStructure(context.TODO(), "CREATE TABLE;\n"))
+}
+
+func TestSplitStatements(t *testing.T) {
+	assert.Equal(t, []string{
+		"CREATE TABLE a (id INT);",
+		"CREATE TABLE b (\n\tid INT\n);",
+		"INSERT INTO a (id) VALUES (1);",
+	}, SplitStatements("CREATE TABLE a (id INT);\n\nCREATE TABLE b (\n\tid INT\n);\nINSERT INTO a (id) VALUES (1)"))
+	assert.Nil(t, SplitStatements("\n\n"))
+}
@@ -1,11 +1,14 @@
 package sql
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/migrator"
 )
 
 // UnescapeCharacter disable field escaping when it starts with this character.
@@ -13,10 +16,14 @@ var UnescapeCharacter byte = '^'
 
 var fieldCache sync.Map
 
+// partitionRank is column name of the record rank within its partition.
+const partitionRank = "rel_rank"
+
 // Builder defines information of query b.
 type Builder struct {
 	config      *Config
 	returnField string
+	onConflict  rel.OnConflict
 	count       int
 }
 
@@ -31,7 +38,7 @@ func (b *Builder) Find(query rel.Query) (string, []interface{}) {
 	b.fields(&buffer, query.SelectQuery.OnlyDistinct, query.SelectQuery.Fields)
 	b.query(&buffer, query)
 
-	return buffer.String(), buffer.Arguments
+	return buffer.String(), b.arguments(buffer.Arguments)
 }
 
 // Aggregate generates query for aggregation.
@@ -54,13 +61,17 @@ func (b *Builder) Aggregate(query rel.Query, mode string, field string) (string,
 
 	b.query(&buffer, query)
 
-	return buffer.String(), buffer.Arguments
+	return buffer.String(), b.arguments(buffer.Arguments)
 }
 
 func (b *Builder) query(buffer *Buffer, query rel.Query) {
-	b.from(buffer, query.Table)
-	b.join(buffer, query.JoinQuery)
-	b.where(buffer, query.WhereQuery)
+	if query.PartitionQuery.Field != "" && query.PartitionQuery.Limit > 0 {
+		b.partition(buffer, query)
+	} else {
+		b.from(buffer, query.Table)
+		b.join(buffer, query.JoinQuery)
+		b.where(buffer, query.WhereQuery)
+	}
 
 	if len(query.GroupQuery.Fields) > 0 {
 		b.groupBy(buffer, query.GroupQuery.Fields)
@@ -75,13 +86,14 @@ func (b *Builder) query(buffer *Buffer, query rel.Query) {
 		buffer.WriteString(string(query.LockQuery))
 	}
 
-	buffer.WriteString(";")
+	b.terminate(buffer)
 }
 
 // Insert generates query for insert.
 func (b *Builder) Insert(table string, modifies map[string]rel.Modify) (string, []interface{}) {
 	var (
 		buffer Buffer
+		fields []string
 		count  = len(modifies)
 	)
 
@@ -101,6 +113,7 @@ func (b *Builder) Insert(table string, modifies map[string]rel.Modify) (string,
 				buffer.WriteString(field)
 				buffer.WriteString(b.config.EscapeChar)
 				buffer.Arguments[i] = mod.Value
+				fields = append(fields, field)
 			}
 
 			if i < count-1 {
@@ -122,16 +135,12 @@ func (b *Builder) Insert(table string, modifies map[string]rel.Modify) (string,
 		buffer.WriteByte(')')
 	}
 
-	if b.returnField != "" {
-		buffer.WriteString(" RETURNING ")
-		buffer.WriteString(b.config.EscapeChar)
-		buffer.WriteString(b.returnField)
-		buffer.WriteString(b.config.EscapeChar)
-	}
+	b.conflict(&buffer, fields)
+	b.returning(&buffer)
 
-	buffer.WriteString(";")
+	b.terminate(&buffer)
 
-	return buffer.String(), buffer.Arguments
+	return buffer.String(), b.arguments(buffer.Arguments)
 }
 
 // InsertAll generates query for multiple insert.
@@ -186,16 +195,12 @@ func (b *Builder) InsertAll(table string, fields []string, bulkModifies []map[st
 		}
 	}
 
-	if b.returnField != "" {
-		buffer.WriteString(" RETURNING ")
-		buffer.WriteString(b.config.EscapeChar)
-		buffer.WriteString(b.returnField)
-		buffer.WriteString(b.config.EscapeChar)
-	}
+	b.conflict(&buffer, fields)
+	b.returning(&buffer)
 
-	buffer.WriteString(";")
+	b.terminate(&buffer)
 
-	return buffer.String(), buffer.Arguments
+	return buffer.String(), b.arguments(buffer.Arguments)
 }
 
 // Update generates query for update.
@@ -239,9 +244,9 @@ func (b *Builder) Update(table string, modifies map[string]rel.Modify, filter re
 
 	b.where(&buffer, filter)
 
-	buffer.WriteString(";")
+	b.terminate(&buffer)
 
-	return buffer.String(), buffer.Arguments
+	return buffer.String(), b.arguments(buffer.Arguments)
 }
 
 // Delete generates query for delete.
@@ -257,9 +262,343 @@ func (b *Builder) Delete(table string, filter rel.FilterQuery) (string, []interf
 
 	b.where(&buffer, filter)
 
-	buffer.WriteString(";")
+	b.terminate(&buffer)
 
-	return buffer.String(), buffer.Arguments
+	return buffer.String(), b.arguments(buffer.Arguments)
+}
+
+// Table generates statements of table migration, each statement must be executed separately.
+func (b *Builder) Table(table migrator.Table) []string {
+	switch table.Op {
+	case migrator.SchemaCreate:
+		return []string{b.createTable(table)}
+	case migrator.SchemaAlter:
+		return b.alterTable(table)
+	case migrator.SchemaRename:
+		return []string{b.renameTable(table)}
+	case migrator.SchemaDrop:
+		return []string{b.dropTable(table)}
+	}
+
+	return nil
+}
+
+func (b *Builder) createTable(table migrator.Table) string {
+	var (
+		buffer Buffer
+	)
+
+	buffer.WriteString("CREATE TABLE ")
+
+	if table.Optional {
+		buffer.WriteString("IF NOT EXISTS ")
+	}
+
+	buffer.WriteString(b.escape(table.Name))
+	buffer.WriteString(" (")
+
+	for i, def := range table.Definitions {
+		if i > 0 {
+			buffer.WriteString(", ")
+		}
+
+		switch v := def.(type) {
+		case migrator.Column:
+			b.column(&buffer, v)
+		case migrator.Key:
+			b.key(&buffer, v)
+		}
+	}
+
+	buffer.WriteByte(')')
+	b.options(&buffer, table.Options)
+	b.terminate(&buffer)
+
+	return buffer.String()
+}
+
+func (b *Builder) alterTable(table migrator.Table) []string {
+	var (
+		statements = make([]string, 0, len(table.Definitions))
+	)
+
+	for _, def := range table.Definitions {
+		var (
+			buffer Buffer
+		)
+
+		buffer.WriteString("ALTER TABLE ")
+		buffer.WriteString(b.escape(table.Name))
+		buffer.WriteByte(' ')
+
+		switch v := def.(type) {
+		case migrator.Column:
+			switch v.Op {
+			case migrator.SchemaCreate:
+				buffer.WriteString("ADD COLUMN ")
+				b.column(&buffer, v)
+			case migrator.SchemaRename:
+				buffer.WriteString("RENAME COLUMN ")
+				buffer.WriteString(b.escape(v.Name))
+				buffer.WriteString(" TO ")
+				buffer.WriteString(b.escape(v.Rename))
+			case migrator.SchemaDrop:
+				buffer.WriteString("DROP COLUMN ")
+				buffer.WriteString(b.escape(v.Name))
+			}
+		case migrator.Key:
+			switch v.Op {
+			case migrator.SchemaCreate:
+				buffer.WriteString("ADD ")
+				b.key(&buffer, v)
+			case migrator.SchemaDrop:
+				buffer.WriteString("DROP CONSTRAINT ")
+				buffer.WriteString(b.escape(v.Name))
+			}
+		}
+
+		b.terminate(&buffer)
+		statements = append(statements, buffer.String())
+	}
+
+	return statements
+}
+
+func (b *Builder) renameTable(table migrator.Table) string {
+	var (
+		buffer Buffer
+	)
+
+	buffer.WriteString("ALTER TABLE ")
+	buffer.WriteString(b.escape(table.Name))
+	buffer.WriteString(" RENAME TO ")
+	buffer.WriteString(b.escape(table.Rename))
+	b.terminate(&buffer)
+
+	return buffer.String()
+}
+
+func (b *Builder) dropTable(table migrator.Table) string {
+	var (
+		buffer Buffer
+	)
+
+	buffer.WriteString("DROP TABLE ")
+
+	if table.Optional {
+		buffer.WriteString("IF EXISTS ")
+	}
+
+	buffer.WriteString(b.escape(table.Name))
+	b.terminate(&buffer)
+
+	return buffer.String()
+}
+
+// Index generates statement of index migration.
+func (b *Builder) Index(index migrator.Index) string {
+	var (
+		buffer Buffer
+	)
+
+	switch index.Op {
+	case migrator.SchemaCreate:
+		buffer.WriteString("CREATE ")
+		if index.Unique {
+			buffer.WriteString("UNIQUE ")
+		}
+
+		buffer.WriteString("INDEX ")
+		if index.Concurrent {
+			buffer.WriteString("CONCURRENTLY ")
+		}
+
+		if index.Optional {
+			buffer.WriteString("IF NOT EXISTS ")
+		}
+
+		buffer.WriteString(b.escape(index.Name))
+		buffer.WriteString(" ON ")
+		buffer.WriteString(b.escape(index.Table))
+		buffer.WriteByte(' ')
+		b.columns(&buffer, index.Columns)
+		b.options(&buffer, index.Options)
+
+		if index.Filter != "" {
+			buffer.WriteString(" WHERE ")
+			buffer.WriteString(index.Filter)
+		}
+	case migrator.SchemaDrop:
+		buffer.WriteString("DROP INDEX ")
+		if index.Concurrent {
+			buffer.WriteString("CONCURRENTLY ")
+		}
+
+		if index.Optional {
+			buffer.WriteString("IF EXISTS ")
+		}
+
+		buffer.WriteString(b.escape(index.Name))
+
+		if b.config.DropIndexOnTable {
+			buffer.WriteString(" ON ")
+			buffer.WriteString(b.escape(index.Table))
+		}
+
+		b.options(&buffer, index.Options)
+	}
+
+	b.terminate(&buffer)
+	return buffer.String()
+}
+
+func (b *Builder) column(buffer *Buffer, column migrator.Column) {
+	mapColumn := b.config.MapColumnFunc
+	if mapColumn == nil {
+		mapColumn = MapColumn
+	}
+
+	buffer.WriteString(b.escape(column.Name))
+	buffer.WriteByte(' ')
+	buffer.WriteString(mapColumn(&column))
+
+	if column.Unique {
+		buffer.WriteString(" UNIQUE")
+	}
+
+	if column.Required {
+		buffer.WriteString(" NOT NULL")
+	}
+
+	if column.Default != nil {
+		buffer.WriteString(" DEFAULT ")
+		buffer.WriteString(b.value(column.Default))
+	}
+
+	b.options(buffer, column.Options)
+}
+
+func (b *Builder) key(buffer *Buffer, key migrator.Key) {
+	if key.Name != "" {
+		buffer.WriteString("CONSTRAINT ")
+		buffer.WriteString(b.escape(key.Name))
+		buffer.WriteByte(' ')
+	}
+
+	buffer.WriteString(string(key.Type))
+	buffer.WriteByte(' ')
+
+	if key.Type == migrator.CheckKey {
+		buffer.WriteByte('(')
+		buffer.WriteString(key.Expression)
+		buffer.WriteByte(')')
+	} else {
+		b.columns(buffer, key.Columns)
+	}
+
+	if key.Type == migrator.ForeignKey {
+		buffer.WriteString(" REFERENCES ")
+		buffer.WriteString(b.escape(key.Reference.Table))
+		buffer.WriteByte(' ')
+		b.columns(buffer, key.Reference.Columns)
+
+		if key.Reference.OnDelete != "" {
+			buffer.WriteString(" ON DELETE ")
+			buffer.WriteString(key.Reference.OnDelete)
+		}
+
+		if key.Reference.OnUpdate != "" {
+			buffer.WriteString(" ON UPDATE ")
+			buffer.WriteString(key.Reference.OnUpdate)
+		}
+	}
+
+	b.options(buffer, key.Options)
+}
+
+func (b *Builder) columns(buffer *Buffer, columns []string) {
+	buffer.WriteByte('(')
+
+	for i, column := range columns {
+		if i > 0 {
+			buffer.WriteString(", ")
+		}
+
+		buffer.WriteString(b.escape(column))
+	}
+
+	buffer.WriteByte(')')
+}
+
+func (b *Builder) options(buffer *Buffer, options string) {
+	if options == "" {
+		return
+	}
+
+	buffer.WriteByte(' ')
+	buffer.WriteString(options)
+}
+
+// value formats value as sql literal.
+func (b *Builder) value(value interface{}) string {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return "TRUE"
+		}
+
+		return "FALSE"
+	case string:
+		return "'" + strings.Replace(v, "'", "''", -1) + "'"
+	case time.Time:
+		return "'" + v.UTC().Format("2006-01-02 15:04:05") + "'"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// MapColumn returns sql type of the column, the type is compatible with mysql.
+func MapColumn(column *migrator.Column) string {
+	var (
+		typ string
+	)
+
+	switch column.Type {
+	case migrator.ID:
+		return "INT UNSIGNED AUTO_INCREMENT PRIMARY KEY"
+	case migrator.BigID:
+		return "BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY"
+	case migrator.Int, migrator.BigInt, migrator.Text:
+		typ = string(column.Type) + size(column.Limit)
+	case migrator.Float:
+		typ = "FLOAT" + size(column.Precision)
+	case migrator.Decimal:
+		typ = "DECIMAL"
+		if column.Precision > 0 {
+			typ += "(" + strconv.Itoa(column.Precision) + "," + strconv.Itoa(column.Scale) + ")"
+		}
+	case migrator.String:
+		typ = "VARCHAR(255)"
+		if column.Limit > 0 {
+			typ = "VARCHAR" + size(column.Limit)
+		}
+	default:
+		typ = string(column.Type)
+	}
+
+	if column.Unsigned {
+		typ += " UNSIGNED"
+	}
+
+	return typ
+}
+
+func size(n int) string {
+	if n <= 0 {
+		return ""
+	}
+
+	return "(" + strconv.Itoa(n) + ")"
 }
 
 func (b *Builder) fields(buffer *Buffer, distinct bool, fields []string) {
@@ -295,6 +634,28 @@ func (b *Builder) from(buffer *Buffer, table string) {
 	buffer.WriteString(b.config.EscapeChar)
 }
 
+// partition selects from subquery that ranks records of each partition using window function, and filters records within the limit.
+func (b *Builder) partition(buffer *Buffer, query rel.Query) {
+	buffer.WriteString(" FROM (SELECT ")
+	buffer.WriteString(b.escape(query.Table + ".*"))
+	buffer.WriteString(",ROW_NUMBER() OVER (PARTITION BY ")
+	buffer.WriteString(b.escape(query.PartitionQuery.Field))
+	b.orderBy(buffer, query.SortQuery)
+	buffer.WriteString(") AS ")
+	buffer.WriteString(b.escape(partitionRank))
+	b.from(buffer, query.Table)
+	b.join(buffer, query.JoinQuery)
+	b.where(buffer, query.WhereQuery)
+	buffer.WriteString(") AS ")
+	buffer.WriteString(b.config.EscapeChar)
+	buffer.WriteString(query.Table)
+	buffer.WriteString(b.config.EscapeChar)
+	buffer.WriteString(" WHERE ")
+	buffer.WriteString(b.escape(partitionRank))
+	buffer.WriteString("<=")
+	buffer.WriteString(strconv.Itoa(query.PartitionQuery.Limit))
+}
+
 func (b *Builder) join(buffer *Buffer, joins []rel.JoinQuery) {
 	if len(joins) == 0 {
 		return
@@ -309,6 +670,14 @@ func (b *Builder) join(buffer *Buffer, joins []rel.JoinQuery) {
 			buffer.WriteString(b.config.EscapeChar)
 			buffer.WriteString(join.Table)
 			buffer.WriteString(b.config.EscapeChar)
+
+			if join.Alias != "" {
+				buffer.WriteString(" AS ")
+				buffer.WriteString(b.config.EscapeChar)
+				buffer.WriteString(join.Alias)
+				buffer.WriteString(b.config.EscapeChar)
+			}
+
 			buffer.WriteString(" ON ")
 			buffer.WriteString(b.escape(join.From))
 			buffer.WriteString("=")
@@ -377,6 +746,11 @@ func (b *Builder) orderBy(buffer *Buffer, orders []rel.SortQuery) {
 }
 
 func (b *Builder) limitOffset(buffer *Buffer, limit rel.Limit, offset rel.Offset) {
+	if b.config.OffsetFetch {
+		b.offsetFetch(buffer, limit, offset)
+		return
+	}
+
 	if limit > 0 {
 		buffer.WriteString(" LIMIT ")
 		buffer.WriteString(strconv.Itoa(int(limit)))
@@ -388,6 +762,20 @@ func (b *Builder) limitOffset(buffer *Buffer, limit rel.Limit, offset rel.Offset
 	}
 }
 
+func (b *Builder) offsetFetch(buffer *Buffer, limit rel.Limit, offset rel.Offset) {
+	if offset > 0 {
+		buffer.WriteString(" OFFSET ")
+		buffer.WriteString(strconv.Itoa(int(offset)))
+		buffer.WriteString(" ROWS")
+	}
+
+	if limit > 0 {
+		buffer.WriteString(" FETCH NEXT ")
+		buffer.WriteString(strconv.Itoa(int(limit)))
+		buffer.WriteString(" ROWS ONLY")
+	}
+}
+
 func (b *Builder) filter(buffer *Buffer, filter rel.FilterQuery) {
 	switch filter.Type {
 	case rel.FilterAndOp:
@@ -482,6 +870,19 @@ func (b *Builder) buildInclusion(buffer *Buffer, filter rel.FilterQuery) {
 
 	buffer.WriteString(b.escape(filter.Field))
 
+	if b.config.InArrayThreshold > 0 && len(values) > b.config.InArrayThreshold {
+		if filter.Type == rel.FilterInOp {
+			buffer.WriteString("=ANY(")
+		} else {
+			buffer.WriteString("<>ALL(")
+		}
+
+		buffer.WriteString(b.ph())
+		buffer.WriteByte(')')
+		buffer.Append(values)
+		return
+	}
+
 	if filter.Type == rel.FilterInOp {
 		buffer.WriteString(" IN (")
 	} else {
@@ -497,6 +898,48 @@ func (b *Builder) buildInclusion(buffer *Buffer, filter rel.FilterQuery) {
 	buffer.Append(values...)
 }
 
+func (b *Builder) conflict(buffer *Buffer, fields []string) {
+	if b.onConflict == "" || b.config.OnConflictFunc == nil {
+		return
+	}
+
+	buffer.WriteString(b.config.OnConflictFunc(b.onConflict, fields))
+}
+
+func (b *Builder) returning(buffer *Buffer) {
+	if b.returnField == "" {
+		return
+	}
+
+	if b.config.ReturningKeyword != "" {
+		buffer.WriteByte(' ')
+		buffer.WriteString(b.config.ReturningKeyword)
+		buffer.WriteByte(' ')
+	} else {
+		buffer.WriteString(" RETURNING ")
+	}
+
+	buffer.WriteString(b.escape(b.returnField))
+}
+
+func (b *Builder) terminate(buffer *Buffer) {
+	if !b.config.NoSemicolon {
+		buffer.WriteByte(';')
+	}
+}
+
+func (b *Builder) arguments(args []interface{}) []interface{} {
+	if b.config.ArgumentFunc == nil {
+		return args
+	}
+
+	for i := range args {
+		args[i] = b.config.ArgumentFunc(args[i])
+	}
+
+	return args
+}
+
 func (b *Builder) ph() string {
 	if b.config.Ordinal {
 		b.count++
@@ -544,6 +987,12 @@ func (b *Builder) Returning(field string) *Builder {
 	return b
 }
 
+// OnConflict sets conflict resolution of insert query, it's rendered using OnConflictFunc of the config.
+func (b *Builder) OnConflict(onConflict rel.OnConflict) *Builder {
+	b.onConflict = onConflict
+	return b
+}
+
 // NewBuilder create new SQL builder.
 func NewBuilder(config *Config) *Builder {
 	return &Builder{
@@ -2,9 +2,12 @@ package sql
 
 import (
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/migrator"
 	"github.com/Fs02/rel/sort"
 	"github.com/Fs02/rel/where"
 	"github.com/stretchr/testify/assert"
@@ -71,6 +74,11 @@ func TestBuilder_Find(t *testing.T) {
 			nil,
 			query.JoinOn("transactions", "transactions.id", "users.transaction_id"),
 		},
+		{
+			"SELECT * FROM `transactions` LEFT JOIN `users` AS `buyer` ON `transactions`.`user_id`=`buyer`.`id`;",
+			nil,
+			rel.Build("transactions", rel.JoinQuery{Mode: "LEFT JOIN", Table: "users", Alias: "buyer", From: "transactions.user_id", To: "buyer.id"}),
+		},
 		{
 			"SELECT * FROM `users` WHERE `id`=?;",
 			[]interface{}{10},
@@ -101,6 +109,16 @@ func TestBuilder_Find(t *testing.T) {
 			nil,
 			query.Offset(10).Limit(10),
 		},
+		{
+			"SELECT `id`,`user_id` FROM (SELECT `users`.*,ROW_NUMBER() OVER (PARTITION BY `user_id` ORDER BY `created_at` DESC) AS `rel_rank` FROM `users` WHERE `user_id` IN (?,?)) AS `users` WHERE `rel_rank`<=3 ORDER BY `created_at` DESC;",
+			[]interface{}{1, 2},
+			query.Select("id", "user_id").Where(where.In("user_id", 1, 2)).Partition("user_id", 3).SortDesc("created_at"),
+		},
+		{
+			"SELECT * FROM `users` ORDER BY `created_at` DESC;",
+			nil,
+			query.Partition("", 3).SortDesc("created_at"),
+		},
 	}
 
 	for _, test := range tests {
@@ -162,6 +180,11 @@ func TestBuilder_Find_ordinal(t *testing.T) {
 			[]interface{}{1000},
 			query.Distinct().Group("type").Having(where.Gt("price", 1000)),
 		},
+		{
+			"SELECT * FROM (SELECT \"users\".*,ROW_NUMBER() OVER (PARTITION BY \"user_id\" ORDER BY \"id\" ASC) AS \"rel_rank\" FROM \"users\" WHERE \"active\"=$1) AS \"users\" WHERE \"rel_rank\"<=1 ORDER BY \"id\" ASC LIMIT 10;",
+			[]interface{}{true},
+			query.Where(where.Eq("active", true)).Partition("user_id", 1).SortAsc("id").Limit(10),
+		},
 		{
 			"SELECT * FROM \"users\" JOIN \"transactions\" ON \"transactions\".\"id\"=\"users\".\"transaction_id\";",
 			nil,
@@ -388,6 +411,35 @@ func TestBuilder_InsertAll(t *testing.T) {
 	assert.Equal(t, []interface{}{"foo", 10, "boo", 20}, args)
 }
 
+func TestBuilder_Insert_onConflict(t *testing.T) {
+	var (
+		config = &Config{
+			Placeholder: "?",
+			EscapeChar:  "`",
+			OnConflictFunc: func(onConflict rel.OnConflict, fields []string) string {
+				return " ON CONFLICT " + string(onConflict) + " " + strings.Join(fields, ",")
+			},
+		}
+		modifies = map[string]rel.Modify{
+			"name": rel.Set("name", "foo"),
+		}
+		bulkModifies = []map[string]rel.Modify{
+			{"name": rel.Set("name", "foo")},
+		}
+	)
+
+	statement, args := NewBuilder(config).OnConflict(rel.OnConflictIgnore()).Insert("users", modifies)
+	assert.Equal(t, "INSERT INTO `users` (`name`) VALUES (?) ON CONFLICT IGNORE name;", statement)
+	assert.Equal(t, []interface{}{"foo"}, args)
+
+	statement, args = NewBuilder(config).OnConflict(rel.OnConflictReplace()).InsertAll("users", []string{"name"}, bulkModifies)
+	assert.Equal(t, "INSERT INTO `users` (`name`) VALUES (?) ON CONFLICT REPLACE name;", statement)
+	assert.Equal(t, []interface{}{"foo"}, args)
+
+	statement, _ = NewBuilder(config).Insert("users", modifies)
+	assert.Equal(t, "INSERT INTO `users` (`name`) VALUES (?);", statement)
+}
+
 func TestBuilder_InsertAll_ordinal(t *testing.T) {
 	var (
 		config = &Config{
@@ -527,6 +579,230 @@ func TestBuilder_Delete_ordinal(t *testing.T) {
 	assert.Equal(t, []interface{}{1}, args)
 }
 
+func TestBuilder_Table(t *testing.T) {
+	var (
+		config = &Config{
+			Placeholder: "?",
+			EscapeChar:  "`",
+		}
+		builder = NewBuilder(config)
+		now     = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	)
+
+	tests := []struct {
+		result []string
+		table  func(schema *migrator.Schema)
+	}{
+		{
+			result: []string{"CREATE TABLE `products` (`id` INT UNSIGNED AUTO_INCREMENT PRIMARY KEY, `name` VARCHAR(255), `description` TEXT);"},
+			table: func(schema *migrator.Schema) {
+				schema.CreateTable("products", func(t *migrator.Table) {
+					t.ID("id")
+					t.String("name")
+					t.Text("description")
+				})
+			},
+		},
+		{
+			result: []string{"CREATE TABLE IF NOT EXISTS `columns` (`bool` BOOL NOT NULL DEFAULT FALSE, `int` INT(11) UNSIGNED, `bigint` BIGINT DEFAULT 0, `float` FLOAT(24), `decimal` DECIMAL(6,2) UNIQUE, `string` VARCHAR(144) DEFAULT 'it''s', `text` TEXT(1000), `date` DATE, `datetime` DATETIME DEFAULT '2020-01-02 03:04:05', `time` TIME, `timestamp` TIMESTAMP, `blob` BLOB) ENGINE=InnoDB;"},
+			table: func(schema *migrator.Schema) {
+				schema.CreateTable("columns", func(t *migrator.Table) {
+					t.Bool("bool", migrator.Required(true), migrator.Default(false))
+					t.Int("int", migrator.Limit(11), migrator.Unsigned(true))
+					t.BigInt("bigint", migrator.Default(0))
+					t.Float("float", migrator.Precision(24))
+					t.Decimal("decimal", migrator.Precision(6), migrator.Scale(2), migrator.Unique(true))
+					t.String("string", migrator.Limit(144), migrator.Default("it's"))
+					t.Text("text", migrator.Limit(1000))
+					t.Date("date")
+					t.DateTime("datetime", migrator.Default(now))
+					t.Time("time")
+					t.Timestamp("timestamp")
+					t.Column("blob", "BLOB")
+				}, migrator.Optional(true), migrator.Options("ENGINE=InnoDB"))
+			},
+		},
+		{
+			result: []string{
+				"ALTER TABLE `users` ADD COLUMN `verified` BOOL;",
+				"ALTER TABLE `users` RENAME COLUMN `name` TO `fullname`;",
+				"ALTER TABLE `users` DROP COLUMN `age`;",
+			},
+			table: func(schema *migrator.Schema) {
+				schema.AlterTable("users", func(t *migrator.AlterTable) {
+					t.Bool("verified")
+					t.RenameColumn("name", "fullname")
+					t.DropColumn("age")
+				})
+			},
+		},
+		{
+			result: []string{"CREATE TABLE `order_items` (`order_id` INT, `product_id` INT, `price` DECIMAL(8,2), PRIMARY KEY (`order_id`, `product_id`), CONSTRAINT `order_items_order_fk` FOREIGN KEY (`order_id`) REFERENCES `orders` (`id`) ON DELETE CASCADE ON UPDATE CASCADE, FOREIGN KEY (`product_id`) REFERENCES `products` (`id`) DEFERRABLE, UNIQUE (`order_id`, `price`), CONSTRAINT `price_check` CHECK (price >= 0));"},
+			table: func(schema *migrator.Schema) {
+				schema.CreateTable("order_items", func(t *migrator.Table) {
+					t.Int("order_id")
+					t.Int("product_id")
+					t.Decimal("price", migrator.Precision(8), migrator.Scale(2))
+					t.PrimaryKeys([]string{"order_id", "product_id"})
+					t.ForeignKey("order_id", "orders", "id", migrator.Name("order_items_order_fk"), migrator.OnDelete("CASCADE"), migrator.OnUpdate("CASCADE"))
+					t.ForeignKey("product_id", "products", "id", migrator.Options("DEFERRABLE"))
+					t.Unique([]string{"order_id", "price"})
+					t.Check("price >= 0", migrator.Name("price_check"))
+				})
+			},
+		},
+		{
+			result: []string{
+				"ALTER TABLE `users` ADD CONSTRAINT `users_group_fk` FOREIGN KEY (`group_id`) REFERENCES `groups` (`id`) ON DELETE SET NULL;",
+				"ALTER TABLE `users` DROP CONSTRAINT `users_email_key`;",
+			},
+			table: func(schema *migrator.Schema) {
+				schema.AlterTable("users", func(t *migrator.AlterTable) {
+					t.ForeignKey("group_id", "groups", "id", migrator.Name("users_group_fk"), migrator.OnDelete("SET NULL"))
+					t.DropKey("users_email_key")
+				})
+			},
+		},
+		{
+			result: []string{"ALTER TABLE `trxs` RENAME TO `transactions`;"},
+			table: func(schema *migrator.Schema) {
+				schema.RenameTable("trxs", "transactions")
+			},
+		},
+		{
+			result: []string{"DROP TABLE `logs`;"},
+			table: func(schema *migrator.Schema) {
+				schema.DropTable("logs")
+			},
+		},
+		{
+			result: []string{"DROP TABLE IF EXISTS `logs`;"},
+			table: func(schema *migrator.Schema) {
+				schema.DropTable("logs", migrator.Optional(true))
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.result[0], func(t *testing.T) {
+			var schema migrator.Schema
+			test.table(&schema)
+
+			assert.Equal(t, test.result, builder.Table(schema.Migrations[0].(migrator.Table)))
+		})
+	}
+}
+
+func TestBuilder_Index(t *testing.T) {
+	var (
+		config = &Config{
+			Placeholder: "?",
+			EscapeChar:  "`",
+		}
+		builder = NewBuilder(config)
+	)
+
+	tests := []struct {
+		result string
+		index  func(schema *migrator.Schema)
+	}{
+		{
+			result: "CREATE INDEX `users_name` ON `users` (`name`);",
+			index: func(schema *migrator.Schema) {
+				schema.CreateIndex("users", "users_name", []string{"name"})
+			},
+		},
+		{
+			result: "CREATE UNIQUE INDEX IF NOT EXISTS `users_email` ON `users` (lower(`email`), `tenant_id`) WHERE deleted_at IS NULL;",
+			index: func(schema *migrator.Schema) {
+				schema.CreateUniqueIndex("users", "users_email", []string{"lower(email)", "tenant_id"}, migrator.Optional(true), migrator.Where("deleted_at IS NULL"))
+			},
+		},
+		{
+			result: "CREATE INDEX CONCURRENTLY `users_tags` ON `users` (`tags`) USING gin;",
+			index: func(schema *migrator.Schema) {
+				schema.CreateIndex("users", "users_tags", []string{"tags"}, migrator.Concurrent(true), migrator.Options("USING gin"))
+			},
+		},
+		{
+			result: "DROP INDEX `users_name`;",
+			index: func(schema *migrator.Schema) {
+				schema.DropIndex("users", "users_name")
+			},
+		},
+		{
+			result: "DROP INDEX CONCURRENTLY IF EXISTS `users_name`;",
+			index: func(schema *migrator.Schema) {
+				schema.DropIndex("users", "users_name", migrator.Concurrent(true), migrator.Optional(true))
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.result, func(t *testing.T) {
+			var schema migrator.Schema
+			test.index(&schema)
+
+			assert.Equal(t, test.result, builder.Index(schema.Migrations[0].(migrator.Index)))
+		})
+	}
+}
+
+func TestBuilder_Index_dropIndexOnTable(t *testing.T) {
+	var (
+		config = &Config{
+			Placeholder:      "?",
+			EscapeChar:       "`",
+			DropIndexOnTable: true,
+		}
+		builder = NewBuilder(config)
+		schema  migrator.Schema
+	)
+
+	schema.DropIndex("users", "users_name")
+
+	assert.Equal(t, "DROP INDEX `users_name` ON `users`;", builder.Index(schema.Migrations[0].(migrator.Index)))
+}
+
+func TestBuilder_Table_mapColumn(t *testing.T) {
+	var (
+		config = &Config{
+			Placeholder: "$",
+			EscapeChar:  "\"",
+			Ordinal:     true,
+			MapColumnFunc: func(column *migrator.Column) string {
+				return "SERIAL"
+			},
+		}
+		builder = NewBuilder(config)
+		schema  migrator.Schema
+	)
+
+	schema.AddColumn("users", "id", migrator.ID)
+
+	assert.Equal(t, []string{"ALTER TABLE \"users\" ADD COLUMN \"id\" SERIAL;"}, builder.Table(schema.Migrations[0].(migrator.Table)))
+}
+
+func TestMapColumn(t *testing.T) {
+	tests := []struct {
+		result string
+		column migrator.Column
+	}{
+		{"BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY", migrator.Column{Type: migrator.BigID}},
+		{"INT", migrator.Column{Type: migrator.Int}},
+		{"BIGINT(20) UNSIGNED", migrator.Column{Type: migrator.BigInt, Limit: 20, Unsigned: true}},
+		{"DECIMAL", migrator.Column{Type: migrator.Decimal}},
+		{"VARCHAR(255)", migrator.Column{Type: migrator.String}},
+		{"JSON", migrator.Column{Type: "JSON"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.result, func(t *testing.T) {
+			assert.Equal(t, test.result, MapColumn(&test.column))
+		})
+	}
+}
+
 func TestBuilder_Select(t *testing.T) {
 	var (
 		config = &Config{
@@ -1238,6 +1514,58 @@ func TestBuilder_Filter_ordinal(t *testing.T) {
 	}
 }
 
+func TestBuilder_Filter_inArray(t *testing.T) {
+	var (
+		config = &Config{
+			Placeholder:      "$",
+			EscapeChar:       "\"",
+			Ordinal:          true,
+			InArrayThreshold: 2,
+		}
+	)
+
+	tests := []struct {
+		QueryString string
+		Args        []interface{}
+		Filter      rel.FilterQuery
+	}{
+		{
+			"\"field\" IN ($1,$2)",
+			[]interface{}{"value1", "value2"},
+			where.In("field", "value1", "value2"),
+		},
+		{
+			"\"field\"=ANY($1)",
+			[]interface{}{[]interface{}{"value1", "value2", "value3"}},
+			where.In("field", "value1", "value2", "value3"),
+		},
+		{
+			"\"field\"<>ALL($1)",
+			[]interface{}{[]interface{}{"value1", "value2", "value3"}},
+			where.Nin("field", "value1", "value2", "value3"),
+		},
+		{
+			"(\"field1\"=ANY($1) AND \"field2\"=$2)",
+			[]interface{}{[]interface{}{1, 2, 3}, "value"},
+			where.And(where.In("field1", 1, 2, 3), where.Eq("field2", "value")),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.QueryString, func(t *testing.T) {
+			var (
+				buffer  Buffer
+				builder = NewBuilder(config)
+			)
+
+			builder.filter(&buffer, test.Filter)
+
+			assert.Equal(t, test.QueryString, buffer.String())
+			assert.Equal(t, test.Args, buffer.Arguments)
+		})
+	}
+}
+
 func TestBuilder_Lock(t *testing.T) {
 	var (
 		config = &Config{
@@ -1252,3 +1580,88 @@ func TestBuilder_Lock(t *testing.T) {
 	assert.Equal(t, "SELECT * FROM `users` FOR UPDATE;", qs)
 	assert.Nil(t, args)
 }
+
+func TestBuilder_argumentFunc(t *testing.T) {
+	var (
+		config = &Config{
+			Placeholder: "?",
+			EscapeChar:  "`",
+			ArgumentFunc: func(value interface{}) interface{} {
+				return fmt.Sprint(value)
+			},
+		}
+		builder  = NewBuilder(config)
+		query    = rel.From("users").Where(where.Eq("id", 10), where.In("status", 1, 2))
+		qs, args = builder.Find(query)
+	)
+
+	assert.Equal(t, "SELECT * FROM `users` WHERE (`id`=? AND `status` IN (?,?));", qs)
+	assert.Equal(t, []interface{}{"10", "1", "2"}, args)
+}
+
+func TestBuilder_noSemicolon(t *testing.T) {
+	var (
+		config = &Config{
+			Placeholder:      "@p",
+			Ordinal:          true,
+			EscapeChar:       "`",
+			NoSemicolon:      true,
+			ReturningKeyword: "THEN RETURN",
+		}
+	)
+
+	qs, args := NewBuilder(config).Find(rel.From("users").Where(where.Eq("id", 10)))
+	assert.Equal(t, "SELECT * FROM `users` WHERE `id`=@p1", qs)
+	assert.Equal(t, []interface{}{10}, args)
+
+	qs, args = NewBuilder(config).Returning("id").Insert("users", map[string]rel.Modify{"name": rel.Set("name", "foo")})
+	assert.Equal(t, "INSERT INTO `users` (`name`) VALUES (@p1) THEN RETURN `id`", qs)
+	assert.Equal(t, []interface{}{"foo"}, args)
+
+	qs, args = NewBuilder(config).Update("users", map[string]rel.Modify{"name": rel.Set("name", "foo")}, where.Eq("id", 1))
+	assert.Equal(t, "UPDATE `users` SET `name`=@p1 WHERE `id`=@p2", qs)
+	assert.Equal(t, []interface{}{"foo", 1}, args)
+
+	qs, args = NewBuilder(config).Delete("users", where.Eq("id", 1))
+	assert.Equal(t, "DELETE FROM `users` WHERE `id`=@p1", qs)
+	assert.Equal(t, []interface{}{1}, args)
+}
+
+func TestBuilder_offsetFetch(t *testing.T) {
+	var (
+		config = &Config{
+			Placeholder: ":",
+			Ordinal:     true,
+			OffsetFetch: true,
+		}
+	)
+
+	tests := []struct {
+		result string
+		query  rel.Query
+	}{
+		{
+			result: "SELECT * FROM users;",
+			query:  rel.From("users"),
+		},
+		{
+			result: "SELECT * FROM users FETCH NEXT 10 ROWS ONLY;",
+			query:  rel.From("users").Limit(10),
+		},
+		{
+			result: "SELECT * FROM users ORDER BY id ASC OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY;",
+			query:  rel.From("users").SortAsc("id").Offset(20).Limit(10),
+		},
+		{
+			result: "SELECT * FROM users ORDER BY id ASC OFFSET 20 ROWS;",
+			query:  rel.From("users").SortAsc("id").Offset(20),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.result, func(t *testing.T) {
+			qs, _ := NewBuilder(config).Find(test.query)
+			assert.Equal(t, test.result, qs)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package sql
+
+import (
+	"context"
+	"database/sql/driver"
+	"sync"
+	"time"
+)
+
+// RefreshMargin is the duration before expiration when credential will be refreshed.
+var RefreshMargin = time.Minute
+
+// CredentialFunc returns data source name and its expiration time.
+// It's intended to build data source name using short lived token such as cloud IAM token or dynamic secret.
+// Zero expiration time means the data source name never expires.
+type CredentialFunc func(ctx context.Context) (dsn string, expiry time.Time, err error)
+
+// Connector opens database connection using data source name returned by credential function.
+// Data source name is reused until it's about to expire, thus new connections always use valid credential.
+// Use it together with ConnMaxLifetime to make sure old connections are closed before the credential is revoked.
+type Connector struct {
+	driver     driver.Driver
+	credential CredentialFunc
+	mutex      sync.Mutex
+	dsn        string
+	expiry     time.Time
+}
+
+var _ driver.Connector = (*Connector)(nil)
+
+// NewConnector returns connector that uses credential function to open connection.
+func NewConnector(driver driver.Driver, credential CredentialFunc) *Connector {
+	return &Connector{
+		driver:     driver,
+		credential: credential,
+	}
+}
+
+// Connect returns a new connection to the database.
+func (c *Connector) Connect(ctx context.Context) (driver.Conn, error) {
+	dsn, err := c.DSN(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if dc, ok := c.driver.(driver.DriverContext); ok {
+		connector, err := dc.OpenConnector(dsn)
+		if err != nil {
+			return nil, err
+		}
+
+		return connector.Connect(ctx)
+	}
+
+	return c.driver.Open(dsn)
+}
+
+// Driver returns the underlying driver.
+func (c *Connector) Driver() driver.Driver {
+	return c.driver
+}
+
+// DSN returns cached data source name, or a new one from credential function when it's about to expire.
+func (c *Connector) DSN(ctx context.Context) (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.dsn != "" && (c.expiry.IsZero() || time.Now().Add(RefreshMargin).Before(c.expiry)) {
+		return c.dsn, nil
+	}
+
+	dsn, expiry, err := c.credential(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	c.dsn, c.expiry = dsn, expiry
+	return dsn, nil
+}
@@ -0,0 +1,77 @@
+package sql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testDriver struct {
+	dsn []string
+}
+
+func (td *testDriver) Open(dsn string) (driver.Conn, error) {
+	td.dsn = append(td.dsn, dsn)
+	return nil, nil
+}
+
+func TestConnector(t *testing.T) {
+	var (
+		ctx    = context.TODO()
+		calls  = 0
+		expiry = time.Now().Add(time.Hour)
+		drv    = &testDriver{}
+	)
+
+	connector := NewConnector(drv, func(ctx context.Context) (string, time.Time, error) {
+		calls++
+		return "token" + string(rune('0'+calls)), expiry, nil
+	})
+
+	assert.Equal(t, drv, connector.Driver())
+
+	_, err := connector.Connect(ctx)
+	assert.Nil(t, err)
+	_, err = connector.Connect(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"token1", "token1"}, drv.dsn)
+
+	// about to expire.
+	expiry = time.Now()
+	connector.expiry = time.Now().Add(RefreshMargin / 2)
+
+	_, err = connector.Connect(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"token1", "token1", "token2"}, drv.dsn)
+	assert.Equal(t, 2, calls)
+}
+
+func TestConnector_neverExpires(t *testing.T) {
+	var (
+		calls     = 0
+		connector = NewConnector(&testDriver{}, func(ctx context.Context) (string, time.Time, error) {
+			calls++
+			return "dsn", time.Time{}, nil
+		})
+	)
+
+	connector.DSN(context.TODO())
+	connector.DSN(context.TODO())
+	assert.Equal(t, 1, calls)
+}
+
+func TestConnector_error(t *testing.T) {
+	var (
+		err       = errors.New("token error")
+		connector = NewConnector(&testDriver{}, func(ctx context.Context) (string, time.Time, error) {
+			return "", time.Time{}, err
+		})
+	)
+
+	_, cerr := connector.Connect(context.TODO())
+	assert.Equal(t, err, cerr)
+}
@@ -7,6 +7,18 @@ import (
 // Cursor used for retrieving result.
 type Cursor struct {
 	*sql.Rows
+	release func()
+}
+
+// Close rows, and returns dedicated connection that is used by the query to the pool.
+func (c *Cursor) Close() error {
+	err := c.Rows.Close()
+	if c.release != nil {
+		c.release()
+		c.release = nil
+	}
+
+	return err
 }
 
 // Fields returned in the result.
@@ -0,0 +1,178 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/migrator"
+)
+
+// Dialect defines database specific syntax and behaviour.
+// Implementing a dialect is enough to support a new database using DialectAdapter.
+type Dialect interface {
+	// EscapeChar used to quote table and column name.
+	EscapeChar() string
+	// Placeholder prefix and whether it's followed by argument position, eg: "?" or "$" and true.
+	Placeholder() (string, bool)
+	// Returning keyword used to return inserted id, eg: RETURNING.
+	// Empty keyword means inserted id is retrieved using last insert id of the result.
+	Returning() string
+	// Error converts driver error into rel's error.
+	Error(err error) error
+}
+
+// IncrementDialect is optional interface implemented by dialect that uses last insert id,
+// it returns auto increment step used to calculate ids of multiple inserted records, or error when it can't be retrieved.
+// Negative value means last insert id refers to the last inserted record.
+type IncrementDialect interface {
+	Increment(ctx context.Context, adapter Adapter) (int, error)
+}
+
+// ColumnDialect is optional interface implemented by dialect that uses different column types than MapColumn.
+type ColumnDialect interface {
+	MapColumn(column *migrator.Column) string
+}
+
+// StructureDialect is optional interface implemented by dialect that is able to dump structure of the database.
+type StructureDialect interface {
+	DumpStructure(ctx context.Context, adapter Adapter) (string, error)
+}
+
+// InspectDialect is optional interface implemented by dialect that is able to list tables, columns and indexes of the database.
+type InspectDialect interface {
+	InspectTables(ctx context.Context, adapter Adapter) ([]migrator.TableInfo, error)
+}
+
+// LockDialect is optional interface implemented by dialect that supports advisory lock.
+type LockDialect interface {
+	Lock(ctx context.Context, adapter Adapter, name string) (func() error, error)
+}
+
+// DialectAdapter is generic sql adapter that uses dialect to build and execute query.
+type DialectAdapter struct {
+	*Adapter
+	Dialect Dialect
+}
+
+var _ rel.Adapter = (*DialectAdapter)(nil)
+
+// NewDialectAdapter using existing connection.
+func NewDialectAdapter(database *sql.DB, dialect Dialect) *DialectAdapter {
+	var (
+		placeholder, ordinal = dialect.Placeholder()
+		config               = &Config{
+			Placeholder:      placeholder,
+			Ordinal:          ordinal,
+			EscapeChar:       dialect.EscapeChar(),
+			ReturningKeyword: dialect.Returning(),
+			ErrorFunc:        dialect.Error,
+		}
+	)
+
+	if id, ok := dialect.(IncrementDialect); ok {
+		config.IncrementFunc = id.Increment
+	}
+
+	if cd, ok := dialect.(ColumnDialect); ok {
+		config.MapColumnFunc = cd.MapColumn
+	}
+
+	if sd, ok := dialect.(StructureDialect); ok {
+		config.DumpStructureFunc = sd.DumpStructure
+	}
+
+	if in, ok := dialect.(InspectDialect); ok {
+		config.InspectTablesFunc = in.InspectTables
+	}
+
+	if ld, ok := dialect.(LockDialect); ok {
+		config.LockFunc = ld.Lock
+	}
+
+	return &DialectAdapter{
+		Adapter: &Adapter{
+			Config: config,
+			DB:     database,
+		},
+		Dialect: dialect,
+	}
+}
+
+// Open connection using driver name and dsn.
+func Open(driverName string, dsn string, dialect Dialect) (*DialectAdapter, error) {
+	database, err := sql.Open(driverName, dsn)
+	return NewDialectAdapter(database, dialect), err
+}
+
+// Insert inserts a record to database and returns its id.
+func (adapter *DialectAdapter) Insert(ctx context.Context, query rel.Query, modifies map[string]rel.Modify, loggers ...rel.Logger) (interface{}, error) {
+	if adapter.Dialect.Returning() == "" {
+		return adapter.Adapter.Insert(ctx, query, modifies, loggers...)
+	}
+
+	var (
+		statement, args = NewBuilder(adapter.Config).Returning("id").Insert(query.Table, modifies)
+		ids, err        = adapter.queryIDs(ctx, statement, args, loggers)
+	)
+
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	return ids[0], nil
+}
+
+// InsertAll inserts multiple records to database and returns its ids.
+func (adapter *DialectAdapter) InsertAll(ctx context.Context, query rel.Query, fields []string, bulkModifies []map[string]rel.Modify, loggers ...rel.Logger) ([]interface{}, error) {
+	if adapter.Dialect.Returning() == "" {
+		return adapter.Adapter.InsertAll(ctx, query, fields, bulkModifies, loggers...)
+	}
+
+	statement, args := NewBuilder(adapter.Config).Returning("id").InsertAll(query.Table, fields, bulkModifies)
+	return adapter.queryIDs(ctx, statement, args, loggers)
+}
+
+// Begin begins a new transaction.
+func (adapter *DialectAdapter) Begin(ctx context.Context) (rel.Adapter, error) {
+	newAdapter, err := adapter.Adapter.Begin(ctx)
+
+	return &DialectAdapter{
+		Adapter: newAdapter.(*Adapter),
+		Dialect: adapter.Dialect,
+	}, err
+}
+
+func (adapter *DialectAdapter) queryIDs(ctx context.Context, statement string, args []interface{}, loggers []rel.Logger) ([]interface{}, error) {
+	var (
+		ids   []interface{}
+		rows  *sql.Rows
+		err   error
+		start = time.Now()
+	)
+
+	if adapter.Tx != nil {
+		rows, err = adapter.Tx.QueryContext(ctx, statement, args...)
+	} else {
+		rows, err = adapter.DB.QueryContext(ctx, statement, args...)
+	}
+
+	rel.LogFunc(ctx, loggers, func() string { return statement }, time.Since(start), err)
+
+	if err != nil {
+		return nil, adapter.Config.ErrorFunc(err)
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var id interface{}
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, adapter.Config.ErrorFunc(rows.Err())
+}
@@ -0,0 +1,121 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/migrator"
+	"github.com/Fs02/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+var errDialect = errors.New("dialect error")
+
+type testDialect struct {
+	returning string
+}
+
+func (td testDialect) EscapeChar() string {
+	return "`"
+}
+
+func (td testDialect) Placeholder() (string, bool) {
+	return "?", false
+}
+
+func (td testDialect) Returning() string {
+	return td.returning
+}
+
+func (td testDialect) Error(err error) error {
+	if err != nil && strings.Contains(err.Error(), "syntax error") {
+		return errDialect
+	}
+
+	return err
+}
+
+type incrementDialect struct {
+	testDialect
+}
+
+func (id incrementDialect) Increment(ctx context.Context, adapter Adapter) (int, error) {
+	return -1, nil
+}
+
+type columnDialect struct {
+	testDialect
+}
+
+func (cd columnDialect) MapColumn(column *migrator.Column) string {
+	return "SERIAL"
+}
+
+func openDialect(t *testing.T, dialect Dialect) *DialectAdapter {
+	adapter, err := Open("sqlite3", "file:dialect?mode=memory&cache=shared", dialect)
+	assert.Nil(t, err)
+
+	_, _, err = adapter.Exec(context.TODO(), `CREATE TABLE IF NOT EXISTS names (
+		id INTEGER PRIMARY KEY,
+		name STRING
+	);`, nil)
+	assert.Nil(t, err)
+
+	return adapter
+}
+
+func TestNewDialectAdapter(t *testing.T) {
+	var (
+		adapter = NewDialectAdapter(nil, testDialect{returning: "RETURNING"})
+	)
+
+	assert.Equal(t, "?", adapter.Config.Placeholder)
+	assert.False(t, adapter.Config.Ordinal)
+	assert.Equal(t, "`", adapter.Config.EscapeChar)
+	assert.Equal(t, "RETURNING", adapter.Config.ReturningKeyword)
+	assert.Nil(t, adapter.Config.IncrementFunc)
+	assert.NotNil(t, NewDialectAdapter(nil, incrementDialect{}).Config.IncrementFunc)
+	assert.Nil(t, adapter.Config.MapColumnFunc)
+	assert.NotNil(t, NewDialectAdapter(nil, columnDialect{}).Config.MapColumnFunc)
+}
+
+func TestDialectAdapter_lastInsertID(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = openDialect(t, incrementDialect{})
+		repo    = rel.New(adapter)
+		name    = Name{Name: "dialect"}
+		names   = []Name{{Name: "dialect1"}, {Name: "dialect2"}}
+	)
+
+	defer adapter.Close()
+
+	assert.Nil(t, repo.Insert(ctx, &name))
+	assert.NotEqual(t, 0, name.ID)
+
+	assert.Nil(t, repo.InsertAll(ctx, &names))
+	assert.NotEqual(t, 0, names[0].ID)
+
+	assert.Nil(t, repo.Transaction(ctx, func(repo rel.Repository) error {
+		assert.IsType(t, &DialectAdapter{}, repo.Adapter())
+		return repo.Find(ctx, &name, where.Eq("id", names[1].ID))
+	}))
+	assert.Equal(t, names[1], name)
+}
+
+func TestDialectAdapter_returning(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = openDialect(t, testDialect{returning: "RETURNING"})
+		repo    = rel.New(adapter)
+	)
+
+	defer adapter.Close()
+
+	// the bundled sqlite doesn't support returning clause.
+	assert.Equal(t, errDialect, repo.Insert(ctx, &Name{Name: "dialect"}))
+	assert.Equal(t, errDialect, repo.InsertAll(ctx, &[]Name{{Name: "dialect1"}}))
+}
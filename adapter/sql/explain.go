@@ -0,0 +1,88 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Explain returns execution plan of the statement, one line for each row of the plan with its columns separated by " | ".
+// Plan is retrieved by prefixing the statement with the keyword returned by ExplainFunc, EXPLAIN or EXPLAIN ANALYZE is used when it's not configured.
+// Analyze executes the statement, thus it should only be used to explain read statement.
+func (adapter *Adapter) Explain(ctx context.Context, statement string, args []interface{}, analyze bool) (string, error) {
+	var (
+		rows    *sql.Rows
+		err     error
+		keyword = "EXPLAIN"
+	)
+
+	if adapter.Config.ExplainFunc != nil {
+		keyword = adapter.Config.ExplainFunc(analyze)
+	} else if analyze {
+		keyword = "EXPLAIN ANALYZE"
+	}
+
+	if adapter.Tx != nil {
+		rows, err = adapter.Tx.QueryContext(ctx, keyword+" "+statement, args...)
+	} else {
+		rows, err = adapter.DB.QueryContext(ctx, keyword+" "+statement, args...)
+	}
+
+	if err != nil {
+		return "", adapter.Config.ErrorFunc(err)
+	}
+
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		plan   []string
+		values = make([]interface{}, len(columns))
+		dest   = make([]interface{}, len(columns))
+		fields = make([]string, len(columns))
+	)
+
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return "", err
+		}
+
+		for i, value := range values {
+			if b, ok := value.([]byte); ok {
+				value = string(b)
+			}
+
+			fields[i] = fmt.Sprint(value)
+		}
+
+		plan = append(plan, strings.Join(fields, " | "))
+	}
+
+	return strings.Join(plan, "\n"), rows.Err()
+}
+
+// explainSlow appends execution plan to read statement that took longer than ExplainThreshold, so it's logged together with the statement.
+// Statement inside transaction is not explained, because the transaction connection may still be busy reading the result,
+// and it's only called when the statement is passed to the loggers, see rel.LogFunc.
+func (adapter *Adapter) explainSlow(ctx context.Context, statement string, args []interface{}, duration time.Duration, err error) string {
+	if adapter.Config.ExplainThreshold <= 0 || duration < adapter.Config.ExplainThreshold || err != nil || adapter.Tx != nil {
+		return statement
+	}
+
+	plan, err := adapter.Explain(ctx, statement, args, adapter.Config.ExplainAnalyze)
+	if err != nil {
+		return statement + "\n-- explain failed: " + err.Error()
+	}
+
+	return statement + "\n" + plan
+}
@@ -0,0 +1,114 @@
+package sql
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Fs02/rel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdapter_Explain(t *testing.T) {
+	var (
+		adapter = open(t)
+	)
+
+	defer adapter.Close()
+
+	adapter.Config.ExplainFunc = func(analyze bool) string {
+		return "EXPLAIN QUERY PLAN"
+	}
+
+	plan, err := adapter.Explain(context.TODO(), "SELECT * FROM `names` WHERE `name`=?;", []interface{}{"rel"}, false)
+	assert.Nil(t, err)
+	assert.Contains(t, plan, "SCAN")
+	assert.Len(t, strings.Split(plan, "\n"), 1)
+	assert.Len(t, strings.Split(plan, " | "), 4)
+}
+
+func TestAdapter_Explain_error(t *testing.T) {
+	var (
+		adapter = open(t)
+	)
+
+	defer adapter.Close()
+
+	_, err := adapter.Explain(context.TODO(), "SELECT * FROM `names`;", nil, true)
+	assert.NotNil(t, err)
+}
+
+func TestAdapter_explainSlow(t *testing.T) {
+	var (
+		ctx        = context.TODO()
+		adapter    = open(t)
+		repo       = rel.New(adapter)
+		statements []string
+	)
+
+	defer adapter.Close()
+
+	repo.SetLogger(func(statement string, _ time.Duration, _ error) {
+		statements = append(statements, statement)
+	})
+
+	assert.Nil(t, repo.FindAll(ctx, &[]Name{}))
+	assert.Equal(t, "SELECT * FROM `names`;", statements[0])
+
+	adapter.Config.ExplainThreshold = time.Nanosecond
+	adapter.Config.ExplainFunc = func(analyze bool) string {
+		assert.True(t, analyze)
+		return "EXPLAIN QUERY PLAN"
+	}
+	adapter.Config.ExplainAnalyze = true
+
+	assert.Nil(t, repo.FindAll(ctx, &[]Name{}))
+	assert.Contains(t, statements[1], "SELECT * FROM `names`;\n")
+	assert.Contains(t, statements[1], "SCAN")
+
+	_, err := repo.Aggregate(ctx, rel.From("names"), "count", "id")
+	assert.Nil(t, err)
+	assert.Contains(t, statements[2], "SELECT count(`id`) AS count FROM `names`;\n")
+
+	tx, err := adapter.Begin(ctx)
+	assert.Nil(t, err)
+
+	_, err = tx.Aggregate(ctx, rel.From("names"), "count", "id", func(statement string, _ time.Duration, _ error) {
+		statements = append(statements, statement)
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, tx.Rollback(ctx))
+	assert.Equal(t, "SELECT count(`id`) AS count FROM `names`;", statements[3])
+
+	adapter.Config.ExplainFunc = nil
+	assert.Nil(t, repo.FindAll(ctx, &[]Name{}))
+	assert.Contains(t, statements[len(statements)-1], "SELECT * FROM `names`;\n-- explain failed: ")
+}
+
+func TestAdapter_explainSlow_quiet(t *testing.T) {
+	var (
+		ctx        = context.TODO()
+		adapter    = open(t)
+		repo       = rel.New(adapter)
+		explained  int
+		statements []string
+	)
+
+	defer adapter.Close()
+
+	repo.SetLogger(func(statement string, _ time.Duration, _ error) {
+		statements = append(statements, statement)
+	})
+	repo.SetLogLevels(rel.LogLevels{Minimum: rel.InfoLevel, Read: rel.DebugLevel, Write: rel.InfoLevel})
+
+	adapter.Config.ExplainThreshold = time.Nanosecond
+	adapter.Config.ExplainFunc = func(analyze bool) string {
+		explained++
+		return "EXPLAIN QUERY PLAN"
+	}
+
+	assert.Nil(t, repo.FindAll(ctx, &[]Name{}))
+	assert.Equal(t, 0, explained)
+	assert.Len(t, statements, 0)
+}
@@ -2,18 +2,48 @@ package sql
 
 import (
 	"strings"
+
+	"github.com/Fs02/rel"
 )
 
 // ExtractString between two string.
 func ExtractString(s, left, right string) string {
 	var (
 		start = strings.Index(s, left)
-		end   = strings.LastIndex(s, right)
 	)
 
-	if start < 0 || end < 0 || start+len(left) >= end {
+	if start < 0 {
+		return s
+	}
+
+	start += len(left)
+
+	var (
+		end = strings.Index(s[start:], right)
+	)
+
+	if end <= 0 {
 		return s
 	}
 
-	return s[start+len(left) : end]
+	return s[start : start+end]
+}
+
+// BulkLoadable returns true when records can be inserted using bulk load.
+// Bulk load is used when the number of records reach the threshold and every field of every record is set,
+// since bulk load can't fallback to default value.
+func BulkLoadable(threshold int, fields []string, bulkModifies []map[string]rel.Modify) bool {
+	if threshold <= 0 || len(bulkModifies) < threshold {
+		return false
+	}
+
+	for _, modifies := range bulkModifies {
+		for _, field := range fields {
+			if mod, ok := modifies[field]; !ok || mod.Type != rel.ChangeSetOp {
+				return false
+			}
+		}
+	}
+
+	return true
 }
@@ -3,6 +3,7 @@ package sql
 import (
 	"testing"
 
+	"github.com/Fs02/rel"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -15,3 +16,25 @@ func TestExtractString_notFound(t *testing.T) {
 	s := "Duplicate entry '1' for field 'slug'"
 	assert.Equal(t, "Duplicate entry '1' for field 'slug'", ExtractString(s, "key '", "'"))
 }
+
+func TestExtractString_firstMatch(t *testing.T) {
+	s := "a foreign key constraint fails (CONSTRAINT `extras_user_id_fk` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`))"
+	assert.Equal(t, "extras_user_id_fk", ExtractString(s, "CONSTRAINT `", "`"))
+}
+
+func TestBulkLoadable(t *testing.T) {
+	var (
+		fields       = []string{"name", "age"}
+		bulkModifies = []map[string]rel.Modify{
+			{"name": rel.Set("name", "a"), "age": rel.Set("age", 1)},
+			{"name": rel.Set("name", "b"), "age": rel.Set("age", 2)},
+		}
+	)
+
+	assert.True(t, BulkLoadable(2, fields, bulkModifies))
+	assert.False(t, BulkLoadable(3, fields, bulkModifies))
+	assert.False(t, BulkLoadable(0, fields, bulkModifies))
+
+	bulkModifies[1] = map[string]rel.Modify{"name": rel.Set("name", "b")}
+	assert.False(t, BulkLoadable(2, fields, bulkModifies))
+}
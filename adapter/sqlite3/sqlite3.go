@@ -13,11 +13,13 @@
 package sqlite3
 
 import (
+	"context"
 	db "database/sql"
 	"strings"
 
 	"github.com/Fs02/rel"
 	"github.com/Fs02/rel/adapter/sql"
+	"github.com/Fs02/rel/migrator"
 )
 
 // Adapter definition for mysql database.
@@ -27,11 +29,9 @@ type Adapter struct {
 
 var _ rel.Adapter = (*Adapter)(nil)
 
-// Open mysql connection using dsn.
-func Open(dsn string) (*Adapter, error) {
-	var err error
-
-	adapter := &Adapter{
+// New sqlite3 adapter using existing connection.
+func New(database *db.DB) *Adapter {
+	return &Adapter{
 		Adapter: &sql.Adapter{
 			Config: &sql.Config{
 				Placeholder:         "?",
@@ -39,17 +39,74 @@ func Open(dsn string) (*Adapter, error) {
 				InsertDefaultValues: true,
 				IncrementFunc:       incrementFunc,
 				ErrorFunc:           errorFunc,
+				MapColumnFunc:       mapColumnFunc,
+				DumpStructureFunc:   dumpStructureFunc,
+				InspectTablesFunc:   inspectTablesFunc,
+				ExplainFunc:         explainFunc,
+				Capabilities:        rel.TransactionalDDLCapability,
 			},
+			DB: database,
 		},
 	}
-	adapter.DB, err = db.Open("sqlite3", dsn)
+}
 
-	return adapter, err
+// Open sqlite3 connection using dsn.
+func Open(dsn string) (*Adapter, error) {
+	database, err := db.Open("sqlite3", dsn)
+	return New(database), err
 }
 
-func incrementFunc(adapter sql.Adapter) int {
+func incrementFunc(ctx context.Context, adapter sql.Adapter) (int, error) {
 	// decrement
-	return -1
+	return -1, nil
+}
+
+// mapColumnFunc maps column to sqlite3 type, auto increment primary key must be declared as INTEGER.
+func mapColumnFunc(column *migrator.Column) string {
+	switch column.Type {
+	case migrator.ID, migrator.BigID:
+		return "INTEGER PRIMARY KEY AUTOINCREMENT"
+	}
+
+	return sql.MapColumn(column)
+}
+
+// dumpStructureFunc returns statements stored in sqlite_master in order of its creation, internal tables are excluded.
+func dumpStructureFunc(ctx context.Context, adapter sql.Adapter) (string, error) {
+	rows, err := adapter.DB.QueryContext(ctx, "SELECT sql FROM sqlite_master WHERE sql IS NOT NULL AND name NOT LIKE 'sqlite\\_%' ESCAPE '\\' ORDER BY rowid;")
+	if err != nil {
+		return "", err
+	}
+
+	defer rows.Close()
+
+	var (
+		statements []string
+	)
+
+	for rows.Next() {
+		var statement string
+		if err := rows.Scan(&statement); err != nil {
+			return "", err
+		}
+
+		statements = append(statements, statement+";\n")
+	}
+
+	return strings.Join(statements, "\n"), rows.Err()
+}
+
+// inspectTablesFunc lists columns and indexes of every tables using pragma table-valued functions.
+func inspectTablesFunc(ctx context.Context, adapter sql.Adapter) ([]migrator.TableInfo, error) {
+	return sql.Inspect(ctx, adapter,
+		"SELECT m.name, p.name FROM sqlite_master m JOIN pragma_table_info(m.name) p WHERE m.type = 'table' AND m.name NOT LIKE 'sqlite\\_%' ESCAPE '\\' ORDER BY m.name, p.cid;",
+		"SELECT m.name, il.name, ii.name FROM sqlite_master m JOIN pragma_index_list(m.name) il JOIN pragma_index_info(il.name) ii WHERE m.type = 'table' ORDER BY m.name, il.name, ii.seqno;",
+	)
+}
+
+// explainFunc returns EXPLAIN QUERY PLAN, sqlite3 doesn't support analyze.
+func explainFunc(analyze bool) string {
+	return "EXPLAIN QUERY PLAN"
 }
 
 func errorFunc(err error) error {
@@ -8,6 +8,7 @@ import (
 	"github.com/Fs02/go-paranoid"
 	"github.com/Fs02/rel"
 	"github.com/Fs02/rel/adapter/specs"
+	"github.com/Fs02/rel/migrator"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/assert"
 )
@@ -84,6 +85,8 @@ func TestAdapter_specs(t *testing.T) {
 	specs.PreloadHasMany(t, repo)
 	specs.PreloadHasManyWithQuery(t, repo)
 	specs.PreloadHasManySlice(t, repo)
+	specs.PreloadHasManyWithLimit(t, repo)
+	specs.PreloadEager(t, repo)
 	specs.PreloadHasOne(t, repo)
 	specs.PreloadHasOneWithQuery(t, repo)
 	specs.PreloadHasOneSlice(t, repo)
@@ -108,6 +111,7 @@ func TestAdapter_specs(t *testing.T) {
 	specs.UpdateHasManyInsert(t, repo)
 	specs.UpdateHasManyUpdate(t, repo)
 	specs.UpdateHasManyReplace(t, repo)
+	specs.UpdateHasManyDiff(t, repo)
 	specs.UpdateHasOneInsert(t, repo)
 	specs.UpdateHasOneUpdate(t, repo)
 	specs.UpdateBelongsToInsert(t, repo)
@@ -176,3 +180,59 @@ func TestAdapter_Exec_error(t *testing.T) {
 	_, _, err = adapter.Exec(ctx, "error", nil)
 	assert.NotNil(t, err)
 }
+
+func TestMapColumnFunc(t *testing.T) {
+	assert.Equal(t, "INTEGER PRIMARY KEY AUTOINCREMENT", mapColumnFunc(&migrator.Column{Type: migrator.ID}))
+	assert.Equal(t, "INTEGER PRIMARY KEY AUTOINCREMENT", mapColumnFunc(&migrator.Column{Type: migrator.BigID}))
+	assert.Equal(t, "VARCHAR(255)", mapColumnFunc(&migrator.Column{Type: migrator.String}))
+}
+
+func TestDumpStructureFunc(t *testing.T) {
+	adapter, err := Open("file:dump?mode=memory&cache=shared")
+	assert.Nil(t, err)
+	defer adapter.Close()
+
+	_, _, err = adapter.Exec(ctx, "CREATE TABLE authors (id INTEGER PRIMARY KEY AUTOINCREMENT, name VARCHAR(255));", nil)
+	assert.Nil(t, err)
+	_, _, err = adapter.Exec(ctx, "CREATE INDEX authors_name ON authors (name);", nil)
+	assert.Nil(t, err)
+	_, _, err = adapter.Exec(ctx, "INSERT INTO authors (name) VALUES ('Kia');", nil)
+	assert.Nil(t, err)
+
+	structure, err := adapter.DumpStructure(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "CREATE TABLE authors (id INTEGER PRIMARY KEY AUTOINCREMENT, name VARCHAR(255));\n\nCREATE INDEX authors_name ON authors (name);\n", structure)
+
+	fresh, err := Open("file:load?mode=memory&cache=shared")
+	assert.Nil(t, err)
+	defer fresh.Close()
+
+	assert.Nil(t, fresh.LoadStructure(ctx, structure))
+
+	loaded, err := fresh.DumpStructure(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, structure, loaded)
+}
+
+func TestInspectTablesFunc(t *testing.T) {
+	adapter, err := Open("file:inspect?mode=memory&cache=shared")
+	assert.Nil(t, err)
+	defer adapter.Close()
+
+	_, _, err = adapter.Exec(ctx, "CREATE TABLE authors (id INTEGER PRIMARY KEY AUTOINCREMENT, name VARCHAR(255), email VARCHAR(255));", nil)
+	assert.Nil(t, err)
+	_, _, err = adapter.Exec(ctx, "CREATE INDEX authors_name_email ON authors (name, email);", nil)
+	assert.Nil(t, err)
+
+	tables, err := adapter.InspectTables(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []migrator.TableInfo{
+		{
+			Name:    "authors",
+			Columns: []string{"id", "name", "email"},
+			Indexes: []migrator.IndexInfo{
+				{Name: "authors_name_email", Columns: []string{"name", "email"}},
+			},
+		},
+	}, tables)
+}
@@ -0,0 +1,269 @@
+// Package tracing implements adapter middleware that records a span for every operation of REL.
+//
+// Each span is started as a child of the span in the context, and records the database system, operation, table,
+// executed statements and number of affected rows using attribute keys of OpenTelemetry semantic conventions.
+// Values of sensitive fields are redacted from the recorded statements and errors, see rel.Sensitive.
+// Correlated context values such as request id are recorded as attributes, see rel.Correlate.
+// The middleware doesn't depend on any tracing library, Tracer and Span are implemented by wrapping the tracer of choice.
+//
+// Usage:
+//	// tracer wraps OpenTelemetry tracer.
+//	type tracer struct {
+//		tracer trace.Tracer
+//	}
+//
+//	func (t tracer) Start(ctx context.Context, name string) (context.Context, tracing.Span) {
+//		ctx, s := t.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+//		return ctx, span{s}
+//	}
+//
+//	type span struct {
+//		trace.Span
+//	}
+//
+//	func (s span) SetAttributes(attributes ...tracing.Attribute) {
+//		for _, a := range attributes {
+//			s.Span.SetAttributes(attribute.String(a.Key, fmt.Sprint(a.Value)))
+//		}
+//	}
+//
+//	func (s span) RecordError(err error) {
+//		s.Span.RecordError(err)
+//		s.Span.SetStatus(codes.Error, err.Error())
+//	}
+//
+//	func (s span) End() {
+//		s.Span.End()
+//	}
+//
+//	// initialize REL's repo.
+//	middleware := tracing.Middleware(tracer{otel.Tracer("rel")}, "postgresql")
+//	repo := rel.New(rel.WrapAdapter(adapter, middleware))
+//
+//	// or trace operations of the repository without statements.
+//	repo.Instrumentation(tracing.Instrumenter(tracer{otel.Tracer("rel")}, "postgresql"))
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/Fs02/rel"
+)
+
+// Attribute keys recorded to the span.
+const (
+	SystemKey       = "db.system"
+	OperationKey    = "db.operation"
+	TableKey        = "db.sql.table"
+	StatementKey    = "db.statement"
+	RowsAffectedKey = "db.rows_affected"
+)
+
+// Attribute is a key value pair recorded to the span.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Span of an operation.
+type Span interface {
+	SetAttributes(attributes ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// Tracer starts a span as a child of the span in the context, and returns context that holds the new span.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Middleware returns adapter middleware that traces every operation using the tracer.
+// System is recorded as db.system attribute, eg: postgresql, mysql or sqlite.
+func Middleware(tracer Tracer, system string) rel.AdapterMiddleware {
+	return func(adapter rel.Adapter) rel.Adapter {
+		return &tracedAdapter{
+			Adapter: adapter,
+			tracer:  tracer,
+			system:  system,
+		}
+	}
+}
+
+// Instrumenter returns instrumenter that traces every operation of the repository using the tracer.
+// Unlike middleware, the span doesn't record executed statements and number of affected rows.
+func Instrumenter(tracer Tracer, system string) rel.Instrumenter {
+	ta := &tracedAdapter{
+		tracer: tracer,
+		system: system,
+	}
+
+	return func(ctx context.Context, op string, message string) func(err error) {
+		ctx, span, _ := ta.start(ctx, op, message, nil)
+		return func(err error) {
+			end(ctx, span, -1, err)
+		}
+	}
+}
+
+type tracedAdapter struct {
+	rel.Adapter
+	tracer Tracer
+	system string
+}
+
+// Unwrap returns the traced adapter.
+func (ta *tracedAdapter) Unwrap() rel.Adapter {
+	return ta.Adapter
+}
+
+// start a span for the operation, the returned loggers records executed statements to the span.
+func (ta *tracedAdapter) start(ctx context.Context, operation string, table string, loggers []rel.Logger) (context.Context, Span, []rel.Logger) {
+	var (
+		name       = operation
+		attributes = []Attribute{{Key: SystemKey, Value: ta.system}, {Key: OperationKey, Value: operation}}
+	)
+
+	if table != "" {
+		name += " " + table
+		attributes = append(attributes, Attribute{Key: TableKey, Value: table})
+	}
+
+	for _, field := range rel.Correlation(ctx) {
+		attributes = append(attributes, Attribute{Key: field.Name, Value: field.Value})
+	}
+
+	ctx, span := ta.tracer.Start(ctx, name)
+	span.SetAttributes(attributes...)
+
+	loggers = append(loggers[:len(loggers):len(loggers)], func(statement string, _ time.Duration, _ error) {
+		span.SetAttributes(Attribute{Key: StatementKey, Value: rel.Redact(ctx, statement)})
+	})
+
+	return ctx, span, loggers
+}
+
+// end the span, rows affected is recorded when it's not negative and the operation succeed.
+// Values of sensitive fields are redacted from the recorded error.
+func end(ctx context.Context, span Span, rows int, err error) {
+	if err != nil {
+		span.RecordError(rel.RedactError(ctx, err))
+	} else if rows >= 0 {
+		span.SetAttributes(Attribute{Key: RowsAffectedKey, Value: rows})
+	}
+
+	span.End()
+}
+
+func (ta *tracedAdapter) Aggregate(ctx context.Context, query rel.Query, mode string, field string, loggers ...rel.Logger) (int, error) {
+	ctx, span, loggers := ta.start(ctx, "aggregate", query.Table, loggers)
+
+	result, err := ta.Adapter.Aggregate(ctx, query, mode, field, loggers...)
+	end(ctx, span, -1, err)
+
+	return result, err
+}
+
+// Query returns cursor that ends the span when it's closed, so the span records the number of fetched rows.
+func (ta *tracedAdapter) Query(ctx context.Context, query rel.Query, loggers ...rel.Logger) (rel.Cursor, error) {
+	ctx, span, loggers := ta.start(ctx, "query", query.Table, loggers)
+
+	cur, err := ta.Adapter.Query(ctx, query, loggers...)
+	if err != nil {
+		end(ctx, span, -1, err)
+		return nil, err
+	}
+
+	return &tracedCursor{Cursor: cur, ctx: ctx, span: span}, nil
+}
+
+func (ta *tracedAdapter) Insert(ctx context.Context, query rel.Query, modifies map[string]rel.Modify, loggers ...rel.Logger) (interface{}, error) {
+	ctx, span, loggers := ta.start(ctx, "insert", query.Table, loggers)
+
+	id, err := ta.Adapter.Insert(ctx, query, modifies, loggers...)
+	end(ctx, span, 1, err)
+
+	return id, err
+}
+
+func (ta *tracedAdapter) InsertAll(ctx context.Context, query rel.Query, fields []string, bulkModifies []map[string]rel.Modify, loggers ...rel.Logger) ([]interface{}, error) {
+	ctx, span, loggers := ta.start(ctx, "insert_all", query.Table, loggers)
+
+	ids, err := ta.Adapter.InsertAll(ctx, query, fields, bulkModifies, loggers...)
+	end(ctx, span, len(ids), err)
+
+	return ids, err
+}
+
+func (ta *tracedAdapter) Update(ctx context.Context, query rel.Query, modifies map[string]rel.Modify, loggers ...rel.Logger) (int, error) {
+	ctx, span, loggers := ta.start(ctx, "update", query.Table, loggers)
+
+	updated, err := ta.Adapter.Update(ctx, query, modifies, loggers...)
+	end(ctx, span, updated, err)
+
+	return updated, err
+}
+
+func (ta *tracedAdapter) Delete(ctx context.Context, query rel.Query, loggers ...rel.Logger) (int, error) {
+	ctx, span, loggers := ta.start(ctx, "delete", query.Table, loggers)
+
+	deleted, err := ta.Adapter.Delete(ctx, query, loggers...)
+	end(ctx, span, deleted, err)
+
+	return deleted, err
+}
+
+// Begin returns transaction adapter as is, it's decorated again by rel.WrapAdapter.
+func (ta *tracedAdapter) Begin(ctx context.Context) (rel.Adapter, error) {
+	ctx, span, _ := ta.start(ctx, "begin", "", nil)
+
+	adapter, err := ta.Adapter.Begin(ctx)
+	end(ctx, span, -1, err)
+
+	return adapter, err
+}
+
+func (ta *tracedAdapter) Commit(ctx context.Context) error {
+	ctx, span, _ := ta.start(ctx, "commit", "", nil)
+
+	err := ta.Adapter.Commit(ctx)
+	end(ctx, span, -1, err)
+
+	return err
+}
+
+func (ta *tracedAdapter) Rollback(ctx context.Context) error {
+	ctx, span, _ := ta.start(ctx, "rollback", "", nil)
+
+	err := ta.Adapter.Rollback(ctx)
+	end(ctx, span, -1, err)
+
+	return err
+}
+
+type tracedCursor struct {
+	rel.Cursor
+	ctx    context.Context
+	span   Span
+	rows   int
+	closed bool
+}
+
+func (tc *tracedCursor) Next() bool {
+	if tc.Cursor.Next() {
+		tc.rows++
+		return true
+	}
+
+	return false
+}
+
+func (tc *tracedCursor) Close() error {
+	err := tc.Cursor.Close()
+	if !tc.closed {
+		tc.closed = true
+		end(tc.ctx, tc.span, tc.rows, err)
+	}
+
+	return err
+}
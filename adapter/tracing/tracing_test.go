@@ -0,0 +1,217 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/adapter/sqlite3"
+	"github.com/Fs02/rel/where"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+type book struct {
+	ID    int
+	Title string
+}
+
+type spanKey struct{}
+
+type recordedSpan struct {
+	name       string
+	parent     *recordedSpan
+	attributes map[string]interface{}
+	err        error
+	ended      int
+}
+
+func (rs *recordedSpan) SetAttributes(attributes ...Attribute) {
+	for _, a := range attributes {
+		rs.attributes[a.Key] = a.Value
+	}
+}
+
+func (rs *recordedSpan) RecordError(err error) {
+	rs.err = err
+}
+
+func (rs *recordedSpan) End() {
+	rs.ended++
+}
+
+type recordTracer struct {
+	spans []*recordedSpan
+}
+
+func (rt *recordTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	parent, _ := ctx.Value(spanKey{}).(*recordedSpan)
+	span := &recordedSpan{name: name, parent: parent, attributes: make(map[string]interface{})}
+	rt.spans = append(rt.spans, span)
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func (rt *recordTracer) last() *recordedSpan {
+	return rt.spans[len(rt.spans)-1]
+}
+
+func open(t *testing.T, tracer *recordTracer) (*sqlite3.Adapter, rel.Repository) {
+	adapter, err := sqlite3.Open("file:tracing?mode=memory&cache=shared")
+	assert.Nil(t, err)
+
+	_, _, err = adapter.Exec(context.TODO(), "CREATE TABLE IF NOT EXISTS books (id INTEGER PRIMARY KEY AUTOINCREMENT, title TEXT);", nil)
+	assert.Nil(t, err)
+
+	repo := rel.New(rel.WrapAdapter(adapter, Middleware(tracer, "sqlite")))
+	repo.SetLogger()
+
+	return adapter, repo
+}
+
+func TestMiddleware(t *testing.T) {
+	var (
+		tracer        = &recordTracer{}
+		adapter, repo = open(t, tracer)
+		ctx, root     = tracer.Start(context.TODO(), "request")
+		record        = book{Title: "REL for dummies"}
+		records       []book
+	)
+
+	defer adapter.Close()
+
+	assert.Nil(t, repo.Insert(ctx, &record))
+	assert.Equal(t, "insert books", tracer.last().name)
+	assert.Equal(t, root, tracer.last().parent)
+	assert.Equal(t, map[string]interface{}{
+		SystemKey:       "sqlite",
+		OperationKey:    "insert",
+		TableKey:        "books",
+		StatementKey:    "INSERT INTO `books` (`title`) VALUES (?);",
+		RowsAffectedKey: 1,
+	}, tracer.last().attributes)
+	assert.Equal(t, 1, tracer.last().ended)
+
+	assert.Nil(t, repo.FindAll(ctx, &records))
+	assert.Equal(t, "query books", tracer.last().name)
+	assert.Equal(t, "SELECT * FROM `books`;", tracer.last().attributes[StatementKey])
+	assert.Equal(t, len(records), tracer.last().attributes[RowsAffectedKey])
+	assert.Equal(t, 1, tracer.last().ended)
+
+	count, err := repo.Count(ctx, "books")
+	assert.Nil(t, err)
+	assert.Equal(t, "aggregate books", tracer.last().name)
+	assert.NotContains(t, tracer.last().attributes, RowsAffectedKey)
+
+	record.Title = "REL for experts"
+	assert.Nil(t, repo.Update(ctx, &record))
+	assert.Equal(t, "update books", tracer.last().name)
+	assert.Equal(t, 1, tracer.last().attributes[RowsAffectedKey])
+
+	assert.Nil(t, repo.Delete(ctx, &record))
+	assert.Equal(t, "delete books", tracer.last().name)
+	assert.Equal(t, 1, tracer.last().attributes[RowsAffectedKey])
+	assert.Equal(t, count-1, repo.MustCount(ctx, "books"))
+}
+
+func TestMiddleware_transaction(t *testing.T) {
+	var (
+		tracer        = &recordTracer{}
+		adapter, repo = open(t, tracer)
+		ctx, root     = tracer.Start(context.TODO(), "request")
+		records       = []book{{Title: "REL for dummies"}, {Title: "REL for experts"}}
+	)
+
+	defer adapter.Close()
+
+	assert.Nil(t, repo.Transaction(ctx, func(repo rel.Repository) error {
+		return repo.InsertAll(ctx, &records)
+	}))
+
+	assert.Len(t, tracer.spans, 4)
+	assert.Equal(t, "begin", tracer.spans[1].name)
+	assert.Equal(t, "insert_all books", tracer.spans[2].name)
+	assert.Equal(t, 2, tracer.spans[2].attributes[RowsAffectedKey])
+	assert.Equal(t, "commit", tracer.spans[3].name)
+
+	for _, span := range tracer.spans[1:] {
+		assert.Equal(t, root, span.parent)
+		assert.Equal(t, 1, span.ended)
+	}
+
+	err := errors.New("rollback")
+	assert.Equal(t, err, repo.Transaction(ctx, func(repo rel.Repository) error {
+		return err
+	}))
+
+	assert.Equal(t, "rollback", tracer.last().name)
+	assert.Nil(t, tracer.last().err)
+}
+
+func TestMiddleware_error(t *testing.T) {
+	var (
+		tracer        = &recordTracer{}
+		adapter, repo = open(t, tracer)
+		ctx           = context.TODO()
+		record        book
+	)
+
+	defer adapter.Close()
+
+	err := repo.Find(ctx, &record, rel.From("missing"), where.Eq("id", 1))
+	assert.NotNil(t, err)
+	assert.Equal(t, "query missing", tracer.last().name)
+	assert.Equal(t, err, tracer.last().err)
+	assert.NotContains(t, tracer.last().attributes, RowsAffectedKey)
+	assert.Equal(t, 1, tracer.last().ended)
+	assert.Nil(t, tracer.last().parent)
+
+	_, err = repo.Aggregate(ctx, rel.From("missing"), "sum", "id")
+	assert.NotNil(t, err)
+	assert.Equal(t, "aggregate missing", tracer.last().name)
+	assert.NotNil(t, tracer.last().err)
+}
+
+func TestInstrumenter(t *testing.T) {
+	var (
+		tracer     = &recordTracer{}
+		adapter, _ = open(t, tracer)
+		repo       = rel.New(adapter)
+		ctx, root  = tracer.Start(context.TODO(), "request")
+		record     = book{Title: "REL for dummies"}
+	)
+
+	defer adapter.Close()
+
+	repo.SetLogger()
+	repo.Instrumentation(Instrumenter(tracer, "sqlite"))
+
+	assert.Nil(t, repo.Insert(ctx, &record))
+	assert.Equal(t, "insert books", tracer.last().name)
+	assert.Equal(t, root, tracer.last().parent)
+	assert.Equal(t, map[string]interface{}{
+		SystemKey:    "sqlite",
+		OperationKey: "insert",
+		TableKey:     "books",
+	}, tracer.last().attributes)
+	assert.Equal(t, 1, tracer.last().ended)
+}
+
+type requestIDKey struct{}
+
+func TestMiddleware_correlation(t *testing.T) {
+	var (
+		tracer        = &recordTracer{}
+		adapter, repo = open(t, tracer)
+		ctx           = context.WithValue(context.TODO(), requestIDKey{}, "abc")
+	)
+
+	defer adapter.Close()
+
+	rel.Correlate("request_id", requestIDKey{})
+
+	_, err := repo.Count(ctx, "books")
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", tracer.last().attributes["request_id"])
+}
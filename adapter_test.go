@@ -2,17 +2,28 @@ package rel
 
 import (
 	"context"
+	"errors"
+	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
 type testAdapter struct {
 	mock.Mock
-	result interface{}
+	result             interface{}
+	unsupported        Capabilities
+	transactionOptions TransactionOptions
+	transactionCtx     context.Context
 }
 
 var _ Adapter = (*testAdapter)(nil)
 
+func (ta *testAdapter) Capabilities() Capabilities {
+	return (TransactionCapability | SavepointCapability |
+		JoinCapability | GroupCapability | TwoPhaseCommitCapability | WindowCapability | OnConflictCapability) &^ ta.unsupported
+}
+
 func (ta *testAdapter) Open(dsn string) error {
 	args := ta.Called(dsn)
 	return args.Error(0)
@@ -59,6 +70,8 @@ func (ta *testAdapter) Delete(ctx context.Context, query Query, logger ...Logger
 }
 
 func (ta *testAdapter) Begin(ctx context.Context) (Adapter, error) {
+	ta.transactionOptions = TransactionOptionsFrom(ctx)
+	ta.transactionCtx = ctx
 	args := ta.Called()
 	return ta, args.Error(0)
 }
@@ -73,7 +86,168 @@ func (ta *testAdapter) Rollback(ctx context.Context) error {
 	return args.Error(0)
 }
 
+func (ta *testAdapter) Prepare(ctx context.Context, id string) error {
+	args := ta.Called(id)
+	return args.Error(0)
+}
+
+func (ta *testAdapter) CommitPrepared(ctx context.Context, id string) error {
+	args := ta.Called(id)
+	return args.Error(0)
+}
+
+func (ta *testAdapter) RollbackPrepared(ctx context.Context, id string) error {
+	args := ta.Called(id)
+	return args.Error(0)
+}
+
 func (ta *testAdapter) Result(result interface{}) *testAdapter {
 	ta.result = result
 	return ta
 }
+
+func TestCapabilities(t *testing.T) {
+	var (
+		capabilities = TransactionCapability | JoinCapability
+	)
+
+	assert.True(t, capabilities.Is(TransactionCapability))
+	assert.True(t, capabilities.Is(TransactionCapability|JoinCapability))
+	assert.False(t, capabilities.Is(TransactionCapability|SavepointCapability))
+}
+
+func TestCapabilities_String(t *testing.T) {
+	assert.Equal(t, "transaction", TransactionCapability.String())
+	assert.Equal(t, "savepoint", SavepointCapability.String())
+	assert.Equal(t, "join", JoinCapability.String())
+	assert.Equal(t, "group", GroupCapability.String())
+	assert.Equal(t, "two-phase commit", TwoPhaseCommitCapability.String())
+	assert.Equal(t, "transactional ddl", TransactionalDDLCapability.String())
+	assert.Equal(t, "window function", WindowCapability.String())
+	assert.Equal(t, "on conflict", OnConflictCapability.String())
+	assert.Equal(t, "", (TransactionCapability | JoinCapability).String())
+}
+
+type recordAdapter struct {
+	Adapter
+	name    string
+	records *[]string
+}
+
+func (ra recordAdapter) Insert(ctx context.Context, query Query, modifies map[string]Modify, loggers ...Logger) (interface{}, error) {
+	*ra.records = append(*ra.records, ra.name)
+	return ra.Adapter.Insert(ctx, query, modifies, loggers...)
+}
+
+func (ra recordAdapter) Commit(ctx context.Context) error {
+	*ra.records = append(*ra.records, ra.name+" commit")
+	return ra.Adapter.Commit(ctx)
+}
+
+func (ra recordAdapter) Unwrap() Adapter {
+	return ra.Adapter
+}
+
+func recordMiddleware(name string, records *[]string) AdapterMiddleware {
+	return func(adapter Adapter) Adapter {
+		return recordAdapter{Adapter: adapter, name: name, records: records}
+	}
+}
+
+func TestWrapAdapter(t *testing.T) {
+	var (
+		records  []string
+		adapter  = &testAdapter{}
+		wrapped  = WrapAdapter(adapter, recordMiddleware("first", &records), recordMiddleware("second", &records))
+		query    = From("users")
+		modifies = map[string]Modify{"name": Set("name", "name")}
+	)
+
+	adapter.On("Insert", query, modifies).Return(1, nil).Times(2)
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	id, err := wrapped.Insert(context.TODO(), query, modifies)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, id)
+	assert.Equal(t, []string{"first", "second"}, records)
+
+	records = nil
+	assert.Nil(t, New(wrapped).Transaction(context.TODO(), func(repo Repository) error {
+		_, err := repo.Adapter().Insert(context.TODO(), query, modifies)
+		return err
+	}))
+	assert.Equal(t, []string{"first", "second", "first commit", "second commit"}, records)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestWrapAdapter_beginError(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		wrapped = WrapAdapter(adapter)
+		err     = errors.New("error")
+	)
+
+	adapter.On("Begin").Return(err).Once()
+
+	_, beginErr := wrapped.Begin(context.TODO())
+	assert.Equal(t, err, beginErr)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestWrapAdapter_twoPhaseCommit(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		wrapped = WrapAdapter(adapter).(TwoPhaseCommitAdapter)
+	)
+
+	adapter.On("Prepare", "tx-1").Return(nil).Once()
+	adapter.On("CommitPrepared", "tx-1").Return(nil).Once()
+	adapter.On("RollbackPrepared", "tx-1").Return(nil).Once()
+
+	assert.Nil(t, wrapped.Prepare(context.TODO(), "tx-1"))
+	assert.Nil(t, wrapped.CommitPrepared(context.TODO(), "tx-1"))
+	assert.Nil(t, wrapped.RollbackPrepared(context.TODO(), "tx-1"))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestWrapAdapter_twoPhaseCommitNotSupported(t *testing.T) {
+	var (
+		records []string
+		wrapped = WrapAdapter(&testAdapter{}, recordMiddleware("first", &records)).(TwoPhaseCommitAdapter)
+		err     = NotSupportedError{Capability: TwoPhaseCommitCapability}
+	)
+
+	assert.Equal(t, err, wrapped.Prepare(context.TODO(), "tx-1"))
+	assert.Equal(t, err, wrapped.CommitPrepared(context.TODO(), "tx-1"))
+	assert.Equal(t, err, wrapped.RollbackPrepared(context.TODO(), "tx-1"))
+}
+
+func TestAsAdapter(t *testing.T) {
+	var (
+		records []string
+		adapter = &testAdapter{}
+		wrapped = WrapAdapter(adapter, recordMiddleware("first", &records))
+		target  *testAdapter
+		tpc     TwoPhaseCommitAdapter
+		locker  interface {
+			Lock(ctx context.Context, name string) (func() error, error)
+		}
+	)
+
+	assert.True(t, AsAdapter(wrapped, &target))
+	assert.Equal(t, adapter, target)
+
+	assert.True(t, AsAdapter(wrapped, &tpc))
+	assert.Equal(t, wrapped, tpc)
+
+	assert.False(t, AsAdapter(wrapped, &locker))
+	assert.Nil(t, locker)
+
+	assert.Panics(t, func() {
+		AsAdapter(wrapped, nil)
+	})
+}
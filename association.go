@@ -2,6 +2,7 @@ package rel
 
 import (
 	"reflect"
+	"strings"
 	"sync"
 
 	"github.com/azer/snakecase"
@@ -17,8 +18,51 @@ const (
 	HasOne
 	// HasMany association.
 	HasMany
+	// ManyToMany association.
+	ManyToMany
 )
 
+// Replacement defines how existing records of has many association are replaced when the association is updated.
+// It can be declared using replace tag, eg: `replace:"nullify"`, or for each update using Replace modifier.
+type Replacement uint8
+
+const (
+	// ReplaceDiff deletes only existing records that are no longer in the association, remaining records are updated, this is the default.
+	ReplaceDiff Replacement = iota
+	// ReplaceDelete deletes all existing records and reinserts every record in the association.
+	ReplaceDelete
+	// ReplaceNullify sets foreign key of existing records that are no longer in the association to null.
+	ReplaceNullify
+	// ReplaceRestrict returns error when there's existing record that is no longer in the association.
+	ReplaceRestrict
+)
+
+// String representation of the replacement.
+func (r Replacement) String() string {
+	switch r {
+	case ReplaceDiff:
+		return "diff"
+	case ReplaceDelete:
+		return "delete"
+	case ReplaceNullify:
+		return "nullify"
+	case ReplaceRestrict:
+		return "restrict"
+	default:
+		return ""
+	}
+}
+
+func parseReplacement(tag string) Replacement {
+	for r := ReplaceDiff; r <= ReplaceRestrict; r++ {
+		if tag == r.String() {
+			return r
+		}
+	}
+
+	panic("rel: invalid replace strategy (" + tag + ")")
+}
+
 type associationKey struct {
 	rt    reflect.Type
 	index int
@@ -31,6 +75,14 @@ type associationData struct {
 	referenceIndex  int
 	foreignField    string
 	foreignIndex    int
+	through         string
+	throughRef      string
+	throughFk       string
+	throughFlag     DocumentFlag
+	throughAssoc    bool
+	replacement     Replacement
+	touch           bool
+	verify          bool
 }
 
 var associationCache sync.Map
@@ -109,6 +161,21 @@ func (a Association) IsZero() bool {
 	return isDeepZero(rv, 1)
 }
 
+// Replacement strategy of has many association declared using replace tag.
+func (a Association) Replacement() Replacement {
+	return a.data.replacement
+}
+
+// Touch returns true if updated at of belongs to association is updated whenever the record is inserted or updated.
+func (a Association) Touch() bool {
+	return a.data.touch
+}
+
+// Verify returns true if existence of the referenced record is verified before the record is saved, only belongs to association can be verified.
+func (a Association) Verify() bool {
+	return a.data.verify
+}
+
 // ReferenceField of the association.
 func (a Association) ReferenceField() string {
 	return a.data.referenceColumn
@@ -124,10 +191,25 @@ func (a Association) ForeignField() string {
 	return a.data.foreignField
 }
 
+// Through returns join table of many to many association.
+func (a Association) Through() string {
+	return a.data.through
+}
+
+// ThroughReferenceField returns column of the join table that references the reference field of many to many association.
+func (a Association) ThroughReferenceField() string {
+	return a.data.throughRef
+}
+
+// ThroughForeignField returns column of the join table that references the foreign field of many to many association.
+func (a Association) ThroughForeignField() string {
+	return a.data.throughFk
+}
+
 // ForeignValue of the association.
-// It'll panic if association type is has many.
+// It'll panic if association type is has many or many to many.
 func (a Association) ForeignValue() interface{} {
-	if a.Type() == HasMany {
+	if a.Type() == HasMany || a.Type() == ManyToMany {
 		panic("cannot infer foreign value for has many association")
 	}
 
@@ -146,6 +228,108 @@ func (a Association) ForeignValue() interface{} {
 	return indirect(rv.Field(a.data.foreignIndex))
 }
 
+// targetType returns struct type of the association.
+func (a Association) targetType() reflect.Type {
+	return elemType(a.rv.Type().FieldByIndex(a.data.targetIndex).Type)
+}
+
+// foreignType returns type of the foreign field, pointer is dereferenced since it's used as key of the association.
+func (a Association) foreignType() reflect.Type {
+	rt := a.targetType().Field(a.data.foreignIndex).Type
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+
+	return rt
+}
+
+// extractThroughAssociationData of has many through association, eg: `through:"posts"` or `through:"posts.comments"`.
+// The first field is an association of the struct, and the second field is an association of the first association's struct, it defaults to the field name.
+// The association is loaded as many to many association, table of the first association is used as the join table.
+// Second return value is false if through doesn't refer to an association, which means it's a join table.
+func extractThroughAssociationData(rt reflect.Type, ft reflect.Type, name string, through string) (associationData, bool) {
+	var (
+		path   = strings.SplitN(through, ".", 2)
+		source = name
+	)
+
+	if len(path) == 2 {
+		source = path[1]
+	}
+
+	index, exist := extractDocumentData(rt, true).index[path[0]]
+	if !exist || !isAssociationType(rt.Field(index).Type) {
+		return associationData{}, false
+	}
+
+	if path[0] == name {
+		panic("rel: association (" + name + ") can't be through itself")
+	}
+
+	var (
+		intermediate = extractAssociationData(rt, index)
+		it           = elemType(rt.Field(index).Type)
+	)
+
+	sourceIndex, exist := extractDocumentData(it, true).index[source]
+	if !exist || !isAssociationType(it.Field(sourceIndex).Type) {
+		panic("rel: through association source (" + source + ") field not found")
+	}
+
+	if elemType(it.Field(sourceIndex).Type) != ft {
+		panic("rel: through association source (" + source + ") type doesn't match (" + name + ")")
+	}
+
+	var (
+		sourceData = extractAssociationData(it, sourceIndex)
+	)
+
+	if intermediate.through != "" || sourceData.through != "" {
+		panic("rel: nested through association (" + name + ") is not supported")
+	}
+
+	return associationData{
+		typ:             ManyToMany,
+		referenceColumn: intermediate.referenceColumn,
+		referenceIndex:  intermediate.referenceIndex,
+		foreignField:    sourceData.foreignField,
+		foreignIndex:    sourceData.foreignIndex,
+		through:         NewDocument(reflect.New(it).Interface()).Table(),
+		throughRef:      intermediate.foreignField,
+		throughFk:       sourceData.referenceColumn,
+		throughFlag:     extractDocumentData(it, true).flag,
+		throughAssoc:    true,
+	}, true
+}
+
+// isAssociationType returns true if the type is a struct with primary key or pointer and slice of it.
+func isAssociationType(rt reflect.Type) bool {
+	rt = elemType(rt)
+	if rt.Kind() != reflect.Struct {
+		return false
+	}
+
+	pk, _ := searchPrimary(rt)
+	return pk != ""
+}
+
+func elemType(rt reflect.Type) reflect.Type {
+	for rt.Kind() == reflect.Ptr || rt.Kind() == reflect.Slice || rt.Kind() == reflect.Array {
+		rt = rt.Elem()
+	}
+
+	return rt
+}
+
+// primaryName returns primary field of the struct, it defaults to id.
+func primaryName(rt reflect.Type) string {
+	if field, _ := searchPrimary(rt); field != "" {
+		return field
+	}
+
+	return "id"
+}
+
 func newAssociation(rv reflect.Value, index int) Association {
 	if rv.Kind() == reflect.Ptr {
 		rv = rv.Elem()
@@ -174,6 +358,10 @@ func extractAssociationData(rt reflect.Type, index int) associationData {
 		ft        = sf.Type
 		ref       = sf.Tag.Get("ref")
 		fk        = sf.Tag.Get("fk")
+		through   = sf.Tag.Get("through")
+		replace   = sf.Tag.Get("replace")
+		touch     = sf.Tag.Get("touch")
+		verify    = sf.Tag.Get("verify")
 		fName     = fieldName(sf)
 		assocData = associationData{
 			targetIndex: sf.Index,
@@ -189,14 +377,64 @@ func extractAssociationData(rt reflect.Type, index int) associationData {
 		fkDocData  = extractDocumentData(ft, true)
 	)
 
+	// many to many association references primary key on both side by default,
+	// and the join table columns are guessed from the name of both types.
+	if through != "" {
+		if data, ok := extractThroughAssociationData(rt, ft, fName, through); ok {
+			data.targetIndex = sf.Index
+			associationCache.Store(key, data)
+			return data
+		}
+
+		var (
+			columns = strings.Split(through, ",")
+		)
+
+		if ref == "" {
+			ref = "id"
+		}
+
+		if fk == "" {
+			fk = "id"
+		}
+
+		assocData.through = columns[0]
+		if len(columns) == 3 {
+			assocData.throughRef = columns[1]
+			assocData.throughFk = columns[2]
+		} else {
+			assocData.throughRef = snakecase.SnakeCase(rt.Name()) + "_id"
+			assocData.throughFk = snakecase.SnakeCase(ft.Name()) + "_id"
+		}
+	}
+
+	var (
+		refPrimary = primaryName(rt)
+		fkPrimary  = primaryName(ft)
+	)
+
 	// Try to guess ref and fk if not defined.
-	if ref == "" || fk == "" {
+	// when only one of them is defined, the other is guessed from it, this is required by self referencing association,
+	// since both side of the association have the same fields.
+	if ref == "" && fk == "" {
 		if _, isBelongsTo := refDocData.index[fName+"_id"]; isBelongsTo {
 			ref = fName + "_id"
-			fk = "id"
+			fk = fkPrimary
 		} else {
-			ref = "id"
+			ref = refPrimary
+			fk = snakecase.SnakeCase(rt.Name()) + "_id"
+		}
+	} else if ref == "" {
+		if _, isBelongsTo := refDocData.index[fName+"_id"]; isBelongsTo && fk == fkPrimary {
+			ref = fName + "_id"
+		} else {
+			ref = refPrimary
+		}
+	} else if fk == "" {
+		if ref == refPrimary {
 			fk = snakecase.SnakeCase(rt.Name()) + "_id"
+		} else {
+			fk = fkPrimary
 		}
 	}
 
@@ -214,17 +452,41 @@ func extractAssociationData(rt reflect.Type, index int) associationData {
 		assocData.foreignField = fk
 	}
 
+	if replace != "" {
+		assocData.replacement = parseReplacement(replace)
+	}
+
 	// guess assoc type
-	if sf.Type.Kind() == reflect.Slice || sf.Type.Kind() == reflect.Array {
+	if assocData.through != "" {
+		assocData.typ = ManyToMany
+	} else if sf.Type.Kind() == reflect.Slice || sf.Type.Kind() == reflect.Array {
 		assocData.typ = HasMany
 	} else {
-		if len(assocData.referenceColumn) > len(assocData.foreignField) {
+		var (
+			refIsPrimary = assocData.referenceColumn == refPrimary
+			fkIsPrimary  = assocData.foreignField == fkPrimary
+		)
+
+		// belongs to association references primary key of the other struct, while has one is referenced by it.
+		// fallback to compare the length of the field name when it's not conclusive.
+		if fkIsPrimary != refIsPrimary {
+			if fkIsPrimary {
+				assocData.typ = BelongsTo
+			} else {
+				assocData.typ = HasOne
+			}
+		} else if len(assocData.referenceColumn) > len(assocData.foreignField) {
 			assocData.typ = BelongsTo
 		} else {
 			assocData.typ = HasOne
 		}
 	}
 
+	if assocData.typ == BelongsTo {
+		assocData.touch = touch == "true"
+		assocData.verify = verify == "true"
+	}
+
 	associationCache.Store(key, assocData)
 
 	return assocData
@@ -0,0 +1,325 @@
+package rel
+
+import (
+	"context"
+	"reflect"
+)
+
+// AssociationProxy manages records of has many or many to many association of a record, without saving the whole association.
+// Has many association is managed by updating foreign key of the records, and many to many association is managed by inserting and deleting rows of the join table.
+// Records that are removed from has many association are deleted by default, or nullified and restricted according to replace tag of the association, see Replacement.
+// New records are inserted, every modification is performed in a transaction and loaded association of the record is not modified.
+//
+// Example:
+//	transactions := repo.Association(&user, "transactions")
+//	transactions.Add(ctx, &trx1, &trx2)
+//	transactions.Remove(ctx, &trx1)
+//	count, err := transactions.Count(ctx)
+type AssociationProxy interface {
+	Add(ctx context.Context, records ...interface{}) error
+	Remove(ctx context.Context, records ...interface{}) error
+	Replace(ctx context.Context, records ...interface{}) error
+	Clear(ctx context.Context) error
+	Count(ctx context.Context) (int, error)
+}
+
+type associationProxy struct {
+	repo   repository
+	assoc  Association
+	target *Document
+}
+
+func newAssociationProxy(repo repository, record interface{}, field string) associationProxy {
+	var (
+		doc   = NewDocument(record)
+		assoc = doc.Association(field)
+	)
+
+	if assoc.Type() != HasMany && assoc.Type() != ManyToMany {
+		panic("rel: association proxy only supports has many and many to many association")
+	}
+
+	if assoc.data.throughAssoc {
+		panic("rel: association proxy doesn't support has many through association (" + field + ")")
+	}
+
+	if isZero(assoc.ReferenceValue()) {
+		panic("rel: association proxy requires reference value of association (" + field + ")")
+	}
+
+	return associationProxy{
+		repo:   repo,
+		assoc:  assoc,
+		target: NewDocument(reflect.New(assoc.targetType()).Interface()),
+	}
+}
+
+// Add records to the association.
+func (ap associationProxy) Add(ctx context.Context, records ...interface{}) error {
+	docs := ap.documents(records)
+	if len(docs) == 0 {
+		return nil
+	}
+
+	return ap.transaction(ctx, func(ap associationProxy) error {
+		return ap.add(ctx, docs)
+	})
+}
+
+// Remove records from the association.
+func (ap associationProxy) Remove(ctx context.Context, records ...interface{}) error {
+	docs := ap.documents(records)
+	if len(docs) == 0 {
+		return nil
+	}
+
+	return ap.transaction(ctx, func(ap associationProxy) error {
+		if ap.assoc.Type() == ManyToMany {
+			values := ap.foreignValues(docs)
+			if len(values) == 0 {
+				return nil
+			}
+
+			return ap.removeThrough(ctx, In(ap.assoc.ThroughForeignField(), values...))
+		}
+
+		var (
+			ids []interface{}
+		)
+
+		for _, doc := range docs {
+			if pValue := doc.PrimaryValue(); !isZero(pValue) {
+				ids = append(ids, pValue)
+			}
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+
+		if err := ap.remove(ctx, In(ap.target.PrimaryField(), ids...)); err != nil {
+			return err
+		}
+
+		if ap.assoc.Replacement() == ReplaceNullify {
+			for _, doc := range docs {
+				doc.SetValue(ap.assoc.ForeignField(), nil)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Replace records of the association, existing records that are not in the records are removed.
+func (ap associationProxy) Replace(ctx context.Context, records ...interface{}) error {
+	docs := ap.documents(records)
+
+	return ap.transaction(ctx, func(ap associationProxy) error {
+		if ap.assoc.Type() == ManyToMany {
+			return ap.addThrough(ctx, docs, true)
+		}
+
+		if err := ap.add(ctx, docs); err != nil {
+			return err
+		}
+
+		var (
+			ids []interface{}
+		)
+
+		for _, doc := range docs {
+			ids = append(ids, doc.PrimaryValue())
+		}
+
+		if len(ids) == 0 {
+			return ap.remove(ctx)
+		}
+
+		return ap.remove(ctx, Nin(ap.target.PrimaryField(), ids...))
+	})
+}
+
+// Clear removes all records from the association.
+func (ap associationProxy) Clear(ctx context.Context) error {
+	return ap.transaction(ctx, func(ap associationProxy) error {
+		if ap.assoc.Type() == ManyToMany {
+			return ap.removeThrough(ctx)
+		}
+
+		return ap.remove(ctx)
+	})
+}
+
+// Count records of the association.
+func (ap associationProxy) Count(ctx context.Context) (int, error) {
+	var (
+		rValue = ap.assoc.ReferenceValue()
+	)
+
+	if ap.assoc.Type() == ManyToMany {
+		return ap.repo.Aggregate(ctx, Build(ap.assoc.Through(), Eq(ap.assoc.ThroughReferenceField(), rValue)), "count", "*")
+	}
+
+	query := Build(ap.target.Table(), Eq(ap.assoc.ForeignField(), rValue))
+	return ap.repo.Aggregate(ctx, ap.repo.withDefaultScope(ap.target.data, query), "count", "*")
+}
+
+func (ap associationProxy) transaction(ctx context.Context, fn func(ap associationProxy) error) error {
+	if ap.repo.readOnly {
+		return ErrReadOnlyTransaction
+	}
+
+	return ap.repo.Transaction(ctx, func(r Repository) error {
+		ap.repo = *r.(*repository)
+		return fn(ap)
+	})
+}
+
+func (ap associationProxy) documents(records []interface{}) []*Document {
+	docs := make([]*Document, len(records))
+	for i := range records {
+		docs[i] = NewDocument(records[i])
+		if docs[i].rt != ap.target.rt {
+			panic("rel: record type (" + docs[i].rt.String() + ") doesn't match association type (" + ap.target.rt.String() + ")")
+		}
+	}
+
+	return docs
+}
+
+func (ap associationProxy) add(ctx context.Context, docs []*Document) error {
+	if ap.assoc.Type() == ManyToMany {
+		return ap.addThrough(ctx, docs, false)
+	}
+
+	var (
+		pField = ap.target.PrimaryField()
+		fField = ap.assoc.ForeignField()
+		rValue = ap.assoc.ReferenceValue()
+		ids    []interface{}
+	)
+
+	for _, doc := range docs {
+		doc.SetValue(fField, rValue)
+
+		if pValue := doc.PrimaryValue(); !isZero(pValue) {
+			ids = append(ids, pValue)
+		}
+	}
+
+	if err := ap.insertNew(ctx, docs); err != nil {
+		return err
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return ap.repo.updateAll(ctx, Build(ap.target.Table(), In(pField, ids...)), map[string]Modify{fField: Set(fField, rValue)})
+}
+
+// insertNew inserts records that doesn't have primary value.
+func (ap associationProxy) insertNew(ctx context.Context, docs []*Document) error {
+	for _, doc := range docs {
+		if !isZero(doc.PrimaryValue()) {
+			continue
+		}
+
+		if err := ap.repo.insert(ctx, doc, Apply(doc, newStructset(doc, false))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// remove records of has many association that matches the filters using replacement strategy of the association.
+func (ap associationProxy) remove(ctx context.Context, filters ...FilterQuery) error {
+	var (
+		fField = ap.assoc.ForeignField()
+		filter = Eq(fField, ap.assoc.ReferenceValue()).And(filters...)
+	)
+
+	return ap.repo.removeHasMany(ctx, ap.target.data, ap.target.Table(), fField, filter, ap.assoc.Replacement())
+}
+
+func (ap associationProxy) foreignValues(docs []*Document) []interface{} {
+	var (
+		fField = ap.assoc.ForeignField()
+		values = make([]interface{}, 0, len(docs))
+	)
+
+	for _, doc := range docs {
+		if value, _ := doc.Value(fField); !isZero(value) {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+// addThrough inserts join table rows of many to many association, existing rows are replaced.
+// if replace is true, every existing rows of the association are removed.
+func (ap associationProxy) addThrough(ctx context.Context, docs []*Document, replace bool) error {
+	if err := ap.insertNew(ctx, docs); err != nil {
+		return err
+	}
+
+	var (
+		table    = ap.assoc.Through()
+		refField = ap.assoc.ThroughReferenceField()
+		fkField  = ap.assoc.ThroughForeignField()
+		rValue   = ap.assoc.ReferenceValue()
+		values   = ap.foreignValues(docs)
+		filters  []FilterQuery
+	)
+
+	if !replace {
+		if len(values) == 0 {
+			return nil
+		}
+
+		filters = append(filters, In(fkField, values...))
+	}
+
+	if err := ap.removeThrough(ctx, filters...); err != nil {
+		return err
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	var (
+		query        = Build(table)
+		fields       = []string{refField, fkField}
+		bulkModifies = make([]map[string]Modify, len(values))
+	)
+
+	for i := range values {
+		bulkModifies[i] = map[string]Modify{
+			refField: Set(refField, rValue),
+			fkField:  Set(fkField, values[i]),
+		}
+	}
+
+	ctx = ap.repo.instrument(ctx)
+	ctx, loggers := ap.repo.loggers(ctx, ap.repo.logLevels.Write, query, bulkModifies...)
+
+	finish := ap.repo.observe(ctx, "insert_all", query)
+	_, err := ap.repo.adapter.InsertAll(ctx, query, fields, bulkModifies, loggers...)
+	finish(err)
+
+	return err
+}
+
+// removeThrough deletes join table rows of many to many association that matches the filters.
+func (ap associationProxy) removeThrough(ctx context.Context, filters ...FilterQuery) error {
+	var (
+		query = Build(ap.assoc.Through(), Eq(ap.assoc.ThroughReferenceField(), ap.assoc.ReferenceValue()).And(filters...))
+	)
+
+	_, err := ap.repo.deleteAll(ctx, Invalid, query)
+	return err
+}
@@ -0,0 +1,311 @@
+package rel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type Playlist struct {
+	ID    int
+	Songs []Song `replace:"nullify"`
+}
+
+type Song struct {
+	ID         int
+	Title      string
+	PlaylistID *int
+}
+
+func TestRepository_Association_hasManyAdd(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		user    = User{ID: 1}
+		trx1    = Transaction{ID: 2, Item: "item1"}
+		trx2    = Transaction{Item: "item2"}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Insert", From("transactions"), mock.Anything).Return(3, nil).Once()
+	adapter.On("Update", From("transactions").Where(In("id", 2)), map[string]Modify{"user_id": Set("user_id", 1)}).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Association(&user, "transactions").Add(context.TODO(), &trx1, &trx2))
+	assert.Equal(t, Transaction{ID: 2, Item: "item1", BuyerID: 1}, trx1)
+	assert.Equal(t, Transaction{ID: 3, Item: "item2", BuyerID: 1}, trx2)
+	assert.Nil(t, user.Transactions)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Association_hasManyAddError(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		user    = User{ID: 1}
+		trx     = Transaction{ID: 2, Item: "item"}
+		err     = errors.New("error")
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Update", From("transactions").Where(In("id", 2)), mock.Anything).Return(0, err).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, err, repo.Association(&user, "transactions").Add(context.TODO(), &trx))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Association_hasManyRemove(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		user    = User{ID: 1}
+		trx1    = Transaction{ID: 2, BuyerID: 1}
+		trx2    = Transaction{BuyerID: 1}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Delete", From("transactions").Where(Eq("user_id", 1).AndIn("id", 2))).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Association(&user, "transactions").Remove(context.TODO(), &trx1, &trx2))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Association_hasManyRemoveNullify(t *testing.T) {
+	var (
+		adapter    = &testAdapter{}
+		repo       = repository{adapter: adapter}
+		playlistID = 1
+		playlist   = Playlist{ID: 1}
+		song       = Song{ID: 2, PlaylistID: &playlistID}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Update", From("songs").Where(Eq("playlist_id", 1).AndIn("id", 2)), map[string]Modify{"playlist_id": Set("playlist_id", nil)}).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Association(&playlist, "songs").Remove(context.TODO(), &song))
+	assert.Nil(t, song.PlaylistID)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Association_hasManyReplace(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		user    = User{ID: 1}
+		trx     = Transaction{ID: 2}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Update", From("transactions").Where(In("id", 2)), map[string]Modify{"user_id": Set("user_id", 1)}).Return(1, nil).Once()
+	adapter.On("Delete", From("transactions").Where(Eq("user_id", 1).AndNin("id", 2))).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Association(&user, "transactions").Replace(context.TODO(), &trx))
+	assert.Equal(t, 1, trx.BuyerID)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Association_hasManyReplaceEmpty(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		user    = User{ID: 1}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Delete", From("transactions").Where(Eq("user_id", 1))).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Association(&user, "transactions").Replace(context.TODO()))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Association_hasManyClear(t *testing.T) {
+	var (
+		adapter  = &testAdapter{}
+		repo     = repository{adapter: adapter}
+		playlist = Playlist{ID: 1}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Update", From("songs").Where(Eq("playlist_id", 1)), map[string]Modify{"playlist_id": Set("playlist_id", nil)}).Return(2, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Association(&playlist, "songs").Clear(context.TODO()))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Association_hasManyCount(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		user    = User{ID: 1}
+	)
+
+	adapter.On("Aggregate", From("transactions").Where(Eq("user_id", 1)), "count", "*").Return(3, nil).Once()
+
+	count, err := repo.Association(&user, "transactions").Count(context.TODO())
+	assert.Nil(t, err)
+	assert.Equal(t, 3, count)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Association_manyToManyAdd(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		student = Student{ID: 1}
+		course1 = Course{ID: 2, Title: "course1"}
+		course2 = Course{Title: "course2"}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Insert", From("courses"), mock.Anything).Return(3, nil).Once()
+	adapter.On("Delete", From("enrollments").Where(Eq("student_id", 1).AndIn("course_id", 2, 3))).Return(1, nil).Once()
+	adapter.On("InsertAll", From("enrollments"), []string{"student_id", "course_id"}, []map[string]Modify{
+		{"student_id": Set("student_id", 1), "course_id": Set("course_id", 2)},
+		{"student_id": Set("student_id", 1), "course_id": Set("course_id", 3)},
+	}).Return([]interface{}{1, 2}, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Association(&student, "courses").Add(context.TODO(), &course1, &course2))
+	assert.Equal(t, 3, course2.ID)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Association_manyToManyAddError(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		student = Student{ID: 1}
+		course  = Course{ID: 2}
+		err     = errors.New("error")
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Delete", From("enrollments").Where(Eq("student_id", 1).AndIn("course_id", 2))).Return(0, nil).Once()
+	adapter.On("InsertAll", From("enrollments"), mock.Anything, mock.Anything).Return([]interface{}(nil), err).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, err, repo.Association(&student, "courses").Add(context.TODO(), &course))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Association_manyToManyRemove(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		student = Student{ID: 1}
+		course  = Course{ID: 2}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Delete", From("enrollments").Where(Eq("student_id", 1).AndIn("course_id", 2))).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Association(&student, "courses").Remove(context.TODO(), &course))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Association_manyToManyReplace(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		student = Student{ID: 1}
+		course  = Course{ID: 2}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Delete", From("enrollments").Where(Eq("student_id", 1))).Return(2, nil).Once()
+	adapter.On("InsertAll", From("enrollments"), []string{"student_id", "course_id"}, []map[string]Modify{
+		{"student_id": Set("student_id", 1), "course_id": Set("course_id", 2)},
+	}).Return([]interface{}{1}, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Association(&student, "courses").Replace(context.TODO(), &course))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Association_manyToManyClear(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		student = Student{ID: 1}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Delete", From("enrollments").Where(Eq("student_id", 1))).Return(2, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Association(&student, "courses").Clear(context.TODO()))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Association_manyToManyCount(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		student = Student{ID: 1}
+	)
+
+	adapter.On("Aggregate", From("enrollments").Where(Eq("student_id", 1)), "count", "*").Return(2, nil).Once()
+
+	count, err := repo.Association(&student, "courses").Count(context.TODO())
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Association_readOnly(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter, readOnly: true}
+		user    = User{ID: 1}
+	)
+
+	assert.Equal(t, ErrReadOnlyTransaction, repo.Association(&user, "transactions").Clear(context.TODO()))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Association_invalid(t *testing.T) {
+	var (
+		repo = repository{adapter: &testAdapter{}}
+	)
+
+	assert.PanicsWithValue(t, "rel: association proxy only supports has many and many to many association", func() {
+		repo.Association(&User{ID: 1}, "address")
+	})
+
+	assert.PanicsWithValue(t, "rel: association proxy doesn't support has many through association (remarks)", func() {
+		repo.Association(&Author{ID: 1}, "remarks")
+	})
+
+	assert.PanicsWithValue(t, "rel: association proxy requires reference value of association (transactions)", func() {
+		repo.Association(&User{}, "transactions")
+	})
+
+	assert.PanicsWithValue(t, "rel: record type (rel.Address) doesn't match association type (rel.Transaction)", func() {
+		repo.Association(&User{ID: 1}, "transactions").Add(context.TODO(), &Address{})
+	})
+}
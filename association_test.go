@@ -3,6 +3,7 @@ package rel
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -206,3 +207,242 @@ func TestAssociation_Collection(t *testing.T) {
 		})
 	}
 }
+
+type Student struct {
+	ID      int
+	Name    string
+	Courses []Course `through:"enrollments"`
+}
+
+type Course struct {
+	ID       int
+	Title    string
+	Students []Student `through:"enrollments"`
+	Mentors  []Student `through:"mentorships,course_id,mentor_id"`
+}
+
+type Author struct {
+	ID       int
+	Articles []Article
+	Taggings []Tagging
+	Remarks  []Remark `through:"articles"`
+	Tags     []Tag    `through:"taggings.tag"`
+}
+
+type Article struct {
+	ID        int
+	AuthorID  int
+	Remarks   []Remark
+	DeletedAt *time.Time
+}
+
+type Remark struct {
+	ID        int
+	ArticleID *int
+	Body      string
+}
+
+type Tagging struct {
+	ID       int
+	AuthorID int
+	TagID    int
+	Tag      Tag
+}
+
+type Tag struct {
+	ID   int
+	Name string
+}
+
+func TestAssociation_manyToMany(t *testing.T) {
+	var (
+		student = NewDocument(&Student{ID: 1}).Association("courses")
+		course  = NewDocument(&Course{ID: 10}).Association("students")
+		mentors = NewDocument(&Course{ID: 10}).Association("mentors")
+	)
+
+	assert.Equal(t, ManyToMany, int(student.Type()))
+	assert.Equal(t, "enrollments", student.Through())
+	assert.Equal(t, "student_id", student.ThroughReferenceField())
+	assert.Equal(t, "course_id", student.ThroughForeignField())
+	assert.Equal(t, "id", student.ReferenceField())
+	assert.Equal(t, 1, student.ReferenceValue())
+	assert.Equal(t, "id", student.ForeignField())
+	assert.Equal(t, reflect.TypeOf(0), student.foreignType())
+	assert.Panics(t, func() { student.ForeignValue() })
+
+	assert.Equal(t, ManyToMany, int(course.Type()))
+	assert.Equal(t, "enrollments", course.Through())
+	assert.Equal(t, "course_id", course.ThroughReferenceField())
+	assert.Equal(t, "student_id", course.ThroughForeignField())
+
+	assert.Equal(t, "mentorships", mentors.Through())
+	assert.Equal(t, "course_id", mentors.ThroughReferenceField())
+	assert.Equal(t, "mentor_id", mentors.ThroughForeignField())
+
+	assert.Equal(t, []string{"courses"}, NewDocument(&Student{}).ManyToMany())
+	assert.Nil(t, NewDocument(&Student{}).HasMany())
+}
+
+func TestAssociation_replacement(t *testing.T) {
+	type Item struct {
+		ID      int
+		OrderID int
+	}
+
+	type Order struct {
+		ID            int
+		Items         []Item
+		NullifyItems  []Item `ref:"id" fk:"order_id" replace:"nullify"`
+		RestrictItems []Item `ref:"id" fk:"order_id" replace:"restrict"`
+		DeleteItems   []Item `ref:"id" fk:"order_id" replace:"delete"`
+	}
+
+	type InvalidOrder struct {
+		ID    int
+		Items []Item `ref:"id" fk:"order_id" replace:"cascade"`
+	}
+
+	var (
+		doc = NewDocument(&Order{})
+	)
+
+	assert.Equal(t, ReplaceDiff, doc.Association("items").Replacement())
+	assert.Equal(t, ReplaceNullify, doc.Association("nullify_items").Replacement())
+	assert.Equal(t, ReplaceRestrict, doc.Association("restrict_items").Replacement())
+	assert.Equal(t, ReplaceDelete, doc.Association("delete_items").Replacement())
+	assert.PanicsWithValue(t, "rel: invalid replace strategy (cascade)", func() {
+		NewDocument(&InvalidOrder{}).Association("items")
+	})
+}
+
+func TestReplacement_String(t *testing.T) {
+	assert.Equal(t, "delete", ReplaceDelete.String())
+	assert.Equal(t, "nullify", ReplaceNullify.String())
+	assert.Equal(t, "restrict", ReplaceRestrict.String())
+	assert.Equal(t, "diff", ReplaceDiff.String())
+	assert.Equal(t, "", Replacement(10).String())
+}
+
+func TestAssociation_touch(t *testing.T) {
+	var (
+		doc = NewDocument(&Note{})
+	)
+
+	assert.True(t, doc.Association("user").Touch())
+	assert.False(t, NewDocument(&Address{}).Association("user").Touch())
+	assert.Equal(t, []string{"user"}, doc.data.touch)
+}
+
+func TestAssociation_verify(t *testing.T) {
+	var (
+		doc = NewDocument(&Review{})
+	)
+
+	assert.True(t, doc.Association("user").Verify())
+	assert.False(t, NewDocument(&Note{}).Association("user").Verify())
+	assert.Equal(t, []string{"user"}, doc.data.verify)
+}
+
+func TestAssociation_selfReferencing(t *testing.T) {
+	var (
+		parentID = 1
+		category = NewDocument(&Category{ID: 2, ParentID: &parentID})
+		employee = NewDocument(&Employee{ID: 1})
+		tests    = []struct {
+			assoc Association
+			typ   AssociationType
+			ref   string
+			fk    string
+		}{
+			{category.Association("parent"), BelongsTo, "parent_id", "id"},
+			{category.Association("children"), HasMany, "id", "parent_id"},
+			{employee.Association("manager"), BelongsTo, "manager_id", "id"},
+			{employee.Association("reports"), HasMany, "id", "manager_id"},
+			{employee.Association("predecessor"), BelongsTo, "predecessor_id", "id"},
+			{employee.Association("successor"), HasOne, "id", "predecessor_id"},
+		}
+	)
+
+	for _, test := range tests {
+		assert.Equal(t, test.typ, test.assoc.Type())
+		assert.Equal(t, test.ref, test.assoc.ReferenceField())
+		assert.Equal(t, test.fk, test.assoc.ForeignField())
+	}
+
+	assert.Equal(t, 1, category.Association("parent").ReferenceValue())
+	assert.Equal(t, 2, category.Association("children").ReferenceValue())
+	assert.Equal(t, []string{"parent"}, category.BelongsTo())
+	assert.Equal(t, []string{"children"}, category.HasMany())
+	assert.Equal(t, []string{"manager", "predecessor"}, employee.BelongsTo())
+	assert.Equal(t, []string{"successor"}, employee.HasOne())
+	assert.Equal(t, []string{"reports"}, employee.HasMany())
+}
+
+func TestAssociation_hasManyThrough(t *testing.T) {
+	var (
+		remarks = NewDocument(&Author{ID: 1}).Association("remarks")
+		tags    = NewDocument(&Author{ID: 1}).Association("tags")
+	)
+
+	assert.Equal(t, ManyToMany, int(remarks.Type()))
+	assert.Equal(t, "articles", remarks.Through())
+	assert.Equal(t, "author_id", remarks.ThroughReferenceField())
+	assert.Equal(t, "id", remarks.ThroughForeignField())
+	assert.Equal(t, "id", remarks.ReferenceField())
+	assert.Equal(t, 1, remarks.ReferenceValue())
+	assert.Equal(t, "article_id", remarks.ForeignField())
+	assert.Equal(t, reflect.TypeOf(0), remarks.foreignType())
+	assert.True(t, remarks.data.throughFlag.Is(HasDeletedAt))
+
+	assert.Equal(t, ManyToMany, int(tags.Type()))
+	assert.Equal(t, "taggings", tags.Through())
+	assert.Equal(t, "author_id", tags.ThroughReferenceField())
+	assert.Equal(t, "tag_id", tags.ThroughForeignField())
+	assert.Equal(t, "id", tags.ForeignField())
+	assert.False(t, tags.data.throughFlag.Is(HasDeletedAt))
+
+	assert.Equal(t, []string{"remarks", "tags"}, NewDocument(&Author{}).ManyToMany())
+	assert.Equal(t, []string{"articles", "taggings"}, NewDocument(&Author{}).HasMany())
+}
+
+func TestAssociation_hasManyThroughInvalid(t *testing.T) {
+	type Itself struct {
+		ID     int
+		Others []Itself `through:"others"`
+	}
+
+	type Unknown struct {
+		ID       int
+		Articles []Article `fk:"author_id"`
+		Remarks  []Remark  `through:"articles.notes"`
+	}
+
+	type Mismatch struct {
+		ID       int
+		Articles []Article `fk:"author_id"`
+		Tags     []Tag     `through:"articles.remarks"`
+	}
+
+	type Nested struct {
+		ID      int
+		Authors []Author `fk:"id"`
+		Tags    []Tag    `through:"authors"`
+	}
+
+	assert.PanicsWithValue(t, "rel: association (others) can't be through itself", func() {
+		NewDocument(&Itself{})
+	})
+
+	assert.PanicsWithValue(t, "rel: through association source (notes) field not found", func() {
+		NewDocument(&Unknown{})
+	})
+
+	assert.PanicsWithValue(t, "rel: through association source (remarks) type doesn't match (tags)", func() {
+		NewDocument(&Mismatch{})
+	})
+
+	assert.PanicsWithValue(t, "rel: nested through association (tags) is not supported", func() {
+		NewDocument(&Nested{})
+	})
+}
@@ -0,0 +1,136 @@
+package rel
+
+import (
+	"reflect"
+)
+
+// chunkQuery splits query into multiple queries when the in filter of its where query has more values than size,
+// every query uses the same filters except the in filter that only contains a chunk of the values.
+// Query that results can't be merged by concatenating them (limit, offset, group, distinct, eager and or filter) is never split,
+// and query with sort is only split when sorted is true, which means the caller doesn't depend on the order across chunks.
+func chunkQuery(query Query, size int, sorted bool) []Query {
+	if size <= 0 || query.LimitQuery > 0 || query.OffsetQuery > 0 || len(query.GroupQuery.Fields) > 0 ||
+		query.SelectQuery.OnlyDistinct || len(query.EagerQuery) > 0 || (len(query.SortQuery) > 0 && !sorted) {
+		return []Query{query}
+	}
+
+	var (
+		where = query.WhereQuery
+		index = -1
+	)
+
+	switch where.Type {
+	case FilterInOp:
+		if values, ok := where.Value.([]interface{}); ok && len(values) > size {
+			return chunkFilter(query, where, size, func(filter FilterQuery) FilterQuery {
+				return filter
+			})
+		}
+	case FilterAndOp:
+		for i, inner := range where.Inner {
+			if values, ok := inner.Value.([]interface{}); ok && inner.Type == FilterInOp && len(values) > size &&
+				(index < 0 || len(values) > len(where.Inner[index].Value.([]interface{}))) {
+				index = i
+			}
+		}
+
+		if index >= 0 {
+			return chunkFilter(query, where.Inner[index], size, func(filter FilterQuery) FilterQuery {
+				inner := make([]FilterQuery, len(where.Inner))
+				copy(inner, where.Inner)
+				inner[index] = filter
+
+				return FilterQuery{Type: FilterAndOp, Inner: inner}
+			})
+		}
+	}
+
+	return []Query{query}
+}
+
+func chunkFilter(query Query, in FilterQuery, size int, wrap func(FilterQuery) FilterQuery) []Query {
+	var (
+		values  = uniqueValues(in.Value.([]interface{}))
+		queries = make([]Query, 0, (len(values)+size-1)/size)
+	)
+
+	for i := 0; i < len(values); i += size {
+		end := i + size
+		if end > len(values) {
+			end = len(values)
+		}
+
+		chunk := query
+		chunk.WhereQuery = wrap(In(in.Field, values[i:end]...))
+		queries = append(queries, chunk)
+	}
+
+	return queries
+}
+
+// uniqueValues returns values without duplicates in the original order, so the same value isn't queried in multiple chunks.
+// Values that aren't comparable are always kept.
+func uniqueValues(values []interface{}) []interface{} {
+	var (
+		seen   = make(map[interface{}]struct{}, len(values))
+		result = make([]interface{}, 0, len(values))
+	)
+
+	for _, value := range values {
+		if value != nil && reflect.TypeOf(value).Comparable() {
+			if _, ok := seen[value]; ok {
+				continue
+			}
+
+			seen[value] = struct{}{}
+		}
+
+		result = append(result, value)
+	}
+
+	return result
+}
+
+// chunkCursor reads results of chunked queries in order, the query of the next chunk is executed
+// only after the cursor of the previous chunk is exhausted.
+type chunkCursor struct {
+	Cursor
+	queries []Query
+	query   func(Query) (Cursor, error)
+	err     error
+}
+
+// Next prepares the next row, and executes query of the next chunk when the current cursor is exhausted.
+// Error of executing the query is returned by the following Scan.
+func (cc *chunkCursor) Next() bool {
+	if cc.err != nil {
+		return false
+	}
+
+	for !cc.Cursor.Next() {
+		if len(cc.queries) == 0 {
+			return false
+		}
+
+		cur, err := cc.query(cc.queries[0])
+		if err != nil {
+			cc.err = err
+			return true
+		}
+
+		cc.Cursor.Close()
+		cc.Cursor = cur
+		cc.queries = cc.queries[1:]
+	}
+
+	return true
+}
+
+// Scan the current row, or returns error of executing the query of the next chunk.
+func (cc *chunkCursor) Scan(dest ...interface{}) error {
+	if cc.err != nil {
+		return cc.err
+	}
+
+	return cc.Cursor.Scan(dest...)
+}
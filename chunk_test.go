@@ -0,0 +1,177 @@
+package rel
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunkQuery(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  Query
+		size   int
+		sorted bool
+		result []Query
+	}{
+		{
+			name:   "disabled",
+			query:  From("users").Where(In("id", 1, 2, 3)),
+			result: []Query{From("users").Where(In("id", 1, 2, 3))},
+		},
+		{
+			name:   "below size",
+			query:  From("users").Where(In("id", 1, 2, 3)),
+			size:   3,
+			result: []Query{From("users").Where(In("id", 1, 2, 3))},
+		},
+		{
+			name:  "in",
+			query: From("users").Where(In("id", 1, 2, 3)),
+			size:  2,
+			result: []Query{
+				From("users").Where(In("id", 1, 2)),
+				From("users").Where(In("id", 3)),
+			},
+		},
+		{
+			name:  "duplicates",
+			query: From("users").Where(In("id", 1, 2, 1, 3, 2, []byte("a"), []byte("a"))),
+			size:  2,
+			result: []Query{
+				From("users").Where(In("id", 1, 2)),
+				From("users").Where(In("id", 3, []byte("a"))),
+				From("users").Where(In("id", []byte("a"))),
+			},
+		},
+		{
+			name:  "and",
+			query: From("users").Where(Eq("active", true), In("id", 1, 2), In("role_id", 1, 2, 3, 4, 5)),
+			size:  2,
+			result: []Query{
+				From("users").Where(Eq("active", true), In("id", 1, 2), In("role_id", 1, 2)),
+				From("users").Where(Eq("active", true), In("id", 1, 2), In("role_id", 3, 4)),
+				From("users").Where(Eq("active", true), In("id", 1, 2), In("role_id", 5)),
+			},
+		},
+		{
+			name:   "or",
+			query:  From("users").Where(Or(Eq("active", true), In("id", 1, 2, 3))),
+			size:   2,
+			result: []Query{From("users").Where(Or(Eq("active", true), In("id", 1, 2, 3)))},
+		},
+		{
+			name:   "not in",
+			query:  From("users").Where(Nin("id", 1, 2, 3)),
+			size:   2,
+			result: []Query{From("users").Where(Nin("id", 1, 2, 3))},
+		},
+		{
+			name:   "limit",
+			query:  From("users").Where(In("id", 1, 2, 3)).Limit(10),
+			size:   2,
+			result: []Query{From("users").Where(In("id", 1, 2, 3)).Limit(10)},
+		},
+		{
+			name:   "group",
+			query:  From("users").Where(In("id", 1, 2, 3)).Group("role_id"),
+			size:   2,
+			result: []Query{From("users").Where(In("id", 1, 2, 3)).Group("role_id")},
+		},
+		{
+			name:   "distinct",
+			query:  From("users").Select("role_id").Distinct().Where(In("id", 1, 2, 3)),
+			size:   2,
+			result: []Query{From("users").Select("role_id").Distinct().Where(In("id", 1, 2, 3))},
+		},
+		{
+			name:   "sort",
+			query:  From("users").Where(In("id", 1, 2, 3)).SortAsc("name"),
+			size:   2,
+			result: []Query{From("users").Where(In("id", 1, 2, 3)).SortAsc("name")},
+		},
+		{
+			name:   "sorted",
+			query:  From("users").Where(In("id", 1, 2, 3)).SortAsc("name"),
+			size:   2,
+			sorted: true,
+			result: []Query{
+				From("users").Where(In("id", 1, 2)).SortAsc("name"),
+				From("users").Where(In("id", 3)).SortAsc("name"),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.result, chunkQuery(test.query, test.size, test.sorted))
+		})
+	}
+}
+
+func TestChunkCursor(t *testing.T) {
+	var (
+		cur1    = &testCursor{}
+		cur2    = &testCursor{}
+		queries = []Query{From("users").Where(In("id", 3))}
+		id      int
+	)
+
+	cur1.On("Next").Return(true).Once()
+	cur1.MockScan(1).Once()
+	cur1.On("Next").Return(false).Once()
+	cur1.On("Close").Return(nil).Once()
+
+	cur2.On("Next").Return(true).Once()
+	cur2.MockScan(3).Once()
+	cur2.On("Next").Return(false).Once()
+
+	cur := &chunkCursor{
+		Cursor:  cur1,
+		queries: queries,
+		query: func(query Query) (Cursor, error) {
+			assert.Equal(t, queries[0], query)
+			return cur2, nil
+		},
+	}
+
+	assert.True(t, cur.Next())
+	assert.Nil(t, cur.Scan(&id))
+	assert.Equal(t, 1, id)
+
+	assert.True(t, cur.Next())
+	assert.Nil(t, cur.Scan(&id))
+	assert.Equal(t, 3, id)
+
+	assert.False(t, cur.Next())
+	assert.Equal(t, cur2, cur.Cursor)
+
+	cur1.AssertExpectations(t)
+	cur2.AssertExpectations(t)
+}
+
+func TestChunkCursor_error(t *testing.T) {
+	var (
+		cur1 = &testCursor{}
+		err  = errors.New("error")
+		id   int
+	)
+
+	cur1.On("Next").Return(false).Once()
+
+	cur := &chunkCursor{
+		Cursor:  cur1,
+		queries: []Query{From("users").Where(In("id", 3))},
+		query: func(query Query) (Cursor, error) {
+			return nil, err
+		},
+	}
+
+	assert.True(t, cur.Next())
+	assert.Equal(t, err, cur.Scan(&id))
+	assert.False(t, cur.Next())
+	assert.Equal(t, cur1, cur.Cursor)
+
+	cur1.AssertExpectations(t)
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	errMissingName = errors.New("rel: migration name is required")
+	errMissingDSN  = errors.New("rel: dsn is required, use -dsn flag or REL_DSN environment variable")
+	errMissingFile = errors.New("rel: go file is required")
+)
+
+// adapter that can be used by the generated migration runner.
+type adapter struct {
+	Name    string
+	Package string
+	Driver  string
+}
+
+var databaseName = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+var adapters = map[string]adapter{
+	"sqlite3": {
+		Name:    "sqlite3",
+		Package: "github.com/Fs02/rel/adapter/sqlite3",
+		Driver:  "github.com/mattn/go-sqlite3",
+	},
+	"mysql": {
+		Name:    "mysql",
+		Package: "github.com/Fs02/rel/adapter/mysql",
+		Driver:  "github.com/go-sql-driver/mysql",
+	},
+	"postgres": {
+		Name:    "postgres",
+		Package: "github.com/Fs02/rel/adapter/postgres",
+		Driver:  "github.com/lib/pq",
+	},
+}
+
+type config struct {
+	command  string
+	flags    *flag.FlagSet
+	database string
+	dir      string
+	adapter  string
+	dsn      string
+	table    string
+	version  int64
+	step     int
+	file     string
+	types    string
+	output   string
+}
+
+func (c *config) parse(args []string) error {
+	if err := c.flags.Parse(args); err != nil {
+		return err
+	}
+
+	if c.command == "gen" {
+		return nil
+	}
+
+	if c.database != "" && !databaseName.MatchString(c.database) {
+		return fmt.Errorf("rel: invalid database name %q, only letters, digits and underscores are allowed", c.database)
+	}
+
+	c.resolve()
+
+	if c.command == "create" {
+		return nil
+	}
+
+	if _, ok := adapters[c.adapter]; !ok {
+		return fmt.Errorf("rel: unsupported adapter %q, supported adapters are sqlite3, mysql and postgres", c.adapter)
+	}
+
+	if c.dsn == "" && c.database != "" {
+		return fmt.Errorf("rel: dsn is required, use -dsn flag or %s environment variable", c.env("DSN"))
+	} else if c.dsn == "" {
+		return errMissingDSN
+	}
+
+	return nil
+}
+
+// resolve flags that are not set using environment variables, or defaults of the database.
+// Named database uses environment variables prefixed by its name, eg: REL_ANALYTICS_DSN,
+// and stores its migrations and structure inside db/<name> directory.
+func (c *config) resolve() {
+	root := "db"
+	if c.database != "" {
+		root = filepath.Join(root, c.database)
+	}
+
+	c.dir = c.lookup(c.dir, "DIR", filepath.Join(root, "migrations"))
+	c.adapter = c.lookup(c.adapter, "ADAPTER", "sqlite3")
+	c.dsn = c.lookup(c.dsn, "DSN", "")
+	c.table = c.lookup(c.table, "VERSION_TABLE", "schema_migrations")
+	c.file = c.lookup(c.file, "STRUCTURE", filepath.Join(root, "structure.sql"))
+}
+
+func (c config) lookup(value string, key string, fallback string) string {
+	if value != "" {
+		return value
+	}
+
+	if value, ok := os.LookupEnv(c.env(key)); ok {
+		return value
+	}
+
+	return fallback
+}
+
+// env returns name of environment variable of the database.
+func (c config) env(key string) string {
+	if c.database == "" {
+		return "REL_" + key
+	}
+
+	return "REL_" + strings.ToUpper(c.database) + "_" + key
+}
+
+func newConfig(command string) *config {
+	c := &config{
+		command: command,
+		flags:   flag.NewFlagSet("rel "+command, flag.ContinueOnError),
+	}
+
+	c.flags.SetOutput(os.Stderr)
+
+	if command == "gen" {
+		c.flags.StringVar(&c.types, "type", "", "comma separated names of struct to generate (default every struct in the file)")
+		c.flags.StringVar(&c.output, "output", "", "generated file (default <file>_rel.go)")
+		return c
+	}
+
+	c.flags.StringVar(&c.database, "database", os.Getenv("REL_DATABASE"), "name of the database, when the project has multiple databases")
+	c.flags.StringVar(&c.dir, "dir", "", "migrations directory (default db/migrations, or db/<database>/migrations)")
+
+	if command == "create" {
+		return c
+	}
+
+	c.flags.StringVar(&c.adapter, "adapter", "", "adapter, one of sqlite3, mysql or postgres (default sqlite3)")
+	c.flags.StringVar(&c.dsn, "dsn", "", "data source name used to open connection")
+	c.flags.StringVar(&c.table, "table", "", "table used to track applied migrations (default schema_migrations)")
+
+	switch command {
+	case "migrate":
+		c.flags.Int64Var(&c.version, "version", 0, "migrate schema to the version instead of the latest version")
+	case "rollback":
+		c.flags.IntVar(&c.step, "step", 1, "number of migrations to revert")
+	case "dump", "load":
+		c.flags.StringVar(&c.file, "file", "", "structure file (default db/structure.sql, or db/<database>/structure.sql)")
+	}
+
+	return c
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig(t *testing.T) {
+	config := newConfig("migrate")
+
+	assert.Nil(t, config.parse([]string{"-adapter", "postgres", "-dsn", "postgres://localhost/rel", "-dir", "migrations", "-version", "20200829084000"}))
+	assert.Equal(t, "postgres", config.adapter)
+	assert.Equal(t, "postgres://localhost/rel", config.dsn)
+	assert.Equal(t, "migrations", config.dir)
+	assert.Equal(t, []string{"migrate-to", "20200829084000"}, config.arguments())
+}
+
+func TestConfig_env(t *testing.T) {
+	os.Setenv("REL_ADAPTER", "mysql")
+	os.Setenv("REL_DSN", "root@(127.0.0.1:3306)/rel")
+	defer os.Unsetenv("REL_ADAPTER")
+	defer os.Unsetenv("REL_DSN")
+
+	config := newConfig("rollback")
+
+	assert.Nil(t, config.parse(nil))
+	assert.Equal(t, "mysql", config.adapter)
+	assert.Equal(t, "root@(127.0.0.1:3306)/rel", config.dsn)
+	assert.Equal(t, "db/migrations", config.dir)
+	assert.Equal(t, "schema_migrations", config.table)
+	assert.Equal(t, []string{"rollback", "1"}, config.arguments())
+}
+
+func TestConfig_database(t *testing.T) {
+	os.Setenv("REL_DSN", "primary.db")
+	os.Setenv("REL_ANALYTICS_ADAPTER", "postgres")
+	os.Setenv("REL_ANALYTICS_DSN", "postgres://localhost/analytics")
+	defer os.Unsetenv("REL_DSN")
+	defer os.Unsetenv("REL_ANALYTICS_ADAPTER")
+	defer os.Unsetenv("REL_ANALYTICS_DSN")
+
+	config := newConfig("dump")
+
+	assert.Nil(t, config.parse([]string{"-database", "analytics"}))
+	assert.Equal(t, "analytics", config.database)
+	assert.Equal(t, "postgres", config.adapter)
+	assert.Equal(t, "postgres://localhost/analytics", config.dsn)
+	assert.Equal(t, "db/analytics/migrations", config.dir)
+	assert.Equal(t, "schema_migrations", config.table)
+	assert.Equal(t, []string{"dump", "db/analytics/structure.sql"}, config.arguments())
+}
+
+func TestConfig_database_env(t *testing.T) {
+	os.Setenv("REL_DATABASE", "analytics")
+	os.Setenv("REL_ANALYTICS_DIR", "analytics/migrations")
+	os.Setenv("REL_ANALYTICS_VERSION_TABLE", "analytics_schema_migrations")
+	defer os.Unsetenv("REL_DATABASE")
+	defer os.Unsetenv("REL_ANALYTICS_DIR")
+	defer os.Unsetenv("REL_ANALYTICS_VERSION_TABLE")
+
+	config := newConfig("status")
+
+	assert.Nil(t, config.parse([]string{"-dsn", "analytics.db"}))
+	assert.Equal(t, "analytics", config.database)
+	assert.Equal(t, "sqlite3", config.adapter)
+	assert.Equal(t, "analytics/migrations", config.dir)
+	assert.Equal(t, "analytics_schema_migrations", config.table)
+}
+
+func TestConfig_database_missingDSN(t *testing.T) {
+	os.Setenv("REL_DSN", "primary.db")
+	defer os.Unsetenv("REL_DSN")
+
+	config := newConfig("migrate")
+
+	err := config.parse([]string{"-database", "analytics"})
+	assert.EqualError(t, err, "rel: dsn is required, use -dsn flag or REL_ANALYTICS_DSN environment variable")
+}
+
+func TestConfig_database_invalid(t *testing.T) {
+	config := newConfig("create")
+
+	err := config.parse([]string{"-database", "../analytics"})
+	assert.EqualError(t, err, `rel: invalid database name "../analytics", only letters, digits and underscores are allowed`)
+}
+
+func TestConfig_arguments(t *testing.T) {
+	tests := []struct {
+		command   string
+		args      []string
+		arguments []string
+	}{
+		{
+			command:   "migrate",
+			arguments: []string{"migrate"},
+		},
+		{
+			command:   "rollback",
+			args:      []string{"-step", "3"},
+			arguments: []string{"rollback", "3"},
+		},
+		{
+			command:   "status",
+			arguments: []string{"status"},
+		},
+		{
+			command:   "dump",
+			arguments: []string{"dump", "db/structure.sql"},
+		},
+		{
+			command:   "load",
+			args:      []string{"-file", "structure.sql"},
+			arguments: []string{"load", "structure.sql"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.command, func(t *testing.T) {
+			config := newConfig(test.command)
+
+			assert.Nil(t, config.parse(append(test.args, "-dsn", "dev.db")))
+			assert.Equal(t, test.arguments, config.arguments())
+		})
+	}
+}
+
+func TestConfig_unsupportedAdapter(t *testing.T) {
+	config := newConfig("migrate")
+
+	err := config.parse([]string{"-adapter", "oracle", "-dsn", "dev.db"})
+	assert.EqualError(t, err, `rel: unsupported adapter "oracle", supported adapters are sqlite3, mysql and postgres`)
+}
+
+func TestConfig_missingDSN(t *testing.T) {
+	config := newConfig("status")
+
+	assert.Equal(t, errMissingDSN, config.parse(nil))
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/azer/snakecase"
+)
+
+// model is a struct type that its scanner and valuer are generated.
+type model struct {
+	Name   string
+	Fields []modelField
+}
+
+// modelField of model that is mapped to a column, using the same rules as rel.
+type modelField struct {
+	Name   string
+	Column string
+	Ptr    bool
+}
+
+var models = template.Must(template.New("models").Parse(`// Code generated by rel gen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"database/sql"
+{{- if .Nullable}}
+
+	"github.com/Fs02/rel"
+{{- end}}
+)
+{{range .Models}}
+// FieldScanners returns scanners of the fields, it's used by rel to scan {{.Name}} without reflection.
+func (r *{{.Name}}) FieldScanners(fields []string) []interface{} {
+	result := make([]interface{}, len(fields))
+	for i, field := range fields {
+		switch field {
+		{{- range .Fields}}
+		case {{printf "%q" .Column}}:
+			result[i] = {{if .Ptr}}&r.{{.Name}}{{else}}rel.Nullable(&r.{{.Name}}){{end}}
+		{{- end}}
+		default:
+			result[i] = &sql.RawBytes{}
+		}
+	}
+
+	return result
+}
+
+// FieldValue returns value of the field, it's used by rel to build changes of {{.Name}} without reflection.
+func (r *{{.Name}}) FieldValue(field string) (interface{}, bool) {
+	switch field {
+	{{- range .Fields}}
+	case {{printf "%q" .Column}}:
+		{{- if .Ptr}}
+		if r.{{.Name}} == nil {
+			return nil, true
+		}
+
+		return *r.{{.Name}}, true
+		{{- else}}
+		return r.{{.Name}}, true
+		{{- end}}
+	{{- end}}
+	}
+
+	return nil, false
+}
+{{end}}`))
+
+// gen generates scanner and valuer of structs defined in the file, and returns path of the generated file.
+// All structs in the file are generated when types is empty.
+func gen(path string, output string, types []string) (string, error) {
+	pkg, result, err := parseModels(path, types)
+	if err != nil {
+		return "", err
+	}
+
+	if output == "" {
+		output = strings.TrimSuffix(path, filepath.Ext(path)) + "_rel.go"
+	}
+
+	var (
+		buffer   bytes.Buffer
+		nullable = false
+	)
+
+	for _, m := range result {
+		for _, field := range m.Fields {
+			nullable = nullable || !field.Ptr
+		}
+	}
+
+	if err := models.Execute(&buffer, struct {
+		Package  string
+		Nullable bool
+		Models   []model
+	}{
+		Package:  pkg,
+		Nullable: nullable,
+		Models:   result,
+	}); err != nil {
+		return "", err
+	}
+
+	src, err := format.Source(buffer.Bytes())
+	if err != nil {
+		return "", err
+	}
+
+	return output, ioutil.WriteFile(output, src, 0644)
+}
+
+// parseModels returns package name and struct types defined in the file, in the order of declaration.
+func parseModels(path string, types []string) (string, []model, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var (
+		result []model
+		found  = make(map[string]bool)
+	)
+
+	for _, decl := range file.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range gd.Specs {
+			var (
+				ts = spec.(*ast.TypeSpec)
+			)
+
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok || (len(types) > 0 && !contains(types, ts.Name.Name)) {
+				continue
+			}
+
+			found[ts.Name.Name] = true
+			result = append(result, model{
+				Name:   ts.Name.Name,
+				Fields: parseFields(st),
+			})
+		}
+	}
+
+	for _, name := range types {
+		if !found[name] {
+			return "", nil, fmt.Errorf("rel: struct %s is not found in %s", name, filepath.Base(path))
+		}
+	}
+
+	if len(result) == 0 {
+		return "", nil, fmt.Errorf("rel: no struct is found in %s", filepath.Base(path))
+	}
+
+	return file.Name.Name, result, nil
+}
+
+func parseFields(st *ast.StructType) []modelField {
+	var (
+		result []modelField
+	)
+
+	for _, field := range st.Fields.List {
+		var (
+			tag    reflect.StructTag
+			names  []string
+			_, ptr = field.Type.(*ast.StarExpr)
+		)
+
+		if field.Tag != nil {
+			value, _ := strconv.Unquote(field.Tag.Value)
+			tag = reflect.StructTag(value)
+		}
+
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+
+		// embedded field is named using its type.
+		if len(names) == 0 {
+			names = append(names, embeddedName(field.Type))
+		}
+
+		for _, name := range names {
+			if column := columnName(name, tag); column != "" {
+				result = append(result, modelField{
+					Name:   name,
+					Column: column,
+					Ptr:    ptr,
+				})
+			}
+		}
+	}
+
+	return result
+}
+
+// columnName of the field, mirrors how rel names the field using db tag or snake case of its name.
+func columnName(name string, tag reflect.StructTag) string {
+	if tag := tag.Get("db"); tag != "" {
+		column := strings.Split(tag, ",")[0]
+
+		if column == "-" {
+			return ""
+		}
+
+		if column != "" {
+			return column
+		}
+	}
+
+	return snakecase.SnakeCase(name)
+}
+
+func embeddedName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.Ident:
+		return t.Name
+	}
+
+	return ""
+}
+
+func contains(values []string, value string) bool {
+	for i := range values {
+		if values[i] == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const genModels = `package models
+
+type Base struct{}
+
+type User struct {
+	Base
+	ID         int
+	Name, Nick string
+	Email      *string ` + "`db:\"email_address\"`" + `
+	Secret     string  ` + "`db:\"-\"`" + `
+}
+
+type Book struct {
+	ID *int
+}
+`
+
+func TestGen(t *testing.T) {
+	var (
+		dir  = tempDir(t)
+		path = filepath.Join(dir, "models.go")
+	)
+
+	writeFile(t, path, genModels)
+
+	output, err := gen(path, "", []string{"User"})
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(dir, "models_rel.go"), output)
+
+	src, err := ioutil.ReadFile(output)
+	assert.Nil(t, err)
+	assert.Equal(t, `// Code generated by rel gen. DO NOT EDIT.
+
+package models
+
+import (
+	"database/sql"
+
+	"github.com/Fs02/rel"
+)
+
+// FieldScanners returns scanners of the fields, it's used by rel to scan User without reflection.
+func (r *User) FieldScanners(fields []string) []interface{} {
+	result := make([]interface{}, len(fields))
+	for i, field := range fields {
+		switch field {
+		case "base":
+			result[i] = rel.Nullable(&r.Base)
+		case "id":
+			result[i] = rel.Nullable(&r.ID)
+		case "name":
+			result[i] = rel.Nullable(&r.Name)
+		case "nick":
+			result[i] = rel.Nullable(&r.Nick)
+		case "email_address":
+			result[i] = &r.Email
+		default:
+			result[i] = &sql.RawBytes{}
+		}
+	}
+
+	return result
+}
+
+// FieldValue returns value of the field, it's used by rel to build changes of User without reflection.
+func (r *User) FieldValue(field string) (interface{}, bool) {
+	switch field {
+	case "base":
+		return r.Base, true
+	case "id":
+		return r.ID, true
+	case "name":
+		return r.Name, true
+	case "nick":
+		return r.Nick, true
+	case "email_address":
+		if r.Email == nil {
+			return nil, true
+		}
+
+		return *r.Email, true
+	}
+
+	return nil, false
+}
+`, string(src))
+}
+
+func TestGen_pointerOnly(t *testing.T) {
+	var (
+		dir    = tempDir(t)
+		path   = filepath.Join(dir, "models.go")
+		output = filepath.Join(dir, "book.go")
+	)
+
+	writeFile(t, path, genModels)
+
+	result, err := gen(path, output, []string{"Book"})
+	assert.Nil(t, err)
+	assert.Equal(t, output, result)
+
+	src, err := ioutil.ReadFile(output)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(src), "github.com/Fs02/rel")
+	assert.Contains(t, string(src), "result[i] = &r.ID")
+}
+
+func TestGen_allStructs(t *testing.T) {
+	var (
+		path = filepath.Join(tempDir(t), "models.go")
+	)
+
+	writeFile(t, path, genModels)
+
+	_, models, err := parseModels(path, nil)
+	assert.Nil(t, err)
+	assert.Len(t, models, 3)
+	assert.Equal(t, "Base", models[0].Name)
+	assert.Equal(t, []modelField{{Name: "ID", Column: "id", Ptr: true}}, models[2].Fields)
+}
+
+func TestGen_notFound(t *testing.T) {
+	var (
+		path = filepath.Join(tempDir(t), "models.go")
+	)
+
+	writeFile(t, path, genModels)
+
+	_, err := gen(path, "", []string{"Author"})
+	assert.EqualError(t, err, "rel: struct Author is not found in models.go")
+
+	writeFile(t, path, "package models\n")
+
+	_, err = gen(path, "", nil)
+	assert.EqualError(t, err, "rel: no struct is found in models.go")
+}
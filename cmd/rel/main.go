@@ -0,0 +1,118 @@
+// Command rel manages schema migrations of a project using REL.
+//
+// Migrations are go files inside migrations directory, named using its version and name, eg: 20200829084000_create_books.go.
+// Each file defines migrate function and optionally rollback function, which are registered to migrator by their version.
+//
+// Usage:
+//	rel create create_books             # creates db/migrations/20200829084000_create_books.go
+//	rel migrate                         # applies pending migrations
+//	rel migrate -version 20200829084000 # migrates schema to the version
+//	rel rollback                        # reverts the last applied migration
+//	rel rollback -step 3                # reverts the last 3 applied migrations
+//	rel status                          # prints status of every migrations
+//	rel dump                            # dumps structure of the database to db/structure.sql
+//	rel load                            # loads structure of the database from db/structure.sql
+//	rel gen -type User,Book models.go   # generates scanner and valuer of User and Book to models_rel.go
+//
+// Adapter and dsn are configured using -adapter and -dsn flags, or REL_ADAPTER and REL_DSN environment variables.
+// Supported adapters are sqlite3, mysql and postgres.
+//
+// Generated scanner and valuer are used by rel in place of reflection to scan and build changes of the struct,
+// it's optional and can be used for the hottest models, eg: using go:generate directive.
+//	//go:generate rel gen -type User models.go
+//
+// Project with multiple databases selects the database using -database flag or REL_DATABASE environment variable,
+// its migrations are stored in db/<database>/migrations and configured using environment variables prefixed by its name, eg: REL_ANALYTICS_DSN.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const usage = `Usage: rel <command> [flags]
+
+Commands:
+  create <name>  creates a new migration file
+  migrate        applies pending migrations
+  rollback       reverts applied migrations
+  status         prints status of every migrations
+  dump           dumps structure of the database
+  load           loads structure of the database
+  gen <file>     generates scanner and valuer of structs in a go file
+
+Run 'rel <command> -h' for flags of the command.
+`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stdout, usage)
+		return nil
+	}
+
+	var (
+		command = args[0]
+		config  = newConfig(command)
+	)
+
+	switch command {
+	case "create":
+		if err := config.parse(args[1:]); err != nil {
+			return err
+		}
+
+		if config.flags.NArg() != 1 {
+			return errMissingName
+		}
+
+		path, err := create(config.dir, config.flags.Arg(0), time.Now())
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(stdout, "created", path)
+		return nil
+	case "gen":
+		if err := config.parse(args[1:]); err != nil {
+			return err
+		}
+
+		if config.flags.NArg() != 1 {
+			return errMissingFile
+		}
+
+		var types []string
+		if config.types != "" {
+			types = strings.Split(config.types, ",")
+		}
+
+		path, err := gen(config.flags.Arg(0), config.output, types)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(stdout, "generated", path)
+		return nil
+	case "migrate", "rollback", "status", "dump", "load":
+		if err := config.parse(args[1:]); err != nil {
+			return err
+		}
+
+		return execute(config, stdout)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return nil
+	default:
+		return fmt.Errorf("rel: unknown command %q", command)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/azer/snakecase"
+)
+
+var migrationFile = regexp.MustCompile(`^(\d+)_(\w+)\.go$`)
+
+// migration file defined in migrations directory.
+type migration struct {
+	Version  int64
+	Name     string
+	Migrate  string
+	Rollback string
+}
+
+// scan migrations directory and returns migrations sorted by its version.
+func scan(dir string) ([]migration, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		result []migration
+		fset   = token.NewFileSet()
+	)
+
+	for _, file := range files {
+		match := migrationFile.FindStringSubmatch(file.Name())
+		if file.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		funcs, err := parseFuncs(fset, filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m := migration{
+			Version:  version,
+			Name:     match[2],
+			Migrate:  "Migrate" + camelize(match[2]),
+			Rollback: "Rollback" + camelize(match[2]),
+		}
+
+		if !funcs[m.Migrate] {
+			return nil, fmt.Errorf("rel: %s doesn't define %s function", file.Name(), m.Migrate)
+		}
+
+		if !funcs[m.Rollback] {
+			m.Rollback = ""
+		}
+
+		result = append(result, m)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+
+	return result, nil
+}
+
+// parseFuncs returns name of top level functions in the file.
+func parseFuncs(fset *token.FileSet, path string) (map[string]bool, error) {
+	file, err := parser.ParseFile(fset, path, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	funcs := make(map[string]bool)
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil {
+			funcs[fn.Name.Name] = true
+		}
+	}
+
+	return funcs, nil
+}
+
+func camelize(name string) string {
+	var (
+		buffer strings.Builder
+	)
+
+	for _, word := range strings.Split(name, "_") {
+		if word != "" {
+			buffer.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+
+	return buffer.String()
+}
+
+var scaffold = template.Must(template.New("scaffold").Parse(`package {{.Package}}
+
+import (
+	"github.com/Fs02/rel/migrator"
+)
+
+// Migrate{{.Func}} definition.
+func Migrate{{.Func}}(schema *migrator.Schema) {
+}
+
+// Rollback{{.Func}} definition.
+func Rollback{{.Func}}(schema *migrator.Schema) {
+}
+`))
+
+// create a new migration file in migrations directory and returns its path.
+func create(dir string, name string, now time.Time) (string, error) {
+	name = snakecase.SnakeCase(strings.NewReplacer(" ", "_", "-", "_").Replace(name))
+	if name == "" {
+		return "", errMissingName
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	var (
+		pkg  = packageName(dir)
+		path = filepath.Join(dir, now.UTC().Format("20060102150405")+"_"+name+".go")
+	)
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return "", err
+	}
+
+	defer file.Close()
+
+	return path, scaffold.Execute(file, struct {
+		Package string
+		Func    string
+	}{
+		Package: pkg,
+		Func:    camelize(name),
+	})
+}
+
+// packageName returns package name of existing files in the directory, or name of the directory.
+func packageName(dir string) string {
+	if pkgs, err := parser.ParseDir(token.NewFileSet(), dir, nil, parser.PackageClauseOnly); err == nil {
+		for name := range pkgs {
+			if !strings.HasSuffix(name, "_test") {
+				return name
+			}
+		}
+	}
+
+	abs, _ := filepath.Abs(dir)
+	return strings.NewReplacer("-", "", ".", "", "_", "").Replace(filepath.Base(abs))
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "rel")
+	assert.Nil(t, err)
+
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+
+	return dir
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+}
+
+func TestScan(t *testing.T) {
+	dir := tempDir(t)
+
+	writeFile(t, filepath.Join(dir, "20200829084100_add_author_to_books.go"), `package migrations
+
+func MigrateAddAuthorToBooks(schema *migrator.Schema) {}
+`)
+	writeFile(t, filepath.Join(dir, "20200829084000_create_books.go"), `package migrations
+
+func MigrateCreateBooks(schema *migrator.Schema) {}
+
+func RollbackCreateBooks(schema *migrator.Schema) {}
+`)
+	writeFile(t, filepath.Join(dir, "helper.go"), `package migrations`)
+
+	migrations, err := scan(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, []migration{
+		{Version: 20200829084000, Name: "create_books", Migrate: "MigrateCreateBooks", Rollback: "RollbackCreateBooks"},
+		{Version: 20200829084100, Name: "add_author_to_books", Migrate: "MigrateAddAuthorToBooks"},
+	}, migrations)
+}
+
+func TestScan_missingMigrate(t *testing.T) {
+	dir := tempDir(t)
+
+	writeFile(t, filepath.Join(dir, "20200829084000_create_books.go"), `package migrations
+
+func RollbackCreateBooks(schema *migrator.Schema) {}
+`)
+
+	_, err := scan(dir)
+	assert.EqualError(t, err, "rel: 20200829084000_create_books.go doesn't define MigrateCreateBooks function")
+}
+
+func TestScan_notExists(t *testing.T) {
+	_, err := scan(filepath.Join(tempDir(t), "migrations"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCamelize(t *testing.T) {
+	assert.Equal(t, "CreateBooks", camelize("create_books"))
+	assert.Equal(t, "AddISBN", camelize("add_ISBN"))
+	assert.Equal(t, "Users", camelize("_users_"))
+}
+
+func TestCreate(t *testing.T) {
+	var (
+		dir = filepath.Join(tempDir(t), "db", "migrations")
+		now = time.Date(2020, 8, 29, 8, 40, 0, 0, time.UTC)
+	)
+
+	path, err := create(dir, "Create Books", now)
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(dir, "20200829084000_create_books.go"), path)
+
+	content, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Contains(t, string(content), "package migrations\n")
+	assert.Contains(t, string(content), "func MigrateCreateBooks(schema *migrator.Schema) {\n}")
+	assert.Contains(t, string(content), "func RollbackCreateBooks(schema *migrator.Schema) {\n}")
+
+	migrations, err := scan(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, []migration{
+		{Version: 20200829084000, Name: "create_books", Migrate: "MigrateCreateBooks", Rollback: "RollbackCreateBooks"},
+	}, migrations)
+
+	// file already exists.
+	_, err = create(dir, "create_books", now)
+	assert.True(t, os.IsExist(err))
+}
+
+func TestCreate_existingPackage(t *testing.T) {
+	dir := tempDir(t)
+	writeFile(t, filepath.Join(dir, "helper.go"), `package schema`)
+
+	path, err := create(dir, "create_books", time.Now())
+	assert.Nil(t, err)
+
+	content, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Contains(t, string(content), "package schema\n")
+}
+
+func TestCreate_missingName(t *testing.T) {
+	_, err := create(tempDir(t), "", time.Now())
+	assert.Equal(t, errMissingName, err)
+}
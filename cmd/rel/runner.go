@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+var errModuleNotFound = errors.New("rel: go.mod not found, rel must be run inside a go module")
+
+var runner = template.Must(template.New("runner").Parse(`// Code generated by rel. DO NOT EDIT.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/migrator"
+	"{{.Adapter.Package}}"
+	_ "{{.Adapter.Driver}}"
+{{- if .Migrations}}
+	migrations "{{.Package}}"
+{{- end}}
+)
+
+func main() {
+	adapter, err := {{.Adapter.Name}}.Open(os.Getenv("REL_DSN"))
+	if err != nil {
+		fail(err)
+	}
+
+	defer adapter.Close()
+
+	var (
+		ctx = context.Background()
+		m   = migrator.New(rel.New(adapter), migrator.VersionTableName(os.Getenv("REL_VERSION_TABLE")))
+	)
+{{range .Migrations}}
+	m.Register({{.Version}}, "{{.Name}}", migrations.{{.Migrate}}, {{if .Rollback}}migrations.{{.Rollback}}{{else}}nil{{end}})
+{{- end}}
+
+	switch os.Args[1] {
+	case "migrate":
+		err = m.Migrate(ctx)
+	case "migrate-to":
+		err = m.MigrateTo(ctx, parseInt(os.Args[2]))
+	case "rollback":
+		err = m.Rollback(ctx, int(parseInt(os.Args[2])))
+	case "status":
+		err = status(ctx, m)
+	case "dump":
+		err = dump(ctx, m, os.Args[2])
+	case "load":
+		err = load(ctx, m, os.Args[2])
+	}
+
+	if err != nil {
+		fail(err)
+	}
+}
+
+func status(ctx context.Context, m *migrator.Migrator) error {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Status\tVersion\tName\tApplied At")
+
+	for _, s := range status {
+		state, appliedAt := "down", ""
+		if s.Applied {
+			state, appliedAt = "up", s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", state, s.Version, s.Name, appliedAt)
+	}
+
+	return w.Flush()
+}
+
+func dump(ctx context.Context, m *migrator.Migrator, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := m.Dump(ctx, file); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+func load(ctx context.Context, m *migrator.Migrator, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	return m.Load(ctx, file)
+}
+
+func parseInt(s string) int64 {
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		fail(err)
+	}
+
+	return i
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
+`))
+
+// generate migration runner that registers every migrations in the package.
+func generate(w io.Writer, target adapter, pkg string, migrations []migration) error {
+	return runner.Execute(w, struct {
+		Adapter    adapter
+		Package    string
+		Migrations []migration
+	}{
+		Adapter:    target,
+		Package:    pkg,
+		Migrations: migrations,
+	})
+}
+
+// arguments passed to the generated migration runner.
+func (c config) arguments() []string {
+	switch {
+	case c.command == "migrate" && c.version != 0:
+		return []string{"migrate-to", strconv.FormatInt(c.version, 10)}
+	case c.command == "rollback":
+		return []string{"rollback", strconv.Itoa(c.step)}
+	case c.command == "dump" || c.command == "load":
+		return []string{c.command, c.file}
+	default:
+		return []string{c.command}
+	}
+}
+
+// execute generates migration runner inside the module and runs it using go run.
+func execute(config *config, stdout io.Writer) error {
+	root, module, err := findModule(".")
+	if err != nil {
+		return err
+	}
+
+	pkg, err := importPath(root, module, config.dir)
+	if err != nil {
+		return err
+	}
+
+	migrations, err := scan(config.dir)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempDir(root, ".rel")
+	if err != nil {
+		return err
+	}
+
+	defer os.RemoveAll(tmp)
+
+	file, err := os.Create(filepath.Join(tmp, "main.go"))
+	if err != nil {
+		return err
+	}
+
+	if err := generate(file, adapters[config.adapter], pkg, migrations); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("go", append([]string{"run", file.Name()}, config.arguments()...)...)
+	cmd.Env = append(os.Environ(), "REL_DSN="+config.dsn, "REL_VERSION_TABLE="+config.table)
+	cmd.Stdout = stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
+
+// findModule looks up go.mod from the directory to its parents, and returns the module root and path.
+func findModule(dir string) (string, string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", "", err
+	}
+
+	for {
+		if file, err := os.Open(filepath.Join(dir, "go.mod")); err == nil {
+			defer file.Close()
+
+			scanner := bufio.NewScanner(file)
+			for scanner.Scan() {
+				if line := strings.TrimSpace(scanner.Text()); strings.HasPrefix(line, "module ") {
+					return dir, strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module ")), `"`), nil
+				}
+			}
+
+			return "", "", errModuleNotFound
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", errModuleNotFound
+		}
+
+		dir = parent
+	}
+}
+
+// importPath of the directory inside the module.
+func importPath(root string, module string, dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", errors.New("rel: migrations directory must be inside the module")
+	}
+
+	if rel == "." {
+		return module, nil
+	}
+
+	return module + "/" + filepath.ToSlash(rel), nil
+}
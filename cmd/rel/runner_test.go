@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	var (
+		buffer     bytes.Buffer
+		migrations = []migration{
+			{Version: 20200829084000, Name: "create_books", Migrate: "MigrateCreateBooks", Rollback: "RollbackCreateBooks"},
+			{Version: 20200829084100, Name: "add_author_to_books", Migrate: "MigrateAddAuthorToBooks"},
+		}
+	)
+
+	assert.Nil(t, generate(&buffer, adapters["sqlite3"], "example.com/app/db/migrations", migrations))
+
+	_, err := format.Source(buffer.Bytes())
+	assert.Nil(t, err)
+
+	assert.Contains(t, buffer.String(), `"github.com/Fs02/rel/adapter/sqlite3"`)
+	assert.Contains(t, buffer.String(), `_ "github.com/mattn/go-sqlite3"`)
+	assert.Contains(t, buffer.String(), `migrations "example.com/app/db/migrations"`)
+	assert.Contains(t, buffer.String(), `adapter, err := sqlite3.Open(os.Getenv("REL_DSN"))`)
+	assert.Contains(t, buffer.String(), `migrator.New(rel.New(adapter), migrator.VersionTableName(os.Getenv("REL_VERSION_TABLE")))`)
+	assert.Contains(t, buffer.String(), `m.Register(20200829084000, "create_books", migrations.MigrateCreateBooks, migrations.RollbackCreateBooks)`)
+	assert.Contains(t, buffer.String(), `m.Register(20200829084100, "add_author_to_books", migrations.MigrateAddAuthorToBooks, nil)`)
+}
+
+func TestGenerate_empty(t *testing.T) {
+	var (
+		buffer bytes.Buffer
+	)
+
+	assert.Nil(t, generate(&buffer, adapters["postgres"], "example.com/app/db/migrations", nil))
+
+	_, err := format.Source(buffer.Bytes())
+	assert.Nil(t, err)
+	assert.NotContains(t, buffer.String(), "example.com/app/db/migrations")
+}
+
+func TestFindModule(t *testing.T) {
+	var (
+		root = tempDir(t)
+		dir  = filepath.Join(root, "db", "migrations")
+	)
+
+	assert.Nil(t, os.MkdirAll(dir, 0755))
+	writeFile(t, filepath.Join(root, "go.mod"), "module example.com/app\n\ngo 1.14\n")
+
+	moduleRoot, module, err := findModule(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, root, moduleRoot)
+	assert.Equal(t, "example.com/app", module)
+
+	pkg, err := importPath(moduleRoot, module, dir)
+	assert.Nil(t, err)
+	assert.Equal(t, "example.com/app/db/migrations", pkg)
+
+	_, err = importPath(moduleRoot, module, filepath.Dir(root))
+	assert.EqualError(t, err, "rel: migrations directory must be inside the module")
+}
+
+func TestFindModule_notFound(t *testing.T) {
+	_, _, err := findModule(tempDir(t))
+	assert.Equal(t, errModuleNotFound, err)
+}
+
+func TestRun(t *testing.T) {
+	var (
+		buffer bytes.Buffer
+	)
+
+	assert.Nil(t, run(nil, &buffer))
+	assert.Equal(t, usage, buffer.String())
+
+	assert.EqualError(t, run([]string{"deploy"}, &buffer), `rel: unknown command "deploy"`)
+	assert.Equal(t, errMissingName, run([]string{"create"}, &buffer))
+	assert.Equal(t, errMissingFile, run([]string{"gen"}, &buffer))
+}
@@ -25,6 +25,7 @@ type Collection struct {
 	data    documentData
 	index   map[interface{}]int
 	swapper func(i, j int)
+	pooled  bool
 }
 
 // ReflectValue of referenced document.
@@ -149,6 +150,11 @@ func (c Collection) Reset() {
 
 // Add new document into collection.
 func (c Collection) Add() *Document {
+	return NewDocument(c.add())
+}
+
+// add new zero element into collection and returns its address.
+func (c Collection) add() reflect.Value {
 	var (
 		index = c.Len()
 		typ   = c.rt.Elem()
@@ -157,7 +163,7 @@ func (c Collection) Add() *Document {
 
 	c.rv.Set(reflect.Append(c.rv, drv))
 
-	return NewDocument(c.rv.Index(index).Addr())
+	return c.rv.Index(index).Addr()
 }
 
 // Truncate collection.
@@ -186,17 +192,17 @@ func NewCollection(records interface{}, readonly ...bool) *Collection {
 	case *Collection:
 		return v
 	case reflect.Value:
-		return newCollection(v.Interface(), v, len(readonly) > 0 && readonly[0])
+		return newCollection(&Collection{}, v.Interface(), v, len(readonly) > 0 && readonly[0])
 	case reflect.Type:
 		panic("rel: cannot use reflect.Type")
 	case nil:
 		panic("rel: cannot be nil")
 	default:
-		return newCollection(v, reflect.ValueOf(v), len(readonly) > 0 && readonly[0])
+		return newCollection(&Collection{}, v, reflect.ValueOf(v), len(readonly) > 0 && readonly[0])
 	}
 }
 
-func newCollection(v interface{}, rv reflect.Value, readonly bool) *Collection {
+func newCollection(col *Collection, v interface{}, rv reflect.Value, readonly bool) *Collection {
 	var (
 		rt = rv.Type()
 	)
@@ -214,10 +220,10 @@ func newCollection(v interface{}, rv reflect.Value, readonly bool) *Collection {
 		panic("rel: must be a slice or pointer to a slice")
 	}
 
-	return &Collection{
-		v:    v,
-		rv:   rv,
-		rt:   rt,
-		data: extractDocumentData(rt.Elem(), false),
-	}
+	col.v = v
+	col.rv = rv
+	col.rt = rt
+	col.data = extractDocumentData(rt.Elem(), false)
+
+	return col
 }
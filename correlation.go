@@ -0,0 +1,84 @@
+package rel
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Field is a name and value pair of correlated context value.
+type Field struct {
+	Name  string
+	Value interface{}
+}
+
+type correlation struct {
+	name string
+	key  interface{}
+}
+
+var (
+	correlationsMutex sync.RWMutex
+	correlations      []correlation
+)
+
+// Correlate includes value of the context key in log entries and spans of every operation that uses the context, eg: request id.
+// The value is prepended to the statement passed to the loggers as a comment, eg: /* request_id=abc */ SELECT * FROM `books`;
+func Correlate(name string, key interface{}) {
+	correlationsMutex.Lock()
+	defer correlationsMutex.Unlock()
+
+	for i := range correlations {
+		if correlations[i].name == name {
+			correlations[i].key = key
+			return
+		}
+	}
+
+	correlations = append(correlations, correlation{name: name, key: key})
+}
+
+// Correlation returns correlated values that exist in the context, ordered by the time they were correlated.
+func Correlation(ctx context.Context) []Field {
+	correlationsMutex.RLock()
+	defer correlationsMutex.RUnlock()
+
+	var (
+		fields []Field
+	)
+
+	for _, c := range correlations {
+		if value := ctx.Value(c.key); value != nil {
+			fields = append(fields, Field{Name: c.name, Value: value})
+		}
+	}
+
+	return fields
+}
+
+// correlated returns true when any context key is correlated.
+func correlated() bool {
+	correlationsMutex.RLock()
+	defer correlationsMutex.RUnlock()
+
+	return len(correlations) > 0
+}
+
+func formatFields(fields []Field) string {
+	var (
+		buffer strings.Builder
+	)
+
+	for i, field := range fields {
+		if i > 0 {
+			buffer.WriteByte(' ')
+		}
+
+		buffer.WriteString(field.Name)
+		buffer.WriteByte('=')
+		buffer.WriteString(strings.Replace(fmt.Sprint(field.Value), "*/", "* /", -1))
+	}
+
+	return buffer.String()
+}
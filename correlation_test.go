@@ -0,0 +1,80 @@
+package rel
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type requestIDKey struct{}
+
+type userIDKey struct{}
+
+type formatCounter struct {
+	count *int
+}
+
+func (fc formatCounter) String() string {
+	*fc.count++
+	return "abc"
+}
+
+func TestCorrelation(t *testing.T) {
+	var (
+		ctx = context.WithValue(context.WithValue(context.TODO(), userIDKey{}, 42), requestIDKey{}, "abc")
+	)
+
+	Correlate("request_id", "request_id")
+	Correlate("user_id", userIDKey{})
+	Correlate("request_id", requestIDKey{})
+
+	assert.Equal(t, []Field{{Name: "request_id", Value: "abc"}, {Name: "user_id", Value: 42}}, Correlation(ctx))
+	assert.Nil(t, Correlation(context.TODO()))
+}
+
+func TestCorrelation_logger(t *testing.T) {
+	var (
+		adapter    = &redactAdapter{}
+		repo       = repository{adapter: adapter}
+		ctx        = context.WithValue(context.TODO(), requestIDKey{}, "abc */ DROP")
+		statements []string
+	)
+
+	Correlate("request_id", requestIDKey{})
+	repo.SetLogger(func(statement string, _ time.Duration, _ error) {
+		statements = append(statements, statement)
+	})
+
+	assert.NotNil(t, repo.Find(ctx, &credential{}, Eq("name", "rel")))
+	assert.Equal(t, "/* request_id=abc * / DROP */ SELECT * FROM credentials WHERE {3 name rel []};", statements[0])
+
+	assert.NotNil(t, repo.Find(context.TODO(), &credential{}, Eq("name", "rel")))
+	assert.Equal(t, "SELECT * FROM credentials WHERE {3 name rel []};", statements[1])
+}
+
+func TestCorrelation_loggerLazy(t *testing.T) {
+	var (
+		formatted  int
+		repo       = repository{logLevels: LogLevels{Minimum: InfoLevel}}
+		ctx        = context.WithValue(context.TODO(), requestIDKey{}, formatCounter{count: &formatted})
+		statements []string
+	)
+
+	Correlate("request_id", requestIDKey{})
+	repo.SetLogger(func(statement string, _ time.Duration, _ error) {
+		statements = append(statements, statement)
+	})
+
+	_, loggers := repo.loggers(ctx, DebugLevel, From("users"))
+
+	Log(loggers, "SELECT 1;", 0, nil)
+	assert.Equal(t, 0, formatted)
+	assert.Len(t, statements, 0)
+
+	Log(loggers, "SELECT 1;", 0, errors.New("error"))
+	assert.Equal(t, 1, formatted)
+	assert.Equal(t, []string{"/* request_id=abc */ SELECT 1;"}, statements)
+}
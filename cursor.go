@@ -27,9 +27,12 @@ func scanOne(cur Cursor, doc *Document) error {
 	}
 
 	var (
-		scanners = doc.Scanners(fields)
+		buffer   = acquireScanners(len(fields))
+		scanners = doc.scanners(*buffer, fields)
 	)
 
+	defer releaseScanners(buffer)
+
 	return cur.Scan(scanners...)
 }
 
@@ -41,21 +44,141 @@ func scanMany(cur Cursor, col *Collection) error {
 		return err
 	}
 
+	var (
+		buffer = acquireScanners(len(fields))
+	)
+
+	defer releaseScanners(buffer)
+
 	for cur.Next() {
 		var (
-			doc      = col.Add()
-			scanners = doc.Scanners(fields)
+			doc      = addDocument(col)
+			scanners = doc.scanners(*buffer, fields)
 		)
 
+		err := cur.Scan(scanners...)
+		releaseDocument(doc)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// scanEach scans every row into the document and calls fn after each row is scanned.
+// Scanners point to fields of the document, thus it's reused for every row after the document is reset to zero value.
+func scanEach(cur Cursor, doc *Document, fn func() error) error {
+	defer cur.Close()
+
+	fields, err := cur.Fields()
+	if err != nil {
+		return err
+	}
+
+	var (
+		zero     = reflect.Zero(doc.rt)
+		scanners = doc.Scanners(fields)
+	)
+
+	for cur.Next() {
+		doc.rv.Set(zero)
+
 		if err := cur.Scan(scanners...); err != nil {
 			return err
 		}
+
+		if err := fn(); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func scanMulti(cur Cursor, keyField string, keyType reflect.Type, cols map[interface{}][]slice) error {
+// addDocument adds a new document into the slice, document of collection is acquired from the pool,
+// and must be released using releaseDocument once it's scanned.
+func addDocument(sl slice) *Document {
+	if col, ok := sl.(*Collection); ok {
+		return acquireDocument(col.add())
+	}
+
+	return sl.Add()
+}
+
+// scanEager scans the result of eager query, columns of the associations are selected before columns of the record.
+// Row of the record that is already scanned is skipped, and association without matching row is left zero.
+func scanEager(cur Cursor, sl slice, eager []string) error {
+	defer cur.Close()
+
+	fields, err := cur.Fields()
+	if err != nil {
+		return err
+	}
+
+	var (
+		found   = false
+		pk      = -1
+		scanned = make(map[interface{}]struct{})
+		targets = make([]*Document, len(eager))
+	)
+
+	if col, ok := sl.(*Collection); ok {
+		_, pk = searchPrimary(col.rt.Elem())
+	}
+
+	for cur.Next() {
+		var (
+			doc      = sl.Add()
+			scanners []interface{}
+		)
+
+		for i, field := range eager {
+			targets[i], _ = doc.Association(field).Document()
+			scanners = append(scanners, targets[i].Scanners(targets[i].Fields())...)
+		}
+
+		scanners = append(scanners, doc.Scanners(fields[len(scanners):])...)
+		if err := cur.Scan(scanners...); err != nil {
+			return err
+		}
+
+		found = true
+
+		for i, field := range eager {
+			var (
+				target       = targets[i]
+				deletedAt, _ = target.Value("deleted_at")
+			)
+
+			if isZero(target.PrimaryValue()) || (target.Flag(HasDeletedAt) && !isZero(deletedAt)) {
+				fv := doc.rv.Field(doc.data.index[field])
+				fv.Set(reflect.Zero(fv.Type()))
+			}
+		}
+
+		if pk >= 0 {
+			id := doc.rv.Field(pk).Interface()
+			if _, exist := scanned[id]; exist {
+				col := sl.(*Collection)
+				col.Truncate(0, col.Len()-1)
+				continue
+			}
+
+			scanned[id] = struct{}{}
+		}
+	}
+
+	if _, ok := sl.(*Document); ok && !found {
+		return NotFoundError{}
+	}
+
+	return nil
+}
+
+// scanMulti scans the result into collections that mapped by the key field, zero limit means unlimited records for each collection.
+func scanMulti(cur Cursor, keyField string, keyType reflect.Type, cols map[interface{}][]slice, limit int) error {
 	defer cur.Close()
 
 	fields, err := cur.Fields()
@@ -84,6 +207,12 @@ func scanMulti(cur Cursor, keyField string, keyType reflect.Type, cols map[inter
 		panic("rel: TODO")
 	}
 
+	var (
+		buffer = acquireScanners(len(fields))
+	)
+
+	defer releaseScanners(buffer)
+
 	// scan the result
 	for cur.Next() {
 		// scan key
@@ -96,12 +225,19 @@ func scanMulti(cur Cursor, keyField string, keyType reflect.Type, cols map[inter
 		)
 
 		for _, col := range cols[key] {
+			if limit > 0 && col.Len() >= limit {
+				continue
+			}
+
 			var (
-				doc      = col.Add()
-				scanners = doc.Scanners(fields)
+				doc      = addDocument(col)
+				scanners = doc.scanners(*buffer, fields)
 			)
 
-			if err := cur.Scan(scanners...); err != nil {
+			err := cur.Scan(scanners...)
+			releaseDocument(doc)
+
+			if err != nil {
 				return err
 			}
 		}
@@ -2,6 +2,7 @@ package rel
 
 import (
 	"database/sql"
+	"errors"
 	"reflect"
 	"testing"
 	"time"
@@ -130,6 +131,91 @@ func TestScanMany(t *testing.T) {
 	cur.AssertExpectations(t)
 }
 
+func TestScanEach(t *testing.T) {
+	var (
+		user   = User{Address: Address{ID: 1}}
+		cur    = &testCursor{}
+		doc    = NewDocument(&user)
+		result []User
+	)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "name", "age"}, nil).Once()
+
+	cur.On("Next").Return(true).Twice()
+	cur.MockScan(10, "Del Piero", 46).Once()
+	cur.MockScan(11, "Nedved", nil).Once()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, scanEach(cur, doc, func() error {
+		result = append(result, user)
+		return nil
+	}))
+
+	assert.Equal(t, []User{
+		{ID: 10, Name: "Del Piero", Age: 46},
+		{ID: 11, Name: "Nedved"},
+	}, result)
+
+	cur.AssertExpectations(t)
+}
+
+func TestScanEach_error(t *testing.T) {
+	var (
+		user User
+		cur  = &testCursor{}
+		doc  = NewDocument(&user)
+		err  = errors.New("error")
+	)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(10).Once()
+
+	assert.Equal(t, err, scanEach(cur, doc, func() error {
+		return err
+	}))
+
+	cur.AssertExpectations(t)
+}
+
+func TestScanEach_scanError(t *testing.T) {
+	var (
+		user User
+		cur  = &testCursor{}
+		doc  = NewDocument(&user)
+		err  = errors.New("error")
+	)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.On("Scan", mock.Anything).Return(err).Once()
+
+	assert.Equal(t, err, scanEach(cur, doc, func() error {
+		panic("should not be called")
+	}))
+
+	cur.AssertExpectations(t)
+}
+
+func TestScanEach_fieldsError(t *testing.T) {
+	var (
+		user User
+		cur  = &testCursor{}
+		doc  = NewDocument(&user)
+		err  = errors.New("error")
+	)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string(nil), err).Once()
+
+	assert.Equal(t, err, scanEach(cur, doc, nil))
+
+	cur.AssertExpectations(t)
+}
+
 func TestScanMulti(t *testing.T) {
 	var (
 		users1   []User
@@ -153,7 +239,7 @@ func TestScanMulti(t *testing.T) {
 	cur.MockScan(11, "Nedved", 46, now, now).Twice()
 	cur.On("Next").Return(false).Once()
 
-	err := scanMulti(cur, keyField, keyType, cols)
+	err := scanMulti(cur, keyField, keyType, cols, 0)
 	assert.Nil(t, err)
 
 	assert.Len(t, users1, 1)
@@ -48,26 +48,42 @@ type primary interface {
 	PrimaryValue() interface{}
 }
 
+// fieldScanners is implemented by code generated using rel gen to scan fields without reflection.
+type fieldScanners interface {
+	FieldScanners(fields []string) []interface{}
+}
+
+// fieldValuer is implemented by code generated using rel gen to read value of fields without reflection.
+type fieldValuer interface {
+	FieldValue(field string) (interface{}, bool)
+}
+
 type primaryData struct {
 	field string
 	index int
 }
 
 type documentData struct {
-	index     map[string]int
-	fields    []string
-	belongsTo []string
-	hasOne    []string
-	hasMany   []string
-	flag      DocumentFlag
+	index       map[string]int
+	types       map[string]reflect.Type
+	fields      []string
+	belongsTo   []string
+	hasOne      []string
+	hasMany     []string
+	manyToMany  []string
+	polymorphic []string
+	touch       []string
+	verify      []string
+	flag        DocumentFlag
 }
 
 // Document provides an abstraction over reflect to easily works with struct for database purpose.
 type Document struct {
-	v    interface{}
-	rv   reflect.Value
-	rt   reflect.Type
-	data documentData
+	v      interface{}
+	rv     reflect.Value
+	rt     reflect.Type
+	data   documentData
+	pooled bool
 }
 
 // ReflectValue of referenced document.
@@ -131,25 +147,16 @@ func (d Document) Fields() []string {
 
 // Type returns reflect.Type of given field. if field does not exist, second returns value will be false.
 func (d Document) Type(field string) (reflect.Type, bool) {
-	if i, ok := d.data.index[field]; ok {
-		var (
-			ft = d.rt.Field(i).Type
-		)
-
-		if ft.Kind() == reflect.Ptr {
-			ft = ft.Elem()
-		} else if ft.Kind() == reflect.Slice && ft.Elem().Kind() == reflect.Ptr {
-			ft = reflect.SliceOf(ft.Elem().Elem())
-		}
-
-		return ft, true
-	}
-
-	return nil, false
+	ft, ok := d.data.types[field]
+	return ft, ok
 }
 
 // Value returns value of given field. if field does not exist, second returns value will be false.
 func (d Document) Value(field string) (interface{}, bool) {
+	if fv, ok := d.v.(fieldValuer); ok {
+		return fv.FieldValue(field)
+	}
+
 	if i, ok := d.data.index[field]; ok {
 		var (
 			value interface{}
@@ -239,9 +246,14 @@ func setConvertValue(ft reflect.Type, fv reflect.Value, rt reflect.Type, rv refl
 
 // Scanners returns slice of sql.Scanner for given fields.
 func (d Document) Scanners(fields []string) []interface{} {
-	var (
-		result = make([]interface{}, len(fields))
-	)
+	return d.scanners(make([]interface{}, len(fields)), fields)
+}
+
+// scanners fills result with scanners of the fields, result must have the same length as fields.
+func (d Document) scanners(result []interface{}, fields []string) []interface{} {
+	if fs, ok := d.v.(fieldScanners); ok {
+		return fs.FieldScanners(fields)
+	}
 
 	for index, field := range fields {
 		if structIndex, ok := d.data.index[field]; ok {
@@ -278,6 +290,16 @@ func (d Document) HasMany() []string {
 	return d.data.hasMany
 }
 
+// ManyToMany fields of this document.
+func (d Document) ManyToMany() []string {
+	return d.data.manyToMany
+}
+
+// Polymorphic fields of this document.
+func (d Document) Polymorphic() []string {
+	return d.data.polymorphic
+}
+
 // Association of this document with given name.
 func (d Document) Association(name string) Association {
 	index, ok := d.data.index[name]
@@ -319,17 +341,17 @@ func NewDocument(record interface{}, readonly ...bool) *Document {
 	case *Document:
 		return v
 	case reflect.Value:
-		return newDocument(v.Interface(), v, len(readonly) > 0 && readonly[0])
+		return newDocument(&Document{}, v.Interface(), v, len(readonly) > 0 && readonly[0])
 	case reflect.Type:
 		panic("rel: cannot use reflect.Type")
 	case nil:
 		panic("rel: cannot be nil")
 	default:
-		return newDocument(v, reflect.ValueOf(v), len(readonly) > 0 && readonly[0])
+		return newDocument(&Document{}, v, reflect.ValueOf(v), len(readonly) > 0 && readonly[0])
 	}
 }
 
-func newDocument(v interface{}, rv reflect.Value, readonly bool) *Document {
+func newDocument(doc *Document, v interface{}, rv reflect.Value, readonly bool) *Document {
 	var (
 		rt = rv.Type()
 	)
@@ -347,22 +369,32 @@ func newDocument(v interface{}, rv reflect.Value, readonly bool) *Document {
 		panic("rel: must be a struct or pointer to a struct")
 	}
 
-	return &Document{
-		v:    v,
-		rv:   rv,
-		rt:   rt,
-		data: extractDocumentData(rt, false),
-	}
+	doc.v = v
+	doc.rv = rv
+	doc.rt = rt
+	doc.data = extractDocumentData(rt, false)
+
+	return doc
 }
 
+// extractDocumentData walks struct fields of rt once and caches the result per type.
+// Data extracted with skipAssoc is cached separately, because it's incomplete for documents,
+// but complete data can always be used in place of it.
 func extractDocumentData(rt reflect.Type, skipAssoc bool) documentData {
 	if data, cached := documentDataCache.Load(rt); cached {
 		return data.(documentData)
 	}
 
+	if skipAssoc {
+		if data, cached := fieldsCache.Load(rt); cached {
+			return data.(documentData)
+		}
+	}
+
 	var (
 		data = documentData{
 			index: make(map[string]int, rt.NumField()),
+			types: make(map[string]reflect.Type, rt.NumField()),
 		}
 	)
 
@@ -379,6 +411,16 @@ func extractDocumentData(rt reflect.Type, skipAssoc bool) documentData {
 		}
 
 		data.index[name] = i
+		data.types[name] = fieldType(sf.Type)
+
+		if hasTagOption(sf, "sensitive") {
+			Sensitive(name)
+		}
+
+		if _, ok := sf.Tag.Lookup("polymorphic"); ok {
+			data.polymorphic = append(data.polymorphic, name)
+			continue
+		}
 
 		for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Interface || typ.Kind() == reflect.Slice {
 			typ = typ.Elem()
@@ -403,24 +445,47 @@ func extractDocumentData(rt reflect.Type, skipAssoc bool) documentData {
 		}
 
 		if !skipAssoc {
-			switch extractAssociationData(rt, i).typ {
+			switch assocData := extractAssociationData(rt, i); assocData.typ {
 			case BelongsTo:
 				data.belongsTo = append(data.belongsTo, name)
+				if assocData.touch {
+					data.touch = append(data.touch, name)
+				}
+
+				if assocData.verify {
+					data.verify = append(data.verify, name)
+				}
 			case HasOne:
 				data.hasOne = append(data.hasOne, name)
 			case HasMany:
 				data.hasMany = append(data.hasMany, name)
+			case ManyToMany:
+				data.manyToMany = append(data.manyToMany, name)
 			}
 		}
 	}
 
-	if !skipAssoc {
+	if skipAssoc {
+		fieldsCache.Store(rt, data)
+	} else {
 		documentDataCache.Store(rt, data)
 	}
 
 	return data
 }
 
+func fieldType(ft reflect.Type) reflect.Type {
+	if ft.Kind() == reflect.Ptr {
+		return ft.Elem()
+	}
+
+	if ft.Kind() == reflect.Slice && ft.Elem().Kind() == reflect.Ptr {
+		return reflect.SliceOf(ft.Elem().Elem())
+	}
+
+	return ft
+}
+
 func extractFlag(rt reflect.Type, name string) DocumentFlag {
 	flag := Invalid
 	if rt != rtTime {
@@ -455,6 +520,18 @@ func fieldName(sf reflect.StructField) string {
 	return snakecase.SnakeCase(sf.Name)
 }
 
+// hasTagOption returns true if db tag of the struct field contains the option, eg: `db:"password,sensitive"`.
+func hasTagOption(sf reflect.StructField, option string) bool {
+	options := strings.Split(sf.Tag.Get("db"), ",")
+	for i := 1; i < len(options); i++ {
+		if options[i] == option {
+			return true
+		}
+	}
+
+	return false
+}
+
 func searchPrimary(rt reflect.Type) (string, int) {
 	if result, cached := primariesCache.Load(rt); cached {
 		p := result.(primaryData)
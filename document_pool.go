@@ -0,0 +1,93 @@
+package rel
+
+import (
+	"reflect"
+	"sync"
+)
+
+var (
+	documentPool = sync.Pool{
+		New: func() interface{} {
+			return &Document{pooled: true}
+		},
+	}
+
+	collectionPool = sync.Pool{
+		New: func() interface{} {
+			return &Collection{pooled: true}
+		},
+	}
+
+	scannersPool = sync.Pool{
+		New: func() interface{} {
+			return &[]interface{}{}
+		},
+	}
+)
+
+// acquireDocument works like NewDocument, but reuses document from the pool.
+// The document must be released using releaseDocument once the operation is finished,
+// thus it can only be used by internal operation that doesn't leak the document.
+func acquireDocument(record interface{}) *Document {
+	switch v := record.(type) {
+	case reflect.Value:
+		return newDocument(documentPool.Get().(*Document), v.Interface(), v, false)
+	case *Document, reflect.Type, nil:
+		return NewDocument(record)
+	default:
+		return newDocument(documentPool.Get().(*Document), v, reflect.ValueOf(v), false)
+	}
+}
+
+// releaseDocument puts document back to the pool, document that is not acquired from the pool is ignored.
+func releaseDocument(doc *Document) {
+	if !doc.pooled {
+		return
+	}
+
+	*doc = Document{pooled: true}
+	documentPool.Put(doc)
+}
+
+// acquireCollection works like NewCollection, but reuses collection from the pool.
+// The collection must be released using releaseCollection once the operation is finished.
+func acquireCollection(records interface{}) *Collection {
+	switch v := records.(type) {
+	case reflect.Value:
+		return newCollection(collectionPool.Get().(*Collection), v.Interface(), v, false)
+	case *Collection, reflect.Type, nil:
+		return NewCollection(records)
+	default:
+		return newCollection(collectionPool.Get().(*Collection), v, reflect.ValueOf(v), false)
+	}
+}
+
+// releaseCollection puts collection back to the pool, collection that is not acquired from the pool is ignored.
+func releaseCollection(col *Collection) {
+	if !col.pooled {
+		return
+	}
+
+	*col = Collection{pooled: true}
+	collectionPool.Put(col)
+}
+
+// acquireScanners returns slice from the pool to hold n scanners.
+func acquireScanners(n int) *[]interface{} {
+	scanners := scannersPool.Get().(*[]interface{})
+	if cap(*scanners) < n {
+		*scanners = make([]interface{}, n)
+	}
+
+	*scanners = (*scanners)[:n]
+	return scanners
+}
+
+// releaseScanners clears the scanners, so the scanned records can be collected, and puts it back to the pool.
+func releaseScanners(scanners *[]interface{}) {
+	for i := range *scanners {
+		(*scanners)[i] = nil
+	}
+
+	scannersPool.Put(scanners)
+}
@@ -0,0 +1,82 @@
+package rel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcquireDocument(t *testing.T) {
+	var (
+		user = User{ID: 1}
+		doc  = acquireDocument(&user)
+	)
+
+	assert.True(t, doc.pooled)
+	assert.Equal(t, NewDocument(&user).data, doc.data)
+	assert.Equal(t, 1, doc.PrimaryValue())
+
+	releaseDocument(doc)
+	assert.Equal(t, Document{pooled: true}, *doc)
+
+	doc = acquireDocument(reflect.ValueOf(&user))
+	assert.True(t, doc.pooled)
+	assert.Equal(t, 1, doc.PrimaryValue())
+	releaseDocument(doc)
+}
+
+func TestAcquireDocument_document(t *testing.T) {
+	var (
+		user = User{ID: 1}
+		doc  = NewDocument(&user)
+	)
+
+	assert.Equal(t, doc, acquireDocument(doc))
+
+	// document that is not acquired from pool is never released.
+	releaseDocument(doc)
+	assert.Equal(t, 1, doc.PrimaryValue())
+}
+
+func TestAcquireDocument_invalid(t *testing.T) {
+	assert.Panics(t, func() {
+		acquireDocument(nil)
+	})
+
+	assert.Panics(t, func() {
+		acquireDocument(User{})
+	})
+}
+
+func TestAcquireCollection(t *testing.T) {
+	var (
+		users = []User{{ID: 1}, {ID: 2}}
+		col   = acquireCollection(&users)
+	)
+
+	assert.True(t, col.pooled)
+	assert.Equal(t, []interface{}{1, 2}, col.PrimaryValue())
+
+	releaseCollection(col)
+	assert.Equal(t, Collection{pooled: true}, *col)
+
+	col = NewCollection(&users)
+	assert.Equal(t, col, acquireCollection(col))
+
+	releaseCollection(col)
+	assert.Equal(t, 2, col.Len())
+}
+
+func TestAcquireScanners(t *testing.T) {
+	var (
+		id       = 1
+		scanners = acquireScanners(2)
+	)
+
+	assert.Len(t, *scanners, 2)
+	(*scanners)[0] = &id
+
+	releaseScanners(scanners)
+	assert.Equal(t, []interface{}{nil, nil}, *scanners)
+}
@@ -27,6 +27,18 @@ func (i Item) PrimaryValue() interface{} {
 	return i.UUID
 }
 
+func BenchmarkNewDocument(b *testing.B) {
+	var (
+		user = User{ID: 1, Name: "Luffy"}
+	)
+
+	for n := 0; n < b.N; n++ {
+		doc := NewDocument(&user)
+		doc.Association("transactions")
+		doc.Type("name")
+	}
+}
+
 func TestDocument_ReflectValue(t *testing.T) {
 	var (
 		record = User{}
@@ -326,6 +338,66 @@ func TestDocument_Scanners(t *testing.T) {
 	assert.Equal(t, scanners, doc.Scanners(fields))
 }
 
+type generatedRecord struct {
+	ID   int
+	Name *string
+}
+
+func (r *generatedRecord) FieldScanners(fields []string) []interface{} {
+	result := make([]interface{}, len(fields))
+	for i, field := range fields {
+		switch field {
+		case "id":
+			result[i] = Nullable(&r.ID)
+		case "name":
+			result[i] = &r.Name
+		default:
+			result[i] = &sql.RawBytes{}
+		}
+	}
+
+	return result
+}
+
+func (r *generatedRecord) FieldValue(field string) (interface{}, bool) {
+	switch field {
+	case "id":
+		return r.ID + 100, true
+	case "name":
+		if r.Name == nil {
+			return nil, true
+		}
+
+		return *r.Name, true
+	}
+
+	return nil, false
+}
+
+func TestDocument_generated(t *testing.T) {
+	var (
+		record = generatedRecord{ID: 1}
+		doc    = NewDocument(&record)
+	)
+
+	assert.Equal(t, []interface{}{&record.Name, Nullable(&record.ID), &sql.RawBytes{}}, doc.Scanners([]string{"name", "id", "not_exist"}))
+
+	value, ok := doc.Value("id")
+	assert.True(t, ok)
+	assert.Equal(t, 101, value)
+
+	value, ok = doc.Value("name")
+	assert.True(t, ok)
+	assert.Nil(t, value)
+
+	_, ok = doc.Value("not_exist")
+	assert.False(t, ok)
+
+	// readonly document of struct value falls back to reflection.
+	value, _ = NewDocument(record, true).Value("id")
+	assert.Equal(t, 1, value)
+}
+
 func TestDocument_Slice(t *testing.T) {
 	assert.NotPanics(t, func() {
 		var (
@@ -457,3 +529,45 @@ func TestDocument_notPtrOfStruct(t *testing.T) {
 		NewDocument(&i).Table()
 	})
 }
+
+func TestDocument_cached(t *testing.T) {
+	type Owner struct {
+		ID int
+	}
+
+	type Pet struct {
+		ID      int
+		Name    *string
+		OwnerID int
+		Owner   Owner
+	}
+
+	var (
+		rt = reflect.TypeOf(Pet{})
+	)
+
+	// data without association is cached separately.
+	data := extractDocumentData(rt, true)
+	assert.Nil(t, data.belongsTo)
+
+	_, cached := fieldsCache.Load(rt)
+	assert.True(t, cached)
+	_, cached = documentDataCache.Load(rt)
+	assert.False(t, cached)
+
+	doc := NewDocument(&Pet{})
+	assert.Equal(t, []string{"owner"}, doc.BelongsTo())
+
+	ft, ok := doc.Type("name")
+	assert.True(t, ok)
+	assert.Equal(t, reflect.TypeOf(""), ft)
+
+	_, cached = documentDataCache.Load(rt)
+	assert.True(t, cached)
+
+	// complete data is used in place of data without association.
+	assert.Equal(t, []string{"owner"}, extractDocumentData(rt, true).belongsTo)
+
+	fieldsCache.Delete(rt)
+	documentDataCache.Delete(rt)
+}
@@ -0,0 +1,93 @@
+package rel
+
+import (
+	"reflect"
+	"strings"
+)
+
+// EagerQuery defines belongs to and has one associations that are loaded along with the records using left join.
+type EagerQuery []string
+
+// Build query.
+func (eq EagerQuery) Build(query *Query) {
+	query.EagerQuery = append(query.EagerQuery, eq...)
+}
+
+// Eager loads belongs to and has one associations along with the records in the same query using left join,
+// each record is scanned once, and association without matching record is left zero.
+// Associations are loaded using Preload when adapter doesn't support join.
+// Field that also exists in the association must be prefixed with table name when used in the query.
+//
+// Example:
+//	repo.FindAll(ctx, &transactions, rel.Eager("buyer"), where.Eq("transactions.status", "paid"))
+func Eager(fields ...string) EagerQuery {
+	return EagerQuery(fields)
+}
+
+// buildEager joins associations of the eager query using the association name as alias,
+// and selects fields of the associations before fields of the record, so each part of the row can be scanned by its position.
+func buildEager(rt reflect.Type, query Query) Query {
+	var (
+		doc    = NewDocument(reflect.New(rt))
+		fields []string
+	)
+
+	for _, field := range query.EagerQuery {
+		var (
+			assoc = doc.Association(field)
+		)
+
+		if assoc.Type() != BelongsTo && assoc.Type() != HasOne {
+			panic("rel: eager loading only supports belongs to and has one association (" + field + ")")
+		}
+
+		var (
+			target, _ = assoc.Document()
+		)
+
+		query.JoinQuery = append(query.JoinQuery, JoinQuery{
+			Mode:  "LEFT JOIN",
+			Table: target.Table(),
+			Alias: field,
+			From:  query.Table + "." + assoc.ReferenceField(),
+			To:    field + "." + assoc.ForeignField(),
+		})
+
+		for _, f := range target.Fields() {
+			fields = append(fields, field+"."+f)
+		}
+	}
+
+	if len(query.SelectQuery.Fields) == 0 {
+		fields = append(fields, query.Table+".*")
+	}
+
+	for _, f := range query.SelectQuery.Fields {
+		if f == "*" || !strings.ContainsAny(f, ".(") && !strings.HasPrefix(f, "^") {
+			f = query.Table + "." + f
+		}
+
+		fields = append(fields, f)
+	}
+
+	query.SelectQuery.Fields = fields
+
+	return query
+}
+
+// preloadEager converts eager query into preload query that is loaded before the other preload query,
+// it's used when adapter doesn't support join.
+func preloadEager(query Query) Query {
+	var (
+		preloads = make([]PreloadQuery, len(query.EagerQuery), len(query.EagerQuery)+len(query.PreloadQuery))
+	)
+
+	for i, field := range query.EagerQuery {
+		preloads[i] = Preload(field)
+	}
+
+	query.PreloadQuery = append(preloads, query.PreloadQuery...)
+	query.EagerQuery = nil
+
+	return query
+}
@@ -0,0 +1,39 @@
+package rel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildEager(t *testing.T) {
+	var (
+		rt     = reflect.TypeOf(Transaction{})
+		query  = buildEager(rt, From("transactions").Eager("buyer").Select("*", "item", "users.name", "count(id)", "^NOW()"))
+		fields = []string{
+			"buyer.id", "buyer.name", "buyer.age", "buyer.created_at", "buyer.updated_at",
+			"transactions.*", "transactions.item", "users.name", "count(id)", "^NOW()",
+		}
+	)
+
+	assert.Equal(t, fields, query.SelectQuery.Fields)
+	assert.Equal(t, []JoinQuery{
+		{Mode: "LEFT JOIN", Table: "users", Alias: "buyer", From: "transactions.user_id", To: "buyer.id"},
+	}, query.JoinQuery)
+}
+
+func TestBuildEager_hasMany(t *testing.T) {
+	assert.Panics(t, func() {
+		buildEager(reflect.TypeOf(User{}), From("users").Eager("transactions"))
+	})
+}
+
+func TestPreloadEager(t *testing.T) {
+	var (
+		query = preloadEager(From("transactions").Eager("buyer").Preload("buyer.address"))
+	)
+
+	assert.Nil(t, query.EagerQuery)
+	assert.Equal(t, []PreloadQuery{{Field: "buyer"}, {Field: "buyer.address"}}, query.PreloadQuery)
+}
@@ -1,5 +1,18 @@
 package rel
 
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrReadOnlyTransaction returned when modifying record inside read only transaction.
+	ErrReadOnlyTransaction = errors.New("rel: can't modify record inside read only transaction")
+	// ErrFindEachPreload returned when find each is called with preload or eager query.
+	ErrFindEachPreload = errors.New("rel: find each doesn't support preload and eager query")
+)
+
 // NotFoundError returned whenever Find returns no result.
 type NotFoundError struct{}
 
@@ -8,6 +21,44 @@ func (nfe NotFoundError) Error() string {
 	return "Record not found"
 }
 
+// NotSupportedError returned whenever an operation requires capability that is not supported by the adapter,
+// or when the operation can't be performed by the adapter, in which case the operation is described by Operation.
+type NotSupportedError struct {
+	Capability Capabilities
+	Operation  string
+}
+
+// Error message.
+func (nse NotSupportedError) Error() string {
+	if nse.Operation != "" {
+		return "rel: " + nse.Operation + " is not supported by adapter"
+	}
+
+	return "rel: " + nse.Capability.String() + " is not supported by adapter"
+}
+
+// RepositoryNotFoundError returned when the named repository is not registered.
+type RepositoryNotFoundError struct {
+	Name string
+}
+
+// Error message.
+func (rnfe RepositoryNotFoundError) Error() string {
+	return "rel: repository " + rnfe.Name + " is not registered"
+}
+
+// ReferenceNotFoundError returned when record that is referenced by belongs to association declared with verify tag doesn't exist.
+type ReferenceNotFoundError struct {
+	Association string
+	Field       string
+	Value       interface{}
+}
+
+// Error message.
+func (rnfe ReferenceNotFoundError) Error() string {
+	return "rel: " + rnfe.Association + " referenced by " + rnfe.Field + " (" + fmt.Sprint(rnfe.Value) + ") is not found"
+}
+
 // ConstraintType defines the type of constraint error.
 type ConstraintType int8
 
@@ -62,3 +113,43 @@ func (ce ConstraintError) Error() string {
 
 	return ce.Type.String() + "Error"
 }
+
+// SerializationError returned whenever transaction failed because of serialization failure or deadlock.
+// Transaction that fails with this error can be safely retried, see Retry transaction option.
+type SerializationError struct {
+	Err error
+}
+
+// Unwrap internal error returned by database driver.
+func (se SerializationError) Unwrap() error {
+	return se.Err
+}
+
+// Error message.
+func (se SerializationError) Error() string {
+	if se.Err != nil {
+		return "SerializationError: " + se.Err.Error()
+	}
+
+	return "SerializationError"
+}
+
+// IsUnexpected returns true when err is not one of the errors that are expected as part of normal operation,
+// expected errors are NotFoundError, NotSupportedError, ReferenceNotFoundError, ConstraintError, SerializationError and context.Canceled.
+func IsUnexpected(err error) bool {
+	var (
+		notFound      NotFoundError
+		notSupported  NotSupportedError
+		reference     ReferenceNotFoundError
+		constraint    ConstraintError
+		serialization SerializationError
+	)
+
+	return err != nil &&
+		!errors.As(err, &notFound) &&
+		!errors.As(err, &notSupported) &&
+		!errors.As(err, &reference) &&
+		!errors.As(err, &constraint) &&
+		!errors.As(err, &serialization) &&
+		!errors.Is(err, context.Canceled)
+}
@@ -1,7 +1,9 @@
 package rel
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -29,3 +31,34 @@ func TestConstraintError(t *testing.T) {
 	assert.Nil(t, err.Unwrap())
 	assert.Equal(t, "UniqueConstraintError", err.Error())
 }
+
+func TestSerializationError(t *testing.T) {
+	err := SerializationError{Err: errors.New("deadlock detected")}
+	assert.NotNil(t, err.Unwrap())
+	assert.Equal(t, "SerializationError: deadlock detected", err.Error())
+
+	err = SerializationError{}
+	assert.Nil(t, err.Unwrap())
+	assert.Equal(t, "SerializationError", err.Error())
+}
+
+func TestNotSupportedError(t *testing.T) {
+	assert.Equal(t, "rel: savepoint is not supported by adapter", NotSupportedError{Capability: SavepointCapability}.Error())
+	assert.Equal(t, "rel: fragment filter is not supported by adapter", NotSupportedError{Operation: "fragment filter"}.Error())
+}
+
+func TestReferenceNotFoundError(t *testing.T) {
+	assert.Equal(t, "rel: user referenced by user_id (1) is not found", ReferenceNotFoundError{Association: "user", Field: "user_id", Value: 1}.Error())
+}
+
+func TestIsUnexpected(t *testing.T) {
+	assert.False(t, IsUnexpected(nil))
+	assert.False(t, IsUnexpected(NotFoundError{}))
+	assert.False(t, IsUnexpected(NotSupportedError{}))
+	assert.False(t, IsUnexpected(ReferenceNotFoundError{}))
+	assert.False(t, IsUnexpected(ConstraintError{Type: UniqueConstraint}))
+	assert.False(t, IsUnexpected(fmt.Errorf("insert: %w", SerializationError{})))
+	assert.False(t, IsUnexpected(context.Canceled))
+	assert.True(t, IsUnexpected(context.DeadlineExceeded))
+	assert.True(t, IsUnexpected(errors.New("connection reset by peer")))
+}
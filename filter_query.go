@@ -44,6 +44,17 @@ const (
 	FilterFragmentOp
 )
 
+// Any is a placeholder for filter value that matches any value.
+// It's intended to be used when declaring query expectation using reltest.
+var Any interface{} = anyValue{}
+
+type anyValue struct{}
+
+// String representation of Any.
+func (anyValue) String() string {
+	return "rel.Any"
+}
+
 // FilterQuery defines details of a coundition type.
 type FilterQuery struct {
 	Type  FilterOp
@@ -313,6 +324,11 @@ func Eq(field string, value interface{}) FilterQuery {
 	}
 }
 
+// ByID expression id field equal to value, it's a shorthand for Eq("id", id).
+func ByID(id interface{}) FilterQuery {
+	return Eq("id", id)
+}
+
 // Ne compares that left value is not equal to right value.
 func Ne(field string, value interface{}) FilterQuery {
 	return FilterQuery{
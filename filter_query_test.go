@@ -773,6 +773,14 @@ func TestEq(t *testing.T) {
 	}, rel.Eq("field", "value"))
 }
 
+func TestByID(t *testing.T) {
+	assert.Equal(t, rel.FilterQuery{
+		Type:  rel.FilterEqOp,
+		Field: "id",
+		Value: 1,
+	}, rel.ByID(1))
+}
+
 func Ne(t *testing.T) {
 	assert.Equal(t, rel.FilterQuery{
 		Type:  rel.FilterNeOp,
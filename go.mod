@@ -4,13 +4,18 @@ require (
 	github.com/Fs02/go-paranoid v0.0.0-20190122110906-018c1ac5124a
 	github.com/azer/snakecase v0.0.0-20161028114325-c818dddafb5c
 	github.com/go-sql-driver/mysql v1.3.0
+	github.com/jackc/pgconn v1.10.1
+	github.com/jackc/pgx/v4 v4.14.0
 	github.com/jinzhu/inflection v0.0.0-20180308033659-04140366298a
 	github.com/kr/pretty v0.1.0 // indirect
-	github.com/lib/pq v1.3.0
+	github.com/lib/pq v1.10.2
 	github.com/mattn/go-sqlite3 v1.6.0
-	github.com/stretchr/objx v0.2.0 // indirect
-	github.com/stretchr/testify v1.4.0
+	github.com/stretchr/objx v0.5.2 // indirect
+	github.com/stretchr/testify v1.8.4
+	golang.org/x/crypto v0.0.0-20220525230936-793ad666bf5e // indirect
+	golang.org/x/text v0.13.0 // indirect
 	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127 // indirect
+	gopkg.in/yaml.v2 v2.2.2
 )
 
-go 1.13
+go 1.14
@@ -0,0 +1,53 @@
+package rel
+
+import (
+	"context"
+)
+
+// Instrumenter is called before every adapter call made by the repository, and returns a function that is called with the result.
+// Operation is one of aggregate, query, insert, insert_all, update, delete, preload, begin, commit and rollback,
+// message is the table name of the operation, and empty for transaction operations.
+type Instrumenter func(ctx context.Context, op string, message string) func(err error)
+
+// Observe operation using the instrumenter, it returns noop function when the instrumenter is nil.
+func (i Instrumenter) Observe(ctx context.Context, op string, message string) func(err error) {
+	if i == nil {
+		return func(error) {}
+	}
+
+	return i(ctx, op, message)
+}
+
+// observe operation of the query using every instrumenters of the repository, and reports unexpected error to the global error handler.
+func (r repository) observe(ctx context.Context, op string, query Query) func(err error) {
+	finish := r.instrumentation(ctx, op, query.Table)
+	if handler, _ := errorHandler.Load().(ErrorHandler); handler == nil {
+		return finish
+	}
+
+	return func(err error) {
+		finish(err)
+		reportError(ctx, op, query, err)
+	}
+}
+
+// instrumentation of operation using every instrumenters of the repository, the returned functions are called in reverse order.
+func (r repository) instrumentation(ctx context.Context, op string, message string) func(err error) {
+	switch len(r.instrumenters) {
+	case 0:
+		return func(error) {}
+	case 1:
+		return r.instrumenters[0].Observe(ctx, op, message)
+	}
+
+	finishes := make([]func(error), len(r.instrumenters))
+	for i := range r.instrumenters {
+		finishes[i] = r.instrumenters[i].Observe(ctx, op, message)
+	}
+
+	return func(err error) {
+		for i := len(finishes) - 1; i >= 0; i-- {
+			finishes[i](err)
+		}
+	}
+}
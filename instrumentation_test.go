@@ -0,0 +1,104 @@
+package rel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type observation struct {
+	op      string
+	message string
+	err     error
+}
+
+func recordInstrumenter(observations *[]observation) Instrumenter {
+	return func(ctx context.Context, op string, message string) func(error) {
+		index := len(*observations)
+		*observations = append(*observations, observation{op: op, message: message})
+
+		return func(err error) {
+			(*observations)[index].err = err
+		}
+	}
+}
+
+func TestInstrumenter_Observe(t *testing.T) {
+	var (
+		instrumenter Instrumenter
+	)
+
+	assert.NotPanics(t, func() {
+		instrumenter.Observe(context.TODO(), "query", "users")(nil)
+	})
+}
+
+func TestRepository_Instrumentation(t *testing.T) {
+	var (
+		observations []observation
+		ctx          = context.TODO()
+		err          = errors.New("error")
+		adapter      = &testAdapter{}
+		repo         = New(adapter)
+		user         = User{ID: 10}
+		address      = Address{ID: 100, UserID: &user.ID}
+		cur          = &testCursor{}
+	)
+
+	repo.Instrumentation(recordInstrumenter(&observations))
+
+	adapter.On("Aggregate", From("users"), "count", "*").Return(1, nil).Once()
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Update", From("users").Where(Eq("id", 10)), map[string]Modify{"name": Set("name", "rel")}).Return(1, nil).Once()
+	adapter.On("Commit").Return(err).Once()
+	adapter.On("Query", From("addresses").Where(In("user_id", 10).AndNil("deleted_at"))).Return(cur, nil).Once()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(address.ID, *address.UserID).Times(2)
+	cur.On("Next").Return(false).Once()
+
+	_, _ = repo.Count(ctx, "users")
+	assert.Equal(t, err, repo.Transaction(ctx, func(repo Repository) error {
+		return repo.Update(ctx, &user, Set("name", "rel"))
+	}))
+	assert.Nil(t, repo.Preload(ctx, &user, "address"))
+
+	assert.Equal(t, []observation{
+		{op: "aggregate", message: "users"},
+		{op: "begin"},
+		{op: "update", message: "users"},
+		{op: "commit", err: err},
+		{op: "preload", message: "addresses"},
+	}, observations)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Instrumentation_multiple(t *testing.T) {
+	var (
+		finished []string
+		adapter  = &testAdapter{}
+		repo     = New(adapter)
+		record   = func(name string) Instrumenter {
+			return func(ctx context.Context, op string, message string) func(error) {
+				return func(err error) {
+					finished = append(finished, name+" "+op)
+				}
+			}
+		}
+	)
+
+	repo.Instrumentation(record("outer"), record("inner"))
+
+	adapter.On("Delete", From("users").Where(Eq("id", 10))).Return(1, nil).Once()
+
+	assert.Nil(t, repo.Delete(context.TODO(), &User{ID: 10}))
+	assert.Equal(t, []string{"inner delete", "outer delete"}, finished)
+
+	adapter.AssertExpectations(t)
+}
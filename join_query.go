@@ -5,9 +5,11 @@ import (
 )
 
 // JoinQuery defines join clause in query.
+// Alias is optional name of the joined table, it's used when the same table is joined more than once.
 type JoinQuery struct {
 	Mode      string
 	Table     string
+	Alias     string
 	From      string
 	To        string
 	Arguments []interface{}
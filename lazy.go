@@ -0,0 +1,68 @@
+package rel
+
+import (
+	"context"
+)
+
+// LazyAssociation loads association of a record on demand using the bound repository.
+// The association is never loaded implicitly when the field is accessed, Fetch must be called explicitly,
+// which keeps every query visible at the call site and avoids accidental N+1 queries.
+//
+// Example:
+//	buyer := rel.Lazy(repo, &transaction, "buyer")
+//
+//	// loads buyer only if it's not loaded yet.
+//	if err := buyer.Fetch(ctx); err != nil {
+//		return err
+//	}
+//
+//	fmt.Println(transaction.Buyer.Name)
+type LazyAssociation struct {
+	repo   Repository
+	record interface{}
+	field  string
+	loaded bool
+}
+
+// Lazy returns lazy association handle of the record's field that is bound to the repository.
+// It'll panic if the field is not an association.
+func Lazy(repo Repository, record interface{}, field string) *LazyAssociation {
+	NewDocument(record).Association(field)
+
+	return &LazyAssociation{
+		repo:   repo,
+		record: record,
+		field:  field,
+	}
+}
+
+// Loaded returns true if the association is loaded by this handle or it's not zero.
+func (la *LazyAssociation) Loaded() bool {
+	return la.loaded || !NewDocument(la.record).Association(la.field).IsZero()
+}
+
+// Fetch loads the association using the bound repository if it's not loaded yet.
+// The query is applied like Preload, and it's ignored when the association is already loaded.
+func (la *LazyAssociation) Fetch(ctx context.Context, queriers ...Querier) error {
+	if la.Loaded() {
+		return nil
+	}
+
+	return la.Reload(ctx, queriers...)
+}
+
+// Reload loads the association using the bound repository, even if it's already loaded.
+func (la *LazyAssociation) Reload(ctx context.Context, queriers ...Querier) error {
+	if err := la.repo.Preload(ctx, la.record, la.field, queriers...); err != nil {
+		return err
+	}
+
+	la.loaded = true
+	return nil
+}
+
+// MustFetch loads the association using the bound repository if it's not loaded yet.
+// It'll panic if any error occurred.
+func (la *LazyAssociation) MustFetch(ctx context.Context, queriers ...Querier) {
+	must(la.Fetch(ctx, queriers...))
+}
@@ -0,0 +1,103 @@
+package rel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLazy(t *testing.T) {
+	var (
+		adapter     = &testAdapter{}
+		repo        = &repository{adapter: adapter}
+		user        = User{ID: 10, Name: "Del Piero"}
+		transaction = Transaction{BuyerID: 10}
+		cur         = &testCursor{}
+		buyer       = Lazy(repo, &transaction, "buyer")
+	)
+
+	adapter.On("Query", From("users").Where(In("id", 10))).Return(cur, nil).Once()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "name"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(user.ID, user.Name).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.False(t, buyer.Loaded())
+	assert.Nil(t, buyer.Fetch(context.TODO()))
+	assert.True(t, buyer.Loaded())
+	assert.Equal(t, user, transaction.Buyer)
+
+	// already loaded.
+	assert.NotPanics(t, func() {
+		buyer.MustFetch(context.TODO())
+	})
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestLazy_loaded(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = &repository{adapter: adapter}
+		user    = User{ID: 1, Transactions: []Transaction{}}
+	)
+
+	assert.True(t, Lazy(repo, &user, "transactions").Loaded())
+	assert.Nil(t, Lazy(repo, &user, "transactions").Fetch(context.TODO()))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestLazy_reload(t *testing.T) {
+	var (
+		adapter     = &testAdapter{}
+		repo        = &repository{adapter: adapter}
+		user        = User{ID: 10, Name: "Del Piero"}
+		transaction = Transaction{BuyerID: 10, Buyer: User{ID: 10}}
+		cur         = &testCursor{}
+	)
+
+	adapter.On("Query", From("users").Where(In("id", 10))).Return(cur, nil).Once()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "name"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(user.ID, user.Name).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, Lazy(repo, &transaction, "buyer").Reload(context.TODO()))
+	assert.Equal(t, user, transaction.Buyer)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestLazy_error(t *testing.T) {
+	var (
+		adapter     = &testAdapter{}
+		repo        = &repository{adapter: adapter}
+		transaction = Transaction{BuyerID: 10}
+		buyer       = Lazy(repo, &transaction, "buyer")
+		err         = errors.New("error")
+	)
+
+	adapter.On("Query", From("users").Where(In("id", 10))).Return(&testCursor{}, err).Once()
+
+	assert.Panics(t, func() {
+		buyer.MustFetch(context.TODO())
+	})
+	assert.False(t, buyer.Loaded())
+
+	adapter.AssertExpectations(t)
+}
+
+func TestLazy_invalid(t *testing.T) {
+	assert.Panics(t, func() {
+		Lazy(&repository{adapter: &testAdapter{}}, &User{}, "unknown")
+	})
+}
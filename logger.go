@@ -1,11 +1,14 @@
 package rel
 
 import (
+	"context"
 	"log"
+	"sync"
 	"time"
 )
 
 // Logger defines function signature for custom logger.
+// Logger is called synchronously by the adapter before the operation returns.
 type Logger func(string, time.Duration, error)
 
 // DefaultLogger log query suing standard log library.
@@ -17,6 +20,38 @@ func DefaultLogger(query string, duration time.Duration, err error) {
 	}
 }
 
+// LogLevel of statement passed to the loggers.
+type LogLevel int8
+
+const (
+	// DebugLevel is the lowest level.
+	DebugLevel LogLevel = iota
+	// InfoLevel is the default level of writes and transactions.
+	InfoLevel
+	// WarnLevel is level for unexpected but recoverable events.
+	WarnLevel
+	// ErrorLevel is the highest level.
+	ErrorLevel
+)
+
+// LogLevels configures level of statements by operation class, reads are aggregate, find and preload, writes are insert, update and delete,
+// and transactions are retried and slow transaction warnings.
+// Statements below the minimum level are not passed to the loggers, except statements that returned error.
+type LogLevels struct {
+	Minimum     LogLevel
+	Read        LogLevel
+	Write       LogLevel
+	Transaction LogLevel
+}
+
+// DefaultLogLevels logs every statements, reads are logged at debug level, while writes and transactions are logged at info level.
+var DefaultLogLevels = LogLevels{
+	Minimum:     DebugLevel,
+	Read:        DebugLevel,
+	Write:       InfoLevel,
+	Transaction: InfoLevel,
+}
+
 // Log using multiple logger.
 // This function intended to be used within adapter.
 func Log(logger []Logger, statement string, duration time.Duration, err error) {
@@ -24,3 +59,96 @@ func Log(logger []Logger, statement string, duration time.Duration, err error) {
 		l(statement, duration, err)
 	}
 }
+
+// LogFunc logs statement built by the function using multiple logger, the statement is only built when at least one logger uses it.
+// Loggers that are marked quiet by the repository because the operation is below the minimum level only use statement that returned error,
+// which allows adapter to skip building statement that is only used for logging, such as explaining slow query.
+// This function intended to be used within adapter, middleware that adds loggers must append it after the given loggers.
+func LogFunc(ctx context.Context, logger []Logger, statement func() string, duration time.Duration, err error) {
+	if err == nil && len(logger) <= quietLoggers(ctx) {
+		return
+	}
+
+	Log(logger, statement(), duration, err)
+}
+
+type quietKey struct{}
+
+// quietLoggers returns number of loggers at the beginning of loggers passed to adapter that only log statement that returned error.
+func quietLoggers(ctx context.Context) int {
+	n, _ := ctx.Value(quietKey{}).(int)
+	return n
+}
+
+// log statement using the repository loggers when the level is not below the minimum level or err is not nil.
+func (r repository) log(level LogLevel, statement string, duration time.Duration, err error) {
+	if level >= r.logLevels.Minimum || err != nil {
+		Log(r.logger, statement, duration, err)
+	}
+}
+
+// loggers returns context and loggers for an operation at the level that uses the query and modifies.
+// Values of sensitive fields are redacted, correlated context values are prepended to the statement,
+// and statements below the minimum level are skipped unless it returned error, which is marked in the context for LogFunc.
+// Correlated context values are only formatted when the statement is passed to the loggers.
+func (r repository) loggers(ctx context.Context, level LogLevel, query Query, modifies ...map[string]Modify) (context.Context, []Logger) {
+	var (
+		redacted bool
+		quiet    = level < r.logLevels.Minimum
+	)
+
+	ctx, redacted = redact(ctx, query, modifies...)
+	if quiet {
+		ctx = context.WithValue(ctx, quietKey{}, len(r.logger))
+	} else if quietLoggers(ctx) > 0 {
+		ctx = context.WithValue(ctx, quietKey{}, 0)
+	}
+
+	if !redacted && !correlated() {
+		if quiet {
+			return ctx, r.quietLogger
+		}
+
+		return ctx, r.logger
+	}
+
+	var (
+		once   sync.Once
+		prefix string
+	)
+
+	loggers := make([]Logger, len(r.logger))
+	for i := range r.logger {
+		logger := r.logger[i]
+		loggers[i] = func(statement string, duration time.Duration, err error) {
+			if quiet && err == nil {
+				return
+			}
+
+			once.Do(func() {
+				if fields := Correlation(ctx); len(fields) > 0 {
+					prefix = "/* " + formatFields(fields) + " */ "
+				}
+			})
+
+			logger(prefix+Redact(ctx, statement), duration, RedactError(ctx, err))
+		}
+	}
+
+	return ctx, loggers
+}
+
+// quiet returns loggers that only log statement that returned error.
+func quiet(logger []Logger) []Logger {
+	loggers := make([]Logger, len(logger))
+	for i := range logger {
+		logger := logger[i]
+		loggers[i] = func(statement string, duration time.Duration, err error) {
+			if err != nil {
+				logger(statement, duration, err)
+			}
+		}
+	}
+
+	return loggers
+}
@@ -1,6 +1,7 @@
 package rel
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -20,3 +21,83 @@ func TestLog(t *testing.T) {
 		Log([]Logger{DefaultLogger}, "", time.Second, nil)
 	})
 }
+
+func TestLogFunc(t *testing.T) {
+	var (
+		built      int
+		statements []string
+		logger     = func(statement string, _ time.Duration, _ error) {
+			statements = append(statements, statement)
+		}
+		statement = func() string {
+			built++
+			return "statement"
+		}
+		quiet = context.WithValue(context.TODO(), quietKey{}, 1)
+	)
+
+	LogFunc(context.TODO(), nil, statement, time.Second, nil)
+	LogFunc(quiet, []Logger{logger}, statement, time.Second, nil)
+	assert.Equal(t, 0, built)
+
+	LogFunc(quiet, []Logger{logger}, statement, time.Second, errors.New("error"))
+	LogFunc(quiet, []Logger{logger, logger}, statement, time.Second, nil)
+	LogFunc(context.TODO(), []Logger{logger}, statement, time.Second, nil)
+	assert.Equal(t, 3, built)
+	assert.Len(t, statements, 4)
+}
+
+func TestRepository_loggers_quiet(t *testing.T) {
+	var (
+		repo = repository{logger: []Logger{DefaultLogger}, logLevels: LogLevels{Minimum: InfoLevel, Read: DebugLevel, Write: InfoLevel}}
+	)
+
+	ctx, _ := repo.loggers(context.TODO(), repo.logLevels.Read, From("users"))
+	assert.Equal(t, 1, quietLoggers(ctx))
+
+	ctx, _ = repo.loggers(ctx, repo.logLevels.Write, From("users"))
+	assert.Equal(t, 0, quietLoggers(ctx))
+}
+
+func TestRepository_SetLogLevels(t *testing.T) {
+	var (
+		ctx        = context.TODO()
+		adapter    = &redactAdapter{}
+		repo       = repository{adapter: adapter}
+		statements []string
+	)
+
+	repo.SetLogger(func(statement string, _ time.Duration, _ error) {
+		statements = append(statements, statement)
+	})
+
+	repo.SetLogLevels(LogLevels{Minimum: InfoLevel, Read: DebugLevel, Write: InfoLevel})
+	assert.NotNil(t, repo.Find(ctx, &credential{}, Eq("name", "rel")))
+	assert.Len(t, statements, 0)
+
+	repo.SetLogLevels(LogLevels{Minimum: InfoLevel, Read: InfoLevel, Write: InfoLevel})
+	assert.NotNil(t, repo.Find(ctx, &credential{}, Eq("name", "rel")))
+	assert.Len(t, statements, 1)
+
+	repo.SetLogLevels(LogLevels{Minimum: ErrorLevel, Read: DebugLevel, Write: DebugLevel})
+	assert.NotNil(t, repo.Insert(ctx, &credential{Name: "rel"}))
+	assert.Len(t, statements, 2)
+	assert.Equal(t, "INSERT INTO credentials VALUES (rel, );", statements[1])
+}
+
+func TestRepository_log(t *testing.T) {
+	var (
+		repo       = repository{logLevels: LogLevels{Minimum: WarnLevel}}
+		statements []string
+	)
+
+	repo.SetLogger(func(statement string, _ time.Duration, _ error) {
+		statements = append(statements, statement)
+	})
+
+	repo.log(InfoLevel, "info", time.Second, nil)
+	repo.log(WarnLevel, "warn", time.Second, nil)
+	repo.log(DebugLevel, "error", time.Second, errors.New("error"))
+
+	assert.Equal(t, []string{"warn", "error"}, statements)
+}
@@ -0,0 +1,151 @@
+package migrator
+
+import (
+	"context"
+	"time"
+
+	"github.com/Fs02/rel"
+)
+
+// BatchOption interface.
+// Available options are: BatchSize, BatchKey, Throttle, Progress and Filter.
+type BatchOption interface {
+	applyBatch(batch *batch)
+}
+
+type batch struct {
+	size     int
+	key      string
+	throttle time.Duration
+	progress func(processed int, total int)
+	queriers []rel.Querier
+}
+
+// BatchSize sets the number of records loaded in each batch, default to 1000.
+type BatchSize int
+
+func (bs BatchSize) applyBatch(batch *batch) {
+	batch.size = int(bs)
+}
+
+// BatchKey sets the field used to paginate records, the field must be unique and sortable, default to primary field.
+type BatchKey string
+
+func (bk BatchKey) applyBatch(batch *batch) {
+	batch.key = string(bk)
+}
+
+// Throttle sets the duration to wait between batches, to reduce load of the database.
+type Throttle time.Duration
+
+func (t Throttle) applyBatch(batch *batch) {
+	batch.throttle = time.Duration(t)
+}
+
+type progress func(processed int, total int)
+
+func (p progress) applyBatch(batch *batch) {
+	batch.progress = p
+}
+
+// Progress reports the number of processed records and the total records after each batch.
+// Total records is counted once before the first batch, so it may differ from processed records when records are modified concurrently.
+func Progress(fn func(processed int, total int)) BatchOption {
+	return progress(fn)
+}
+
+type filter []rel.Querier
+
+func (f filter) applyBatch(batch *batch) {
+	batch.queriers = append(batch.queriers, f...)
+}
+
+// Filter records to be iterated.
+func Filter(queriers ...rel.Querier) BatchOption {
+	return filter(queriers)
+}
+
+// Batch iterates records in batches ordered by its key using keyset pagination.
+// Records is a pointer to slice that is loaded with each batch before fn is called,
+// and each batch is processed inside its own transaction, so locks are held only while the batch is processed.
+//
+// Batch is meant to be used inside Do together with DisableTransaction, eg:
+//	schema.DisableTransaction()
+//	schema.Do(func(ctx context.Context, repo rel.Repository) error {
+//		var users []User
+//		return migrator.Batch(ctx, repo, &users, func(repo rel.Repository) error {
+//			for i := range users {
+//				if err := repo.Update(ctx, &users[i], rel.Set("verified", true)); err != nil {
+//					return err
+//				}
+//			}
+//
+//			return nil
+//		}, migrator.BatchSize(500), migrator.Throttle(time.Second))
+//	})
+func Batch(ctx context.Context, repo rel.Repository, records interface{}, fn func(repo rel.Repository) error, options ...BatchOption) error {
+	var (
+		col = rel.NewCollection(records)
+		b   = batch{size: 1000, key: col.PrimaryField()}
+	)
+
+	for i := range options {
+		options[i].applyBatch(&b)
+	}
+
+	var (
+		total     = -1
+		processed = 0
+		last      interface{}
+	)
+
+	if b.progress != nil {
+		count, err := repo.Count(ctx, col.Table(), b.queriers...)
+		if err != nil {
+			return err
+		}
+
+		total = count
+	}
+
+	for {
+		queriers := append([]rel.Querier{}, b.queriers...)
+		if last != nil {
+			queriers = append(queriers, rel.Where(rel.Gt(b.key, last)))
+		}
+
+		queriers = append(queriers, rel.NewSortAsc(b.key), rel.Limit(b.size))
+
+		if err := repo.FindAll(ctx, records, queriers...); err != nil {
+			return err
+		}
+
+		n := col.Len()
+		if n == 0 {
+			return nil
+		}
+
+		last, _ = col.Get(n - 1).Value(b.key)
+
+		if err := repo.Transaction(ctx, fn); err != nil {
+			return err
+		}
+
+		processed += n
+		if b.progress != nil {
+			b.progress(processed, total)
+		}
+
+		if n < b.size {
+			return nil
+		}
+
+		if b.throttle > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(b.throttle):
+			}
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package migrator_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Fs02/rel"
+	. "github.com/Fs02/rel/migrator"
+	"github.com/stretchr/testify/assert"
+)
+
+type user struct {
+	ID       int
+	Name     string
+	Verified bool
+}
+
+func seedUsers(t *testing.T, repo rel.Repository, n int) {
+	var schema Schema
+
+	schema.CreateTable("users", func(t *Table) {
+		t.ID("id")
+		t.String("name")
+		t.Bool("verified", Default(false))
+	})
+
+	assert.Nil(t, schema.Apply(ctx, repo))
+
+	users := make([]user, n)
+	for i := range users {
+		users[i].Name = "user " + strconv.Itoa(i+1)
+	}
+
+	assert.Nil(t, repo.InsertAll(ctx, &users))
+}
+
+func TestBatch(t *testing.T) {
+	var (
+		adapter  = open(t, "batch")
+		repo     = rel.New(adapter)
+		users    []user
+		batches  [][]int
+		progress [][2]int
+	)
+
+	defer adapter.Close()
+
+	seedUsers(t, repo, 25)
+
+	err := Batch(ctx, repo, &users, func(repo rel.Repository) error {
+		ids := make([]int, len(users))
+		for i := range users {
+			ids[i] = users[i].ID
+		}
+
+		batches = append(batches, ids)
+
+		for i := range users {
+			if err := repo.Update(ctx, &users[i], rel.Set("verified", true)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, BatchSize(10), Progress(func(processed int, total int) {
+		progress = append(progress, [2]int{processed, total})
+	}))
+
+	assert.Nil(t, err)
+	assert.Len(t, batches, 3)
+	assert.Equal(t, 1, batches[0][0])
+	assert.Equal(t, 11, batches[1][0])
+	assert.Equal(t, []int{21, 22, 23, 24, 25}, batches[2])
+	assert.Equal(t, [][2]int{{10, 25}, {20, 25}, {25, 25}}, progress)
+	assert.Equal(t, 25, repo.MustCount(ctx, "users", rel.Eq("verified", true)))
+}
+
+func TestBatch_filter(t *testing.T) {
+	var (
+		adapter = open(t, "batch_filter")
+		repo    = rel.New(adapter)
+		users   []user
+		count   = 0
+	)
+
+	defer adapter.Close()
+
+	seedUsers(t, repo, 10)
+
+	err := Batch(ctx, repo, &users, func(repo rel.Repository) error {
+		count += len(users)
+		return nil
+	}, BatchSize(2), BatchKey("name"), Filter(rel.Where(rel.Gt("id", 5))))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 5, count)
+}
+
+func TestBatch_error(t *testing.T) {
+	var (
+		adapter = open(t, "batch_error")
+		repo    = rel.New(adapter)
+		users   []user
+		err     = errors.New("error")
+		calls   = 0
+	)
+
+	defer adapter.Close()
+
+	seedUsers(t, repo, 10)
+
+	assert.Equal(t, err, Batch(ctx, repo, &users, func(repo rel.Repository) error {
+		calls++
+		assert.Nil(t, repo.Update(ctx, &users[0], rel.Set("verified", true)))
+		return err
+	}, BatchSize(5)))
+
+	assert.Equal(t, 1, calls)
+
+	// the failed batch is rolled back.
+	assert.Equal(t, 0, repo.MustCount(ctx, "users", rel.Eq("verified", true)))
+}
+
+func TestBatch_throttle(t *testing.T) {
+	var (
+		adapter     = open(t, "batch_throttle")
+		repo        = rel.New(adapter)
+		users       []user
+		calls       = 0
+		ctx, cancel = context.WithCancel(ctx)
+	)
+
+	defer adapter.Close()
+
+	seedUsers(t, repo, 10)
+
+	err := Batch(ctx, repo, &users, func(repo rel.Repository) error {
+		calls++
+		cancel()
+		return nil
+	}, BatchSize(5), Throttle(time.Minute))
+
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,94 @@
+package migrator
+
+// ColumnType definition.
+type ColumnType string
+
+const (
+	// ID ColumnType.
+	ID ColumnType = "ID"
+	// BigID ColumnType.
+	BigID ColumnType = "BigID"
+	// Bool ColumnType.
+	Bool ColumnType = "BOOL"
+	// Int ColumnType.
+	Int ColumnType = "INT"
+	// BigInt ColumnType.
+	BigInt ColumnType = "BIGINT"
+	// Float ColumnType.
+	Float ColumnType = "FLOAT"
+	// Decimal ColumnType.
+	Decimal ColumnType = "DECIMAL"
+	// String ColumnType.
+	String ColumnType = "STRING"
+	// Text ColumnType.
+	Text ColumnType = "TEXT"
+	// Date ColumnType.
+	Date ColumnType = "DATE"
+	// DateTime ColumnType.
+	DateTime ColumnType = "DATETIME"
+	// Time ColumnType.
+	Time ColumnType = "TIME"
+	// Timestamp ColumnType.
+	Timestamp ColumnType = "TIMESTAMP"
+)
+
+// Column definition.
+type Column struct {
+	Op        SchemaOp
+	Name      string
+	Type      ColumnType
+	Rename    string
+	Unique    bool
+	Required  bool
+	Unsigned  bool
+	Limit     int
+	Precision int
+	Scale     int
+	Default   interface{}
+	Options   string
+}
+
+func (Column) definition() {}
+
+func (c Column) invert() (Column, error) {
+	switch c.Op {
+	case SchemaCreate:
+		return dropColumn(c.Name, nil), nil
+	case SchemaRename:
+		return renameColumn(c.Rename, c.Name, nil), nil
+	default:
+		return Column{}, ErrIrreversible
+	}
+}
+
+func createColumn(name string, typ ColumnType, options []ColumnOption) Column {
+	column := Column{
+		Op:   SchemaCreate,
+		Name: name,
+		Type: typ,
+	}
+
+	applyColumnOptions(&column, options)
+	return column
+}
+
+func renameColumn(name string, newName string, options []ColumnOption) Column {
+	column := Column{
+		Op:     SchemaRename,
+		Name:   name,
+		Rename: newName,
+	}
+
+	applyColumnOptions(&column, options)
+	return column
+}
+
+func dropColumn(name string, options []ColumnOption) Column {
+	column := Column{
+		Op:   SchemaDrop,
+		Name: name,
+	}
+
+	applyColumnOptions(&column, options)
+	return column
+}
@@ -0,0 +1,319 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Fs02/rel"
+)
+
+// TableInfo describes existing table of the database.
+type TableInfo struct {
+	Name    string
+	Columns []string
+	Indexes []IndexInfo
+}
+
+// IndexInfo describes existing index of the database, depending on the database, primary and unique key may be reported as index.
+type IndexInfo struct {
+	Name    string
+	Columns []string
+}
+
+// InspectAdapter is implemented by adapter that is able to list tables, columns and indexes of the database.
+type InspectAdapter interface {
+	InspectTables(ctx context.Context) ([]TableInfo, error)
+}
+
+// Diff compares records against tables of the database, and returns schema that creates missing tables, columns and indexes.
+// Records must be pointers to struct, eg: &Book{}. Column type is inferred from type of the field,
+// and reference field of belongs to association is indexed when it's not the first column of existing index.
+// Diff never drops or changes existing columns, the result is a draft that should be reviewed before it's applied.
+func Diff(ctx context.Context, repo rel.Repository, records ...interface{}) (Schema, error) {
+	var adapter InspectAdapter
+	if !rel.AsAdapter(repo.Adapter(), &adapter) {
+		return Schema{}, ErrNotSupported
+	}
+
+	tables, err := adapter.InspectTables(ctx)
+	if err != nil {
+		return Schema{}, err
+	}
+
+	var (
+		schema   Schema
+		indexes  []Index
+		existing = make(map[string]TableInfo, len(tables))
+	)
+
+	for _, table := range tables {
+		existing[table.Name] = table
+	}
+
+	for _, record := range records {
+		var (
+			doc        = rel.NewDocument(record)
+			name       = doc.Table()
+			table, ok  = existing[name]
+			definition = Table{Op: SchemaAlter, Name: name}
+			keys       []Key
+		)
+
+		if !ok {
+			definition.Op = SchemaCreate
+			table.Name = name
+		}
+
+		for _, field := range doc.Fields() {
+			if contains(table.Columns, field) {
+				continue
+			}
+
+			var (
+				typ, _  = doc.Type(field)
+				primary = definition.Op == SchemaCreate && field == doc.PrimaryField()
+				column  = inferColumn(field, typ, primary)
+			)
+
+			definition.Definitions = append(definition.Definitions, column)
+			table.Columns = append(table.Columns, field)
+
+			if primary && column.Type != ID && column.Type != BigID {
+				keys = append(keys, createKey([]string{field}, PrimaryKey, nil))
+			}
+		}
+
+		// table constraints must be defined after columns.
+		for _, key := range keys {
+			definition.Definitions = append(definition.Definitions, key)
+		}
+
+		for _, assoc := range doc.BelongsTo() {
+			column := doc.Association(assoc).ReferenceField()
+			if !contains(table.Columns, column) || indexed(table.Indexes, column) {
+				continue
+			}
+
+			index := createIndex(name, name+"_"+column+"_index", []string{column}, nil)
+			indexes = append(indexes, index)
+			table.Indexes = append(table.Indexes, IndexInfo{Name: index.Name, Columns: index.Columns})
+		}
+
+		if len(definition.Definitions) > 0 {
+			schema.add(definition)
+		}
+
+		existing[name] = table
+	}
+
+	for _, index := range indexes {
+		schema.add(index)
+	}
+
+	return schema, nil
+}
+
+// inferColumn returns column definition based on Go type of the field, integer primary field is defined as auto increment.
+func inferColumn(name string, typ reflect.Type, primary bool) Column {
+	var (
+		column = Column{Op: SchemaCreate, Name: name, Type: Text}
+	)
+
+	if typ == reflect.TypeOf(time.Time{}) {
+		column.Type = DateTime
+		return column
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		column.Type = Bool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		column.Type = Int
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		column.Type = Int
+		column.Unsigned = true
+	case reflect.Int64:
+		column.Type = BigInt
+	case reflect.Uint64:
+		column.Type = BigInt
+		column.Unsigned = true
+	case reflect.Float32, reflect.Float64:
+		column.Type = Float
+	case reflect.String:
+		column.Type = String
+	}
+
+	if primary {
+		switch column.Type {
+		case Int:
+			column = Column{Op: SchemaCreate, Name: name, Type: ID}
+		case BigInt:
+			column = Column{Op: SchemaCreate, Name: name, Type: BigID}
+		}
+	}
+
+	return column
+}
+
+func contains(columns []string, column string) bool {
+	for i := range columns {
+		if columns[i] == column {
+			return true
+		}
+	}
+
+	return false
+}
+
+func indexed(indexes []IndexInfo, column string) bool {
+	for i := range indexes {
+		if len(indexes[i].Columns) > 0 && indexes[i].Columns[0] == column {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Generate writes schema as a migration file that can be used by rel command,
+// pkg is the package name of migrations directory and name is the name of migration in snake case, eg: add_books.
+// Only migrations returned by Diff are supported, the migration is rolled back by inverting it automatically.
+//
+// Example:
+//	schema, err := migrator.Diff(ctx, repo, &Book{}, &Author{})
+//	if err != nil {
+//		panic(err)
+//	}
+//
+//	file, _ := os.Create("db/migrations/20200829084000_add_books.go")
+//	defer file.Close()
+//
+//	migrator.Generate(file, "migrations", "add_books", schema)
+func Generate(w io.Writer, pkg string, name string, schema Schema) error {
+	var (
+		buffer strings.Builder
+	)
+
+	buffer.WriteString("package " + pkg + "\n\n")
+	buffer.WriteString("import (\n\t\"github.com/Fs02/rel/migrator\"\n)\n\n")
+	buffer.WriteString("// Migrate" + camelize(name) + " definition.\n")
+	buffer.WriteString("func Migrate" + camelize(name) + "(schema *migrator.Schema) {\n")
+
+	for i, migration := range schema.Migrations {
+		if i > 0 {
+			buffer.WriteString("\n")
+		}
+
+		if err := generate(&buffer, migration); err != nil {
+			return err
+		}
+	}
+
+	buffer.WriteString("}\n")
+
+	_, err := io.WriteString(w, buffer.String())
+	return err
+}
+
+func generate(buffer *strings.Builder, migration Migration) error {
+	switch v := migration.(type) {
+	case Table:
+		switch v.Op {
+		case SchemaCreate:
+			buffer.WriteString("\tschema.CreateTable(" + strconv.Quote(v.Name) + ", func(t *migrator.Table) {\n")
+		case SchemaAlter:
+			buffer.WriteString("\tschema.AlterTable(" + strconv.Quote(v.Name) + ", func(t *migrator.AlterTable) {\n")
+		default:
+			return fmt.Errorf("migrator: unable to generate table operation %d", v.Op)
+		}
+
+		for _, definition := range v.Definitions {
+			if err := generateDefinition(buffer, definition); err != nil {
+				return err
+			}
+		}
+
+		buffer.WriteString("\t})\n")
+	case Index:
+		if v.Op != SchemaCreate || v.Unique || v.Filter != "" || v.Concurrent || v.Optional || v.Options != "" {
+			return fmt.Errorf("migrator: unable to generate index %s", v.Name)
+		}
+
+		buffer.WriteString("\tschema.CreateIndex(" + strconv.Quote(v.Table) + ", " + strconv.Quote(v.Name) + ", " + quoteSlice(v.Columns) + ")\n")
+	default:
+		return fmt.Errorf("migrator: unable to generate migration %T", migration)
+	}
+
+	return nil
+}
+
+func generateDefinition(buffer *strings.Builder, definition TableDefinition) error {
+	switch v := definition.(type) {
+	case Column:
+		method, ok := columnMethods[v.Type]
+		if v.Op != SchemaCreate || !ok {
+			return fmt.Errorf("migrator: unable to generate column %s", v.Name)
+		}
+
+		buffer.WriteString("\t\tt." + method + "(" + strconv.Quote(v.Name))
+		if v.Unsigned {
+			buffer.WriteString(", migrator.Unsigned(true)")
+		}
+
+		buffer.WriteString(")\n")
+	case Key:
+		if v.Op != SchemaCreate || v.Type != PrimaryKey || len(v.Columns) != 1 {
+			return fmt.Errorf("migrator: unable to generate key %s", v.Name)
+		}
+
+		buffer.WriteString("\t\tt.PrimaryKey(" + strconv.Quote(v.Columns[0]) + ")\n")
+	default:
+		return fmt.Errorf("migrator: unable to generate definition %T", definition)
+	}
+
+	return nil
+}
+
+var columnMethods = map[ColumnType]string{
+	ID:        "ID",
+	BigID:     "BigID",
+	Bool:      "Bool",
+	Int:       "Int",
+	BigInt:    "BigInt",
+	Float:     "Float",
+	Decimal:   "Decimal",
+	String:    "String",
+	Text:      "Text",
+	Date:      "Date",
+	DateTime:  "DateTime",
+	Time:      "Time",
+	Timestamp: "Timestamp",
+}
+
+func quoteSlice(values []string) string {
+	quoted := make([]string, len(values))
+	for i := range values {
+		quoted[i] = strconv.Quote(values[i])
+	}
+
+	return "[]string{" + strings.Join(quoted, ", ") + "}"
+}
+
+func camelize(name string) string {
+	var (
+		buffer strings.Builder
+	)
+
+	for _, word := range strings.Split(name, "_") {
+		if word != "" {
+			buffer.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+
+	return buffer.String()
+}
@@ -0,0 +1,208 @@
+package migrator_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/Fs02/rel"
+	. "github.com/Fs02/rel/migrator"
+	"github.com/Fs02/rel/reltest"
+	"github.com/stretchr/testify/assert"
+)
+
+type review struct {
+	ID        int
+	BookID    int
+	Book      book
+	Body      string
+	Rating    uint8
+	Published bool
+	Score     float64
+	Note      *string
+	CreatedAt time.Time
+}
+
+type authorProfile struct {
+	ID    int
+	Name  string
+	Email string
+}
+
+func (authorProfile) Table() string {
+	return "authors"
+}
+
+type tag struct {
+	Code  string `db:"code,primary"`
+	Count int64
+}
+
+func TestDiff(t *testing.T) {
+	var (
+		adapter  = open(t, "diff")
+		repo     = rel.New(adapter)
+		migrator = New(repo)
+	)
+
+	defer adapter.Close()
+
+	register(migrator)
+	assert.Nil(t, migrator.Migrate(ctx))
+
+	schema, err := Diff(ctx, repo, &book{}, &review{}, &authorProfile{}, &tag{})
+	assert.Nil(t, err)
+	assert.Equal(t, []Migration{
+		Table{
+			Op:   SchemaCreate,
+			Name: "reviews",
+			Definitions: []TableDefinition{
+				Column{Op: SchemaCreate, Name: "id", Type: ID},
+				Column{Op: SchemaCreate, Name: "book_id", Type: Int},
+				Column{Op: SchemaCreate, Name: "body", Type: String},
+				Column{Op: SchemaCreate, Name: "rating", Type: Int, Unsigned: true},
+				Column{Op: SchemaCreate, Name: "published", Type: Bool},
+				Column{Op: SchemaCreate, Name: "score", Type: Float},
+				Column{Op: SchemaCreate, Name: "note", Type: String},
+				Column{Op: SchemaCreate, Name: "created_at", Type: DateTime},
+			},
+		},
+		Table{
+			Op:   SchemaAlter,
+			Name: "authors",
+			Definitions: []TableDefinition{
+				Column{Op: SchemaCreate, Name: "email", Type: String},
+			},
+		},
+		Table{
+			Op:   SchemaCreate,
+			Name: "tags",
+			Definitions: []TableDefinition{
+				Column{Op: SchemaCreate, Name: "code", Type: String},
+				Column{Op: SchemaCreate, Name: "count", Type: BigInt},
+				Key{Op: SchemaCreate, Type: PrimaryKey, Columns: []string{"code"}},
+			},
+		},
+		Index{Op: SchemaCreate, Table: "reviews", Name: "reviews_book_id_index", Columns: []string{"book_id"}},
+	}, schema.Migrations)
+
+	// apply the diff, nothing should be left afterwards.
+	migrator.Register(20200829084200, "sync_models", func(s *Schema) {
+		s.Migrations = schema.Migrations
+	}, nil)
+
+	assert.Nil(t, migrator.Migrate(ctx))
+
+	schema, err = Diff(ctx, repo, &book{}, &review{}, &authorProfile{}, &tag{})
+	assert.Nil(t, err)
+	assert.Len(t, schema.Migrations, 0)
+}
+
+func TestDiff_notSupported(t *testing.T) {
+	_, err := Diff(ctx, reltest.New(), &book{})
+	assert.Equal(t, ErrNotSupported, err)
+}
+
+func TestGenerate(t *testing.T) {
+	var (
+		buffer bytes.Buffer
+		schema Schema
+	)
+
+	schema.CreateTable("reviews", func(t *Table) {
+		t.ID("id")
+		t.Int("book_id")
+		t.Int("rating", Unsigned(true))
+	})
+
+	schema.AlterTable("authors", func(t *AlterTable) {
+		t.String("email")
+	})
+
+	schema.CreateTable("tags", func(t *Table) {
+		t.String("code")
+		t.PrimaryKey("code")
+	})
+
+	schema.CreateIndex("reviews", "reviews_book_id_index", []string{"book_id"})
+
+	assert.Nil(t, Generate(&buffer, "migrations", "sync_models", schema))
+	assert.Equal(t, `package migrations
+
+import (
+	"github.com/Fs02/rel/migrator"
+)
+
+// MigrateSyncModels definition.
+func MigrateSyncModels(schema *migrator.Schema) {
+	schema.CreateTable("reviews", func(t *migrator.Table) {
+		t.ID("id")
+		t.Int("book_id")
+		t.Int("rating", migrator.Unsigned(true))
+	})
+
+	schema.AlterTable("authors", func(t *migrator.AlterTable) {
+		t.String("email")
+	})
+
+	schema.CreateTable("tags", func(t *migrator.Table) {
+		t.String("code")
+		t.PrimaryKey("code")
+	})
+
+	schema.CreateIndex("reviews", "reviews_book_id_index", []string{"book_id"})
+}
+`, buffer.String())
+}
+
+func TestGenerate_unsupported(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(schema *Schema)
+	}{
+		{
+			name: "drop table",
+			fn: func(schema *Schema) {
+				schema.DropTable("books")
+			},
+		},
+		{
+			name: "drop column",
+			fn: func(schema *Schema) {
+				schema.DropColumn("books", "title")
+			},
+		},
+		{
+			name: "unique index",
+			fn: func(schema *Schema) {
+				schema.CreateUniqueIndex("books", "books_title", []string{"title"})
+			},
+		},
+		{
+			name: "foreign key",
+			fn: func(schema *Schema) {
+				schema.CreateTable("reviews", func(t *Table) {
+					t.ForeignKey("book_id", "books", "id")
+				})
+			},
+		},
+		{
+			name: "do",
+			fn: func(schema *Schema) {
+				schema.Do(nil)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				buffer bytes.Buffer
+				schema Schema
+			)
+
+			test.fn(&schema)
+			assert.NotNil(t, Generate(&buffer, "migrations", "unsupported", schema))
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package migrator
+
+// Index definition.
+// Columns may contain expression, eg: lower(email), which is supported by PostgreSQL, SQLite3 and MySQL 8.
+type Index struct {
+	Op         SchemaOp
+	Table      string
+	Name       string
+	Columns    []string
+	Unique     bool
+	Filter     string
+	Concurrent bool
+	Optional   bool
+	Options    string
+}
+
+func (Index) migration() {}
+
+func (i Index) invert() (Index, error) {
+	switch i.Op {
+	case SchemaCreate:
+		return Index{
+			Op:         SchemaDrop,
+			Table:      i.Table,
+			Name:       i.Name,
+			Concurrent: i.Concurrent,
+		}, nil
+	default:
+		return Index{}, ErrIrreversible
+	}
+}
+
+func createIndex(table string, name string, columns []string, options []IndexOption) Index {
+	index := Index{
+		Op:      SchemaCreate,
+		Table:   table,
+		Name:    name,
+		Columns: columns,
+	}
+
+	applyIndexOptions(&index, options)
+	return index
+}
+
+func dropIndex(table string, name string, options []IndexOption) Index {
+	index := Index{
+		Op:    SchemaDrop,
+		Table: table,
+		Name:  name,
+	}
+
+	applyIndexOptions(&index, options)
+	return index
+}
@@ -0,0 +1,92 @@
+package migrator
+
+// KeyType definition.
+type KeyType string
+
+const (
+	// PrimaryKey KeyType.
+	PrimaryKey KeyType = "PRIMARY KEY"
+	// ForeignKey KeyType.
+	ForeignKey KeyType = "FOREIGN KEY"
+	// UniqueKey KeyType.
+	UniqueKey KeyType = "UNIQUE"
+	// CheckKey KeyType.
+	CheckKey KeyType = "CHECK"
+)
+
+// ForeignKeyReference definition.
+type ForeignKeyReference struct {
+	Table    string
+	Columns  []string
+	OnDelete string
+	OnUpdate string
+}
+
+// Key definition.
+// Key is rendered as table constraint, when name is empty, the name is generated by the database.
+type Key struct {
+	Op         SchemaOp
+	Name       string
+	Type       KeyType
+	Columns    []string
+	Expression string
+	Reference  ForeignKeyReference
+	Options    string
+}
+
+func (Key) definition() {}
+
+func (k Key) invert() (Key, error) {
+	if k.Op != SchemaCreate || k.Name == "" {
+		return Key{}, ErrIrreversible
+	}
+
+	return dropKey(k.Name, nil), nil
+}
+
+func createKey(columns []string, typ KeyType, options []KeyOption) Key {
+	key := Key{
+		Op:      SchemaCreate,
+		Type:    typ,
+		Columns: columns,
+	}
+
+	applyKeyOptions(&key, options)
+	return key
+}
+
+func createForeignKey(column string, refTable string, refColumn string, options []KeyOption) Key {
+	key := Key{
+		Op:      SchemaCreate,
+		Type:    ForeignKey,
+		Columns: []string{column},
+		Reference: ForeignKeyReference{
+			Table:   refTable,
+			Columns: []string{refColumn},
+		},
+	}
+
+	applyKeyOptions(&key, options)
+	return key
+}
+
+func createCheck(expression string, options []KeyOption) Key {
+	key := Key{
+		Op:         SchemaCreate,
+		Type:       CheckKey,
+		Expression: expression,
+	}
+
+	applyKeyOptions(&key, options)
+	return key
+}
+
+func dropKey(name string, options []KeyOption) Key {
+	key := Key{
+		Op:   SchemaDrop,
+		Name: name,
+	}
+
+	applyKeyOptions(&key, options)
+	return key
+}
@@ -0,0 +1,112 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Fs02/rel"
+)
+
+// LockTable is the name of table used to lock migrations when the adapter doesn't support advisory lock.
+const LockTable = "schema_migrations_lock"
+
+// ErrLockTimeout is returned when migration lock is not acquired within the lock timeout.
+// Lock row that is left by terminated instance can be removed from schema_migrations_lock table once no migration is running.
+var ErrLockTimeout = errors.New("migrator: timeout waiting for migration lock, migrations may be running by another instance")
+
+// LockAdapter is implemented by adapter that is able to hold a lock across connections and instances, such as advisory lock.
+// Lock blocks until the lock identified by name is acquired or the context is done, and returns a function to release it.
+type LockAdapter interface {
+	Lock(ctx context.Context, name string) (func() error, error)
+}
+
+type lock struct {
+	ID        int
+	Name      string
+	CreatedAt time.Time
+}
+
+func (lock) Table() string {
+	return LockTable
+}
+
+// lockInterval is the duration to wait before retrying to insert lock row.
+var lockInterval = time.Second
+
+// lock runs fn while holding migration lock, so concurrent instances don't apply the same migrations twice.
+// Advisory lock is used when it's supported by the adapter, otherwise a row identified by the version table is inserted to lock table.
+func (m *Migrator) lock(ctx context.Context, fn func() error) (err error) {
+	var (
+		lockCtx = ctx
+		cancel  = func() {}
+	)
+
+	if m.lockTimeout > 0 {
+		lockCtx, cancel = context.WithTimeout(ctx, m.lockTimeout)
+	}
+
+	unlock, err := m.acquire(lockCtx)
+	cancel()
+
+	if err != nil {
+		if ctx.Err() == nil && lockCtx.Err() == context.DeadlineExceeded {
+			return ErrLockTimeout
+		}
+
+		return err
+	}
+
+	defer func() {
+		if uerr := unlock(); err == nil {
+			err = uerr
+		}
+	}()
+
+	return fn()
+}
+
+func (m *Migrator) acquire(ctx context.Context) (func() error, error) {
+	var adapter LockAdapter
+	if rel.AsAdapter(m.repo.Adapter(), &adapter) {
+		unlock, err := adapter.Lock(ctx, m.table)
+		if err != ErrNotSupported {
+			return unlock, err
+		}
+	}
+
+	var (
+		schema Schema
+		l      = lock{Name: m.table}
+	)
+
+	schema.CreateTable(LockTable, func(t *Table) {
+		t.ID("id")
+		t.String("name", Unique(true), Required(true))
+		t.DateTime("created_at")
+	}, Optional(true))
+
+	if err := schema.Apply(ctx, m.repo); err != nil {
+		return nil, err
+	}
+
+	for {
+		err := m.repo.Insert(ctx, &l)
+		if err == nil {
+			return func() error {
+				return m.repo.Delete(context.Background(), &l)
+			}, nil
+		}
+
+		var cerr rel.ConstraintError
+		if !errors.As(err, &cerr) || cerr.Type != rel.UniqueConstraint {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockInterval):
+		}
+	}
+}
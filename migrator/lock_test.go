@@ -0,0 +1,110 @@
+package migrator_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/adapter/sqlite3"
+	. "github.com/Fs02/rel/migrator"
+	"github.com/stretchr/testify/assert"
+)
+
+type migrationLock struct {
+	ID   int
+	Name string
+}
+
+func (migrationLock) Table() string {
+	return LockTable
+}
+
+type lockAdapter struct {
+	*sqlite3.Adapter
+	names    []string
+	unlocked int
+	err      error
+}
+
+func (la *lockAdapter) Lock(ctx context.Context, name string) (func() error, error) {
+	if la.err != nil {
+		return nil, la.err
+	}
+
+	la.names = append(la.names, name)
+	return func() error {
+		la.unlocked++
+		return nil
+	}, nil
+}
+
+func TestMigrator_lock(t *testing.T) {
+	var (
+		adapter  = open(t, "lock")
+		repo     = rel.New(adapter)
+		migrator = New(repo, LockTimeout(10*time.Millisecond))
+	)
+
+	defer adapter.Close()
+
+	register(migrator)
+	assert.Nil(t, migrator.Migrate(ctx))
+	assert.Equal(t, 0, repo.MustCount(ctx, LockTable))
+
+	// another instance is holding the lock.
+	lock := migrationLock{Name: VersionTable}
+	repo.MustInsert(ctx, &lock)
+
+	assert.Equal(t, ErrLockTimeout, migrator.Rollback(ctx, 1))
+	assert.Equal(t, ErrLockTimeout, migrator.MigrateTo(ctx, 0))
+	assert.Equal(t, 2, repo.MustCount(ctx, VersionTable))
+
+	// lock of another version table doesn't block.
+	assert.Nil(t, New(repo, VersionTableName("analytics_schema_migrations")).Migrate(ctx))
+
+	// canceled context.
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.Equal(t, context.Canceled, New(repo).Migrate(canceled))
+
+	repo.MustDelete(ctx, &lock)
+
+	assert.Nil(t, migrator.Rollback(ctx, 1))
+	assert.Equal(t, 1, repo.MustCount(ctx, VersionTable))
+	assert.Equal(t, 0, repo.MustCount(ctx, LockTable))
+}
+
+func TestMigrator_lock_advisory(t *testing.T) {
+	var (
+		adapter  = &lockAdapter{Adapter: open(t, "lock_advisory")}
+		repo     = rel.New(adapter)
+		migrator = New(repo, VersionTableName("analytics_schema_migrations"))
+	)
+
+	defer adapter.Close()
+
+	register(migrator)
+	assert.Nil(t, migrator.Migrate(ctx))
+	assert.Nil(t, migrator.Rollback(ctx, 1))
+	assert.Equal(t, []string{"analytics_schema_migrations", "analytics_schema_migrations"}, adapter.names)
+	assert.Equal(t, 2, adapter.unlocked)
+
+	// lock table is not used.
+	_, err := repo.Count(ctx, LockTable)
+	assert.NotNil(t, err)
+}
+
+func TestMigrator_lock_error(t *testing.T) {
+	var (
+		err      = errors.New("lock error")
+		adapter  = &lockAdapter{Adapter: open(t, "lock_error"), err: err}
+		migrator = New(rel.New(adapter))
+	)
+
+	defer adapter.Close()
+
+	register(migrator)
+	assert.Equal(t, err, migrator.Migrate(ctx))
+}
@@ -0,0 +1,338 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Fs02/rel"
+)
+
+// VersionTable is the default name of table used to track applied migrations.
+const VersionTable = "schema_migrations"
+
+// ErrNotRegistered is returned when reverting applied migration that is not registered.
+var ErrNotRegistered = errors.New("migrator: migration is not registered")
+
+type version struct {
+	ID        int
+	Version   int64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	table string `db:"-"`
+}
+
+func (v version) Table() string {
+	if v.table == "" {
+		return VersionTable
+	}
+
+	return v.table
+}
+
+type step struct {
+	version int64
+	name    string
+	up      func(schema *Schema)
+	down    func(schema *Schema)
+}
+
+// schema returns migrations to be applied, down migrations are inverted from up migrations when it's not defined.
+func (s step) schema(up bool) (Schema, error) {
+	var (
+		schema Schema
+	)
+
+	if up {
+		s.up(&schema)
+		return schema, nil
+	}
+
+	if s.down != nil {
+		s.down(&schema)
+		return schema, nil
+	}
+
+	s.up(&schema)
+	return schema.invert()
+}
+
+// Status of a migration.
+// Migration that is applied, but not registered to migrator will have empty name.
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// MigrationError is returned when a migration fails to be applied.
+type MigrationError struct {
+	Version int64
+	Name    string
+	Err     error
+}
+
+// Unwrap error returned by the migration.
+func (me MigrationError) Unwrap() error {
+	return me.Err
+}
+
+// Error message.
+func (me MigrationError) Error() string {
+	return "migrator: migration " + strconv.FormatInt(me.Version, 10) + " " + me.Name + " failed: " + me.Err.Error()
+}
+
+// Migrator applies registered migrations in order of its version.
+// Applied versions are tracked in schema_migrations table, which is created automatically.
+// Each set of migrations, such as migrations of another database, can be tracked independently using VersionTableName option.
+//
+// Each migration is applied inside transaction when the adapter supports transactional ddl,
+// otherwise failed migration may leave the schema partially changed.
+//
+// Migrate, Rollback and MigrateTo hold a lock while applying migrations, so instances that are rolled out simultaneously
+// wait for each other instead of applying the same migration twice.
+type Migrator struct {
+	repo        rel.Repository
+	table       string
+	lockTimeout time.Duration
+	steps       []step
+}
+
+// Register a migration with its version and name.
+// Version should be unique and increasing, timestamp of when the migration is written is commonly used, eg: 20200829084000.
+//
+// Down migration is used to revert the migration, when it's nil, up migration is inverted automatically.
+// Create table, add column, create index and rename can be inverted, while drop and data migration require down migration to be defined.
+func (m *Migrator) Register(version int64, name string, up func(schema *Schema), down func(schema *Schema)) {
+	i := sort.Search(len(m.steps), func(i int) bool {
+		return m.steps[i].version >= version
+	})
+
+	if i < len(m.steps) && m.steps[i].version == version {
+		panic("migrator: duplicate migration version " + strconv.FormatInt(version, 10))
+	}
+
+	m.steps = append(m.steps, step{})
+	copy(m.steps[i+1:], m.steps[i:])
+	m.steps[i] = step{version: version, name: name, up: up, down: down}
+}
+
+func (m *Migrator) find(version int64) (step, bool) {
+	i := sort.Search(len(m.steps), func(i int) bool {
+		return m.steps[i].version >= version
+	})
+
+	if i < len(m.steps) && m.steps[i].version == version {
+		return m.steps[i], true
+	}
+
+	return step{}, false
+}
+
+// Migrate applies pending migrations in order of its version.
+// It stops at the first migration that fails, migrations applied before it are kept.
+func (m *Migrator) Migrate(ctx context.Context) error {
+	return m.lock(ctx, func() error {
+		applied, err := m.applied(ctx)
+		if err != nil {
+			return err
+		}
+
+		return m.up(ctx, applied, math.MaxInt64)
+	})
+}
+
+// Rollback reverts the last n applied migrations in reverse order of its version.
+func (m *Migrator) Rollback(ctx context.Context, n int) error {
+	return m.lock(ctx, func() error {
+		applied, err := m.applied(ctx)
+		if err != nil {
+			return err
+		}
+
+		if n > len(applied) {
+			n = len(applied)
+		}
+
+		return m.down(ctx, applied[len(applied)-n:])
+	})
+}
+
+// MigrateTo migrates the schema to the given version.
+// Applied migrations newer than the version are reverted, and pending migrations up to the version are applied.
+// Version zero reverts every applied migrations.
+func (m *Migrator) MigrateTo(ctx context.Context, version int64) error {
+	return m.lock(ctx, func() error {
+		applied, err := m.applied(ctx)
+		if err != nil {
+			return err
+		}
+
+		i := sort.Search(len(applied), func(i int) bool {
+			return applied[i].Version > version
+		})
+
+		if err := m.down(ctx, applied[i:]); err != nil {
+			return err
+		}
+
+		return m.up(ctx, applied[:i], version)
+	})
+}
+
+// up applies pending migrations up to the given version.
+func (m *Migrator) up(ctx context.Context, applied []version, target int64) error {
+	var (
+		versions = make(map[int64]bool, len(applied))
+	)
+
+	for _, v := range applied {
+		versions[v.Version] = true
+	}
+
+	for _, s := range m.steps {
+		if s.version > target {
+			break
+		}
+
+		if versions[s.version] {
+			continue
+		}
+
+		if err := m.run(ctx, s, true, version{Version: s.version}); err != nil {
+			return MigrationError{Version: s.version, Name: s.name, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// down reverts applied migrations in reverse order.
+func (m *Migrator) down(ctx context.Context, applied []version) error {
+	for i := len(applied) - 1; i >= 0; i-- {
+		var (
+			v     = applied[i]
+			s, ok = m.find(v.Version)
+		)
+
+		if !ok {
+			return MigrationError{Version: v.Version, Err: ErrNotRegistered}
+		}
+
+		if err := m.run(ctx, s, false, v); err != nil {
+			return MigrationError{Version: s.version, Name: s.name, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// Status returns status of every registered and applied migrations sorted by its version.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	versions, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		result  = make([]Status, 0, len(m.steps))
+		applied = make(map[int64]version, len(versions))
+	)
+
+	for _, v := range versions {
+		applied[v.Version] = v
+	}
+
+	for _, s := range m.steps {
+		status := Status{Version: s.version, Name: s.name}
+		if v, ok := applied[s.version]; ok {
+			status.Applied = true
+			status.AppliedAt = v.CreatedAt
+			delete(applied, s.version)
+		}
+
+		result = append(result, status)
+	}
+
+	for _, v := range applied {
+		result = append(result, Status{Version: v.Version, Applied: true, AppliedAt: v.CreatedAt})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+
+	return result, nil
+}
+
+// applied returns applied versions sorted by its version, version table is created if it doesn't exist.
+func (m *Migrator) applied(ctx context.Context) ([]version, error) {
+	var (
+		schema   Schema
+		versions []version
+	)
+
+	schema.CreateTable(m.table, func(t *Table) {
+		t.ID("id")
+		t.BigInt("version", Unique(true), Required(true))
+		t.DateTime("created_at")
+		t.DateTime("updated_at")
+	}, Optional(true))
+
+	if err := schema.Apply(ctx, m.repo); err != nil {
+		return nil, err
+	}
+
+	err := m.repo.FindAll(ctx, &versions, rel.From(m.table), rel.NewSortAsc("version"))
+	return versions, err
+}
+
+// run migration and records its version, or deletes the version when reverting.
+func (m *Migrator) run(ctx context.Context, s step, up bool, v version) error {
+	schema, err := s.schema(up)
+	if err != nil {
+		return err
+	}
+
+	v.table = m.table
+
+	return m.transaction(ctx, schema.transactional(), func(repo rel.Repository) error {
+		if err := schema.Apply(ctx, repo); err != nil {
+			return err
+		}
+
+		if up {
+			return repo.Insert(ctx, &v)
+		}
+
+		return repo.Delete(ctx, &v)
+	})
+}
+
+// transaction runs fn inside transaction when it's enabled and the adapter supports transactional ddl.
+func (m *Migrator) transaction(ctx context.Context, enabled bool, fn func(repo rel.Repository) error) error {
+	if enabled && m.repo.Adapter().Capabilities().Is(rel.TransactionCapability|rel.TransactionalDDLCapability) {
+		return m.repo.Transaction(ctx, fn)
+	}
+
+	return fn(m.repo)
+}
+
+// New migrator that applies migrations using the repository.
+func New(repo rel.Repository, options ...MigratorOption) *Migrator {
+	m := &Migrator{
+		repo:  repo,
+		table: VersionTable,
+	}
+
+	for i := range options {
+		options[i].applyMigrator(m)
+	}
+
+	return m
+}
@@ -0,0 +1,336 @@
+package migrator_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/adapter/sqlite3"
+	. "github.com/Fs02/rel/migrator"
+	"github.com/Fs02/rel/reltest"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+var ctx = context.TODO()
+
+type version struct {
+	ID      int
+	Version int64
+}
+
+func (version) Table() string {
+	return VersionTable
+}
+
+type book struct {
+	ID     int
+	Title  string
+	Author string
+}
+
+type author struct {
+	ID   int
+	Name string
+}
+
+func open(t *testing.T, name string) *sqlite3.Adapter {
+	adapter, err := sqlite3.Open("file:" + name + "?mode=memory&cache=shared")
+	assert.Nil(t, err)
+
+	return adapter
+}
+
+func register(m *Migrator) {
+	m.Register(20200829084000, "create_books", func(schema *Schema) {
+		schema.CreateTable("books", func(t *Table) {
+			t.ID("id")
+			t.String("title")
+			t.String("author")
+		})
+	}, nil)
+
+	m.Register(20200829084100, "create_authors", func(schema *Schema) {
+		schema.CreateTable("authors", func(t *Table) {
+			t.ID("id")
+			t.String("name")
+		})
+	}, nil)
+}
+
+func TestMigrator_Migrate(t *testing.T) {
+	var (
+		adapter  = open(t, "migrate")
+		repo     = rel.New(adapter)
+		migrator = New(repo)
+	)
+
+	defer adapter.Close()
+
+	register(migrator)
+
+	assert.Nil(t, migrator.Migrate(ctx))
+	assert.Nil(t, repo.Insert(ctx, &book{Title: "REL for dummies", Author: "Kia"}))
+
+	// already applied migrations are skipped.
+	assert.Nil(t, migrator.Migrate(ctx))
+	assert.Equal(t, 2, repo.MustCount(ctx, VersionTable))
+}
+
+func TestMigrator_Migrate_error(t *testing.T) {
+	var (
+		adapter  = open(t, "migrate_error")
+		repo     = rel.New(adapter)
+		migrator = New(repo)
+	)
+
+	defer adapter.Close()
+
+	register(migrator)
+	migrator.Register(20200829084200, "add_invalid_column", func(schema *Schema) {
+		schema.AddColumn("books", "isbn", String)
+		schema.AddColumn("books", "isbn", String)
+	}, nil)
+
+	err := migrator.Migrate(ctx)
+	assert.NotNil(t, err)
+
+	var me MigrationError
+	assert.True(t, errors.As(err, &me))
+	assert.Equal(t, int64(20200829084200), me.Version)
+	assert.Equal(t, "add_invalid_column", me.Name)
+	assert.Contains(t, err.Error(), "migrator: migration 20200829084200 add_invalid_column failed: ")
+
+	// transaction is rolled back, so the column can be added again.
+	assert.Equal(t, 2, repo.MustCount(ctx, VersionTable))
+	_, _, err = adapter.Exec(ctx, "ALTER TABLE books ADD COLUMN isbn VARCHAR(255);", nil)
+	assert.Nil(t, err)
+}
+
+func TestMigrator_Migrate_disableTransaction(t *testing.T) {
+	var (
+		adapter  = open(t, "migrate_disable_transaction")
+		repo     = rel.New(adapter)
+		migrator = New(repo)
+		err      = errors.New("error")
+	)
+
+	defer adapter.Close()
+
+	migrator.Register(20200829084000, "create_authors", func(schema *Schema) {
+		schema.DisableTransaction()
+		schema.CreateTable("authors", func(t *Table) {
+			t.ID("id")
+			t.String("name")
+		})
+		schema.Do(func(ctx context.Context, repo rel.Repository) error {
+			return err
+		})
+	}, nil)
+
+	assert.True(t, errors.Is(migrator.Migrate(ctx), err))
+
+	// changes are kept since the migration is not applied inside transaction.
+	assert.Equal(t, 0, repo.MustCount(ctx, VersionTable))
+	assert.Nil(t, repo.Insert(ctx, &author{Name: "Kia"}))
+}
+
+func TestMigrator_Rollback(t *testing.T) {
+	var (
+		adapter  = open(t, "rollback")
+		repo     = rel.New(adapter)
+		migrator = New(repo)
+	)
+
+	defer adapter.Close()
+
+	register(migrator)
+	migrator.Register(20200829084200, "drop_authors", func(schema *Schema) {
+		schema.DropTable("authors")
+	}, func(schema *Schema) {
+		schema.CreateTable("authors", func(t *Table) {
+			t.ID("id")
+			t.String("name")
+		})
+	})
+
+	assert.Nil(t, migrator.Migrate(ctx))
+	assert.Equal(t, 3, repo.MustCount(ctx, VersionTable))
+
+	// uses defined down migration.
+	assert.Nil(t, migrator.Rollback(ctx, 1))
+	assert.Equal(t, 2, repo.MustCount(ctx, VersionTable))
+	assert.Nil(t, repo.Insert(ctx, &author{Name: "Kia"}))
+
+	// inverted up migrations.
+	assert.Nil(t, migrator.Rollback(ctx, 5))
+	assert.Equal(t, 0, repo.MustCount(ctx, VersionTable))
+	assert.NotNil(t, repo.Insert(ctx, &book{Title: "REL for dummies"}))
+
+	// nothing to rollback.
+	assert.Nil(t, migrator.Rollback(ctx, 1))
+}
+
+func TestMigrator_Rollback_irreversible(t *testing.T) {
+	var (
+		adapter  = open(t, "rollback_irreversible")
+		repo     = rel.New(adapter)
+		migrator = New(repo)
+	)
+
+	defer adapter.Close()
+
+	register(migrator)
+	migrator.Register(20200829084200, "drop_authors", func(schema *Schema) {
+		schema.DropTable("authors")
+	}, nil)
+
+	assert.Nil(t, migrator.Migrate(ctx))
+
+	err := migrator.Rollback(ctx, 1)
+	assert.True(t, errors.Is(err, ErrIrreversible))
+	assert.Equal(t, 3, repo.MustCount(ctx, VersionTable))
+}
+
+func TestMigrator_Rollback_notRegistered(t *testing.T) {
+	var (
+		adapter  = open(t, "rollback_not_registered")
+		repo     = rel.New(adapter)
+		migrator = New(repo)
+	)
+
+	defer adapter.Close()
+
+	register(migrator)
+	assert.Nil(t, migrator.Migrate(ctx))
+	assert.Nil(t, repo.Insert(ctx, &version{Version: 20200829084200}))
+
+	err := migrator.Rollback(ctx, 1)
+	assert.Equal(t, MigrationError{Version: 20200829084200, Err: ErrNotRegistered}, err)
+	assert.Equal(t, 3, repo.MustCount(ctx, VersionTable))
+}
+
+func TestMigrator_MigrateTo(t *testing.T) {
+	var (
+		adapter  = open(t, "migrate_to")
+		repo     = rel.New(adapter)
+		migrator = New(repo)
+	)
+
+	defer adapter.Close()
+
+	register(migrator)
+
+	// migrate up.
+	assert.Nil(t, migrator.MigrateTo(ctx, 20200829084000))
+	assert.Equal(t, 1, repo.MustCount(ctx, VersionTable))
+	assert.Nil(t, repo.Insert(ctx, &book{Title: "REL for dummies"}))
+
+	assert.Nil(t, migrator.MigrateTo(ctx, 20200829084100))
+	assert.Equal(t, 2, repo.MustCount(ctx, VersionTable))
+	assert.Nil(t, repo.Insert(ctx, &author{Name: "Kia"}))
+
+	// migrate down.
+	assert.Nil(t, migrator.MigrateTo(ctx, 20200829084000))
+	assert.Equal(t, 1, repo.MustCount(ctx, VersionTable))
+	assert.NotNil(t, repo.Insert(ctx, &author{Name: "Kia"}))
+
+	assert.Nil(t, migrator.MigrateTo(ctx, 0))
+	assert.Equal(t, 0, repo.MustCount(ctx, VersionTable))
+}
+
+func TestMigrator_Status(t *testing.T) {
+	var (
+		adapter  = open(t, "status")
+		repo     = rel.New(adapter)
+		migrator = New(repo)
+	)
+
+	defer adapter.Close()
+
+	migrator.Register(20200829084000, "create_books", func(schema *Schema) {
+		schema.CreateTable("books", func(t *Table) {
+			t.ID("id")
+		})
+	}, nil)
+
+	assert.Nil(t, migrator.Migrate(ctx))
+
+	// unregistered version.
+	assert.Nil(t, repo.Insert(ctx, &version{Version: 20200829083000}))
+	migrator.Register(20200829084100, "add_author_to_books", func(schema *Schema) {
+		schema.AddColumn("books", "author", String)
+	}, nil)
+
+	status, err := migrator.Status(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, status, 3)
+
+	assert.Equal(t, int64(20200829083000), status[0].Version)
+	assert.Equal(t, "", status[0].Name)
+	assert.True(t, status[0].Applied)
+
+	assert.Equal(t, int64(20200829084000), status[1].Version)
+	assert.Equal(t, "create_books", status[1].Name)
+	assert.True(t, status[1].Applied)
+	assert.False(t, status[1].AppliedAt.IsZero())
+
+	assert.Equal(t, Status{Version: 20200829084100, Name: "add_author_to_books"}, status[2])
+}
+
+func TestMigrator_versionTable(t *testing.T) {
+	var (
+		adapter   = open(t, "version_table")
+		repo      = rel.New(adapter)
+		primary   = New(repo)
+		analytics = New(repo, VersionTableName("analytics_schema_migrations"))
+	)
+
+	defer adapter.Close()
+
+	register(primary)
+	analytics.Register(20200829084000, "create_events", func(schema *Schema) {
+		schema.CreateTable("events", func(t *Table) {
+			t.ID("id")
+			t.String("name")
+		})
+	}, nil)
+
+	assert.Nil(t, primary.Migrate(ctx))
+	assert.Nil(t, analytics.Migrate(ctx))
+	assert.Equal(t, 2, repo.MustCount(ctx, VersionTable))
+	assert.Equal(t, 1, repo.MustCount(ctx, "analytics_schema_migrations"))
+	assert.Equal(t, 0, repo.MustCount(ctx, "events"))
+
+	status, err := analytics.Status(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []Status{{Version: 20200829084000, Name: "create_events", Applied: true, AppliedAt: status[0].AppliedAt}}, status)
+
+	// rolling back analytics keeps primary migrations.
+	assert.Nil(t, analytics.Rollback(ctx, 1))
+	assert.Equal(t, 0, repo.MustCount(ctx, "analytics_schema_migrations"))
+	assert.Equal(t, 2, repo.MustCount(ctx, VersionTable))
+	assert.Nil(t, repo.Insert(ctx, &book{Title: "REL for dummies"}))
+}
+
+func TestMigrator_Register_duplicate(t *testing.T) {
+	migrator := New(nil)
+	register(migrator)
+
+	assert.Panics(t, func() {
+		migrator.Register(20200829084000, "duplicate", func(schema *Schema) {}, nil)
+	})
+}
+
+func TestMigrator_notSupported(t *testing.T) {
+	var (
+		repo     = reltest.New()
+		migrator = New(repo)
+	)
+
+	register(migrator)
+
+	_, err := migrator.Status(ctx)
+	assert.Equal(t, ErrNotSupported, err)
+}
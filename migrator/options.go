@@ -0,0 +1,202 @@
+package migrator
+
+import (
+	"time"
+)
+
+// MigratorOption interface.
+// Available options are: VersionTableName and LockTimeout.
+type MigratorOption interface {
+	applyMigrator(m *Migrator)
+}
+
+// TableOption interface.
+// Available options are: Optional and Options.
+type TableOption interface {
+	applyTable(table *Table)
+}
+
+func applyTableOptions(table *Table, options []TableOption) {
+	for i := range options {
+		options[i].applyTable(table)
+	}
+}
+
+// ColumnOption interface.
+// Available options are: Unique, Required, Unsigned, Limit, Precision, Scale, Default and Options.
+type ColumnOption interface {
+	applyColumn(column *Column)
+}
+
+func applyColumnOptions(column *Column, options []ColumnOption) {
+	for i := range options {
+		options[i].applyColumn(column)
+	}
+}
+
+// KeyOption interface.
+// Available options are: Name, OnDelete, OnUpdate and Options.
+type KeyOption interface {
+	applyKey(key *Key)
+}
+
+func applyKeyOptions(key *Key, options []KeyOption) {
+	for i := range options {
+		options[i].applyKey(key)
+	}
+}
+
+// IndexOption interface.
+// Available options are: Unique, Where, Concurrent, Optional and Options.
+type IndexOption interface {
+	applyIndex(index *Index)
+}
+
+func applyIndexOptions(index *Index, options []IndexOption) {
+	for i := range options {
+		options[i].applyIndex(index)
+	}
+}
+
+// Optional option.
+// When used with create table or index, the table or index will only be created if it doesn't exist.
+// When used with drop table or index, the table or index will only be dropped if it exists.
+type Optional bool
+
+func (o Optional) applyTable(table *Table) {
+	table.Optional = bool(o)
+}
+
+func (o Optional) applyIndex(index *Index) {
+	index.Optional = bool(o)
+}
+
+// Options to be appended to the end of table, column, index or key definition, eg: `ENGINE=InnoDB`.
+type Options string
+
+func (o Options) applyTable(table *Table) {
+	table.Options = string(o)
+}
+
+func (o Options) applyColumn(column *Column) {
+	column.Options = string(o)
+}
+
+func (o Options) applyIndex(index *Index) {
+	index.Options = string(o)
+}
+
+func (o Options) applyKey(key *Key) {
+	key.Options = string(o)
+}
+
+// Name of the key, it's required to drop the key later.
+type Name string
+
+func (n Name) applyKey(key *Key) {
+	key.Name = string(n)
+}
+
+// OnDelete sets referential action of foreign key when the referenced row is deleted, eg: CASCADE, SET NULL or RESTRICT.
+type OnDelete string
+
+func (od OnDelete) applyKey(key *Key) {
+	key.Reference.OnDelete = string(od)
+}
+
+// OnUpdate sets referential action of foreign key when the referenced row is updated, eg: CASCADE, SET NULL or RESTRICT.
+type OnUpdate string
+
+func (ou OnUpdate) applyKey(key *Key) {
+	key.Reference.OnUpdate = string(ou)
+}
+
+// Unique set column or index as unique.
+type Unique bool
+
+func (u Unique) applyColumn(column *Column) {
+	column.Unique = bool(u)
+}
+
+func (u Unique) applyIndex(index *Index) {
+	index.Unique = bool(u)
+}
+
+// Where sets condition of partial index, eg: `deleted_at IS NULL`.
+// Partial index is supported by PostgreSQL and SQLite3.
+type Where string
+
+func (w Where) applyIndex(index *Index) {
+	index.Filter = string(w)
+}
+
+// Concurrent creates or drops index without locking writes to the table.
+// It's only supported by PostgreSQL, and the migration is applied outside transaction since it can't be run inside transaction.
+type Concurrent bool
+
+func (c Concurrent) applyIndex(index *Index) {
+	index.Concurrent = bool(c)
+}
+
+// Required disallows null values.
+type Required bool
+
+func (r Required) applyColumn(column *Column) {
+	column.Required = bool(r)
+}
+
+// Unsigned sets integer column to be unsigned.
+type Unsigned bool
+
+func (u Unsigned) applyColumn(column *Column) {
+	column.Unsigned = bool(u)
+}
+
+// Limit sets the maximum size of string, binary or integer column.
+type Limit int
+
+func (l Limit) applyColumn(column *Column) {
+	column.Limit = int(l)
+}
+
+// Precision defines the precision of decimal column, which is the number of digits.
+type Precision int
+
+func (p Precision) applyColumn(column *Column) {
+	column.Precision = int(p)
+}
+
+// Scale defines the scale of decimal column, which is the number of digits to the right of the decimal point.
+type Scale int
+
+func (s Scale) applyColumn(column *Column) {
+	column.Scale = int(s)
+}
+
+type defaultValue struct {
+	value interface{}
+}
+
+func (d defaultValue) applyColumn(column *Column) {
+	column.Default = d.value
+}
+
+// Default sets default value of the column.
+func Default(value interface{}) ColumnOption {
+	return defaultValue{value: value}
+}
+
+// VersionTableName sets the name of table used to track applied migrations, default to schema_migrations.
+// Migrators using different version tables apply their migrations independently, even when they share the same database.
+type VersionTableName string
+
+func (vtn VersionTableName) applyMigrator(m *Migrator) {
+	m.table = string(vtn)
+}
+
+// LockTimeout sets the maximum duration to wait for migration lock held by another instance, default to wait until the context is done.
+type LockTimeout time.Duration
+
+func (lt LockTimeout) applyMigrator(m *Migrator) {
+	m.lockTimeout = time.Duration(lt)
+}
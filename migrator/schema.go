@@ -0,0 +1,215 @@
+// Package migrator provides Go DSL to define and apply schema changes using REL's adapter.
+//
+// Usage:
+//	var schema migrator.Schema
+//
+//	schema.CreateTable("books", func(t *migrator.Table) {
+//		t.ID("id")
+//		t.String("title", migrator.Limit(100), migrator.Required(true))
+//		t.Int("author_id", migrator.Unsigned(true))
+//		t.Timestamps()
+//	})
+//
+//	schema.AlterTable("authors", func(t *migrator.AlterTable) {
+//		t.Bool("verified")
+//		t.RenameColumn("name", "full_name")
+//	})
+//
+// Each migration is rendered by the adapter using syntax of its database.
+package migrator
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Fs02/rel"
+)
+
+// SchemaOp defines type of schema operation.
+type SchemaOp uint8
+
+const (
+	// SchemaCreate creates table or adds column.
+	SchemaCreate SchemaOp = iota
+	// SchemaAlter alters table.
+	SchemaAlter
+	// SchemaRename renames table or column.
+	SchemaRename
+	// SchemaDrop drops table or column.
+	SchemaDrop
+)
+
+// Migration is a schema change that is applied by adapter.
+type Migration interface {
+	migration()
+}
+
+// Adapter is implemented by adapter that is able to apply schema migration.
+type Adapter interface {
+	Apply(ctx context.Context, migration Migration) error
+}
+
+var (
+	// ErrNotSupported is returned when adapter doesn't implement Adapter interface.
+	ErrNotSupported = errors.New("migrator: adapter doesn't support schema migration")
+	// ErrIrreversible is returned when migration without down migration can't be inverted automatically.
+	ErrIrreversible = errors.New("migrator: migration is irreversible, down migration must be defined")
+)
+
+// Do is a migration that modifies data using the repository, such as backfilling a new column.
+type Do func(ctx context.Context, repo rel.Repository) error
+
+func (Do) migration() {}
+
+// Schema collects migrations to be applied.
+type Schema struct {
+	Migrations []Migration
+
+	disableTransaction bool
+}
+
+func (s *Schema) add(migration Migration) {
+	s.Migrations = append(s.Migrations, migration)
+}
+
+// CreateTable with name and its definition.
+func (s *Schema) CreateTable(name string, fn func(t *Table), options ...TableOption) {
+	table := createTable(name, options)
+	fn(&table)
+	s.add(table)
+}
+
+// AlterTable with name and its definition.
+func (s *Schema) AlterTable(name string, fn func(t *AlterTable), options ...TableOption) {
+	table := alterTable(name, options)
+	fn(&table)
+	s.add(table.Table)
+}
+
+// RenameTable by name.
+func (s *Schema) RenameTable(name string, newName string, options ...TableOption) {
+	s.add(renameTable(name, newName, options))
+}
+
+// DropTable by name.
+func (s *Schema) DropTable(name string, options ...TableOption) {
+	s.add(dropTable(name, options))
+}
+
+// AddColumn to table.
+func (s *Schema) AddColumn(table string, name string, typ ColumnType, options ...ColumnOption) {
+	at := alterTable(table, nil)
+	at.Column(name, typ, options...)
+	s.add(at.Table)
+}
+
+// RenameColumn of table.
+func (s *Schema) RenameColumn(table string, name string, newName string, options ...ColumnOption) {
+	at := alterTable(table, nil)
+	at.RenameColumn(name, newName, options...)
+	s.add(at.Table)
+}
+
+// DropColumn of table.
+func (s *Schema) DropColumn(table string, name string, options ...ColumnOption) {
+	at := alterTable(table, nil)
+	at.DropColumn(name, options...)
+	s.add(at.Table)
+}
+
+// CreateIndex for columns of the table.
+func (s *Schema) CreateIndex(table string, name string, columns []string, options ...IndexOption) {
+	s.add(createIndex(table, name, columns, options))
+}
+
+// CreateUniqueIndex for columns of the table.
+func (s *Schema) CreateUniqueIndex(table string, name string, columns []string, options ...IndexOption) {
+	s.add(createIndex(table, name, columns, append(options, Unique(true))))
+}
+
+// DropIndex by name.
+func (s *Schema) DropIndex(table string, name string, options ...IndexOption) {
+	s.add(dropIndex(table, name, options))
+}
+
+// Do runs fn to modify data using the repository.
+// Do can't be inverted automatically, thus down migration must be defined to revert it.
+func (s *Schema) Do(fn func(ctx context.Context, repo rel.Repository) error) {
+	s.add(Do(fn))
+}
+
+// DisableTransaction disables transaction used by Migrator to apply this schema.
+// It's useful for long running data migration that commits in batches, or operation that can't be run inside transaction.
+func (s *Schema) DisableTransaction() {
+	s.disableTransaction = true
+}
+
+// transactional returns false when transaction is disabled or the schema contains concurrent index.
+func (s Schema) transactional() bool {
+	if s.disableTransaction {
+		return false
+	}
+
+	for _, migration := range s.Migrations {
+		if index, ok := migration.(Index); ok && index.Concurrent {
+			return false
+		}
+	}
+
+	return true
+}
+
+// invert returns migrations that revert changes made by this schema.
+// Create table, add column, create index and rename can be inverted, while drop can't be inverted since the definition is lost.
+func (s Schema) invert() (Schema, error) {
+	var (
+		result = Schema{Migrations: make([]Migration, 0, len(s.Migrations))}
+	)
+
+	for i := len(s.Migrations) - 1; i >= 0; i-- {
+		var (
+			inverted Migration
+			err      error
+		)
+
+		switch v := s.Migrations[i].(type) {
+		case Table:
+			inverted, err = v.invert()
+		case Index:
+			inverted, err = v.invert()
+		default:
+			err = ErrIrreversible
+		}
+
+		if err != nil {
+			return Schema{}, err
+		}
+
+		result.add(inverted)
+	}
+
+	return result, nil
+}
+
+// Apply migrations collected in the schema using adapter of the repository.
+func (s Schema) Apply(ctx context.Context, repo rel.Repository) error {
+	var adapter Adapter
+	if !rel.AsAdapter(repo.Adapter(), &adapter) {
+		return ErrNotSupported
+	}
+
+	for _, migration := range s.Migrations {
+		var err error
+		if do, ok := migration.(Do); ok {
+			err = do(ctx, repo)
+		} else {
+			err = adapter.Apply(ctx, migration)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
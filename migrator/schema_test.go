@@ -0,0 +1,366 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/reltest"
+	"github.com/stretchr/testify/assert"
+)
+
+type testAdapter struct {
+	rel.Adapter
+	migrations []Migration
+	err        error
+}
+
+func (ta *testAdapter) Apply(ctx context.Context, migration Migration) error {
+	ta.migrations = append(ta.migrations, migration)
+	return ta.err
+}
+
+func TestSchema_CreateTable(t *testing.T) {
+	var schema Schema
+
+	schema.CreateTable("products", func(t *Table) {
+		t.ID("id")
+		t.String("name")
+		t.Text("description")
+	}, Optional(true))
+
+	assert.Equal(t, Table{
+		Op:       SchemaCreate,
+		Name:     "products",
+		Optional: true,
+		Definitions: []TableDefinition{
+			Column{Op: SchemaCreate, Name: "id", Type: ID},
+			Column{Op: SchemaCreate, Name: "name", Type: String},
+			Column{Op: SchemaCreate, Name: "description", Type: Text},
+		},
+	}, schema.Migrations[0])
+}
+
+func TestSchema_AlterTable(t *testing.T) {
+	var schema Schema
+
+	schema.AlterTable("users", func(t *AlterTable) {
+		t.Bool("verified")
+		t.RenameColumn("name", "fullname")
+		t.DropColumn("age")
+	})
+
+	assert.Equal(t, Table{
+		Op:   SchemaAlter,
+		Name: "users",
+		Definitions: []TableDefinition{
+			Column{Op: SchemaCreate, Name: "verified", Type: Bool},
+			Column{Op: SchemaRename, Name: "name", Rename: "fullname"},
+			Column{Op: SchemaDrop, Name: "age"},
+		},
+	}, schema.Migrations[0])
+}
+
+func TestSchema_RenameTable(t *testing.T) {
+	var schema Schema
+
+	schema.RenameTable("trxs", "transactions")
+
+	assert.Equal(t, Table{
+		Op:     SchemaRename,
+		Name:   "trxs",
+		Rename: "transactions",
+	}, schema.Migrations[0])
+}
+
+func TestSchema_DropTable(t *testing.T) {
+	var schema Schema
+
+	schema.DropTable("logs", Optional(true))
+
+	assert.Equal(t, Table{
+		Op:       SchemaDrop,
+		Name:     "logs",
+		Optional: true,
+	}, schema.Migrations[0])
+}
+
+func TestSchema_AddColumn(t *testing.T) {
+	var schema Schema
+
+	schema.AddColumn("products", "description", String, Limit(500))
+
+	assert.Equal(t, Table{
+		Op:   SchemaAlter,
+		Name: "products",
+		Definitions: []TableDefinition{
+			Column{Op: SchemaCreate, Name: "description", Type: String, Limit: 500},
+		},
+	}, schema.Migrations[0])
+}
+
+func TestSchema_RenameColumn(t *testing.T) {
+	var schema Schema
+
+	schema.RenameColumn("users", "name", "fullname")
+
+	assert.Equal(t, Table{
+		Op:   SchemaAlter,
+		Name: "users",
+		Definitions: []TableDefinition{
+			Column{Op: SchemaRename, Name: "name", Rename: "fullname"},
+		},
+	}, schema.Migrations[0])
+}
+
+func TestSchema_DropColumn(t *testing.T) {
+	var schema Schema
+
+	schema.DropColumn("users", "verified")
+
+	assert.Equal(t, Table{
+		Op:   SchemaAlter,
+		Name: "users",
+		Definitions: []TableDefinition{
+			Column{Op: SchemaDrop, Name: "verified"},
+		},
+	}, schema.Migrations[0])
+}
+
+func TestSchema_CreateIndex(t *testing.T) {
+	var schema Schema
+
+	schema.CreateIndex("users", "users_name", []string{"name"}, Optional(true), Options("USING btree"))
+
+	assert.Equal(t, Index{
+		Op:       SchemaCreate,
+		Table:    "users",
+		Name:     "users_name",
+		Columns:  []string{"name"},
+		Optional: true,
+		Options:  "USING btree",
+	}, schema.Migrations[0])
+}
+
+func TestSchema_CreateUniqueIndex(t *testing.T) {
+	var schema Schema
+
+	schema.CreateUniqueIndex("users", "users_email", []string{"lower(email)"}, Where("deleted_at IS NULL"), Concurrent(true))
+
+	assert.Equal(t, Index{
+		Op:         SchemaCreate,
+		Table:      "users",
+		Name:       "users_email",
+		Columns:    []string{"lower(email)"},
+		Unique:     true,
+		Filter:     "deleted_at IS NULL",
+		Concurrent: true,
+	}, schema.Migrations[0])
+	assert.False(t, schema.transactional())
+}
+
+func TestSchema_DropIndex(t *testing.T) {
+	var schema Schema
+
+	schema.DropIndex("users", "users_name", Optional(true))
+
+	assert.Equal(t, Index{
+		Op:       SchemaDrop,
+		Table:    "users",
+		Name:     "users_name",
+		Optional: true,
+	}, schema.Migrations[0])
+	assert.True(t, schema.transactional())
+}
+
+func TestSchema_Do(t *testing.T) {
+	var (
+		schema  Schema
+		called  = false
+		adapter = &testAdapter{}
+		repo    = rel.New(adapter)
+	)
+
+	schema.DropTable("logs")
+	schema.Do(func(ctx context.Context, r rel.Repository) error {
+		called = true
+		assert.Equal(t, repo, r)
+		return nil
+	})
+
+	assert.True(t, schema.transactional())
+	schema.DisableTransaction()
+	assert.False(t, schema.transactional())
+
+	assert.Nil(t, schema.Apply(context.TODO(), repo))
+	assert.True(t, called)
+	assert.Equal(t, schema.Migrations[:1], adapter.migrations)
+}
+
+func TestSchema_Do_error(t *testing.T) {
+	var (
+		schema  Schema
+		err     = errors.New("error")
+		adapter = &testAdapter{}
+	)
+
+	schema.Do(func(ctx context.Context, repo rel.Repository) error {
+		return err
+	})
+	schema.DropTable("logs")
+
+	assert.Equal(t, err, schema.Apply(context.TODO(), rel.New(adapter)))
+	assert.Len(t, adapter.migrations, 0)
+}
+
+func TestSchema_invert(t *testing.T) {
+	var schema Schema
+
+	schema.CreateTable("products", func(t *Table) {
+		t.ID("id")
+	})
+	schema.AlterTable("users", func(t *AlterTable) {
+		t.Bool("verified")
+		t.RenameColumn("name", "fullname")
+		t.ForeignKey("group_id", "groups", "id", Name("users_group_fk"))
+	})
+	schema.RenameTable("trxs", "transactions")
+	schema.CreateIndex("users", "users_name", []string{"name"}, Concurrent(true))
+
+	inverted, err := schema.invert()
+	assert.Nil(t, err)
+	assert.Equal(t, []Migration{
+		Index{Op: SchemaDrop, Table: "users", Name: "users_name", Concurrent: true},
+		Table{Op: SchemaRename, Name: "transactions", Rename: "trxs"},
+		Table{
+			Op:   SchemaAlter,
+			Name: "users",
+			Definitions: []TableDefinition{
+				Key{Op: SchemaDrop, Name: "users_group_fk"},
+				Column{Op: SchemaRename, Name: "fullname", Rename: "name"},
+				Column{Op: SchemaDrop, Name: "verified"},
+			},
+		},
+		Table{Op: SchemaDrop, Name: "products"},
+	}, inverted.Migrations)
+}
+
+func TestSchema_invert_irreversible(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(schema *Schema)
+	}{
+		{
+			name: "DropTable",
+			fn: func(schema *Schema) {
+				schema.DropTable("logs")
+			},
+		},
+		{
+			name: "DropColumn",
+			fn: func(schema *Schema) {
+				schema.DropColumn("users", "verified")
+			},
+		},
+		{
+			name: "UnnamedKey",
+			fn: func(schema *Schema) {
+				schema.AlterTable("users", func(t *AlterTable) {
+					t.Unique([]string{"email"})
+				})
+			},
+		},
+		{
+			name: "DropKey",
+			fn: func(schema *Schema) {
+				schema.AlterTable("users", func(t *AlterTable) {
+					t.DropKey("users_email_key")
+				})
+			},
+		},
+		{
+			name: "DropIndex",
+			fn: func(schema *Schema) {
+				schema.DropIndex("users", "users_name")
+			},
+		},
+		{
+			name: "Do",
+			fn: func(schema *Schema) {
+				schema.Do(func(ctx context.Context, repo rel.Repository) error { return nil })
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var schema Schema
+
+			schema.CreateTable("products", func(t *Table) {
+				t.ID("id")
+			})
+			test.fn(&schema)
+
+			_, err := schema.invert()
+			assert.Equal(t, ErrIrreversible, err)
+		})
+	}
+}
+
+func TestSchema_Apply(t *testing.T) {
+	var (
+		schema  Schema
+		adapter = &testAdapter{}
+		repo    = rel.New(adapter)
+	)
+
+	schema.CreateTable("products", func(t *Table) {
+		t.ID("id")
+	})
+	schema.DropTable("logs")
+
+	assert.Nil(t, schema.Apply(context.TODO(), repo))
+	assert.Equal(t, schema.Migrations, adapter.migrations)
+}
+
+func TestSchema_Apply_wrapped(t *testing.T) {
+	var (
+		schema  Schema
+		adapter = &testAdapter{}
+		repo    = rel.New(rel.WrapAdapter(adapter, func(adapter rel.Adapter) rel.Adapter {
+			return adapter
+		}))
+	)
+
+	schema.DropTable("logs")
+
+	assert.Nil(t, schema.Apply(context.TODO(), repo))
+	assert.Equal(t, schema.Migrations, adapter.migrations)
+}
+
+func TestSchema_Apply_error(t *testing.T) {
+	var (
+		schema  Schema
+		err     = errors.New("error")
+		adapter = &testAdapter{err: err}
+		repo    = rel.New(adapter)
+	)
+
+	schema.DropTable("logs")
+	schema.DropTable("users")
+
+	assert.Equal(t, err, schema.Apply(context.TODO(), repo))
+	assert.Len(t, adapter.migrations, 1)
+}
+
+func TestSchema_Apply_notSupported(t *testing.T) {
+	var (
+		schema Schema
+		repo   = reltest.New()
+	)
+
+	schema.DropTable("logs")
+
+	assert.Equal(t, ErrNotSupported, schema.Apply(context.TODO(), repo))
+}
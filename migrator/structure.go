@@ -0,0 +1,77 @@
+package migrator
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/Fs02/rel"
+)
+
+// StructureAdapter is implemented by adapter that is able to dump and load structure of the database.
+type StructureAdapter interface {
+	DumpStructure(ctx context.Context) (string, error)
+	LoadStructure(ctx context.Context, structure string) error
+}
+
+// Dump writes structure of the database along with applied versions.
+// The dump can be loaded using Load to prepare a fresh database, such as test database, without replaying every migrations.
+func (m *Migrator) Dump(ctx context.Context, w io.Writer) error {
+	var adapter StructureAdapter
+	if !rel.AsAdapter(m.repo.Adapter(), &adapter) {
+		return ErrNotSupported
+	}
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+
+	structure, err := adapter.DumpStructure(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, structure); err != nil {
+		return err
+	}
+
+	if len(applied) == 0 {
+		return nil
+	}
+
+	var (
+		buffer strings.Builder
+	)
+
+	buffer.WriteString("\nINSERT INTO " + m.table + " (version, created_at, updated_at) VALUES\n")
+	for i, v := range applied {
+		if i > 0 {
+			buffer.WriteString(",\n")
+		}
+
+		buffer.WriteString("(" + strconv.FormatInt(v.Version, 10) + ", CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)")
+	}
+
+	buffer.WriteString(";\n")
+
+	_, err = io.WriteString(w, buffer.String())
+	return err
+}
+
+// Load structure written by Dump into an empty database.
+func (m *Migrator) Load(ctx context.Context, r io.Reader) error {
+	var adapter StructureAdapter
+	if !rel.AsAdapter(m.repo.Adapter(), &adapter) {
+		return ErrNotSupported
+	}
+
+	structure, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	return adapter.LoadStructure(ctx, string(structure))
+}
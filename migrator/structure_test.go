@@ -0,0 +1,81 @@
+package migrator_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Fs02/rel"
+	. "github.com/Fs02/rel/migrator"
+	"github.com/Fs02/rel/reltest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrator_Dump(t *testing.T) {
+	var (
+		buffer   bytes.Buffer
+		adapter  = open(t, "dump")
+		repo     = rel.New(adapter)
+		migrator = New(repo)
+	)
+
+	defer adapter.Close()
+
+	register(migrator)
+	assert.Nil(t, migrator.Migrate(ctx))
+	assert.Nil(t, migrator.Dump(ctx, &buffer))
+
+	assert.Contains(t, buffer.String(), "CREATE TABLE `books`")
+	assert.Contains(t, buffer.String(), "CREATE TABLE `authors`")
+	assert.True(t, strings.HasSuffix(buffer.String(), `
+INSERT INTO schema_migrations (version, created_at, updated_at) VALUES
+(20200829084000, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
+(20200829084100, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+`))
+
+	// load into a fresh database.
+	var (
+		fresh         = open(t, "load")
+		freshRepo     = rel.New(fresh)
+		freshMigrator = New(freshRepo)
+	)
+
+	defer fresh.Close()
+
+	register(freshMigrator)
+	assert.Nil(t, freshMigrator.Load(ctx, &buffer))
+	assert.Nil(t, freshRepo.Insert(ctx, &book{Title: "REL for dummies", Author: "Kia"}))
+
+	status, err := freshMigrator.Status(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, status, 2)
+	assert.True(t, status[0].Applied)
+	assert.True(t, status[1].Applied)
+
+	// nothing to migrate.
+	assert.Nil(t, freshMigrator.Migrate(ctx))
+	assert.Equal(t, 2, freshRepo.MustCount(ctx, VersionTable))
+}
+
+func TestMigrator_Dump_empty(t *testing.T) {
+	var (
+		buffer   bytes.Buffer
+		adapter  = open(t, "dump_empty")
+		migrator = New(rel.New(adapter))
+	)
+
+	defer adapter.Close()
+
+	assert.Nil(t, migrator.Dump(ctx, &buffer))
+	assert.NotContains(t, buffer.String(), "INSERT INTO")
+}
+
+func TestMigrator_Dump_notSupported(t *testing.T) {
+	var (
+		buffer   bytes.Buffer
+		migrator = New(reltest.New())
+	)
+
+	assert.Equal(t, ErrNotSupported, migrator.Dump(ctx, &buffer))
+	assert.Equal(t, ErrNotSupported, migrator.Load(ctx, &buffer))
+}
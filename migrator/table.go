@@ -0,0 +1,216 @@
+package migrator
+
+// TableDefinition is a definition of table, such as column.
+type TableDefinition interface {
+	definition()
+}
+
+// Table definition.
+type Table struct {
+	Op          SchemaOp
+	Name        string
+	Rename      string
+	Definitions []TableDefinition
+	Optional    bool
+	Options     string
+}
+
+func (Table) migration() {}
+
+// Column defines a column with name and type.
+func (t *Table) Column(name string, typ ColumnType, options ...ColumnOption) {
+	t.Definitions = append(t.Definitions, createColumn(name, typ, options))
+}
+
+// ID defines auto increment integer primary key.
+func (t *Table) ID(name string, options ...ColumnOption) {
+	t.Column(name, ID, options...)
+}
+
+// BigID defines auto increment big integer primary key.
+func (t *Table) BigID(name string, options ...ColumnOption) {
+	t.Column(name, BigID, options...)
+}
+
+// Bool defines a column with name and Bool type.
+func (t *Table) Bool(name string, options ...ColumnOption) {
+	t.Column(name, Bool, options...)
+}
+
+// Int defines a column with name and Int type.
+func (t *Table) Int(name string, options ...ColumnOption) {
+	t.Column(name, Int, options...)
+}
+
+// BigInt defines a column with name and BigInt type.
+func (t *Table) BigInt(name string, options ...ColumnOption) {
+	t.Column(name, BigInt, options...)
+}
+
+// Float defines a column with name and Float type.
+func (t *Table) Float(name string, options ...ColumnOption) {
+	t.Column(name, Float, options...)
+}
+
+// Decimal defines a column with name and Decimal type.
+func (t *Table) Decimal(name string, options ...ColumnOption) {
+	t.Column(name, Decimal, options...)
+}
+
+// String defines a column with name and String type.
+func (t *Table) String(name string, options ...ColumnOption) {
+	t.Column(name, String, options...)
+}
+
+// Text defines a column with name and Text type.
+func (t *Table) Text(name string, options ...ColumnOption) {
+	t.Column(name, Text, options...)
+}
+
+// Date defines a column with name and Date type.
+func (t *Table) Date(name string, options ...ColumnOption) {
+	t.Column(name, Date, options...)
+}
+
+// DateTime defines a column with name and DateTime type.
+func (t *Table) DateTime(name string, options ...ColumnOption) {
+	t.Column(name, DateTime, options...)
+}
+
+// Time defines a column with name and Time type.
+func (t *Table) Time(name string, options ...ColumnOption) {
+	t.Column(name, Time, options...)
+}
+
+// Timestamp defines a column with name and Timestamp type.
+func (t *Table) Timestamp(name string, options ...ColumnOption) {
+	t.Column(name, Timestamp, options...)
+}
+
+// PrimaryKey defines primary key of the table using a column.
+func (t *Table) PrimaryKey(column string, options ...KeyOption) {
+	t.PrimaryKeys([]string{column}, options...)
+}
+
+// PrimaryKeys defines composite primary key of the table.
+func (t *Table) PrimaryKeys(columns []string, options ...KeyOption) {
+	t.Definitions = append(t.Definitions, createKey(columns, PrimaryKey, options))
+}
+
+// ForeignKey defines foreign key that references column of other table.
+func (t *Table) ForeignKey(column string, refTable string, refColumn string, options ...KeyOption) {
+	t.Definitions = append(t.Definitions, createForeignKey(column, refTable, refColumn, options))
+}
+
+// Unique defines unique constraint of the columns.
+func (t *Table) Unique(columns []string, options ...KeyOption) {
+	t.Definitions = append(t.Definitions, createKey(columns, UniqueKey, options))
+}
+
+// Check defines check constraint using an expression, eg: `price >= 0`.
+func (t *Table) Check(expression string, options ...KeyOption) {
+	t.Definitions = append(t.Definitions, createCheck(expression, options))
+}
+
+// Timestamps defines created_at and updated_at column.
+func (t *Table) Timestamps() {
+	t.DateTime("created_at")
+	t.DateTime("updated_at")
+}
+
+// AlterTable definition.
+type AlterTable struct {
+	Table
+}
+
+// RenameColumn to a new name.
+func (at *AlterTable) RenameColumn(name string, newName string, options ...ColumnOption) {
+	at.Definitions = append(at.Definitions, renameColumn(name, newName, options))
+}
+
+// DropColumn by name.
+func (at *AlterTable) DropColumn(name string, options ...ColumnOption) {
+	at.Definitions = append(at.Definitions, dropColumn(name, options))
+}
+
+// DropKey by name.
+func (at *AlterTable) DropKey(name string, options ...KeyOption) {
+	at.Definitions = append(at.Definitions, dropKey(name, options))
+}
+
+func (t Table) invert() (Table, error) {
+	switch t.Op {
+	case SchemaCreate:
+		return dropTable(t.Name, nil), nil
+	case SchemaAlter:
+		at := alterTable(t.Name, nil)
+		for i := len(t.Definitions) - 1; i >= 0; i-- {
+			var (
+				inverted TableDefinition
+				err      error
+			)
+
+			switch v := t.Definitions[i].(type) {
+			case Column:
+				inverted, err = v.invert()
+			case Key:
+				inverted, err = v.invert()
+			default:
+				err = ErrIrreversible
+			}
+
+			if err != nil {
+				return Table{}, err
+			}
+
+			at.Definitions = append(at.Definitions, inverted)
+		}
+
+		return at.Table, nil
+	case SchemaRename:
+		return renameTable(t.Rename, t.Name, nil), nil
+	default:
+		return Table{}, ErrIrreversible
+	}
+}
+
+func createTable(name string, options []TableOption) Table {
+	table := Table{
+		Op:   SchemaCreate,
+		Name: name,
+	}
+
+	applyTableOptions(&table, options)
+	return table
+}
+
+func alterTable(name string, options []TableOption) AlterTable {
+	table := Table{
+		Op:   SchemaAlter,
+		Name: name,
+	}
+
+	applyTableOptions(&table, options)
+	return AlterTable{Table: table}
+}
+
+func renameTable(name string, newName string, options []TableOption) Table {
+	table := Table{
+		Op:     SchemaRename,
+		Name:   name,
+		Rename: newName,
+	}
+
+	applyTableOptions(&table, options)
+	return table
+}
+
+func dropTable(name string, options []TableOption) Table {
+	table := Table{
+		Op:   SchemaDrop,
+		Name: name,
+	}
+
+	applyTableOptions(&table, options)
+	return table
+}
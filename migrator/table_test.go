@@ -0,0 +1,158 @@
+package migrator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTable(t *testing.T) {
+	var (
+		now   = time.Now()
+		table = createTable("table", []TableOption{Options("ENGINE=InnoDB")})
+	)
+
+	t.Run("Column", func(t *testing.T) {
+		table.Column("column", Decimal, Precision(10), Scale(2), Required(true), Default(0))
+		assert.Equal(t, Column{
+			Name:      "column",
+			Type:      Decimal,
+			Precision: 10,
+			Scale:     2,
+			Required:  true,
+			Default:   0,
+		}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("ID", func(t *testing.T) {
+		table.ID("id")
+		assert.Equal(t, Column{Name: "id", Type: ID}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("BigID", func(t *testing.T) {
+		table.BigID("big_id")
+		assert.Equal(t, Column{Name: "big_id", Type: BigID}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("Bool", func(t *testing.T) {
+		table.Bool("boolean", Default(false))
+		assert.Equal(t, Column{Name: "boolean", Type: Bool, Default: false}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("Int", func(t *testing.T) {
+		table.Int("integer", Unsigned(true), Limit(11))
+		assert.Equal(t, Column{Name: "integer", Type: Int, Unsigned: true, Limit: 11}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("BigInt", func(t *testing.T) {
+		table.BigInt("bigint")
+		assert.Equal(t, Column{Name: "bigint", Type: BigInt}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("Float", func(t *testing.T) {
+		table.Float("float", Precision(24))
+		assert.Equal(t, Column{Name: "float", Type: Float, Precision: 24}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("Decimal", func(t *testing.T) {
+		table.Decimal("decimal")
+		assert.Equal(t, Column{Name: "decimal", Type: Decimal}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("String", func(t *testing.T) {
+		table.String("string", Limit(100), Unique(true))
+		assert.Equal(t, Column{Name: "string", Type: String, Limit: 100, Unique: true}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("Text", func(t *testing.T) {
+		table.Text("text", Options("COLLATE utf8mb4_unicode_ci"))
+		assert.Equal(t, Column{Name: "text", Type: Text, Options: "COLLATE utf8mb4_unicode_ci"}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("Date", func(t *testing.T) {
+		table.Date("date")
+		assert.Equal(t, Column{Name: "date", Type: Date}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("DateTime", func(t *testing.T) {
+		table.DateTime("datetime", Default(now))
+		assert.Equal(t, Column{Name: "datetime", Type: DateTime, Default: now}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("Time", func(t *testing.T) {
+		table.Time("time")
+		assert.Equal(t, Column{Name: "time", Type: Time}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("Timestamp", func(t *testing.T) {
+		table.Timestamp("timestamp")
+		assert.Equal(t, Column{Name: "timestamp", Type: Timestamp}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("PrimaryKey", func(t *testing.T) {
+		table.PrimaryKey("id", Name("table_pk"))
+		assert.Equal(t, Key{Name: "table_pk", Type: PrimaryKey, Columns: []string{"id"}}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("PrimaryKeys", func(t *testing.T) {
+		table.PrimaryKeys([]string{"order_id", "product_id"})
+		assert.Equal(t, Key{Type: PrimaryKey, Columns: []string{"order_id", "product_id"}}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("ForeignKey", func(t *testing.T) {
+		table.ForeignKey("user_id", "users", "id", OnDelete("CASCADE"), OnUpdate("SET NULL"), Options("DEFERRABLE"))
+		assert.Equal(t, Key{
+			Type:    ForeignKey,
+			Columns: []string{"user_id"},
+			Reference: ForeignKeyReference{
+				Table:    "users",
+				Columns:  []string{"id"},
+				OnDelete: "CASCADE",
+				OnUpdate: "SET NULL",
+			},
+			Options: "DEFERRABLE",
+		}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("Unique", func(t *testing.T) {
+		table.Unique([]string{"email"})
+		assert.Equal(t, Key{Type: UniqueKey, Columns: []string{"email"}}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("Check", func(t *testing.T) {
+		table.Check("price >= 0", Name("price_check"))
+		assert.Equal(t, Key{Name: "price_check", Type: CheckKey, Expression: "price >= 0"}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("Timestamps", func(t *testing.T) {
+		table.Timestamps()
+		assert.Equal(t, []TableDefinition{
+			Column{Name: "created_at", Type: DateTime},
+			Column{Name: "updated_at", Type: DateTime},
+		}, table.Definitions[len(table.Definitions)-2:])
+	})
+
+	assert.Equal(t, "ENGINE=InnoDB", table.Options)
+}
+
+func TestAlterTable(t *testing.T) {
+	var (
+		table = alterTable("table", nil)
+	)
+
+	t.Run("RenameColumn", func(t *testing.T) {
+		table.RenameColumn("column", "new_column")
+		assert.Equal(t, Column{Op: SchemaRename, Name: "column", Rename: "new_column"}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("DropColumn", func(t *testing.T) {
+		table.DropColumn("column")
+		assert.Equal(t, Column{Op: SchemaDrop, Name: "column"}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("DropKey", func(t *testing.T) {
+		table.DropKey("table_fk")
+		assert.Equal(t, Key{Op: SchemaDrop, Name: "table_fk"}, table.Definitions[len(table.Definitions)-1])
+	})
+}
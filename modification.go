@@ -34,10 +34,12 @@ type AssocModification struct {
 // Modification represents value to be inserted or updated to database.
 // It's not safe to be used multiple time. some operation my alter modification data.
 type Modification struct {
-	Modifies map[string]Modify
-	Assoc    map[string]AssocModification
-	Unscoped Unscoped
-	Reload   bool
+	Modifies     map[string]Modify
+	Assoc        map[string]AssocModification
+	Replacements map[string]Replacement
+	Unscoped     Unscoped
+	Reload       bool
+	OnConflict   OnConflict
 }
 
 // Add a modify.
@@ -60,6 +62,15 @@ func (m *Modification) SetDeletedIDs(field string, ids []interface{}) {
 	m.Assoc[field] = assoc
 }
 
+// SetReplacement of has many association, it overrides replacement declared using tag.
+func (m *Modification) SetReplacement(field string, replacement Replacement) {
+	if m.Replacements == nil {
+		m.Replacements = make(map[string]Replacement)
+	}
+
+	m.Replacements[field] = replacement
+}
+
 // ChangeOp represents type of modify operation.
 type ChangeOp int
 
@@ -167,3 +178,22 @@ type Reload bool
 func (r Reload) Apply(doc *Document, modification *Modification) {
 	modification.Reload = bool(r)
 }
+
+type replace struct {
+	field       string
+	replacement Replacement
+}
+
+// Apply modification.
+func (r replace) Apply(doc *Document, modification *Modification) {
+	modification.SetReplacement(r.field, r.replacement)
+}
+
+// Replace sets the replacement strategy of has many association for the operation.
+// It's used along with other modifier that modifies the association, such as structset.
+//
+// Example:
+//	repo.Update(ctx, &user, rel.NewStructset(&user, false), rel.Replace("transactions", rel.ReplaceDelete))
+func Replace(field string, replacement Replacement) Modifier {
+	return replace{field: field, replacement: replacement}
+}
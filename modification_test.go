@@ -71,6 +71,28 @@ func TestApplyModification_Reload(t *testing.T) {
 	assert.Equal(t, "string", record.Field1)
 }
 
+func TestApplyModification_Replace(t *testing.T) {
+	var (
+		record    = TestRecord{}
+		doc       = NewDocument(&record)
+		modifiers = []Modifier{
+			Set("field1", "string"),
+			Replace("children", ReplaceNullify),
+		}
+		modification = Modification{
+			Modifies: map[string]Modify{
+				"field1": Set("field1", "string"),
+			},
+			Assoc: map[string]AssocModification{},
+			Replacements: map[string]Replacement{
+				"children": ReplaceNullify,
+			},
+		}
+	)
+
+	assert.Equal(t, modification, Apply(doc, modifiers...))
+}
+
 func TestApplyModification_setValueError(t *testing.T) {
 	var (
 		record = TestRecord{}
@@ -0,0 +1,77 @@
+package rel
+
+import (
+	"reflect"
+	"sync"
+)
+
+var polymorphicTypes sync.Map
+
+// Polymorphic registers types of record that can be assigned to interface field of polymorphic association.
+// The type is identified by its table name, which is the value stored in the type column of the association.
+//
+// Example:
+//	type Comment struct {
+//		ID              int
+//		CommentableType string
+//		CommentableID   int
+//		Commentable     interface{} `polymorphic:"commentable"`
+//	}
+//
+//	rel.Polymorphic(Post{}, Video{})
+func Polymorphic(records ...interface{}) {
+	for _, record := range records {
+		var (
+			rt = reflect.TypeOf(record)
+		)
+
+		if rt.Kind() == reflect.Ptr {
+			rt = rt.Elem()
+		}
+
+		polymorphicTypes.Store(NewDocument(reflect.New(rt).Interface()).Table(), rt)
+	}
+}
+
+func polymorphicType(table string) reflect.Type {
+	if rt, ok := polymorphicTypes.Load(table); ok {
+		return rt.(reflect.Type)
+	}
+
+	panic("rel: polymorphic type " + table + " is not registered")
+}
+
+type polymorphicData struct {
+	typeField string
+	idField   string
+	rt        reflect.Type
+}
+
+// extractPolymorphicData of struct field tagged with polymorphic, eg: `polymorphic:"owner"`.
+// The tag value is prefix of type and id field of the association, it defaults to the field name.
+// Target type is nil when the field is an interface, and resolved from registered types when loaded.
+func extractPolymorphicData(rt reflect.Type, index int) polymorphicData {
+	var (
+		sf     = rt.Field(index)
+		ft     = sf.Type
+		prefix = sf.Tag.Get("polymorphic")
+		data   polymorphicData
+	)
+
+	if prefix == "" {
+		prefix = fieldName(sf)
+	}
+
+	data.typeField = prefix + "_type"
+	data.idField = prefix + "_id"
+
+	if ft.Kind() == reflect.Ptr {
+		ft = ft.Elem()
+	}
+
+	if ft.Kind() == reflect.Struct {
+		data.rt = ft
+	}
+
+	return data
+}
@@ -0,0 +1,93 @@
+package rel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type Post struct {
+	ID    int
+	Title string
+}
+
+type Video struct {
+	ID    int
+	Title string
+}
+
+type Comment struct {
+	ID              int
+	Body            string
+	CommentableType string
+	CommentableID   int
+	Commentable     interface{} `polymorphic:"commentable"`
+}
+
+type PostComment struct {
+	ID              int
+	CommentableType string
+	CommentableID   int
+	Post            *Post `polymorphic:"commentable"`
+}
+
+func TestPolymorphic(t *testing.T) {
+	Polymorphic(Post{}, &Video{})
+
+	assert.Equal(t, reflect.TypeOf(Post{}), polymorphicType("posts"))
+	assert.Equal(t, reflect.TypeOf(Video{}), polymorphicType("videos"))
+	assert.Panics(t, func() {
+		polymorphicType("photos")
+	})
+}
+
+func TestExtractPolymorphicData(t *testing.T) {
+	tests := []struct {
+		record interface{}
+		index  int
+		data   polymorphicData
+	}{
+		{
+			record: Comment{},
+			index:  4,
+			data:   polymorphicData{typeField: "commentable_type", idField: "commentable_id"},
+		},
+		{
+			record: PostComment{},
+			index:  3,
+			data:   polymorphicData{typeField: "commentable_type", idField: "commentable_id", rt: reflect.TypeOf(Post{})},
+		},
+		{
+			record: struct {
+				OwnerType string
+				OwnerID   int
+				Owner     User `polymorphic:""`
+			}{},
+			index: 2,
+			data:  polymorphicData{typeField: "owner_type", idField: "owner_id", rt: reflect.TypeOf(User{})},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(reflect.TypeOf(test.record).String(), func(t *testing.T) {
+			assert.Equal(t, test.data, extractPolymorphicData(reflect.TypeOf(test.record), test.index))
+		})
+	}
+}
+
+func TestDocument_Polymorphic(t *testing.T) {
+	var (
+		comment = Comment{Commentable: &Post{ID: 1}}
+		doc     = NewDocument(&comment)
+	)
+
+	assert.Equal(t, []string{"id", "body", "commentable_type", "commentable_id"}, doc.Fields())
+	assert.Equal(t, []string{"commentable"}, doc.Polymorphic())
+	assert.Nil(t, doc.HasOne())
+	assert.Nil(t, doc.BelongsTo())
+
+	value, ok := doc.Value("commentable")
+	assert.True(t, ok)
+	assert.Equal(t, &Post{ID: 1}, value)
+}
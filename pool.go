@@ -0,0 +1,60 @@
+package rel
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// PoolHooks are called on connection lifecycle events by adapter that maintains connection pool, nil hook is ignored.
+// OnAcquire is called after a connection is acquired along with the time it took, OnRelease is called when the connection is returned along with the time it was held,
+// OnExhausted is called before acquiring when every connection in the pool is in use, and OnWait is called after acquiring from the exhausted pool along with the time spent waiting.
+//
+// Example:
+//	adapter.SetPoolHooks(rel.PoolHooks{
+//		OnExhausted: func(ctx context.Context, stats sql.DBStats) {
+//			alert("connection pool exhausted", stats.InUse, stats.WaitCount)
+//		},
+//	})
+type PoolHooks struct {
+	OnAcquire   func(ctx context.Context, duration time.Duration)
+	OnRelease   func(ctx context.Context, duration time.Duration)
+	OnWait      func(ctx context.Context, duration time.Duration)
+	OnExhausted func(ctx context.Context, stats sql.DBStats)
+}
+
+// Acquire a connection using acquire function from the pool that has the stats, and calls the hooks.
+// The returned function must be called when the connection is returned to the pool.
+// This function intended to be used within adapter.
+func (ph PoolHooks) Acquire(ctx context.Context, stats sql.DBStats, acquire func() error) (func(), error) {
+	var (
+		exhausted = stats.MaxOpenConnections > 0 && stats.InUse >= stats.MaxOpenConnections
+	)
+
+	if exhausted && ph.OnExhausted != nil {
+		ph.OnExhausted(ctx, stats)
+	}
+
+	start := time.Now()
+	err := acquire()
+	duration := time.Since(start)
+
+	if exhausted && ph.OnWait != nil {
+		ph.OnWait(ctx, duration)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if ph.OnAcquire != nil {
+		ph.OnAcquire(ctx, duration)
+	}
+
+	acquired := time.Now()
+	return func() {
+		if ph.OnRelease != nil {
+			ph.OnRelease(ctx, time.Since(acquired))
+		}
+	}, nil
+}
@@ -0,0 +1,89 @@
+package rel
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func recordPoolHooks(events *[]string) PoolHooks {
+	return PoolHooks{
+		OnAcquire: func(context.Context, time.Duration) {
+			*events = append(*events, "acquire")
+		},
+		OnRelease: func(context.Context, time.Duration) {
+			*events = append(*events, "release")
+		},
+		OnWait: func(context.Context, time.Duration) {
+			*events = append(*events, "wait")
+		},
+		OnExhausted: func(context.Context, sql.DBStats) {
+			*events = append(*events, "exhausted")
+		},
+	}
+}
+
+func TestPoolHooks_Acquire(t *testing.T) {
+	var (
+		events []string
+		hooks  = recordPoolHooks(&events)
+	)
+
+	release, err := hooks.Acquire(context.TODO(), sql.DBStats{MaxOpenConnections: 2, InUse: 1}, func() error {
+		events = append(events, "connect")
+		return nil
+	})
+
+	assert.Nil(t, err)
+	release()
+	assert.Equal(t, []string{"connect", "acquire", "release"}, events)
+}
+
+func TestPoolHooks_Acquire_exhausted(t *testing.T) {
+	var (
+		events []string
+		hooks  = recordPoolHooks(&events)
+	)
+
+	release, err := hooks.Acquire(context.TODO(), sql.DBStats{MaxOpenConnections: 2, InUse: 2}, func() error {
+		events = append(events, "connect")
+		return nil
+	})
+
+	assert.Nil(t, err)
+	release()
+	assert.Equal(t, []string{"exhausted", "connect", "wait", "acquire", "release"}, events)
+}
+
+func TestPoolHooks_Acquire_error(t *testing.T) {
+	var (
+		events []string
+		hooks  = recordPoolHooks(&events)
+		err    = errors.New("context deadline exceeded")
+	)
+
+	release, rerr := hooks.Acquire(context.TODO(), sql.DBStats{MaxOpenConnections: 1, InUse: 1}, func() error {
+		return err
+	})
+
+	assert.Nil(t, release)
+	assert.Equal(t, err, rerr)
+	assert.Equal(t, []string{"exhausted", "wait"}, events)
+}
+
+func TestPoolHooks_Acquire_nil(t *testing.T) {
+	var (
+		hooks PoolHooks
+	)
+
+	release, err := hooks.Acquire(context.TODO(), sql.DBStats{MaxOpenConnections: 1, InUse: 1}, func() error {
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.NotPanics(t, release)
+}
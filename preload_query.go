@@ -0,0 +1,26 @@
+package rel
+
+// PreloadQuery defines association to be preloaded after the records are found.
+type PreloadQuery struct {
+	Field    string
+	Queriers []Querier
+}
+
+// Build query.
+func (pq PreloadQuery) Build(query *Query) {
+	query.PreloadQuery = append(query.PreloadQuery, pq)
+}
+
+// Preload association after the records are found using the queriers, which is equivalent to calling Preload on the found records.
+//
+// Example:
+//	repo.FindAll(ctx, &users, rel.Preload("address"), rel.Preload("transactions", where.Eq("paid", true)))
+//
+//	// as chainable query.
+//	repo.FindAll(ctx, &users, rel.From("users").Preload("address").Preload("transactions"))
+func Preload(field string, queriers ...Querier) PreloadQuery {
+	return PreloadQuery{
+		Field:    field,
+		Queriers: queriers,
+	}
+}
@@ -22,6 +22,10 @@ func Build(table string, queriers ...Querier) Query {
 			q.Build(&query)
 		case JoinQuery:
 			q.Build(&query)
+		case EagerQuery:
+			q.Build(&query)
+		case PreloadQuery:
+			q.Build(&query)
 		case FilterQuery:
 			q.Build(&query)
 		case GroupQuery:
@@ -32,10 +36,16 @@ func Build(table string, queriers ...Querier) Query {
 			q.Build(&query)
 		case Limit:
 			q.Build(&query)
+		case Partition:
+			q.Build(&query)
 		case Lock:
 			q.Build(&query)
 		case Unscoped:
 			q.Build(&query)
+		case ReadFromPrimary:
+			q.Build(&query)
+		case OnConflict:
+			q.Build(&query)
 		}
 	}
 
@@ -52,17 +62,23 @@ func Build(table string, queriers ...Querier) Query {
 
 // Query defines information about query generated by query builder.
 type Query struct {
-	empty         bool // todo: use bit to mark what is updated and use it when building
-	Table         string
-	SelectQuery   SelectQuery
-	JoinQuery     []JoinQuery
-	WhereQuery    FilterQuery
-	GroupQuery    GroupQuery
-	SortQuery     []SortQuery
-	OffsetQuery   Offset
-	LimitQuery    Limit
-	LockQuery     Lock
-	UnscopedQuery Unscoped
+	empty          bool // todo: use bit to mark what is updated and use it when building
+	Table          string
+	SelectQuery    SelectQuery
+	JoinQuery      []JoinQuery
+	EagerQuery     EagerQuery
+	PreloadQuery   []PreloadQuery
+	WhereQuery     FilterQuery
+	GroupQuery     GroupQuery
+	SortQuery      []SortQuery
+	OffsetQuery    Offset
+	LimitQuery     Limit
+	PartitionQuery Partition
+	LockQuery      Lock
+	UnscopedQuery  Unscoped
+
+	ReadFromPrimaryQuery ReadFromPrimary
+	OnConflictQuery      OnConflict
 }
 
 // Build query.
@@ -80,14 +96,18 @@ func (q Query) Build(query *Query) {
 		}
 
 		query.JoinQuery = append(query.JoinQuery, q.JoinQuery...)
+		query.EagerQuery = append(query.EagerQuery, q.EagerQuery...)
+		query.PreloadQuery = append(query.PreloadQuery, q.PreloadQuery...)
 
-		query.WhereQuery = query.WhereQuery.And(q.WhereQuery)
+		if !q.WhereQuery.None() {
+			query.WhereQuery = query.WhereQuery.And(q.WhereQuery)
+		}
 
 		if q.GroupQuery.Fields != nil {
 			query.GroupQuery = q.GroupQuery
 		}
 
-		q.SortQuery = append(q.SortQuery, query.SortQuery...)
+		query.SortQuery = append(query.SortQuery, q.SortQuery...)
 
 		if q.OffsetQuery != 0 {
 			query.OffsetQuery = q.OffsetQuery
@@ -97,9 +117,21 @@ func (q Query) Build(query *Query) {
 			query.LimitQuery = q.LimitQuery
 		}
 
+		if q.PartitionQuery.Limit != 0 {
+			query.PartitionQuery = q.PartitionQuery
+		}
+
 		if q.LockQuery != "" {
 			query.LockQuery = q.LockQuery
 		}
+
+		if q.ReadFromPrimaryQuery {
+			query.ReadFromPrimaryQuery = true
+		}
+
+		if q.OnConflictQuery != "" {
+			query.OnConflictQuery = q.OnConflictQuery
+		}
 	}
 }
 
@@ -145,6 +177,18 @@ func (q Query) Joinf(expr string, args ...interface{}) Query {
 	return q
 }
 
+// Eager loads belongs to and has one associations along with the records using left join.
+func (q Query) Eager(fields ...string) Query {
+	q.EagerQuery = append(q.EagerQuery, fields...)
+	return q
+}
+
+// Preload association after the records are found.
+func (q Query) Preload(field string, queriers ...Querier) Query {
+	q.PreloadQuery = append(q.PreloadQuery, Preload(field, queriers...))
+	return q
+}
+
 // Where query.
 func (q Query) Where(filters ...FilterQuery) Query {
 	q.WhereQuery = q.WhereQuery.And(filters...)
@@ -244,6 +288,12 @@ func (q Query) Limit(limit Limit) Query {
 	return q
 }
 
+// Partition limits records returned for each distinct value of the field, records are ranked using sort query.
+func (q Query) Partition(field string, limit int) Query {
+	q.PartitionQuery = Partition{Field: field, Limit: limit}
+	return q
+}
+
 // Lock query expression.
 func (q Query) Lock(lock Lock) Query {
 	q.LockQuery = lock
@@ -256,6 +306,18 @@ func (q Query) Unscoped() Query {
 	return q
 }
 
+// ReadFromPrimary forces query to be executed on primary database when replication is used.
+func (q Query) ReadFromPrimary() Query {
+	q.ReadFromPrimaryQuery = true
+	return q
+}
+
+// OnConflict sets conflict resolution used when inserting records.
+func (q Query) OnConflict(onConflict OnConflict) Query {
+	q.OnConflictQuery = onConflict
+	return q
+}
+
 // Select query create a query with chainable syntax, using select as the starting point.
 func Select(fields ...string) Query {
 	return Query{
@@ -332,6 +394,32 @@ func (l Limit) Build(query *Query) {
 	query.LimitQuery = l
 }
 
+// Partition query limits records returned for each distinct value of the field, records are ranked using sort query.
+// Partition requires adapter that supports window function.
+//
+// Example:
+//	// latest 3 transactions of each user.
+//	repo.FindAll(ctx, &transactions, rel.Partition{Field: "user_id", Limit: 3}, rel.NewSortDesc("created_at"))
+type Partition struct {
+	Field string
+	Limit int
+}
+
+// Build query.
+func (p Partition) Build(query *Query) {
+	query.PartitionQuery = p
+}
+
+// PreloadLimit limits associated records loaded by preload for each record, records are ranked using sort query of the preload.
+// Window function is used when supported by adapter, otherwise the records are limited after loaded.
+//
+// Example:
+//	// latest 3 transactions of each user.
+//	repo.Preload(ctx, &users, "transactions", rel.PreloadLimit(3), rel.NewSortDesc("created_at"))
+func PreloadLimit(limit int) Partition {
+	return Partition{Limit: limit}
+}
+
 // Lock query.
 // This query will be ignored if used outside of transaction.
 type Lock string
@@ -358,3 +446,42 @@ func (u Unscoped) Build(query *Query) {
 func (u Unscoped) Apply(doc *Document, modification *Modification) {
 	modification.Unscoped = u
 }
+
+// ReadFromPrimary query.
+// This query can be used to read your own writes when replication is used.
+type ReadFromPrimary bool
+
+// Build query.
+func (rfp ReadFromPrimary) Build(query *Query) {
+	query.ReadFromPrimaryQuery = rfp
+}
+
+// OnConflict query.
+// It resolves conflict with existing record when inserting records, and requires adapter that supports it.
+type OnConflict string
+
+// Build query.
+func (oc OnConflict) Build(query *Query) {
+	query.OnConflictQuery = oc
+}
+
+// Apply modification.
+func (oc OnConflict) Apply(doc *Document, modification *Modification) {
+	modification.OnConflict = oc
+}
+
+// OnConflictIgnore keeps existing record when inserted record conflicts with it.
+//
+// Example:
+//	repo.Insert(ctx, &user, rel.NewStructset(&user, false), rel.OnConflictIgnore())
+func OnConflictIgnore() OnConflict {
+	return "IGNORE"
+}
+
+// OnConflictReplace replaces fields of existing record using inserted values when inserted record conflicts with it.
+//
+// Example:
+//	repo.InsertAll(ctx, &users, rel.OnConflictReplace())
+func OnConflictReplace() OnConflict {
+	return "REPLACE"
+}
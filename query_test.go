@@ -136,6 +136,14 @@ func TestQuery_Build(t *testing.T) {
 	assert.Equal(t, q, rel.Build("", q))
 }
 
+func TestQuery_Build_merge(t *testing.T) {
+	query := rel.Build("comments", rel.Where(rel.Eq("published", true)).SortDesc("created_at"), rel.Select("id", "body").SortAsc("id"))
+
+	assert.Equal(t, rel.Eq("published", true), query.WhereQuery)
+	assert.Equal(t, []string{"id", "body"}, query.SelectQuery.Fields)
+	assert.Equal(t, []rel.SortQuery{rel.NewSortDesc("created_at"), rel.NewSortAsc("id")}, query.SortQuery)
+}
+
 func TestQuery_Select(t *testing.T) {
 	assert.Equal(t, rel.Query{
 		Table: "users",
@@ -617,9 +625,72 @@ func TestQuery_Limit(t *testing.T) {
 	}, rel.From("users").Limit(10))
 }
 
+func TestQuery_Eager(t *testing.T) {
+	result := rel.Query{
+		Table:      "transactions",
+		EagerQuery: rel.EagerQuery{"buyer", "address"},
+	}
+
+	assert.Equal(t, result, rel.From("transactions").Eager("buyer").Eager("address"))
+	assert.Equal(t, result, rel.Build("transactions", rel.Eager("buyer", "address")))
+	assert.Equal(t, result, rel.Build("", rel.From("transactions").Eager("buyer"), rel.Eager("address")))
+}
+
+func TestQuery_Preload(t *testing.T) {
+	result := rel.Query{
+		Table: "users",
+		PreloadQuery: []rel.PreloadQuery{
+			{Field: "address"},
+			{Field: "transactions", Queriers: []rel.Querier{where.Eq("paid", true)}},
+		},
+	}
+
+	assert.Equal(t, result, rel.From("users").Preload("address").Preload("transactions", where.Eq("paid", true)))
+	assert.Equal(t, result, rel.Build("users", rel.Preload("address"), rel.Preload("transactions", where.Eq("paid", true))))
+	assert.Equal(t, result, rel.Build("", rel.From("users").Preload("address"), rel.Preload("transactions", where.Eq("paid", true))))
+}
+
+func TestQuery_Partition(t *testing.T) {
+	result := rel.Query{
+		Table:          "transactions",
+		PartitionQuery: rel.Partition{Field: "user_id", Limit: 3},
+	}
+
+	assert.Equal(t, result, rel.From("transactions").Partition("user_id", 3))
+	assert.Equal(t, result, rel.Build("transactions", rel.Partition{Field: "user_id", Limit: 3}))
+	assert.Equal(t, result, rel.Build("", rel.From("transactions"), rel.From("users").Partition("user_id", 3)).From("transactions"))
+	assert.Equal(t, rel.Partition{Limit: 3}, rel.PreloadLimit(3))
+}
+
 func TestQuery_Lock_outsideTransaction(t *testing.T) {
 	assert.Equal(t, rel.Query{
 		Table:     "users",
 		LockQuery: "FOR UPDATE",
 	}, rel.From("users").Lock(rel.ForUpdate()))
 }
+
+func TestQuery_ReadFromPrimary(t *testing.T) {
+	var (
+		result = rel.Query{
+			Table:                "users",
+			ReadFromPrimaryQuery: true,
+		}
+	)
+
+	assert.Equal(t, result, rel.From("users").ReadFromPrimary())
+	assert.Equal(t, result, rel.Build("users", rel.ReadFromPrimary(true)))
+	assert.Equal(t, result, rel.Build("", rel.From("users"), rel.From("").ReadFromPrimary()))
+}
+
+func TestQuery_OnConflict(t *testing.T) {
+	var (
+		result = rel.Query{
+			Table:           "users",
+			OnConflictQuery: rel.OnConflictReplace(),
+		}
+	)
+
+	assert.Equal(t, result, rel.From("users").OnConflict(rel.OnConflictReplace()))
+	assert.Equal(t, result, rel.Build("users", rel.OnConflictReplace()))
+	assert.Equal(t, result, rel.Build("", rel.From("users"), rel.From("").OnConflict(rel.OnConflictReplace())))
+}
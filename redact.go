@@ -0,0 +1,143 @@
+package rel
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Redacted replaces values of sensitive fields in logger output and instrumentation.
+const Redacted = "[REDACTED]"
+
+var (
+	sensitiveFields sync.Map
+	sensitiveCount  int32
+)
+
+// Sensitive marks fields as sensitive, so their values are replaced with [REDACTED] in logger output and instrumentation.
+// Fields are matched by name regardless of the table, struct field can also be marked using db tag, eg: `db:"password,sensitive"`.
+func Sensitive(fields ...string) {
+	for _, field := range fields {
+		if _, loaded := sensitiveFields.LoadOrStore(field, struct{}{}); !loaded {
+			atomic.AddInt32(&sensitiveCount, 1)
+		}
+	}
+}
+
+func isSensitive(field string) bool {
+	_, ok := sensitiveFields.Load(field)
+	return ok
+}
+
+type redactKey struct{}
+
+// Redact replaces values of sensitive fields that are used by the current operation in the text.
+// It's intended to be used by adapter middleware that records statement outside of the loggers.
+func Redact(ctx context.Context, text string) string {
+	if replacer, ok := ctx.Value(redactKey{}).(*strings.Replacer); ok {
+		return replacer.Replace(text)
+	}
+
+	return text
+}
+
+// RedactError returns error with message redacted using Redact, the original error can be retrieved using errors.Unwrap.
+func RedactError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if replacer, ok := ctx.Value(redactKey{}).(*strings.Replacer); ok {
+		return redactedError{message: replacer.Replace(err.Error()), err: err}
+	}
+
+	return err
+}
+
+type redactedError struct {
+	message string
+	err     error
+}
+
+func (re redactedError) Error() string {
+	return re.message
+}
+
+func (re redactedError) Unwrap() error {
+	return re.err
+}
+
+// redact collects values of sensitive fields used by the query and modifies,
+// and returns context that replaces those values with [REDACTED] when used with Redact.
+func redact(ctx context.Context, query Query, modifies ...map[string]Modify) (context.Context, bool) {
+	if atomic.LoadInt32(&sensitiveCount) == 0 {
+		return ctx, false
+	}
+
+	values := sensitiveValues(nil, query.WhereQuery)
+	for i := range modifies {
+		for field, modify := range modifies[i] {
+			if isSensitive(field) {
+				values = appendValue(values, modify.Value)
+			}
+		}
+	}
+
+	if len(values) == 0 {
+		return ctx, false
+	}
+
+	// longer value is replaced first, so value that contains another value is redacted entirely.
+	sort.Slice(values, func(i, j int) bool {
+		return len(values[i]) > len(values[j])
+	})
+
+	oldnew := make([]string, 0, 2*len(values))
+	for _, value := range values {
+		oldnew = append(oldnew, value, Redacted)
+	}
+
+	return context.WithValue(ctx, redactKey{}, strings.NewReplacer(oldnew...)), true
+}
+
+func sensitiveValues(values []string, filter FilterQuery) []string {
+	if filter.Type != FilterFragmentOp && isSensitive(filter.Field) {
+		values = appendValue(values, filter.Value)
+	}
+
+	for i := range filter.Inner {
+		values = sensitiveValues(values, filter.Inner[i])
+	}
+
+	return values
+}
+
+func appendValue(values []string, value interface{}) []string {
+	var s string
+
+	switch v := value.(type) {
+	case nil:
+		return values
+	case []interface{}:
+		for i := range v {
+			values = appendValue(values, v[i])
+		}
+
+		return values
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		s = fmt.Sprint(v)
+	}
+
+	if s == "" {
+		return values
+	}
+
+	return append(values, s)
+}
@@ -0,0 +1,81 @@
+package rel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type credential struct {
+	ID    int
+	Name  string
+	Token string `db:"token,sensitive"`
+	Pin   int
+}
+
+// redactAdapter logs statements that contain the values, and returns errors that contain the values.
+type redactAdapter struct {
+	testAdapter
+	ctx context.Context
+}
+
+func (ra *redactAdapter) Insert(ctx context.Context, query Query, modifies map[string]Modify, loggers ...Logger) (interface{}, error) {
+	ra.ctx = ctx
+	err := fmt.Errorf("duplicate entry '%v'", modifies["token"].Value)
+	Log(loggers, fmt.Sprintf("INSERT INTO credentials VALUES (%v, %v);", modifies["name"].Value, modifies["token"].Value), time.Second, err)
+	return nil, err
+}
+
+func (ra *redactAdapter) Query(ctx context.Context, query Query, loggers ...Logger) (Cursor, error) {
+	ra.ctx = ctx
+	Log(loggers, fmt.Sprintf("SELECT * FROM credentials WHERE %v;", query.WhereQuery), time.Second, nil)
+	return nil, errors.New("query")
+}
+
+func TestSensitive(t *testing.T) {
+	var (
+		adapter    = &redactAdapter{}
+		repo       = repository{adapter: adapter}
+		statements []string
+		errs       []error
+	)
+
+	Sensitive("pin")
+	repo.SetLogger(func(statement string, _ time.Duration, err error) {
+		statements = append(statements, statement)
+		errs = append(errs, err)
+	})
+
+	err := repo.Insert(context.TODO(), &credential{Name: "rel", Token: "s3cr3t"})
+	assert.EqualError(t, err, "duplicate entry 's3cr3t'")
+	assert.Equal(t, "INSERT INTO credentials VALUES (rel, [REDACTED]);", statements[0])
+	assert.EqualError(t, errs[0], "duplicate entry '[REDACTED]'")
+	assert.Equal(t, err, errors.Unwrap(errs[0]))
+	assert.Equal(t, "token [REDACTED]", Redact(adapter.ctx, "token s3cr3t"))
+	assert.EqualError(t, RedactError(adapter.ctx, err), "duplicate entry '[REDACTED]'")
+
+	assert.NotNil(t, repo.Find(context.TODO(), &credential{}, Eq("name", "rel").AndEq("pin", 1234).OrIn("token", "abc", "abcdef")))
+	assert.Contains(t, statements[1], "rel")
+	assert.Contains(t, statements[1], "[[REDACTED] [REDACTED]]")
+	assert.NotContains(t, statements[1], "1234")
+	assert.NotContains(t, statements[1], "abc")
+}
+
+func TestSensitive_none(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = &redactAdapter{}
+		repo    = repository{adapter: adapter}
+		err     = errors.New("error")
+	)
+
+	assert.NotNil(t, repo.Find(ctx, &credential{}, Eq("name", "rel")))
+	assert.Equal(t, ctx, adapter.ctx)
+	assert.Equal(t, "rel", Redact(ctx, "rel"))
+	assert.Equal(t, err, RedactError(ctx, err))
+	assert.Nil(t, RedactError(ctx, nil))
+}
@@ -0,0 +1,127 @@
+package rel
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// RepositoryConfig holds configuration of a named repository.
+// InChunkSize splits query into multiple queries when its in filter has more values than the size, and merges the results.
+// Queries with limit, offset, group, distinct or sort (except preload) are never split. Zero disables chunking (default).
+type RepositoryConfig struct {
+	Adapter            Adapter
+	Loggers            []Logger
+	LogLevels          *LogLevels
+	Middlewares        []AdapterMiddleware
+	Instrumenters      []Instrumenter
+	PreloadParallelism int
+	InChunkSize        int
+}
+
+// Registry holds multiple named repositories, it's intended for application that talks to several databases.
+type Registry struct {
+	mutex        sync.RWMutex
+	repositories map[string]Repository
+}
+
+// NewRegistry create an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		repositories: make(map[string]Repository),
+	}
+}
+
+// Register repository using the given name.
+// It panics if the name is already registered.
+func (r *Registry) Register(name string, repository Repository) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.repositories[name]; ok {
+		panic("rel: repository " + name + " is already registered")
+	}
+
+	r.repositories[name] = repository
+}
+
+// Configure creates and register repository using the given configuration.
+// Adapter is wrapped by middlewares, loggers and log levels will replace the default if specified, and instrumenters are attached to the repository.
+// Preload parallelism limits concurrent preload queries of the repository, zero means unlimited,
+// and in chunk size splits query with huge in filter into multiple queries, zero disables chunking.
+func (r *Registry) Configure(name string, config RepositoryConfig) Repository {
+	var (
+		adapter = config.Adapter
+	)
+
+	if len(config.Middlewares) > 0 {
+		adapter = WrapAdapter(adapter, config.Middlewares...)
+	}
+
+	repository := New(adapter)
+	if len(config.Loggers) > 0 {
+		repository.SetLogger(config.Loggers...)
+	}
+
+	if config.LogLevels != nil {
+		repository.SetLogLevels(*config.LogLevels)
+	}
+
+	repository.Instrumentation(config.Instrumenters...)
+	repository.SetPreloadParallelism(config.PreloadParallelism)
+	repository.SetInChunkSize(config.InChunkSize)
+
+	r.Register(name, repository)
+
+	return repository
+}
+
+// Get repository by name.
+func (r *Registry) Get(name string) (Repository, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	repository, ok := r.repositories[name]
+	if !ok {
+		return nil, RepositoryNotFoundError{Name: name}
+	}
+
+	return repository, nil
+}
+
+// MustGet repository by name.
+// It'll panic if the repository is not registered.
+func (r *Registry) MustGet(name string) Repository {
+	repository, err := r.Get(name)
+	must(err)
+	return repository
+}
+
+// Names of registered repositories in alphabetical order.
+func (r *Registry) Names() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var (
+		names = make([]string, 0, len(r.repositories))
+	)
+
+	for name := range r.repositories {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Ping all registered repositories.
+func (r *Registry) Ping(ctx context.Context) error {
+	for _, name := range r.Names() {
+		if err := r.MustGet(name).Ping(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
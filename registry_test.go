@@ -0,0 +1,106 @@
+package rel
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	var (
+		registry = NewRegistry()
+		primary  = New(&testAdapter{})
+	)
+
+	registry.Register("primary", primary)
+
+	repo, err := registry.Get("primary")
+	assert.Nil(t, err)
+	assert.Equal(t, primary, repo)
+	assert.Equal(t, primary, registry.MustGet("primary"))
+
+	assert.Panics(t, func() {
+		registry.Register("primary", primary)
+	})
+}
+
+func TestRegistry_notFound(t *testing.T) {
+	var (
+		registry = NewRegistry()
+	)
+
+	repo, err := registry.Get("analytics")
+	assert.Nil(t, repo)
+	assert.Equal(t, RepositoryNotFoundError{Name: "analytics"}, err)
+	assert.Equal(t, "rel: repository analytics is not registered", err.Error())
+
+	assert.Panics(t, func() {
+		registry.MustGet("analytics")
+	})
+}
+
+func TestRegistry_Configure(t *testing.T) {
+	var (
+		registry = NewRegistry()
+		adapter  = &testAdapter{}
+		wrapped  = 0
+		logger   = func(string, time.Duration, error) {}
+	)
+
+	repo := registry.Configure("analytics", RepositoryConfig{
+		Adapter:   adapter,
+		Loggers:   []Logger{logger},
+		LogLevels: &LogLevels{Minimum: WarnLevel},
+		Middlewares: []AdapterMiddleware{
+			func(adapter Adapter) Adapter {
+				wrapped++
+				return adapter
+			},
+		},
+		Instrumenters: []Instrumenter{
+			func(context.Context, string, string) func(error) {
+				return func(error) {}
+			},
+		},
+		PreloadParallelism: 4,
+		InChunkSize:        1000,
+	})
+
+	assert.Equal(t, 1, wrapped)
+	assert.Equal(t, repo, registry.MustGet("analytics"))
+	assert.Len(t, repo.(*repository).logger, 1)
+	assert.Len(t, repo.(*repository).instrumenters, 1)
+	assert.Equal(t, WarnLevel, repo.(*repository).logLevels.Minimum)
+	assert.Equal(t, 4, repo.(*repository).preloadParallelism)
+	assert.Equal(t, 1000, repo.(*repository).inChunkSize)
+
+	repo = registry.Configure("legacy", RepositoryConfig{Adapter: adapter})
+	assert.Equal(t, adapter, repo.Adapter())
+	assert.Equal(t, DefaultLogLevels, repo.(*repository).logLevels)
+	assert.Equal(t, []string{"analytics", "legacy"}, registry.Names())
+}
+
+func TestRegistry_Ping(t *testing.T) {
+	var (
+		registry  = NewRegistry()
+		primary   = &testAdapter{}
+		analytics = &testAdapter{}
+		err       = errors.New("connection refused")
+	)
+
+	registry.Register("primary", New(primary))
+	registry.Register("analytics", New(analytics))
+
+	analytics.On("Ping").Return(nil).Once()
+	primary.On("Ping").Return(nil).Once()
+	assert.Nil(t, registry.Ping(context.TODO()))
+
+	analytics.On("Ping").Return(err).Once()
+	assert.Equal(t, err, registry.Ping(context.TODO()))
+
+	primary.AssertExpectations(t)
+	analytics.AssertExpectations(t)
+}
@@ -32,6 +32,38 @@ type Address struct {
 	DeletedAt *time.Time
 }
 
+type Note struct {
+	ID     int
+	Text   string
+	UserID int
+	User   User `touch:"true"`
+}
+
+type Review struct {
+	ID     int
+	Text   string
+	UserID int
+	User   User `verify:"true"`
+}
+
+type Category struct {
+	ID       int
+	Name     string
+	ParentID *int
+	Parent   *Category
+	Children []Category `fk:"parent_id"`
+}
+
+type Employee struct {
+	ID            int
+	ManagerID     *int
+	Manager       *Employee  `ref:"manager_id"`
+	Reports       []Employee `fk:"manager_id"`
+	PredecessorID *int
+	Predecessor   *Employee `fk:"id"`
+	Successor     *Employee `fk:"predecessor_id"`
+}
+
 type Owner struct {
 	User   *User
 	UserID *int
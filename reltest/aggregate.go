@@ -8,12 +8,23 @@ type Aggregate struct {
 }
 
 // Result sets the result of this query.
-func (a *Aggregate) Result(count int) {
+func (a *Aggregate) Result(count int) *Aggregate {
 	a.Return(count, nil)
+	return a
+}
+
+// Then sets the result of the next call of this query.
+func (a *Aggregate) Then(count int) *Aggregate {
+	return &Aggregate{Expect: a.then(count, nil)}
+}
+
+// ThenError sets error to be returned by the next call of this query.
+func (a *Aggregate) ThenError(err error) *Aggregate {
+	return &Aggregate{Expect: a.then(0, err)}
 }
 
 // Error sets error to be returned.
-func (a Aggregate) Error(err error) {
+func (a *Aggregate) Error(err error) {
 	a.Return(0, err)
 }
 
@@ -25,8 +36,8 @@ func (a *Aggregate) ConnectionClosed() {
 // ExpectAggregate to be called with given field and queries.
 func ExpectAggregate(r *Repository, query rel.Query, aggregate string, field string) *Aggregate {
 	return &Aggregate{
-		Expect: newExpect(r, "Aggregate",
-			[]interface{}{query, aggregate, field},
+		Expect: expectQueriers(r, "Aggregate", []rel.Querier{query},
+			[]interface{}{matchQueryArgument(query), aggregate, field},
 			[]interface{}{0, nil},
 		),
 	}
@@ -35,8 +46,8 @@ func ExpectAggregate(r *Repository, query rel.Query, aggregate string, field str
 // ExpectCount to be called with given field and queries.
 func ExpectCount(r *Repository, collection string, queriers []rel.Querier) *Aggregate {
 	return &Aggregate{
-		Expect: newExpect(r, "Count",
-			[]interface{}{collection, queriers},
+		Expect: expectQueriers(r, "Count", queriers,
+			[]interface{}{collection, matchQueriers(queriers)},
 			[]interface{}{0, nil},
 		),
 	}
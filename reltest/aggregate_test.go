@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/where"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -47,6 +48,27 @@ func TestAggregate_error(t *testing.T) {
 	repo.AssertExpectations(t)
 }
 
+func TestAggregate_then(t *testing.T) {
+	var (
+		repo = New()
+	)
+
+	repo.ExpectCount("books").Result(2).ThenError(sql.ErrConnDone).Then(3)
+
+	count, err := repo.Count(context.TODO(), "books")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+
+	count, err = repo.Count(context.TODO(), "books")
+	assert.Equal(t, sql.ErrConnDone, err)
+	assert.Equal(t, 0, count)
+
+	count, err = repo.Count(context.TODO(), "books")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, count)
+	repo.AssertExpectations(t)
+}
+
 func TestAggregate_Count(t *testing.T) {
 	var (
 		repo = New()
@@ -84,3 +106,58 @@ func TestAggregate_Count_error(t *testing.T) {
 	})
 	repo.AssertExpectations(t)
 }
+
+func TestAggregate_mismatch(t *testing.T) {
+	var (
+		repo  = New()
+		query = rel.From("books").Where(where.Eq("available", true))
+	)
+
+	repo.ExpectAggregate(query, "sum", "price").Result(10)
+
+	assert.Panics(t, func() {
+		repo.Aggregate(context.TODO(), query, "max", "price")
+	})
+
+	assert.Panics(t, func() {
+		repo.Aggregate(context.TODO(), query, "sum", "stock")
+	})
+
+	assert.Panics(t, func() {
+		repo.Aggregate(context.TODO(), rel.From("books"), "sum", "price")
+	})
+
+	sum, err := repo.Aggregate(context.TODO(), rel.From("books").Where(where.Eq("available", true)), "sum", "price")
+	assert.Equal(t, 10, sum)
+	assert.Nil(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestAggregate_anyValue(t *testing.T) {
+	var (
+		repo = New()
+	)
+
+	repo.ExpectAggregate(rel.From("books").Where(where.Eq("author_id", rel.Any)), "count", "id").Result(4)
+	count, err := repo.Aggregate(context.TODO(), rel.From("books").Where(where.Eq("author_id", 3)), "count", "id")
+	assert.Equal(t, 4, count)
+	assert.Nil(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestAggregate_Count_query(t *testing.T) {
+	var (
+		repo = New()
+	)
+
+	repo.ExpectCount("books", where.Eq("available", true), where.Gt("price", 10)).Result(2)
+
+	assert.Panics(t, func() {
+		repo.Count(context.TODO(), "books", where.Eq("available", true))
+	})
+
+	count, err := repo.Count(context.TODO(), "books", where.Gt("price", 10).AndEq("available", true))
+	assert.Equal(t, 2, count)
+	assert.Nil(t, err)
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,127 @@
+package reltest
+
+import (
+	"context"
+
+	"github.com/Fs02/rel"
+	"github.com/stretchr/testify/mock"
+)
+
+// Association asserts and simulate association proxy for test.
+type Association struct {
+	r      *Repository
+	field  string
+	record interface{}
+}
+
+// For match expect calls for given record.
+// Record can also be a function that accepts the record and returns bool for custom matching.
+func (a *Association) For(record interface{}) *Association {
+	a.record = matchRecord(record)
+	return a
+}
+
+// ExpectAdd to be called with given records.
+func (a *Association) ExpectAdd(records ...interface{}) *Expect {
+	return a.expect("Add", records)
+}
+
+// ExpectRemove to be called with given records.
+func (a *Association) ExpectRemove(records ...interface{}) *Expect {
+	return a.expect("Remove", records)
+}
+
+// ExpectReplace to be called with given records.
+func (a *Association) ExpectReplace(records ...interface{}) *Expect {
+	return a.expect("Replace", records)
+}
+
+// ExpectClear to be called.
+func (a *Association) ExpectClear() *Expect {
+	return newExpect(a.r, "Association.Clear",
+		[]interface{}{a.record, a.field},
+		[]interface{}{nil},
+	)
+}
+
+// ExpectCount to be called.
+func (a *Association) ExpectCount() *Aggregate {
+	return &Aggregate{
+		Expect: newExpect(a.r, "Association.Count",
+			[]interface{}{a.record, a.field},
+			[]interface{}{0, nil},
+		),
+	}
+}
+
+func (a *Association) expect(method string, records []interface{}) *Expect {
+	return newExpect(a.r, "Association."+method,
+		[]interface{}{a.record, a.field, records},
+		[]interface{}{nil},
+	)
+}
+
+// ExpectAssociation to be called with given field.
+func ExpectAssociation(r *Repository, field string) *Association {
+	return &Association{
+		r:      r,
+		field:  field,
+		record: mock.Anything,
+	}
+}
+
+type associationProxy struct {
+	r      *Repository
+	record interface{}
+	field  string
+}
+
+func (ap associationProxy) Add(ctx context.Context, records ...interface{}) error {
+	return ap.modify(ctx, "Add", records, func(proxy rel.AssociationProxy) error {
+		return proxy.Add(ctx, records...)
+	})
+}
+
+func (ap associationProxy) Remove(ctx context.Context, records ...interface{}) error {
+	return ap.modify(ctx, "Remove", records, func(proxy rel.AssociationProxy) error {
+		return proxy.Remove(ctx, records...)
+	})
+}
+
+func (ap associationProxy) Replace(ctx context.Context, records ...interface{}) error {
+	return ap.modify(ctx, "Replace", records, func(proxy rel.AssociationProxy) error {
+		return proxy.Replace(ctx, records...)
+	})
+}
+
+func (ap associationProxy) Clear(ctx context.Context) error {
+	ret, matched := ap.r.called(ctx, "Association.Clear", mock.Arguments{nil}, ap.record, ap.field)
+	if ap.r.memory() != nil {
+		return ap.r.persist(ret, matched, func() error {
+			return ap.r.repo.Association(ap.record, ap.field).Clear(ctx)
+		})
+	}
+
+	return ret.Error(0)
+}
+
+func (ap associationProxy) Count(ctx context.Context) (int, error) {
+	count, err := ap.r.repo.Association(ap.record, ap.field).Count(ctx)
+	ret, matched := ap.r.called(ctx, "Association.Count", mock.Arguments{0, nil}, ap.record, ap.field)
+	if !matched && ap.r.memory() != nil {
+		return count, err
+	}
+
+	return ret.Int(0), ret.Error(1)
+}
+
+func (ap associationProxy) modify(ctx context.Context, method string, records []interface{}, mutate func(proxy rel.AssociationProxy) error) error {
+	ret, matched := ap.r.called(ctx, "Association."+method, mock.Arguments{nil}, ap.record, ap.field, records)
+	if ap.r.memory() != nil {
+		return ap.r.persist(ret, matched, func() error {
+			return mutate(ap.r.repo.Association(ap.record, ap.field))
+		})
+	}
+
+	return ret.Error(0)
+}
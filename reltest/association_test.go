@@ -0,0 +1,107 @@
+package reltest
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssociation(t *testing.T) {
+	var (
+		repo    = New()
+		book    = Book{ID: 1}
+		rating1 = Rating{ID: 1, Score: 5}
+		rating2 = Rating{ID: 2, Score: 3}
+	)
+
+	repo.ExpectAssociation("ratings").ExpectAdd(&rating1, &rating2)
+	repo.ExpectAssociation("ratings").ExpectRemove(&rating1)
+	repo.ExpectAssociation("ratings").ExpectReplace(&rating2)
+	repo.ExpectAssociation("ratings").ExpectClear()
+	repo.ExpectAssociation("ratings").ExpectCount().Result(2)
+
+	ratings := repo.Association(&book, "ratings")
+	assert.Nil(t, ratings.Add(context.TODO(), &rating1, &rating2))
+	assert.Nil(t, ratings.Remove(context.TODO(), &rating1))
+	assert.Nil(t, ratings.Replace(context.TODO(), &rating2))
+	assert.Nil(t, ratings.Clear(context.TODO()))
+
+	count, err := ratings.Count(context.TODO())
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+
+	repo.AssertExpectations(t)
+}
+
+func TestAssociation_for(t *testing.T) {
+	var (
+		repo   = New()
+		book1  = Book{ID: 1}
+		book2  = Book{ID: 2}
+		rating = Rating{ID: 1, Score: 5}
+	)
+
+	repo.ExpectAssociation("ratings").For(&book2).ExpectAdd(&rating).ConnectionClosed()
+	repo.ExpectAssociation("ratings").For(&book1).ExpectAdd(&rating)
+
+	assert.Nil(t, repo.Association(&book1, "ratings").Add(context.TODO(), &rating))
+	assert.Equal(t, sql.ErrConnDone, repo.Association(&book2, "ratings").Add(context.TODO(), &rating))
+
+	repo.AssertExpectations(t)
+}
+
+func TestAssociation_error(t *testing.T) {
+	var (
+		repo = New()
+		book = Book{ID: 1}
+	)
+
+	repo.ExpectAssociation("ratings").ExpectClear().ConnectionClosed()
+	repo.ExpectAssociation("ratings").ExpectCount().ConnectionClosed()
+
+	assert.Equal(t, sql.ErrConnDone, repo.Association(&book, "ratings").Clear(context.TODO()))
+
+	count, err := repo.Association(&book, "ratings").Count(context.TODO())
+	assert.Equal(t, sql.ErrConnDone, err)
+	assert.Equal(t, 0, count)
+
+	repo.AssertExpectations(t)
+}
+
+func TestAssociation_invalid(t *testing.T) {
+	var (
+		repo = New()
+		book = Book{ID: 1}
+	)
+
+	assert.Panics(t, func() {
+		repo.Association(&book, "poster")
+	})
+}
+
+func TestAssociation_stateful(t *testing.T) {
+	var (
+		ctx    = context.TODO()
+		repo   = NewStateful()
+		book   = Book{Title: "Rel for dummies"}
+		rating = Rating{Score: 5}
+	)
+
+	assert.Nil(t, repo.Insert(ctx, &book))
+
+	ratings := repo.Association(&book, "ratings")
+	assert.Nil(t, ratings.Add(ctx, &rating))
+	assert.Equal(t, book.ID, rating.BookID)
+
+	count, err := ratings.Count(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+
+	assert.Nil(t, ratings.Clear(ctx))
+
+	count, err = ratings.Count(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, count)
+}
@@ -3,6 +3,7 @@ package reltest
 import (
 	"strings"
 
+	"github.com/Fs02/rel"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -12,8 +13,9 @@ type Delete struct {
 }
 
 // For match expect calls for given record.
+// Record can also be a function that accepts the record and returns bool for custom matching.
 func (d *Delete) For(record interface{}) *Delete {
-	d.Arguments[0] = record
+	d.Arguments[0] = matchRecord(record)
 	return d
 }
 
@@ -23,6 +25,27 @@ func (d *Delete) ForType(typ string) *Delete {
 	return d.For(mock.AnythingOfType("*" + strings.TrimPrefix(typ, "*")))
 }
 
+// NotFound sets NotFoundError to be returned.
+func (d *Delete) NotFound() {
+	d.Error(rel.NotFoundError{})
+}
+
+// ForeignKeyViolated sets foreign key constraint error to be returned.
+func (d *Delete) ForeignKeyViolated(key string) {
+	d.Error(rel.ConstraintError{
+		Key:  key,
+		Type: rel.ForeignKeyConstraint,
+	})
+}
+
+// CheckViolated sets check constraint error to be returned.
+func (d *Delete) CheckViolated(key string) {
+	d.Error(rel.ConstraintError{
+		Key:  key,
+		Type: rel.CheckConstraint,
+	})
+}
+
 // ExpectDelete to be called with given field and queries.
 func ExpectDelete(r *Repository) *Delete {
 	return &Delete{
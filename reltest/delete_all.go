@@ -18,8 +18,8 @@ func (eda *DeleteAll) Unsafe() {
 // ExpectDeleteAll to be called with given field and queries.
 func ExpectDeleteAll(r *Repository, queriers []rel.Querier) *DeleteAll {
 	eda := &DeleteAll{
-		Expect: newExpect(r, "DeleteAll",
-			[]interface{}{queriers},
+		Expect: expectQueriers(r, "DeleteAll", queriers,
+			[]interface{}{matchQueriers(queriers)},
 			[]interface{}{nil},
 		),
 	}
@@ -0,0 +1,417 @@
+package reltest
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Fs02/rel"
+	"github.com/stretchr/testify/mock"
+)
+
+// argumentLabels of each mocked method, the last argument of every method is the scope.
+var argumentLabels = map[string][]string{
+	"Aggregate":   {"query", "mode", "field"},
+	"Count":       {"table", "query"},
+	"Find":        {"record", "query"},
+	"FindAll":     {"records", "query"},
+	"FindEach":    {"record", "query"},
+	"Insert":      {"record", "modifiers", "changes"},
+	"InsertAll":   {"records", "onConflict"},
+	"Update":      {"record", "modifiers", "changes"},
+	"Delete":      {"record"},
+	"DeleteAll":   {"query"},
+	"Preload":     {"records", "field", "query"},
+	"Transaction": {},
+}
+
+const anyDescription = "any"
+
+type line struct {
+	key   string
+	value string
+}
+
+// mismatch describes unexpected call and its difference against the closest expectation.
+// Fallbacks to the original message when the call matches an expectation that has been called over its times.
+func (r *Repository) mismatch(method string, arguments []interface{}, original string) string {
+	var (
+		closest     []string
+		actual      = describeArguments(method, arguments, nil)
+		expectation = false
+		buffer      strings.Builder
+	)
+
+	for _, e := range r.expectations(method) {
+		diff := diffLines(describeArguments(method, e.Arguments, e.queriers), actual)
+		if !expectation || len(diff) < len(closest) {
+			closest = diff
+			expectation = true
+		}
+	}
+
+	if expectation && len(closest) == 0 {
+		return original
+	}
+
+	buffer.WriteString("reltest: unexpected " + method + " call\n")
+	for _, l := range actual {
+		if l.value != "" {
+			buffer.WriteString("\t" + l.key + ": " + l.value + "\n")
+		}
+	}
+
+	if !expectation {
+		buffer.WriteString("\nno " + method + " expectation declared\n")
+		return buffer.String()
+	}
+
+	buffer.WriteString("\nclosest expectation:\n")
+	for _, d := range closest {
+		buffer.WriteString(d)
+	}
+
+	return buffer.String()
+}
+
+// expectations of the given method declared on this repository.
+func (r *Repository) expectations(method string) []*Expect {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var (
+		result []*Expect
+	)
+
+	for _, e := range r.expects {
+		if e.Method == method {
+			result = append(result, e)
+		}
+	}
+
+	return result
+}
+
+func diffLines(expected []line, actual []line) []string {
+	var (
+		keys   []string
+		diff   []string
+		values = make(map[string][2]string)
+	)
+
+	for _, l := range expected {
+		if _, ok := values[l.key]; !ok {
+			keys = append(keys, l.key)
+		}
+
+		v := values[l.key]
+		v[0] = l.value
+		values[l.key] = v
+	}
+
+	for _, l := range actual {
+		if _, ok := values[l.key]; !ok {
+			keys = append(keys, l.key)
+		}
+
+		v := values[l.key]
+		v[1] = l.value
+		values[l.key] = v
+	}
+
+	for _, key := range keys {
+		v := values[key]
+		if v[0] == anyDescription || v[0] == v[1] {
+			continue
+		}
+
+		diff = append(diff, fmt.Sprintf("\t%s:\n\t\texpected: %s\n\t\tactual:   %s\n", key, none(v[0]), none(v[1])))
+	}
+
+	return diff
+}
+
+func none(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+
+	return s
+}
+
+func describeArguments(method string, arguments []interface{}, queriers []rel.Querier) []line {
+	var (
+		labels = argumentLabels[method]
+		result []line
+	)
+
+	for i, arg := range arguments {
+		label := "scope"
+		if i < len(labels) {
+			label = labels[i]
+		}
+
+		switch v := arg.(type) {
+		case rel.Query:
+			result = append(result, describeQuery(v)...)
+		case []rel.Querier:
+			result = append(result, describeQueriers(v)...)
+		case []rel.Modifier:
+			result = append(result, line{key: label, value: describeModifiers(v)})
+		case *changes:
+			result = append(result, line{key: label, value: describeModifies(v.modifies())})
+		case *scope:
+			if v != nil {
+				result = append(result, line{key: label, value: fmt.Sprintf("#%d", v.id)})
+			}
+		case mock.AnythingOfTypeArgument:
+			result = append(result, line{key: label, value: string(v)})
+		case string:
+			if v == mock.Anything {
+				v = anyDescription
+			}
+
+			result = append(result, line{key: label, value: v})
+		default:
+			if isArgumentMatcher(arg) {
+				if label == "query" {
+					result = append(result, describeQueriers(queriers)...)
+				} else {
+					result = append(result, line{key: label, value: "matched by function"})
+				}
+			} else {
+				result = append(result, line{key: label, value: describeRecord(arg)})
+			}
+		}
+	}
+
+	return result
+}
+
+func isArgumentMatcher(arg interface{}) bool {
+	return fmt.Sprintf("%T", arg) == "mock.argumentMatcher"
+}
+
+func describeRecord(record interface{}) string {
+	if record == nil {
+		return "nil"
+	}
+
+	rv := reflect.ValueOf(record)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		return fmt.Sprintf("%T%+v", record, rv.Elem().Interface())
+	}
+
+	return fmt.Sprintf("%T%+v", record, record)
+}
+
+func describeQueriers(queriers []rel.Querier) []line {
+	var (
+		plain    = make([]rel.Querier, 0, len(queriers))
+		matchers = 0
+	)
+
+	for i := range queriers {
+		if _, ok := queriers[i].(QueryMatcher); ok {
+			matchers++
+		} else {
+			plain = append(plain, queriers[i])
+		}
+	}
+
+	result := describeQuery(rel.Build("", plain...))
+	if matchers > 0 {
+		result = append(result, line{key: "matcher", value: fmt.Sprintf("%d query matcher", matchers)})
+	}
+
+	return result
+}
+
+func describeQuery(query rel.Query) []line {
+	var (
+		result []line
+	)
+
+	add := func(key string, value string) {
+		if value != "" {
+			result = append(result, line{key: key, value: value})
+		}
+	}
+
+	add("table", query.Table)
+
+	if len(query.SelectQuery.Fields) > 0 {
+		distinct := ""
+		if query.SelectQuery.OnlyDistinct {
+			distinct = "DISTINCT "
+		}
+
+		add("select", distinct+strings.Join(query.SelectQuery.Fields, ", "))
+	}
+
+	for i, join := range query.JoinQuery {
+		value := join.Mode + " " + join.Table
+		if join.From != "" || join.To != "" {
+			value += " ON " + join.From + " = " + join.To
+		}
+
+		add(fmt.Sprintf("join[%d]", i), value)
+	}
+
+	add("where", describeFilter(query.WhereQuery))
+
+	if len(query.GroupQuery.Fields) > 0 {
+		add("group", strings.Join(query.GroupQuery.Fields, ", "))
+		add("having", describeFilter(query.GroupQuery.Filter))
+	}
+
+	if len(query.SortQuery) > 0 {
+		sorts := make([]string, len(query.SortQuery))
+		for i, s := range query.SortQuery {
+			sorts[i] = s.Field + " ASC"
+			if s.Desc() {
+				sorts[i] = s.Field + " DESC"
+			}
+		}
+
+		add("sort", strings.Join(sorts, ", "))
+	}
+
+	if query.OffsetQuery != 0 {
+		add("offset", fmt.Sprint(query.OffsetQuery))
+	}
+
+	if query.LimitQuery != 0 {
+		add("limit", fmt.Sprint(query.LimitQuery))
+	}
+
+	add("lock", string(query.LockQuery))
+
+	if query.UnscopedQuery {
+		add("unscoped", "true")
+	}
+
+	if query.ReadFromPrimaryQuery {
+		add("read from primary", "true")
+	}
+
+	return result
+}
+
+var filterOperators = map[rel.FilterOp]string{
+	rel.FilterEqOp:      "=",
+	rel.FilterNeOp:      "<>",
+	rel.FilterLtOp:      "<",
+	rel.FilterLteOp:     "<=",
+	rel.FilterGtOp:      ">",
+	rel.FilterGteOp:     ">=",
+	rel.FilterInOp:      "IN",
+	rel.FilterNinOp:     "NOT IN",
+	rel.FilterLikeOp:    "LIKE",
+	rel.FilterNotLikeOp: "NOT LIKE",
+}
+
+// describeFilter formats filter as readable condition, conditions are sorted so that the order doesn't matter.
+func describeFilter(filter rel.FilterQuery) string {
+	filter = flattenFilter(filter)
+
+	switch filter.Type {
+	case rel.FilterAndOp, rel.FilterOrOp, rel.FilterNotOp:
+		var (
+			inner = make([]string, 0, len(filter.Inner))
+		)
+
+		for _, f := range filter.Inner {
+			if s := describeFilter(f); s != "" {
+				if f.Type == rel.FilterAndOp || f.Type == rel.FilterOrOp {
+					s = "(" + s + ")"
+				}
+
+				inner = append(inner, s)
+			}
+		}
+
+		sort.Strings(inner)
+
+		switch filter.Type {
+		case rel.FilterOrOp:
+			return strings.Join(inner, " OR ")
+		case rel.FilterNotOp:
+			return "NOT (" + strings.Join(inner, " AND ") + ")"
+		default:
+			return strings.Join(inner, " AND ")
+		}
+	case rel.FilterNilOp:
+		return filter.Field + " IS NULL"
+	case rel.FilterNotNilOp:
+		return filter.Field + " IS NOT NULL"
+	case rel.FilterFragmentOp:
+		return filter.Field + " " + describeValue(filter.Value)
+	default:
+		return filter.Field + " " + filterOperators[filter.Type] + " " + describeValue(filter.Value)
+	}
+}
+
+func describeValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return fmt.Sprintf("%q", v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case []interface{}:
+		values := make([]string, len(v))
+		for i := range v {
+			values[i] = describeValue(v[i])
+		}
+
+		return "(" + strings.Join(values, ", ") + ")"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func describeModifiers(modifiers []rel.Modifier) string {
+	var (
+		result = make([]string, len(modifiers))
+	)
+
+	for i, modifier := range modifiers {
+		switch m := modifier.(type) {
+		case rel.Modify:
+			result[i] = describeModify(m)
+		case rel.Map:
+			result[i] = fmt.Sprintf("%v", map[string]interface{}(m))
+		default:
+			result[i] = fmt.Sprintf("%T", modifier)
+		}
+	}
+
+	return strings.Join(result, ", ")
+}
+
+func describeModify(modify rel.Modify) string {
+	switch modify.Type {
+	case rel.ChangeIncOp:
+		return modify.Field + " += " + describeValue(modify.Value)
+	case rel.ChangeFragmentOp:
+		return modify.Field + " " + describeValue(modify.Value)
+	default:
+		return modify.Field + " = " + describeValue(modify.Value)
+	}
+}
+
+// describeModifies formats modifies sorted by its field.
+func describeModifies(modifies map[string]rel.Modify) string {
+	var (
+		result = make([]string, 0, len(modifies))
+	)
+
+	for _, modify := range modifies {
+		result = append(result, describeModify(modify))
+	}
+
+	sort.Strings(result)
+	return strings.Join(result, ", ")
+}
@@ -0,0 +1,120 @@
+package reltest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/sort"
+	"github.com/Fs02/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+func mismatchMessage(fn func()) (msg string) {
+	defer func() {
+		msg, _ = recover().(string)
+	}()
+
+	fn()
+	return
+}
+
+func TestDiagnostic_find(t *testing.T) {
+	var (
+		repo = New()
+		book Book
+	)
+
+	repo.ExpectFind(rel.From("books"), where.Eq("id", 1).AndEq("published", true))
+	repo.ExpectFind(rel.From("ratings"), where.Eq("id", 2))
+
+	msg := mismatchMessage(func() {
+		repo.Find(context.TODO(), &book, rel.From("books"), where.Eq("id", 2).AndEq("published", true), sort.Desc("id"))
+	})
+
+	assert.Contains(t, msg, "reltest: unexpected Find call")
+	assert.Contains(t, msg, "where: id = 2 AND published = true")
+	assert.Contains(t, msg, "closest expectation:")
+	assert.Contains(t, msg, "where:\n\t\texpected: id = 1 AND published = true\n\t\tactual:   id = 2 AND published = true")
+	assert.Contains(t, msg, "sort:\n\t\texpected: <none>\n\t\tactual:   id DESC")
+	assert.NotContains(t, msg, "table:\n")
+	assert.NotContains(t, msg, "ratings")
+}
+
+func TestDiagnostic_update(t *testing.T) {
+	var (
+		repo = New()
+		book = Book{ID: 1}
+	)
+
+	repo.ExpectUpdate(rel.Set("title", "Rel"), rel.Inc("views"))
+
+	msg := mismatchMessage(func() {
+		repo.Update(context.TODO(), &book, rel.Set("title", "REL"), rel.Inc("views"))
+	})
+
+	assert.Contains(t, msg, "reltest: unexpected Update call")
+	assert.Contains(t, msg, "modifiers:\n\t\texpected: title = \"Rel\", views += 1\n\t\tactual:   title = \"REL\", views += 1")
+	assert.NotContains(t, msg, "record:\n")
+}
+
+func TestDiagnostic_count(t *testing.T) {
+	repo := New()
+	repo.ExpectCount("books", where.In("id", 1, 2).OrNil("deleted_at"))
+
+	msg := mismatchMessage(func() {
+		repo.Count(context.TODO(), "book", where.In("id", 1, 2).OrNil("deleted_at"))
+	})
+
+	assert.Contains(t, msg, "table:\n\t\texpected: books\n\t\tactual:   book")
+	assert.NotContains(t, msg, "where:\n")
+	assert.Contains(t, msg, "where: deleted_at IS NULL OR id IN (1, 2)")
+}
+
+func TestDiagnostic_noExpectation(t *testing.T) {
+	repo := New()
+	repo.ExpectInsert()
+
+	msg := mismatchMessage(func() {
+		repo.DeleteAll(context.TODO(), rel.From("books"))
+	})
+
+	assert.Contains(t, msg, "reltest: unexpected DeleteAll call")
+	assert.Contains(t, msg, "table: books")
+	assert.Contains(t, msg, "no DeleteAll expectation declared")
+}
+
+func TestDiagnostic_exhausted(t *testing.T) {
+	var (
+		repo = New()
+		book Book
+	)
+
+	repo.ExpectFind(where.Eq("id", 1))
+	assert.Nil(t, repo.Find(context.TODO(), &book, where.Eq("id", 1)))
+
+	msg := mismatchMessage(func() {
+		repo.Find(context.TODO(), &book, where.Eq("id", 1))
+	})
+
+	assert.NotContains(t, msg, "reltest: unexpected Find call")
+	assert.Contains(t, msg, "called over 1 times")
+}
+
+func TestDiagnostic_scope(t *testing.T) {
+	var (
+		repo = New()
+		book Book
+	)
+
+	repo.Scope(context.TODO(), func(repo *Repository) {
+		repo.ExpectFind(where.Eq("id", 1))
+	})
+
+	msg := mismatchMessage(func() {
+		repo.Find(context.TODO(), &book, where.Eq("id", 1))
+	})
+
+	assert.Contains(t, msg, "scope:\n\t\texpected: #")
+	assert.Contains(t, msg, "actual:   <none>")
+}
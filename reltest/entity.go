@@ -0,0 +1,97 @@
+package reltest
+
+import (
+	"reflect"
+
+	"github.com/Fs02/rel"
+	"github.com/stretchr/testify/mock"
+)
+
+// Entity declares expectations that only match calls for a single record type.
+type Entity struct {
+	repo  *Repository
+	typ   reflect.Type
+	table string
+}
+
+// ofType returns argument matcher that matches pointer to the record.
+func (e *Entity) ofType() interface{} {
+	return mock.AnythingOfType(reflect.PtrTo(e.typ).String())
+}
+
+// ofCollection returns argument matcher that matches pointer to slice of the record.
+func (e *Entity) ofCollection() interface{} {
+	return mock.AnythingOfType(reflect.PtrTo(reflect.SliceOf(e.typ)).String())
+}
+
+// ExpectFind apply mocks and expectations for Find of this record type.
+func (e *Entity) ExpectFind(queriers ...rel.Querier) *Find {
+	ef := e.repo.ExpectFind(queriers...)
+	ef.Arguments[0] = e.ofType()
+	return ef
+}
+
+// ExpectFindAll apply mocks and expectations for FindAll of this record type.
+func (e *Entity) ExpectFindAll(queriers ...rel.Querier) *FindAll {
+	efa := e.repo.ExpectFindAll(queriers...)
+	efa.Arguments[0] = e.ofCollection()
+	return efa
+}
+
+// ExpectCount apply mocks and expectations for Count of the table of this record type.
+func (e *Entity) ExpectCount(queriers ...rel.Querier) *Aggregate {
+	return e.repo.ExpectCount(e.table, queriers...)
+}
+
+// ExpectInsert apply mocks and expectations for Insert of this record type.
+func (e *Entity) ExpectInsert(modifiers ...rel.Modifier) *Modify {
+	return e.repo.ExpectInsert(modifiers...).For(e.ofType())
+}
+
+// ExpectInsertAll apply mocks and expectations for InsertAll of this record type.
+func (e *Entity) ExpectInsertAll(onConflict ...rel.OnConflict) *Modify {
+	return e.repo.ExpectInsertAll(onConflict...).For(e.ofCollection())
+}
+
+// ExpectUpdate apply mocks and expectations for Update of this record type.
+func (e *Entity) ExpectUpdate(modifiers ...rel.Modifier) *Modify {
+	return e.repo.ExpectUpdate(modifiers...).For(e.ofType())
+}
+
+// ExpectDelete apply mocks and expectations for Delete of this record type.
+func (e *Entity) ExpectDelete() *Delete {
+	return e.repo.ExpectDelete().For(e.ofType())
+}
+
+// ExpectDeleteAll apply mocks and expectations for DeleteAll of the table of this record type.
+func (e *Entity) ExpectDeleteAll(queriers ...rel.Querier) *DeleteAll {
+	return e.repo.ExpectDeleteAll(append([]rel.Querier{rel.From(e.table)}, queriers...)...)
+}
+
+// For returns expectations builder that only matches calls for the type of given record, example:
+//	books := repo.For(&Book{})
+//	books.ExpectFind(where.Eq("id", 1)).Result(book)
+//	books.ExpectUpdate().ForChanges("title")
+//
+// Find, FindAll, Insert, InsertAll, Update and Delete expectations match the type of record,
+// while Count and DeleteAll expectations match the table of the record.
+func (r *Repository) For(record interface{}) *Entity {
+	rt := reflect.TypeOf(record)
+	if rt == nil {
+		panic("reltest: record must be a struct or pointer to a struct")
+	}
+
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+
+	if rt.Kind() != reflect.Struct {
+		panic("reltest: record must be a struct or pointer to a struct")
+	}
+
+	return &Entity{
+		repo:  r,
+		typ:   rt,
+		table: rel.NewDocument(reflect.New(rt).Interface()).Table(),
+	}
+}
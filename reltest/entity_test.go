@@ -0,0 +1,111 @@
+package reltest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntity(t *testing.T) {
+	var (
+		repo    = New()
+		books   = repo.For(&Book{})
+		authors = repo.For(Author{})
+		book    = Book{ID: 1, Title: "Golang for dummies"}
+		result  Book
+		author  Author
+	)
+
+	authors.ExpectFind(where.Eq("id", 1)).NotFound()
+	books.ExpectFind(where.Eq("id", 1)).Result(book)
+	books.ExpectUpdate(rel.Set("title", "REL for dummies"))
+	authors.ExpectUpdate().Error(rel.NotFoundError{})
+
+	assert.Nil(t, repo.Find(context.TODO(), &result, where.Eq("id", 1)))
+	assert.Equal(t, book, result)
+	assert.Equal(t, rel.NotFoundError{}, repo.Find(context.TODO(), &author, where.Eq("id", 1)))
+
+	assert.Equal(t, rel.NotFoundError{}, repo.Update(context.TODO(), &author))
+	assert.Nil(t, repo.Update(context.TODO(), &result, rel.Set("title", "REL for dummies")))
+
+	repo.AssertExpectations(t)
+}
+
+func TestEntity_collection(t *testing.T) {
+	var (
+		repo   = New()
+		books  = repo.For(&Book{})
+		result []Book
+	)
+
+	books.ExpectFindAll(where.Like("title", "%dummies%"))
+	books.ExpectInsertAll()
+	books.ExpectCount(where.Eq("author_id", 1)).Result(2)
+
+	assert.Nil(t, repo.FindAll(context.TODO(), &result, where.Like("title", "%dummies%")))
+	assert.Panics(t, func() {
+		repo.InsertAll(context.TODO(), &[]Author{{Name: "Kia"}})
+	})
+	assert.Nil(t, repo.InsertAll(context.TODO(), &[]Book{{Title: "REL for dummies"}}))
+
+	count, err := repo.Count(context.TODO(), "books", where.Eq("author_id", 1))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+
+	repo.AssertExpectations(t)
+}
+
+func TestEntity_delete(t *testing.T) {
+	var (
+		repo  = New()
+		books = repo.For(&Book{})
+	)
+
+	books.ExpectDelete()
+	books.ExpectDeleteAll(where.Eq("author_id", 1))
+
+	assert.Panics(t, func() {
+		repo.Delete(context.TODO(), &Author{ID: 1})
+	})
+	assert.Nil(t, repo.Delete(context.TODO(), &Book{ID: 1}))
+
+	assert.Panics(t, func() {
+		repo.DeleteAll(context.TODO(), rel.From("authors").Where(where.Eq("author_id", 1)))
+	})
+	assert.Nil(t, repo.DeleteAll(context.TODO(), rel.From("books").Where(where.Eq("author_id", 1))))
+
+	repo.AssertExpectations(t)
+}
+
+func TestEntity_insert(t *testing.T) {
+	var (
+		repo  = New()
+		books = repo.For(&Book{})
+	)
+
+	books.ExpectInsert().For(func(book *Book) bool {
+		return book.Title == "REL for dummies"
+	})
+
+	assert.Panics(t, func() {
+		repo.Insert(context.TODO(), &Author{Name: "REL for dummies"})
+	})
+	assert.Nil(t, repo.Insert(context.TODO(), &Book{Title: "REL for dummies"}))
+
+	repo.AssertExpectations(t)
+}
+
+func TestEntity_invalid(t *testing.T) {
+	repo := New()
+
+	assert.Panics(t, func() {
+		repo.For(nil)
+	})
+
+	assert.Panics(t, func() {
+		repo.For(&[]Book{})
+	})
+}
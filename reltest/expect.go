@@ -1,24 +1,89 @@
 package reltest
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 
+	"github.com/Fs02/rel"
 	"github.com/stretchr/testify/mock"
 )
 
 var (
 	// ErrConnectionClosed is alias for sql.ErrConnDone.
 	ErrConnectionClosed = sql.ErrConnDone
+
+	// ErrSerializationFailure is the underlying error of simulated serialization error.
+	ErrSerializationFailure = errors.New("reltest: could not serialize access due to concurrent update")
 )
 
 // Expect is base behaviour for all reltest expectations.
 type Expect struct {
 	*mock.Call
+	state    *state
+	queriers []rel.Querier
+}
+
+// Expectation is implemented by all reltest expectations.
+type Expectation interface {
+	expect() *Expect
+}
+
+func (e *Expect) expect() *Expect {
+	return e
+}
+
+// After asserts that this expectation is only called after given expectations are called.
+func (e *Expect) After(expectations ...Expectation) *Expect {
+	for i := range expectations {
+		e.NotBefore(expectations[i].expect().Call)
+	}
+
+	return e
+}
+
+// Before asserts that this expectation is called before given expectations.
+func (e *Expect) Before(expectations ...Expectation) *Expect {
+	for i := range expectations {
+		expectations[i].expect().After(e)
+	}
+
+	return e
+}
+
+// Times sets the exact number of times this expectation should be called.
+func (e *Expect) Times(n int) *Expect {
+	e.Call.Times(n)
+	return e
+}
+
+// Once sets this expectation to be called exactly once, this is the default.
+func (e *Expect) Once() *Expect {
+	return e.Times(1)
+}
+
+// Twice sets this expectation to be called exactly twice.
+func (e *Expect) Twice() *Expect {
+	return e.Times(2)
+}
+
+// AnyTimes allows this expectation to be called any number of times, including not called at all.
+func (e *Expect) AnyTimes() *Expect {
+	e.Call.Times(0)
+	e.Maybe()
+	return e
 }
 
 // Error sets error to be returned.
 func (e *Expect) Error(err error) {
-	e.Return(err)
+	// error is always the last return value.
+	rets := append(mock.Arguments(nil), e.ReturnArguments...)
+	rets[len(rets)-1] = err
+
+	e.Return(rets...)
 }
 
 // ConnectionClosed sets this error to be returned.
@@ -26,8 +91,78 @@ func (e *Expect) ConnectionClosed() {
 	e.Error(ErrConnectionClosed)
 }
 
+// Timeout sets context deadline exceeded error to be returned.
+func (e *Expect) Timeout() {
+	e.Error(context.DeadlineExceeded)
+}
+
+// Serialization sets serialization error to be returned.
+func (e *Expect) Serialization() {
+	e.Error(rel.SerializationError{Err: ErrSerializationFailure})
+}
+
 func newExpect(r *Repository, methodName string, args []interface{}, rets []interface{}) *Expect {
-	return &Expect{
-		Call: r.mock.On(methodName, args...).Return(rets...).Once(),
+	e := &Expect{
+		Call:  r.mock.On(methodName, append(args, r.scope.argument())...).Return(rets...).Once(),
+		state: r.state,
+	}
+
+	r.track(e)
+	return e
+}
+
+// then registers expectation with the same arguments that is matched after this expectation is used up.
+func (e *Expect) then(rets ...interface{}) *Expect {
+	next := &Expect{
+		Call:     e.Parent.On(e.Method, append([]interface{}(nil), e.Arguments...)...).Return(rets...).Once(),
+		state:    e.state,
+		queriers: e.queriers,
+	}
+
+	e.state.track(next)
+	return next
+}
+
+// expectQueriers is like newExpect, but also keeps expected queriers to describe mismatched call.
+func expectQueriers(r *Repository, methodName string, queriers []rel.Querier, args []interface{}, rets []interface{}) *Expect {
+	e := newExpect(r, methodName, args, rets)
+	e.queriers = queriers
+	return e
+}
+
+// InOrder asserts that given expectations are called in the same order as declared.
+func InOrder(expectations ...Expectation) {
+	for i := 1; i < len(expectations); i++ {
+		expectations[i].expect().After(expectations[i-1])
 	}
 }
+
+// matchRecord returns argument matcher when record is a function, otherwise returns record as is.
+func matchRecord(record interface{}) interface{} {
+	if record != nil && reflect.TypeOf(record).Kind() == reflect.Func {
+		return mock.MatchedBy(record)
+	}
+
+	return record
+}
+
+// unmatchedCall is raised when repository receives call that doesn't match any expectation.
+type unmatchedCall string
+
+// failT fails the mock by raising unmatchedCall, so it can be recovered by repository
+// to either return defaults when relaxed or to describe the mismatch.
+// Ordering violation is not recovered.
+type failT struct{}
+
+func (failT) Logf(format string, args ...interface{}) {}
+
+func (failT) Errorf(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	if strings.Contains(msg, "Must not be called before") {
+		panic(msg)
+	}
+
+	panic(unmatchedCall(msg))
+}
+
+func (failT) FailNow() {}
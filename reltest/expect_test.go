@@ -0,0 +1,192 @@
+package reltest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpect_inOrder(t *testing.T) {
+	var (
+		repo   = New()
+		book   = Book{ID: 1}
+		rating = Rating{BookID: 1}
+	)
+
+	repo.InOrder(
+		repo.ExpectFind(where.Eq("id", 1)),
+		repo.ExpectInsert().For(&rating),
+		repo.ExpectUpdate().For(&book),
+	)
+
+	assert.Nil(t, repo.Find(context.TODO(), &book, where.Eq("id", 1)))
+	assert.Nil(t, repo.Insert(context.TODO(), &rating))
+	assert.Nil(t, repo.Update(context.TODO(), &book))
+	repo.AssertExpectations(t)
+}
+
+func TestExpect_inOrderViolated(t *testing.T) {
+	var (
+		repo   = New()
+		book   = Book{ID: 1}
+		rating = Rating{BookID: 1}
+	)
+
+	repo.InOrder(
+		repo.ExpectInsert().For(&book),
+		repo.ExpectInsert().For(&rating),
+	)
+
+	assert.Panics(t, func() {
+		repo.Insert(context.TODO(), &rating)
+	})
+}
+
+func TestExpect_after(t *testing.T) {
+	var (
+		repo   = New()
+		book   = Book{ID: 1}
+		rating = Rating{BookID: 1}
+		insert = repo.ExpectInsert().For(&book)
+	)
+
+	repo.ExpectInsert().For(&rating).After(insert)
+
+	assert.Panics(t, func() {
+		repo.Insert(context.TODO(), &rating)
+	})
+
+	assert.Nil(t, repo.Insert(context.TODO(), &book))
+	assert.Nil(t, repo.Insert(context.TODO(), &rating))
+	repo.AssertExpectations(t)
+}
+
+func TestExpect_before(t *testing.T) {
+	var (
+		repo   = New()
+		book   = Book{ID: 1}
+		rating = Rating{BookID: 1}
+		insert = repo.ExpectInsert().For(&rating)
+	)
+
+	repo.ExpectInsert().For(&book).Before(insert)
+
+	assert.Panics(t, func() {
+		repo.Insert(context.TODO(), &rating)
+	})
+
+	assert.Nil(t, repo.Insert(context.TODO(), &book))
+	assert.Nil(t, repo.Insert(context.TODO(), &rating))
+	repo.AssertExpectations(t)
+}
+
+func TestExpect_times(t *testing.T) {
+	var (
+		repo = New()
+		book Book
+	)
+
+	repo.ExpectFind(where.Eq("id", 1)).Times(2)
+
+	assert.Nil(t, repo.Find(context.TODO(), &book, where.Eq("id", 1)))
+	assert.False(t, repo.AssertExpectations(&testing.T{}))
+
+	assert.Nil(t, repo.Find(context.TODO(), &book, where.Eq("id", 1)))
+	repo.AssertExpectations(t)
+
+	assert.Panics(t, func() {
+		repo.Find(context.TODO(), &book, where.Eq("id", 1))
+	})
+}
+
+func TestExpect_twice(t *testing.T) {
+	var (
+		repo = New()
+		book Book
+	)
+
+	repo.ExpectFind(where.Eq("id", 1)).Twice()
+
+	assert.Nil(t, repo.Find(context.TODO(), &book, where.Eq("id", 1)))
+	assert.Nil(t, repo.Find(context.TODO(), &book, where.Eq("id", 1)))
+	repo.AssertExpectations(t)
+}
+
+func TestExpect_once(t *testing.T) {
+	var (
+		repo = New()
+		book Book
+	)
+
+	repo.ExpectFind(where.Eq("id", 1)).Times(3).Once()
+
+	assert.Nil(t, repo.Find(context.TODO(), &book, where.Eq("id", 1)))
+	repo.AssertExpectations(t)
+
+	assert.Panics(t, func() {
+		repo.Find(context.TODO(), &book, where.Eq("id", 1))
+	})
+}
+
+func TestExpect_anyTimes(t *testing.T) {
+	var (
+		repo = New()
+		book Book
+	)
+
+	repo.ExpectFind(where.Eq("id", 1)).AnyTimes()
+	repo.AssertExpectations(t)
+
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, repo.Find(context.TODO(), &book, where.Eq("id", 1)))
+	}
+
+	repo.AssertExpectations(t)
+}
+
+func TestExpect_errors(t *testing.T) {
+	var (
+		repo = New()
+		book = Book{ID: 1}
+		ctx  = context.TODO()
+	)
+
+	repo.ExpectFind(where.Eq("id", 1)).Timeout()
+	assert.Equal(t, context.DeadlineExceeded, repo.Find(ctx, &book, where.Eq("id", 1)))
+
+	repo.ExpectFindAll().Serialization()
+	assert.Equal(t, rel.SerializationError{Err: ErrSerializationFailure}, repo.FindAll(ctx, &[]Book{}))
+
+	repo.ExpectCount("books").Timeout()
+	count, err := repo.Count(ctx, "books")
+	assert.Equal(t, 0, count)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	repo.ExpectTransaction(func(repo *Repository) {}).Serialization()
+	assert.Equal(t, rel.SerializationError{Err: ErrSerializationFailure}, repo.Transaction(ctx, func(rel.Repository) error {
+		return nil
+	}))
+
+	repo.ExpectInsert().ForeignKeyViolated("author_id")
+	assert.Equal(t, rel.ConstraintError{Key: "author_id", Type: rel.ForeignKeyConstraint}, repo.Insert(ctx, &book))
+
+	repo.ExpectUpdate().CheckViolated("views")
+	assert.Equal(t, rel.ConstraintError{Key: "views", Type: rel.CheckConstraint}, repo.Update(ctx, &book))
+
+	repo.ExpectUpdate().NotFound()
+	assert.Equal(t, rel.NotFoundError{}, repo.Update(ctx, &book))
+
+	repo.ExpectDelete().ForeignKeyViolated("book_id")
+	assert.Equal(t, rel.ConstraintError{Key: "book_id", Type: rel.ForeignKeyConstraint}, repo.Delete(ctx, &book))
+
+	repo.ExpectDelete().CheckViolated("book_id")
+	assert.Equal(t, rel.ConstraintError{Key: "book_id", Type: rel.CheckConstraint}, repo.Delete(ctx, &book))
+
+	repo.ExpectDelete().NotFound()
+	assert.Equal(t, rel.NotFoundError{}, repo.Delete(ctx, &book))
+
+	repo.AssertExpectations(t)
+}
@@ -19,8 +19,8 @@ func (f *Find) NotFound() {
 func ExpectFind(r *Repository, queriers []rel.Querier) *Find {
 	return &Find{
 		FindAll: &FindAll{
-			Expect: newExpect(r, "Find",
-				[]interface{}{mock.Anything, queriers},
+			Expect: expectQueriers(r, "Find", queriers,
+				[]interface{}{mock.Anything, matchQueriers(queriers)},
 				[]interface{}{nil},
 			),
 		},
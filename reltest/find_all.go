@@ -1,11 +1,15 @@
 package reltest
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
 	"reflect"
 
 	"github.com/Fs02/rel"
 	"github.com/stretchr/testify/mock"
+	"gopkg.in/yaml.v2"
 )
 
 // FindAll asserts and simulate find all function for test.
@@ -14,20 +18,62 @@ type FindAll struct {
 }
 
 // Result sets the result of this query.
-func (fa *FindAll) Result(records interface{}) {
+func (fa *FindAll) Result(records interface{}) *FindAll {
 	fa.Arguments[0] = mock.AnythingOfType(fmt.Sprintf("*%T", records))
 
 	fa.Run(func(args mock.Arguments) {
 		reflect.ValueOf(args[0]).Elem().Set(reflect.ValueOf(records))
 	})
+
+	return fa
+}
+
+// Then sets the result of the next call of this query, example:
+//	repo.ExpectFindAll(rel.Limit(10)).Result(page1).Then(page2).Then([]Book{})
+func (fa *FindAll) Then(records interface{}) *FindAll {
+	return (&FindAll{Expect: fa.then(nil)}).Result(records)
+}
+
+// ThenError sets error to be returned by the next call of this query.
+func (fa *FindAll) ThenError(err error) *FindAll {
+	return &FindAll{Expect: fa.then(err)}
+}
+
+// ResultFromFile sets the result of this query from json or yaml file.
+// The file is unmarshalled into the type of destination, example: `testdata/books.json`.
+func (fa *FindAll) ResultFromFile(filename string) {
+	var (
+		data, err = ioutil.ReadFile(filename)
+		unmarshal = fixtureUnmarshaler(filename)
+	)
+
+	must(err)
+
+	fa.Run(func(args mock.Arguments) {
+		rv := reflect.ValueOf(args[0]).Elem()
+		rv.Set(reflect.Zero(rv.Type()))
+
+		must(unmarshal(data, args[0]))
+	})
 }
 
 // ExpectFindAll to be called with given field and queries.
 func ExpectFindAll(r *Repository, queriers []rel.Querier) *FindAll {
 	return &FindAll{
-		Expect: newExpect(r, "FindAll",
-			[]interface{}{mock.Anything, queriers},
+		Expect: expectQueriers(r, "FindAll", queriers,
+			[]interface{}{mock.Anything, matchQueriers(queriers)},
 			[]interface{}{nil},
 		),
 	}
 }
+
+func fixtureUnmarshaler(filename string) func([]byte, interface{}) error {
+	switch ext := filepath.Ext(filename); ext {
+	case ".json":
+		return json.Unmarshal
+	case ".yml", ".yaml":
+		return yaml.Unmarshal
+	default:
+		panic("reltest: unsupported fixture file " + filename)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"testing"
 
+	"github.com/Fs02/rel"
 	"github.com/Fs02/rel/where"
 	"github.com/stretchr/testify/assert"
 )
@@ -54,3 +55,74 @@ func TestFindAll_error(t *testing.T) {
 	})
 	repo.AssertExpectations(t)
 }
+
+func TestFindAll_then(t *testing.T) {
+	var (
+		repo   = New()
+		result []Book
+		page1  = []Book{{ID: 1}, {ID: 2}}
+		page2  = []Book{{ID: 3}}
+	)
+
+	repo.ExpectFindAll(where.Gt("id", rel.Any)).Result(page1).ThenError(sql.ErrConnDone).Then(page2).Then([]Book{})
+
+	assert.Nil(t, repo.FindAll(context.TODO(), &result, where.Gt("id", 0)))
+	assert.Equal(t, page1, result)
+
+	assert.Equal(t, sql.ErrConnDone, repo.FindAll(context.TODO(), &result, where.Gt("id", 2)))
+
+	assert.Nil(t, repo.FindAll(context.TODO(), &result, where.Gt("id", 2)))
+	assert.Equal(t, page2, result)
+
+	assert.Nil(t, repo.FindAll(context.TODO(), &result, where.Gt("id", 3)))
+	assert.Empty(t, result)
+
+	assert.Panics(t, func() {
+		repo.FindAll(context.TODO(), &result, where.Gt("id", 3))
+	})
+	repo.AssertExpectations(t)
+}
+
+func TestFindAll_thenNotCalled(t *testing.T) {
+	var (
+		repo   = New()
+		result []Book
+	)
+
+	repo.ExpectFindAll().Result([]Book{{ID: 1}}).Then([]Book{})
+	assert.Nil(t, repo.FindAll(context.TODO(), &result))
+	assert.False(t, repo.AssertExpectations(&testing.T{}))
+}
+
+func TestFindAll_resultFromFile(t *testing.T) {
+	var (
+		books = []Book{
+			{ID: 1, Title: "Golang for dummies", AuthorID: 1, Ratings: []Rating{{ID: 1, Score: 9, BookID: 1}}},
+			{ID: 2, Title: "Rel for dummies", AuthorID: 1},
+		}
+	)
+
+	for _, filename := range []string{"testdata/books.json", "testdata/books.yaml"} {
+		t.Run(filename, func(t *testing.T) {
+			var (
+				repo   = New()
+				result = []Book{{ID: 3}}
+			)
+
+			repo.ExpectFindAll(where.Eq("author_id", 1)).ResultFromFile(filename)
+			assert.Nil(t, repo.FindAll(context.TODO(), &result, where.Eq("author_id", 1)))
+			assert.Equal(t, books, result)
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestFindAll_resultFromFileUnsupported(t *testing.T) {
+	var (
+		repo = New()
+	)
+
+	assert.Panics(t, func() {
+		repo.ExpectFindAll().ResultFromFile("testdata/books.csv")
+	})
+}
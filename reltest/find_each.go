@@ -0,0 +1,58 @@
+package reltest
+
+import (
+	"reflect"
+
+	"github.com/Fs02/rel"
+	"github.com/stretchr/testify/mock"
+)
+
+// FindEach asserts and simulate find each function for test.
+type FindEach struct {
+	*Expect
+}
+
+// Result sets the records that are passed one by one to the callback of this query.
+// Records must be a slice of the record type, example: []Book.
+func (fe *FindEach) Result(records interface{}) *FindEach {
+	var (
+		rt = reflect.TypeOf(records)
+	)
+
+	if rt.Kind() != reflect.Slice {
+		panic("reltest: result of find each must be a slice")
+	}
+
+	fe.Arguments[0] = mock.AnythingOfType("*" + rt.Elem().String())
+	fe.ReturnArguments[0] = records
+
+	return fe
+}
+
+// ExpectFindEach to be called with given queries.
+func ExpectFindEach(r *Repository, queriers []rel.Querier) *FindEach {
+	return &FindEach{
+		Expect: expectQueriers(r, "FindEach", queriers,
+			[]interface{}{mock.Anything, matchQueriers(queriers)},
+			[]interface{}{nil, nil},
+		),
+	}
+}
+
+// each copies the records one by one into the record and calls fn, it stops when fn returns an error.
+func each(records interface{}, record interface{}, fn func(record interface{}) error) error {
+	var (
+		rv  = reflect.ValueOf(records)
+		dst = reflect.ValueOf(record).Elem()
+	)
+
+	for i := 0; i < rv.Len(); i++ {
+		dst.Set(rv.Index(i))
+
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package reltest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/Fs02/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindEach(t *testing.T) {
+	var (
+		repo   = New()
+		book   Book
+		result []Book
+		books  = []Book{
+			{ID: 1, Title: "Golang for dummies"},
+			{ID: 2, Title: "Rel for dummies"},
+		}
+		fn = func(record interface{}) error {
+			result = append(result, *record.(*Book))
+			return nil
+		}
+	)
+
+	repo.ExpectFindEach(where.Like("title", "%dummies%")).Result(books)
+	assert.Nil(t, repo.FindEach(context.TODO(), &book, fn, where.Like("title", "%dummies%")))
+	assert.Equal(t, books, result)
+	repo.AssertExpectations(t)
+
+	result = nil
+	repo.ExpectFindEach(where.Like("title", "%dummies%")).Result(books)
+	assert.NotPanics(t, func() {
+		repo.MustFindEach(context.TODO(), &book, fn, where.Like("title", "%dummies%"))
+		assert.Equal(t, books, result)
+	})
+	repo.AssertExpectations(t)
+}
+
+func TestFindEach_error(t *testing.T) {
+	var (
+		repo = New()
+		book Book
+		fn   = func(record interface{}) error {
+			return nil
+		}
+	)
+
+	repo.ExpectFindEach(where.Like("title", "%dummies%")).ConnectionClosed()
+	assert.Equal(t, sql.ErrConnDone, repo.FindEach(context.TODO(), &book, fn, where.Like("title", "%dummies%")))
+	repo.AssertExpectations(t)
+
+	repo.ExpectFindEach(where.Like("title", "%dummies%")).ConnectionClosed()
+	assert.Panics(t, func() {
+		repo.MustFindEach(context.TODO(), &book, fn, where.Like("title", "%dummies%"))
+	})
+	repo.AssertExpectations(t)
+}
+
+func TestFindEach_callbackError(t *testing.T) {
+	var (
+		repo  = New()
+		book  Book
+		calls = 0
+		err   = errors.New("error")
+		books = []Book{{ID: 1}, {ID: 2}}
+	)
+
+	repo.ExpectFindEach().Result(books)
+	assert.Equal(t, err, repo.FindEach(context.TODO(), &book, func(record interface{}) error {
+		calls++
+		return err
+	}))
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, books[0], book)
+	repo.AssertExpectations(t)
+}
+
+func TestFindEach_invalidResult(t *testing.T) {
+	assert.Panics(t, func() {
+		New().ExpectFindEach().Result(Book{})
+	})
+}
+
+func TestFindEach_stateful(t *testing.T) {
+	var (
+		ctx    = context.TODO()
+		repo   = NewStateful()
+		book   Book
+		titles []string
+	)
+
+	repo.MustInsert(ctx, &Book{Title: "Golang for dummies"})
+	repo.MustInsert(ctx, &Book{Title: "Rel for dummies"})
+
+	assert.Nil(t, repo.FindEach(ctx, &book, func(record interface{}) error {
+		titles = append(titles, book.Title)
+		return nil
+	}, where.Like("title", "Rel%")))
+	assert.Equal(t, []string{"Rel for dummies"}, titles)
+}
@@ -29,6 +29,22 @@ func TestFind(t *testing.T) {
 	repo.AssertExpectations(t)
 }
 
+func TestFind_byID(t *testing.T) {
+	var (
+		repo   = New()
+		result Book
+		book   = Book{ID: 2, Title: "Rel for dummies"}
+	)
+
+	repo.ExpectFind(rel.ByID(2)).Result(book)
+	assert.Nil(t, repo.Find(context.TODO(), &result, where.Eq("id", 2)))
+	assert.Equal(t, book, result)
+
+	repo.ExpectFind(rel.ByID(3)).NotFound()
+	assert.Equal(t, rel.NotFoundError{}, repo.Find(context.TODO(), &result, rel.ByID(3)))
+	repo.AssertExpectations(t)
+}
+
 func TestFind_noResult(t *testing.T) {
 	var (
 		result Book
@@ -49,3 +65,16 @@ func TestFind_noResult(t *testing.T) {
 	})
 	repo.AssertExpectations(t)
 }
+
+func TestFind_resultFromFile(t *testing.T) {
+	var (
+		repo   = New()
+		result = Book{Views: 10}
+		book   = Book{ID: 1, Title: "Golang for dummies", AuthorID: 1}
+	)
+
+	repo.ExpectFind(where.Eq("id", 1)).ResultFromFile("testdata/book.json")
+	assert.Nil(t, repo.Find(context.TODO(), &result, where.Eq("id", 1)))
+	assert.Equal(t, book, result)
+	repo.AssertExpectations(t)
+}
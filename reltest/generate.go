@@ -0,0 +1,285 @@
+package reltest
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/Fs02/rel"
+)
+
+var (
+	generatorWords = []string{
+		"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel",
+		"india", "juliet", "kilo", "lima", "mike", "november", "oscar", "papa",
+		"quebec", "romeo", "sierra", "tango", "uniform", "victor", "whiskey", "yankee", "zulu",
+	}
+
+	generatorNames = []string{
+		"Alice", "Bob", "Carol", "Dave", "Erin", "Frank", "Grace", "Heidi",
+		"Ivan", "Judy", "Mallory", "Niaj", "Olivia", "Peggy", "Rupert", "Sybil",
+		"Trent", "Victor", "Walter",
+	}
+
+	generatorTime = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// Generate fills records with n plausible records fabricated from its struct schema, it can be used as expectation result.
+// The records are generated deterministically, primary field is filled sequentially starting from 1 and association fields are left empty.
+//
+// Generated value can be configured using `reltest` tag:
+//	type Book struct {
+//		ID       int
+//		Title    string
+//		Status   string `reltest:"enum=draft|published"`
+//		Contact  string `reltest:"format=email"`
+//		Internal string `reltest:"-"`
+//	}
+//
+// Supported formats are: email, name, url, uuid, word and sentence.
+//
+// Overrides are applied to every records, it can be either rel.Map of field and value or
+// a function that accepts pointer to the record, example:
+//	reltest.Generate(&books, 50, rel.Map{"author_id": 1}, func(book *Book) {
+//		book.Views = book.ID * 10
+//	})
+func Generate(records interface{}, n int, overrides ...interface{}) {
+	rv := reflect.ValueOf(records)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		panic("reltest: records must be a pointer to a slice")
+	}
+
+	var (
+		slice     = rv.Elem()
+		rt        = slice.Type().Elem()
+		ptr       = rt.Kind() == reflect.Ptr
+		generator = &generator{rand: rand.New(rand.NewSource(1))}
+		result    = reflect.MakeSlice(slice.Type(), n, n)
+	)
+
+	if ptr {
+		rt = rt.Elem()
+	}
+
+	for i := 0; i < n; i++ {
+		record := reflect.New(rt)
+		generator.generate(record.Interface(), i)
+
+		for _, override := range overrides {
+			applyOverride(record, override)
+		}
+
+		if ptr {
+			result.Index(i).Set(record)
+		} else {
+			result.Index(i).Set(record.Elem())
+		}
+	}
+
+	slice.Set(result)
+}
+
+func applyOverride(record reflect.Value, override interface{}) {
+	switch o := override.(type) {
+	case rel.Map:
+		applyOverrideMap(record, o)
+	case map[string]interface{}:
+		applyOverrideMap(record, o)
+	default:
+		fn := reflect.ValueOf(override)
+		if fn.Kind() != reflect.Func || fn.Type().NumIn() != 1 || fn.Type().In(0) != record.Type() {
+			panic(fmt.Sprintf("reltest: unsupported override type %T", override))
+		}
+
+		fn.Call([]reflect.Value{record})
+	}
+}
+
+func applyOverrideMap(record reflect.Value, values map[string]interface{}) {
+	doc := rel.NewDocument(record.Interface())
+	for field, value := range values {
+		if !doc.SetValue(field, value) {
+			panic(fmt.Sprintf("reltest: cannot override field %s of %s", field, record.Type().Elem()))
+		}
+	}
+}
+
+type generator struct {
+	rand *rand.Rand
+}
+
+func (g *generator) generate(record interface{}, index int) {
+	var (
+		doc     = rel.NewDocument(record)
+		rv      = reflect.ValueOf(record).Elem()
+		rt      = rv.Type()
+		primary = doc.PrimaryField()
+	)
+
+	for _, field := range doc.Fields() {
+		var (
+			i   = doc.Index()[field]
+			sf  = rt.Field(i)
+			tag = sf.Tag.Get("reltest")
+		)
+
+		if tag == "-" || sf.PkgPath != "" || field == "deleted_at" {
+			continue
+		}
+
+		fv := rv.Field(i)
+		if field == primary {
+			g.sequence(fv, index+1)
+			continue
+		}
+
+		if fv.Kind() == reflect.Ptr {
+			value := reflect.New(fv.Type().Elem())
+			if g.value(value.Elem(), field, tag) {
+				fv.Set(value)
+			}
+
+			continue
+		}
+
+		g.value(fv, field, tag)
+	}
+}
+
+func (g *generator) sequence(fv reflect.Value, n int) {
+	switch fv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		fv.SetInt(int64(n))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		fv.SetUint(uint64(n))
+	case reflect.String:
+		fv.SetString(g.uuid())
+	}
+}
+
+// value generates value of the field, it returns false when the type of field is not supported.
+func (g *generator) value(fv reflect.Value, field string, tag string) bool {
+	var (
+		options = parseGeneratorTag(tag)
+	)
+
+	if enum, ok := options["enum"]; ok {
+		values := strings.Split(enum, "|")
+		g.set(fv, values[g.rand.Intn(len(values))])
+		return true
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(g.string(field, options["format"]))
+	case reflect.Bool:
+		fv.SetBool(g.rand.Intn(2) == 1)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		fv.SetInt(int64(g.rand.Intn(100) + 1))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		fv.SetUint(uint64(g.rand.Intn(100) + 1))
+	case reflect.Float32, reflect.Float64:
+		fv.SetFloat(float64(g.rand.Intn(10000)) / 100)
+	case reflect.Struct:
+		if fv.Type() != reflect.TypeOf(time.Time{}) {
+			return false
+		}
+
+		fv.Set(reflect.ValueOf(generatorTime.Add(time.Duration(g.rand.Intn(365*24)) * time.Hour)))
+	default:
+		return false
+	}
+
+	return true
+}
+
+// set enum value that is written as string to the field.
+func (g *generator) set(fv reflect.Value, value string) {
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(value)
+	default:
+		var (
+			ptr = reflect.New(fv.Type())
+		)
+
+		if _, err := fmt.Sscan(value, ptr.Interface()); err != nil {
+			panic(fmt.Sprintf("reltest: invalid enum value %s for %s", value, fv.Type()))
+		}
+
+		fv.Set(ptr.Elem())
+	}
+}
+
+func (g *generator) string(field string, format string) string {
+	if format == "" {
+		switch {
+		case strings.Contains(field, "email"):
+			format = "email"
+		case strings.HasSuffix(field, "name"):
+			format = "name"
+		case strings.HasSuffix(field, "url"):
+			format = "url"
+		case strings.HasSuffix(field, "uuid"):
+			format = "uuid"
+		default:
+			format = "sentence"
+		}
+	}
+
+	switch format {
+	case "email":
+		return strings.ToLower(g.name()) + "@" + g.word() + ".com"
+	case "name":
+		return g.name()
+	case "url":
+		return "https://" + g.word() + ".com/" + g.word()
+	case "uuid":
+		return g.uuid()
+	case "word":
+		return g.word()
+	case "sentence":
+		words := make([]string, g.rand.Intn(3)+2)
+		for i := range words {
+			words[i] = g.word()
+		}
+
+		sentence := strings.Join(words, " ")
+		return strings.ToUpper(sentence[:1]) + sentence[1:]
+	default:
+		panic("reltest: unsupported format " + format)
+	}
+}
+
+func (g *generator) word() string {
+	return generatorWords[g.rand.Intn(len(generatorWords))]
+}
+
+func (g *generator) name() string {
+	return generatorNames[g.rand.Intn(len(generatorNames))]
+}
+
+func (g *generator) uuid() string {
+	b := make([]byte, 16)
+	g.rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func parseGeneratorTag(tag string) map[string]string {
+	var (
+		options = make(map[string]string)
+	)
+
+	for _, option := range strings.Split(tag, ",") {
+		if kv := strings.SplitN(option, "=", 2); len(kv) == 2 {
+			options[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+		}
+	}
+
+	return options
+}
@@ -0,0 +1,119 @@
+package reltest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Fs02/rel"
+	"github.com/stretchr/testify/assert"
+)
+
+type Profile struct {
+	ID        int
+	Name      string
+	Email     string
+	Website   string `reltest:"format=url"`
+	Status    string `reltest:"enum=active|inactive"`
+	Level     int    `reltest:"enum=1|2|3"`
+	Secret    string `reltest:"-"`
+	Score     float64
+	Verified  *bool
+	CreatedAt time.Time
+	DeletedAt *time.Time
+	Tags      []string
+}
+
+func TestGenerate(t *testing.T) {
+	var (
+		profiles []Profile
+	)
+
+	Generate(&profiles, 50)
+	assert.Len(t, profiles, 50)
+
+	for i, profile := range profiles {
+		assert.Equal(t, i+1, profile.ID)
+		assert.NotEmpty(t, profile.Name)
+		assert.Regexp(t, `^[a-z]+@[a-z]+\.com$`, profile.Email)
+		assert.Regexp(t, `^https://`, profile.Website)
+		assert.Contains(t, []string{"active", "inactive"}, profile.Status)
+		assert.Contains(t, []int{1, 2, 3}, profile.Level)
+		assert.Empty(t, profile.Secret)
+		assert.NotNil(t, profile.Verified)
+		assert.False(t, profile.CreatedAt.IsZero())
+		assert.Nil(t, profile.DeletedAt)
+		assert.Nil(t, profile.Tags)
+	}
+}
+
+func TestGenerate_deterministic(t *testing.T) {
+	var (
+		result1 []Profile
+		result2 []Profile
+	)
+
+	Generate(&result1, 10)
+	Generate(&result2, 10)
+	assert.Equal(t, result1, result2)
+}
+
+func TestGenerate_pointer(t *testing.T) {
+	var (
+		books []*Book
+	)
+
+	Generate(&books, 2)
+	assert.Len(t, books, 2)
+	assert.Equal(t, 1, books[0].ID)
+	assert.Equal(t, 2, books[1].ID)
+	assert.NotEmpty(t, books[0].Title)
+	assert.Equal(t, Author{}, books[0].Author)
+	assert.Nil(t, books[0].Ratings)
+}
+
+func TestGenerate_overrides(t *testing.T) {
+	var (
+		books []Book
+	)
+
+	Generate(&books, 3, rel.Map{"author_id": 1}, func(book *Book) {
+		book.Views = book.ID * 10
+	})
+
+	for i, book := range books {
+		assert.Equal(t, 1, book.AuthorID)
+		assert.Equal(t, (i+1)*10, book.Views)
+	}
+}
+
+func TestGenerate_asResult(t *testing.T) {
+	var (
+		repo   = New()
+		books  []Book
+		result []Book
+	)
+
+	Generate(&books, 5)
+	repo.ExpectFindAll().Result(books)
+	assert.Nil(t, repo.FindAll(context.TODO(), &result))
+	assert.Equal(t, books, result)
+}
+
+func TestGenerate_invalid(t *testing.T) {
+	var (
+		books []Book
+	)
+
+	assert.Panics(t, func() {
+		Generate(books, 1)
+	})
+
+	assert.Panics(t, func() {
+		Generate(&books, 1, rel.Map{"unknown": 1})
+	})
+
+	assert.Panics(t, func() {
+		Generate(&books, 1, func(book Book) {})
+	})
+}
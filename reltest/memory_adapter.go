@@ -0,0 +1,112 @@
+package reltest
+
+import (
+	"context"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/adapter/memory"
+)
+
+// memoryAdapter stores records using in-memory adapter, it's used by stateful repository.
+// It embeds nopAdapter, so snapshot works the same, while auto increment is assigned by in-memory adapter.
+// Transaction is kept as a stack of in-memory adapter transactions, so the same adapter can be shared by repository and its transactions.
+type memoryAdapter struct {
+	*nopAdapter
+	adapters []*memory.Adapter
+}
+
+var _ rel.Adapter = (*memoryAdapter)(nil)
+
+func newMemoryAdapter() *memoryAdapter {
+	return &memoryAdapter{
+		nopAdapter: &nopAdapter{autoIncrement: true},
+		adapters:   []*memory.Adapter{memory.New()},
+	}
+}
+
+// reset discards stored records.
+func (ma *memoryAdapter) reset() {
+	ma.mutex.Lock()
+	defer ma.mutex.Unlock()
+
+	ma.adapters = []*memory.Adapter{memory.New()}
+}
+
+// current returns adapter of the innermost transaction.
+func (ma *memoryAdapter) current() *memory.Adapter {
+	ma.mutex.Lock()
+	defer ma.mutex.Unlock()
+
+	return ma.adapters[len(ma.adapters)-1]
+}
+
+func (ma *memoryAdapter) Capabilities() rel.Capabilities {
+	return ma.current().Capabilities()
+}
+
+// Begin transaction on top of the innermost transaction, transaction is not isolated from other calls.
+func (ma *memoryAdapter) Begin(ctx context.Context) (rel.Adapter, error) {
+	adapter, err := ma.current().Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ma.mutex.Lock()
+	defer ma.mutex.Unlock()
+
+	ma.adapters = append(ma.adapters, adapter.(*memory.Adapter))
+	return ma, nil
+}
+
+// Commit applies records of the innermost transaction to its parent.
+func (ma *memoryAdapter) Commit(ctx context.Context) error {
+	if adapter := ma.end(); adapter != nil {
+		return adapter.Commit(ctx)
+	}
+
+	return nil
+}
+
+// Rollback discards records of the innermost transaction.
+func (ma *memoryAdapter) Rollback(ctx context.Context) error {
+	ma.end()
+	return nil
+}
+
+func (ma *memoryAdapter) end() *memory.Adapter {
+	ma.mutex.Lock()
+	defer ma.mutex.Unlock()
+
+	if len(ma.adapters) == 1 {
+		return nil
+	}
+
+	adapter := ma.adapters[len(ma.adapters)-1]
+	ma.adapters = ma.adapters[:len(ma.adapters)-1]
+
+	return adapter
+}
+
+func (ma *memoryAdapter) Aggregate(ctx context.Context, query rel.Query, mode string, field string, loggers ...rel.Logger) (int, error) {
+	return ma.current().Aggregate(ctx, query, mode, field, loggers...)
+}
+
+func (ma *memoryAdapter) Query(ctx context.Context, query rel.Query, loggers ...rel.Logger) (rel.Cursor, error) {
+	return ma.current().Query(ctx, query, loggers...)
+}
+
+func (ma *memoryAdapter) Insert(ctx context.Context, query rel.Query, modifies map[string]rel.Modify, loggers ...rel.Logger) (interface{}, error) {
+	return ma.current().Insert(ctx, query, modifies, loggers...)
+}
+
+func (ma *memoryAdapter) InsertAll(ctx context.Context, query rel.Query, fields []string, bulkModifies []map[string]rel.Modify, loggers ...rel.Logger) ([]interface{}, error) {
+	return ma.current().InsertAll(ctx, query, fields, bulkModifies, loggers...)
+}
+
+func (ma *memoryAdapter) Update(ctx context.Context, query rel.Query, modifies map[string]rel.Modify, loggers ...rel.Logger) (int, error) {
+	return ma.current().Update(ctx, query, modifies, loggers...)
+}
+
+func (ma *memoryAdapter) Delete(ctx context.Context, query rel.Query, loggers ...rel.Logger) (int, error) {
+	return ma.current().Delete(ctx, query, loggers...)
+}
@@ -1,7 +1,9 @@
 package reltest
 
 import (
+	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/Fs02/rel"
 	"github.com/stretchr/testify/mock"
@@ -10,11 +12,13 @@ import (
 // Modify asserts and simulate insert or update function for test.
 type Modify struct {
 	*Expect
+	assertions []func(modifies map[string]rel.Modify) bool
 }
 
 // For match expect calls for given record.
+// Record can also be a function that accepts the record and returns bool for custom matching.
 func (m *Modify) For(record interface{}) *Modify {
-	m.Arguments[0] = record
+	m.Arguments[0] = matchRecord(record)
 	return m
 }
 
@@ -24,6 +28,56 @@ func (m *Modify) ForType(typ string) *Modify {
 	return m.For(mock.AnythingOfType("*" + strings.TrimPrefix(typ, "*")))
 }
 
+// ForChanges match expect calls that only changes the given fields.
+// Update using struct changes all fields of the record, so it won't match unless all fields are specified.
+func (m *Modify) ForChanges(fields ...string) *Modify {
+	return m.assert(func(modifies map[string]rel.Modify) bool {
+		if len(modifies) != len(fields) {
+			return false
+		}
+
+		for i := range fields {
+			if _, ok := modifies[fields[i]]; !ok {
+				return false
+			}
+		}
+
+		return true
+	})
+}
+
+// AssertChanged match expect calls that sets the field to the given value.
+// rel.Any can be used as value to match any value, example:
+//	repo.ExpectUpdate().ForChanges("status", "approved_at").AssertChanged("status", "approved").AssertChanged("approved_at", rel.Any)
+func (m *Modify) AssertChanged(field string, value interface{}) *Modify {
+	return m.assert(func(modifies map[string]rel.Modify) bool {
+		modify, ok := modifies[field]
+		return ok && modify.Type == rel.ChangeSetOp && matchValue(value, modify.Value)
+	})
+}
+
+func (m *Modify) assert(assertion func(modifies map[string]rel.Modify) bool) *Modify {
+	if m.Method == "InsertAll" {
+		panic("reltest: asserting changes of InsertAll is not supported")
+	}
+
+	m.assertions = append(m.assertions, assertion)
+	assertions := m.assertions
+
+	m.Arguments[2] = mock.MatchedBy(func(c *changes) bool {
+		modifies := c.modifies()
+		for i := range assertions {
+			if !assertions[i](modifies) {
+				return false
+			}
+		}
+
+		return true
+	})
+
+	return m
+}
+
 // NotUnique sets not unique error to be returned.
 func (m *Modify) NotUnique(key string) {
 	m.Error(rel.ConstraintError{
@@ -32,11 +86,32 @@ func (m *Modify) NotUnique(key string) {
 	})
 }
 
+// NotFound sets NotFoundError to be returned.
+func (m *Modify) NotFound() {
+	m.Error(rel.NotFoundError{})
+}
+
+// ForeignKeyViolated sets foreign key constraint error to be returned.
+func (m *Modify) ForeignKeyViolated(key string) {
+	m.Error(rel.ConstraintError{
+		Key:  key,
+		Type: rel.ForeignKeyConstraint,
+	})
+}
+
+// CheckViolated sets check constraint error to be returned.
+func (m *Modify) CheckViolated(key string) {
+	m.Error(rel.ConstraintError{
+		Key:  key,
+		Type: rel.CheckConstraint,
+	})
+}
+
 // ExpectModify to be called with given field and queries.
 func ExpectModify(r *Repository, methodName string, modifiers []rel.Modifier, insertion bool) *Modify {
 	em := &Modify{
 		Expect: newExpect(r, methodName,
-			[]interface{}{mock.Anything, modifiers},
+			[]interface{}{mock.Anything, modifiers, mock.Anything},
 			[]interface{}{nil},
 		),
 	}
@@ -44,14 +119,47 @@ func ExpectModify(r *Repository, methodName string, modifiers []rel.Modifier, in
 	return em
 }
 
-// ExpectInsertAll to be called.
-func ExpectInsertAll(r *Repository) *Modify {
+// ExpectInsertAll to be called with given on conflict option.
+func ExpectInsertAll(r *Repository, onConflict ...rel.OnConflict) *Modify {
 	em := &Modify{
 		Expect: newExpect(r, "InsertAll",
-			[]interface{}{mock.Anything},
+			[]interface{}{mock.Anything, onConflict},
 			[]interface{}{nil},
 		),
 	}
 
 	return em
 }
+
+// changes lazily computes changes that will be applied by modifiers to the record.
+type changes struct {
+	record    interface{}
+	modifiers []rel.Modifier
+	once      sync.Once
+	result    map[string]rel.Modify
+}
+
+func (c *changes) modifies() map[string]rel.Modify {
+	c.once.Do(func() {
+		// apply to the copy of record, so the record is not modified before the actual call.
+		rv := reflect.ValueOf(c.record)
+		if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+			return
+		}
+
+		var (
+			record    = reflect.New(rv.Type().Elem())
+			modifiers = c.modifiers
+		)
+
+		record.Elem().Set(rv.Elem())
+
+		if len(modifiers) == 0 {
+			modifiers = []rel.Modifier{rel.NewStructset(record.Interface(), false)}
+		}
+
+		c.result = rel.Apply(rel.NewDocument(record.Interface()), modifiers...).Modifies
+	})
+
+	return c.result
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/Fs02/rel"
 	"github.com/stretchr/testify/assert"
@@ -203,6 +204,24 @@ func TestModify_InsertAll(t *testing.T) {
 	repo.AssertExpectations(t)
 }
 
+func TestModify_InsertAll_onConflict(t *testing.T) {
+	var (
+		repo    = New()
+		results = []Book{
+			{Title: "Golang for dummies"},
+		}
+	)
+
+	repo.ExpectInsertAll(rel.OnConflictReplace())
+	assert.Nil(t, repo.InsertAll(context.TODO(), &results, rel.OnConflictReplace()))
+	repo.AssertExpectations(t)
+
+	repo.ExpectInsertAll(rel.OnConflictReplace())
+	assert.Panics(t, func() {
+		repo.MustInsertAll(context.TODO(), &results)
+	})
+}
+
 func TestModify_Update(t *testing.T) {
 	var (
 		repo   = New()
@@ -596,3 +615,148 @@ func TestModify_Update_notUnique(t *testing.T) {
 	)
 	repo.AssertExpectations(t)
 }
+
+func TestModify_Insert_autoIncrement(t *testing.T) {
+	type Event struct {
+		ID        int
+		Name      string
+		CreatedAt time.Time
+		UpdatedAt time.Time
+	}
+
+	var (
+		repo   = New().AutoIncrement()
+		book1  = Book{Title: "Golang for dummies"}
+		book2  = Book{Title: "Rel for dummies"}
+		event  = Event{Name: "created"}
+		events = []Event{{Name: "updated"}, {Name: "deleted"}}
+	)
+
+	repo.ExpectInsert().ForType("reltest.Book").Times(2)
+	repo.ExpectInsert().ForType("reltest.Event")
+	repo.ExpectInsertAll()
+
+	assert.Nil(t, repo.Insert(context.TODO(), &book1))
+	assert.Nil(t, repo.Insert(context.TODO(), &book2))
+	assert.Nil(t, repo.Insert(context.TODO(), &event))
+	assert.Nil(t, repo.InsertAll(context.TODO(), &events))
+	repo.AssertExpectations(t)
+
+	assert.Equal(t, 1, book1.ID)
+	assert.Equal(t, 2, book2.ID)
+	assert.Equal(t, 1, event.ID)
+	assert.False(t, event.CreatedAt.IsZero())
+	assert.False(t, event.UpdatedAt.IsZero())
+	assert.Equal(t, 2, events[0].ID)
+	assert.Equal(t, 3, events[1].ID)
+}
+
+func TestModify_Insert_autoIncrementTransaction(t *testing.T) {
+	var (
+		repo  = New().AutoIncrement()
+		book1 = Book{Title: "Golang for dummies"}
+		book2 = Book{Title: "Rel for dummies"}
+	)
+
+	repo.ExpectInsert()
+	repo.ExpectTransaction(func(repo *Repository) {
+		repo.ExpectInsert()
+	})
+
+	assert.Nil(t, repo.Insert(context.TODO(), &book1))
+	assert.Nil(t, repo.Transaction(context.TODO(), func(repo rel.Repository) error {
+		return repo.Insert(context.TODO(), &book2)
+	}))
+	repo.AssertExpectations(t)
+
+	assert.Equal(t, 1, book1.ID)
+	assert.Equal(t, 2, book2.ID)
+}
+
+func TestModify_Insert_forMatcher(t *testing.T) {
+	var (
+		repo   = New()
+		result = Book{Title: "Golang for dummies"}
+	)
+
+	repo.ExpectInsert().For(func(book *Book) bool {
+		return book.Title == "Golang for dummies"
+	})
+	assert.Nil(t, repo.Insert(context.TODO(), &result))
+	repo.AssertExpectations(t)
+
+	repo.ExpectInsert().For(func(book *Book) bool {
+		return book.Title == "Rel for dummies"
+	})
+	assert.Panics(t, func() {
+		_ = repo.Insert(context.TODO(), &result)
+	})
+}
+
+func TestModify_Update_forChanges(t *testing.T) {
+	var (
+		repo = New()
+		book = Book{ID: 1, Title: "Rel for dummies", Views: 10}
+	)
+
+	repo.ExpectUpdate(rel.Set("title", "REL for dummies")).ForChanges("title").AssertChanged("title", "REL for dummies")
+	assert.Nil(t, repo.Update(context.TODO(), &book, rel.Set("title", "REL for dummies")))
+	assert.Equal(t, "REL for dummies", book.Title)
+	repo.AssertExpectations(t)
+
+	repo.ExpectUpdate(rel.Set("title", "Rel"), rel.Inc("views")).ForChanges("title", "views").AssertChanged("title", rel.Any)
+	assert.Nil(t, repo.Update(context.TODO(), &book, rel.Set("title", "Rel"), rel.Inc("views")))
+	repo.AssertExpectations(t)
+}
+
+func TestModify_Update_forChangesStruct(t *testing.T) {
+	var (
+		repo = New()
+		book = Book{ID: 1, Title: "Rel for dummies", Views: 10}
+	)
+
+	repo.ExpectUpdate().ForChanges("title")
+	assert.Panics(t, func() {
+		repo.Update(context.TODO(), &book)
+	})
+
+	repo.ExpectUpdate().ForChanges("title", "author_id", "views").AssertChanged("views", 10)
+	assert.Nil(t, repo.Update(context.TODO(), &book))
+	assert.Equal(t, 10, book.Views)
+}
+
+func TestModify_Update_assertChangedMismatch(t *testing.T) {
+	var (
+		repo = New()
+		book = Book{ID: 1, Title: "Rel for dummies", Views: 10}
+	)
+
+	repo.ExpectUpdate().AssertChanged("title", "REL for dummies")
+	assert.Panics(t, func() {
+		repo.Update(context.TODO(), &book, rel.Set("title", "Rel"))
+	})
+
+	assert.Panics(t, func() {
+		repo.Update(context.TODO(), &book, rel.Inc("title"))
+	})
+
+	assert.Equal(t, "Rel for dummies", book.Title)
+}
+
+func TestModify_Insert_assertChanged(t *testing.T) {
+	var (
+		repo = New()
+		book = Book{Title: "Rel for dummies"}
+	)
+
+	repo.ExpectInsert().AssertChanged("title", "Rel for dummies")
+	assert.Nil(t, repo.Insert(context.TODO(), &book))
+	assert.Equal(t, 1, book.ID)
+	repo.AssertExpectations(t)
+}
+
+func TestModify_InsertAll_assertChanged(t *testing.T) {
+	assert.Panics(t, func() {
+		New().ExpectInsertAll().ForChanges("title")
+	})
+}
@@ -0,0 +1,78 @@
+package reltest
+
+import (
+	"reflect"
+
+	"github.com/Fs02/rel"
+	"github.com/stretchr/testify/mock"
+)
+
+// MutateAny asserts and simulate update any and delete any function for test.
+type MutateAny struct {
+	*Expect
+}
+
+// Result sets the number of affected records.
+func (ema *MutateAny) Result(count int) *MutateAny {
+	ema.Return(count, nil)
+	return ema
+}
+
+// Unsafe allows for unsafe mutation that doesn't contains where clause.
+func (ema *MutateAny) Unsafe() *MutateAny {
+	ema.RunFn = nil // clear validation
+	return ema
+}
+
+// ExpectUpdateAny to be called with given query and modifies, modifies are matched regardless of its order.
+func ExpectUpdateAny(r *Repository, query rel.Query, modifies []rel.Modify) *MutateAny {
+	return expectMutateAny(r, "UpdateAny", query,
+		[]interface{}{matchQueryArgument(query), matchModifies(modifies)},
+	)
+}
+
+// ExpectDeleteAny to be called with given query.
+func ExpectDeleteAny(r *Repository, query rel.Query) *MutateAny {
+	return expectMutateAny(r, "DeleteAny", query,
+		[]interface{}{matchQueryArgument(query)},
+	)
+}
+
+func expectMutateAny(r *Repository, methodName string, query rel.Query, args []interface{}) *MutateAny {
+	ema := &MutateAny{
+		Expect: expectQueriers(r, methodName, []rel.Querier{query}, args, []interface{}{0, nil}),
+	}
+
+	// validation
+	ema.Run(func(args mock.Arguments) {
+		query := args[0].(rel.Query)
+
+		if query.Table == "" {
+			panic("reltest: cannot call " + methodName + " without specifying table name. use rel.From(tableName)")
+		}
+
+		if query.WhereQuery.None() {
+			panic("reltest: unsafe " + methodName + " detected. if you want to mutate all records without filter, please use " + methodName + "().Unsafe()")
+		}
+	})
+
+	return ema
+}
+
+// matchModifies returns argument matcher that compares modifies regardless of its order.
+func matchModifies(modifies []rel.Modify) interface{} {
+	expected := modifiesMap(modifies)
+
+	return mock.MatchedBy(func(actual []rel.Modify) bool {
+		return reflect.DeepEqual(expected, modifiesMap(actual))
+	})
+}
+
+func modifiesMap(modifies []rel.Modify) map[string]rel.Modify {
+	result := make(map[string]rel.Modify, len(modifies))
+	for i := range modifies {
+		result[modifies[i].Field] = modifies[i]
+	}
+
+	return result
+}
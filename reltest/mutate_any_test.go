@@ -0,0 +1,134 @@
+package reltest
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateAny(t *testing.T) {
+	var (
+		repo  = New()
+		query = rel.From("books").Where(where.Eq("author_id", 1))
+	)
+
+	repo.ExpectUpdateAny(query, rel.Set("discontinued", true), rel.Inc("version")).Result(2)
+	updatedCount, err := repo.UpdateAny(context.TODO(), query, rel.Inc("version"), rel.Set("discontinued", true))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, updatedCount)
+	repo.AssertExpectations(t)
+
+	repo.ExpectUpdateAny(query, rel.Set("discontinued", true)).Result(3)
+	assert.NotPanics(t, func() {
+		assert.Equal(t, 3, repo.MustUpdateAny(context.TODO(), query, rel.Set("discontinued", true)))
+	})
+	repo.AssertExpectations(t)
+}
+
+func TestUpdateAny_mismatch(t *testing.T) {
+	var (
+		repo  = New()
+		query = rel.From("books").Where(where.Eq("author_id", 1))
+	)
+
+	repo.ExpectUpdateAny(query, rel.Set("discontinued", true))
+	assert.Panics(t, func() {
+		repo.MustUpdateAny(context.TODO(), query, rel.Set("discontinued", false))
+	})
+
+	assert.Panics(t, func() {
+		repo.MustUpdateAny(context.TODO(), rel.From("books").Where(where.Eq("author_id", 2)), rel.Set("discontinued", true))
+	})
+}
+
+func TestUpdateAny_error(t *testing.T) {
+	var (
+		repo  = New()
+		query = rel.From("books").Where(where.Eq("author_id", 1))
+	)
+
+	repo.ExpectUpdateAny(query, rel.Set("discontinued", true)).ConnectionClosed()
+	updatedCount, err := repo.UpdateAny(context.TODO(), query, rel.Set("discontinued", true))
+	assert.Equal(t, sql.ErrConnDone, err)
+	assert.Equal(t, 0, updatedCount)
+	repo.AssertExpectations(t)
+
+	repo.ExpectUpdateAny(query, rel.Set("discontinued", true)).ConnectionClosed()
+	assert.Panics(t, func() {
+		repo.MustUpdateAny(context.TODO(), query, rel.Set("discontinued", true))
+	})
+	repo.AssertExpectations(t)
+}
+
+func TestUpdateAny_unsafe(t *testing.T) {
+	var (
+		repo = New()
+	)
+
+	repo.ExpectUpdateAny(rel.From("books"), rel.Set("discontinued", true))
+	assert.Panics(t, func() {
+		repo.MustUpdateAny(context.TODO(), rel.From("books"), rel.Set("discontinued", true))
+	})
+	repo.AssertExpectations(t)
+
+	repo.ExpectUpdateAny(rel.From("books"), rel.Set("discontinued", true)).Unsafe().Result(10)
+	assert.NotPanics(t, func() {
+		assert.Equal(t, 10, repo.MustUpdateAny(context.TODO(), rel.From("books"), rel.Set("discontinued", true)))
+	})
+	repo.AssertExpectations(t)
+}
+
+func TestDeleteAny(t *testing.T) {
+	var (
+		repo  = New()
+		query = rel.From("books").Where(where.Eq("author_id", 1))
+	)
+
+	repo.ExpectDeleteAny(query).Result(2)
+	deletedCount, err := repo.DeleteAny(context.TODO(), query)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, deletedCount)
+	repo.AssertExpectations(t)
+
+	repo.ExpectDeleteAny(query).ConnectionClosed()
+	assert.Panics(t, func() {
+		repo.MustDeleteAny(context.TODO(), query)
+	})
+	repo.AssertExpectations(t)
+}
+
+func TestDeleteAny_noTable(t *testing.T) {
+	var (
+		repo = New()
+	)
+
+	repo.ExpectDeleteAny(rel.Query{})
+	assert.Panics(t, func() {
+		repo.MustDeleteAny(context.TODO(), rel.Query{})
+	})
+	repo.AssertExpectations(t)
+}
+
+func TestMutateAny_stateful(t *testing.T) {
+	var (
+		ctx   = context.TODO()
+		repo  = NewStateful()
+		books = []Book{{Title: "a", Views: 1}, {Title: "b", Views: 1}, {Title: "c", Views: 5}}
+	)
+
+	assert.Nil(t, repo.InsertAll(ctx, &books))
+
+	updatedCount, err := repo.UpdateAny(ctx, rel.From("books").Where(where.Eq("views", 1)), rel.Inc("views"))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, updatedCount)
+	assert.Equal(t, 2, repo.MustCount(ctx, "books", where.Eq("views", 2)))
+
+	deletedCount, err := repo.DeleteAny(ctx, rel.From("books").Where(where.Eq("views", 2)))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, deletedCount)
+	assert.Equal(t, 1, repo.MustCount(ctx, "books"))
+}
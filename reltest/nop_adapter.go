@@ -2,14 +2,86 @@ package reltest
 
 import (
 	"context"
+	"strings"
+	"sync"
 
 	"github.com/Fs02/rel"
 	"github.com/stretchr/testify/mock"
 )
 
 type nopAdapter struct {
-	mock  mock.Mock
-	count int
+	mock          mock.Mock
+	count         int
+	mutex         sync.Mutex
+	autoIncrement bool
+	sequences     map[string]int
+	mutations     []mutation
+	snapshotting  bool
+}
+
+func (na *nopAdapter) setAutoIncrement(autoIncrement bool) {
+	na.mutex.Lock()
+	defer na.mutex.Unlock()
+
+	na.autoIncrement = autoIncrement
+}
+
+// reset sequences and recorded mutations.
+func (na *nopAdapter) reset() {
+	na.mutex.Lock()
+	defer na.mutex.Unlock()
+
+	na.sequences = nil
+	na.mutations = nil
+}
+
+func (na *nopAdapter) startSnapshot() {
+	na.mutex.Lock()
+	defer na.mutex.Unlock()
+
+	na.snapshotting = true
+	na.mutations = nil
+}
+
+func (na *nopAdapter) snapshotStarted() bool {
+	na.mutex.Lock()
+	defer na.mutex.Unlock()
+
+	return na.snapshotting
+}
+
+func (na *nopAdapter) addMutation(m mutation) {
+	na.mutex.Lock()
+	defer na.mutex.Unlock()
+
+	na.mutations = append(na.mutations, m)
+}
+
+// snapshot formats recorded mutations separated by empty line.
+func (na *nopAdapter) snapshot() string {
+	na.mutex.Lock()
+	defer na.mutex.Unlock()
+
+	result := make([]string, len(na.mutations))
+	for i := range na.mutations {
+		result[i] = na.mutations[i].String()
+	}
+
+	return strings.Join(result, "\n")
+}
+
+// increment sequence of the table, caller must hold the mutex.
+func (na *nopAdapter) increment(table string) int {
+	if na.sequences == nil {
+		na.sequences = make(map[string]int)
+	}
+
+	na.sequences[table]++
+	return na.sequences[table]
+}
+
+func (na *nopAdapter) Capabilities() rel.Capabilities {
+	return rel.TransactionCapability | rel.SavepointCapability | rel.JoinCapability | rel.GroupCapability | rel.WindowCapability
 }
 
 func (na *nopAdapter) Ping(ctx context.Context) error {
@@ -33,6 +105,13 @@ func (na *nopAdapter) Delete(ctx context.Context, query rel.Query, loggers ...re
 }
 
 func (na *nopAdapter) Insert(ctx context.Context, query rel.Query, modifies map[string]rel.Modify, loggers ...rel.Logger) (interface{}, error) {
+	na.mutex.Lock()
+	defer na.mutex.Unlock()
+
+	if na.autoIncrement {
+		return na.increment(query.Table), nil
+	}
+
 	return 1, nil
 }
 
@@ -41,8 +120,15 @@ func (na *nopAdapter) InsertAll(ctx context.Context, query rel.Query, fields []s
 		ids = make([]interface{}, len(bulkModifies))
 	)
 
+	na.mutex.Lock()
+	defer na.mutex.Unlock()
+
 	for i := range bulkModifies {
-		ids[i] = i + 1
+		if na.autoIncrement {
+			ids[i] = na.increment(query.Table)
+		} else {
+			ids[i] = i + 1
+		}
 	}
 
 	return ids, nil
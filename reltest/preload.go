@@ -14,7 +14,7 @@ type Preload struct {
 }
 
 // Result sets the result of Preload query.
-func (p *Preload) Result(records interface{}) {
+func (p *Preload) Result(records interface{}) *Preload {
 	p.Run(func(args mock.Arguments) {
 		var (
 			target = asSlice(args[0], false)
@@ -24,11 +24,24 @@ func (p *Preload) Result(records interface{}) {
 
 		preload(target, result, path)
 	})
+
+	return p
+}
+
+// Then sets the result of the next call of this Preload query.
+func (p *Preload) Then(records interface{}) *Preload {
+	return (&Preload{Expect: p.then(nil)}).Result(records)
+}
+
+// ThenError sets error to be returned by the next call of this Preload query.
+func (p *Preload) ThenError(err error) *Preload {
+	return &Preload{Expect: p.then(err)}
 }
 
 // For match expect calls for given record.
+// Record can also be a function that accepts the record and returns bool for custom matching.
 func (p *Preload) For(record interface{}) *Preload {
-	p.Arguments[0] = record
+	p.Arguments[0] = matchRecord(record)
 	return p
 }
 
@@ -41,8 +54,8 @@ func (p *Preload) ForType(typ string) *Preload {
 // ExpectPreload to be called with given field and queries.
 func ExpectPreload(r *Repository, field string, queriers []rel.Querier) *Preload {
 	return &Preload{
-		Expect: newExpect(r, "Preload",
-			[]interface{}{mock.Anything, field, queriers},
+		Expect: expectQueriers(r, "Preload", queriers,
+			[]interface{}{mock.Anything, field, matchQueriers(queriers)},
 			[]interface{}{nil},
 		),
 	}
@@ -95,7 +108,7 @@ func preload(target slice, result slice, path []string) {
 			n       = len(stack) - 1
 			top     = stack[n]
 			assocs  = top.doc.Association(path[top.index])
-			hasMany = assocs.Type() == rel.HasMany
+			hasMany = assocs.Type() == rel.HasMany || assocs.Type() == rel.ManyToMany
 		)
 
 		stack = stack[:n]
@@ -119,6 +132,12 @@ func preload(target slice, result slice, path []string) {
 
 			curr.Reset()
 
+			// join table is not available, every result is associated to each record.
+			if assocs.Type() == rel.ManyToMany {
+				curr.ReflectValue().Set(result.ReflectValue())
+				continue
+			}
+
 			if mappedResult == nil {
 				mappedResult = mapResult(result, fField, hasMany)
 			}
@@ -127,7 +146,7 @@ func preload(target slice, result slice, path []string) {
 				curr.ReflectValue().Set(rv)
 			}
 		} else {
-			if assocs.Type() == rel.HasMany {
+			if hasMany {
 				var (
 					col, loaded = assocs.Collection()
 				)
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"testing"
 
+	"github.com/Fs02/rel/where"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -28,6 +29,25 @@ func TestPreload(t *testing.T) {
 	repo.AssertExpectations(t)
 }
 
+func TestPreload_then(t *testing.T) {
+	var (
+		repo   = New()
+		result = Book{ID: 2, Title: "Rel for dummies", AuthorID: 1}
+		author = Author{ID: 1, Name: "Kia"}
+	)
+
+	preload := repo.ExpectPreload("author")
+	preload.ConnectionClosed()
+	preload.Then(author)
+
+	assert.Equal(t, sql.ErrConnDone, repo.Preload(context.TODO(), &result, "author"))
+	assert.Equal(t, Author{}, result.Author)
+
+	assert.Nil(t, repo.Preload(context.TODO(), &result, "author"))
+	assert.Equal(t, author, result.Author)
+	repo.AssertExpectations(t)
+}
+
 func TestPreload_nested(t *testing.T) {
 	var (
 		repo   = New()
@@ -123,6 +143,96 @@ func TestPreload_sliceNested(t *testing.T) {
 	repo.AssertExpectations(t)
 }
 
+func TestPreload_hasOne(t *testing.T) {
+	var (
+		repo   = New()
+		result = []Book{
+			{ID: 1, Title: "Golang for dummies"},
+			{ID: 2, Title: "Rel for dummies"},
+		}
+		posters = []Poster{
+			{ID: 1, BookID: 2, Image: "http://image.url/2"},
+			{ID: 2, BookID: 1, Image: "http://image.url/1"},
+		}
+	)
+
+	repo.ExpectPreload("poster").Result(posters)
+	assert.Nil(t, repo.Preload(context.TODO(), &result, "poster"))
+	assert.Equal(t, posters[1], result[0].Poster)
+	assert.Equal(t, posters[0], result[1].Poster)
+	repo.AssertExpectations(t)
+}
+
+func TestPreloadAll(t *testing.T) {
+	var (
+		repo   = New()
+		result = Book{ID: 2, Title: "Rel for dummies", AuthorID: 1}
+		author = Author{ID: 1, Name: "Kia"}
+		poster = Poster{ID: 1, BookID: 2, Image: "http://image.url/2"}
+	)
+
+	repo.ExpectPreload("author").Result(author)
+	repo.ExpectPreload("poster").Result(poster)
+	assert.Nil(t, repo.PreloadAll(context.TODO(), &result, "author", "poster"))
+	assert.Equal(t, author, result.Author)
+	assert.Equal(t, poster, result.Poster)
+	repo.AssertExpectations(t)
+
+	repo.ExpectPreload("author").ConnectionClosed()
+	assert.Panics(t, func() {
+		repo.MustPreloadAll(context.TODO(), &result, "author", "poster")
+	})
+	repo.AssertExpectations(t)
+}
+
+func TestPreloadMap(t *testing.T) {
+	var (
+		repo    = New()
+		books   = []Book{{ID: 1}, {ID: 2}}
+		ratings = []Rating{
+			{ID: 1, BookID: 1, Score: 9},
+			{ID: 2, BookID: 1, Score: 10},
+			{ID: 3, BookID: 2, Score: 8},
+		}
+	)
+
+	repo.ExpectPreload("ratings").Result(ratings)
+	result, err := repo.PreloadMap(context.TODO(), &books, "ratings")
+	assert.Nil(t, err)
+	assert.Equal(t, map[interface{}]interface{}{
+		1: ratings[:2],
+		2: ratings[2:],
+	}, result)
+	repo.AssertExpectations(t)
+
+	repo.ExpectPreload("ratings").ConnectionClosed()
+	assert.Panics(t, func() {
+		repo.MustPreloadMap(context.TODO(), &books[0], "ratings")
+	})
+	repo.AssertExpectations(t)
+}
+
+func TestPreload_query(t *testing.T) {
+	var (
+		repo    = New()
+		result  = Book{ID: 1, Title: "Golang for dummies"}
+		ratings = []Rating{
+			{ID: 1, BookID: 1, Score: 9},
+			{ID: 2, BookID: 1, Score: 10},
+		}
+	)
+
+	repo.ExpectPreload("ratings", where.Gte("score", 9)).Result(ratings)
+	assert.Nil(t, repo.Preload(context.TODO(), &result, "ratings", where.Gte("score", 9)))
+	assert.Equal(t, ratings, result.Ratings)
+	repo.AssertExpectations(t)
+
+	repo.ExpectPreload("ratings", where.Gte("score", 9)).Result(ratings)
+	assert.Panics(t, func() {
+		repo.MustPreload(context.TODO(), &result, "ratings", where.Gte("score", 5))
+	})
+}
+
 func TestPreload_nilReferenceValue(t *testing.T) {
 	var (
 		repo   = New()
@@ -199,3 +309,21 @@ func TestPreload_error(t *testing.T) {
 	})
 	repo.AssertExpectations(t)
 }
+
+func TestPreload_manyToMany(t *testing.T) {
+	type Shelf struct {
+		ID    int
+		Books []Book `through:"shelf_books"`
+	}
+
+	var (
+		repo   = New()
+		result = Shelf{ID: 1}
+		books  = []Book{{ID: 2, Title: "Rel for dummies"}, {ID: 3, Title: "Rel for experts"}}
+	)
+
+	repo.ExpectPreload("books").Result(books)
+	assert.Nil(t, repo.Preload(context.TODO(), &result, "books"))
+	assert.Equal(t, books, result.Books)
+	repo.AssertExpectations(t)
+}
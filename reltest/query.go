@@ -0,0 +1,245 @@
+package reltest
+
+import (
+	"reflect"
+
+	"github.com/Fs02/rel"
+	"github.com/stretchr/testify/mock"
+)
+
+// QueryMatcher is a querier that matches actual query using custom function.
+// It can be passed to any expectation that accepts queriers, example:
+//	repo.ExpectFindAll(reltest.QueryMatcher(func(query rel.Query) bool {
+//		return query.LimitQuery <= 100
+//	}))
+type QueryMatcher func(query rel.Query) bool
+
+// Build query, query matcher doesn't modify the query.
+func (qm QueryMatcher) Build(query *rel.Query) {}
+
+// QueryContains returns query matcher that matches when actual query contains all of the given queriers.
+// Actual query may have additional filters, sorts, joins or other parts that are not specified.
+func QueryContains(queriers ...rel.Querier) QueryMatcher {
+	expected := rel.Build("", queriers...)
+
+	return func(actual rel.Query) bool {
+		return containsQuery(expected, actual)
+	}
+}
+
+// matchQueriers returns argument matcher that structurally matches actual queriers against expected queriers.
+// When expected queriers contains query matcher, the rest of queriers only needs to be contained in actual query.
+func matchQueriers(queriers []rel.Querier) interface{} {
+	var (
+		matchers []QueryMatcher
+		plain    = make([]rel.Querier, 0, len(queriers))
+	)
+
+	for i := range queriers {
+		if matcher, ok := queriers[i].(QueryMatcher); ok {
+			matchers = append(matchers, matcher)
+		} else {
+			plain = append(plain, queriers[i])
+		}
+	}
+
+	expected := rel.Build("", plain...)
+
+	return mock.MatchedBy(func(actual []rel.Querier) bool {
+		query := rel.Build("", actual...)
+
+		if len(matchers) == 0 {
+			return matchQuery(expected, query)
+		}
+
+		if !containsQuery(expected, query) {
+			return false
+		}
+
+		for i := range matchers {
+			if !matchers[i](query) {
+				return false
+			}
+		}
+
+		return true
+	})
+}
+
+// matchQueryArgument returns argument matcher that structurally matches actual query against expected query.
+func matchQueryArgument(query rel.Query) interface{} {
+	return mock.MatchedBy(func(actual rel.Query) bool {
+		return matchQuery(query, actual)
+	})
+}
+
+// matchQuery compares query structurally, filters are compared regardless of its order,
+// and rel.Any can be used as filter value to match any value.
+func matchQuery(expected rel.Query, actual rel.Query) bool {
+	return expected.Table == actual.Table &&
+		reflect.DeepEqual(expected.SelectQuery, actual.SelectQuery) &&
+		reflect.DeepEqual(expected.JoinQuery, actual.JoinQuery) &&
+		matchFilter(expected.WhereQuery, actual.WhereQuery) &&
+		reflect.DeepEqual(expected.GroupQuery, actual.GroupQuery) &&
+		reflect.DeepEqual(expected.SortQuery, actual.SortQuery) &&
+		expected.OffsetQuery == actual.OffsetQuery &&
+		expected.LimitQuery == actual.LimitQuery &&
+		expected.LockQuery == actual.LockQuery &&
+		expected.UnscopedQuery == actual.UnscopedQuery &&
+		expected.ReadFromPrimaryQuery == actual.ReadFromPrimaryQuery
+}
+
+// containsQuery checks whether every part specified in expected query exists in actual query.
+func containsQuery(expected rel.Query, actual rel.Query) bool {
+	return containsValue(expected.Table, actual.Table) &&
+		containsValue(expected.SelectQuery, actual.SelectQuery) &&
+		containsElements(expected.JoinQuery, actual.JoinQuery) &&
+		containsFilter(expected.WhereQuery, actual.WhereQuery) &&
+		containsValue(expected.GroupQuery, actual.GroupQuery) &&
+		containsElements(expected.SortQuery, actual.SortQuery) &&
+		containsValue(expected.OffsetQuery, actual.OffsetQuery) &&
+		containsValue(expected.LimitQuery, actual.LimitQuery) &&
+		containsValue(expected.LockQuery, actual.LockQuery) &&
+		containsValue(expected.UnscopedQuery, actual.UnscopedQuery) &&
+		containsValue(expected.ReadFromPrimaryQuery, actual.ReadFromPrimaryQuery)
+}
+
+func containsValue(expected interface{}, actual interface{}) bool {
+	return reflect.ValueOf(expected).IsZero() || reflect.DeepEqual(expected, actual)
+}
+
+func containsElements(expected interface{}, actual interface{}) bool {
+	var (
+		ev = reflect.ValueOf(expected)
+		av = reflect.ValueOf(actual)
+	)
+
+	for i := 0; i < ev.Len(); i++ {
+		found := false
+
+		for j := 0; j < av.Len() && !found; j++ {
+			found = reflect.DeepEqual(ev.Index(i).Interface(), av.Index(j).Interface())
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// containsFilter checks whether every expected conditions exists in actual filter.
+func containsFilter(expected rel.FilterQuery, actual rel.FilterQuery) bool {
+	var (
+		conditions = conjunctions(flattenFilter(expected))
+		candidates = conjunctions(flattenFilter(actual))
+	)
+
+	for _, e := range conditions {
+		found := false
+
+		for i := 0; i < len(candidates) && !found; i++ {
+			found = matchFilter(e, candidates[i])
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func conjunctions(filter rel.FilterQuery) []rel.FilterQuery {
+	if filter.Type == rel.FilterAndOp {
+		return filter.Inner
+	}
+
+	return []rel.FilterQuery{filter}
+}
+
+func matchFilter(expected rel.FilterQuery, actual rel.FilterQuery) bool {
+	expected, actual = flattenFilter(expected), flattenFilter(actual)
+
+	if expected.Type != actual.Type || expected.Field != actual.Field || len(expected.Inner) != len(actual.Inner) {
+		return false
+	}
+
+	if !matchValue(expected.Value, actual.Value) {
+		return false
+	}
+
+	// inner filters may appear in any order, each expected filter must match distinct actual filter.
+	var (
+		matched = make([]bool, len(actual.Inner))
+	)
+
+	for _, e := range expected.Inner {
+		found := false
+
+		for i, a := range actual.Inner {
+			if !matched[i] && matchFilter(e, a) {
+				matched[i] = true
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// flattenFilter merges nested and/or filter with the same operator, and unwraps and/or with single inner filter.
+func flattenFilter(filter rel.FilterQuery) rel.FilterQuery {
+	if filter.Type != rel.FilterAndOp && filter.Type != rel.FilterOrOp {
+		return filter
+	}
+
+	if len(filter.Inner) == 1 {
+		return flattenFilter(filter.Inner[0])
+	}
+
+	var (
+		inner = make([]rel.FilterQuery, 0, len(filter.Inner))
+	)
+
+	for _, f := range filter.Inner {
+		f = flattenFilter(f)
+		if f.Type == filter.Type {
+			inner = append(inner, f.Inner...)
+		} else {
+			inner = append(inner, f)
+		}
+	}
+
+	filter.Inner = inner
+	return filter
+}
+
+func matchValue(expected interface{}, actual interface{}) bool {
+	if expected == rel.Any {
+		return true
+	}
+
+	if values, ok := expected.([]interface{}); ok {
+		actualValues, ok := actual.([]interface{})
+		if !ok || len(values) != len(actualValues) {
+			return false
+		}
+
+		for i := range values {
+			if !matchValue(values[i], actualValues[i]) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return reflect.DeepEqual(expected, actual)
+}
@@ -0,0 +1,210 @@
+package reltest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected rel.Query
+		actual   rel.Query
+		match    bool
+	}{
+		{
+			name:     "equal",
+			expected: rel.From("books").Where(where.Eq("id", 1)),
+			actual:   rel.From("books").Where(where.Eq("id", 1)),
+			match:    true,
+		},
+		{
+			name:     "different table",
+			expected: rel.From("books"),
+			actual:   rel.From("users"),
+			match:    false,
+		},
+		{
+			name:     "filter in any order",
+			expected: rel.From("books").Where(where.Eq("id", 1), where.Eq("status", "published")),
+			actual:   rel.From("books").Where(where.Eq("status", "published")).Where(where.Eq("id", 1)),
+			match:    true,
+		},
+		{
+			name:     "nested and",
+			expected: rel.From("books").Where(where.Eq("id", 1), where.Eq("status", "published"), where.Lt("price", 10)),
+			actual:   rel.From("books").Where(where.And(where.Eq("status", "published"), where.Lt("price", 10)), where.Eq("id", 1)),
+			match:    true,
+		},
+		{
+			name:     "or in any order",
+			expected: rel.From("books").Where(where.Or(where.Eq("id", 1), where.Eq("id", 2))),
+			actual:   rel.From("books").Where(where.Or(where.Eq("id", 2), where.Eq("id", 1))),
+			match:    true,
+		},
+		{
+			name:     "different filter",
+			expected: rel.From("books").Where(where.Eq("id", 1), where.Eq("status", "published")),
+			actual:   rel.From("books").Where(where.Eq("id", 1), where.Eq("status", "draft")),
+			match:    false,
+		},
+		{
+			name:     "missing filter",
+			expected: rel.From("books").Where(where.Eq("id", 1), where.Eq("status", "published")),
+			actual:   rel.From("books").Where(where.Eq("id", 1)),
+			match:    false,
+		},
+		{
+			name:     "any value",
+			expected: rel.From("books").Where(where.Eq("status", rel.Any)),
+			actual:   rel.From("books").Where(where.Eq("status", "published")),
+			match:    true,
+		},
+		{
+			name:     "any value in list",
+			expected: rel.From("books").Where(where.In("id", 1, rel.Any)),
+			actual:   rel.From("books").Where(where.In("id", 1, 2)),
+			match:    true,
+		},
+		{
+			name:     "any value with different field",
+			expected: rel.From("books").Where(where.Eq("status", rel.Any)),
+			actual:   rel.From("books").Where(where.Eq("title", "published")),
+			match:    false,
+		},
+		{
+			name:     "limit and sort",
+			expected: rel.From("books").Limit(10).Offset(5).SortAsc("id"),
+			actual:   rel.From("books").SortAsc("id").Offset(5).Limit(10),
+			match:    true,
+		},
+		{
+			name:     "different limit",
+			expected: rel.From("books").Limit(10),
+			actual:   rel.From("books").Limit(5),
+			match:    false,
+		},
+		{
+			name:     "different sort",
+			expected: rel.From("books").SortAsc("id"),
+			actual:   rel.From("books").SortDesc("id"),
+			match:    false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.match, matchQuery(test.expected, test.actual))
+		})
+	}
+}
+
+func TestFindAll_anyValue(t *testing.T) {
+	var (
+		repo   = New()
+		result []Book
+		books  = []Book{{ID: 1, Title: "Golang for dummies"}}
+	)
+
+	repo.ExpectFindAll(where.Eq("status", rel.Any), rel.Limit(10), where.Like("title", "%dummies%")).Result(books)
+	assert.Nil(t, repo.FindAll(context.TODO(), &result, where.Like("title", "%dummies%").AndEq("status", "published"), rel.Limit(10)))
+	assert.Equal(t, books, result)
+	repo.AssertExpectations(t)
+}
+
+func TestQueryContains(t *testing.T) {
+	var (
+		actual = rel.From("books").
+			Where(where.Eq("status", "published"), where.Lt("price", 10), where.Eq("deleted", false)).
+			SortAsc("id").
+			Limit(10)
+	)
+
+	tests := []struct {
+		name     string
+		queriers []rel.Querier
+		match    bool
+	}{
+		{
+			name:  "empty",
+			match: true,
+		},
+		{
+			name:     "filter",
+			queriers: []rel.Querier{where.Eq("status", "published")},
+			match:    true,
+		},
+		{
+			name:     "multiple filter",
+			queriers: []rel.Querier{where.Eq("deleted", false).AndEq("status", rel.Any)},
+			match:    true,
+		},
+		{
+			name:     "filter not found",
+			queriers: []rel.Querier{where.Eq("status", "draft")},
+			match:    false,
+		},
+		{
+			name:     "limit and sort",
+			queriers: []rel.Querier{rel.Limit(10), rel.NewSortAsc("id")},
+			match:    true,
+		},
+		{
+			name:     "different limit",
+			queriers: []rel.Querier{rel.Limit(5)},
+			match:    false,
+		},
+		{
+			name:     "different table",
+			queriers: []rel.Querier{rel.From("users")},
+			match:    false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.match, QueryContains(test.queriers...)(actual))
+		})
+	}
+}
+
+func TestFindAll_queryContains(t *testing.T) {
+	var (
+		repo   = New()
+		result []Book
+		books  = []Book{{ID: 1, Title: "Golang for dummies"}}
+	)
+
+	repo.ExpectFindAll(QueryContains(where.Eq("status", "published"))).Result(books)
+	assert.Nil(t, repo.FindAll(context.TODO(), &result, where.Like("title", "%dummies%").AndEq("status", "published"), rel.Limit(10)))
+	assert.Equal(t, books, result)
+	repo.AssertExpectations(t)
+
+	repo.ExpectFindAll(QueryContains(where.Eq("status", "published")))
+	assert.Panics(t, func() {
+		repo.FindAll(context.TODO(), &result, where.Eq("status", "draft"))
+	})
+}
+
+func TestFindAll_queryMatcher(t *testing.T) {
+	var (
+		repo   = New()
+		result []Book
+		limit  = QueryMatcher(func(query rel.Query) bool {
+			return query.LimitQuery > 0 && query.LimitQuery <= 100
+		})
+	)
+
+	repo.ExpectFindAll(limit, where.Eq("status", "published"))
+	assert.Nil(t, repo.FindAll(context.TODO(), &result, where.Eq("status", "published").AndLt("price", 10), rel.Limit(20)))
+	repo.AssertExpectations(t)
+
+	repo.ExpectFindAll(limit)
+	assert.Panics(t, func() {
+		repo.FindAll(context.TODO(), &result, where.Eq("status", "published"))
+	})
+}
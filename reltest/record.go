@@ -0,0 +1,564 @@
+package reltest
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Fs02/rel"
+)
+
+// Recording of adapter calls, stored as golden file.
+type Recording struct {
+	Capabilities rel.Capabilities `json:"capabilities"`
+	Calls        []RecordedCall   `json:"calls"`
+}
+
+// RecordedCall is a single adapter call with its result.
+type RecordedCall struct {
+	Method string            `json:"method"`
+	Table  string            `json:"table,omitempty"`
+	Count  int               `json:"count,omitempty"`
+	IDs    []RecordedValue   `json:"ids,omitempty"`
+	Fields []string          `json:"fields,omitempty"`
+	Rows   [][]RecordedValue `json:"rows,omitempty"`
+	Error  string            `json:"error,omitempty"`
+}
+
+// RecordedValue is a value with its type, so it can be restored as is when replayed.
+type RecordedValue struct {
+	Type  string `json:"type"`
+	Value string `json:"value,omitempty"`
+}
+
+func recordValue(value interface{}) RecordedValue {
+	switch v := value.(type) {
+	case nil:
+		return RecordedValue{Type: "nil"}
+	case bool:
+		return RecordedValue{Type: "bool", Value: strconv.FormatBool(v)}
+	case int, int8, int16, int32, int64:
+		return RecordedValue{Type: "int", Value: strconv.FormatInt(reflect.ValueOf(v).Int(), 10)}
+	case uint, uint8, uint16, uint32, uint64:
+		return RecordedValue{Type: "uint", Value: strconv.FormatUint(reflect.ValueOf(v).Uint(), 10)}
+	case float32, float64:
+		return RecordedValue{Type: "float", Value: strconv.FormatFloat(reflect.ValueOf(v).Float(), 'g', -1, 64)}
+	case []byte:
+		return RecordedValue{Type: "bytes", Value: base64.StdEncoding.EncodeToString(v)}
+	case time.Time:
+		return RecordedValue{Type: "time", Value: v.Format(time.RFC3339Nano)}
+	default:
+		return RecordedValue{Type: "string", Value: fmt.Sprint(v)}
+	}
+}
+
+// Interface returns the original value.
+func (rv RecordedValue) Interface() (interface{}, error) {
+	switch rv.Type {
+	case "nil":
+		return nil, nil
+	case "bool":
+		return strconv.ParseBool(rv.Value)
+	case "int":
+		return strconv.ParseInt(rv.Value, 10, 64)
+	case "uint":
+		return strconv.ParseUint(rv.Value, 10, 64)
+	case "float":
+		return strconv.ParseFloat(rv.Value, 64)
+	case "bytes":
+		return base64.StdEncoding.DecodeString(rv.Value)
+	case "time":
+		return time.Parse(time.RFC3339Nano, rv.Value)
+	case "string":
+		return rv.Value, nil
+	default:
+		return nil, errors.New("reltest: unknown recorded value type " + rv.Type)
+	}
+}
+
+func recordError(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
+
+func replayError(err string) error {
+	if err == "" {
+		return nil
+	}
+
+	return errors.New(err)
+}
+
+type recording struct {
+	mutex sync.Mutex
+	Recording
+}
+
+func (r *recording) add(call RecordedCall) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.Calls = append(r.Calls, call)
+}
+
+// Recorder is an adapter that proxies calls to real adapter and records each call with its result.
+// Recorded calls can be saved as golden file and replayed later using Replay without database.
+type Recorder struct {
+	adapter   rel.Adapter
+	recording *recording
+}
+
+var _ rel.Adapter = (*Recorder)(nil)
+
+// Capabilities of the underlying adapter.
+func (r *Recorder) Capabilities() rel.Capabilities {
+	return r.adapter.Capabilities()
+}
+
+// Ping database using the underlying adapter.
+func (r *Recorder) Ping(ctx context.Context) error {
+	return r.adapter.Ping(ctx)
+}
+
+// Aggregate and record the result.
+func (r *Recorder) Aggregate(ctx context.Context, query rel.Query, mode string, field string, loggers ...rel.Logger) (int, error) {
+	count, err := r.adapter.Aggregate(ctx, query, mode, field, loggers...)
+	r.recording.add(RecordedCall{Method: "Aggregate", Table: query.Table, Count: count, Error: recordError(err)})
+	return count, err
+}
+
+// Query and record all rows returned by the cursor.
+func (r *Recorder) Query(ctx context.Context, query rel.Query, loggers ...rel.Logger) (rel.Cursor, error) {
+	var (
+		call     = RecordedCall{Method: "Query", Table: query.Table}
+		cur, err = r.adapter.Query(ctx, query, loggers...)
+	)
+
+	if err == nil {
+		call.Fields, call.Rows, err = recordRows(cur)
+	}
+
+	call.Error = recordError(err)
+	r.recording.add(call)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newReplayCursor(call.Fields, call.Rows), nil
+}
+
+func recordRows(cur rel.Cursor) ([]string, [][]RecordedValue, error) {
+	defer cur.Close()
+
+	fields, err := cur.Fields()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		rows [][]RecordedValue
+	)
+
+	for cur.Next() {
+		var (
+			values = make([]interface{}, len(fields))
+			dest   = make([]interface{}, len(fields))
+			row    = make([]RecordedValue, len(fields))
+		)
+
+		for i := range values {
+			dest[i] = &values[i]
+		}
+
+		if err := cur.Scan(dest...); err != nil {
+			return nil, nil, err
+		}
+
+		for i := range values {
+			row[i] = recordValue(values[i])
+		}
+
+		rows = append(rows, row)
+	}
+
+	return fields, rows, nil
+}
+
+// Insert and record the returned id.
+func (r *Recorder) Insert(ctx context.Context, query rel.Query, modifies map[string]rel.Modify, loggers ...rel.Logger) (interface{}, error) {
+	id, err := r.adapter.Insert(ctx, query, modifies, loggers...)
+	r.recording.add(RecordedCall{Method: "Insert", Table: query.Table, IDs: []RecordedValue{recordValue(id)}, Error: recordError(err)})
+	return id, err
+}
+
+// InsertAll and record the returned ids.
+func (r *Recorder) InsertAll(ctx context.Context, query rel.Query, fields []string, bulkModifies []map[string]rel.Modify, loggers ...rel.Logger) ([]interface{}, error) {
+	var (
+		ids, err = r.adapter.InsertAll(ctx, query, fields, bulkModifies, loggers...)
+		call     = RecordedCall{Method: "InsertAll", Table: query.Table, IDs: make([]RecordedValue, len(ids)), Error: recordError(err)}
+	)
+
+	for i := range ids {
+		call.IDs[i] = recordValue(ids[i])
+	}
+
+	r.recording.add(call)
+	return ids, err
+}
+
+// Update and record the number of updated records.
+func (r *Recorder) Update(ctx context.Context, query rel.Query, modifies map[string]rel.Modify, loggers ...rel.Logger) (int, error) {
+	count, err := r.adapter.Update(ctx, query, modifies, loggers...)
+	r.recording.add(RecordedCall{Method: "Update", Table: query.Table, Count: count, Error: recordError(err)})
+	return count, err
+}
+
+// Delete and record the number of deleted records.
+func (r *Recorder) Delete(ctx context.Context, query rel.Query, loggers ...rel.Logger) (int, error) {
+	count, err := r.adapter.Delete(ctx, query, loggers...)
+	r.recording.add(RecordedCall{Method: "Delete", Table: query.Table, Count: count, Error: recordError(err)})
+	return count, err
+}
+
+// Begin transaction, calls inside transaction are recorded to the same recording.
+func (r *Recorder) Begin(ctx context.Context) (rel.Adapter, error) {
+	adapter, err := r.adapter.Begin(ctx)
+	r.recording.add(RecordedCall{Method: "Begin", Error: recordError(err)})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Recorder{adapter: adapter, recording: r.recording}, nil
+}
+
+// Commit transaction.
+func (r *Recorder) Commit(ctx context.Context) error {
+	err := r.adapter.Commit(ctx)
+	r.recording.add(RecordedCall{Method: "Commit", Error: recordError(err)})
+	return err
+}
+
+// Rollback transaction.
+func (r *Recorder) Rollback(ctx context.Context) error {
+	err := r.adapter.Rollback(ctx)
+	r.recording.add(RecordedCall{Method: "Rollback", Error: recordError(err)})
+	return err
+}
+
+// Recording returns calls recorded so far.
+func (r *Recorder) Recording() Recording {
+	r.recording.mutex.Lock()
+	defer r.recording.mutex.Unlock()
+
+	return Recording{
+		Capabilities: r.recording.Capabilities,
+		Calls:        append([]RecordedCall(nil), r.recording.Calls...),
+	}
+}
+
+// Save recorded calls to golden file.
+func (r *Recorder) Save(filename string) error {
+	data, err := json.MarshalIndent(r.Recording(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filename, data, 0644)
+}
+
+// Record calls to adapter, use Save to write the recorded calls to golden file.
+func Record(adapter rel.Adapter) *Recorder {
+	return &Recorder{
+		adapter: adapter,
+		recording: &recording{
+			Recording: Recording{Capabilities: adapter.Capabilities()},
+		},
+	}
+}
+
+type replay struct {
+	mutex sync.Mutex
+	index int
+	Recording
+}
+
+func (r *replay) next(method string, table string) (RecordedCall, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.index >= len(r.Calls) {
+		return RecordedCall{}, fmt.Errorf("reltest: unexpected %s on %q, no more recorded calls", method, table)
+	}
+
+	call := r.Calls[r.index]
+	if call.Method != method || call.Table != table {
+		return RecordedCall{}, fmt.Errorf("reltest: unexpected %s on %q, recorded call is %s on %q", method, table, call.Method, call.Table)
+	}
+
+	r.index++
+	return call, nil
+}
+
+// Replayer is an adapter that replays calls recorded by Recorder.
+// Calls must be made in the same order as they were recorded, otherwise an error is returned.
+type Replayer struct {
+	replay *replay
+}
+
+var _ rel.Adapter = (*Replayer)(nil)
+
+// Capabilities of the recorded adapter.
+func (r *Replayer) Capabilities() rel.Capabilities {
+	return r.replay.Capabilities
+}
+
+// Ping always succeed.
+func (r *Replayer) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Aggregate returns recorded result.
+func (r *Replayer) Aggregate(ctx context.Context, query rel.Query, mode string, field string, loggers ...rel.Logger) (int, error) {
+	call, err := r.replay.next("Aggregate", query.Table)
+	if err != nil {
+		return 0, err
+	}
+
+	return call.Count, replayError(call.Error)
+}
+
+// Query returns cursor of recorded rows.
+func (r *Replayer) Query(ctx context.Context, query rel.Query, loggers ...rel.Logger) (rel.Cursor, error) {
+	call, err := r.replay.next("Query", query.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := replayError(call.Error); err != nil {
+		return nil, err
+	}
+
+	return newReplayCursor(call.Fields, call.Rows), nil
+}
+
+// Insert returns recorded id.
+func (r *Replayer) Insert(ctx context.Context, query rel.Query, modifies map[string]rel.Modify, loggers ...rel.Logger) (interface{}, error) {
+	call, err := r.replay.next("Insert", query.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		id interface{}
+	)
+
+	if len(call.IDs) > 0 {
+		if id, err = call.IDs[0].Interface(); err != nil {
+			return nil, err
+		}
+	}
+
+	return id, replayError(call.Error)
+}
+
+// InsertAll returns recorded ids.
+func (r *Replayer) InsertAll(ctx context.Context, query rel.Query, fields []string, bulkModifies []map[string]rel.Modify, loggers ...rel.Logger) ([]interface{}, error) {
+	call, err := r.replay.next("InsertAll", query.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		ids = make([]interface{}, len(call.IDs))
+	)
+
+	for i := range call.IDs {
+		if ids[i], err = call.IDs[i].Interface(); err != nil {
+			return nil, err
+		}
+	}
+
+	return ids, replayError(call.Error)
+}
+
+// Update returns recorded number of updated records.
+func (r *Replayer) Update(ctx context.Context, query rel.Query, modifies map[string]rel.Modify, loggers ...rel.Logger) (int, error) {
+	call, err := r.replay.next("Update", query.Table)
+	if err != nil {
+		return 0, err
+	}
+
+	return call.Count, replayError(call.Error)
+}
+
+// Delete returns recorded number of deleted records.
+func (r *Replayer) Delete(ctx context.Context, query rel.Query, loggers ...rel.Logger) (int, error) {
+	call, err := r.replay.next("Delete", query.Table)
+	if err != nil {
+		return 0, err
+	}
+
+	return call.Count, replayError(call.Error)
+}
+
+// Begin replays transaction.
+func (r *Replayer) Begin(ctx context.Context) (rel.Adapter, error) {
+	call, err := r.replay.next("Begin", "")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := replayError(call.Error); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Commit replays commit.
+func (r *Replayer) Commit(ctx context.Context) error {
+	call, err := r.replay.next("Commit", "")
+	if err != nil {
+		return err
+	}
+
+	return replayError(call.Error)
+}
+
+// Rollback replays rollback.
+func (r *Replayer) Rollback(ctx context.Context) error {
+	call, err := r.replay.next("Rollback", "")
+	if err != nil {
+		return err
+	}
+
+	return replayError(call.Error)
+}
+
+// Replay calls from recording.
+func Replay(recording Recording) *Replayer {
+	return &Replayer{
+		replay: &replay{Recording: recording},
+	}
+}
+
+// ReplayFile replays calls from golden file saved by Recorder.
+func ReplayFile(filename string) (*Replayer, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		recording Recording
+	)
+
+	if err := json.Unmarshal(data, &recording); err != nil {
+		return nil, err
+	}
+
+	return Replay(recording), nil
+}
+
+type replayCursor struct {
+	fields []string
+	rows   [][]RecordedValue
+	index  int
+}
+
+var _ rel.Cursor = (*replayCursor)(nil)
+
+func newReplayCursor(fields []string, rows [][]RecordedValue) *replayCursor {
+	return &replayCursor{
+		fields: fields,
+		rows:   rows,
+		index:  -1,
+	}
+}
+
+func (rc *replayCursor) Close() error {
+	return nil
+}
+
+func (rc *replayCursor) Fields() ([]string, error) {
+	return rc.fields, nil
+}
+
+func (rc *replayCursor) Next() bool {
+	rc.index++
+	return rc.index < len(rc.rows)
+}
+
+func (rc *replayCursor) Scan(dest ...interface{}) error {
+	if rc.index < 0 || rc.index >= len(rc.rows) {
+		return errors.New("reltest: Scan called without calling Next")
+	}
+
+	if len(dest) != len(rc.fields) {
+		return errors.New("reltest: expected " + strconv.Itoa(len(rc.fields)) + " destination arguments in Scan")
+	}
+
+	for i := range dest {
+		value, err := rc.rows[rc.index][i].Interface()
+		if err != nil {
+			return err
+		}
+
+		if err := assign(dest[i], value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (rc *replayCursor) NopScanner() interface{} {
+	return &sql.RawBytes{}
+}
+
+func assign(dest interface{}, value interface{}) error {
+	switch d := dest.(type) {
+	case *sql.RawBytes:
+		return nil
+	case sql.Scanner:
+		return d.Scan(value)
+	}
+
+	var (
+		rv = reflect.ValueOf(dest)
+	)
+
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("reltest: destination must be a non nil pointer")
+	}
+
+	rv = rv.Elem()
+	if value == nil {
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	}
+
+	if rv.Kind() != reflect.Ptr {
+		return rel.Nullable(dest).(sql.Scanner).Scan(value)
+	}
+
+	ptr := reflect.New(rv.Type().Elem())
+	if err := rel.Nullable(ptr.Interface()).(sql.Scanner).Scan(value); err != nil {
+		return err
+	}
+
+	rv.Set(ptr)
+	return nil
+}
@@ -0,0 +1,148 @@
+package reltest
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/adapter/memory"
+	"github.com/Fs02/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordedBook struct {
+	ID        int
+	Title     string
+	Price     float64
+	Available bool
+	Note      *string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (recordedBook) Table() string {
+	return "books"
+}
+
+func runRecordedScenario(t *testing.T, repo rel.Repository) ([]recordedBook, int) {
+	var (
+		ctx   = context.TODO()
+		note  = "bestseller"
+		books = []recordedBook{
+			{Title: "Golang for dummies", Price: 9.5, Available: true, Note: &note},
+			{Title: "Rel for dummies", Price: 10},
+		}
+		result []recordedBook
+	)
+
+	assert.Nil(t, repo.Insert(ctx, &books[0]))
+	assert.Nil(t, repo.Transaction(ctx, func(repo rel.Repository) error {
+		return repo.Insert(ctx, &books[1])
+	}))
+	assert.Nil(t, repo.Update(ctx, &books[1], rel.Set("available", true)))
+	assert.Nil(t, repo.FindAll(ctx, &result, where.Eq("available", true)))
+
+	count, err := repo.Count(ctx, "books")
+	assert.Nil(t, err)
+
+	assert.Nil(t, repo.Delete(ctx, &books[0]))
+	assert.Equal(t, rel.NotFoundError{}, repo.Find(ctx, &recordedBook{}, where.Eq("id", books[0].ID)))
+
+	return result, count
+}
+
+func TestRecordReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reltest")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		filename = filepath.Join(dir, "golden.json")
+		recorder = Record(memory.New())
+	)
+
+	recorded, recordedCount := runRecordedScenario(t, rel.New(recorder))
+	assert.Len(t, recorded, 2)
+	assert.Equal(t, 2, recordedCount)
+	assert.Nil(t, recorder.Save(filename))
+
+	replayer, err := ReplayFile(filename)
+	assert.Nil(t, err)
+	assert.Equal(t, recorder.Capabilities(), replayer.Capabilities())
+	assert.Nil(t, replayer.Ping(context.TODO()))
+
+	replayed, replayedCount := runRecordedScenario(t, rel.New(replayer))
+	assert.Equal(t, recordedCount, replayedCount)
+	assert.Len(t, replayed, 2)
+
+	for i := range recorded {
+		assert.Equal(t, recorded[i].ID, replayed[i].ID)
+		assert.Equal(t, recorded[i].Title, replayed[i].Title)
+		assert.Equal(t, recorded[i].Price, replayed[i].Price)
+		assert.Equal(t, recorded[i].Available, replayed[i].Available)
+		assert.Equal(t, recorded[i].Note, replayed[i].Note)
+		assert.True(t, recorded[i].CreatedAt.Equal(replayed[i].CreatedAt))
+	}
+}
+
+func TestReplay_unexpectedCall(t *testing.T) {
+	var (
+		repo = rel.New(Replay(Recording{
+			Calls: []RecordedCall{
+				{Method: "Insert", Table: "books", IDs: []RecordedValue{{Type: "int", Value: "1"}}},
+			},
+		}))
+	)
+
+	assert.EqualError(t, repo.Delete(context.TODO(), &recordedBook{ID: 1}), `reltest: unexpected Delete on "books", recorded call is Insert on "books"`)
+	assert.Nil(t, repo.Insert(context.TODO(), &recordedBook{}))
+	assert.EqualError(t, repo.Insert(context.TODO(), &recordedBook{}), `reltest: unexpected Insert on "books", no more recorded calls`)
+}
+
+func TestReplay_error(t *testing.T) {
+	var (
+		repo = rel.New(Replay(Recording{
+			Calls: []RecordedCall{
+				{Method: "Update", Table: "books", Error: "connection refused"},
+			},
+		}))
+	)
+
+	assert.Equal(t, errors.New("connection refused"), repo.Update(context.TODO(), &recordedBook{ID: 1}, rel.Set("available", true)))
+}
+
+func TestRecordedValue(t *testing.T) {
+	var (
+		now = time.Now().Round(0)
+	)
+
+	tests := []interface{}{
+		nil,
+		true,
+		int64(10),
+		uint64(10),
+		10.5,
+		[]byte("bytes"),
+		now,
+		"string",
+	}
+
+	for _, value := range tests {
+		result, err := recordValue(value).Interface()
+		assert.Nil(t, err)
+
+		if tm, ok := value.(time.Time); ok {
+			assert.True(t, tm.Equal(result.(time.Time)))
+		} else {
+			assert.Equal(t, value, result)
+		}
+	}
+
+	_, err := RecordedValue{Type: "unknown"}.Interface()
+	assert.EqualError(t, err, "reltest: unknown recorded value type unknown")
+}
@@ -2,13 +2,22 @@ package reltest
 
 import (
 	"context"
+	"database/sql"
+	"reflect"
 	"runtime"
+	"sync"
 	"testing"
 
 	"github.com/Fs02/rel"
 	"github.com/stretchr/testify/mock"
 )
 
+// reset record to its zero value.
+func reset(record interface{}) {
+	rv := reflect.ValueOf(record).Elem()
+	rv.Set(reflect.Zero(rv.Type()))
+}
+
 func must(err error) {
 	if err != nil {
 		panic(err)
@@ -17,13 +26,157 @@ func must(err error) {
 
 // Repository is an autogenerated mock type for the Repository type
 type Repository struct {
-	repo rel.Repository
-	mock mock.Mock
-	tx   *Repository
+	*state
+	scope  *scope
+	values map[string]interface{}
+}
+
+// state is shared between repository, its scopes and transactions started from it.
+type state struct {
+	repo    rel.Repository
+	mock    mock.Mock
+	mutex   sync.Mutex
+	tx      *state
+	relaxed bool
+	expects []*Expect
+}
+
+func newState(adapter rel.Adapter) *state {
+	s := &state{
+		repo: rel.New(adapter),
+	}
+
+	s.mock.Test(failT{})
+	return s
+}
+
+func (s *state) transaction() *state {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.tx == nil {
+		s.tx = newState(s.repo.Adapter())
+
+		if s.relaxed {
+			s.tx.relax()
+		}
+	}
+
+	return s.tx
+}
+
+func (s *state) relax() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.relaxed = true
+
+	if s.tx != nil {
+		s.tx.relax()
+	}
+}
+
+func (s *state) track(e *Expect) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.expects = append(s.expects, e)
+}
+
+func (s *state) isRelaxed() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.relaxed
+}
+
+// called tells the mock that method has been called with given arguments.
+// Unmatched call on relaxed repository returns the given defaults instead of failing,
+// otherwise it fails with the difference against the closest expectation.
+func (r *Repository) called(ctx context.Context, method string, defaults mock.Arguments, arguments ...interface{}) (ret mock.Arguments, matched bool) {
+	arguments = append(arguments, scopeOf(ctx))
+
+	defer func() {
+		if p := recover(); p != nil {
+			msg, ok := p.(unmatchedCall)
+			if !ok {
+				panic(p)
+			}
+
+			if !r.isRelaxed() {
+				panic(r.mismatch(method, arguments, string(msg)))
+			}
+
+			ret, matched = defaults, false
+		}
+	}()
+
+	return r.mock.MethodCalled(method, arguments...), true
+}
+
+func (s *state) assertExpectations(t *testing.T) bool {
+	s.mutex.Lock()
+	tx := s.tx
+	s.mutex.Unlock()
+
+	if tx != nil {
+		return s.mock.AssertExpectations(t) && tx.assertExpectations(t)
+	}
+
+	return s.mock.AssertExpectations(t)
+}
+
+// reset discards expectations and calls, including the expectations of transaction.
+func (s *state) reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.mock.ExpectedCalls = nil
+	s.mock.Calls = nil
+	s.expects = nil
+	s.tx = nil
+}
+
+func (r *Repository) reset() {
+	r.state.reset()
+	r.nop().reset()
+
+	if ma := r.memory(); ma != nil {
+		ma.reset()
+	}
 }
 
 var _ rel.Repository = (*Repository)(nil)
 
+// nop returns the underlying adapter.
+func (r *Repository) nop() *nopAdapter {
+	if ma := r.memory(); ma != nil {
+		return ma.nopAdapter
+	}
+
+	return r.repo.Adapter().(*nopAdapter)
+}
+
+// memory returns the underlying adapter of stateful repository, otherwise nil.
+func (r *Repository) memory() *memoryAdapter {
+	ma, _ := r.repo.Adapter().(*memoryAdapter)
+	return ma
+}
+
+// persist applies mutation to stateful repository when expectation succeed.
+// Error of the mutation is returned when the call doesn't match any expectation.
+func (r *Repository) persist(ret mock.Arguments, matched bool, mutate func() error) error {
+	if err := ret.Error(0); err != nil {
+		return err
+	}
+
+	if err := mutate(); !matched {
+		return err
+	}
+
+	return nil
+}
+
 // Adapter provides a mock function with given fields:
 func (r *Repository) Adapter() rel.Adapter {
 	return nil
@@ -33,15 +186,40 @@ func (r *Repository) Adapter() rel.Adapter {
 func (r *Repository) SetLogger(logger ...rel.Logger) {
 }
 
+// SetLogLevels provides a mock function with given fields: levels
+func (r *Repository) SetLogLevels(levels rel.LogLevels) {
+}
+
+// SetPreloadParallelism provides a mock function with given fields: n
+func (r *Repository) SetPreloadParallelism(n int) {
+}
+
+// SetInChunkSize provides a mock function with given fields: n
+func (r *Repository) SetInChunkSize(n int) {
+}
+
+// Instrumentation provides a mock function with given fields: instrumenters
+func (r *Repository) Instrumentation(instrumenters ...rel.Instrumenter) {
+}
+
 // Ping database.
 func (r *Repository) Ping(ctx context.Context) error {
 	return r.repo.Ping(ctx)
 }
 
+// Stats returns zero connection pool statistics.
+func (r *Repository) Stats() sql.DBStats {
+	return r.repo.Stats()
+}
+
 // Aggregate provides a mock function with given fields: query, aggregate, field
 func (r *Repository) Aggregate(ctx context.Context, query rel.Query, aggregate string, field string) (int, error) {
-	r.repo.Aggregate(ctx, query, aggregate, field)
-	ret := r.mock.Called(query, aggregate, field)
+	result, err := r.repo.Aggregate(ctx, query, aggregate, field)
+	ret, matched := r.called(ctx, "Aggregate", mock.Arguments{0, nil}, query, aggregate, field)
+	if !matched && r.memory() != nil {
+		return result, err
+	}
+
 	return ret.Int(0), ret.Error(1)
 }
 
@@ -59,8 +237,12 @@ func (r *Repository) ExpectAggregate(query rel.Query, aggregate string, field st
 
 // Count provides a mock function with given fields: collection, queriers
 func (r *Repository) Count(ctx context.Context, collection string, queriers ...rel.Querier) (int, error) {
-	r.repo.Count(ctx, collection, queriers...)
-	ret := r.mock.Called(collection, queriers)
+	count, err := r.repo.Count(ctx, collection, queriers...)
+	ret, matched := r.called(ctx, "Count", mock.Arguments{0, nil}, collection, queriers)
+	if !matched && r.memory() != nil {
+		return count, err
+	}
+
 	return ret.Int(0), ret.Error(1)
 }
 
@@ -78,8 +260,18 @@ func (r *Repository) ExpectCount(collection string, queriers ...rel.Querier) *Ag
 
 // Find provides a mock function with given fields: record, queriers
 func (r *Repository) Find(ctx context.Context, record interface{}, queriers ...rel.Querier) error {
-	r.repo.Find(ctx, record, queriers...)
-	return r.mock.Called(record, queriers).Error(0)
+	err := r.repo.Find(ctx, record, queriers...)
+
+	ret, matched := r.called(ctx, "Find", mock.Arguments{nil}, record, queriers)
+	if !matched {
+		if r.memory() != nil {
+			return err
+		}
+
+		reset(record)
+	}
+
+	return ret.Error(0)
 }
 
 // MustFind provides a mock function with given fields: record, queriers
@@ -94,8 +286,18 @@ func (r *Repository) ExpectFind(queriers ...rel.Querier) *Find {
 
 // FindAll provides a mock function with given fields: records, queriers
 func (r *Repository) FindAll(ctx context.Context, records interface{}, queriers ...rel.Querier) error {
-	r.repo.FindAll(ctx, records, queriers...)
-	return r.mock.Called(records, queriers).Error(0)
+	err := r.repo.FindAll(ctx, records, queriers...)
+
+	ret, matched := r.called(ctx, "FindAll", mock.Arguments{nil}, records, queriers)
+	if !matched {
+		if r.memory() != nil {
+			return err
+		}
+
+		reset(records)
+	}
+
+	return ret.Error(0)
 }
 
 // ExpectFindAll apply mocks and expectations for FindAll
@@ -108,9 +310,47 @@ func (r *Repository) MustFindAll(ctx context.Context, records interface{}, queri
 	must(r.FindAll(ctx, records, queriers...))
 }
 
+// FindEach provides a mock function with given fields: record, fn, queriers
+// Records set using ExpectFindEach(...).Result are copied into the record and passed to fn one by one.
+func (r *Repository) FindEach(ctx context.Context, record interface{}, fn func(record interface{}) error, queriers ...rel.Querier) error {
+	ret, matched := r.called(ctx, "FindEach", mock.Arguments{nil, nil}, record, queriers)
+	if !matched && r.memory() != nil {
+		return r.repo.FindEach(ctx, record, fn, queriers...)
+	}
+
+	if err := ret.Error(1); err != nil {
+		return err
+	}
+
+	if records := ret.Get(0); records != nil {
+		return each(records, record, fn)
+	}
+
+	return nil
+}
+
+// ExpectFindEach apply mocks and expectations for FindEach
+func (r *Repository) ExpectFindEach(queriers ...rel.Querier) *FindEach {
+	return ExpectFindEach(r, queriers)
+}
+
+// MustFindEach provides a mock function with given fields: record, fn, queriers
+func (r *Repository) MustFindEach(ctx context.Context, record interface{}, fn func(record interface{}) error, queriers ...rel.Querier) {
+	must(r.FindEach(ctx, record, fn, queriers...))
+}
+
 // Insert provides a mock function with given fields: record, modifiers
 func (r *Repository) Insert(ctx context.Context, record interface{}, modifiers ...rel.Modifier) error {
-	ret := r.mock.Called(record, modifiers)
+	ret, matched := r.called(ctx, "Insert", mock.Arguments{nil}, record, modifiers, &changes{record: record, modifiers: modifiers})
+	if ret.Error(0) == nil {
+		r.recordMutation("Insert", record, false, modifiers...)
+	}
+
+	if r.memory() != nil {
+		return r.persist(ret, matched, func() error {
+			return r.repo.Insert(ctx, record, modifiers...)
+		})
+	}
 
 	r.repo.Insert(ctx, record, modifiers...)
 	return ret.Error(0)
@@ -127,26 +367,44 @@ func (r *Repository) ExpectInsert(modifiers ...rel.Modifier) *Modify {
 }
 
 // InsertAll records.
-func (r *Repository) InsertAll(ctx context.Context, records interface{}) error {
-	ret := r.mock.Called(records)
+func (r *Repository) InsertAll(ctx context.Context, records interface{}, onConflict ...rel.OnConflict) error {
+	ret, matched := r.called(ctx, "InsertAll", mock.Arguments{nil}, records, onConflict)
+	if ret.Error(0) == nil {
+		r.recordMutations("InsertAll", records)
+	}
+
+	if r.memory() != nil {
+		return r.persist(ret, matched, func() error {
+			return r.repo.InsertAll(ctx, records, onConflict...)
+		})
+	}
 
-	r.repo.InsertAll(ctx, records)
+	r.repo.InsertAll(ctx, records, onConflict...)
 	return ret.Error(0)
 }
 
 // MustInsertAll records.
-func (r *Repository) MustInsertAll(ctx context.Context, records interface{}) {
-	must(r.InsertAll(ctx, records))
+func (r *Repository) MustInsertAll(ctx context.Context, records interface{}, onConflict ...rel.OnConflict) {
+	must(r.InsertAll(ctx, records, onConflict...))
 }
 
 // ExpectInsertAll records.
-func (r *Repository) ExpectInsertAll() *Modify {
-	return ExpectInsertAll(r)
+func (r *Repository) ExpectInsertAll(onConflict ...rel.OnConflict) *Modify {
+	return ExpectInsertAll(r, onConflict...)
 }
 
 // Update provides a mock function with given fields: record, modifiers
 func (r *Repository) Update(ctx context.Context, record interface{}, modifiers ...rel.Modifier) error {
-	ret := r.mock.Called(record, modifiers)
+	ret, matched := r.called(ctx, "Update", mock.Arguments{nil}, record, modifiers, &changes{record: record, modifiers: modifiers})
+	if ret.Error(0) == nil {
+		r.recordMutation("Update", record, true, modifiers...)
+	}
+
+	if r.memory() != nil {
+		return r.persist(ret, matched, func() error {
+			return r.repo.Update(ctx, record, modifiers...)
+		})
+	}
 
 	if err := r.repo.Update(ctx, record, modifiers...); err != nil {
 		return err
@@ -165,9 +423,50 @@ func (r *Repository) ExpectUpdate(modifiers ...rel.Modifier) *Modify {
 	return ExpectModify(r, "Update", modifiers, false)
 }
 
+// UpdateAny provides a mock function with given fields: query, modifies
+func (r *Repository) UpdateAny(ctx context.Context, query rel.Query, modifies ...rel.Modify) (int, error) {
+	ret, matched := r.called(ctx, "UpdateAny", mock.Arguments{0, nil}, query, modifies)
+	if ret.Error(1) != nil {
+		return 0, ret.Error(1)
+	}
+
+	r.recordQueryMutation("UpdateAny", []rel.Querier{query}, modifiesMap(modifies))
+
+	if r.memory() != nil {
+		if updatedCount, err := r.repo.UpdateAny(ctx, query, modifies...); !matched {
+			return updatedCount, err
+		}
+	}
+
+	return ret.Int(0), nil
+}
+
+// MustUpdateAny provides a mock function with given fields: query, modifies
+func (r *Repository) MustUpdateAny(ctx context.Context, query rel.Query, modifies ...rel.Modify) int {
+	updatedCount, err := r.UpdateAny(ctx, query, modifies...)
+	must(err)
+	return updatedCount
+}
+
+// ExpectUpdateAny apply mocks and expectations for UpdateAny
+func (r *Repository) ExpectUpdateAny(query rel.Query, modifies ...rel.Modify) *MutateAny {
+	return ExpectUpdateAny(r, query, modifies)
+}
+
 // Delete provides a mock function with given fields: record
 func (r *Repository) Delete(ctx context.Context, record interface{}) error {
-	return r.mock.Called(record).Error(0)
+	ret, matched := r.called(ctx, "Delete", mock.Arguments{nil}, record)
+	if ret.Error(0) == nil {
+		r.recordMutation("Delete", record, true)
+	}
+
+	if r.memory() != nil {
+		return r.persist(ret, matched, func() error {
+			return r.repo.Delete(ctx, record)
+		})
+	}
+
+	return ret.Error(0)
 }
 
 // MustDelete provides a mock function with given fields: record
@@ -182,7 +481,18 @@ func (r *Repository) ExpectDelete() *Delete {
 
 // DeleteAll provides a mock function with given fields: queriers
 func (r *Repository) DeleteAll(ctx context.Context, queriers ...rel.Querier) error {
-	return r.mock.Called(queriers).Error(0)
+	ret, matched := r.called(ctx, "DeleteAll", mock.Arguments{nil}, queriers)
+	if ret.Error(0) == nil {
+		r.recordQueryMutation("DeleteAll", queriers)
+	}
+
+	if r.memory() != nil {
+		return r.persist(ret, matched, func() error {
+			return r.repo.DeleteAll(ctx, queriers...)
+		})
+	}
+
+	return ret.Error(0)
 }
 
 // MustDeleteAll provides a mock function with given fields: queriers
@@ -195,9 +505,44 @@ func (r *Repository) ExpectDeleteAll(queriers ...rel.Querier) *DeleteAll {
 	return ExpectDeleteAll(r, queriers)
 }
 
+// DeleteAny provides a mock function with given fields: query
+func (r *Repository) DeleteAny(ctx context.Context, query rel.Query) (int, error) {
+	ret, matched := r.called(ctx, "DeleteAny", mock.Arguments{0, nil}, query)
+	if ret.Error(1) != nil {
+		return 0, ret.Error(1)
+	}
+
+	r.recordQueryMutation("DeleteAny", []rel.Querier{query})
+
+	if r.memory() != nil {
+		if deletedCount, err := r.repo.DeleteAny(ctx, query); !matched {
+			return deletedCount, err
+		}
+	}
+
+	return ret.Int(0), nil
+}
+
+// MustDeleteAny provides a mock function with given fields: query
+func (r *Repository) MustDeleteAny(ctx context.Context, query rel.Query) int {
+	deletedCount, err := r.DeleteAny(ctx, query)
+	must(err)
+	return deletedCount
+}
+
+// ExpectDeleteAny apply mocks and expectations for DeleteAny
+func (r *Repository) ExpectDeleteAny(query rel.Query) *MutateAny {
+	return ExpectDeleteAny(r, query)
+}
+
 // Preload provides a mock function with given fields: records, field, queriers
 func (r *Repository) Preload(ctx context.Context, records interface{}, field string, queriers ...rel.Querier) error {
-	return r.mock.Called(records, field, queriers).Error(0)
+	ret, matched := r.called(ctx, "Preload", mock.Arguments{nil}, records, field, queriers)
+	if !matched && r.memory() != nil {
+		return r.repo.Preload(ctx, records, field, queriers...)
+	}
+
+	return ret.Error(0)
 }
 
 // MustPreload provides a mock function with given fields: records, field, queriers
@@ -205,16 +550,92 @@ func (r *Repository) MustPreload(ctx context.Context, records interface{}, field
 	must(r.Preload(ctx, records, field, queriers...))
 }
 
+// PreloadAll calls Preload for each field in order, so it can be mocked using ExpectPreload for each field.
+func (r *Repository) PreloadAll(ctx context.Context, records interface{}, fields ...string) error {
+	for _, field := range fields {
+		if err := r.Preload(ctx, records, field); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MustPreloadAll calls Preload for each field in order.
+func (r *Repository) MustPreloadAll(ctx context.Context, records interface{}, fields ...string) {
+	must(r.PreloadAll(ctx, records, fields...))
+}
+
+// PreloadMap calls Preload for the field, so it can be mocked using ExpectPreload, and returns the loaded association keyed by primary value of the records.
+func (r *Repository) PreloadMap(ctx context.Context, records interface{}, field string, queriers ...rel.Querier) (map[interface{}]interface{}, error) {
+	if err := r.Preload(ctx, records, field, queriers...); err != nil {
+		return nil, err
+	}
+
+	var (
+		docs   []*rel.Document
+		result = make(map[interface{}]interface{})
+	)
+
+	if rt := reflect.TypeOf(records); rt.Kind() == reflect.Ptr && rt.Elem().Kind() == reflect.Slice {
+		col := rel.NewCollection(records)
+		for i := 0; i < col.Len(); i++ {
+			docs = append(docs, col.Get(i))
+		}
+	} else {
+		docs = append(docs, rel.NewDocument(records))
+	}
+
+	for _, doc := range docs {
+		value, _ := doc.Value(field)
+		result[doc.PrimaryValue()] = value
+	}
+
+	return result, nil
+}
+
+// MustPreloadMap calls Preload for the field, and returns the loaded association keyed by primary value of the records.
+func (r *Repository) MustPreloadMap(ctx context.Context, records interface{}, field string, queriers ...rel.Querier) map[interface{}]interface{} {
+	result, err := r.PreloadMap(ctx, records, field, queriers...)
+	must(err)
+	return result
+}
+
 // ExpectPreload apply mocks and expectations for Preload
 func (r *Repository) ExpectPreload(field string, queriers ...rel.Querier) *Preload {
 	return ExpectPreload(r, field, queriers)
 }
 
+// Association provides a mock of association proxy with given fields: record, field
+func (r *Repository) Association(record interface{}, field string) rel.AssociationProxy {
+	r.repo.Association(record, field)
+	return associationProxy{r: r, record: record, field: field}
+}
+
+// ExpectAssociation apply mocks and expectations for association proxy of the field.
+func (r *Repository) ExpectAssociation(field string) *Association {
+	return ExpectAssociation(r, field)
+}
+
 // Transaction provides a mock function with given fields: fn
-func (r *Repository) Transaction(ctx context.Context, fn func(rel.Repository) error) error {
-	r.mock.Called()
+func (r *Repository) Transaction(ctx context.Context, fn func(rel.Repository) error, opts ...rel.TransactionOption) error {
+	ret, _ := r.called(ctx, "Transaction", mock.Arguments{nil, nil})
+	if err := ret.Error(1); err != nil {
+		return err
+	}
+
+	var (
+		err            error
+		transaction, _ = ret.Get(0).(*Transaction)
+		// transaction scoped values are discarded after each transaction.
+		tx = &Repository{state: r.transaction(), values: make(map[string]interface{})}
+	)
+
+	ma := r.memory()
+	if ma != nil {
+		ma.Begin(ctx)
+	}
 
-	var err error
 	func() {
 		defer func() {
 			if p := recover(); p != nil {
@@ -229,35 +650,120 @@ func (r *Repository) Transaction(ctx context.Context, fn func(rel.Repository) er
 			}
 		}()
 
-		err = fn(r.tx)
+		err = fn(tx)
 	}()
 
+	if ma != nil {
+		if err != nil {
+			ma.Rollback(ctx)
+		} else {
+			ma.Commit(ctx)
+		}
+	}
+
+	transaction.assert(err)
+
 	return err
 }
 
-// ExpectTransaction declare expectation inside transaction.
-func (r *Repository) ExpectTransaction(fn func(*Repository)) {
-	r.mock.On("Transaction").Once()
+// Set stores value to be retrieved using Get.
+// It'll panic when called outside of transaction, the same as rel's repository.
+func (r *Repository) Set(key string, value interface{}) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
 
-	if r.tx == nil {
-		r.tx = New()
+	if r.values == nil {
+		panic("reltest: Set can only be called inside transaction")
 	}
 
-	fn(r.tx)
+	r.values[key] = value
+}
+
+// Get value stored using Set.
+func (r *Repository) Get(key string) interface{} {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.values[key]
+}
+
+// ExpectTransaction declare expectation inside transaction.
+func (r *Repository) ExpectTransaction(fn func(*Repository)) *Transaction {
+	return ExpectTransaction(r, fn)
+}
+
+// Relaxed makes unmatched calls succeed instead of failing, queries return zero values and mutations succeed.
+// It's useful for tests that only care about a subset of repository interactions.
+func (r *Repository) Relaxed() *Repository {
+	r.relax()
+	return r
+}
+
+// AutoIncrement assigns auto incrementing primary key per table on insert instead of always using 1.
+func (r *Repository) AutoIncrement() *Repository {
+	r.nop().setAutoIncrement(true)
+	return r
+}
+
+// InOrder asserts that given expectations are called in the same order as declared.
+func (r *Repository) InOrder(expectations ...Expectation) {
+	InOrder(expectations...)
+}
+
+// Scope declares expectations that only match calls made using the returned context.
+// It isolates expectations of parallel tests that share the same repository.
+func (r *Repository) Scope(ctx context.Context, fn func(*Repository)) context.Context {
+	s := newScope()
+	fn(&Repository{state: r.state, scope: s})
+
+	return context.WithValue(ctx, scopeKey{}, s)
 }
 
 // AssertExpectations asserts that everything was in fact called as expected. Calls may have occurred in any order.
 func (r *Repository) AssertExpectations(t *testing.T) bool {
-	if r.tx != nil {
-		return r.mock.AssertExpectations(t) && r.tx.AssertExpectations(t)
-	}
+	return r.assertExpectations(t)
+}
+
+// Cleanup asserts expectations and resets the repository when the test and all of its subtests complete,
+// so the repository can be reused by the next test.
+// Expectations, recorded mutations and records of stateful repository are discarded when reset.
+func (r *Repository) Cleanup(t *testing.T) *Repository {
+	t.Helper()
+	t.Cleanup(func() {
+		r.AssertExpectations(t)
+		r.reset()
+	})
 
-	return r.mock.AssertExpectations(t)
+	return r
+}
+
+// Run fn with new test repository, expectations are asserted when the test and all of its subtests complete.
+func Run(t *testing.T, fn func(repo *Repository)) {
+	t.Helper()
+	fn(New().Cleanup(t))
 }
 
 // New test repository.
 func New() *Repository {
 	return &Repository{
-		repo: rel.New(&nopAdapter{}),
+		state: newState(&nopAdapter{}),
 	}
 }
+
+// NewStateful returns relaxed test repository that stores records in memory.
+// Unmatched Insert, Update and Delete modify the stored records, and unmatched Find, FindAll, Count and Preload query them,
+// so flow of repository calls can be tested without declaring every expectation.
+// Declared expectations still take precedence, and its successful mutations are also stored.
+//
+// Records are stored using memory adapter, thus they can be queried using where conditions (except fragment), sort, offset and limit,
+// while join and group query are not supported.
+func NewStateful() *Repository {
+	return (&Repository{
+		state: newState(newMemoryAdapter()),
+	}).Relaxed()
+}
+
+// NewStub returns relaxed test repository, see Relaxed.
+func NewStub() *Repository {
+	return New().Relaxed()
+}
@@ -3,9 +3,11 @@ package reltest
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"testing"
 
 	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/where"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -46,6 +48,10 @@ func TestRepository_Ping(t *testing.T) {
 	assert.Nil(t, New().Ping(context.TODO()))
 }
 
+func TestRepository_Stats(t *testing.T) {
+	assert.Equal(t, sql.DBStats{}, New().Stats())
+}
+
 func TestRepository_Transaction(t *testing.T) {
 	var (
 		repo   = New()
@@ -65,6 +71,34 @@ func TestRepository_Transaction(t *testing.T) {
 	repo.AssertExpectations(t)
 }
 
+func TestRepository_Set_outsideTransaction(t *testing.T) {
+	var (
+		repo = New()
+	)
+
+	assert.PanicsWithValue(t, "reltest: Set can only be called inside transaction", func() {
+		repo.Set("actor", 1)
+	})
+	assert.Nil(t, repo.Get("actor"))
+}
+
+func TestRepository_Transaction_set(t *testing.T) {
+	var (
+		repo = New()
+	)
+
+	repo.ExpectTransaction(func(repo *Repository) {})
+
+	assert.Nil(t, repo.Transaction(context.TODO(), func(repo rel.Repository) error {
+		assert.Nil(t, repo.Get("actor"))
+		repo.Set("actor", 1)
+		assert.Equal(t, 1, repo.Get("actor"))
+		return nil
+	}))
+
+	repo.AssertExpectations(t)
+}
+
 func TestRepository_Transaction_error(t *testing.T) {
 	var (
 		repo   = New()
@@ -120,3 +154,234 @@ func TestRepository_Transaction_runtimerError(t *testing.T) {
 
 	repo.AssertExpectations(t)
 }
+
+func TestRepository_Transaction_commit(t *testing.T) {
+	var (
+		repo = New()
+	)
+
+	repo.ExpectTransaction(func(repo *Repository) {}).Commit()
+	assert.Nil(t, repo.Transaction(context.TODO(), func(repo rel.Repository) error {
+		return nil
+	}))
+	repo.AssertExpectations(t)
+
+	repo.ExpectTransaction(func(repo *Repository) {}).Commit()
+	assert.Panics(t, func() {
+		_ = repo.Transaction(context.TODO(), func(repo rel.Repository) error {
+			return errors.New("error")
+		})
+	})
+}
+
+func TestRepository_Transaction_rollback(t *testing.T) {
+	var (
+		repo = New()
+		err  = errors.New("error")
+	)
+
+	repo.ExpectTransaction(func(repo *Repository) {}).Rollback()
+	assert.Equal(t, err, repo.Transaction(context.TODO(), func(repo rel.Repository) error {
+		return err
+	}))
+	repo.AssertExpectations(t)
+
+	repo.ExpectTransaction(func(repo *Repository) {}).Rollback()
+	assert.Equal(t, err, repo.Transaction(context.TODO(), func(repo rel.Repository) error {
+		panic(err)
+	}))
+	repo.AssertExpectations(t)
+
+	repo.ExpectTransaction(func(repo *Repository) {}).Rollback()
+	assert.Panics(t, func() {
+		_ = repo.Transaction(context.TODO(), func(repo rel.Repository) error {
+			return nil
+		})
+	})
+}
+
+func TestRepository_Transaction_beginError(t *testing.T) {
+	var (
+		repo   = New()
+		called = false
+	)
+
+	repo.ExpectTransaction(func(repo *Repository) {}).ConnectionClosed()
+	assert.Equal(t, sql.ErrConnDone, repo.Transaction(context.TODO(), func(repo rel.Repository) error {
+		called = true
+		return nil
+	}))
+	assert.False(t, called)
+	repo.AssertExpectations(t)
+}
+
+func TestRepository_Transaction_valuesDiscarded(t *testing.T) {
+	var (
+		repo = New()
+	)
+
+	repo.ExpectTransaction(func(repo *Repository) {}).Times(2)
+
+	assert.Nil(t, repo.Transaction(context.TODO(), func(repo rel.Repository) error {
+		repo.Set("actor", 1)
+		return nil
+	}))
+
+	assert.Nil(t, repo.Transaction(context.TODO(), func(repo rel.Repository) error {
+		assert.Nil(t, repo.Get("actor"))
+		return nil
+	}))
+
+	repo.AssertExpectations(t)
+}
+
+func TestRepository_Transaction_outsideTransaction(t *testing.T) {
+	var (
+		repo   = New()
+		result = Book{Title: "Golang for dummies"}
+	)
+
+	repo.ExpectTransaction(func(repo *Repository) {
+		repo.ExpectInsert()
+	})
+
+	assert.Panics(t, func() {
+		_ = repo.Transaction(context.TODO(), func(tx rel.Repository) error {
+			return repo.Insert(context.TODO(), &result)
+		})
+	})
+}
+
+func TestRepository_relaxed(t *testing.T) {
+	var (
+		repo   = NewStub()
+		ctx    = context.TODO()
+		book   = Book{Title: "Golang for dummies"}
+		books  []Book
+		result = Book{ID: 2, Title: "Rel for dummies"}
+	)
+
+	repo.ExpectFind(where.Eq("id", 2)).Result(result)
+
+	assert.Nil(t, repo.Find(ctx, &book, where.Eq("id", 2)))
+	assert.Equal(t, result, book)
+
+	assert.Nil(t, repo.Find(ctx, &book, where.Eq("id", 1)))
+	assert.Nil(t, repo.FindAll(ctx, &books))
+	assert.Len(t, books, 0)
+
+	count, err := repo.Count(ctx, "books")
+	assert.Equal(t, 0, count)
+	assert.Nil(t, err)
+
+	assert.Nil(t, repo.Insert(ctx, &book))
+	assert.Nil(t, repo.Update(ctx, &book, rel.Set("title", "Rel for dummies")))
+	assert.Nil(t, repo.Delete(ctx, &book))
+	assert.Nil(t, repo.DeleteAll(ctx, rel.From("books")))
+	assert.Nil(t, repo.Preload(ctx, &book, "author"))
+	assert.Nil(t, repo.Transaction(ctx, func(repo rel.Repository) error {
+		return repo.Insert(ctx, &book)
+	}))
+
+	repo.AssertExpectations(t)
+}
+
+func TestRepository_relaxedError(t *testing.T) {
+	var (
+		repo = New()
+		book = Book{Title: "Golang for dummies"}
+	)
+
+	repo.ExpectTransaction(func(repo *Repository) {
+		repo.ExpectInsert().ConnectionClosed()
+	})
+
+	repo.Relaxed()
+
+	assert.Equal(t, sql.ErrConnDone, repo.Transaction(context.TODO(), func(repo rel.Repository) error {
+		assert.Nil(t, repo.Update(context.TODO(), &book))
+		return repo.Insert(context.TODO(), &book)
+	}))
+
+	repo.AssertExpectations(t)
+}
+
+func TestRepository_relaxedOrder(t *testing.T) {
+	var (
+		repo = NewStub()
+		book = Book{Title: "Golang for dummies"}
+	)
+
+	repo.InOrder(
+		repo.ExpectInsert(),
+		repo.ExpectUpdate(),
+	)
+
+	assert.Panics(t, func() {
+		_ = repo.Update(context.TODO(), &book)
+	})
+}
+
+func TestRepository_Cleanup(t *testing.T) {
+	var (
+		repo = New().AutoIncrement().Snapshot()
+		book = Book{Title: "Golang for dummies"}
+	)
+
+	t.Run("first", func(t *testing.T) {
+		repo.Cleanup(t)
+		repo.ExpectInsert()
+		repo.ExpectTransaction(func(repo *Repository) {
+			repo.ExpectUpdate()
+		})
+
+		t.Run("subtest", func(t *testing.T) {
+			assert.Nil(t, repo.Insert(context.TODO(), &book))
+		})
+
+		assert.Nil(t, repo.Transaction(context.TODO(), func(repo rel.Repository) error {
+			return repo.Update(context.TODO(), &book)
+		}))
+	})
+
+	assert.Empty(t, repo.nop().snapshot())
+	assert.Panics(t, func() {
+		repo.Insert(context.TODO(), &Book{})
+	})
+
+	t.Run("second", func(t *testing.T) {
+		repo.Cleanup(t)
+		repo.ExpectInsert()
+
+		book := Book{}
+		assert.Nil(t, repo.Insert(context.TODO(), &book))
+		assert.Equal(t, 1, book.ID)
+	})
+}
+
+func TestRepository_Cleanup_stateful(t *testing.T) {
+	repo := NewStateful()
+
+	t.Run("insert", func(t *testing.T) {
+		repo.Cleanup(t)
+		assert.Nil(t, repo.Insert(context.TODO(), &Book{Title: "Rel for dummies"}))
+	})
+
+	count, err := repo.Count(context.TODO(), "books")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestRun(t *testing.T) {
+	var (
+		book = Book{Title: "Golang for dummies"}
+	)
+
+	Run(t, func(repo *Repository) {
+		repo.ExpectInsert()
+
+		t.Run("subtest", func(t *testing.T) {
+			assert.Nil(t, repo.Insert(context.TODO(), &book))
+		})
+	})
+}
@@ -0,0 +1,36 @@
+package reltest
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/stretchr/testify/mock"
+)
+
+var scopeID int64
+
+type scopeKey struct{}
+
+// scope identifies expectations declared using Repository.Scope.
+type scope struct {
+	id int64
+}
+
+func newScope() *scope {
+	return &scope{id: atomic.AddInt64(&scopeID, 1)}
+}
+
+// scopeOf returns scope of the context, or nil if context is not scoped.
+func scopeOf(ctx context.Context) *scope {
+	s, _ := ctx.Value(scopeKey{}).(*scope)
+	return s
+}
+
+// argument to match scope of the call, unscoped expectations match calls from any scope.
+func (s *scope) argument() interface{} {
+	if s == nil {
+		return mock.Anything
+	}
+
+	return s
+}
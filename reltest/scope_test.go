@@ -0,0 +1,110 @@
+package reltest
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_Scope(t *testing.T) {
+	var (
+		repo = New()
+	)
+
+	t.Run("group", func(t *testing.T) {
+		for i := 1; i <= 10; i++ {
+			id := i
+			t.Run("", func(t *testing.T) {
+				t.Parallel()
+
+				var (
+					result Book
+					book   = Book{ID: id, Title: "Book"}
+				)
+
+				ctx := repo.Scope(context.TODO(), func(repo *Repository) {
+					repo.ExpectFind(where.Eq("id", rel.Any)).Result(book)
+					repo.ExpectTransaction(func(repo *Repository) {
+						repo.ExpectUpdate().For(&result)
+					}).Commit()
+				})
+
+				assert.Nil(t, repo.Find(ctx, &result, where.Eq("id", id)))
+				assert.Equal(t, book, result)
+				assert.Nil(t, repo.Transaction(ctx, func(repo rel.Repository) error {
+					repo.Set("id", id)
+					assert.Equal(t, id, repo.Get("id"))
+					return repo.Update(ctx, &result)
+				}))
+			})
+		}
+	})
+
+	repo.AssertExpectations(t)
+}
+
+func TestRepository_Scope_unscopedCall(t *testing.T) {
+	var (
+		repo   = New()
+		result Book
+	)
+
+	ctx := repo.Scope(context.TODO(), func(repo *Repository) {
+		repo.ExpectFind(where.Eq("id", 1))
+	})
+
+	assert.Panics(t, func() {
+		_ = repo.Find(context.TODO(), &result, where.Eq("id", 1))
+	})
+
+	assert.Nil(t, repo.Find(ctx, &result, where.Eq("id", 1)))
+	repo.AssertExpectations(t)
+}
+
+func TestRepository_Scope_unscopedExpectation(t *testing.T) {
+	var (
+		repo   = New()
+		result Book
+		ctx    = repo.Scope(context.TODO(), func(repo *Repository) {})
+	)
+
+	repo.ExpectFind(where.Eq("id", 1))
+	assert.Nil(t, repo.Find(ctx, &result, where.Eq("id", 1)))
+	repo.AssertExpectations(t)
+}
+
+func TestRepository_concurrent(t *testing.T) {
+	var (
+		repo = New().AutoIncrement()
+		wg   sync.WaitGroup
+	)
+
+	repo.ExpectInsert().Times(10)
+	repo.ExpectTransaction(func(repo *Repository) {
+		repo.ExpectFind().AnyTimes()
+	}).Times(10)
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var (
+				book Book
+			)
+
+			assert.Nil(t, repo.Insert(context.TODO(), &book))
+			assert.Nil(t, repo.Transaction(context.TODO(), func(repo rel.Repository) error {
+				repo.Set("book", book.ID)
+				return repo.Find(context.TODO(), &book)
+			}))
+		}()
+	}
+
+	wg.Wait()
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,165 @@
+package reltest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Fs02/rel"
+	"github.com/stretchr/testify/assert"
+)
+
+// UpdateSnapshotEnv is environment variable that when set to 1, AssertSnapshot overwrites the existing snapshot file.
+const UpdateSnapshotEnv = "RELTEST_UPDATE_SNAPSHOT"
+
+// snapshotNow replaces time that is generated by repository when the mutation applied, so snapshot stays the same across run.
+type snapshotNow struct{}
+
+func (snapshotNow) String() string {
+	return "<now>"
+}
+
+// mutation recorded to snapshot.
+type mutation struct {
+	method   string
+	query    rel.Query
+	modifies []map[string]rel.Modify
+}
+
+func (m mutation) String() string {
+	var (
+		buffer strings.Builder
+	)
+
+	buffer.WriteString(m.method + " " + m.query.Table + "\n")
+	for _, l := range describeQuery(m.query) {
+		if l.key != "table" {
+			buffer.WriteString("\t" + l.key + ": " + l.value + "\n")
+		}
+	}
+
+	for _, modifies := range m.modifies {
+		buffer.WriteString("\tchanges: " + describeModifies(normalizeModifies(modifies)) + "\n")
+	}
+
+	return buffer.String()
+}
+
+// normalizeModifies replaces time that is close to current time with snapshotNow.
+func normalizeModifies(modifies map[string]rel.Modify) map[string]rel.Modify {
+	var (
+		result = make(map[string]rel.Modify, len(modifies))
+		now    = time.Now()
+	)
+
+	for field, modify := range modifies {
+		if t, ok := modify.Value.(time.Time); ok && t.After(now.Add(-time.Minute)) && t.Before(now.Add(time.Minute)) {
+			modify.Value = snapshotNow{}
+		}
+
+		result[field] = modify
+	}
+
+	return result
+}
+
+// Snapshot starts recording mutations applied to this repository, including mutations inside transaction.
+// Recorded mutations can be compared against snapshot file using AssertSnapshot.
+func (r *Repository) Snapshot() *Repository {
+	r.nop().startSnapshot()
+	return r
+}
+
+// AssertSnapshot asserts that mutations recorded since Snapshot is called equal to the content of snapshot file, example:
+//	repo := reltest.New().Snapshot()
+//	repo.ExpectInsert()
+//	repo.ExpectUpdate()
+//
+//	checkout(ctx, repo)
+//	repo.AssertSnapshot(t, "testdata/checkout.snap")
+//
+// Snapshot file is created when it doesn't exist, and overwritten when RELTEST_UPDATE_SNAPSHOT environment variable is set to 1.
+// Time that is close to current time, such as created_at and updated_at is written as <now>.
+func (r *Repository) AssertSnapshot(t *testing.T, filename string) bool {
+	var (
+		actual = r.nop().snapshot()
+	)
+
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) || os.Getenv(UpdateSnapshotEnv) == "1" {
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			return assert.Fail(t, err.Error())
+		}
+
+		if err := ioutil.WriteFile(filename, []byte(actual), 0644); err != nil {
+			return assert.Fail(t, err.Error())
+		}
+
+		return true
+	}
+
+	if err != nil {
+		return assert.Fail(t, err.Error())
+	}
+
+	return assert.Equal(t, string(data), actual, "mutations doesn't match snapshot %s, set %s=1 to update the snapshot", filename, UpdateSnapshotEnv)
+}
+
+// recordMutation of record to snapshot when the snapshot is started.
+func (r *Repository) recordMutation(method string, record interface{}, withQuery bool, modifiers ...rel.Modifier) {
+	na := r.nop()
+	if !na.snapshotStarted() {
+		return
+	}
+
+	var (
+		doc = rel.NewDocument(record, true)
+		m   = mutation{method: method, query: rel.Build(doc.Table())}
+	)
+
+	if withQuery {
+		m.query = rel.Build(doc.Table(), rel.Eq(doc.PrimaryField(), doc.PrimaryValue()))
+	}
+
+	if method != "Delete" {
+		m.modifies = append(m.modifies, (&changes{record: record, modifiers: modifiers}).modifies())
+	}
+
+	na.addMutation(m)
+}
+
+// recordMutations of records to snapshot when the snapshot is started.
+func (r *Repository) recordMutations(method string, records interface{}) {
+	na := r.nop()
+	if !na.snapshotStarted() {
+		return
+	}
+
+	var (
+		rv = reflect.ValueOf(records).Elem()
+		m  = mutation{method: method, query: rel.Build(rel.NewCollection(records, true).Table())}
+	)
+
+	for i := 0; i < rv.Len(); i++ {
+		record := rv.Index(i)
+		if record.Kind() != reflect.Ptr {
+			record = record.Addr()
+		}
+
+		m.modifies = append(m.modifies, (&changes{record: record.Interface()}).modifies())
+	}
+
+	na.addMutation(m)
+}
+
+// recordQueryMutation to snapshot when the snapshot is started.
+func (r *Repository) recordQueryMutation(method string, queriers []rel.Querier, modifies ...map[string]rel.Modify) {
+	na := r.nop()
+	if na.snapshotStarted() {
+		na.addMutation(mutation{method: method, query: rel.Build("", queriers...), modifies: modifies})
+	}
+}
@@ -0,0 +1,94 @@
+package reltest
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+type snapshotUser struct {
+	ID        int
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func snapshotFlow(repo *Repository) {
+	var (
+		ctx   = context.TODO()
+		book  = Book{Title: "Rel for dummies", AuthorID: 1}
+		users = []snapshotUser{{Name: "Kia"}, {Name: "Rel"}}
+	)
+
+	repo.MustInsert(ctx, &book)
+	repo.MustInsertAll(ctx, &users)
+	repo.MustUpdate(ctx, &book, rel.Set("title", "REL for dummies"), rel.Inc("views"))
+	repo.Transaction(ctx, func(repo rel.Repository) error {
+		repo.MustDelete(ctx, &book)
+		repo.MustDeleteAll(ctx, rel.From("ratings").Where(where.Eq("book_id", 1).AndLt("score", 5)))
+		return nil
+	})
+}
+
+func TestSnapshot(t *testing.T) {
+	repo := New().Snapshot().Relaxed()
+	snapshotFlow(repo)
+
+	assert.True(t, repo.AssertSnapshot(t, "testdata/mutations.snap"))
+}
+
+func TestSnapshot_mismatch(t *testing.T) {
+	repo := New().Snapshot().Relaxed()
+	snapshotFlow(repo)
+	repo.MustDelete(context.TODO(), &Book{ID: 2})
+
+	assert.False(t, repo.AssertSnapshot(&testing.T{}, "testdata/mutations.snap"))
+}
+
+func TestSnapshot_create(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reltest")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		repo     = New().Snapshot()
+		filename = filepath.Join(dir, "testdata", "book.snap")
+		book     = Book{ID: 1, Title: "Rel for dummies"}
+	)
+
+	repo.ExpectUpdate(rel.Set("title", "REL"))
+	assert.Nil(t, repo.Update(context.TODO(), &book, rel.Set("title", "REL")))
+
+	assert.True(t, repo.AssertSnapshot(t, filename))
+
+	data, err := ioutil.ReadFile(filename)
+	assert.Nil(t, err)
+	assert.Equal(t, "Update books\n\twhere: id = 1\n\tchanges: title = \"REL\"\n", string(data))
+
+	assert.True(t, repo.AssertSnapshot(t, filename))
+}
+
+func TestSnapshot_notStarted(t *testing.T) {
+	repo := New().Relaxed()
+	snapshotFlow(repo)
+
+	assert.Equal(t, "", repo.nop().snapshot())
+}
+
+func TestSnapshot_error(t *testing.T) {
+	var (
+		repo = New().Snapshot()
+		book = Book{ID: 1}
+	)
+
+	repo.ExpectDelete().ConnectionClosed()
+	assert.NotNil(t, repo.Delete(context.TODO(), &book))
+	assert.Equal(t, "", repo.nop().snapshot())
+}
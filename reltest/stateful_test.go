@@ -0,0 +1,173 @@
+package reltest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Fs02/rel"
+	"github.com/Fs02/rel/sort"
+	"github.com/Fs02/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+type Article struct {
+	ID        int
+	Title     string
+	Views     int
+	Published bool
+	CreatedAt time.Time
+	DeletedAt *time.Time
+}
+
+func TestStateful(t *testing.T) {
+	var (
+		ctx  = context.TODO()
+		repo = NewStateful()
+		book = Book{Title: "Rel for dummies", Views: 10}
+	)
+
+	assert.Nil(t, repo.Insert(ctx, &book))
+	assert.Equal(t, 1, book.ID)
+
+	var result Book
+	assert.Nil(t, repo.Find(ctx, &result, where.Eq("id", 1)))
+	assert.Equal(t, book, result)
+
+	assert.Nil(t, repo.Update(ctx, &book, rel.Set("title", "REL for dummies"), rel.Inc("views")))
+	assert.Nil(t, repo.Find(ctx, &result, rel.ByID(1)))
+	assert.Equal(t, "REL for dummies", result.Title)
+	assert.Equal(t, 11, result.Views)
+
+	assert.Nil(t, repo.Delete(ctx, &book))
+	assert.Equal(t, rel.NotFoundError{}, repo.Find(ctx, &result, where.Eq("id", 1)))
+	assert.Equal(t, rel.NotFoundError{}, repo.Update(ctx, &book))
+}
+
+func TestStateful_findAll(t *testing.T) {
+	var (
+		ctx    = context.TODO()
+		repo   = NewStateful()
+		result []Book
+		books  = []Book{
+			{Title: "Golang for dummies", Views: 5},
+			{Title: "Rel for dummies", Views: 20},
+			{Title: "Rel in action", Views: 10},
+			{Title: "Testing", Views: 15},
+		}
+	)
+
+	assert.Nil(t, repo.InsertAll(ctx, &books))
+	assert.Equal(t, 4, books[3].ID)
+
+	assert.Nil(t, repo.FindAll(ctx, &result, where.Like("title", "Rel%"), sort.Desc("views")))
+	assert.Equal(t, []Book{books[1], books[2]}, result)
+
+	assert.Nil(t, repo.FindAll(ctx, &result, where.Gte("views", 10).OrIn("id", 1), sort.Asc("views"), rel.Offset(1), rel.Limit(2)))
+	assert.Equal(t, []Book{books[2], books[3]}, result)
+
+	assert.Nil(t, repo.FindAll(ctx, &result, rel.Select("id", "title"), where.NotLike("title", "%dummies%")))
+	assert.Equal(t, []Book{{ID: 3, Title: "Rel in action"}, {ID: 4, Title: "Testing"}}, result)
+
+	count, err := repo.Count(ctx, "books", where.Ne("views", 5))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, count)
+
+	sum, err := repo.Aggregate(ctx, rel.From("books").Where(where.Nin("id", 1)), "sum", "views")
+	assert.Nil(t, err)
+	assert.Equal(t, 45, sum)
+
+	assert.Nil(t, repo.DeleteAll(ctx, rel.From("books").Where(where.Lt("views", 15))))
+	assert.Nil(t, repo.FindAll(ctx, &result, sort.Asc("id")))
+	assert.Equal(t, []Book{books[1], books[3]}, result)
+
+	assert.Error(t, repo.FindAll(ctx, &result, where.Fragment("views > ?", 1)))
+	assert.Error(t, repo.FindAll(ctx, &result, rel.Join("authors")))
+}
+
+func TestStateful_softDelete(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		repo    = NewStateful()
+		article = Article{Title: "Stateful", Published: true}
+		result  []Article
+	)
+
+	assert.Nil(t, repo.Insert(ctx, &article))
+	assert.False(t, article.CreatedAt.IsZero())
+
+	assert.Nil(t, repo.FindAll(ctx, &result, where.Eq("published", true)))
+	assert.Len(t, result, 1)
+
+	assert.Nil(t, repo.Delete(ctx, &article))
+	assert.Nil(t, repo.FindAll(ctx, &result))
+	assert.Len(t, result, 0)
+
+	assert.Nil(t, repo.FindAll(ctx, &result, rel.Unscoped(true)))
+	assert.Len(t, result, 1)
+	assert.NotNil(t, result[0].DeletedAt)
+}
+
+func TestStateful_preload(t *testing.T) {
+	var (
+		ctx    = context.TODO()
+		repo   = NewStateful()
+		author = Author{Name: "Kia"}
+		book   Book
+	)
+
+	assert.Nil(t, repo.Insert(ctx, &author))
+	assert.Nil(t, repo.Insert(ctx, &Book{Title: "Rel for dummies", AuthorID: author.ID}))
+
+	assert.Nil(t, repo.Find(ctx, &book, where.Eq("title", "Rel for dummies")))
+	assert.Nil(t, repo.Preload(ctx, &book, "author"))
+	assert.Equal(t, author, book.Author)
+}
+
+func TestStateful_expectation(t *testing.T) {
+	var (
+		ctx    = context.TODO()
+		repo   = NewStateful()
+		book   = Book{Title: "Rel for dummies"}
+		result Book
+	)
+
+	repo.ExpectInsert().ConnectionClosed()
+	assert.Equal(t, ErrConnectionClosed, repo.Insert(ctx, &book))
+	assert.Equal(t, rel.NotFoundError{}, repo.Find(ctx, &result, where.Eq("title", "Rel for dummies")))
+
+	repo.ExpectInsert()
+	assert.Nil(t, repo.Insert(ctx, &book))
+	assert.Nil(t, repo.Find(ctx, &result, where.Eq("title", "Rel for dummies")))
+
+	repo.ExpectFind(where.Eq("id", 1)).Result(Book{ID: 1, Title: "Mocked"})
+	assert.Nil(t, repo.Find(ctx, &result, where.Eq("id", 1)))
+	assert.Equal(t, "Mocked", result.Title)
+	repo.AssertExpectations(t)
+}
+
+func TestStateful_transaction(t *testing.T) {
+	var (
+		ctx    = context.TODO()
+		repo   = NewStateful()
+		result []Book
+	)
+
+	assert.Nil(t, repo.Transaction(ctx, func(repo rel.Repository) error {
+		return repo.Insert(ctx, &Book{Title: "Committed"})
+	}))
+
+	assert.Error(t, repo.Transaction(ctx, func(repo rel.Repository) error {
+		repo.MustInsert(ctx, &Book{Title: "Rolled back"})
+		return errors.New("error")
+	}))
+
+	assert.Nil(t, repo.FindAll(ctx, &result))
+	assert.Len(t, result, 1)
+	assert.Equal(t, "Committed", result[0].Title)
+
+	assert.Nil(t, repo.Insert(ctx, &Book{Title: "Next"}))
+	assert.Nil(t, repo.FindAll(ctx, &result, where.Eq("title", "Next")))
+	assert.Equal(t, 2, result[0].ID)
+}
@@ -0,0 +1,56 @@
+package reltest
+
+import (
+	"fmt"
+)
+
+type transactionOutcome int
+
+const (
+	anyOutcome transactionOutcome = iota
+	commitOutcome
+	rollbackOutcome
+)
+
+// Transaction asserts and simulate transaction function for test.
+type Transaction struct {
+	*Expect
+	outcome transactionOutcome
+}
+
+// Commit asserts that transaction is committed, which means transaction function returns nil.
+func (t *Transaction) Commit() *Transaction {
+	t.outcome = commitOutcome
+	return t
+}
+
+// Rollback asserts that transaction is rolled back, which means transaction function returns an error.
+func (t *Transaction) Rollback() *Transaction {
+	t.outcome = rollbackOutcome
+	return t
+}
+
+func (t *Transaction) assert(err error) {
+	switch {
+	case t == nil:
+		return
+	case t.outcome == commitOutcome && err != nil:
+		panic(fmt.Sprintf("reltest: expected transaction to be committed, but rolled back with error: %v", err))
+	case t.outcome == rollbackOutcome && err == nil:
+		panic("reltest: expected transaction to be rolled back, but committed")
+	}
+}
+
+// ExpectTransaction to be called, inner expectations are declared using given function.
+func ExpectTransaction(r *Repository, fn func(*Repository)) *Transaction {
+	et := &Transaction{
+		Expect: newExpect(r, "Transaction", nil, nil),
+	}
+
+	// error returned by expectation is used as error when starting the transaction.
+	et.Return(et, nil)
+
+	fn(&Repository{state: r.transaction(), scope: r.scope})
+
+	return et
+}
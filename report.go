@@ -0,0 +1,160 @@
+package rel
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+)
+
+// ErrorHandler is called with the operation, fingerprint of the query and the error, see OnError.
+type ErrorHandler func(ctx context.Context, op string, fingerprint string, err error)
+
+var (
+	errorHandler atomic.Value
+)
+
+// OnError sets global handler that is called for every unexpected error returned by adapter, see IsUnexpected.
+// It's intended to report errors to error tracking service in one place, values of sensitive fields in the error are redacted.
+// Operation is the same as Instrumenter, and nil handler removes the previous handler.
+//
+// Example:
+//	rel.OnError(func(ctx context.Context, op string, fingerprint string, err error) {
+//		sentry.WithScope(func(scope *sentry.Scope) {
+//			scope.SetFingerprint([]string{op, fingerprint})
+//			sentry.CaptureException(err)
+//		})
+//	})
+func OnError(handler ErrorHandler) {
+	errorHandler.Store(handler)
+}
+
+func reportError(ctx context.Context, op string, query Query, err error) {
+	handler, _ := errorHandler.Load().(ErrorHandler)
+	if handler == nil || !IsUnexpected(err) {
+		return
+	}
+
+	handler(ctx, op, Fingerprint(query), RedactError(ctx, err))
+}
+
+// Fingerprint returns normalized form of the query without its values, so queries that only differ by values share the same fingerprint.
+// eg: users WHERE (id = ? AND deleted_at IS NULL) ORDER BY created_at DESC LIMIT ?
+func Fingerprint(query Query) string {
+	var (
+		buffer strings.Builder
+	)
+
+	buffer.WriteString(query.Table)
+
+	if len(query.SelectQuery.Fields) > 0 {
+		buffer.WriteString(" SELECT ")
+		if query.SelectQuery.OnlyDistinct {
+			buffer.WriteString("DISTINCT ")
+		}
+
+		buffer.WriteString(strings.Join(query.SelectQuery.Fields, ", "))
+	}
+
+	for _, join := range query.JoinQuery {
+		buffer.WriteString(" " + join.Mode)
+		if join.Table != "" {
+			buffer.WriteString(" " + join.Table)
+		}
+
+		if join.From != "" {
+			buffer.WriteString(" ON " + join.From + " = " + join.To)
+		}
+	}
+
+	if !query.WhereQuery.None() {
+		buffer.WriteString(" WHERE ")
+		fingerprintFilter(&buffer, query.WhereQuery)
+	}
+
+	if len(query.GroupQuery.Fields) > 0 {
+		buffer.WriteString(" GROUP BY " + strings.Join(query.GroupQuery.Fields, ", "))
+
+		if !query.GroupQuery.Filter.None() {
+			buffer.WriteString(" HAVING ")
+			fingerprintFilter(&buffer, query.GroupQuery.Filter)
+		}
+	}
+
+	for i, sort := range query.SortQuery {
+		if i == 0 {
+			buffer.WriteString(" ORDER BY ")
+		} else {
+			buffer.WriteString(", ")
+		}
+
+		buffer.WriteString(sort.Field)
+		if sort.Asc() {
+			buffer.WriteString(" ASC")
+		} else {
+			buffer.WriteString(" DESC")
+		}
+	}
+
+	if query.LimitQuery > 0 {
+		buffer.WriteString(" LIMIT ?")
+	}
+
+	if query.OffsetQuery > 0 {
+		buffer.WriteString(" OFFSET ?")
+	}
+
+	if query.LockQuery != "" {
+		buffer.WriteString(" " + string(query.LockQuery))
+	}
+
+	return buffer.String()
+}
+
+func fingerprintFilter(buffer *strings.Builder, filter FilterQuery) {
+	switch filter.Type {
+	case FilterAndOp, FilterOrOp:
+		op := " AND "
+		if filter.Type == FilterOrOp {
+			op = " OR "
+		}
+
+		buffer.WriteByte('(')
+		for i := range filter.Inner {
+			if i > 0 {
+				buffer.WriteString(op)
+			}
+
+			fingerprintFilter(buffer, filter.Inner[i])
+		}
+		buffer.WriteByte(')')
+	case FilterNotOp:
+		buffer.WriteString("NOT ")
+		fingerprintFilter(buffer, And(filter.Inner...))
+	case FilterEqOp:
+		buffer.WriteString(filter.Field + " = ?")
+	case FilterNeOp:
+		buffer.WriteString(filter.Field + " <> ?")
+	case FilterLtOp:
+		buffer.WriteString(filter.Field + " < ?")
+	case FilterLteOp:
+		buffer.WriteString(filter.Field + " <= ?")
+	case FilterGtOp:
+		buffer.WriteString(filter.Field + " > ?")
+	case FilterGteOp:
+		buffer.WriteString(filter.Field + " >= ?")
+	case FilterNilOp:
+		buffer.WriteString(filter.Field + " IS NULL")
+	case FilterNotNilOp:
+		buffer.WriteString(filter.Field + " IS NOT NULL")
+	case FilterInOp:
+		buffer.WriteString(filter.Field + " IN (?)")
+	case FilterNinOp:
+		buffer.WriteString(filter.Field + " NOT IN (?)")
+	case FilterLikeOp:
+		buffer.WriteString(filter.Field + " LIKE ?")
+	case FilterNotLikeOp:
+		buffer.WriteString(filter.Field + " NOT LIKE ?")
+	case FilterFragmentOp:
+		buffer.WriteString(filter.Field)
+	}
+}
@@ -0,0 +1,106 @@
+package rel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type report struct {
+	op          string
+	fingerprint string
+	err         error
+}
+
+func TestOnError(t *testing.T) {
+	var (
+		reports []report
+		ctx     = context.TODO()
+		err     = errors.New("connection reset by peer")
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	OnError(func(ctx context.Context, op string, fingerprint string, err error) {
+		reports = append(reports, report{op: op, fingerprint: fingerprint, err: err})
+	})
+	defer OnError(nil)
+
+	adapter.On("Aggregate", From("users").Where(Eq("name", "rel")), "count", "*").Return(0, err).Once()
+	adapter.On("Delete", From("users").Where(Eq("id", 10))).Return(0, ConstraintError{Type: ForeignKeyConstraint}).Once()
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Commit").Return(err).Once()
+
+	_, _ = repo.Count(ctx, "users", Eq("name", "rel"))
+	_ = repo.Delete(ctx, &User{ID: 10})
+	_ = repo.Transaction(ctx, func(Repository) error { return nil })
+
+	assert.Equal(t, []report{
+		{op: "aggregate", fingerprint: "users WHERE name = ?", err: err},
+		{op: "commit", fingerprint: "", err: err},
+	}, reports)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestOnError_sensitive(t *testing.T) {
+	var (
+		reports []report
+		adapter = &redactAdapter{}
+		repo    = repository{adapter: adapter}
+	)
+
+	OnError(func(ctx context.Context, op string, fingerprint string, err error) {
+		reports = append(reports, report{op: op, fingerprint: fingerprint, err: err})
+	})
+	defer OnError(nil)
+
+	err := repo.Insert(context.TODO(), &credential{Name: "rel", Token: "s3cr3t"})
+	assert.NotNil(t, err)
+	assert.Len(t, reports, 1)
+	assert.Equal(t, "insert", reports[0].op)
+	assert.Equal(t, "credentials", reports[0].fingerprint)
+	assert.EqualError(t, reports[0].err, "duplicate entry '[REDACTED]'")
+}
+
+func TestFingerprint(t *testing.T) {
+	tests := []struct {
+		query       Query
+		fingerprint string
+	}{
+		{
+			query:       From("users"),
+			fingerprint: "users",
+		},
+		{
+			query:       From("users").Where(Eq("id", 1).AndNil("deleted_at")).SortDesc("created_at").Limit(10).Offset(20),
+			fingerprint: "users WHERE (id = ? AND deleted_at IS NULL) ORDER BY created_at DESC LIMIT ? OFFSET ?",
+		},
+		{
+			query:       From("users").Where(In("id", 1, 2, 3).OrNotNil("admin")),
+			fingerprint: "users WHERE (id IN (?) OR admin IS NOT NULL)",
+		},
+		{
+			query:       From("users").Where(Not(Ne("a", 1), Lt("b", 1), Lte("c", 1), Gt("d", 1), Gte("e", 1)), Nin("f", 1), Like("g", "%a"), NotLike("h", "%a"), FilterFragment("i = ?", 1)),
+			fingerprint: "users WHERE (NOT (a <> ? AND b < ? AND c <= ? AND d > ? AND e >= ?) AND f NOT IN (?) AND g LIKE ? AND h NOT LIKE ? AND i = ?)",
+		},
+		{
+			query:       From("transactions").Select("user_id", "SUM(amount)").Distinct().Join("users").JoinOn("addresses", "users.id", "addresses.user_id").Group("user_id").Having(Gt("SUM(amount)", 100)).SortAsc("user_id").Lock("FOR UPDATE"),
+			fingerprint: "transactions SELECT DISTINCT user_id, SUM(amount) JOIN users JOIN addresses ON users.id = addresses.user_id GROUP BY user_id HAVING SUM(amount) > ? ORDER BY user_id ASC FOR UPDATE",
+		},
+		{
+			query:       From("users").Joinf("JOIN addresses ON addresses.user_id = users.id AND addresses.primary = ?", true),
+			fingerprint: "users JOIN addresses ON addresses.user_id = users.id AND addresses.primary = ?",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.fingerprint, func(t *testing.T) {
+			assert.Equal(t, test.fingerprint, Fingerprint(test.query))
+		})
+	}
+
+	assert.Equal(t, Fingerprint(From("users").Where(Eq("id", 1))), Fingerprint(From("users").Where(Eq("id", 2))))
+}
@@ -2,18 +2,27 @@ package rel
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"reflect"
 	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Repository defines sets of available database operations.
-// TODO: support update all.
 type Repository interface {
 	Adapter() Adapter
 	SetLogger(logger ...Logger)
+	SetLogLevels(levels LogLevels)
+	SetPreloadParallelism(n int)
+	SetInChunkSize(n int)
+	Instrumentation(instrumenters ...Instrumenter)
 	Ping(ctx context.Context) error
+	Stats() sql.DBStats
 	Aggregate(ctx context.Context, query Query, aggregate string, field string) (int, error)
 	MustAggregate(ctx context.Context, query Query, aggregate string, field string) int
 	Count(ctx context.Context, collection string, queriers ...Querier) (int, error)
@@ -22,25 +31,48 @@ type Repository interface {
 	MustFind(ctx context.Context, record interface{}, queriers ...Querier)
 	FindAll(ctx context.Context, records interface{}, queriers ...Querier) error
 	MustFindAll(ctx context.Context, records interface{}, queriers ...Querier)
+	FindEach(ctx context.Context, record interface{}, fn func(record interface{}) error, queriers ...Querier) error
+	MustFindEach(ctx context.Context, record interface{}, fn func(record interface{}) error, queriers ...Querier)
 	Insert(ctx context.Context, record interface{}, modifiers ...Modifier) error
 	MustInsert(ctx context.Context, record interface{}, modifiers ...Modifier)
-	InsertAll(ctx context.Context, records interface{}) error
-	MustInsertAll(ctx context.Context, records interface{})
+	InsertAll(ctx context.Context, records interface{}, onConflict ...OnConflict) error
+	MustInsertAll(ctx context.Context, records interface{}, onConflict ...OnConflict)
 	Update(ctx context.Context, record interface{}, modifiers ...Modifier) error
 	MustUpdate(ctx context.Context, record interface{}, modifiers ...Modifier)
+	UpdateAny(ctx context.Context, query Query, modifies ...Modify) (int, error)
+	MustUpdateAny(ctx context.Context, query Query, modifies ...Modify) int
 	Delete(ctx context.Context, record interface{}) error
 	MustDelete(ctx context.Context, record interface{})
 	DeleteAll(ctx context.Context, queriers ...Querier) error
 	MustDeleteAll(ctx context.Context, queriers ...Querier)
+	DeleteAny(ctx context.Context, query Query) (int, error)
+	MustDeleteAny(ctx context.Context, query Query) int
 	Preload(ctx context.Context, records interface{}, field string, queriers ...Querier) error
 	MustPreload(ctx context.Context, records interface{}, field string, queriers ...Querier)
-	Transaction(ctx context.Context, fn func(Repository) error) error
+	PreloadAll(ctx context.Context, records interface{}, fields ...string) error
+	MustPreloadAll(ctx context.Context, records interface{}, fields ...string)
+	PreloadMap(ctx context.Context, records interface{}, field string, queriers ...Querier) (map[interface{}]interface{}, error)
+	MustPreloadMap(ctx context.Context, records interface{}, field string, queriers ...Querier) map[interface{}]interface{}
+	Association(record interface{}, field string) AssociationProxy
+	Transaction(ctx context.Context, fn func(Repository) error, opts ...TransactionOption) error
+	Set(key string, value interface{})
+	Get(key string) interface{}
 }
 
 type repository struct {
 	adapter       Adapter
 	logger        []Logger
+	quietLogger   []Logger
+	logLevels     LogLevels
+	instrumenters []Instrumenter
 	inTransaction bool
+	readOnly      bool
+	root          Adapter
+	statements    *int32
+	metadata      *metadata
+
+	preloadParallelism int
+	inChunkSize        int
 }
 
 func (r repository) Adapter() Adapter {
@@ -49,6 +81,36 @@ func (r repository) Adapter() Adapter {
 
 func (r *repository) SetLogger(logger ...Logger) {
 	r.logger = logger
+	r.quietLogger = quiet(logger)
+}
+
+// SetLogLevels configures level of statements by operation class, see LogLevels.
+func (r *repository) SetLogLevels(levels LogLevels) {
+	r.logLevels = levels
+}
+
+// SetPreloadParallelism limits number of association queries that are executed concurrently,
+// when multiple associations are preloaded using PreloadAll or preload query. Zero means unlimited (default),
+// and one loads every association in order.
+func (r *repository) SetPreloadParallelism(n int) {
+	r.preloadParallelism = n
+}
+
+// SetInChunkSize splits query into multiple queries when its in filter has more values than n, and merges the results.
+// Queries with limit, offset, group, distinct or sort (except preload) are never split. Zero disables chunking (default).
+//
+// Example:
+//	repo.SetInChunkSize(10000)
+//
+//	// executes 10 queries that each has 10000 ids.
+//	repo.FindAll(ctx, &books, where.In("id", ids...)) // len(ids) == 100000
+func (r *repository) SetInChunkSize(n int) {
+	r.inChunkSize = n
+}
+
+// Instrumentation replaces instrumenters that are called around every adapter call, see Instrumenter.
+func (r *repository) Instrumentation(instrumenters ...Instrumenter) {
+	r.instrumenters = instrumenters
 }
 
 // Ping database.
@@ -56,6 +118,16 @@ func (r *repository) Ping(ctx context.Context) error {
 	return r.adapter.Ping(ctx)
 }
 
+// Stats returns connection pool statistics of the adapter.
+// Zero value will be returned if adapter doesn't maintain connection pool.
+func (r repository) Stats() sql.DBStats {
+	if sa, ok := r.adapter.(StatsAdapter); ok {
+		return sa.Stats()
+	}
+
+	return sql.DBStats{}
+}
+
 // Aggregate calculate aggregate over the given field.
 // Supported aggregate: count, sum, avg, max, min.
 // Any select, group, offset, limit and sort query will be ignored automatically.
@@ -66,7 +138,18 @@ func (r repository) Aggregate(ctx context.Context, query Query, aggregate string
 	query.OffsetQuery = 0
 	query.SortQuery = nil
 
-	return r.adapter.Aggregate(ctx, query, aggregate, field, r.logger...)
+	if err := r.validate(query); err != nil {
+		return 0, err
+	}
+
+	ctx = r.instrument(ctx)
+	ctx, loggers := r.loggers(ctx, r.logLevels.Read, query)
+
+	finish := r.observe(ctx, "aggregate", query)
+	result, err := r.adapter.Aggregate(ctx, query, aggregate, field, loggers...)
+	finish(err)
+
+	return result, err
 }
 
 // MustAggregate calculate aggregate over the given field.
@@ -94,10 +177,12 @@ func (r repository) MustCount(ctx context.Context, collection string, queriers .
 // If no result found, it'll return not found error.
 func (r repository) Find(ctx context.Context, record interface{}, queriers ...Querier) error {
 	var (
-		doc   = NewDocument(record)
+		doc   = acquireDocument(record)
 		query = Build(doc.Table(), queriers...)
 	)
 
+	defer releaseDocument(doc)
+
 	return r.find(ctx, doc, query)
 }
 
@@ -108,22 +193,52 @@ func (r repository) MustFind(ctx context.Context, record interface{}, queriers .
 }
 
 func (r repository) find(ctx context.Context, doc *Document, query Query) error {
+	if err := r.validate(query); err != nil {
+		return err
+	}
+
+	if len(query.EagerQuery) > 0 && !r.adapter.Capabilities().Is(JoinCapability) {
+		query = preloadEager(query)
+	}
+
 	query = r.withDefaultScope(doc.data, query)
-	cur, err := r.adapter.Query(ctx, query.Limit(1), r.logger...)
+	if len(query.EagerQuery) > 0 {
+		query = buildEager(doc.rt, query)
+	}
+
+	ctx = r.instrument(ctx)
+	ctx, loggers := r.loggers(ctx, r.logLevels.Read, query)
+
+	finish := r.observe(ctx, "query", query)
+	cur, err := r.adapter.Query(ctx, query.Limit(1), loggers...)
+	finish(err)
+
+	if err != nil {
+		return err
+	}
+
+	if len(query.EagerQuery) > 0 {
+		err = scanEager(cur, doc, query.EagerQuery)
+	} else {
+		err = scanOne(cur, doc)
+	}
+
 	if err != nil {
 		return err
 	}
 
-	return scanOne(cur, doc)
+	return r.preloadQueries(ctx, doc, query.PreloadQuery)
 }
 
 // FindAll records that match the query.
 func (r repository) FindAll(ctx context.Context, records interface{}, queriers ...Querier) error {
 	var (
-		col   = NewCollection(records)
+		col   = acquireCollection(records)
 		query = Build(col.Table(), queriers...)
 	)
 
+	defer releaseCollection(col)
+
 	col.Reset()
 
 	return r.findAll(ctx, col, query)
@@ -136,33 +251,111 @@ func (r repository) MustFindAll(ctx context.Context, records interface{}, querie
 }
 
 func (r repository) findAll(ctx context.Context, col *Collection, query Query) error {
+	if err := r.validate(query); err != nil {
+		return err
+	}
+
+	if len(query.EagerQuery) > 0 && !r.adapter.Capabilities().Is(JoinCapability) {
+		query = preloadEager(query)
+	}
+
 	query = r.withDefaultScope(col.data, query)
-	cur, err := r.adapter.Query(ctx, query, r.logger...)
+	if len(query.EagerQuery) > 0 {
+		query = buildEager(col.rt.Elem(), query)
+	}
+
+	ctx = r.instrument(ctx)
+	ctx, loggers := r.loggers(ctx, r.logLevels.Read, query)
+
+	cur, err := r.query(ctx, "query", query, loggers, false)
+	if err != nil {
+		return err
+	}
+
+	if len(query.EagerQuery) > 0 {
+		err = scanEager(cur, col, query.EagerQuery)
+	} else {
+		err = scanMany(cur, col)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return r.preloadQueries(ctx, col, query.PreloadQuery)
+}
+
+// FindEach scans records that match the query one by one into the record, and calls fn with the record after each row is scanned.
+// The same record is reused and reset to zero value for every row, so memory usage stays flat regardless the number of rows,
+// fn must copy the record if it needs to keep it. Iteration stops when fn returns an error, and the error is returned.
+// Preload and eager query are not supported, ErrFindEachPreload is returned when they're used.
+//
+// Example:
+//	var book Book
+//	err := repo.FindEach(ctx, &book, func(record interface{}) error {
+//		return writer.Write([]string{book.Title})
+//	}, where.Eq("published", true))
+func (r repository) FindEach(ctx context.Context, record interface{}, fn func(record interface{}) error, queriers ...Querier) error {
+	var (
+		doc   = acquireDocument(record)
+		query = Build(doc.Table(), queriers...)
+	)
+
+	defer releaseDocument(doc)
+
+	if len(query.PreloadQuery) > 0 || len(query.EagerQuery) > 0 {
+		return ErrFindEachPreload
+	}
+
+	if err := r.validate(query); err != nil {
+		return err
+	}
+
+	query = r.withDefaultScope(doc.data, query)
+
+	ctx = r.instrument(ctx)
+	ctx, loggers := r.loggers(ctx, r.logLevels.Read, query)
+
+	cur, err := r.query(ctx, "query", query, loggers, false)
 	if err != nil {
 		return err
 	}
 
-	return scanMany(cur, col)
+	return scanEach(cur, doc, func() error {
+		return fn(record)
+	})
+}
+
+// MustFindEach scans records that match the query one by one into the record, and calls fn with the record.
+// It'll panic if any error eccured.
+func (r repository) MustFindEach(ctx context.Context, record interface{}, fn func(record interface{}) error, queriers ...Querier) {
+	must(r.FindEach(ctx, record, fn, queriers...))
 }
 
 // Insert an record to database.
 func (r repository) Insert(ctx context.Context, record interface{}, modifiers ...Modifier) error {
+	if r.readOnly {
+		return ErrReadOnlyTransaction
+	}
+
 	if record == nil {
 		return nil
 	}
 
 	var (
 		modification Modification
-		doc          = NewDocument(record)
+		doc          = acquireDocument(record)
 	)
 
+	defer releaseDocument(doc)
+
 	if len(modifiers) == 0 {
 		modification = Apply(doc, newStructset(doc, false))
 	} else {
 		modification = Apply(doc, modifiers...)
 	}
 
-	if len(modification.Assoc) > 0 {
+	if len(modification.Assoc) > 0 || len(doc.data.touch) > 0 {
 		return r.Transaction(ctx, func(r Repository) error {
 			return r.(*repository).insert(ctx, doc, modification)
 		})
@@ -174,14 +367,30 @@ func (r repository) Insert(ctx context.Context, record interface{}, modifiers ..
 func (r repository) insert(ctx context.Context, doc *Document, modification Modification) error {
 	var (
 		pField   = doc.PrimaryField()
-		queriers = Build(doc.Table())
+		queriers = Build(doc.Table(), modification.OnConflict)
 	)
 
+	if modification.OnConflict != "" {
+		if err := r.require(OnConflictCapability); err != nil {
+			return err
+		}
+	}
+
 	if err := r.saveBelongsTo(ctx, doc, &modification); err != nil {
 		return err
 	}
 
-	pValue, err := r.Adapter().Insert(ctx, queriers, modification.Modifies, r.logger...)
+	if err := r.verify(ctx, doc.data, doc, modification); err != nil {
+		return err
+	}
+
+	ctx = r.instrument(ctx)
+	ctx, loggers := r.loggers(ctx, r.logLevels.Write, queriers, modification.Modifies)
+
+	finish := r.observe(ctx, "insert", queriers)
+	pValue, err := r.Adapter().Insert(ctx, queriers, modification.Modifies, loggers...)
+	finish(err)
+
 	if err != nil {
 		return err
 	}
@@ -204,7 +413,7 @@ func (r repository) insert(ctx context.Context, doc *Document, modification Modi
 		return err
 	}
 
-	return nil
+	return r.touch(ctx, doc.data, doc)
 }
 
 // MustInsert an record to database.
@@ -213,7 +422,13 @@ func (r repository) MustInsert(ctx context.Context, record interface{}, modifier
 	must(r.Insert(ctx, record, modifiers...))
 }
 
-func (r repository) InsertAll(ctx context.Context, records interface{}) error {
+// InsertAll records to database.
+// Conflict with existing records is resolved using onConflict option when it's specified, it requires adapter that supports it.
+func (r repository) InsertAll(ctx context.Context, records interface{}, onConflict ...OnConflict) error {
+	if r.readOnly {
+		return ErrReadOnlyTransaction
+	}
+
 	if records == nil {
 		return nil
 	}
@@ -226,13 +441,25 @@ func (r repository) InsertAll(ctx context.Context, records interface{}) error {
 	for i := range mods {
 		doc := col.Get(i)
 		mods[i] = Apply(doc, newStructset(doc, false))
+
+		for j := range onConflict {
+			onConflict[j].Apply(doc, &mods[i])
+		}
+	}
+
+	if len(col.data.touch) > 0 {
+		return r.Transaction(ctx, func(r Repository) error {
+			return r.(*repository).insertAll(ctx, col, mods)
+		})
 	}
 
 	return r.insertAll(ctx, col, mods)
 }
 
-func (r repository) MustInsertAll(ctx context.Context, records interface{}) {
-	must(r.InsertAll(ctx, records))
+// MustInsertAll records to database.
+// It'll panic if any error occurred.
+func (r repository) MustInsertAll(ctx context.Context, records interface{}, onConflict ...OnConflict) {
+	must(r.InsertAll(ctx, records, onConflict...))
 }
 
 // TODO: support assocs
@@ -243,7 +470,7 @@ func (r repository) insertAll(ctx context.Context, col *Collection, modification
 
 	var (
 		pField       = col.PrimaryField()
-		queriers     = Build(col.Table())
+		queriers     = Build(col.Table(), modification[0].OnConflict)
 		fields       = make([]string, 0, len(modification[0].Modifies))
 		fieldMap     = make(map[string]struct{}, len(modification[0].Modifies))
 		bulkModifies = make([]map[string]Modify, len(modification))
@@ -260,7 +487,23 @@ func (r repository) insertAll(ctx context.Context, col *Collection, modification
 		bulkModifies[i] = modification[i].Modifies
 	}
 
-	ids, err := r.adapter.InsertAll(ctx, queriers, fields, bulkModifies, r.logger...)
+	if queriers.OnConflictQuery != "" {
+		if err := r.require(OnConflictCapability); err != nil {
+			return err
+		}
+	}
+
+	if err := r.verify(ctx, col.data, col, modification...); err != nil {
+		return err
+	}
+
+	ctx = r.instrument(ctx)
+	ctx, loggers := r.loggers(ctx, r.logLevels.Write, queriers, bulkModifies...)
+
+	finish := r.observe(ctx, "insert_all", queriers)
+	ids, err := r.adapter.InsertAll(ctx, queriers, fields, bulkModifies, loggers...)
+	finish(err)
+
 	if err != nil {
 		return err
 	}
@@ -270,33 +513,39 @@ func (r repository) insertAll(ctx context.Context, col *Collection, modification
 		col.Get(i).SetValue(pField, id)
 	}
 
-	return nil
+	return r.touch(ctx, col.data, col)
 }
 
 // Update an record in database.
 // It'll panic if any error occurred.
+// Has many association is updated by default, existing record is updated, new record is inserted and missing record is deleted, see Replacement.
 // not supported:
-// - update has many (will be replaced by default)
 // - replacing has one or belongs to assoc may cause duplicate record, please ensure database level unique constraint enabled.
 func (r repository) Update(ctx context.Context, record interface{}, modifiers ...Modifier) error {
+	if r.readOnly {
+		return ErrReadOnlyTransaction
+	}
+
 	if record == nil {
 		return nil
 	}
 
 	var (
 		modification Modification
-		doc          = NewDocument(record)
+		doc          = acquireDocument(record)
 		pField       = doc.PrimaryField()
 		pValue       = doc.PrimaryValue()
 	)
 
+	defer releaseDocument(doc)
+
 	if len(modifiers) == 0 {
 		modification = Apply(doc, newStructset(doc, false))
 	} else {
 		modification = Apply(doc, modifiers...)
 	}
 
-	if len(modification.Assoc) > 0 {
+	if len(modification.Assoc) > 0 || len(doc.data.touch) > 0 {
 		return r.Transaction(ctx, func(r Repository) error {
 			return r.(*repository).update(ctx, doc, modification, Eq(pField, pValue))
 		})
@@ -311,11 +560,21 @@ func (r repository) update(ctx context.Context, doc *Document, modification Modi
 	}
 
 	if len(modification.Modifies) != 0 {
+		if err := r.verify(ctx, doc.data, doc, modification); err != nil {
+			return err
+		}
+
+		ctx = r.instrument(ctx)
+
 		var (
 			query             = r.withDefaultScope(doc.data, Build(doc.Table(), filter, modification.Unscoped))
-			updatedCount, err = r.adapter.Update(ctx, query, modification.Modifies, r.logger...)
+			ctx, loggers      = r.loggers(ctx, r.logLevels.Write, query, modification.Modifies)
+			finish            = r.observe(ctx, "update", query)
+			updatedCount, err = r.adapter.Update(ctx, query, modification.Modifies, loggers...)
 		)
 
+		finish(err)
+
 		if err != nil {
 			return err
 		}
@@ -329,6 +588,10 @@ func (r repository) update(ctx context.Context, doc *Document, modification Modi
 				return err
 			}
 		}
+
+		if err := r.touch(ctx, doc.data, doc); err != nil {
+			return err
+		}
 	}
 
 	if err := r.saveHasOne(ctx, doc, &modification); err != nil {
@@ -457,14 +720,12 @@ func (r repository) saveHasMany(ctx context.Context, doc *Document, modification
 		}
 
 		var (
-			assoc      = doc.Association(field)
-			col, _     = assoc.Collection()
-			table      = col.Table()
-			pField     = col.PrimaryField()
-			fField     = assoc.ForeignField()
-			rValue     = assoc.ReferenceValue()
-			mods       = assocMods.Modifications
-			deletedIDs = assocMods.DeletedIDs
+			assoc  = doc.Association(field)
+			col, _ = assoc.Collection()
+			pField = col.PrimaryField()
+			fField = assoc.ForeignField()
+			rValue = assoc.ReferenceValue()
+			mods   = assocMods.Modifications
 		)
 
 		// this shouldn't happen unless there's bug in the modifier.
@@ -472,20 +733,21 @@ func (r repository) saveHasMany(ctx context.Context, doc *Document, modification
 			panic("rel: invalid modifier")
 		}
 
-		if !insertion {
-			var (
-				filter = Eq(fField, rValue)
-			)
+		replacement, ok := modification.Replacements[field]
+		if !ok {
+			replacement = assoc.Replacement()
+		}
 
-			if deletedIDs == nil {
-				// if it's nil, then clear old association (used by structset).
-				if err := r.deleteAll(ctx, col.data.flag, Build(table, filter)); err != nil {
-					return err
-				}
-			} else if len(deletedIDs) > 0 {
-				if err := r.deleteAll(ctx, col.data.flag, Build(table, filter.AndIn(pField, deletedIDs...))); err != nil {
-					return err
-				}
+		if assocMods.DeletedIDs == nil && (insertion || replacement == ReplaceDelete) {
+			// reset id of every record (used by structset), since it'll be reinserted.
+			for i := range mods {
+				col.Get(i).SetValue(pField, nil)
+			}
+		}
+
+		if !insertion {
+			if err := r.replaceHasMany(ctx, assoc, col, assocMods.DeletedIDs, replacement); err != nil {
+				return err
 			}
 		}
 
@@ -550,21 +812,34 @@ func (r repository) saveHasMany(ctx context.Context, doc *Document, modification
 
 // Delete single entry.
 func (r repository) Delete(ctx context.Context, record interface{}) error {
+	if r.readOnly {
+		return ErrReadOnlyTransaction
+	}
+
 	var (
 		err          error
 		deletedCount int
-		doc          = NewDocument(record)
+		doc          = acquireDocument(record)
 		table        = doc.Table()
 		pField       = doc.PrimaryField()
 		pValue       = doc.PrimaryValue()
 		query        = Build(table, Eq(pField, pValue))
 	)
 
+	defer releaseDocument(doc)
+
+	ctx = r.instrument(ctx)
+	ctx, loggers := r.loggers(ctx, r.logLevels.Write, query)
+
 	if doc.Flag(HasDeletedAt) {
 		modifies := map[string]Modify{"deleted_at": Set("deleted_at", now())}
-		deletedCount, err = r.adapter.Update(ctx, query, modifies, r.logger...)
+		finish := r.observe(ctx, "update", query)
+		deletedCount, err = r.adapter.Update(ctx, query, modifies, loggers...)
+		finish(err)
 	} else {
-		deletedCount, err = r.adapter.Delete(ctx, query, r.logger...)
+		finish := r.observe(ctx, "delete", query)
+		deletedCount, err = r.adapter.Delete(ctx, query, loggers...)
+		finish(err)
 	}
 
 	if err == nil && deletedCount == 0 {
@@ -581,209 +856,1007 @@ func (r repository) MustDelete(ctx context.Context, record interface{}) {
 }
 
 func (r repository) DeleteAll(ctx context.Context, queriers ...Querier) error {
+	if r.readOnly {
+		return ErrReadOnlyTransaction
+	}
+
 	var (
 		q = Build("", queriers...)
 	)
 
-	return r.deleteAll(ctx, Invalid, q)
+	_, err := r.deleteAll(ctx, Invalid, q)
+	return err
 }
 
 func (r repository) MustDeleteAll(ctx context.Context, queriers ...Querier) {
 	must(r.DeleteAll(ctx, queriers...))
 }
 
-func (r repository) deleteAll(ctx context.Context, flag DocumentFlag, query Query) error {
-	var (
-		err error
-	)
-
-	if flag.Is(HasDeletedAt) {
-		modifies := map[string]Modify{"deleted_at": Set("deleted_at", nil)}
-		_, err = r.adapter.Update(ctx, query, modifies, r.logger...)
-	} else {
-		_, err = r.adapter.Delete(ctx, query, r.logger...)
+// DeleteAny deletes every record that matches the query, and returns the number of deleted records.
+// Soft delete is not applied, since the query isn't bound to a record.
+func (r repository) DeleteAny(ctx context.Context, query Query) (int, error) {
+	if r.readOnly {
+		return 0, ErrReadOnlyTransaction
 	}
 
-	return err
+	return r.deleteAll(ctx, Invalid, query)
 }
 
-// Preload loads association with given query.
-func (r repository) Preload(ctx context.Context, records interface{}, field string, queriers ...Querier) error {
-	var (
-		sl   slice
-		path = strings.Split(field, ".")
-		rt   = reflect.TypeOf(records)
-	)
-
-	if rt.Kind() != reflect.Ptr {
-		panic("rel: record parameter must be a pointer.")
-	}
+// MustDeleteAny deletes every record that matches the query, and returns the number of deleted records.
+// It'll panic if any error occurred.
+func (r repository) MustDeleteAny(ctx context.Context, query Query) int {
+	deletedCount, err := r.DeleteAny(ctx, query)
+	must(err)
+	return deletedCount
+}
 
-	rt = rt.Elem()
-	if rt.Kind() == reflect.Slice {
-		sl = NewCollection(records)
-	} else {
-		sl = NewDocument(records)
+// UpdateAny updates every record that matches the query using the modifies, and returns the number of updated records.
+// Unlike Update, updated_at field is not set automatically.
+func (r repository) UpdateAny(ctx context.Context, query Query, modifies ...Modify) (int, error) {
+	if r.readOnly {
+		return 0, ErrReadOnlyTransaction
 	}
 
-	var (
-		targets, table, keyField, keyType, ddata = r.mapPreloadTargets(sl, path)
-	)
-
-	if len(targets) == 0 {
-		return nil
+	if len(modifies) == 0 {
+		return 0, nil
 	}
 
 	var (
-		ids = make([]interface{}, len(targets))
-		i   = 0
+		mods = make(map[string]Modify, len(modifies))
 	)
 
-	for key := range targets {
-		ids[i] = key
-		i++
+	for i := range modifies {
+		mods[modifies[i].Field] = modifies[i]
 	}
 
-	var (
-		query    = Build(table, append(queriers, In(keyField, ids...))...)
-		cur, err = r.adapter.Query(ctx, r.withDefaultScope(ddata, query), r.logger...)
-	)
+	ctx = r.instrument(ctx)
+	ctx, loggers := r.loggers(ctx, r.logLevels.Write, query, mods)
 
-	if err != nil {
-		return err
-	}
+	finish := r.observe(ctx, "update", query)
+	updatedCount, err := r.adapter.Update(ctx, query, mods, loggers...)
+	finish(err)
 
-	return scanMulti(cur, keyField, keyType, targets)
+	return updatedCount, err
 }
 
-// MustPreload loads association with given query.
+// MustUpdateAny updates every record that matches the query using the modifies, and returns the number of updated records.
 // It'll panic if any error occurred.
-func (r repository) MustPreload(ctx context.Context, records interface{}, field string, queriers ...Querier) {
-	must(r.Preload(ctx, records, field, queriers...))
+func (r repository) MustUpdateAny(ctx context.Context, query Query, modifies ...Modify) int {
+	updatedCount, err := r.UpdateAny(ctx, query, modifies...)
+	must(err)
+	return updatedCount
 }
 
-func (r repository) mapPreloadTargets(sl slice, path []string) (map[interface{}][]slice, string, string, reflect.Type, documentData) {
-	type frame struct {
-		index int
-		doc   *Document
-	}
-
-	var (
-		table     string
-		keyField  string
-		keyType   reflect.Type
-		ddata     documentData
-		mapTarget = make(map[interface{}][]slice)
-		stack     = make([]frame, sl.Len())
-	)
-
-	// init stack
-	for i := 0; i < len(stack); i++ {
-		stack[i] = frame{index: 0, doc: sl.Get(i)}
-	}
-
-	for len(stack) > 0 {
+// verify ensures records referenced by belongs to associations that are declared with verify tag exist, eg: `verify:"true"`.
+// Only reference that is set by the modifications is verified, reference to association that is saved along the record is skipped.
+func (r repository) verify(ctx context.Context, ddata documentData, sl slice, modifications ...Modification) error {
+	for _, field := range ddata.verify {
 		var (
-			n      = len(stack) - 1
-			top    = stack[n]
-			assocs = top.doc.Association(path[top.index])
+			assoc  = sl.Get(0).Association(field)
+			rField = assoc.ReferenceField()
+			fField = assoc.ForeignField()
+			fType  = assoc.foreignType()
+			values []interface{}
+			seen   = make(map[interface{}]bool)
+			found  = make(map[interface{}]bool)
 		)
 
-		stack = stack[:n]
-
-		if top.index == len(path)-1 {
-			var (
-				target slice
-				ref    = assocs.ReferenceValue()
-			)
+		for i := range modifications {
+			if _, saved := modifications[i].Assoc[field]; saved {
+				continue
+			}
 
-			if ref == nil {
+			mod, ok := modifications[i].Modifies[rField]
+			if !ok || mod.Type != ChangeSetOp || isZero(mod.Value) {
 				continue
 			}
 
-			if assocs.Type() == HasMany {
-				target, _ = assocs.Collection()
-			} else {
-				target, _ = assocs.Document()
+			rv := reflect.Indirect(reflect.ValueOf(mod.Value))
+			if rv.Type().ConvertibleTo(fType) {
+				rv = rv.Convert(fType)
 			}
 
-			target.Reset()
-			mapTarget[ref] = append(mapTarget[ref], target)
+			if value := rv.Interface(); !seen[value] {
+				seen[value] = true
+				values = append(values, value)
+			}
+		}
 
-			if table == "" {
-				table = target.Table()
-				keyField = assocs.ForeignField()
-				keyType = reflect.TypeOf(ref)
+		if len(values) == 0 {
+			continue
+		}
 
-				if doc, ok := target.(*Document); ok {
-					ddata = doc.data
-				}
+		var (
+			col   = NewCollection(reflect.New(reflect.SliceOf(assoc.targetType())).Interface())
+			query = Build(col.Table(), Select(fField), In(fField, values...))
+		)
+
+		if err := r.findAll(ctx, col, query); err != nil {
+			return err
+		}
 
-				if col, ok := target.(*Collection); ok {
-					ddata = col.data
+		for i := 0; i < col.Len(); i++ {
+			value, _ := col.Get(i).Value(fField)
+			found[indirect(reflect.ValueOf(value))] = true
+		}
+
+		for _, value := range values {
+			if !found[value] {
+				return ReferenceNotFoundError{
+					Association: field,
+					Field:       rField,
+					Value:       value,
 				}
 			}
-		} else {
-			if assocs.Type() == HasMany {
-				var (
-					col, loaded = assocs.Collection()
-				)
+		}
+	}
 
-				if !loaded {
-					continue
-				}
+	return nil
+}
 
-				stack = append(stack, make([]frame, col.Len())...)
-				for i := 0; i < col.Len(); i++ {
-					stack[n+i] = frame{
-						index: top.index + 1,
-						doc:   col.Get(i),
-					}
-				}
-			} else {
-				if doc, loaded := assocs.Document(); loaded {
-					stack = append(stack, frame{
-						index: top.index + 1,
-						doc:   doc,
-					})
-				}
+// touch updates updated at of belongs to associations that are declared with touch tag, eg: `touch:"true"`.
+func (r repository) touch(ctx context.Context, ddata documentData, sl slice) error {
+	for _, field := range ddata.touch {
+		var (
+			assoc  = sl.Get(0).Association(field)
+			target = NewDocument(reflect.New(assoc.targetType()).Interface())
+			values []interface{}
+			exists = make(map[interface{}]struct{})
+		)
+
+		if !target.Flag(HasUpdatedAt) {
+			continue
+		}
+
+		for i := 0; i < sl.Len(); i++ {
+			rValue := sl.Get(i).Association(field).ReferenceValue()
+			if _, exist := exists[rValue]; !exist && !isZero(rValue) {
+				exists[rValue] = struct{}{}
+				values = append(values, rValue)
 			}
 		}
 
+		if len(values) == 0 {
+			continue
+		}
+
+		var (
+			query    = Build(target.Table(), In(assoc.ForeignField(), values...))
+			modifies = map[string]Modify{"updated_at": Set("updated_at", now().Truncate(time.Second))}
+		)
+
+		if err := r.updateAll(ctx, query, modifies); err != nil {
+			return err
+		}
 	}
 
-	return mapTarget, table, keyField, keyType, ddata
+	return nil
 }
 
-func (r repository) withDefaultScope(ddata documentData, query Query) Query {
-	if query.UnscopedQuery {
-		return query
-	}
+// replaceHasMany removes existing records of has many association using the replacement strategy.
+// if deleted ids is nil, existing records that are no longer in the association are removed (used by structset), otherwise only the deleted ids are removed.
+func (r repository) replaceHasMany(ctx context.Context, assoc Association, col *Collection, deletedIDs []interface{}, replacement Replacement) error {
+	var (
+		table  = col.Table()
+		pField = col.PrimaryField()
+		fField = assoc.ForeignField()
+		filter = Eq(fField, assoc.ReferenceValue())
+	)
 
-	if ddata.flag.Is(HasDeletedAt) {
-		query = query.Where(Nil("deleted_at"))
+	if deletedIDs != nil {
+		if len(deletedIDs) == 0 {
+			return nil
+		}
+
+		filter = filter.AndIn(pField, deletedIDs...)
+	} else if replacement != ReplaceDelete {
+		// keep existing records that are still in the association.
+		var (
+			ids []interface{}
+		)
+
+		for i := 0; i < col.Len(); i++ {
+			if pValue := col.Get(i).PrimaryValue(); !isZero(pValue) {
+				ids = append(ids, pValue)
+			}
+		}
+
+		if len(ids) > 0 {
+			filter = filter.AndNin(pField, ids...)
+		}
 	}
 
-	return query
+	return r.removeHasMany(ctx, col.data, table, fField, filter, replacement)
 }
 
-// Transaction performs transaction with given function argument.
-func (r repository) Transaction(ctx context.Context, fn func(Repository) error) error {
-	adp, err := r.adapter.Begin(ctx)
-	if err != nil {
+// removeHasMany removes records of has many association that matches the filter using the replacement strategy.
+func (r repository) removeHasMany(ctx context.Context, ddata documentData, table string, fField string, filter FilterQuery, replacement Replacement) error {
+	switch replacement {
+	case ReplaceNullify:
+		return r.updateAll(ctx, Build(table, filter), map[string]Modify{fField: Set(fField, nil)})
+	case ReplaceRestrict:
+		count, err := r.Aggregate(ctx, r.withDefaultScope(ddata, Build(table, filter)), "count", "*")
+		if err != nil {
+			return err
+		}
+
+		if count > 0 {
+			return ConstraintError{
+				Key:  fField,
+				Type: ForeignKeyConstraint,
+				Err:  errors.New("rel: has many association is restricted"),
+			}
+		}
+
+		return nil
+	default:
+		_, err := r.deleteAll(ctx, ddata.flag, Build(table, filter))
 		return err
 	}
+}
 
-	txRepo := &repository{
-		adapter:       adp,
-		logger:        []Logger{DefaultLogger},
+func (r repository) updateAll(ctx context.Context, query Query, modifies map[string]Modify) error {
+	ctx = r.instrument(ctx)
+	ctx, loggers := r.loggers(ctx, r.logLevels.Write, query, modifies)
+
+	finish := r.observe(ctx, "update", query)
+	_, err := r.adapter.Update(ctx, query, modifies, loggers...)
+	finish(err)
+
+	return err
+}
+
+func (r repository) deleteAll(ctx context.Context, flag DocumentFlag, query Query) (int, error) {
+	var (
+		deletedCount int
+		err          error
+	)
+
+	ctx = r.instrument(ctx)
+	ctx, loggers := r.loggers(ctx, r.logLevels.Write, query)
+
+	if flag.Is(HasDeletedAt) {
+		modifies := map[string]Modify{"deleted_at": Set("deleted_at", nil)}
+		finish := r.observe(ctx, "update", query)
+		deletedCount, err = r.adapter.Update(ctx, query, modifies, loggers...)
+		finish(err)
+	} else {
+		finish := r.observe(ctx, "delete", query)
+		deletedCount, err = r.adapter.Delete(ctx, query, loggers...)
+		finish(err)
+	}
+
+	return deletedCount, err
+}
+
+// Preload loads association with given query.
+// The query can be used to filter, sort and select fields of the association, foreign key is always selected.
+// Nested association can be loaded using dotted path such as "transactions.items.product",
+// each level that is not loaded yet is loaded using one query, and the query is only applied to the last level.
+func (r repository) Preload(ctx context.Context, records interface{}, field string, queriers ...Querier) error {
+	return r.preloadPath(ctx, preloadSlice(records), strings.Split(field, "."), queriers...)
+}
+
+// PreloadAll loads associations of the fields, each field is loaded like Preload without query.
+// Associations are loaded concurrently outside of transaction, except fields with the same first association such as "buyer.address" and "buyer.company",
+// which are loaded in order. Number of concurrent queries can be limited using SetPreloadParallelism.
+func (r repository) PreloadAll(ctx context.Context, records interface{}, fields ...string) error {
+	var (
+		preloads = make([]PreloadQuery, len(fields))
+	)
+
+	for i := range fields {
+		preloads[i] = PreloadQuery{Field: fields[i]}
+	}
+
+	return r.preloadQueries(ctx, preloadSlice(records), preloads)
+}
+
+// MustPreloadAll loads associations of the fields.
+// It'll panic if any error occurred.
+func (r repository) MustPreloadAll(ctx context.Context, records interface{}, fields ...string) {
+	must(r.PreloadAll(ctx, records, fields...))
+}
+
+// PreloadMap loads association like Preload, and returns the loaded association keyed by primary value of the records.
+// This is useful to build response that groups association by its parent without grouping it again.
+// The value is the association field of the record, eg: []Transaction for has many association, nested path is not supported.
+//
+// Example:
+//	result, err := repo.PreloadMap(ctx, &users, "transactions")
+//	transactions := result[users[0].ID].([]Transaction)
+func (r repository) PreloadMap(ctx context.Context, records interface{}, field string, queriers ...Querier) (map[interface{}]interface{}, error) {
+	if strings.Contains(field, ".") {
+		panic("rel: preload map doesn't support nested association (" + field + ")")
+	}
+
+	var (
+		sl = preloadSlice(records)
+	)
+
+	if err := r.preloadPath(ctx, sl, []string{field}, queriers...); err != nil {
+		return nil, err
+	}
+
+	result := make(map[interface{}]interface{}, sl.Len())
+	for i := 0; i < sl.Len(); i++ {
+		var (
+			doc      = sl.Get(i)
+			value, _ = doc.Value(field)
+		)
+
+		result[doc.PrimaryValue()] = value
+	}
+
+	return result, nil
+}
+
+// MustPreloadMap loads association and returns it keyed by primary value of the records.
+// It'll panic if any error occurred.
+func (r repository) MustPreloadMap(ctx context.Context, records interface{}, field string, queriers ...Querier) map[interface{}]interface{} {
+	result, err := r.PreloadMap(ctx, records, field, queriers...)
+	must(err)
+	return result
+}
+
+// Association returns proxy to manage records of has many or many to many association of the record, see AssociationProxy.
+// It'll panic if the field is not a has many or many to many association, or the record doesn't have reference value.
+func (r repository) Association(record interface{}, field string) AssociationProxy {
+	return newAssociationProxy(r, record, field)
+}
+
+func preloadSlice(records interface{}) slice {
+	var (
+		rt = reflect.TypeOf(records)
+	)
+
+	if rt.Kind() != reflect.Ptr {
+		panic("rel: record parameter must be a pointer.")
+	}
+
+	if rt.Elem().Kind() == reflect.Slice {
+		return NewCollection(records)
+	}
+
+	return NewDocument(records)
+}
+
+// preloadPath loads every level of the path that is not loaded yet, and loads the last level using the queriers.
+func (r repository) preloadPath(ctx context.Context, sl slice, path []string, queriers ...Querier) error {
+	for i := 1; i < len(path); i++ {
+		if err := r.preload(ctx, sl, path[:i], true); err != nil {
+			return err
+		}
+	}
+
+	return r.preload(ctx, sl, path, false, queriers...)
+}
+
+// preloadQueries loads associations declared by preload query of the records.
+// Preloads are grouped by its first association, each group is loaded concurrently bounded by the preload parallelism,
+// while preloads of the same group such as "buyer" and "buyer.address" are loaded in order.
+func (r repository) preloadQueries(ctx context.Context, sl slice, preloads []PreloadQuery) error {
+	if sl.Len() == 0 {
+		return nil
+	}
+
+	var (
+		roots  []string
+		groups = make(map[string][]PreloadQuery)
+	)
+
+	for _, preload := range preloads {
+		root := strings.SplitN(preload.Field, ".", 2)[0]
+		if _, exist := groups[root]; !exist {
+			roots = append(roots, root)
+		}
+
+		groups[root] = append(groups[root], preload)
+	}
+
+	return r.concurrently(len(roots), func(i int) error {
+		for _, preload := range groups[roots[i]] {
+			if err := r.preloadPath(ctx, sl, strings.Split(preload.Field, "."), preload.Queriers...); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// concurrently calls fn for n times concurrently bounded by the preload parallelism, and returns the first error by its order.
+// Inside transaction fn is called in order, because transaction uses single connection that can't execute queries concurrently.
+func (r repository) concurrently(n int, fn func(i int) error) error {
+	if r.inTransaction || r.preloadParallelism == 1 || n == 1 {
+		for i := 0; i < n; i++ {
+			if err := fn(i); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	var (
+		wg   sync.WaitGroup
+		sem  chan struct{}
+		errs = make([]error, n)
+	)
+
+	if r.preloadParallelism > 0 {
+		sem = make(chan struct{}, r.preloadParallelism)
+	}
+
+	for i := 0; i < n; i++ {
+		if sem != nil {
+			sem <- struct{}{}
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			errs[i] = fn(i)
+
+			if sem != nil {
+				<-sem
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// preload association at the path using one query, when unloaded is true, association that is already loaded is skipped.
+// Many to many and has many through association is loaded using additional query to the join table.
+func (r repository) preload(ctx context.Context, sl slice, path []string, unloaded bool, queriers ...Querier) error {
+	var (
+		field   = path[len(path)-1]
+		parents = preloadParents(sl, path)
+	)
+
+	if len(parents) == 0 {
+		return nil
+	}
+
+	if hasField(parents[0].data.polymorphic, field) {
+		return r.preloadPolymorphic(ctx, parents, field, unloaded, queriers...)
+	}
+
+	var (
+		targets, ids, table, keyField, keyType, ddata, assoc = r.mapPreloadTargets(parents, field, unloaded)
+	)
+
+	if len(targets) == 0 {
+		return nil
+	}
+
+	if assoc.Type() == ManyToMany {
+		var err error
+		if targets, ids, err = r.preloadThrough(ctx, assoc, keyType, targets, ids); err != nil || len(ids) == 0 {
+			return err
+		}
+
+		keyType = assoc.foreignType()
+	}
+
+	var (
+		query = r.withDefaultScope(ddata, Build(table, append(queriers, In(keyField, ids...))...))
+	)
+
+	// key field is required to assign the result to its parent.
+	if fields := query.SelectQuery.Fields; len(fields) > 0 && !hasField(fields, keyField) && !hasField(fields, "*") {
+		query.SelectQuery.Fields = append(fields[:len(fields):len(fields)], keyField)
+	}
+
+	// records of each parent is limited using window function when supported, otherwise it's limited while scanning.
+	limit := query.PartitionQuery.Limit
+	if limit > 0 {
+		if assoc.Type() == HasMany && r.adapter.Capabilities().Is(WindowCapability) {
+			query.PartitionQuery.Field = keyField
+		} else {
+			query.PartitionQuery = Partition{}
+		}
+
+		if assoc.Type() != HasMany && assoc.Type() != ManyToMany {
+			limit = 0
+		}
+	}
+
+	cur, err := r.preloadQuery(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	return scanMulti(cur, keyField, keyType, targets, limit)
+}
+
+// preloadPolymorphic loads polymorphic association of the parents using one query for each type stored in the type field,
+// the loaded record is assigned to the parent that has matching type and id.
+func (r repository) preloadPolymorphic(ctx context.Context, parents []*Document, field string, unloaded bool, queriers ...Querier) error {
+	type group struct {
+		rt      reflect.Type
+		ids     []interface{}
+		targets map[interface{}][]reflect.Value
+	}
+
+	var (
+		index  = parents[0].data.index[field]
+		data   = extractPolymorphicData(parents[0].rt, index)
+		tables []string
+		groups = make(map[string]*group)
+	)
+
+	for _, parent := range parents {
+		var (
+			typ, _   = parent.Value(data.typeField)
+			id, _    = parent.Value(data.idField)
+			table, _ = typ.(string)
+			fv       = parent.rv.Field(index)
+		)
+
+		if unloaded && !isDeepZero(fv, 1) {
+			continue
+		}
+
+		fv.Set(reflect.Zero(fv.Type()))
+
+		if table == "" || isZero(id) {
+			continue
+		}
+
+		g, ok := groups[table]
+		if !ok {
+			g = &group{rt: data.rt, targets: make(map[interface{}][]reflect.Value)}
+			if g.rt == nil {
+				g.rt = polymorphicType(table)
+			} else if NewDocument(reflect.New(g.rt).Interface()).Table() != table {
+				continue
+			}
+
+			groups[table] = g
+			tables = append(tables, table)
+		}
+
+		if _, exist := g.targets[id]; !exist {
+			g.ids = append(g.ids, id)
+		}
+
+		g.targets[id] = append(g.targets[id], fv)
+	}
+
+	for _, table := range tables {
+		var (
+			g     = groups[table]
+			col   = NewCollection(reflect.New(reflect.SliceOf(g.rt)).Interface())
+			pk    = col.PrimaryField()
+			query = r.withDefaultScope(col.data, Build(table, append(queriers, In(pk, g.ids...))...))
+		)
+
+		// primary field is required to assign the result to its parent.
+		if fields := query.SelectQuery.Fields; len(fields) > 0 && !hasField(fields, pk) && !hasField(fields, "*") {
+			query.SelectQuery.Fields = append(fields[:len(fields):len(fields)], pk)
+		}
+
+		cur, err := r.preloadQuery(ctx, query)
+		if err != nil {
+			return err
+		}
+
+		if err := scanMany(cur, col); err != nil {
+			return err
+		}
+
+		for i := 0; i < col.Len(); i++ {
+			var (
+				doc = col.Get(i)
+			)
+
+			for _, fv := range g.targets[doc.PrimaryValue()] {
+				if fv.Kind() == reflect.Struct {
+					fv.Set(doc.rv)
+				} else {
+					rv := reflect.New(g.rt)
+					rv.Elem().Set(doc.rv)
+					fv.Set(rv)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// preloadThrough queries the join table of many to many association using the reference ids,
+// and returns the targets mapped by the foreign ids along with the foreign ids.
+func (r repository) preloadThrough(ctx context.Context, assoc Association, keyType reflect.Type, targets map[interface{}][]slice, ids []interface{}) (map[interface{}][]slice, []interface{}, error) {
+	var (
+		refField = assoc.ThroughReferenceField()
+		fkField  = assoc.ThroughForeignField()
+		query    = r.withDefaultScope(documentData{flag: assoc.data.throughFlag}, Build(assoc.Through(), Select(refField, fkField), In(refField, ids...)))
+	)
+
+	cur, err := r.preloadQuery(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer cur.Close()
+
+	fields, err := cur.Fields()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		fkIDs     []interface{}
+		mapTarget = make(map[interface{}][]slice)
+		refValue  = reflect.New(keyType)
+		fkValue   = reflect.New(assoc.foreignType())
+		scanners  = make([]interface{}, len(fields))
+	)
+
+	for i, field := range fields {
+		switch field {
+		case refField:
+			scanners[i] = refValue.Interface()
+		case fkField:
+			scanners[i] = fkValue.Interface()
+		default:
+			scanners[i] = &sql.RawBytes{}
+		}
+	}
+
+	for cur.Next() {
+		if err := cur.Scan(scanners...); err != nil {
+			return nil, nil, err
+		}
+
+		var (
+			ref = refValue.Elem().Interface()
+			fk  = fkValue.Elem().Interface()
+		)
+
+		if _, exist := mapTarget[fk]; !exist {
+			fkIDs = append(fkIDs, fk)
+		}
+
+		mapTarget[fk] = append(mapTarget[fk], targets[ref]...)
+	}
+
+	return mapTarget, fkIDs, nil
+}
+
+// preloadQuery executes query of preload operation.
+func (r repository) preloadQuery(ctx context.Context, query Query) (Cursor, error) {
+	var (
+		loggers []Logger
+	)
+
+	ctx = r.instrument(ctx)
+	ctx, loggers = r.loggers(ctx, r.logLevels.Read, query)
+
+	return r.query(ctx, "preload", query, loggers, true)
+}
+
+// query executes the query as the operation, it's executed in chunks when its in filter has more values than in chunk size.
+// Only the first chunk is executed immediately, the rest are executed when the returned cursor reaches them.
+// Sorted allows query with sort to be chunked, when the caller doesn't depend on the order across chunks.
+func (r repository) query(ctx context.Context, op string, query Query, loggers []Logger, sorted bool) (Cursor, error) {
+	var (
+		queries = chunkQuery(query, r.inChunkSize, sorted)
+		exec    = func(query Query) (Cursor, error) {
+			finish := r.observe(ctx, op, query)
+			cur, err := r.adapter.Query(ctx, query, loggers...)
+			finish(err)
+
+			return cur, err
+		}
+	)
+
+	cur, err := exec(queries[0])
+	if err != nil || len(queries) == 1 {
+		return cur, err
+	}
+
+	return &chunkCursor{
+		Cursor:  cur,
+		queries: queries[1:],
+		query: func(query Query) (Cursor, error) {
+			r.instrument(ctx)
+			return exec(query)
+		},
+	}, nil
+}
+
+func hasField(fields []string, field string) bool {
+	for i := range fields {
+		if fields[i] == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MustPreload loads association with given query.
+// It'll panic if any error occurred.
+func (r repository) MustPreload(ctx context.Context, records interface{}, field string, queriers ...Querier) {
+	must(r.Preload(ctx, records, field, queriers...))
+}
+
+// preloadParents returns documents that own the last field of the path by walking through the loaded associations.
+func preloadParents(sl slice, path []string) []*Document {
+	var (
+		parents = make([]*Document, sl.Len())
+	)
+
+	for i := range parents {
+		parents[i] = sl.Get(i)
+	}
+
+	for _, field := range path[:len(path)-1] {
+		var (
+			next []*Document
+		)
+
+		for _, parent := range parents {
+			if hasField(parent.data.polymorphic, field) {
+				panic("rel: polymorphic association (" + field + ") can only be preloaded as the last field of the path")
+			}
+
+			var (
+				assocs = parent.Association(field)
+			)
+
+			if assocs.Type() == HasMany || assocs.Type() == ManyToMany {
+				if col, loaded := assocs.Collection(); loaded {
+					for i := 0; i < col.Len(); i++ {
+						next = append(next, col.Get(i))
+					}
+				}
+			} else {
+				if doc, loaded := assocs.Document(); loaded {
+					next = append(next, doc)
+				}
+			}
+		}
+
+		parents = next
+	}
+
+	return parents
+}
+
+// mapPreloadTargets maps association of the parents by its reference value, and returns the distinct reference values in order.
+// Parent that appears multiple times is only mapped once, and parent with zero reference value is skipped.
+func (r repository) mapPreloadTargets(parents []*Document, field string, unloaded bool) (map[interface{}][]slice, []interface{}, string, string, reflect.Type, documentData, Association) {
+	var (
+		table     string
+		keyField  string
+		keyType   reflect.Type
+		ddata     documentData
+		assoc     Association
+		ids       []interface{}
+		mapTarget = make(map[interface{}][]slice)
+		seen      = make(map[uintptr]struct{})
+	)
+
+	for _, parent := range parents {
+		var (
+			target slice
+			loaded bool
+			assocs = parent.Association(field)
+			ref    = assocs.ReferenceValue()
+		)
+
+		if isZero(ref) {
+			continue
+		}
+
+		if parent.rv.CanAddr() {
+			addr := parent.rv.UnsafeAddr()
+			if _, ok := seen[addr]; ok {
+				continue
+			}
+
+			seen[addr] = struct{}{}
+		}
+
+		if assocs.Type() == HasMany || assocs.Type() == ManyToMany {
+			target, loaded = assocs.Collection()
+		} else {
+			target, loaded = assocs.Document()
+		}
+
+		if unloaded && loaded {
+			continue
+		}
+
+		target.Reset()
+
+		if _, exist := mapTarget[ref]; !exist {
+			ids = append(ids, ref)
+		}
+
+		mapTarget[ref] = append(mapTarget[ref], target)
+
+		if table == "" {
+			table = target.Table()
+			keyField = assocs.ForeignField()
+			keyType = reflect.TypeOf(ref)
+			assoc = assocs
+
+			if doc, ok := target.(*Document); ok {
+				ddata = doc.data
+			}
+
+			if col, ok := target.(*Collection); ok {
+				ddata = col.data
+			}
+		}
+	}
+
+	return mapTarget, ids, table, keyField, keyType, ddata, assoc
+}
+
+func (r repository) withDefaultScope(ddata documentData, query Query) Query {
+	if query.UnscopedQuery {
+		return query
+	}
+
+	if ddata.flag.Is(HasDeletedAt) {
+		// field is prefixed with table name, since joined association may have the same field.
+		if len(query.EagerQuery) > 0 {
+			query = query.Where(Nil(query.Table + ".deleted_at"))
+		} else {
+			query = query.Where(Nil("deleted_at"))
+		}
+	}
+
+	return query
+}
+
+// Set attaches value to current transaction, it can be retrieved using Get or TransactionValue from the context passed to adapter.
+// It'll panic when called outside of transaction.
+func (r repository) Set(key string, value interface{}) {
+	if r.metadata == nil {
+		panic("rel: Set can only be called inside transaction")
+	}
+
+	r.metadata.set(key, value)
+}
+
+// Get value attached to current transaction.
+func (r repository) Get(key string) interface{} {
+	return r.metadata.get(key)
+}
+
+// Transaction performs transaction with given function argument.
+// Options such as isolation level are passed to adapter and can be retrieved using TransactionOptionsFrom.
+func (r repository) Transaction(ctx context.Context, fn func(Repository) error, opts ...TransactionOption) error {
+	options := applyTransactionOptions(opts)
+
+	switch {
+	case options.Propagation == PropagationNotSupported && !r.inTransaction:
+		return fn(&r)
+	case options.Propagation == PropagationNotSupported:
+		return fn(r.independent())
+	case options.Propagation == PropagationRequiresNew && r.inTransaction:
+		return r.independent().Transaction(ctx, fn, opts...)
+	case options.Propagation == PropagationRequired && r.inTransaction:
+		r.readOnly = r.readOnly || options.ReadOnly
+		return fn(&r)
+	}
+
+	if err := r.require(TransactionCapability); err != nil {
+		return err
+	}
+
+	if r.inTransaction {
+		if err := r.require(SavepointCapability); err != nil {
+			return err
+		}
+	}
+
+	if options.TwoPhaseCommitID != "" {
+		if r.inTransaction {
+			return errors.New("rel: two-phase commit can't be used inside transaction")
+		}
+
+		if err := r.require(TwoPhaseCommitCapability); err != nil {
+			return err
+		}
+	}
+
+	// nested transaction can't be retried on its own, the error is returned so outer transaction can retry instead.
+	if r.inTransaction || options.MaxRetries == 0 {
+		return r.transaction(ctx, fn, options)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := r.transaction(ctx, fn, options)
+
+		var serializationErr SerializationError
+		if attempt >= options.MaxRetries || !errors.As(err, &serializationErr) {
+			return err
+		}
+
+		backoff := options.Backoff(attempt)
+		r.log(r.logLevels.Transaction, "RETRY TRANSACTION "+strconv.Itoa(attempt+1), backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+}
+
+func (r repository) transaction(ctx context.Context, fn func(Repository) error, options TransactionOptions) error {
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+
+	var (
+		start      = time.Now()
+		statements = r.statements
+		md         = r.metadata
+	)
+
+	if md == nil {
+		md = &metadata{}
+	}
+
+	ctx = context.WithValue(ctx, metadataKey{}, md)
+
+	if statements == nil && (options.SlowThreshold > 0 || options.MaxStatements > 0) {
+		statements = new(int32)
+	}
+
+	finish := r.observe(ctx, "begin", Query{})
+	adp, err := r.adapter.Begin(withTransactionOptions(ctx, options))
+	finish(err)
+
+	if err != nil {
+		return err
+	}
+
+	txRepo := &repository{
+		adapter:       adp,
+		logger:        r.logger,
+		quietLogger:   r.quietLogger,
+		logLevels:     r.logLevels,
+		instrumenters: r.instrumenters,
 		inTransaction: true,
+		readOnly:      r.readOnly || options.ReadOnly,
+		root:          r.rootAdapter(),
+		statements:    statements,
+		metadata:      md,
+
+		preloadParallelism: r.preloadParallelism,
+		inChunkSize:        r.inChunkSize,
+	}
+
+	if statements != nil {
+		defer r.warnSlow(options, start, *statements, statements, &err)
 	}
 
 	func() {
 		defer func() {
 			if p := recover(); p != nil {
-				_ = txRepo.adapter.Rollback(ctx)
+				_ = txRepo.rollback(ctx)
 
 				switch e := p.(type) {
 				case runtime.Error:
@@ -794,9 +1867,11 @@ func (r repository) Transaction(ctx context.Context, fn func(Repository) error)
 					panic(e)
 				}
 			} else if err != nil {
-				_ = txRepo.adapter.Rollback(ctx)
+				_ = txRepo.rollback(ctx)
+			} else if options.TwoPhaseCommitID != "" {
+				err = r.twoPhaseCommit(ctx, txRepo.adapter, options)
 			} else {
-				err = txRepo.adapter.Commit(ctx)
+				err = txRepo.commit(ctx)
 			}
 		}()
 
@@ -806,10 +1881,136 @@ func (r repository) Transaction(ctx context.Context, fn func(Repository) error)
 	return err
 }
 
+// instrument counts statement executed inside transaction, and attaches transaction metadata to the context.
+func (r repository) instrument(ctx context.Context) context.Context {
+	if r.statements != nil {
+		atomic.AddInt32(r.statements, 1)
+	}
+
+	if r.metadata != nil {
+		ctx = context.WithValue(ctx, metadataKey{}, r.metadata)
+	}
+
+	return ctx
+}
+
+// warnSlow logs a warning when transaction takes longer than threshold or executes too many statements.
+func (r repository) warnSlow(options TransactionOptions, start time.Time, offset int32, statements *int32, err *error) {
+	var (
+		duration = time.Since(start)
+		count    = int(atomic.LoadInt32(statements) - offset)
+	)
+
+	if (options.SlowThreshold > 0 && duration > options.SlowThreshold) || (options.MaxStatements > 0 && count > options.MaxStatements) {
+		r.log(r.logLevels.Transaction, "SLOW TRANSACTION: "+strconv.Itoa(count)+" statements", duration, *err)
+	}
+}
+
+func (r repository) commit(ctx context.Context) error {
+	finish := r.observe(ctx, "commit", Query{})
+	err := r.adapter.Commit(ctx)
+	finish(err)
+
+	return err
+}
+
+func (r repository) rollback(ctx context.Context) error {
+	finish := r.observe(ctx, "rollback", Query{})
+	err := r.adapter.Rollback(ctx)
+	finish(err)
+
+	return err
+}
+
+// rootAdapter returns adapter that is used outside of any transaction.
+func (r repository) rootAdapter() Adapter {
+	if r.root != nil {
+		return r.root
+	}
+
+	return r.adapter
+}
+
+// independent returns repository that is not bound to current transaction.
+func (r repository) independent() *repository {
+	return &repository{
+		adapter:       r.rootAdapter(),
+		logger:        r.logger,
+		quietLogger:   r.quietLogger,
+		logLevels:     r.logLevels,
+		instrumenters: r.instrumenters,
+
+		preloadParallelism: r.preloadParallelism,
+		inChunkSize:        r.inChunkSize,
+	}
+}
+
+// twoPhaseCommit prepares the transaction, then commits or rolls back the prepared transaction depending on the result of prepared function.
+func (r repository) twoPhaseCommit(ctx context.Context, txAdapter Adapter, options TransactionOptions) error {
+	var (
+		tpc, ok     = r.adapter.(TwoPhaseCommitAdapter)
+		txTpc, txOk = txAdapter.(TwoPhaseCommitAdapter)
+	)
+
+	if !ok || !txOk {
+		_ = txAdapter.Rollback(ctx)
+		return NotSupportedError{Capability: TwoPhaseCommitCapability}
+	}
+
+	if err := txTpc.Prepare(ctx, options.TwoPhaseCommitID); err != nil {
+		_ = txAdapter.Rollback(ctx)
+		return err
+	}
+
+	if options.Prepared != nil {
+		if err := options.Prepared(ctx); err != nil {
+			_ = tpc.RollbackPrepared(ctx, options.TwoPhaseCommitID)
+			return err
+		}
+	}
+
+	return tpc.CommitPrepared(ctx, options.TwoPhaseCommitID)
+}
+
+// validate query against adapter capabilities, so unsupported query fails early instead of being executed.
+func (r repository) validate(query Query) error {
+	if len(query.JoinQuery) > 0 {
+		if err := r.require(JoinCapability); err != nil {
+			return err
+		}
+	}
+
+	if len(query.GroupQuery.Fields) > 0 {
+		if err := r.require(GroupCapability); err != nil {
+			return err
+		}
+	}
+
+	if query.PartitionQuery.Limit > 0 {
+		if err := r.require(WindowCapability); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r repository) require(capability Capabilities) error {
+	if !r.adapter.Capabilities().Is(capability) {
+		return NotSupportedError{Capability: capability}
+	}
+
+	return nil
+}
+
 // New create new repo using adapter.
 func New(adapter Adapter) Repository {
-	return &repository{
-		adapter: adapter,
-		logger:  []Logger{DefaultLogger},
+	repo := &repository{
+		adapter:   adapter,
+		logLevels: DefaultLogLevels,
 	}
+
+	repo.SetLogger(DefaultLogger)
+
+	return repo
 }
@@ -2,7 +2,9 @@ package rel
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -67,6 +69,21 @@ func TestRepository_Ping(t *testing.T) {
 	adapter.AssertExpectations(t)
 }
 
+type statsAdapter struct {
+	testAdapter
+}
+
+func (sa *statsAdapter) Stats() sql.DBStats {
+	return sql.DBStats{OpenConnections: 2}
+}
+
+func TestRepository_Stats(t *testing.T) {
+	assert.Equal(t, sql.DBStats{}, New(&testAdapter{}).Stats())
+	assert.Equal(t, sql.DBStats{OpenConnections: 2}, New(&statsAdapter{}).Stats())
+	assert.Equal(t, sql.DBStats{OpenConnections: 2}, New(WrapAdapter(&statsAdapter{})).Stats())
+	assert.Equal(t, sql.DBStats{}, New(WrapAdapter(&testAdapter{})).Stats())
+}
+
 func TestRepository_Aggregate(t *testing.T) {
 	var (
 		adapter   = &testAdapter{}
@@ -85,6 +102,22 @@ func TestRepository_Aggregate(t *testing.T) {
 	adapter.AssertExpectations(t)
 }
 
+func TestRepository_Aggregate_notSupported(t *testing.T) {
+	var (
+		adapter = &testAdapter{unsupported: JoinCapability}
+		repo    = repository{adapter: adapter}
+	)
+
+	count, err := repo.Aggregate(context.TODO(), From("users").Join("addresses"), "count", "*")
+	assert.Equal(t, NotSupportedError{Capability: JoinCapability}, err)
+	assert.Equal(t, 0, count)
+
+	_, err = repo.Count(context.TODO(), "users", Join("addresses"))
+	assert.Equal(t, NotSupportedError{Capability: JoinCapability}, err)
+
+	adapter.AssertExpectations(t)
+}
+
 func TestRepository_MustAggregate(t *testing.T) {
 	var (
 		adapter   = &testAdapter{}
@@ -271,1705 +304,3947 @@ func TestRepository_FindAll(t *testing.T) {
 	cur.AssertExpectations(t)
 }
 
-func TestRepository_FindAll_softDelete(t *testing.T) {
+func TestRepository_FindAll_chunked(t *testing.T) {
 	var (
-		addresses []Address
-		adapter   = &testAdapter{}
-		repo      = repository{adapter: adapter}
-		query     = From("addresses").Limit(1)
-		cur       = createCursor(2)
+		users   []User
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter, inChunkSize: 2}
+		cur1    = &testCursor{}
+		cur2    = &testCursor{}
 	)
 
-	adapter.On("Query", query.Where(Nil("deleted_at"))).Return(cur, nil).Once()
+	adapter.On("Query", From("users").Where(In("id", 1, 2))).Return(cur1, nil).Once()
+	adapter.On("Query", From("users").Where(In("id", 3))).Return(cur2, nil).Once()
 
-	assert.Nil(t, repo.FindAll(context.TODO(), &addresses, query))
-	assert.Len(t, addresses, 2)
-	assert.Equal(t, 10, addresses[0].ID)
-	assert.Equal(t, 10, addresses[1].ID)
+	cur1.On("Fields").Return([]string{"id"}, nil).Once()
+	cur1.On("Next").Return(true).Twice()
+	cur1.MockScan(1).Once()
+	cur1.MockScan(2).Once()
+	cur1.On("Next").Return(false).Once()
+	cur1.On("Close").Return(nil).Once()
+
+	cur2.On("Next").Return(true).Once()
+	cur2.MockScan(3).Once()
+	cur2.On("Next").Return(false).Once()
+	cur2.On("Close").Return(nil).Once()
+
+	assert.Nil(t, repo.FindAll(context.TODO(), &users, Where(In("id", 1, 2, 3))))
+	assert.Equal(t, []User{{ID: 1}, {ID: 2}, {ID: 3}}, users)
 
 	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
+	cur1.AssertExpectations(t)
+	cur2.AssertExpectations(t)
 }
 
-func TestRepository_FindAll_softDeleteUnscoped(t *testing.T) {
+func TestRepository_FindAll_chunkedError(t *testing.T) {
 	var (
-		addresses []Address
-		adapter   = &testAdapter{}
-		repo      = repository{adapter: adapter}
-		query     = From("addresses").Limit(1).Unscoped()
-		cur       = createCursor(2)
+		users   []User
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter, inChunkSize: 2}
+		cur     = createCursor(2)
+		err     = errors.New("error")
 	)
 
-	adapter.On("Query", query).Return(cur, nil).Once()
+	adapter.On("Query", From("users").Where(In("id", 1, 2))).Return(cur, nil).Once()
+	adapter.On("Query", From("users").Where(In("id", 3))).Return(&testCursor{}, err).Once()
 
-	assert.Nil(t, repo.FindAll(context.TODO(), &addresses, query))
-	assert.Len(t, addresses, 2)
-	assert.Equal(t, 10, addresses[0].ID)
-	assert.Equal(t, 10, addresses[1].ID)
+	assert.Equal(t, err, repo.FindAll(context.TODO(), &users, Where(In("id", 1, 2, 3))))
 
 	adapter.AssertExpectations(t)
 	cur.AssertExpectations(t)
 }
 
-func TestRepository_FindAll_error(t *testing.T) {
+func TestRepository_FindAll_notSupported(t *testing.T) {
 	var (
+		user    User
 		users   []User
-		adapter = &testAdapter{}
+		adapter = &testAdapter{unsupported: JoinCapability | GroupCapability | WindowCapability}
 		repo    = repository{adapter: adapter}
-		query   = From("users").Limit(1)
-		err     = errors.New("error")
 	)
 
-	adapter.On("Query", query).Return(&testCursor{}, err).Once()
+	assert.Equal(t, NotSupportedError{Capability: JoinCapability}, repo.FindAll(context.TODO(), &users, Join("addresses")))
+	assert.Equal(t, NotSupportedError{Capability: GroupCapability}, repo.Find(context.TODO(), &user, Select("gender").Group("gender")))
+	assert.Equal(t, NotSupportedError{Capability: WindowCapability}, repo.FindAll(context.TODO(), &users, Partition{Field: "gender", Limit: 1}))
 
-	assert.Equal(t, err, repo.FindAll(context.TODO(), &users, query))
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_FindAll_eager(t *testing.T) {
+	var (
+		transactions []Transaction
+		adapter      = &testAdapter{}
+		repo         = repository{adapter: adapter}
+		query        = From("transactions").Eager("buyer").Select("buyer.id", "buyer.name", "buyer.age", "buyer.created_at", "buyer.updated_at", "transactions.*")
+		cur          = &testCursor{}
+	)
+
+	query.JoinQuery = []JoinQuery{
+		{Mode: "LEFT JOIN", Table: "users", Alias: "buyer", From: "transactions.user_id", To: "buyer.id"},
+	}
+
+	adapter.On("Query", query).Return(cur, nil).Once()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "name", "age", "created_at", "updated_at", "id", "item", "user_id"}, nil).Once()
+	cur.On("Next").Return(true).Twice()
+	cur.MockScan(10, "Del Piero", 20, nil, nil, 1, "soap", 10).Once()
+	cur.MockScan(nil, nil, nil, nil, nil, 2, "shampoo", 0).Once()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.FindAll(context.TODO(), &transactions, Eager("buyer")))
+	assert.Equal(t, []Transaction{
+		{ID: 1, Item: "soap", BuyerID: 10, Buyer: User{ID: 10, Name: "Del Piero", Age: 20}},
+		{ID: 2, Item: "shampoo"},
+	}, transactions)
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_MustFindAll(t *testing.T) {
+func TestRepository_FindAll_eagerDuplicate(t *testing.T) {
 	var (
 		users   []User
 		adapter = &testAdapter{}
 		repo    = repository{adapter: adapter}
-		query   = From("users").Limit(1)
-		cur     = createCursor(2)
+		query   = From("users").Eager("address").Select("address.id", "address.user_id", "address.street", "address.deleted_at", "users.id", "users.name")
+		cur     = &testCursor{}
+		now     = time.Now()
 	)
 
+	query.JoinQuery = []JoinQuery{
+		{Mode: "LEFT JOIN", Table: "addresses", Alias: "address", From: "users.id", To: "address.user_id"},
+	}
+
 	adapter.On("Query", query).Return(cur, nil).Once()
 
-	assert.NotPanics(t, func() {
-		repo.MustFindAll(context.TODO(), &users, query)
-	})
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id", "street", "deleted_at", "id", "name"}, nil).Once()
+	cur.On("Next").Return(true).Times(3)
+	cur.MockScan(1, 10, "Grove Street", nil, 10, "Del Piero").Once()
+	cur.MockScan(2, 10, "Baker Street", nil, 10, "Del Piero").Once()
+	cur.MockScan(3, 20, "Abbey Road", &now, 20, "Nedved").Once()
+	cur.On("Next").Return(false).Once()
 
+	assert.Nil(t, repo.FindAll(context.TODO(), &users, Eager("address"), Select("id", "name")))
 	assert.Len(t, users, 2)
 	assert.Equal(t, 10, users[0].ID)
-	assert.Equal(t, 10, users[1].ID)
+	assert.Equal(t, 1, users[0].Address.ID)
+	assert.Equal(t, "Grove Street", users[0].Address.Street)
+	assert.Equal(t, 20, users[1].ID)
+	assert.Equal(t, Address{}, users[1].Address)
 
 	adapter.AssertExpectations(t)
 	cur.AssertExpectations(t)
 }
 
-func TestRepository_Insert(t *testing.T) {
+func TestRepository_FindAll_eagerWithoutJoin(t *testing.T) {
 	var (
-		user      User
-		adapter   = &testAdapter{}
-		repo      = repository{adapter: adapter}
-		modifiers = []Modifier{
-			Set("name", "name"),
-			Set("created_at", now()),
-			Set("updated_at", now()),
-		}
-		modifies = map[string]Modify{
-			"name":       Set("name", "name"),
-			"created_at": Set("created_at", now()),
-			"updated_at": Set("updated_at", now()),
-		}
+		transactions []Transaction
+		adapter      = &testAdapter{unsupported: JoinCapability}
+		repo         = repository{adapter: adapter}
+		cur          = &testCursor{}
+		buyerCur     = &testCursor{}
 	)
 
-	adapter.On("Insert", From("users"), modifies).Return(1, nil).Once()
+	adapter.On("Query", From("transactions").Preload("buyer")).Return(cur, nil).Once()
+	adapter.On("Query", From("users").Where(In("id", 10))).Return(buyerCur, nil).Once()
 
-	assert.Nil(t, repo.Insert(context.TODO(), &user, modifiers...))
-	assert.Equal(t, User{
-		ID:        1,
-		Name:      "name",
-		CreatedAt: now(),
-		UpdatedAt: now(),
-	}, user)
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(1, 10).Once()
+	cur.On("Next").Return(false).Once()
+
+	buyerCur.On("Close").Return(nil).Once()
+	buyerCur.On("Fields").Return([]string{"id", "name"}, nil).Once()
+	buyerCur.On("Next").Return(true).Once()
+	buyerCur.MockScan(10, "Del Piero").Twice()
+	buyerCur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.FindAll(context.TODO(), &transactions, Eager("buyer")))
+	assert.Equal(t, []Transaction{{ID: 1, BuyerID: 10, Buyer: User{ID: 10, Name: "Del Piero"}}}, transactions)
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+	buyerCur.AssertExpectations(t)
 }
 
-func TestRepository_Insert_saveBelongsToError(t *testing.T) {
+func TestRepository_FindAll_eagerHasMany(t *testing.T) {
 	var (
-		address = Address{
-			Street: "street",
-			User:   &User{Name: "name"},
-		}
+		users   []User
 		adapter = &testAdapter{}
 		repo    = repository{adapter: adapter}
-		err     = errors.New("error")
 	)
 
-	adapter.On("Begin").Return(nil).Once()
-	adapter.On("Insert", From("users"), mock.Anything).Return(0, err).Once()
-	adapter.On("Rollback").Return(nil).Once()
-
-	assert.Equal(t, err, repo.Insert(context.TODO(), &address))
+	assert.Panics(t, func() {
+		repo.FindAll(context.TODO(), &users, Eager("transactions"))
+	})
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Insert_saveHasOneError(t *testing.T) {
+func TestRepository_Find_eager(t *testing.T) {
 	var (
-		userID = 1
-		user   = User{
-			Name: "name",
-			Address: Address{
-				Street: "street",
-			},
-		}
+		address Address
 		adapter = &testAdapter{}
 		repo    = repository{adapter: adapter}
-		err     = errors.New("error")
+		query   = From("addresses").Eager("user").Select("user.id", "user.name", "user.age", "user.created_at", "user.updated_at", "addresses.*").Where(Eq("addresses.id", 1), Nil("addresses.deleted_at")).Limit(1)
+		cur     = &testCursor{}
 	)
 
-	adapter.On("Begin").Return(nil).Once()
-	adapter.On("Insert", From("users"), mock.Anything).Return(userID, nil).Once()
-	adapter.On("Insert", From("addresses"), mock.Anything).Return(0, err).Once()
-	adapter.On("Rollback").Return(nil).Once()
+	query.JoinQuery = []JoinQuery{
+		{Mode: "LEFT JOIN", Table: "users", Alias: "user", From: "addresses.user_id", To: "user.id"},
+	}
 
-	assert.Equal(t, err, repo.Insert(context.TODO(), &user))
-	assert.Equal(t, User{
-		ID:        1,
-		Name:      "name",
-		CreatedAt: now(),
-		UpdatedAt: now(),
-		Address: Address{
-			Street: "street",
-		},
-	}, user)
+	adapter.On("Query", query).Return(cur, nil).Once()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "name", "age", "created_at", "updated_at", "id", "street"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(nil, nil, nil, nil, nil, 1, "Grove Street").Once()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Find(context.TODO(), &address, Eager("user"), Eq("addresses.id", 1)))
+	assert.Equal(t, Address{ID: 1, Street: "Grove Street"}, address)
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_Insert_saveHasManyError(t *testing.T) {
+func TestRepository_Find_eagerNotFound(t *testing.T) {
 	var (
-		user = User{
-			Name: "name",
-			Transactions: []Transaction{
-				{Item: "soap"},
-			},
-		}
+		address Address
 		adapter = &testAdapter{}
 		repo    = repository{adapter: adapter}
-		err     = errors.New("error")
+		cur     = &testCursor{}
 	)
 
-	adapter.On("Begin").Return(nil).Once()
-	adapter.On("Insert", From("users"), mock.Anything).Return(1, nil).Once()
-	adapter.On("InsertAll", From("transactions"), mock.Anything, mock.Anything).Return([]interface{}{}, err).Once()
-	adapter.On("Rollback").Return(nil).Once()
+	adapter.On("Query", mock.Anything).Return(cur, nil).Once()
 
-	assert.Equal(t, err, repo.Insert(context.TODO(), &user))
-	assert.Equal(t, User{
-		ID:        1,
-		Name:      "name",
-		CreatedAt: now(),
-		UpdatedAt: now(),
-		Transactions: []Transaction{
-			{BuyerID: 1, Item: "soap"},
-		},
-	}, user)
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "name", "age", "created_at", "updated_at", "id", "street"}, nil).Once()
+	cur.On("Next").Return(false).Once()
+
+	assert.Equal(t, NotFoundError{}, repo.Find(context.TODO(), &address, Eager("user")))
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_Insert_error(t *testing.T) {
+func TestRepository_FindAll_preload(t *testing.T) {
 	var (
-		user      User
-		adapter   = &testAdapter{}
-		repo      = repository{adapter: adapter}
-		modifiers = []Modifier{
-			Set("name", "name"),
-			Set("created_at", now()),
-			Set("updated_at", now()),
-		}
-		modifies = map[string]Modify{
-			"name":       Set("name", "name"),
-			"created_at": Set("created_at", now()),
-			"updated_at": Set("updated_at", now()),
-		}
+		users          []User
+		adapter        = &testAdapter{}
+		repo           = repository{adapter: adapter}
+		query          = From("users").Preload("address").Preload("transactions", Where(Eq("status", "paid")))
+		cur            = &testCursor{}
+		addressCur     = &testCursor{}
+		transactionCur = &testCursor{}
 	)
 
-	adapter.On("Insert", From("users"), modifies).Return(0, errors.New("error")).Once()
+	adapter.On("Query", query).Return(cur, nil).Once()
+	adapter.On("Query", From("addresses").Where(In("user_id", 10), Nil("deleted_at"))).Return(addressCur, nil).Once()
+	adapter.On("Query", From("transactions").Where(Eq("status", "paid"), In("user_id", 10))).Return(transactionCur, nil).Once()
 
-	assert.NotNil(t, repo.Insert(context.TODO(), &user, modifiers...))
-	assert.Panics(t, func() { repo.MustInsert(context.TODO(), &user, modifiers...) })
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "name"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(10, "Del Piero").Once()
+	cur.On("Next").Return(false).Once()
+
+	addressCur.On("Close").Return(nil).Once()
+	addressCur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	addressCur.On("Next").Return(true).Once()
+	addressCur.MockScan(1, 10).Twice()
+	addressCur.On("Next").Return(false).Once()
+
+	transactionCur.On("Close").Return(nil).Once()
+	transactionCur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	transactionCur.On("Next").Return(true).Once()
+	transactionCur.MockScan(5, 10).Twice()
+	transactionCur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.FindAll(context.TODO(), &users, query))
+	assert.Len(t, users, 1)
+	assert.Equal(t, 1, users[0].Address.ID)
+	assert.Equal(t, []Transaction{{ID: 5, BuyerID: 10}}, users[0].Transactions)
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+	addressCur.AssertExpectations(t)
+	transactionCur.AssertExpectations(t)
 }
 
-func TestRepository_Insert_nothing(t *testing.T) {
+func TestRepository_FindAll_preloadEmpty(t *testing.T) {
 	var (
+		users   []User
 		adapter = &testAdapter{}
 		repo    = repository{adapter: adapter}
+		query   = From("users").Preload("transactions")
+		cur     = createCursor(0)
 	)
 
-	assert.Nil(t, repo.Insert(context.TODO(), nil))
-	assert.NotPanics(t, func() { repo.MustInsert(context.TODO(), nil) })
+	adapter.On("Query", query).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.FindAll(context.TODO(), &users, query))
+	assert.Len(t, users, 0)
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_InsertAll(t *testing.T) {
+func TestRepository_Find_preload(t *testing.T) {
 	var (
-		users = []User{
-			{Name: "name1"},
-			{Name: "name2", Age: 12},
-		}
-		adapter  = &testAdapter{}
-		repo     = repository{adapter: adapter}
-		modifies = []map[string]Modify{
-			{
-				"name":       Set("name", "name1"),
-				"age":        Set("age", 0),
-				"created_at": Set("created_at", now()),
-				"updated_at": Set("updated_at", now()),
-			},
-			{
-				"name":       Set("name", "name2"),
-				"age":        Set("age", 12),
-				"created_at": Set("created_at", now()),
-				"updated_at": Set("updated_at", now()),
-			},
-		}
+		transaction Transaction
+		adapter     = &testAdapter{}
+		repo        = repository{adapter: adapter}
+		query       = From("transactions").Preload("buyer.address")
+		cur         = &testCursor{}
+		buyerCur    = &testCursor{}
+		err         = errors.New("error")
 	)
 
-	adapter.On("InsertAll", From("users"), mock.Anything, modifies).Return([]interface{}{1, 2}, nil).Once()
-
-	assert.Nil(t, repo.InsertAll(context.TODO(), &users))
-	assert.Equal(t, []User{
-		{ID: 1, Name: "name1", Age: 0, CreatedAt: now(), UpdatedAt: now()},
-		{ID: 2, Name: "name2", Age: 12, CreatedAt: now(), UpdatedAt: now()},
-	}, users)
+	adapter.On("Query", query.Limit(1)).Return(cur, nil).Once()
+	adapter.On("Query", From("users").Where(In("id", 10))).Return(buyerCur, nil).Once()
+	adapter.On("Query", From("addresses").Where(In("user_id", 10), Nil("deleted_at"))).Return(&testCursor{}, err).Once()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(1, 10).Once()
+
+	buyerCur.On("Close").Return(nil).Once()
+	buyerCur.On("Fields").Return([]string{"id"}, nil).Once()
+	buyerCur.On("Next").Return(true).Once()
+	buyerCur.MockScan(10).Twice()
+	buyerCur.On("Next").Return(false).Once()
+
+	assert.Equal(t, err, repo.Find(context.TODO(), &transaction, query))
+	assert.Equal(t, 10, transaction.Buyer.ID)
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+	buyerCur.AssertExpectations(t)
 }
 
-func TestRepository_InsertAll_empty(t *testing.T) {
+func TestRepository_FindAll_softDelete(t *testing.T) {
+	var (
+		addresses []Address
+		adapter   = &testAdapter{}
+		repo      = repository{adapter: adapter}
+		query     = From("addresses").Limit(1)
+		cur       = createCursor(2)
+	)
+
+	adapter.On("Query", query.Where(Nil("deleted_at"))).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.FindAll(context.TODO(), &addresses, query))
+	assert.Len(t, addresses, 2)
+	assert.Equal(t, 10, addresses[0].ID)
+	assert.Equal(t, 10, addresses[1].ID)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_FindAll_softDeleteUnscoped(t *testing.T) {
+	var (
+		addresses []Address
+		adapter   = &testAdapter{}
+		repo      = repository{adapter: adapter}
+		query     = From("addresses").Limit(1).Unscoped()
+		cur       = createCursor(2)
+	)
+
+	adapter.On("Query", query).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.FindAll(context.TODO(), &addresses, query))
+	assert.Len(t, addresses, 2)
+	assert.Equal(t, 10, addresses[0].ID)
+	assert.Equal(t, 10, addresses[1].ID)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_FindAll_error(t *testing.T) {
 	var (
 		users   []User
 		adapter = &testAdapter{}
 		repo    = repository{adapter: adapter}
+		query   = From("users").Limit(1)
+		err     = errors.New("error")
 	)
 
-	assert.Nil(t, repo.InsertAll(context.TODO(), &users))
+	adapter.On("Query", query).Return(&testCursor{}, err).Once()
+
+	assert.Equal(t, err, repo.FindAll(context.TODO(), &users, query))
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_InsertAll_nothing(t *testing.T) {
+func TestRepository_FindEach(t *testing.T) {
 	var (
+		user    User
+		ids     []int
 		adapter = &testAdapter{}
 		repo    = repository{adapter: adapter}
+		cur     = createCursor(2)
 	)
 
-	assert.Nil(t, repo.InsertAll(context.TODO(), nil))
-	assert.NotPanics(t, func() { repo.MustInsertAll(context.TODO(), nil) })
+	adapter.On("Query", From("users").Where(Eq("age", 10))).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.FindEach(context.TODO(), &user, func(record interface{}) error {
+		assert.Equal(t, &user, record)
+		ids = append(ids, user.ID)
+		return nil
+	}, Eq("age", 10)))
+	assert.Equal(t, []int{10, 10}, ids)
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_Update(t *testing.T) {
+func TestRepository_FindEach_softDelete(t *testing.T) {
 	var (
-		user      = User{ID: 1}
-		adapter   = &testAdapter{}
-		repo      = repository{adapter: adapter}
-		modifiers = []Modifier{
-			Set("name", "name"),
-			Set("updated_at", now()),
-		}
-		modifies = map[string]Modify{
-			"name":       Set("name", "name"),
-			"updated_at": Set("updated_at", now()),
-		}
-		queries = From("users").Where(Eq("id", user.ID))
+		address Address
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		cur     = createCursor(0)
 	)
 
-	adapter.On("Update", queries, modifies).Return(1, nil).Once()
+	adapter.On("Query", From("addresses").Where(Nil("deleted_at"))).Return(cur, nil).Once()
 
-	assert.Nil(t, repo.Update(context.TODO(), &user, modifiers...))
-	assert.Equal(t, User{
-		ID:        1,
-		Name:      "name",
-		UpdatedAt: now(),
-	}, user)
+	assert.NotPanics(t, func() {
+		repo.MustFindEach(context.TODO(), &address, func(record interface{}) error {
+			return nil
+		})
+	})
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_Update_softDelete(t *testing.T) {
+func TestRepository_FindEach_error(t *testing.T) {
 	var (
-		address   = Address{ID: 1}
-		adapter   = &testAdapter{}
-		repo      = repository{adapter: adapter}
-		modifiers = []Modifier{
-			Set("street", "street"),
-		}
-		modifies = map[string]Modify{
-			"street": Set("street", "street"),
-		}
-		queries = From("addresses").Where(Eq("id", address.ID))
+		user    User
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		err     = errors.New("error")
 	)
 
-	adapter.On("Update", queries.Where(Nil("deleted_at")), modifies).Return(1, nil).Once()
+	adapter.On("Query", From("users")).Return(&testCursor{}, err).Once()
 
-	assert.Nil(t, repo.Update(context.TODO(), &address, modifiers...))
-	assert.Equal(t, Address{
-		ID:     1,
-		Street: "street",
-	}, address)
+	assert.Equal(t, err, repo.FindEach(context.TODO(), &user, func(record interface{}) error {
+		return nil
+	}))
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Update_softDeleteUnscoped(t *testing.T) {
+func TestRepository_FindEach_preload(t *testing.T) {
 	var (
-		address   = Address{ID: 1}
-		adapter   = &testAdapter{}
-		repo      = repository{adapter: adapter}
-		modifiers = []Modifier{
-			Unscoped(true),
-			Set("street", "street"),
-		}
-		modifies = map[string]Modify{
-			"street": Set("street", "street"),
-		}
-		queries = From("addresses").Where(Eq("id", address.ID)).Unscoped()
+		user User
+		repo = repository{adapter: &testAdapter{}}
 	)
 
-	adapter.On("Update", queries, modifies).Return(1, nil).Once()
+	assert.Equal(t, ErrFindEachPreload, repo.FindEach(context.TODO(), &user, func(record interface{}) error {
+		return nil
+	}, Preload("transactions")))
 
-	assert.Nil(t, repo.Update(context.TODO(), &address, modifiers...))
-	assert.Equal(t, Address{
-		ID:     1,
-		Street: "street",
-	}, address)
+	assert.Equal(t, ErrFindEachPreload, repo.FindEach(context.TODO(), &user, func(record interface{}) error {
+		return nil
+	}, Eager("address")))
+}
+
+func TestRepository_MustFindAll(t *testing.T) {
+	var (
+		users   []User
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		query   = From("users").Limit(1)
+		cur     = createCursor(2)
+	)
+
+	adapter.On("Query", query).Return(cur, nil).Once()
+
+	assert.NotPanics(t, func() {
+		repo.MustFindAll(context.TODO(), &users, query)
+	})
+
+	assert.Len(t, users, 2)
+	assert.Equal(t, 10, users[0].ID)
+	assert.Equal(t, 10, users[1].ID)
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_Update_notFound(t *testing.T) {
+func TestRepository_Insert(t *testing.T) {
 	var (
-		user      = User{ID: 1}
+		user      User
 		adapter   = &testAdapter{}
 		repo      = repository{adapter: adapter}
 		modifiers = []Modifier{
 			Set("name", "name"),
+			Set("created_at", now()),
 			Set("updated_at", now()),
 		}
 		modifies = map[string]Modify{
 			"name":       Set("name", "name"),
+			"created_at": Set("created_at", now()),
 			"updated_at": Set("updated_at", now()),
 		}
-		queries = From("users").Where(Eq("id", user.ID))
 	)
 
-	adapter.On("Update", queries, modifies).Return(0, nil).Once()
+	adapter.On("Insert", From("users"), modifies).Return(1, nil).Once()
 
-	assert.Equal(t, NotFoundError{}, repo.Update(context.TODO(), &user, modifiers...))
+	assert.Nil(t, repo.Insert(context.TODO(), &user, modifiers...))
+	assert.Equal(t, User{
+		ID:        1,
+		Name:      "name",
+		CreatedAt: now(),
+		UpdatedAt: now(),
+	}, user)
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Update_reload(t *testing.T) {
+func TestRepository_Insert_onConflict(t *testing.T) {
 	var (
-		user      = User{ID: 1}
-		adapter   = &testAdapter{}
-		repo      = repository{adapter: adapter}
-		modifiers = []Modifier{
-			SetFragment("name=?", "name"),
-		}
+		user     User
+		adapter  = &testAdapter{}
+		repo     = repository{adapter: adapter}
 		modifies = map[string]Modify{
-			"name=?": SetFragment("name=?", "name"),
+			"name": Set("name", "name"),
 		}
-		queries = From("users").Where(Eq("id", user.ID))
-		cur     = createCursor(1)
 	)
 
-	adapter.On("Update", queries, modifies).Return(1, nil).Once()
-	adapter.On("Query", queries.Limit(1)).Return(cur, nil).Once()
+	adapter.On("Insert", From("users").OnConflict(OnConflictIgnore()), modifies).Return(1, nil).Once()
 
-	assert.Nil(t, repo.Update(context.TODO(), &user, modifiers...))
-	assert.False(t, cur.Next())
+	assert.Nil(t, repo.Insert(context.TODO(), &user, Set("name", "name"), OnConflictIgnore()))
+	assert.Equal(t, 1, user.ID)
 
 	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
 }
 
-func TestRepository_Update_saveBelongsToError(t *testing.T) {
+func TestRepository_Insert_onConflictNotSupported(t *testing.T) {
 	var (
-		userID  = 1
-		address = Address{
-			ID:     1,
-			UserID: &userID,
-			User: &User{
-				ID:   1,
-				Name: "name",
-			},
-		}
-		adapter = &testAdapter{}
+		user    User
+		adapter = &testAdapter{unsupported: OnConflictCapability}
 		repo    = repository{adapter: adapter}
-		queries = From("users").Where(Eq("id", address.ID))
-		err     = errors.New("error")
 	)
 
-	adapter.On("Begin").Return(nil).Once()
-	adapter.On("Update", queries, mock.Anything).Return(0, err).Once()
-	adapter.On("Rollback").Return(nil).Once()
-
-	assert.Equal(t, err, repo.Update(context.TODO(), &address))
+	assert.Equal(t, NotSupportedError{Capability: OnConflictCapability}, repo.Insert(context.TODO(), &user, Set("name", "name"), OnConflictIgnore()))
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Update_saveHasOneError(t *testing.T) {
+func TestRepository_Insert_touch(t *testing.T) {
 	var (
-		userID = 10
-		user   = User{
-			ID: userID,
-			Address: Address{
-				ID:     1,
-				Street: "street",
-				UserID: &userID,
-			},
-		}
-		adapter = &testAdapter{}
-		repo    = repository{adapter: adapter}
-		err     = errors.New("error")
+		note     = Note{Text: "text", UserID: 1}
+		adapter  = &testAdapter{}
+		repo     = repository{adapter: adapter}
+		modifies = map[string]Modify{"updated_at": Set("updated_at", now().Truncate(time.Second))}
 	)
 
 	adapter.On("Begin").Return(nil).Once()
-	adapter.On("Update", From("users").Where(Eq("id", 10)), mock.Anything).Return(1, nil).Once()
-	adapter.On("Update", From("addresses").Where(Eq("id", 1).AndEq("user_id", 10).AndNil("deleted_at")), mock.Anything).Return(1, err).Once()
-	adapter.On("Rollback").Return(nil).Once()
+	adapter.On("Insert", From("notes"), mock.Anything).Return(1, nil).Once()
+	adapter.On("Update", From("users").Where(In("id", 1)), modifies).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Insert(context.TODO(), &note))
+	assert.Equal(t, 1, note.ID)
 
-	assert.Equal(t, err, repo.Update(context.TODO(), &user))
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Update_saveHasManyError(t *testing.T) {
+func TestRepository_Insert_verify(t *testing.T) {
 	var (
-		user = User{
-			ID: 10,
-			Transactions: []Transaction{
-				{
-					ID:   1,
-					Item: "soap",
-				},
-			},
-		}
+		review  = Review{Text: "text", UserID: 10}
 		adapter = &testAdapter{}
 		repo    = repository{adapter: adapter}
-		err     = errors.New("error")
+		cur     = createCursor(1)
 	)
 
-	adapter.On("Begin").Return(nil).Once()
-	adapter.On("Update", From("users").Where(Eq("id", 10)), mock.Anything).Return(1, nil).Once()
-	adapter.On("Delete", From("transactions").Where(Eq("user_id", 10))).Return(0, err).Once()
-	adapter.On("Rollback").Return(nil).Once()
+	adapter.On("Query", From("users").Select("id").Where(In("id", 10))).Return(cur, nil).Once()
+	adapter.On("Insert", From("reviews"), mock.Anything).Return(1, nil).Once()
+
+	assert.Nil(t, repo.Insert(context.TODO(), &review))
+	assert.Equal(t, 1, review.ID)
 
-	assert.Equal(t, err, repo.Update(context.TODO(), &user))
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_Update_nothing(t *testing.T) {
+func TestRepository_Insert_verifyNotFound(t *testing.T) {
 	var (
+		review  = Review{Text: "text", UserID: 10}
 		adapter = &testAdapter{}
 		repo    = repository{adapter: adapter}
+		cur     = createCursor(0)
 	)
 
-	assert.Nil(t, repo.Update(context.TODO(), nil))
-	assert.NotPanics(t, func() { repo.MustUpdate(context.TODO(), nil) })
+	adapter.On("Query", From("users").Select("id").Where(In("id", 10))).Return(cur, nil).Once()
+
+	assert.Equal(t, ReferenceNotFoundError{Association: "user", Field: "user_id", Value: 10}, repo.Insert(context.TODO(), &review))
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_Update_error(t *testing.T) {
+func TestRepository_Insert_verifyError(t *testing.T) {
 	var (
-		user      = User{ID: 1}
-		adapter   = &testAdapter{}
-		repo      = repository{adapter: adapter}
-		modifiers = []Modifier{
-			Set("name", "name"),
-			Set("updated_at", now()),
-		}
-		modifies = map[string]Modify{
-			"name":       Set("name", "name"),
-			"updated_at": Set("updated_at", now()),
-		}
-		queries = From("users").Where(Eq("id", user.ID))
+		review  = Review{Text: "text", UserID: 10}
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		err     = errors.New("error")
 	)
 
-	adapter.On("Update", queries, modifies).Return(0, errors.New("error")).Once()
+	adapter.On("Query", From("users").Select("id").Where(In("id", 10))).Return(&testCursor{}, err).Once()
+
+	assert.Equal(t, err, repo.Insert(context.TODO(), &review))
 
-	assert.NotNil(t, repo.Update(context.TODO(), &user, modifiers...))
-	assert.Panics(t, func() { repo.MustUpdate(context.TODO(), &user, modifiers...) })
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_saveBelongsTo_update(t *testing.T) {
+func TestRepository_Insert_verifySaved(t *testing.T) {
 	var (
-		adapter      = &testAdapter{}
-		repo         = repository{adapter: adapter}
-		transaction  = Transaction{BuyerID: 1, Buyer: User{ID: 1}}
-		doc          = NewDocument(&transaction)
-		modification = Apply(doc,
-			Map{
-				"buyer": Map{
-					"name":       "buyer1",
-					"age":        20,
-					"updated_at": now(),
-				},
-			},
-		)
-		modifies = map[string]Modify{
-			"name":       Set("name", "buyer1"),
-			"age":        Set("age", 20),
-			"updated_at": Set("updated_at", now()),
-		}
-		q = Build("users", Eq("id", 1))
+		review  = Review{Text: "text", User: User{Name: "user"}}
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
 	)
 
-	adapter.On("Update", q, modifies).Return(1, nil).Once()
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Insert", From("users"), mock.Anything).Return(10, nil).Once()
+	adapter.On("Insert", From("reviews"), mock.Anything).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
 
-	assert.Nil(t, repo.saveBelongsTo(context.TODO(), doc, &modification))
-	assert.Equal(t, Transaction{
-		BuyerID: 1,
-		Buyer: User{
-			ID:        1,
-			Name:      "buyer1",
-			Age:       20,
-			UpdatedAt: now(),
-		},
-	}, transaction)
+	assert.Nil(t, repo.Insert(context.TODO(), &review))
+	assert.Equal(t, 10, review.UserID)
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_saveBelongsTo_updateError(t *testing.T) {
+func TestRepository_Insert_touchError(t *testing.T) {
 	var (
-		adapter      = &testAdapter{}
-		repo         = repository{adapter: adapter}
-		transaction  = Transaction{BuyerID: 1, Buyer: User{ID: 1}}
-		doc          = NewDocument(&transaction)
-		modification = Apply(doc,
-			Map{
-				"buyer": Map{
-					"name":       "buyer1",
-					"age":        20,
-					"updated_at": now(),
-				},
-			},
-		)
-		modifies = map[string]Modify{
-			"name":       Set("name", "buyer1"),
-			"age":        Set("age", 20),
-			"updated_at": Set("updated_at", now()),
-		}
-		q = Build("users", Eq("id", 1))
+		note    = Note{Text: "text", UserID: 1}
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		err     = errors.New("error")
 	)
 
-	adapter.On("Update", q, modifies).Return(0, errors.New("update error")).Once()
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Insert", From("notes"), mock.Anything).Return(1, nil).Once()
+	adapter.On("Update", From("users").Where(In("id", 1)), mock.Anything).Return(0, err).Once()
+	adapter.On("Rollback").Return(nil).Once()
 
-	err := repo.saveBelongsTo(context.TODO(), doc, &modification)
-	assert.Equal(t, errors.New("update error"), err)
+	assert.Equal(t, err, repo.Insert(context.TODO(), &note))
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_saveBelongsTo_updateInconsistentAssoc(t *testing.T) {
+func TestRepository_Insert_touchZero(t *testing.T) {
 	var (
-		adapter      = &testAdapter{}
-		repo         = repository{adapter: adapter}
-		transaction  = Transaction{Buyer: User{ID: 1}}
-		doc          = NewDocument(&transaction)
-		modification = Apply(doc,
-			Map{
-				"buyer": Map{
-					"id":   1,
-					"name": "buyer1",
-					"age":  20,
-				},
-			},
-		)
+		note    = Note{Text: "text"}
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
 	)
 
-	assert.Equal(t, ConstraintError{
-		Key:  "user_id",
-		Type: ForeignKeyConstraint,
-		Err:  errors.New("rel: inconsistent belongs to ref and fk"),
-	}, repo.saveBelongsTo(context.TODO(), doc, &modification))
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Insert", From("notes"), mock.Anything).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Insert(context.TODO(), &note))
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_saveBelongsTo_insertNew(t *testing.T) {
+func TestRepository_Insert_saveBelongsToError(t *testing.T) {
 	var (
-		transaction  Transaction
-		adapter      = &testAdapter{}
-		repo         = repository{adapter: adapter}
-		doc          = NewDocument(&transaction)
-		modification = Apply(doc,
-			Map{
-				"buyer": Map{
-					"name": "buyer1",
-					"age":  20,
-				},
-			},
-		)
-		modifies = map[string]Modify{
-			"name": Set("name", "buyer1"),
-			"age":  Set("age", 20),
+		address = Address{
+			Street: "street",
+			User:   &User{Name: "name"},
 		}
-		q = Build("users")
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		err     = errors.New("error")
 	)
 
-	adapter.On("Insert", q, modifies).Return(1, nil).Once()
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Insert", From("users"), mock.Anything).Return(0, err).Once()
+	adapter.On("Rollback").Return(nil).Once()
 
-	assert.Nil(t, repo.saveBelongsTo(context.TODO(), doc, &modification))
-	assert.Equal(t, Set("user_id", 1), modification.Modifies["user_id"])
-	assert.Equal(t, Transaction{
-		Buyer: User{
-			ID:   1,
-			Name: "buyer1",
-			Age:  20,
-		},
-		BuyerID: 1,
-	}, transaction)
+	assert.Equal(t, err, repo.Insert(context.TODO(), &address))
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_saveBelongsTo_insertNewError(t *testing.T) {
+func TestRepository_Insert_saveHasOneError(t *testing.T) {
 	var (
-		adapter      = &testAdapter{}
-		repo         = repository{adapter: adapter}
-		transaction  = Transaction{}
-		doc          = NewDocument(&transaction)
-		modification = Apply(doc,
-			Map{
-				"buyer": Map{
-					"name":       "buyer1",
-					"age":        20,
-					"created_at": now(),
-					"updated_at": now(),
-				},
+		userID = 1
+		user   = User{
+			Name: "name",
+			Address: Address{
+				Street: "street",
 			},
-		)
-		modifies = map[string]Modify{
-			"name":       Set("name", "buyer1"),
-			"age":        Set("age", 20),
-			"created_at": Set("created_at", now()),
-			"updated_at": Set("updated_at", now()),
 		}
-		q = Build("users")
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		err     = errors.New("error")
 	)
 
-	adapter.On("Insert", q, modifies).Return(0, errors.New("insert error")).Once()
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Insert", From("users"), mock.Anything).Return(userID, nil).Once()
+	adapter.On("Insert", From("addresses"), mock.Anything).Return(0, err).Once()
+	adapter.On("Rollback").Return(nil).Once()
 
-	assert.Equal(t, errors.New("insert error"), repo.saveBelongsTo(context.TODO(), doc, &modification))
-	assert.Zero(t, modification.Modifies["user_id"])
+	assert.Equal(t, err, repo.Insert(context.TODO(), &user))
+	assert.Equal(t, User{
+		ID:        1,
+		Name:      "name",
+		CreatedAt: now(),
+		UpdatedAt: now(),
+		Address: Address{
+			Street: "street",
+		},
+	}, user)
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_saveBelongsTo_notChanged(t *testing.T) {
+func TestRepository_Insert_saveHasManyError(t *testing.T) {
 	var (
-		adapter      = &testAdapter{}
-		repo         = repository{adapter: adapter}
-		transaction  = Transaction{}
-		doc          = NewDocument(&transaction)
-		modification = Apply(doc)
+		user = User{
+			Name: "name",
+			Transactions: []Transaction{
+				{Item: "soap"},
+			},
+		}
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		err     = errors.New("error")
 	)
 
-	err := repo.saveBelongsTo(context.TODO(), doc, &modification)
-	assert.Nil(t, err)
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Insert", From("users"), mock.Anything).Return(1, nil).Once()
+	adapter.On("InsertAll", From("transactions"), mock.Anything, mock.Anything).Return([]interface{}{}, err).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, err, repo.Insert(context.TODO(), &user))
+	assert.Equal(t, User{
+		ID:        1,
+		Name:      "name",
+		CreatedAt: now(),
+		UpdatedAt: now(),
+		Transactions: []Transaction{
+			{BuyerID: 1, Item: "soap"},
+		},
+	}, user)
+
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_saveHasOne_update(t *testing.T) {
+func TestRepository_Insert_error(t *testing.T) {
 	var (
-		adapter      = &testAdapter{}
-		repo         = repository{adapter: adapter}
-		userID       = 1
-		user         = User{ID: userID, Address: Address{ID: 2, UserID: &userID}}
-		doc          = NewDocument(&user)
-		modification = Apply(doc,
-			Map{
-				"address": Map{
-					"street": "street1",
-				},
-			},
-		)
+		user      User
+		adapter   = &testAdapter{}
+		repo      = repository{adapter: adapter}
+		modifiers = []Modifier{
+			Set("name", "name"),
+			Set("created_at", now()),
+			Set("updated_at", now()),
+		}
 		modifies = map[string]Modify{
-			"street": Set("street", "street1"),
+			"name":       Set("name", "name"),
+			"created_at": Set("created_at", now()),
+			"updated_at": Set("updated_at", now()),
 		}
-		q = Build("addresses").Where(Eq("id", 2).AndEq("user_id", 1).AndNil("deleted_at"))
 	)
 
-	adapter.On("Update", q, modifies).Return(1, nil).Once()
+	adapter.On("Insert", From("users"), modifies).Return(0, errors.New("error")).Once()
+
+	assert.NotNil(t, repo.Insert(context.TODO(), &user, modifiers...))
+	assert.Panics(t, func() { repo.MustInsert(context.TODO(), &user, modifiers...) })
 
-	assert.Nil(t, repo.saveHasOne(context.TODO(), doc, &modification))
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_saveHasOne_updateError(t *testing.T) {
+func TestRepository_Insert_nothing(t *testing.T) {
 	var (
-		adapter      = &testAdapter{}
-		repo         = repository{adapter: adapter}
-		userID       = 1
-		user         = User{ID: userID, Address: Address{ID: 2, UserID: &userID}}
-		doc          = NewDocument(&user)
-		modification = Apply(doc,
-			Map{
-				"address": Map{
-					"street": "street1",
-				},
-			},
-		)
-		modifies = map[string]Modify{
-			"street": Set("street", "street1"),
-		}
-		q = Build("addresses").Where(Eq("id", 2).AndEq("user_id", 1).AndNil("deleted_at"))
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
 	)
 
-	adapter.On("Update", q, modifies).Return(0, errors.New("update error")).Once()
-
-	err := repo.saveHasOne(context.TODO(), doc, &modification)
-	assert.Equal(t, errors.New("update error"), err)
+	assert.Nil(t, repo.Insert(context.TODO(), nil))
+	assert.NotPanics(t, func() { repo.MustInsert(context.TODO(), nil) })
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_saveHasOne_updateInconsistentAssoc(t *testing.T) {
+func TestRepository_InsertAll(t *testing.T) {
 	var (
-		adapter      = &testAdapter{}
-		repo         = repository{adapter: adapter}
-		user         = User{ID: 1, Address: Address{ID: 2}}
-		doc          = NewDocument(&user)
-		modification = Apply(doc,
-			Map{
-				"address": Map{
-					"id":     2,
-					"street": "street1",
-				},
+		users = []User{
+			{Name: "name1"},
+			{Name: "name2", Age: 12},
+		}
+		adapter  = &testAdapter{}
+		repo     = repository{adapter: adapter}
+		modifies = []map[string]Modify{
+			{
+				"name":       Set("name", "name1"),
+				"age":        Set("age", 0),
+				"created_at": Set("created_at", now()),
+				"updated_at": Set("updated_at", now()),
 			},
-		)
+			{
+				"name":       Set("name", "name2"),
+				"age":        Set("age", 12),
+				"created_at": Set("created_at", now()),
+				"updated_at": Set("updated_at", now()),
+			},
+		}
 	)
 
-	assert.Equal(t, ConstraintError{
-		Key:  "user_id",
-		Type: ForeignKeyConstraint,
-		Err:  errors.New("rel: inconsistent has one ref and fk"),
-	}, repo.saveHasOne(context.TODO(), doc, &modification))
+	adapter.On("InsertAll", From("users"), mock.Anything, modifies).Return([]interface{}{1, 2}, nil).Once()
+
+	assert.Nil(t, repo.InsertAll(context.TODO(), &users))
+	assert.Equal(t, []User{
+		{ID: 1, Name: "name1", Age: 0, CreatedAt: now(), UpdatedAt: now()},
+		{ID: 2, Name: "name2", Age: 12, CreatedAt: now(), UpdatedAt: now()},
+	}, users)
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_saveHasOne_insertNew(t *testing.T) {
+func TestRepository_InsertAll_onConflict(t *testing.T) {
 	var (
-		user         = User{ID: 1}
-		adapter      = &testAdapter{}
-		repo         = repository{adapter: adapter}
-		doc          = NewDocument(&user)
-		modification = Apply(doc,
-			Map{
-				"address": Map{
-					"street": "street1",
-				},
+		users    = []User{{Name: "name1"}}
+		adapter  = &testAdapter{}
+		repo     = repository{adapter: adapter}
+		modifies = []map[string]Modify{
+			{
+				"name":       Set("name", "name1"),
+				"age":        Set("age", 0),
+				"created_at": Set("created_at", now()),
+				"updated_at": Set("updated_at", now()),
 			},
-		)
-		modifies = map[string]Modify{
-			"street":  Set("street", "street1"),
-			"user_id": Set("user_id", 1),
 		}
-		q = Build("addresses")
 	)
 
-	adapter.On("Insert", q, modifies).Return(2, nil).Once()
+	adapter.On("InsertAll", From("users").OnConflict(OnConflictReplace()), mock.Anything, modifies).Return([]interface{}{1}, nil).Once()
 
-	assert.Nil(t, repo.saveHasOne(context.TODO(), doc, &modification))
-	assert.Equal(t, User{
-		ID: 1,
-		Address: Address{
-			ID:     2,
-			Street: "street1",
-			UserID: &user.ID,
-		},
-	}, user)
+	assert.NotPanics(t, func() {
+		repo.MustInsertAll(context.TODO(), &users, OnConflictReplace())
+	})
+	assert.Equal(t, 1, users[0].ID)
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_saveHasOne_insertNewError(t *testing.T) {
+func TestRepository_InsertAll_onConflictNotSupported(t *testing.T) {
 	var (
-		adapter      = &testAdapter{}
-		repo         = repository{adapter: adapter}
-		user         = User{ID: 1}
-		doc          = NewDocument(&user)
-		modification = Apply(doc,
-			Map{
-				"address": Map{
-					"street": "street1",
-				},
-			},
-		)
-		modifies = map[string]Modify{
-			"street":  Set("street", "street1"),
-			"user_id": Set("user_id", 1),
-		}
-		q = Build("addresses")
+		users   = []User{{Name: "name1"}}
+		adapter = &testAdapter{unsupported: OnConflictCapability}
+		repo    = repository{adapter: adapter}
 	)
 
-	adapter.On("Insert", q, modifies).Return(nil, errors.New("insert error")).Once()
+	assert.Equal(t, NotSupportedError{Capability: OnConflictCapability}, repo.InsertAll(context.TODO(), &users, OnConflictReplace()))
 
-	assert.Equal(t, errors.New("insert error"), repo.saveHasOne(context.TODO(), doc, &modification))
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_InsertAll_empty(t *testing.T) {
+	var (
+		users   []User
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+	)
+
+	assert.Nil(t, repo.InsertAll(context.TODO(), &users))
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_saveHasMany_insert(t *testing.T) {
+func TestRepository_InsertAll_nothing(t *testing.T) {
 	var (
-		adapter      = &testAdapter{}
-		repo         = repository{adapter: adapter}
-		user         = User{ID: 1}
-		doc          = NewDocument(&user)
-		modification = Apply(doc,
-			Map{
-				"transactions": []Map{
-					{"item": "item1"},
-					{"item": "item2"},
-				},
-			},
-		)
-		modifies = []map[string]Modify{
-			{"user_id": Set("user_id", user.ID), "item": Set("item", "item1")},
-			{"user_id": Set("user_id", user.ID), "item": Set("item", "item2")},
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+	)
+
+	assert.Nil(t, repo.InsertAll(context.TODO(), nil))
+	assert.NotPanics(t, func() { repo.MustInsertAll(context.TODO(), nil) })
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_InsertAll_verify(t *testing.T) {
+	var (
+		reviews = []Review{
+			{Text: "text1", UserID: 10},
+			{Text: "text2", UserID: 10},
+			{Text: "text3", UserID: 11},
 		}
-		q = Build("transactions")
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		cur     = createCursor(1)
 	)
 
-	adapter.On("InsertAll", q, []string{"item", "user_id"}, modifies).Return(nil).Return([]interface{}{2, 3}, nil).Maybe()
-	adapter.On("InsertAll", q, []string{"user_id", "item"}, modifies).Return(nil).Return([]interface{}{2, 3}, nil).Maybe()
+	adapter.On("Query", From("users").Select("id").Where(In("id", 10, 11))).Return(cur, nil).Once()
 
-	assert.Nil(t, repo.saveHasMany(context.TODO(), doc, &modification, true))
-	assert.Equal(t, User{
-		ID: 1,
-		Transactions: []Transaction{
-			{ID: 2, BuyerID: 1, Item: "item1"},
-			{ID: 3, BuyerID: 1, Item: "item2"},
-		},
-	}, user)
+	assert.Equal(t, ReferenceNotFoundError{Association: "user", Field: "user_id", Value: 11}, repo.InsertAll(context.TODO(), &reviews))
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_saveHasMany_insertError(t *testing.T) {
+func TestRepository_InsertAll_touch(t *testing.T) {
 	var (
-		adapter      = &testAdapter{}
-		repo         = repository{adapter: adapter}
-		user         = User{ID: 1}
-		doc          = NewDocument(&user)
-		modification = Apply(doc,
-			Map{
-				"transactions": []Map{
-					{"item": "item1"},
-					{"item": "item2"},
-				},
-			},
-		)
-		modifies = []map[string]Modify{
-			{"user_id": Set("user_id", user.ID), "item": Set("item", "item1")},
-			{"user_id": Set("user_id", user.ID), "item": Set("item", "item2")},
+		notes = []Note{
+			{Text: "text1", UserID: 1},
+			{Text: "text2", UserID: 2},
+			{Text: "text3", UserID: 1},
 		}
-		q   = Build("transactions")
-		err = errors.New("insert all error")
+		adapter  = &testAdapter{}
+		repo     = repository{adapter: adapter}
+		modifies = map[string]Modify{"updated_at": Set("updated_at", now().Truncate(time.Second))}
 	)
 
-	adapter.On("InsertAll", q, []string{"item", "user_id"}, modifies).Return(nil).Return([]interface{}{}, err).Maybe()
-	adapter.On("InsertAll", q, []string{"user_id", "item"}, modifies).Return(nil).Return([]interface{}{}, err).Maybe()
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("InsertAll", From("notes"), mock.Anything, mock.Anything).Return([]interface{}{1, 2, 3}, nil).Once()
+	adapter.On("Update", From("users").Where(In("id", 1, 2)), modifies).Return(2, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
 
-	assert.Equal(t, err, repo.saveHasMany(context.TODO(), doc, &modification, true))
+	assert.Nil(t, repo.InsertAll(context.TODO(), &notes))
+	assert.Equal(t, 3, notes[2].ID)
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_saveHasMany_update(t *testing.T) {
+func TestRepository_Update(t *testing.T) {
 	var (
-		adapter = &testAdapter{}
-		repo    = repository{adapter: adapter}
-		user    = User{
-			ID: 1,
-			Transactions: []Transaction{
-				{ID: 1, BuyerID: 1, Item: "item1"},
-				{ID: 2, BuyerID: 1, Item: "item2"},
-				{ID: 3, BuyerID: 1, Item: "item3"},
-			},
+		user      = User{ID: 1}
+		adapter   = &testAdapter{}
+		repo      = repository{adapter: adapter}
+		modifiers = []Modifier{
+			Set("name", "name"),
+			Set("updated_at", now()),
 		}
-		doc          = NewDocument(&user)
-		modification = Apply(doc,
-			Map{
-				"transactions": []Map{
-					{"id": 1, "item": "item1 updated"},
-					{"id": 2, "item": "item2 updated"},
-				},
-			},
-		)
-		modifies = []map[string]Modify{
-			{"item": Set("item", "item1 updated")},
-			{"item": Set("item", "item2 updated")},
+		modifies = map[string]Modify{
+			"name":       Set("name", "name"),
+			"updated_at": Set("updated_at", now()),
 		}
-		q = Build("transactions")
+		queries = From("users").Where(Eq("id", user.ID))
 	)
 
-	modification.SetDeletedIDs("transactions", []interface{}{3})
-
-	adapter.On("Delete", q.Where(Eq("user_id", 1).AndIn("id", 3))).Return(1, nil).Once()
-	adapter.On("Update", q.Where(Eq("id", 1).AndEq("user_id", 1)), modifies[0]).Return(1, nil).Once()
-	adapter.On("Update", q.Where(Eq("id", 2).AndEq("user_id", 1)), modifies[1]).Return(1, nil).Once()
+	adapter.On("Update", queries, modifies).Return(1, nil).Once()
 
-	assert.Nil(t, repo.saveHasMany(context.TODO(), doc, &modification, false))
+	assert.Nil(t, repo.Update(context.TODO(), &user, modifiers...))
 	assert.Equal(t, User{
-		ID: 1,
-		Transactions: []Transaction{
-			{ID: 1, BuyerID: 1, Item: "item1 updated"},
-			{ID: 2, BuyerID: 1, Item: "item2 updated"},
-		},
+		ID:        1,
+		Name:      "name",
+		UpdatedAt: now(),
 	}, user)
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_saveHasMany_updateWithInsert(t *testing.T) {
+func TestRepository_Update_touch(t *testing.T) {
 	var (
-		adapter = &testAdapter{}
-		repo    = repository{adapter: adapter}
-		user    = User{
-			ID: 1,
-			Transactions: []Transaction{
-				{ID: 1, BuyerID: 1, Item: "item1"},
+		note     = Note{ID: 1, Text: "text", UserID: 2}
+		adapter  = &testAdapter{}
+		repo     = repository{adapter: adapter}
+		modifies = map[string]Modify{"updated_at": Set("updated_at", now().Truncate(time.Second))}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Update", From("notes").Where(Eq("id", 1)), map[string]Modify{"text": Set("text", "updated")}).Return(1, nil).Once()
+	adapter.On("Update", From("users").Where(In("id", 2)), modifies).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Update(context.TODO(), &note, Set("text", "updated")))
+	assert.Equal(t, "updated", note.Text)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Update_verify(t *testing.T) {
+	var (
+		review  = Review{ID: 1, Text: "text", UserID: 2}
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		cur     = createCursor(0)
+	)
+
+	adapter.On("Update", From("reviews").Where(Eq("id", 1)), map[string]Modify{"text": Set("text", "updated")}).Return(1, nil).Once()
+	adapter.On("Query", From("users").Select("id").Where(In("id", 3))).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.Update(context.TODO(), &review, Set("text", "updated")))
+	assert.Equal(t, ReferenceNotFoundError{Association: "user", Field: "user_id", Value: 3}, repo.Update(context.TODO(), &review, Set("user_id", 3)))
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Update_softDelete(t *testing.T) {
+	var (
+		address   = Address{ID: 1}
+		adapter   = &testAdapter{}
+		repo      = repository{adapter: adapter}
+		modifiers = []Modifier{
+			Set("street", "street"),
+		}
+		modifies = map[string]Modify{
+			"street": Set("street", "street"),
+		}
+		queries = From("addresses").Where(Eq("id", address.ID))
+	)
+
+	adapter.On("Update", queries.Where(Nil("deleted_at")), modifies).Return(1, nil).Once()
+
+	assert.Nil(t, repo.Update(context.TODO(), &address, modifiers...))
+	assert.Equal(t, Address{
+		ID:     1,
+		Street: "street",
+	}, address)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Update_softDeleteUnscoped(t *testing.T) {
+	var (
+		address   = Address{ID: 1}
+		adapter   = &testAdapter{}
+		repo      = repository{adapter: adapter}
+		modifiers = []Modifier{
+			Unscoped(true),
+			Set("street", "street"),
+		}
+		modifies = map[string]Modify{
+			"street": Set("street", "street"),
+		}
+		queries = From("addresses").Where(Eq("id", address.ID)).Unscoped()
+	)
+
+	adapter.On("Update", queries, modifies).Return(1, nil).Once()
+
+	assert.Nil(t, repo.Update(context.TODO(), &address, modifiers...))
+	assert.Equal(t, Address{
+		ID:     1,
+		Street: "street",
+	}, address)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Update_notFound(t *testing.T) {
+	var (
+		user      = User{ID: 1}
+		adapter   = &testAdapter{}
+		repo      = repository{adapter: adapter}
+		modifiers = []Modifier{
+			Set("name", "name"),
+			Set("updated_at", now()),
+		}
+		modifies = map[string]Modify{
+			"name":       Set("name", "name"),
+			"updated_at": Set("updated_at", now()),
+		}
+		queries = From("users").Where(Eq("id", user.ID))
+	)
+
+	adapter.On("Update", queries, modifies).Return(0, nil).Once()
+
+	assert.Equal(t, NotFoundError{}, repo.Update(context.TODO(), &user, modifiers...))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Update_reload(t *testing.T) {
+	var (
+		user      = User{ID: 1}
+		adapter   = &testAdapter{}
+		repo      = repository{adapter: adapter}
+		modifiers = []Modifier{
+			SetFragment("name=?", "name"),
+		}
+		modifies = map[string]Modify{
+			"name=?": SetFragment("name=?", "name"),
+		}
+		queries = From("users").Where(Eq("id", user.ID))
+		cur     = createCursor(1)
+	)
+
+	adapter.On("Update", queries, modifies).Return(1, nil).Once()
+	adapter.On("Query", queries.Limit(1)).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.Update(context.TODO(), &user, modifiers...))
+	assert.False(t, cur.Next())
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Update_saveBelongsToError(t *testing.T) {
+	var (
+		userID  = 1
+		address = Address{
+			ID:     1,
+			UserID: &userID,
+			User: &User{
+				ID:   1,
+				Name: "name",
 			},
 		}
-		doc          = NewDocument(&user)
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		queries = From("users").Where(Eq("id", address.ID))
+		err     = errors.New("error")
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Update", queries, mock.Anything).Return(0, err).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, err, repo.Update(context.TODO(), &address))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Update_saveHasOneError(t *testing.T) {
+	var (
+		userID = 10
+		user   = User{
+			ID: userID,
+			Address: Address{
+				ID:     1,
+				Street: "street",
+				UserID: &userID,
+			},
+		}
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		err     = errors.New("error")
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Update", From("users").Where(Eq("id", 10)), mock.Anything).Return(1, nil).Once()
+	adapter.On("Update", From("addresses").Where(Eq("id", 1).AndEq("user_id", 10).AndNil("deleted_at")), mock.Anything).Return(1, err).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, err, repo.Update(context.TODO(), &user))
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Update_saveHasManyError(t *testing.T) {
+	var (
+		user = User{
+			ID: 10,
+			Transactions: []Transaction{
+				{
+					ID:   1,
+					Item: "soap",
+				},
+			},
+		}
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		err     = errors.New("error")
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Update", From("users").Where(Eq("id", 10)), mock.Anything).Return(1, nil).Once()
+	adapter.On("Delete", From("transactions").Where(Eq("user_id", 10).AndNin("id", 1))).Return(0, err).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, err, repo.Update(context.TODO(), &user))
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Update_nothing(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+	)
+
+	assert.Nil(t, repo.Update(context.TODO(), nil))
+	assert.NotPanics(t, func() { repo.MustUpdate(context.TODO(), nil) })
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Update_error(t *testing.T) {
+	var (
+		user      = User{ID: 1}
+		adapter   = &testAdapter{}
+		repo      = repository{adapter: adapter}
+		modifiers = []Modifier{
+			Set("name", "name"),
+			Set("updated_at", now()),
+		}
+		modifies = map[string]Modify{
+			"name":       Set("name", "name"),
+			"updated_at": Set("updated_at", now()),
+		}
+		queries = From("users").Where(Eq("id", user.ID))
+	)
+
+	adapter.On("Update", queries, modifies).Return(0, errors.New("error")).Once()
+
+	assert.NotNil(t, repo.Update(context.TODO(), &user, modifiers...))
+	assert.Panics(t, func() { repo.MustUpdate(context.TODO(), &user, modifiers...) })
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_saveBelongsTo_update(t *testing.T) {
+	var (
+		adapter      = &testAdapter{}
+		repo         = repository{adapter: adapter}
+		transaction  = Transaction{BuyerID: 1, Buyer: User{ID: 1}}
+		doc          = NewDocument(&transaction)
 		modification = Apply(doc,
 			Map{
-				"transactions": []Map{
-					{"id": 1, "item": "item1 updated"},
-					{"item": "new item", "user_id": 1},
+				"buyer": Map{
+					"name":       "buyer1",
+					"age":        20,
+					"updated_at": now(),
 				},
 			},
 		)
-		q        = Build("transactions")
-		modifies = []map[string]Modify{
-			{"item": Set("item", "item1 updated")},
-			{"user_id": Set("user_id", user.ID), "item": Set("item", "new item")},
+		modifies = map[string]Modify{
+			"name":       Set("name", "buyer1"),
+			"age":        Set("age", 20),
+			"updated_at": Set("updated_at", now()),
 		}
+		q = Build("users", Eq("id", 1))
+	)
+
+	adapter.On("Update", q, modifies).Return(1, nil).Once()
+
+	assert.Nil(t, repo.saveBelongsTo(context.TODO(), doc, &modification))
+	assert.Equal(t, Transaction{
+		BuyerID: 1,
+		Buyer: User{
+			ID:        1,
+			Name:      "buyer1",
+			Age:       20,
+			UpdatedAt: now(),
+		},
+	}, transaction)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_saveBelongsTo_updateError(t *testing.T) {
+	var (
+		adapter      = &testAdapter{}
+		repo         = repository{adapter: adapter}
+		transaction  = Transaction{BuyerID: 1, Buyer: User{ID: 1}}
+		doc          = NewDocument(&transaction)
+		modification = Apply(doc,
+			Map{
+				"buyer": Map{
+					"name":       "buyer1",
+					"age":        20,
+					"updated_at": now(),
+				},
+			},
+		)
+		modifies = map[string]Modify{
+			"name":       Set("name", "buyer1"),
+			"age":        Set("age", 20),
+			"updated_at": Set("updated_at", now()),
+		}
+		q = Build("users", Eq("id", 1))
+	)
+
+	adapter.On("Update", q, modifies).Return(0, errors.New("update error")).Once()
+
+	err := repo.saveBelongsTo(context.TODO(), doc, &modification)
+	assert.Equal(t, errors.New("update error"), err)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_saveBelongsTo_updateInconsistentAssoc(t *testing.T) {
+	var (
+		adapter      = &testAdapter{}
+		repo         = repository{adapter: adapter}
+		transaction  = Transaction{Buyer: User{ID: 1}}
+		doc          = NewDocument(&transaction)
+		modification = Apply(doc,
+			Map{
+				"buyer": Map{
+					"id":   1,
+					"name": "buyer1",
+					"age":  20,
+				},
+			},
+		)
+	)
+
+	assert.Equal(t, ConstraintError{
+		Key:  "user_id",
+		Type: ForeignKeyConstraint,
+		Err:  errors.New("rel: inconsistent belongs to ref and fk"),
+	}, repo.saveBelongsTo(context.TODO(), doc, &modification))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_saveBelongsTo_insertNew(t *testing.T) {
+	var (
+		transaction  Transaction
+		adapter      = &testAdapter{}
+		repo         = repository{adapter: adapter}
+		doc          = NewDocument(&transaction)
+		modification = Apply(doc,
+			Map{
+				"buyer": Map{
+					"name": "buyer1",
+					"age":  20,
+				},
+			},
+		)
+		modifies = map[string]Modify{
+			"name": Set("name", "buyer1"),
+			"age":  Set("age", 20),
+		}
+		q = Build("users")
+	)
+
+	adapter.On("Insert", q, modifies).Return(1, nil).Once()
+
+	assert.Nil(t, repo.saveBelongsTo(context.TODO(), doc, &modification))
+	assert.Equal(t, Set("user_id", 1), modification.Modifies["user_id"])
+	assert.Equal(t, Transaction{
+		Buyer: User{
+			ID:   1,
+			Name: "buyer1",
+			Age:  20,
+		},
+		BuyerID: 1,
+	}, transaction)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_saveBelongsTo_insertNewError(t *testing.T) {
+	var (
+		adapter      = &testAdapter{}
+		repo         = repository{adapter: adapter}
+		transaction  = Transaction{}
+		doc          = NewDocument(&transaction)
+		modification = Apply(doc,
+			Map{
+				"buyer": Map{
+					"name":       "buyer1",
+					"age":        20,
+					"created_at": now(),
+					"updated_at": now(),
+				},
+			},
+		)
+		modifies = map[string]Modify{
+			"name":       Set("name", "buyer1"),
+			"age":        Set("age", 20),
+			"created_at": Set("created_at", now()),
+			"updated_at": Set("updated_at", now()),
+		}
+		q = Build("users")
+	)
+
+	adapter.On("Insert", q, modifies).Return(0, errors.New("insert error")).Once()
+
+	assert.Equal(t, errors.New("insert error"), repo.saveBelongsTo(context.TODO(), doc, &modification))
+	assert.Zero(t, modification.Modifies["user_id"])
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_saveBelongsTo_notChanged(t *testing.T) {
+	var (
+		adapter      = &testAdapter{}
+		repo         = repository{adapter: adapter}
+		transaction  = Transaction{}
+		doc          = NewDocument(&transaction)
+		modification = Apply(doc)
+	)
+
+	err := repo.saveBelongsTo(context.TODO(), doc, &modification)
+	assert.Nil(t, err)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_saveHasOne_update(t *testing.T) {
+	var (
+		adapter      = &testAdapter{}
+		repo         = repository{adapter: adapter}
+		userID       = 1
+		user         = User{ID: userID, Address: Address{ID: 2, UserID: &userID}}
+		doc          = NewDocument(&user)
+		modification = Apply(doc,
+			Map{
+				"address": Map{
+					"street": "street1",
+				},
+			},
+		)
+		modifies = map[string]Modify{
+			"street": Set("street", "street1"),
+		}
+		q = Build("addresses").Where(Eq("id", 2).AndEq("user_id", 1).AndNil("deleted_at"))
+	)
+
+	adapter.On("Update", q, modifies).Return(1, nil).Once()
+
+	assert.Nil(t, repo.saveHasOne(context.TODO(), doc, &modification))
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_saveHasOne_updateError(t *testing.T) {
+	var (
+		adapter      = &testAdapter{}
+		repo         = repository{adapter: adapter}
+		userID       = 1
+		user         = User{ID: userID, Address: Address{ID: 2, UserID: &userID}}
+		doc          = NewDocument(&user)
+		modification = Apply(doc,
+			Map{
+				"address": Map{
+					"street": "street1",
+				},
+			},
+		)
+		modifies = map[string]Modify{
+			"street": Set("street", "street1"),
+		}
+		q = Build("addresses").Where(Eq("id", 2).AndEq("user_id", 1).AndNil("deleted_at"))
+	)
+
+	adapter.On("Update", q, modifies).Return(0, errors.New("update error")).Once()
+
+	err := repo.saveHasOne(context.TODO(), doc, &modification)
+	assert.Equal(t, errors.New("update error"), err)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_saveHasOne_updateInconsistentAssoc(t *testing.T) {
+	var (
+		adapter      = &testAdapter{}
+		repo         = repository{adapter: adapter}
+		user         = User{ID: 1, Address: Address{ID: 2}}
+		doc          = NewDocument(&user)
+		modification = Apply(doc,
+			Map{
+				"address": Map{
+					"id":     2,
+					"street": "street1",
+				},
+			},
+		)
+	)
+
+	assert.Equal(t, ConstraintError{
+		Key:  "user_id",
+		Type: ForeignKeyConstraint,
+		Err:  errors.New("rel: inconsistent has one ref and fk"),
+	}, repo.saveHasOne(context.TODO(), doc, &modification))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_saveHasOne_insertNew(t *testing.T) {
+	var (
+		user         = User{ID: 1}
+		adapter      = &testAdapter{}
+		repo         = repository{adapter: adapter}
+		doc          = NewDocument(&user)
+		modification = Apply(doc,
+			Map{
+				"address": Map{
+					"street": "street1",
+				},
+			},
+		)
+		modifies = map[string]Modify{
+			"street":  Set("street", "street1"),
+			"user_id": Set("user_id", 1),
+		}
+		q = Build("addresses")
+	)
+
+	adapter.On("Insert", q, modifies).Return(2, nil).Once()
+
+	assert.Nil(t, repo.saveHasOne(context.TODO(), doc, &modification))
+	assert.Equal(t, User{
+		ID: 1,
+		Address: Address{
+			ID:     2,
+			Street: "street1",
+			UserID: &user.ID,
+		},
+	}, user)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_saveHasOne_insertNewError(t *testing.T) {
+	var (
+		adapter      = &testAdapter{}
+		repo         = repository{adapter: adapter}
+		user         = User{ID: 1}
+		doc          = NewDocument(&user)
+		modification = Apply(doc,
+			Map{
+				"address": Map{
+					"street": "street1",
+				},
+			},
+		)
+		modifies = map[string]Modify{
+			"street":  Set("street", "street1"),
+			"user_id": Set("user_id", 1),
+		}
+		q = Build("addresses")
+	)
+
+	adapter.On("Insert", q, modifies).Return(nil, errors.New("insert error")).Once()
+
+	assert.Equal(t, errors.New("insert error"), repo.saveHasOne(context.TODO(), doc, &modification))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_saveHasMany_insert(t *testing.T) {
+	var (
+		adapter      = &testAdapter{}
+		repo         = repository{adapter: adapter}
+		user         = User{ID: 1}
+		doc          = NewDocument(&user)
+		modification = Apply(doc,
+			Map{
+				"transactions": []Map{
+					{"item": "item1"},
+					{"item": "item2"},
+				},
+			},
+		)
+		modifies = []map[string]Modify{
+			{"user_id": Set("user_id", user.ID), "item": Set("item", "item1")},
+			{"user_id": Set("user_id", user.ID), "item": Set("item", "item2")},
+		}
+		q = Build("transactions")
+	)
+
+	adapter.On("InsertAll", q, []string{"item", "user_id"}, modifies).Return(nil).Return([]interface{}{2, 3}, nil).Maybe()
+	adapter.On("InsertAll", q, []string{"user_id", "item"}, modifies).Return(nil).Return([]interface{}{2, 3}, nil).Maybe()
+
+	assert.Nil(t, repo.saveHasMany(context.TODO(), doc, &modification, true))
+	assert.Equal(t, User{
+		ID: 1,
+		Transactions: []Transaction{
+			{ID: 2, BuyerID: 1, Item: "item1"},
+			{ID: 3, BuyerID: 1, Item: "item2"},
+		},
+	}, user)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_saveHasMany_insertError(t *testing.T) {
+	var (
+		adapter      = &testAdapter{}
+		repo         = repository{adapter: adapter}
+		user         = User{ID: 1}
+		doc          = NewDocument(&user)
+		modification = Apply(doc,
+			Map{
+				"transactions": []Map{
+					{"item": "item1"},
+					{"item": "item2"},
+				},
+			},
+		)
+		modifies = []map[string]Modify{
+			{"user_id": Set("user_id", user.ID), "item": Set("item", "item1")},
+			{"user_id": Set("user_id", user.ID), "item": Set("item", "item2")},
+		}
+		q   = Build("transactions")
+		err = errors.New("insert all error")
+	)
+
+	adapter.On("InsertAll", q, []string{"item", "user_id"}, modifies).Return(nil).Return([]interface{}{}, err).Maybe()
+	adapter.On("InsertAll", q, []string{"user_id", "item"}, modifies).Return(nil).Return([]interface{}{}, err).Maybe()
+
+	assert.Equal(t, err, repo.saveHasMany(context.TODO(), doc, &modification, true))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_saveHasMany_update(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		user    = User{
+			ID: 1,
+			Transactions: []Transaction{
+				{ID: 1, BuyerID: 1, Item: "item1"},
+				{ID: 2, BuyerID: 1, Item: "item2"},
+				{ID: 3, BuyerID: 1, Item: "item3"},
+			},
+		}
+		doc          = NewDocument(&user)
+		modification = Apply(doc,
+			Map{
+				"transactions": []Map{
+					{"id": 1, "item": "item1 updated"},
+					{"id": 2, "item": "item2 updated"},
+				},
+			},
+		)
+		modifies = []map[string]Modify{
+			{"item": Set("item", "item1 updated")},
+			{"item": Set("item", "item2 updated")},
+		}
+		q = Build("transactions")
+	)
+
+	modification.SetDeletedIDs("transactions", []interface{}{3})
+
+	adapter.On("Delete", q.Where(Eq("user_id", 1).AndIn("id", 3))).Return(1, nil).Once()
+	adapter.On("Update", q.Where(Eq("id", 1).AndEq("user_id", 1)), modifies[0]).Return(1, nil).Once()
+	adapter.On("Update", q.Where(Eq("id", 2).AndEq("user_id", 1)), modifies[1]).Return(1, nil).Once()
+
+	assert.Nil(t, repo.saveHasMany(context.TODO(), doc, &modification, false))
+	assert.Equal(t, User{
+		ID: 1,
+		Transactions: []Transaction{
+			{ID: 1, BuyerID: 1, Item: "item1 updated"},
+			{ID: 2, BuyerID: 1, Item: "item2 updated"},
+		},
+	}, user)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_saveHasMany_updateWithInsert(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		user    = User{
+			ID: 1,
+			Transactions: []Transaction{
+				{ID: 1, BuyerID: 1, Item: "item1"},
+			},
+		}
+		doc          = NewDocument(&user)
+		modification = Apply(doc,
+			Map{
+				"transactions": []Map{
+					{"id": 1, "item": "item1 updated"},
+					{"item": "new item", "user_id": 1},
+				},
+			},
+		)
+		q        = Build("transactions")
+		modifies = []map[string]Modify{
+			{"item": Set("item", "item1 updated")},
+			{"user_id": Set("user_id", user.ID), "item": Set("item", "new item")},
+		}
+	)
+
+	adapter.On("Update", q.Where(Eq("id", 1).AndEq("user_id", 1)), modifies[0]).Return(1, nil).Once()
+	adapter.On("InsertAll", q, []string{"item", "user_id"}, modifies[1:]).Return(nil).Return([]interface{}{2}, nil).Maybe()
+	adapter.On("InsertAll", q, []string{"user_id", "item"}, modifies[1:]).Return(nil).Return([]interface{}{2}, nil).Maybe()
+
+	assert.Nil(t, repo.saveHasMany(context.TODO(), doc, &modification, false))
+	assert.Equal(t, User{
+		ID: 1,
+		Transactions: []Transaction{
+			{ID: 1, BuyerID: 1, Item: "item1 updated"},
+			{ID: 2, BuyerID: 1, Item: "new item"},
+		},
+	}, user)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_saveHasMany_deleteWithInsert(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		user    = User{
+			ID: 1,
+			Transactions: []Transaction{
+				{ID: 1, Item: "item1"},
+				{ID: 2, Item: "item2"},
+			},
+		}
+		doc          = NewDocument(&user)
+		modification = Apply(doc,
+			Map{
+				"transactions": []Map{
+					{"item": "item3"},
+					{"item": "item4"},
+					{"item": "item5"},
+				},
+			},
+		)
+		modifies = []map[string]Modify{
+			{"user_id": Set("user_id", user.ID), "item": Set("item", "item3")},
+			{"user_id": Set("user_id", user.ID), "item": Set("item", "item4")},
+			{"user_id": Set("user_id", user.ID), "item": Set("item", "item5")},
+		}
+		q = Build("transactions")
+	)
+
+	adapter.On("Delete", q.Where(Eq("user_id", 1).AndIn("id", 1, 2))).Return(1, nil).Once()
+	adapter.On("InsertAll", q, []string{"item", "user_id"}, modifies).Return(nil).Return([]interface{}{3, 4, 5}, nil).Maybe()
+	adapter.On("InsertAll", q, []string{"user_id", "item"}, modifies).Return(nil).Return([]interface{}{3, 4, 5}, nil).Maybe()
+
+	assert.Nil(t, repo.saveHasMany(context.TODO(), doc, &modification, false))
+	assert.Equal(t, User{
+		ID: 1,
+		Transactions: []Transaction{
+			{ID: 3, BuyerID: 1, Item: "item3"},
+			{ID: 4, BuyerID: 1, Item: "item4"},
+			{ID: 5, BuyerID: 1, Item: "item5"},
+		},
+	}, user)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_saveHasMany_replace(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		user    = User{
+			ID: 1,
+			Transactions: []Transaction{
+				{Item: "item3"},
+				{Item: "item4"},
+				{Item: "item5"},
+			},
+		}
+		doc          = NewDocument(&user)
+		modification = Apply(doc, NewStructset(doc, false))
+		modifies     = []map[string]Modify{
+			{"user_id": Set("user_id", user.ID), "status": Set("status", Status("")), "item": Set("item", "item3")},
+			{"user_id": Set("user_id", user.ID), "status": Set("status", Status("")), "item": Set("item", "item4")},
+			{"user_id": Set("user_id", user.ID), "status": Set("status", Status("")), "item": Set("item", "item5")},
+		}
+		q = Build("transactions")
+	)
+
+	adapter.On("Delete", q.Where(Eq("user_id", 1))).Return(1, nil).Once()
+	adapter.On("InsertAll", q, mock.Anything, modifies).Return(nil).Return([]interface{}{3, 4, 5}, nil).Once()
+
+	assert.Nil(t, repo.saveHasMany(context.TODO(), doc, &modification, false))
+	assert.Equal(t, User{
+		ID:        1,
+		CreatedAt: now(),
+		UpdatedAt: now(),
+		Transactions: []Transaction{
+			{ID: 3, BuyerID: 1, Item: "item3"},
+			{ID: 4, BuyerID: 1, Item: "item4"},
+			{ID: 5, BuyerID: 1, Item: "item5"},
+		},
+	}, user)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_saveHasMany_diff(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		user    = User{
+			ID: 1,
+			Transactions: []Transaction{
+				{ID: 1, BuyerID: 1, Item: "item1"},
+				{Item: "item2"},
+				{ID: 3, BuyerID: 1, Item: "item3"},
+			},
+		}
+		doc          = NewDocument(&user)
+		modification = Apply(doc, NewStructset(doc, false))
+		q            = Build("transactions")
+	)
+
+	adapter.On("Delete", q.Where(Eq("user_id", 1).AndNin("id", 1, 3))).Return(1, nil).Once()
+	adapter.On("Update", q.Where(Eq("id", 1).AndEq("user_id", 1)), mock.Anything).Return(1, nil).Once()
+	adapter.On("Update", q.Where(Eq("id", 3).AndEq("user_id", 1)), mock.Anything).Return(1, nil).Once()
+	adapter.On("InsertAll", q, mock.Anything, mock.Anything).Return([]interface{}{4}, nil).Once()
+
+	assert.Nil(t, repo.saveHasMany(context.TODO(), doc, &modification, false))
+	assert.Equal(t, []Transaction{
+		{ID: 1, BuyerID: 1, Item: "item1"},
+		{ID: 3, BuyerID: 1, Item: "item3"},
+		{ID: 4, BuyerID: 1, Item: "item2"},
+	}, user.Transactions)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_saveHasMany_replaceDeleteAllError(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		user    = User{
+			ID: 1,
+			Transactions: []Transaction{
+				{ID: 1, Item: "item1"},
+				{ID: 2, Item: "item2"},
+			},
+		}
+		doc          = NewDocument(&user)
+		modification = Apply(doc, NewStructset(doc, false), Replace("transactions", ReplaceDelete))
+		q            = Build("transactions")
+		err          = errors.New("delete all error")
+	)
+
+	adapter.On("Delete", q.Where(Eq("user_id", 1))).Return(0, err).Once()
+
+	assert.Equal(t, err, repo.saveHasMany(context.TODO(), doc, &modification, false))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_saveHasMany_replaceNullify(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		user    = User{
+			ID: 1,
+			Transactions: []Transaction{
+				{ID: 1, BuyerID: 1, Item: "item1"},
+				{Item: "item2"},
+			},
+		}
+		doc          = NewDocument(&user)
+		modification = Apply(doc, NewStructset(doc, false), Replace("transactions", ReplaceNullify))
+		q            = Build("transactions")
+	)
+
+	adapter.On("Update", q.Where(Eq("user_id", 1).AndNin("id", 1)), map[string]Modify{"user_id": Set("user_id", nil)}).Return(1, nil).Once()
+	adapter.On("Update", q.Where(Eq("id", 1).AndEq("user_id", 1)), mock.Anything).Return(1, nil).Once()
+	adapter.On("InsertAll", q, mock.Anything, mock.Anything).Return([]interface{}{2}, nil).Once()
+
+	assert.Nil(t, repo.saveHasMany(context.TODO(), doc, &modification, false))
+	assert.Equal(t, []Transaction{
+		{ID: 1, BuyerID: 1, Item: "item1"},
+		{ID: 2, BuyerID: 1, Item: "item2"},
+	}, user.Transactions)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_saveHasMany_replaceNullifyDeletedIDs(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		user    = User{
+			ID: 1,
+			Transactions: []Transaction{
+				{ID: 1, BuyerID: 1, Item: "item1"},
+			},
+		}
+		doc          = NewDocument(&user)
+		modification = Apply(doc,
+			Map{
+				"transactions": []Map{
+					{"id": 1, "item": "item1 updated"},
+				},
+			},
+			Replace("transactions", ReplaceNullify),
+		)
+		q = Build("transactions")
+	)
+
+	modification.SetDeletedIDs("transactions", []interface{}{2, 3})
+
+	adapter.On("Update", q.Where(Eq("user_id", 1).AndIn("id", 2, 3)), map[string]Modify{"user_id": Set("user_id", nil)}).Return(2, nil).Once()
+	adapter.On("Update", q.Where(Eq("id", 1).AndEq("user_id", 1)), map[string]Modify{"item": Set("item", "item1 updated")}).Return(1, nil).Once()
+
+	assert.Nil(t, repo.saveHasMany(context.TODO(), doc, &modification, false))
+	assert.Equal(t, "item1 updated", user.Transactions[0].Item)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_saveHasMany_replaceRestrict(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		user    = User{
+			ID: 1,
+			Transactions: []Transaction{
+				{ID: 1, BuyerID: 1, Item: "item1"},
+			},
+		}
+		doc          = NewDocument(&user)
+		modification = Apply(doc, NewStructset(doc, false), Replace("transactions", ReplaceRestrict))
+		q            = Build("transactions")
+	)
+
+	adapter.On("Aggregate", q.Where(Eq("user_id", 1).AndNin("id", 1)), "count", "*").Return(0, nil).Once()
+	adapter.On("Update", q.Where(Eq("id", 1).AndEq("user_id", 1)), mock.Anything).Return(1, nil).Once()
+
+	assert.Nil(t, repo.saveHasMany(context.TODO(), doc, &modification, false))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_saveHasMany_replaceRestrictError(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		user    = User{
+			ID: 1,
+			Transactions: []Transaction{
+				{Item: "item1"},
+			},
+		}
+		doc          = NewDocument(&user)
+		modification = Apply(doc, NewStructset(doc, false), Replace("transactions", ReplaceRestrict))
+		q            = Build("transactions")
+		err          = errors.New("aggregate error")
+	)
+
+	adapter.On("Aggregate", q.Where(Eq("user_id", 1)), "count", "*").Return(2, nil).Once()
+
+	assert.Equal(t, ConstraintError{
+		Key:  "user_id",
+		Type: ForeignKeyConstraint,
+		Err:  errors.New("rel: has many association is restricted"),
+	}, repo.saveHasMany(context.TODO(), doc, &modification, false))
+
+	adapter.On("Aggregate", q.Where(Eq("user_id", 1)), "count", "*").Return(0, err).Once()
+
+	modification = Apply(doc, NewStructset(doc, false), Replace("transactions", ReplaceRestrict))
+	assert.Equal(t, err, repo.saveHasMany(context.TODO(), doc, &modification, false))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_saveHasMany_replaceDiff(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		user    = User{
+			ID: 1,
+			Transactions: []Transaction{
+				{ID: 1, BuyerID: 1, Item: "item1"},
+				{ID: 2, BuyerID: 1, Item: "item2"},
+			},
+		}
+		doc          = NewDocument(&user)
+		modification = Apply(doc, NewStructset(doc, false), Replace("transactions", ReplaceDiff))
+		q            = Build("transactions")
+	)
+
+	adapter.On("Delete", q.Where(Eq("user_id", 1).AndNin("id", 1, 2))).Return(1, nil).Once()
+	adapter.On("Update", q.Where(Eq("id", 1).AndEq("user_id", 1)), mock.Anything).Return(1, nil).Once()
+	adapter.On("Update", q.Where(Eq("id", 2).AndEq("user_id", 1)), mock.Anything).Return(1, nil).Once()
+
+	assert.Nil(t, repo.saveHasMany(context.TODO(), doc, &modification, false))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_saveHasMany_invalidModifier(t *testing.T) {
+	var (
+		adapter      = &testAdapter{}
+		repo         = repository{adapter: adapter}
+		user         = User{ID: 1}
+		doc          = NewDocument(&user)
+		modification = Apply(NewDocument(&User{}),
+			Map{
+				"transactions": []Map{
+					{"item": "item3"},
+				},
+			},
+		)
+	)
+
+	assert.PanicsWithValue(t, "rel: invalid modifier", func() {
+		repo.saveHasMany(context.TODO(), doc, &modification, false)
+	})
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Delete(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		user    = User{ID: 1}
+	)
+
+	adapter.On("Delete", From("users").Where(Eq("id", user.ID))).Return(1, nil).Once()
+
+	assert.Nil(t, repo.Delete(context.TODO(), &user))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Delete_softDelete(t *testing.T) {
+	var (
+		adapter  = &testAdapter{}
+		repo     = repository{adapter: adapter}
+		address  = Address{ID: 1}
+		query    = From("addresses").Where(Eq("id", address.ID))
+		modifies = map[string]Modify{
+			"deleted_at": Set("deleted_at", now()),
+		}
+	)
+
+	adapter.On("Update", query, modifies).Return(1, nil).Once()
+
+	assert.Nil(t, repo.Delete(context.TODO(), &address))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_MustDelete(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		user    = User{ID: 1}
+	)
+
+	adapter.On("Delete", From("users").Where(Eq("id", user.ID))).Return(1, nil).Once()
+
+	assert.NotPanics(t, func() {
+		repo.MustDelete(context.TODO(), &user)
+	})
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_DeleteAll(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		queries = From("logs").Where(Eq("user_id", 1))
+	)
+
+	adapter.On("Delete", From("logs").Where(Eq("user_id", 1))).Return(1, nil).Once()
+
+	assert.Nil(t, repo.DeleteAll(context.TODO(), queries))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_MustDeleteAll(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		queries = From("logs").Where(Eq("user_id", 1))
+	)
+
+	adapter.On("Delete", From("logs").Where(Eq("user_id", 1))).Return(1, nil).Once()
+
+	assert.NotPanics(t, func() {
+		repo.MustDeleteAll(context.TODO(), queries)
+	})
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_DeleteAny(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		query   = From("logs").Where(Eq("user_id", 1))
+	)
+
+	adapter.On("Delete", query).Return(2, nil).Once()
+
+	deletedCount, err := repo.DeleteAny(context.TODO(), query)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, deletedCount)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_MustDeleteAny(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		query   = From("logs").Where(Eq("user_id", 1))
+	)
+
+	adapter.On("Delete", query).Return(2, nil).Once()
+
+	assert.NotPanics(t, func() {
+		assert.Equal(t, 2, repo.MustDeleteAny(context.TODO(), query))
+	})
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_UpdateAny(t *testing.T) {
+	var (
+		adapter  = &testAdapter{}
+		repo     = repository{adapter: adapter}
+		query    = From("users").Where(Eq("active", false))
+		modifies = map[string]Modify{
+			"status": Set("status", "inactive"),
+			"age":    Inc("age"),
+		}
+	)
+
+	adapter.On("Update", query, modifies).Return(3, nil).Once()
+
+	updatedCount, err := repo.UpdateAny(context.TODO(), query, Set("status", "inactive"), Inc("age"))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, updatedCount)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_UpdateAny_noModifies(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+	)
+
+	updatedCount, err := repo.UpdateAny(context.TODO(), From("users"))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, updatedCount)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_MustUpdateAny(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		query   = From("users").Where(Eq("active", false))
+	)
+
+	adapter.On("Update", query, map[string]Modify{"status": Set("status", "inactive")}).Return(3, nil).Once()
+
+	assert.NotPanics(t, func() {
+		assert.Equal(t, 3, repo.MustUpdateAny(context.TODO(), query, Set("status", "inactive")))
+	})
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Preload_hasOne(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		user    = User{ID: 10}
+		address = Address{ID: 100, UserID: &user.ID}
+		cur     = &testCursor{}
+	)
+
+	adapter.On("Query", From("addresses").Where(In("user_id", 10).AndNil("deleted_at"))).Return(cur, nil).Once()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(address.ID, *address.UserID).Times(2)
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &user, "address"))
+	assert.Equal(t, address, user.Address)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_sliceHasOne(t *testing.T) {
+	var (
+		adapter   = &testAdapter{}
+		repo      = repository{adapter: adapter}
+		users     = []User{{ID: 10}, {ID: 20}}
+		addresses = []Address{
+			{ID: 100, UserID: &users[0].ID},
+			{ID: 200, UserID: &users[1].ID},
+		}
+		cur = &testCursor{}
+	)
+
+	// one of these, because of map ordering
+	adapter.On("Query", From("addresses").Where(In("user_id", 10, 20).AndNil("deleted_at"))).Return(cur, nil).Maybe()
+	adapter.On("Query", From("addresses").Where(In("user_id", 20, 10).AndNil("deleted_at"))).Return(cur, nil).Maybe()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Next").Return(true).Twice()
+	cur.MockScan(addresses[0].ID, *addresses[0].UserID).Twice()
+	cur.MockScan(addresses[1].ID, *addresses[1].UserID).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &users, "address"))
+	assert.Equal(t, addresses[0], users[0].Address)
+	assert.Equal(t, addresses[1], users[1].Address)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_nestedHasOne(t *testing.T) {
+	var (
+		adapter     = &testAdapter{}
+		repo        = repository{adapter: adapter}
+		transaction = Transaction{
+			Buyer: User{ID: 10},
+		}
+		address = Address{ID: 100, UserID: &transaction.Buyer.ID}
+		cur     = &testCursor{}
+	)
+
+	adapter.On("Query", From("addresses").Where(In("user_id", 10).AndNil("deleted_at"))).Return(cur, nil).Once()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(address.ID, *address.UserID).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &transaction, "buyer.address"))
+	assert.Equal(t, address, transaction.Buyer.Address)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_sliceNestedHasOne(t *testing.T) {
+	var (
+		adapter      = &testAdapter{}
+		repo         = repository{adapter: adapter}
+		transactions = []Transaction{
+			{Buyer: User{ID: 10}},
+			{Buyer: User{ID: 20}},
+		}
+		addresses = []Address{
+			{ID: 100, UserID: &transactions[0].Buyer.ID},
+			{ID: 200, UserID: &transactions[1].Buyer.ID},
+		}
+		cur = &testCursor{}
+	)
+
+	// one of these, because of map ordering
+	adapter.On("Query", From("addresses").Where(In("user_id", 10, 20).AndNil("deleted_at"))).Return(cur, nil).Maybe()
+	adapter.On("Query", From("addresses").Where(In("user_id", 20, 10).AndNil("deleted_at"))).Return(cur, nil).Maybe()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Next").Return(true).Twice()
+	cur.MockScan(addresses[0].ID, *addresses[0].UserID).Twice()
+	cur.MockScan(addresses[1].ID, *addresses[1].UserID).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &transactions, "buyer.address"))
+	assert.Equal(t, addresses[0], transactions[0].Buyer.Address)
+	assert.Equal(t, addresses[1], transactions[1].Buyer.Address)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_hasMany(t *testing.T) {
+	var (
+		adapter      = &testAdapter{}
+		repo         = repository{adapter: adapter}
+		user         = User{ID: 10}
+		transactions = []Transaction{
+			{ID: 5, BuyerID: 10},
+			{ID: 10, BuyerID: 10},
+		}
+		cur = &testCursor{}
+	)
+
+	adapter.On("Query", From("transactions").Where(In("user_id", 10))).Return(cur, nil).Once()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Next").Return(true).Twice()
+	cur.MockScan(transactions[0].ID, transactions[0].BuyerID).Twice()
+	cur.MockScan(transactions[1].ID, transactions[1].BuyerID).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &user, "transactions"))
+	assert.Equal(t, transactions, user.Transactions)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_selfReferencing(t *testing.T) {
+	var (
+		adapter    = &testAdapter{}
+		repo       = repository{adapter: adapter}
+		parentID   = 1
+		categories = []Category{{ID: 2, ParentID: &parentID}, {ID: 3, ParentID: &parentID}}
+		parentCur  = &testCursor{}
+		childCur   = &testCursor{}
+	)
+
+	adapter.On("Query", From("categories").Where(In("id", 1))).Return(parentCur, nil).Once()
+	adapter.On("Query", From("categories").Where(In("parent_id", 2, 3))).Return(childCur, nil).Once()
+
+	parentCur.On("Close").Return(nil).Once()
+	parentCur.On("Fields").Return([]string{"id", "name"}, nil).Once()
+	parentCur.On("Next").Return(true).Once()
+	parentCur.MockScan(1, "root").Times(3)
+	parentCur.On("Next").Return(false).Once()
+
+	childCur.On("Close").Return(nil).Once()
+	childCur.On("Fields").Return([]string{"id", "parent_id"}, nil).Once()
+	childCur.On("Next").Return(true).Once()
+	childCur.MockScan(4, 3).Twice()
+	childCur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &categories, "parent"))
+	assert.Nil(t, repo.Preload(context.TODO(), &categories, "children"))
+
+	childParentID := 3
+	assert.Equal(t, &Category{ID: 1, Name: "root"}, categories[0].Parent)
+	assert.Equal(t, &Category{ID: 1, Name: "root"}, categories[1].Parent)
+	assert.Equal(t, []Category{}, categories[0].Children)
+	assert.Equal(t, []Category{{ID: 4, ParentID: &childParentID}}, categories[1].Children)
+
+	adapter.AssertExpectations(t)
+	parentCur.AssertExpectations(t)
+	childCur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_sliceHasMany(t *testing.T) {
+	var (
+		adapter      = &testAdapter{}
+		repo         = repository{adapter: adapter}
+		users        = []User{{ID: 10}, {ID: 20}}
+		transactions = []Transaction{
+			{ID: 5, BuyerID: 10},
+			{ID: 10, BuyerID: 10},
+			{ID: 15, BuyerID: 20},
+			{ID: 20, BuyerID: 20},
+		}
+		cur = &testCursor{}
+	)
+
+	adapter.On("Query", From("transactions").Where(In("user_id", 10, 20))).Return(cur, nil).Maybe()
+	adapter.On("Query", From("transactions").Where(In("user_id", 20, 10))).Return(cur, nil).Maybe()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Next").Return(true).Times(4)
+	cur.MockScan(transactions[0].ID, transactions[0].BuyerID).Twice()
+	cur.MockScan(transactions[1].ID, transactions[1].BuyerID).Twice()
+	cur.MockScan(transactions[2].ID, transactions[2].BuyerID).Twice()
+	cur.MockScan(transactions[3].ID, transactions[3].BuyerID).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &users, "transactions"))
+	assert.Equal(t, transactions[:2], users[0].Transactions)
+	assert.Equal(t, transactions[2:], users[1].Transactions)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_limit(t *testing.T) {
+	var (
+		adapter      = &testAdapter{}
+		repo         = repository{adapter: adapter}
+		users        = []User{{ID: 10}, {ID: 20}}
+		transactions = []Transaction{
+			{ID: 10, BuyerID: 10},
+			{ID: 5, BuyerID: 10},
+			{ID: 20, BuyerID: 20},
+		}
+		cur = &testCursor{}
+	)
+
+	adapter.On("Query", From("transactions").Where(In("user_id", 10, 20)).Partition("user_id", 2).SortDesc("id")).Return(cur, nil).Maybe()
+	adapter.On("Query", From("transactions").Where(In("user_id", 20, 10)).Partition("user_id", 2).SortDesc("id")).Return(cur, nil).Maybe()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Next").Return(true).Times(3)
+	cur.MockScan(transactions[0].ID, transactions[0].BuyerID).Twice()
+	cur.MockScan(transactions[1].ID, transactions[1].BuyerID).Twice()
+	cur.MockScan(transactions[2].ID, transactions[2].BuyerID).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &users, "transactions", PreloadLimit(2), NewSortDesc("id")))
+	assert.Equal(t, transactions[:2], users[0].Transactions)
+	assert.Equal(t, transactions[2:], users[1].Transactions)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_limitWithoutWindow(t *testing.T) {
+	var (
+		adapter      = &testAdapter{unsupported: WindowCapability}
+		repo         = repository{adapter: adapter}
+		users        = []User{{ID: 10}, {ID: 20}}
+		transactions = []Transaction{
+			{ID: 20, BuyerID: 10},
+			{ID: 15, BuyerID: 10},
+			{ID: 10, BuyerID: 10},
+			{ID: 5, BuyerID: 20},
+		}
+		cur = &testCursor{}
+	)
+
+	adapter.On("Query", From("transactions").Where(In("user_id", 10, 20)).SortDesc("id")).Return(cur, nil).Maybe()
+	adapter.On("Query", From("transactions").Where(In("user_id", 20, 10)).SortDesc("id")).Return(cur, nil).Maybe()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Next").Return(true).Times(4)
+	cur.MockScan(transactions[0].ID, transactions[0].BuyerID).Twice()
+	cur.MockScan(transactions[1].ID, transactions[1].BuyerID).Twice()
+	cur.MockScan(transactions[2].ID, transactions[2].BuyerID).Once()
+	cur.MockScan(transactions[3].ID, transactions[3].BuyerID).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &users, "transactions", PreloadLimit(2), NewSortDesc("id")))
+	assert.Equal(t, transactions[:2], users[0].Transactions)
+	assert.Equal(t, transactions[3:], users[1].Transactions)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_limitBelongsTo(t *testing.T) {
+	var (
+		adapter      = &testAdapter{}
+		repo         = repository{adapter: adapter}
+		transactions = []Transaction{{ID: 1, BuyerID: 10}, {ID: 2, BuyerID: 10}}
+		cur          = &testCursor{}
+	)
+
+	adapter.On("Query", From("users").Where(In("id", 10))).Return(cur, nil).Once()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "name"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(10, "Del Piero").Times(3)
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &transactions, "buyer", PreloadLimit(1)))
+	assert.Equal(t, User{ID: 10, Name: "Del Piero"}, transactions[0].Buyer)
+	assert.Equal(t, User{ID: 10, Name: "Del Piero"}, transactions[1].Buyer)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_nestedHasMany(t *testing.T) {
+	var (
+		adapter      = &testAdapter{}
+		repo         = repository{adapter: adapter}
+		address      = Address{User: &User{ID: 10}}
+		transactions = []Transaction{
+			{ID: 5, BuyerID: 10},
+			{ID: 10, BuyerID: 10},
+		}
+
+		cur = &testCursor{}
+	)
+
+	adapter.On("Query", From("transactions").Where(In("user_id", 10))).Return(cur, nil).Once()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Next").Return(true).Twice()
+	cur.MockScan(transactions[0].ID, transactions[0].BuyerID).Twice()
+	cur.MockScan(transactions[1].ID, transactions[1].BuyerID).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &address, "user.transactions"))
+	assert.Equal(t, transactions, address.User.Transactions)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_PreloadAll(t *testing.T) {
+	var (
+		adapter        = &testAdapter{}
+		repo           = repository{adapter: adapter}
+		users          = []User{{ID: 10}, {ID: 20}}
+		addressCur     = &testCursor{}
+		transactionCur = &testCursor{}
+	)
+
+	adapter.On("Query", From("addresses").Where(In("user_id", 10, 20), Nil("deleted_at"))).Return(addressCur, nil).Maybe()
+	adapter.On("Query", From("addresses").Where(In("user_id", 20, 10), Nil("deleted_at"))).Return(addressCur, nil).Maybe()
+	adapter.On("Query", From("transactions").Where(In("user_id", 10, 20))).Return(transactionCur, nil).Maybe()
+	adapter.On("Query", From("transactions").Where(In("user_id", 20, 10))).Return(transactionCur, nil).Maybe()
+
+	addressCur.On("Close").Return(nil).Once()
+	addressCur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	addressCur.On("Next").Return(true).Once()
+	addressCur.MockScan(1, 20).Twice()
+	addressCur.On("Next").Return(false).Once()
+
+	transactionCur.On("Close").Return(nil).Once()
+	transactionCur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	transactionCur.On("Next").Return(true).Once()
+	transactionCur.MockScan(5, 10).Twice()
+	transactionCur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.PreloadAll(context.TODO(), &users, "address", "transactions"))
+	assert.Equal(t, Address{}, users[0].Address)
+	assert.Equal(t, []Transaction{{ID: 5, BuyerID: 10}}, users[0].Transactions)
+	assert.Equal(t, 1, users[1].Address.ID)
+	assert.Equal(t, []Transaction{}, users[1].Transactions)
+
+	adapter.AssertExpectations(t)
+	addressCur.AssertExpectations(t)
+	transactionCur.AssertExpectations(t)
+}
+
+func TestRepository_PreloadAll_sameAssociation(t *testing.T) {
+	var (
+		adapter     = &testAdapter{}
+		repo        = repository{adapter: adapter}
+		transaction = Transaction{BuyerID: 10}
+		buyerCur    = &testCursor{}
+		addressCur  = &testCursor{}
+	)
+
+	adapter.On("Query", From("users").Where(In("id", 10))).Return(buyerCur, nil).Once()
+	adapter.On("Query", From("addresses").Where(In("user_id", 10), Nil("deleted_at"))).Return(addressCur, nil).Once()
+
+	buyerCur.On("Close").Return(nil).Once()
+	buyerCur.On("Fields").Return([]string{"id"}, nil).Once()
+	buyerCur.On("Next").Return(true).Once()
+	buyerCur.MockScan(10).Twice()
+	buyerCur.On("Next").Return(false).Once()
+
+	addressCur.On("Close").Return(nil).Once()
+	addressCur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	addressCur.On("Next").Return(true).Once()
+	addressCur.MockScan(1, 10).Twice()
+	addressCur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.PreloadAll(context.TODO(), &transaction, "buyer", "buyer.address"))
+	assert.Equal(t, 10, transaction.Buyer.ID)
+	assert.Equal(t, 1, transaction.Buyer.Address.ID)
+
+	adapter.AssertExpectations(t)
+	buyerCur.AssertExpectations(t)
+	addressCur.AssertExpectations(t)
+}
+
+func TestRepository_PreloadAll_error(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		user    = User{ID: 10}
+		cur     = &testCursor{}
+		err     = errors.New("error")
+	)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Next").Return(false).Once()
+
+	adapter.On("Query", From("addresses").Where(In("user_id", 10), Nil("deleted_at"))).Return(cur, nil).Once()
+	adapter.On("Query", From("transactions").Where(In("user_id", 10))).Return(&testCursor{}, err).Once()
+
+	assert.Equal(t, err, repo.PreloadAll(context.TODO(), &user, "address", "transactions"))
+	assert.Panics(t, func() {
+		repo.MustPreloadAll(context.TODO(), &user, "transactions")
+	})
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_PreloadAll_transaction(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter, inTransaction: true}
+		user    = User{ID: 10}
+		err     = errors.New("error")
+	)
+
+	adapter.On("Query", From("transactions").Where(In("user_id", 10))).Return(&testCursor{}, err).Once()
+
+	assert.Equal(t, err, repo.PreloadAll(context.TODO(), &user, "transactions", "address"))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_PreloadAll_parallelism(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter, preloadParallelism: 1}
+		user    = User{ID: 10}
+		err     = errors.New("error")
+	)
+
+	adapter.On("Query", From("transactions").Where(In("user_id", 10))).Return(&testCursor{}, err).Once()
+
+	assert.Equal(t, err, repo.PreloadAll(context.TODO(), &user, "transactions", "address"))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_SetPreloadParallelism(t *testing.T) {
+	var (
+		repo = New(&testAdapter{})
+	)
+
+	assert.Equal(t, 0, repo.(*repository).preloadParallelism)
+
+	repo.SetPreloadParallelism(2)
+	assert.Equal(t, 2, repo.(*repository).preloadParallelism)
+}
+
+func TestRepository_SetInChunkSize(t *testing.T) {
+	var (
+		repo = New(&testAdapter{})
+	)
+
+	assert.Equal(t, 0, repo.(*repository).inChunkSize)
+
+	repo.SetInChunkSize(1000)
+	assert.Equal(t, 1000, repo.(*repository).inChunkSize)
+}
+
+func TestRepository_concurrently(t *testing.T) {
+	var (
+		repo    = repository{preloadParallelism: 2}
+		running int32
+		peak    int32
+		called  = make([]bool, 6)
+		err     = errors.New("error")
+	)
+
+	assert.Equal(t, err, repo.concurrently(len(called), func(i int) error {
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+
+		for {
+			max := atomic.LoadInt32(&peak)
+			if current <= max || atomic.CompareAndSwapInt32(&peak, max, current) {
+				break
+			}
+		}
+
+		time.Sleep(5 * time.Millisecond)
+		called[i] = true
+
+		if i >= 3 {
+			return err
+		}
+
+		return nil
+	}))
+
+	assert.Equal(t, []bool{true, true, true, true, true, true}, called)
+	assert.LessOrEqual(t, peak, int32(2))
+}
+
+func TestRepository_PreloadMap(t *testing.T) {
+	var (
+		adapter      = &testAdapter{}
+		repo         = repository{adapter: adapter}
+		users        = []User{{ID: 10}, {ID: 20}, {ID: 30}}
+		transactions = []Transaction{
+			{ID: 5, BuyerID: 10},
+			{ID: 10, BuyerID: 10},
+			{ID: 15, BuyerID: 20},
+		}
+		cur = &testCursor{}
+	)
+
+	adapter.On("Query", mock.MatchedBy(func(query Query) bool {
+		return query.Table == "transactions"
+	})).Return(cur, nil).Once()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Next").Return(true).Times(3)
+	cur.MockScan(transactions[0].ID, transactions[0].BuyerID).Twice()
+	cur.MockScan(transactions[1].ID, transactions[1].BuyerID).Twice()
+	cur.MockScan(transactions[2].ID, transactions[2].BuyerID).Twice()
+	cur.On("Next").Return(false).Once()
+
+	result, err := repo.PreloadMap(context.TODO(), &users, "transactions")
+	assert.Nil(t, err)
+	assert.Equal(t, map[interface{}]interface{}{
+		10: transactions[:2],
+		20: transactions[2:],
+		30: []Transaction{},
+	}, result)
+	assert.Equal(t, transactions[:2], users[0].Transactions)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_PreloadMap_belongsTo(t *testing.T) {
+	var (
+		adapter     = &testAdapter{}
+		repo        = repository{adapter: adapter}
+		user        = User{ID: 10, Name: "Del Piero"}
+		transaction = Transaction{ID: 1, BuyerID: 10}
+		cur         = &testCursor{}
+	)
+
+	adapter.On("Query", From("users").Where(In("id", 10))).Return(cur, nil).Once()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "name"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(user.ID, user.Name).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.NotPanics(t, func() {
+		assert.Equal(t, map[interface{}]interface{}{1: user}, repo.MustPreloadMap(context.TODO(), &transaction, "buyer"))
+	})
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_PreloadMap_error(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		user    = User{ID: 10}
+		err     = errors.New("error")
+	)
+
+	adapter.On("Query", From("transactions").Where(In("user_id", 10))).Return(&testCursor{}, err).Once()
+
+	result, rerr := repo.PreloadMap(context.TODO(), &user, "transactions")
+	assert.Equal(t, err, rerr)
+	assert.Nil(t, result)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_PreloadMap_nested(t *testing.T) {
+	var (
+		repo = repository{adapter: &testAdapter{}}
+		user = User{ID: 10}
+	)
+
+	assert.PanicsWithValue(t, "rel: preload map doesn't support nested association (transactions.buyer)", func() {
+		repo.PreloadMap(context.TODO(), &user, "transactions.buyer")
+	})
+}
+
+func TestRepository_Preload_nestedUnloaded(t *testing.T) {
+	var (
+		adapter      = &testAdapter{}
+		repo         = repository{adapter: adapter}
+		user         = User{ID: 10}
+		buyer        = User{ID: 10, Name: "buyer"}
+		transactions = []Transaction{
+			{ID: 5, BuyerID: 10},
+			{ID: 10, BuyerID: 10},
+		}
+		transactionCur = &testCursor{}
+		userCur        = &testCursor{}
+	)
+
+	adapter.On("Query", From("transactions").Where(In("user_id", 10))).Return(transactionCur, nil).Once()
+	adapter.On("Query", From("users").Where(In("id", 10)).Select("id", "name")).Return(userCur, nil).Once()
+
+	transactionCur.On("Close").Return(nil).Once()
+	transactionCur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	transactionCur.On("Next").Return(true).Twice()
+	transactionCur.MockScan(transactions[0].ID, transactions[0].BuyerID).Twice()
+	transactionCur.MockScan(transactions[1].ID, transactions[1].BuyerID).Twice()
+	transactionCur.On("Next").Return(false).Once()
+
+	userCur.On("Close").Return(nil).Once()
+	userCur.On("Fields").Return([]string{"id", "name"}, nil).Once()
+	userCur.On("Next").Return(true).Once()
+	userCur.MockScan(buyer.ID, buyer.Name).Times(3)
+	userCur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &user, "transactions.buyer", Select("id", "name")))
+	assert.Len(t, user.Transactions, 2)
+	assert.Equal(t, buyer, user.Transactions[0].Buyer)
+	assert.Equal(t, buyer, user.Transactions[1].Buyer)
+
+	adapter.AssertExpectations(t)
+	transactionCur.AssertExpectations(t)
+	userCur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_nestedUnloadedError(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		user    = User{ID: 10}
+		err     = errors.New("error")
+	)
+
+	adapter.On("Query", From("transactions").Where(In("user_id", 10))).Return(&testCursor{}, err).Once()
+
+	assert.Equal(t, err, repo.Preload(context.TODO(), &user, "transactions.buyer"))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Preload_query(t *testing.T) {
+	var (
+		adapter      = &testAdapter{}
+		repo         = repository{adapter: adapter}
+		user         = User{ID: 10}
+		transactions = []Transaction{
+			{ID: 10, Item: "book", BuyerID: 10},
+			{ID: 5, Item: "pen", BuyerID: 10},
+		}
+		cur = &testCursor{}
+	)
+
+	adapter.On("Query", From("transactions").Where(Eq("status", "paid"), In("user_id", 10)).SortDesc("id").Select("id", "item", "user_id")).Return(cur, nil).Once()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "item", "user_id"}, nil).Once()
+	cur.On("Next").Return(true).Twice()
+	cur.MockScan(transactions[0].ID, transactions[0].Item, transactions[0].BuyerID).Twice()
+	cur.MockScan(transactions[1].ID, transactions[1].Item, transactions[1].BuyerID).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &user, "transactions", Where(Eq("status", "paid")).SortDesc("id"), Select("id", "item")))
+	assert.Equal(t, transactions, user.Transactions)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_manyToMany(t *testing.T) {
+	var (
+		adapter   = &testAdapter{}
+		repo      = repository{adapter: adapter}
+		students  = []Student{{ID: 1}, {ID: 2}}
+		algebra   = Course{ID: 20, Title: "Algebra"}
+		biology   = Course{ID: 10, Title: "Biology"}
+		joinCur   = &testCursor{}
+		courseCur = &testCursor{}
+	)
+
+	adapter.On("Query", From("enrollments").Select("student_id", "course_id").Where(In("student_id", 1, 2))).Return(joinCur, nil).Maybe()
+	adapter.On("Query", From("enrollments").Select("student_id", "course_id").Where(In("student_id", 2, 1))).Return(joinCur, nil).Maybe()
+	adapter.On("Query", From("courses").Where(In("id", 10, 20)).SortAsc("title")).Return(courseCur, nil).Once()
+
+	joinCur.On("Close").Return(nil).Once()
+	joinCur.On("Fields").Return([]string{"student_id", "course_id"}, nil).Once()
+	joinCur.On("Next").Return(true).Times(3)
+	joinCur.MockScan(1, 10).Once()
+	joinCur.MockScan(2, 10).Once()
+	joinCur.MockScan(1, 20).Once()
+	joinCur.On("Next").Return(false).Once()
+
+	courseCur.On("Close").Return(nil).Once()
+	courseCur.On("Fields").Return([]string{"id", "title"}, nil).Once()
+	courseCur.On("Next").Return(true).Twice()
+	courseCur.MockScan(algebra.ID, algebra.Title).Twice()
+	courseCur.MockScan(biology.ID, biology.Title).Times(3)
+	courseCur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &students, "courses", NewSortAsc("title")))
+	assert.Equal(t, []Course{algebra, biology}, students[0].Courses)
+	assert.Equal(t, []Course{biology}, students[1].Courses)
+
+	adapter.AssertExpectations(t)
+	joinCur.AssertExpectations(t)
+	courseCur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_hasManyThrough(t *testing.T) {
+	var (
+		adapter    = &testAdapter{}
+		repo       = repository{adapter: adapter}
+		authors    = []Author{{ID: 1}, {ID: 2}}
+		articleIDs = []int{10, 20}
+		joinCur    = &testCursor{}
+		remarkCur  = &testCursor{}
+	)
+
+	adapter.On("Query", From("articles").Select("author_id", "id").Where(In("author_id", 1, 2), Nil("deleted_at"))).Return(joinCur, nil).Maybe()
+	adapter.On("Query", From("articles").Select("author_id", "id").Where(In("author_id", 2, 1), Nil("deleted_at"))).Return(joinCur, nil).Maybe()
+	adapter.On("Query", From("remarks").Where(In("article_id", 10, 20))).Return(remarkCur, nil).Once()
+
+	joinCur.On("Close").Return(nil).Once()
+	joinCur.On("Fields").Return([]string{"author_id", "id"}, nil).Once()
+	joinCur.On("Next").Return(true).Twice()
+	joinCur.MockScan(1, 10).Once()
+	joinCur.MockScan(2, 20).Once()
+	joinCur.On("Next").Return(false).Once()
+
+	remarkCur.On("Close").Return(nil).Once()
+	remarkCur.On("Fields").Return([]string{"id", "article_id", "body"}, nil).Once()
+	remarkCur.On("Next").Return(true).Twice()
+	remarkCur.MockScan(1, 10, "first").Twice()
+	remarkCur.MockScan(2, 10, "second").Twice()
+	remarkCur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &authors, "remarks"))
+	assert.Equal(t, []Remark{
+		{ID: 1, ArticleID: &articleIDs[0], Body: "first"},
+		{ID: 2, ArticleID: &articleIDs[0], Body: "second"},
+	}, authors[0].Remarks)
+	assert.Equal(t, []Remark{}, authors[1].Remarks)
+
+	adapter.AssertExpectations(t)
+	joinCur.AssertExpectations(t)
+	remarkCur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_manyToManyInverse(t *testing.T) {
+	var (
+		adapter    = &testAdapter{}
+		repo       = repository{adapter: adapter}
+		course     = Course{ID: 10}
+		joinCur    = &testCursor{}
+		studentCur = &testCursor{}
+	)
+
+	adapter.On("Query", From("enrollments").Select("course_id", "student_id").Where(In("course_id", 10))).Return(joinCur, nil).Once()
+	adapter.On("Query", From("students").Where(In("id", 1)).Select("name", "id")).Return(studentCur, nil).Once()
+
+	joinCur.On("Close").Return(nil).Once()
+	joinCur.On("Fields").Return([]string{"course_id", "student_id"}, nil).Once()
+	joinCur.On("Next").Return(true).Once()
+	joinCur.MockScan(10, 1).Once()
+	joinCur.On("Next").Return(false).Once()
+
+	studentCur.On("Close").Return(nil).Once()
+	studentCur.On("Fields").Return([]string{"name", "id"}, nil).Once()
+	studentCur.On("Next").Return(true).Once()
+	studentCur.MockScan("rel", 1).Twice()
+	studentCur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &course, "students", Select("name")))
+	assert.Equal(t, []Student{{ID: 1, Name: "rel"}}, course.Students)
+
+	adapter.AssertExpectations(t)
+	joinCur.AssertExpectations(t)
+	studentCur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_manyToManyEmpty(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		course  = Course{ID: 10}
+		joinCur = &testCursor{}
+	)
+
+	adapter.On("Query", From("enrollments").Select("course_id", "student_id").Where(In("course_id", 10))).Return(joinCur, nil).Once()
+
+	joinCur.On("Close").Return(nil).Once()
+	joinCur.On("Fields").Return([]string{"course_id", "student_id"}, nil).Once()
+	joinCur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &course, "students"))
+	assert.Equal(t, []Student{}, course.Students)
+
+	adapter.AssertExpectations(t)
+	joinCur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_manyToManyError(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		course  = Course{ID: 10}
+		err     = errors.New("error")
+	)
+
+	adapter.On("Query", From("enrollments").Select("course_id", "student_id").Where(In("course_id", 10))).Return(&testCursor{}, err).Once()
+
+	assert.Equal(t, err, repo.Preload(context.TODO(), &course, "students"))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Preload_polymorphic(t *testing.T) {
+	var (
+		adapter  = &testAdapter{}
+		repo     = repository{adapter: adapter}
+		post     = Post{ID: 1, Title: "Hello"}
+		video    = Video{ID: 2, Title: "World"}
+		postCur  = &testCursor{}
+		videoCur = &testCursor{}
+		comments = []Comment{
+			{ID: 1, CommentableType: "posts", CommentableID: 1},
+			{ID: 2, CommentableType: "videos", CommentableID: 2},
+			{ID: 3, CommentableType: "posts", CommentableID: 1},
+			{ID: 4, CommentableType: "posts", CommentableID: 3, Commentable: &Post{ID: 3}},
+			{ID: 5},
+		}
+	)
+
+	Polymorphic(Post{}, Video{})
+
+	adapter.On("Query", From("posts").Where(In("id", 1, 3)).SortAsc("title")).Return(postCur, nil).Once()
+	adapter.On("Query", From("videos").Where(In("id", 2)).SortAsc("title")).Return(videoCur, nil).Once()
+
+	postCur.On("Close").Return(nil).Once()
+	postCur.On("Fields").Return([]string{"id", "title"}, nil).Once()
+	postCur.On("Next").Return(true).Once()
+	postCur.MockScan(post.ID, post.Title).Once()
+	postCur.On("Next").Return(false).Once()
+
+	videoCur.On("Close").Return(nil).Once()
+	videoCur.On("Fields").Return([]string{"id", "title"}, nil).Once()
+	videoCur.On("Next").Return(true).Once()
+	videoCur.MockScan(video.ID, video.Title).Once()
+	videoCur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &comments, "commentable", NewSortAsc("title")))
+	assert.Equal(t, &post, comments[0].Commentable)
+	assert.Equal(t, &video, comments[1].Commentable)
+	assert.Equal(t, &post, comments[2].Commentable)
+	assert.Nil(t, comments[3].Commentable)
+	assert.Nil(t, comments[4].Commentable)
+
+	adapter.AssertExpectations(t)
+	postCur.AssertExpectations(t)
+	videoCur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_polymorphicStruct(t *testing.T) {
+	var (
+		adapter  = &testAdapter{}
+		repo     = repository{adapter: adapter}
+		post     = Post{ID: 1, Title: "Hello"}
+		cur      = &testCursor{}
+		comments = []PostComment{
+			{ID: 1, CommentableType: "posts", CommentableID: 1},
+			{ID: 2, CommentableType: "videos", CommentableID: 2},
+		}
+	)
+
+	adapter.On("Query", From("posts").Where(In("id", 1)).Select("title", "id")).Return(cur, nil).Once()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"title", "id"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(post.Title, post.ID).Once()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &comments, "post", Select("title")))
+	assert.Equal(t, &post, comments[0].Post)
+	assert.Nil(t, comments[1].Post)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_polymorphicError(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		comment = Comment{ID: 1, CommentableType: "posts", CommentableID: 1}
+		err     = errors.New("error")
+	)
+
+	Polymorphic(Post{})
+
+	adapter.On("Query", From("posts").Where(In("id", 1))).Return(&testCursor{}, err).Once()
+
+	assert.Equal(t, err, repo.Preload(context.TODO(), &comment, "commentable"))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Preload_polymorphicUnregistered(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		comment = Comment{ID: 1, CommentableType: "photos", CommentableID: 1}
+	)
+
+	assert.Panics(t, func() {
+		repo.Preload(context.TODO(), &comment, "commentable")
+	})
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Preload_polymorphicNested(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		comment = Comment{ID: 1, CommentableType: "posts", CommentableID: 1, Commentable: &Post{ID: 1}}
+	)
+
+	assert.Panics(t, func() {
+		repo.Preload(context.TODO(), &comment, "commentable.comments")
+	})
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Preload_nestedNullHasMany(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		address = Address{User: nil}
+	)
+
+	assert.Nil(t, repo.Preload(context.TODO(), &address, "user.transactions"))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Preload_nestedSliceHasMany(t *testing.T) {
+	var (
+		adapter   = &testAdapter{}
+		repo      = repository{adapter: adapter}
+		addresses = []Address{
+			{User: &User{ID: 10}},
+			{User: &User{ID: 20}},
+		}
+		transactions = []Transaction{
+			{ID: 5, BuyerID: 10},
+			{ID: 10, BuyerID: 10},
+			{ID: 15, BuyerID: 20},
+			{ID: 20, BuyerID: 20},
+		}
+		cur = &testCursor{}
+	)
+
+	adapter.On("Query", From("transactions").Where(In("user_id", 10, 20))).Return(cur, nil).Maybe()
+	adapter.On("Query", From("transactions").Where(In("user_id", 20, 10))).Return(cur, nil).Maybe()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Next").Return(true).Times(4)
+	cur.MockScan(transactions[0].ID, transactions[0].BuyerID).Twice()
+	cur.MockScan(transactions[1].ID, transactions[1].BuyerID).Twice()
+	cur.MockScan(transactions[2].ID, transactions[2].BuyerID).Twice()
+	cur.MockScan(transactions[3].ID, transactions[3].BuyerID).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &addresses, "user.transactions"))
+	assert.Equal(t, transactions[:2], addresses[0].User.Transactions)
+	assert.Equal(t, transactions[2:], addresses[1].User.Transactions)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_nestedNullSliceHasMany(t *testing.T) {
+	var (
+		adapter   = &testAdapter{}
+		repo      = repository{adapter: adapter}
+		addresses = []Address{
+			{User: &User{ID: 10}},
+			{User: nil},
+			{User: &User{ID: 15}},
+		}
+		transactions = []Transaction{
+			{ID: 5, BuyerID: 10},
+			{ID: 10, BuyerID: 10},
+			{ID: 15, BuyerID: 15},
+		}
+		cur = &testCursor{}
+	)
+
+	adapter.On("Query", From("transactions").Where(In("user_id", 10, 15))).Return(cur, nil).Maybe()
+	adapter.On("Query", From("transactions").Where(In("user_id", 15, 10))).Return(cur, nil).Maybe()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Next").Return(true).Times(3)
+	cur.MockScan(transactions[0].ID, transactions[0].BuyerID).Twice()
+	cur.MockScan(transactions[1].ID, transactions[1].BuyerID).Twice()
+	cur.MockScan(transactions[2].ID, transactions[2].BuyerID).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &addresses, "user.transactions"))
+	assert.Equal(t, transactions[:2], addresses[0].User.Transactions)
+	assert.Equal(t, []Transaction(nil), addresses[1].User.Transactions)
+	assert.Equal(t, transactions[2:], addresses[2].User.Transactions)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_belongsTo(t *testing.T) {
+	var (
+		adapter     = &testAdapter{}
+		repo        = repository{adapter: adapter}
+		user        = User{ID: 10, Name: "Del Piero"}
+		transaction = Transaction{BuyerID: 10}
+		cur         = &testCursor{}
+	)
+
+	adapter.On("Query", From("users").Where(In("id", 10))).Return(cur, nil).Once()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "name"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(user.ID, user.Name).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &transaction, "buyer"))
+	assert.Equal(t, user, transaction.Buyer)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_ptrBelongsTo(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		user    = User{ID: 10, Name: "Del Piero"}
+		address = Address{UserID: &user.ID}
+		cur     = &testCursor{}
 	)
 
-	adapter.On("Update", q.Where(Eq("id", 1).AndEq("user_id", 1)), modifies[0]).Return(1, nil).Once()
-	adapter.On("InsertAll", q, []string{"item", "user_id"}, modifies[1:]).Return(nil).Return([]interface{}{2}, nil).Maybe()
-	adapter.On("InsertAll", q, []string{"user_id", "item"}, modifies[1:]).Return(nil).Return([]interface{}{2}, nil).Maybe()
+	adapter.On("Query", From("users").Where(In("id", 10))).Return(cur, nil).Once()
 
-	assert.Nil(t, repo.saveHasMany(context.TODO(), doc, &modification, false))
-	assert.Equal(t, User{
-		ID: 1,
-		Transactions: []Transaction{
-			{ID: 1, BuyerID: 1, Item: "item1 updated"},
-			{ID: 2, BuyerID: 1, Item: "new item"},
-		},
-	}, user)
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "name"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(user.ID, user.Name).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &address, "user"))
+	assert.Equal(t, user, *address.User)
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_saveHasMany_deleteWithInsert(t *testing.T) {
+func TestRepository_Preload_nullBelongsTo(t *testing.T) {
 	var (
 		adapter = &testAdapter{}
 		repo    = repository{adapter: adapter}
-		user    = User{
-			ID: 1,
-			Transactions: []Transaction{
-				{ID: 1, Item: "item1"},
-				{ID: 2, Item: "item2"},
-			},
+		address = Address{}
+	)
+
+	assert.Nil(t, repo.Preload(context.TODO(), &address, "user"))
+	assert.Nil(t, address.User)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Preload_sliceBelongsTo(t *testing.T) {
+	var (
+		adapter      = &testAdapter{}
+		repo         = repository{adapter: adapter}
+		transactions = []Transaction{
+			{BuyerID: 10},
+			{BuyerID: 20},
 		}
-		doc          = NewDocument(&user)
-		modification = Apply(doc,
-			Map{
-				"transactions": []Map{
-					{"item": "item3"},
-					{"item": "item4"},
-					{"item": "item5"},
-				},
-			},
-		)
-		modifies = []map[string]Modify{
-			{"user_id": Set("user_id", user.ID), "item": Set("item", "item3")},
-			{"user_id": Set("user_id", user.ID), "item": Set("item", "item4")},
-			{"user_id": Set("user_id", user.ID), "item": Set("item", "item5")},
+		users = []User{
+			{ID: 10, Name: "Del Piero"},
+			{ID: 20, Name: "Nedved"},
 		}
-		q = Build("transactions")
+		cur = &testCursor{}
 	)
 
-	adapter.On("Delete", q.Where(Eq("user_id", 1).AndIn("id", 1, 2))).Return(1, nil).Once()
-	adapter.On("InsertAll", q, []string{"item", "user_id"}, modifies).Return(nil).Return([]interface{}{3, 4, 5}, nil).Maybe()
-	adapter.On("InsertAll", q, []string{"user_id", "item"}, modifies).Return(nil).Return([]interface{}{3, 4, 5}, nil).Maybe()
+	adapter.On("Query", From("users").Where(In("id", 10, 20))).Return(cur, nil).Maybe()
+	adapter.On("Query", From("users").Where(In("id", 20, 10))).Return(cur, nil).Maybe()
 
-	assert.Nil(t, repo.saveHasMany(context.TODO(), doc, &modification, false))
-	assert.Equal(t, User{
-		ID: 1,
-		Transactions: []Transaction{
-			{ID: 3, BuyerID: 1, Item: "item3"},
-			{ID: 4, BuyerID: 1, Item: "item4"},
-			{ID: 5, BuyerID: 1, Item: "item5"},
-		},
-	}, user)
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "name"}, nil).Once()
+	cur.On("Next").Return(true).Twice()
+	cur.MockScan(users[0].ID, users[0].Name).Twice()
+	cur.MockScan(users[1].ID, users[1].Name).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &transactions, "buyer"))
+	assert.Equal(t, users[0], transactions[0].Buyer)
+	assert.Equal(t, users[1], transactions[1].Buyer)
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_saveHasMany_replace(t *testing.T) {
+func TestRepository_Preload_duplicateParents(t *testing.T) {
 	var (
-		adapter = &testAdapter{}
-		repo    = repository{adapter: adapter}
-		user    = User{
-			ID: 1,
-			Transactions: []Transaction{
-				{Item: "item3"},
-				{Item: "item4"},
-				{Item: "item5"},
-			},
+		adapter      = &testAdapter{}
+		repo         = repository{adapter: adapter}
+		users        = []User{{ID: 10}, {ID: 10}}
+		transactions = []Transaction{
+			{ID: 5, BuyerID: 10},
+			{ID: 10, BuyerID: 10},
 		}
-		doc          = NewDocument(&user)
-		modification = Apply(doc, NewStructset(doc, false))
-		modifies     = []map[string]Modify{
-			{"user_id": Set("user_id", user.ID), "status": Set("status", Status("")), "item": Set("item", "item3")},
-			{"user_id": Set("user_id", user.ID), "status": Set("status", Status("")), "item": Set("item", "item4")},
-			{"user_id": Set("user_id", user.ID), "status": Set("status", Status("")), "item": Set("item", "item5")},
+		cur = &testCursor{}
+	)
+
+	adapter.On("Query", From("transactions").Where(In("user_id", 10))).Return(cur, nil).Once()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Next").Return(true).Twice()
+	cur.MockScan(transactions[0].ID, transactions[0].BuyerID).Times(3)
+	cur.MockScan(transactions[1].ID, transactions[1].BuyerID).Times(3)
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &users, "transactions"))
+	assert.Equal(t, transactions, users[0].Transactions)
+	assert.Equal(t, transactions, users[1].Transactions)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_sameParent(t *testing.T) {
+	var (
+		adapter      = &testAdapter{}
+		repo         = repository{adapter: adapter}
+		user         = User{ID: 10}
+		addresses    = []Address{{ID: 1, UserID: &user.ID, User: &user}, {ID: 2, UserID: &user.ID, User: &user}}
+		transactions = []Transaction{
+			{ID: 5, BuyerID: 10},
+			{ID: 10, BuyerID: 10},
 		}
-		q = Build("transactions")
+		cur = &testCursor{}
 	)
 
-	adapter.On("Delete", q.Where(Eq("user_id", 1))).Return(1, nil).Once()
-	adapter.On("InsertAll", q, mock.Anything, modifies).Return(nil).Return([]interface{}{3, 4, 5}, nil).Once()
+	adapter.On("Query", From("transactions").Where(In("user_id", 10))).Return(cur, nil).Once()
 
-	assert.Nil(t, repo.saveHasMany(context.TODO(), doc, &modification, false))
-	assert.Equal(t, User{
-		ID:        1,
-		CreatedAt: now(),
-		UpdatedAt: now(),
-		Transactions: []Transaction{
-			{ID: 3, BuyerID: 1, Item: "item3"},
-			{ID: 4, BuyerID: 1, Item: "item4"},
-			{ID: 5, BuyerID: 1, Item: "item5"},
-		},
-	}, user)
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Next").Return(true).Twice()
+	cur.MockScan(transactions[0].ID, transactions[0].BuyerID).Twice()
+	cur.MockScan(transactions[1].ID, transactions[1].BuyerID).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &addresses, "user.transactions"))
+	assert.Equal(t, transactions, user.Transactions)
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_saveHasMany_replaceDeleteAllError(t *testing.T) {
+func TestRepository_Preload_zeroForeignKey(t *testing.T) {
 	var (
-		adapter = &testAdapter{}
-		repo    = repository{adapter: adapter}
-		user    = User{
-			ID: 1,
-			Transactions: []Transaction{
-				{ID: 1, Item: "item1"},
-				{ID: 2, Item: "item2"},
-			},
+		adapter      = &testAdapter{}
+		repo         = repository{adapter: adapter}
+		user         = User{ID: 10, Name: "Del Piero"}
+		transactions = []Transaction{
+			{BuyerID: 0},
+			{BuyerID: 10},
+			{BuyerID: 10},
 		}
-		doc          = NewDocument(&user)
-		modification = Apply(doc, NewStructset(doc, false))
-		q            = Build("transactions")
-		err          = errors.New("delete all error")
+		cur = &testCursor{}
 	)
 
-	adapter.On("Delete", q.Where(Eq("user_id", 1))).Return(0, err).Once()
+	adapter.On("Query", From("users").Where(In("id", 10))).Return(cur, nil).Once()
 
-	assert.Equal(t, err, repo.saveHasMany(context.TODO(), doc, &modification, false))
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "name"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(user.ID, user.Name).Times(3)
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &transactions, "buyer"))
+	assert.Equal(t, User{}, transactions[0].Buyer)
+	assert.Equal(t, user, transactions[1].Buyer)
+	assert.Equal(t, user, transactions[2].Buyer)
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_saveHasMany_invalidModifier(t *testing.T) {
+func TestRepository_Preload_allZeroForeignKey(t *testing.T) {
 	var (
 		adapter      = &testAdapter{}
 		repo         = repository{adapter: adapter}
-		user         = User{ID: 1}
-		doc          = NewDocument(&user)
-		modification = Apply(NewDocument(&User{}),
-			Map{
-				"transactions": []Map{
-					{"item": "item3"},
-				},
-			},
-		)
+		transactions = []Transaction{{BuyerID: 0}, {BuyerID: 0}}
 	)
 
-	assert.PanicsWithValue(t, "rel: invalid modifier", func() {
-		repo.saveHasMany(context.TODO(), doc, &modification, false)
-	})
+	assert.Nil(t, repo.Preload(context.TODO(), &transactions, "buyer"))
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Delete(t *testing.T) {
+func TestRepository_Preload_ptrSliceBelongsTo(t *testing.T) {
 	var (
 		adapter = &testAdapter{}
 		repo    = repository{adapter: adapter}
-		user    = User{ID: 1}
+		users   = []User{
+			{ID: 10, Name: "Del Piero"},
+			{ID: 20, Name: "Nedved"},
+		}
+		addresses = []Address{
+			{UserID: &users[0].ID},
+			{UserID: &users[1].ID},
+		}
+		cur = &testCursor{}
 	)
 
-	adapter.On("Delete", From("users").Where(Eq("id", user.ID))).Return(1, nil).Once()
+	adapter.On("Query", From("users").Where(In("id", 10, 20))).Return(cur, nil).Maybe()
+	adapter.On("Query", From("users").Where(In("id", 20, 10))).Return(cur, nil).Maybe()
 
-	assert.Nil(t, repo.Delete(context.TODO(), &user))
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "name"}, nil).Once()
+	cur.On("Next").Return(true).Twice()
+	cur.MockScan(users[0].ID, users[0].Name).Twice()
+	cur.MockScan(users[1].ID, users[1].Name).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &addresses, "user"))
+	assert.Equal(t, users[0], *addresses[0].User)
+	assert.Equal(t, users[1], *addresses[1].User)
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_Delete_softDelete(t *testing.T) {
+func TestRepository_Preload_emptySlice(t *testing.T) {
 	var (
-		adapter  = &testAdapter{}
-		repo     = repository{adapter: adapter}
-		address  = Address{ID: 1}
-		query    = From("addresses").Where(Eq("id", address.ID))
-		modifies = map[string]Modify{
-			"deleted_at": Set("deleted_at", now()),
-		}
+		repo      = repository{}
+		addresses = []Address{}
+	)
+
+	assert.Nil(t, repo.Preload(context.TODO(), &addresses, "user.transactions"))
+}
+
+func TestQuery_Preload_notPointerPanic(t *testing.T) {
+	var (
+		repo        = repository{}
+		transaction = Transaction{}
+	)
+
+	assert.Panics(t, func() { repo.Preload(context.TODO(), transaction, "User") })
+}
+
+func TestRepository_Preload_queryError(t *testing.T) {
+	var (
+		adapter     = &testAdapter{}
+		repo        = repository{adapter: adapter}
+		transaction = Transaction{BuyerID: 10}
+		cur         = &testCursor{}
+		err         = errors.New("error")
 	)
 
-	adapter.On("Update", query, modifies).Return(1, nil).Once()
+	adapter.On("Query", From("users").Where(In("id", 10))).Return(cur, err).Once()
 
-	assert.Nil(t, repo.Delete(context.TODO(), &address))
+	assert.Equal(t, err, repo.Preload(context.TODO(), &transaction, "buyer"))
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_MustDelete(t *testing.T) {
+func TestRepository_MustPreload(t *testing.T) {
 	var (
-		adapter = &testAdapter{}
-		repo    = repository{adapter: adapter}
-		user    = User{ID: 1}
+		adapter     = &testAdapter{}
+		repo        = repository{adapter: adapter}
+		transaction = Transaction{BuyerID: 10}
+		cur         = createCursor(0)
 	)
 
-	adapter.On("Delete", From("users").Where(Eq("id", user.ID))).Return(1, nil).Once()
+	adapter.On("Query", From("users").Where(In("id", 10))).Return(cur, nil).Once()
 
 	assert.NotPanics(t, func() {
-		repo.MustDelete(context.TODO(), &user)
+		repo.MustPreload(context.TODO(), &transaction, "buyer")
 	})
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_DeleteAll(t *testing.T) {
-	var (
-		adapter = &testAdapter{}
-		repo    = repository{adapter: adapter}
-		queries = From("logs").Where(Eq("user_id", 1))
-	)
+func TestRepository_Transaction(t *testing.T) {
+	adapter := &testAdapter{}
+	adapter.On("Begin").Return(nil).On("Commit").Return(nil).Once()
 
-	adapter.On("Delete", From("logs").Where(Eq("user_id", 1))).Return(1, nil).Once()
+	repo := repository{adapter: adapter}
 
-	assert.Nil(t, repo.DeleteAll(context.TODO(), queries))
+	err := repo.Transaction(context.TODO(), func(repo Repository) error {
+		assert.True(t, repo.(*repository).inTransaction)
+		return nil
+	})
+
+	assert.False(t, repo.inTransaction)
+	assert.Nil(t, err)
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_MustDeleteAll(t *testing.T) {
-	var (
-		adapter = &testAdapter{}
-		repo    = repository{adapter: adapter}
-		queries = From("logs").Where(Eq("user_id", 1))
-	)
+func TestRepository_Transaction_options(t *testing.T) {
+	adapter := &testAdapter{}
+	adapter.On("Begin").Return(nil).On("Commit").Return(nil).Once()
 
-	adapter.On("Delete", From("logs").Where(Eq("user_id", 1))).Return(1, nil).Once()
+	repo := repository{adapter: adapter}
 
-	assert.NotPanics(t, func() {
-		repo.MustDeleteAll(context.TODO(), queries)
-	})
+	err := repo.Transaction(context.TODO(), func(repo Repository) error {
+		return nil
+	}, Isolation(sql.LevelSerializable))
 
+	assert.Nil(t, err)
+	assert.Equal(t, TransactionOptions{Isolation: sql.LevelSerializable}, adapter.transactionOptions)
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Preload_hasOne(t *testing.T) {
+func TestRepository_Transaction_readOnly(t *testing.T) {
 	var (
+		user    = User{ID: 1}
+		users   = []User{{Name: "a"}}
 		adapter = &testAdapter{}
 		repo    = repository{adapter: adapter}
-		user    = User{ID: 10}
-		address = Address{ID: 100, UserID: &user.ID}
-		cur     = &testCursor{}
 	)
 
-	adapter.On("Query", From("addresses").Where(In("user_id", 10).AndNil("deleted_at"))).Return(cur, nil).Once()
+	adapter.On("Begin").Return(nil).Twice()
+	adapter.On("Rollback").Return(nil).Twice()
 
-	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
-	cur.On("Next").Return(true).Once()
-	cur.MockScan(address.ID, *address.UserID).Times(2)
-	cur.On("Next").Return(false).Once()
+	err := repo.Transaction(context.TODO(), func(repo Repository) error {
+		assert.Equal(t, TransactionOptions{ReadOnly: true}, adapter.transactionOptions)
+		assert.Equal(t, ErrReadOnlyTransaction, repo.Insert(context.TODO(), &user))
+		assert.Equal(t, ErrReadOnlyTransaction, repo.InsertAll(context.TODO(), &users))
+		assert.Equal(t, ErrReadOnlyTransaction, repo.Update(context.TODO(), &user))
+		assert.Equal(t, ErrReadOnlyTransaction, repo.Delete(context.TODO(), &user))
+		assert.Equal(t, ErrReadOnlyTransaction, repo.DeleteAll(context.TODO(), From("users")))
 
-	assert.Nil(t, repo.Preload(context.TODO(), &user, "address"))
-	assert.Equal(t, address, user.Address)
+		_, err := repo.UpdateAny(context.TODO(), From("users"), Set("name", "b"))
+		assert.Equal(t, ErrReadOnlyTransaction, err)
+
+		_, err = repo.DeleteAny(context.TODO(), From("users"))
+		assert.Equal(t, ErrReadOnlyTransaction, err)
+
+		return repo.Transaction(context.TODO(), func(repo Repository) error {
+			return repo.Insert(context.TODO(), &user)
+		})
+	}, ReadOnly())
 
+	assert.Equal(t, ErrReadOnlyTransaction, err)
 	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_sliceHasOne(t *testing.T) {
-	var (
-		adapter   = &testAdapter{}
-		repo      = repository{adapter: adapter}
-		users     = []User{{ID: 10}, {ID: 20}}
-		addresses = []Address{
-			{ID: 100, UserID: &users[0].ID},
-			{ID: 200, UserID: &users[1].ID},
-		}
-		cur = &testCursor{}
-	)
-
-	// one of these, because of map ordering
-	adapter.On("Query", From("addresses").Where(In("user_id", 10, 20).AndNil("deleted_at"))).Return(cur, nil).Maybe()
-	adapter.On("Query", From("addresses").Where(In("user_id", 20, 10).AndNil("deleted_at"))).Return(cur, nil).Maybe()
+func TestRepository_Transaction_timeout(t *testing.T) {
+	adapter := &testAdapter{}
+	adapter.On("Begin").Return(nil).On("Commit").Return(nil).Once()
 
-	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
-	cur.On("Next").Return(true).Twice()
-	cur.MockScan(addresses[0].ID, *addresses[0].UserID).Twice()
-	cur.MockScan(addresses[1].ID, *addresses[1].UserID).Twice()
-	cur.On("Next").Return(false).Once()
+	repo := repository{adapter: adapter}
 
-	assert.Nil(t, repo.Preload(context.TODO(), &users, "address"))
-	assert.Equal(t, addresses[0], users[0].Address)
-	assert.Equal(t, addresses[1], users[1].Address)
+	err := repo.Transaction(context.TODO(), func(repo Repository) error {
+		deadline, ok := adapter.transactionCtx.Deadline()
+		assert.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+		assert.Nil(t, adapter.transactionCtx.Err())
+		return nil
+	}, Timeout(time.Minute), StatementTimeout(time.Second))
 
+	assert.Nil(t, err)
+	assert.Equal(t, TransactionOptions{Timeout: time.Minute, StatementTimeout: time.Second}, adapter.transactionOptions)
+	assert.Equal(t, context.Canceled, adapter.transactionCtx.Err())
 	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_nestedHasOne(t *testing.T) {
+func TestRepository_Transaction_twoPhaseCommit(t *testing.T) {
 	var (
-		adapter     = &testAdapter{}
-		repo        = repository{adapter: adapter}
-		transaction = Transaction{
-			Buyer: User{ID: 10},
-		}
-		address = Address{ID: 100, UserID: &transaction.Buyer.ID}
-		cur     = &testCursor{}
+		prepared bool
+		adapter  = &testAdapter{}
+		repo     = repository{adapter: adapter}
 	)
 
-	adapter.On("Query", From("addresses").Where(In("user_id", 10).AndNil("deleted_at"))).Return(cur, nil).Once()
-
-	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
-	cur.On("Next").Return(true).Once()
-	cur.MockScan(address.ID, *address.UserID).Twice()
-	cur.On("Next").Return(false).Once()
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Prepare", "tx-1").Return(nil).Once()
+	adapter.On("CommitPrepared", "tx-1").Return(nil).Once()
 
-	assert.Nil(t, repo.Preload(context.TODO(), &transaction, "buyer.address"))
-	assert.Equal(t, address, transaction.Buyer.Address)
+	err := repo.Transaction(context.TODO(), func(repo Repository) error {
+		return nil
+	}, TwoPhaseCommit("tx-1", func(ctx context.Context) error {
+		prepared = true
+		return nil
+	}))
 
+	assert.Nil(t, err)
+	assert.True(t, prepared)
 	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_sliceNestedHasOne(t *testing.T) {
+func TestRepository_Transaction_twoPhaseCommitPreparedError(t *testing.T) {
 	var (
-		adapter      = &testAdapter{}
-		repo         = repository{adapter: adapter}
-		transactions = []Transaction{
-			{Buyer: User{ID: 10}},
-			{Buyer: User{ID: 20}},
-		}
-		addresses = []Address{
-			{ID: 100, UserID: &transactions[0].Buyer.ID},
-			{ID: 200, UserID: &transactions[1].Buyer.ID},
-		}
-		cur = &testCursor{}
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		err     = errors.New("publish failed")
 	)
 
-	// one of these, because of map ordering
-	adapter.On("Query", From("addresses").Where(In("user_id", 10, 20).AndNil("deleted_at"))).Return(cur, nil).Maybe()
-	adapter.On("Query", From("addresses").Where(In("user_id", 20, 10).AndNil("deleted_at"))).Return(cur, nil).Maybe()
-
-	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
-	cur.On("Next").Return(true).Twice()
-	cur.MockScan(addresses[0].ID, *addresses[0].UserID).Twice()
-	cur.MockScan(addresses[1].ID, *addresses[1].UserID).Twice()
-	cur.On("Next").Return(false).Once()
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Prepare", "tx-1").Return(nil).Once()
+	adapter.On("RollbackPrepared", "tx-1").Return(nil).Once()
 
-	assert.Nil(t, repo.Preload(context.TODO(), &transactions, "buyer.address"))
-	assert.Equal(t, addresses[0], transactions[0].Buyer.Address)
-	assert.Equal(t, addresses[1], transactions[1].Buyer.Address)
+	assert.Equal(t, err, repo.Transaction(context.TODO(), func(repo Repository) error {
+		return nil
+	}, TwoPhaseCommit("tx-1", func(ctx context.Context) error {
+		return err
+	})))
 
 	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_hasMany(t *testing.T) {
+func TestRepository_Transaction_twoPhaseCommitPrepareError(t *testing.T) {
 	var (
-		adapter      = &testAdapter{}
-		repo         = repository{adapter: adapter}
-		user         = User{ID: 10}
-		transactions = []Transaction{
-			{ID: 5, BuyerID: 10},
-			{ID: 10, BuyerID: 10},
-		}
-		cur = &testCursor{}
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
+		err     = errors.New("prepare failed")
 	)
 
-	adapter.On("Query", From("transactions").Where(In("user_id", 10))).Return(cur, nil).Once()
-
-	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
-	cur.On("Next").Return(true).Twice()
-	cur.MockScan(transactions[0].ID, transactions[0].BuyerID).Twice()
-	cur.MockScan(transactions[1].ID, transactions[1].BuyerID).Twice()
-	cur.On("Next").Return(false).Once()
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Prepare", "tx-1").Return(err).Once()
+	adapter.On("Rollback").Return(nil).Once()
 
-	assert.Nil(t, repo.Preload(context.TODO(), &user, "transactions"))
-	assert.Equal(t, transactions, user.Transactions)
+	assert.Equal(t, err, repo.Transaction(context.TODO(), func(repo Repository) error {
+		return nil
+	}, TwoPhaseCommit("tx-1", nil)))
 
 	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_sliceHasMany(t *testing.T) {
+func TestRepository_Transaction_twoPhaseCommitNested(t *testing.T) {
 	var (
-		adapter      = &testAdapter{}
-		repo         = repository{adapter: adapter}
-		users        = []User{{ID: 10}, {ID: 20}}
-		transactions = []Transaction{
-			{ID: 5, BuyerID: 10},
-			{ID: 10, BuyerID: 10},
-			{ID: 15, BuyerID: 20},
-			{ID: 20, BuyerID: 20},
-		}
-		cur = &testCursor{}
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
 	)
 
-	adapter.On("Query", From("transactions").Where(In("user_id", 10, 20))).Return(cur, nil).Maybe()
-	adapter.On("Query", From("transactions").Where(In("user_id", 20, 10))).Return(cur, nil).Maybe()
-
-	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
-	cur.On("Next").Return(true).Times(4)
-	cur.MockScan(transactions[0].ID, transactions[0].BuyerID).Twice()
-	cur.MockScan(transactions[1].ID, transactions[1].BuyerID).Twice()
-	cur.MockScan(transactions[2].ID, transactions[2].BuyerID).Twice()
-	cur.MockScan(transactions[3].ID, transactions[3].BuyerID).Twice()
-	cur.On("Next").Return(false).Once()
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
 
-	assert.Nil(t, repo.Preload(context.TODO(), &users, "transactions"))
-	assert.Equal(t, transactions[:2], users[0].Transactions)
-	assert.Equal(t, transactions[2:], users[1].Transactions)
+	err := repo.Transaction(context.TODO(), func(repo Repository) error {
+		return repo.Transaction(context.TODO(), func(repo Repository) error {
+			return nil
+		}, TwoPhaseCommit("tx-1", nil))
+	})
 
+	assert.Equal(t, errors.New("rel: two-phase commit can't be used inside transaction"), err)
 	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_nestedHasMany(t *testing.T) {
+func TestRepository_Transaction_twoPhaseCommitNotSupported(t *testing.T) {
 	var (
-		adapter      = &testAdapter{}
-		repo         = repository{adapter: adapter}
-		address      = Address{User: &User{ID: 10}}
-		transactions = []Transaction{
-			{ID: 5, BuyerID: 10},
-			{ID: 10, BuyerID: 10},
-		}
+		adapter = &testAdapter{unsupported: TwoPhaseCommitCapability}
+		repo    = repository{adapter: adapter}
+	)
 
-		cur = &testCursor{}
+	err := repo.Transaction(context.TODO(), func(repo Repository) error {
+		return nil
+	}, TwoPhaseCommit("tx-1", nil))
+
+	assert.Equal(t, NotSupportedError{Capability: TwoPhaseCommitCapability}, err)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Transaction_propagationRequired(t *testing.T) {
+	var (
+		root      = &testAdapter{}
+		txAdapter = &testAdapter{}
+		repo      = repository{adapter: txAdapter, inTransaction: true, root: root}
 	)
 
-	adapter.On("Query", From("transactions").Where(In("user_id", 10))).Return(cur, nil).Once()
+	err := repo.Transaction(context.TODO(), func(repo Repository) error {
+		assert.Equal(t, txAdapter, repo.Adapter())
+		return repo.Insert(context.TODO(), &User{})
+	}, Propagate(PropagationRequired), ReadOnly())
 
-	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
-	cur.On("Next").Return(true).Twice()
-	cur.MockScan(transactions[0].ID, transactions[0].BuyerID).Twice()
-	cur.MockScan(transactions[1].ID, transactions[1].BuyerID).Twice()
-	cur.On("Next").Return(false).Once()
+	assert.Equal(t, ErrReadOnlyTransaction, err)
+	root.AssertExpectations(t)
+	txAdapter.AssertExpectations(t)
+}
 
-	assert.Nil(t, repo.Preload(context.TODO(), &address, "user.transactions"))
-	assert.Equal(t, transactions, address.User.Transactions)
+func TestRepository_Transaction_propagationRequiredOutsideTransaction(t *testing.T) {
+	adapter := &testAdapter{}
+	adapter.On("Begin").Return(nil).On("Commit").Return(nil).Once()
 
+	err := repository{adapter: adapter}.Transaction(context.TODO(), func(repo Repository) error {
+		return nil
+	}, Propagate(PropagationRequired))
+
+	assert.Nil(t, err)
 	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_nestedNullHasMany(t *testing.T) {
+func TestRepository_Transaction_propagationRequiresNew(t *testing.T) {
 	var (
-		adapter = &testAdapter{}
-		repo    = repository{adapter: adapter}
-		address = Address{User: nil}
+		root      = &testAdapter{}
+		txAdapter = &testAdapter{}
+		repo      = repository{adapter: txAdapter, inTransaction: true, root: root}
 	)
 
-	assert.Nil(t, repo.Preload(context.TODO(), &address, "user.transactions"))
+	root.On("Begin").Return(nil).On("Commit").Return(nil).Once()
 
-	adapter.AssertExpectations(t)
+	err := repo.Transaction(context.TODO(), func(repo Repository) error {
+		assert.Equal(t, root, repo.Adapter())
+		return nil
+	}, Propagate(PropagationRequiresNew))
+
+	assert.Nil(t, err)
+	root.AssertExpectations(t)
+	txAdapter.AssertExpectations(t)
 }
 
-func TestRepository_Preload_nestedSliceHasMany(t *testing.T) {
+func TestRepository_Transaction_propagationNotSupported(t *testing.T) {
 	var (
-		adapter   = &testAdapter{}
-		repo      = repository{adapter: adapter}
-		addresses = []Address{
-			{User: &User{ID: 10}},
-			{User: &User{ID: 20}},
-		}
-		transactions = []Transaction{
-			{ID: 5, BuyerID: 10},
-			{ID: 10, BuyerID: 10},
-			{ID: 15, BuyerID: 20},
-			{ID: 20, BuyerID: 20},
-		}
-		cur = &testCursor{}
+		root      = &testAdapter{}
+		txAdapter = &testAdapter{}
+		repo      = repository{adapter: txAdapter, inTransaction: true, root: root}
 	)
 
-	adapter.On("Query", From("transactions").Where(In("user_id", 10, 20))).Return(cur, nil).Maybe()
-	adapter.On("Query", From("transactions").Where(In("user_id", 20, 10))).Return(cur, nil).Maybe()
+	err := repo.Transaction(context.TODO(), func(repo Repository) error {
+		assert.Equal(t, root, repo.Adapter())
+		return nil
+	}, Propagate(PropagationNotSupported))
 
-	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
-	cur.On("Next").Return(true).Times(4)
-	cur.MockScan(transactions[0].ID, transactions[0].BuyerID).Twice()
-	cur.MockScan(transactions[1].ID, transactions[1].BuyerID).Twice()
-	cur.MockScan(transactions[2].ID, transactions[2].BuyerID).Twice()
-	cur.MockScan(transactions[3].ID, transactions[3].BuyerID).Twice()
-	cur.On("Next").Return(false).Once()
+	assert.Nil(t, err)
+	root.AssertExpectations(t)
+	txAdapter.AssertExpectations(t)
 
-	assert.Nil(t, repo.Preload(context.TODO(), &addresses, "user.transactions"))
-	assert.Equal(t, transactions[:2], addresses[0].User.Transactions)
-	assert.Equal(t, transactions[2:], addresses[1].User.Transactions)
+	err = repository{adapter: root}.Transaction(context.TODO(), func(repo Repository) error {
+		assert.Equal(t, root, repo.Adapter())
+		return nil
+	}, Propagate(PropagationNotSupported))
 
-	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
+	assert.Nil(t, err)
+	root.AssertExpectations(t)
 }
 
-func TestRepository_Preload_nestedNullSliceHasMany(t *testing.T) {
+func TestRepository_Transaction_root(t *testing.T) {
 	var (
-		adapter   = &testAdapter{}
-		repo      = repository{adapter: adapter}
-		addresses = []Address{
-			{User: &User{ID: 10}},
-			{User: nil},
-			{User: &User{ID: 15}},
-		}
-		transactions = []Transaction{
-			{ID: 5, BuyerID: 10},
-			{ID: 10, BuyerID: 10},
-			{ID: 15, BuyerID: 15},
-		}
-		cur = &testCursor{}
+		adapter = &testAdapter{}
+		repo    = repository{adapter: adapter}
 	)
 
-	adapter.On("Query", From("transactions").Where(In("user_id", 10, 15))).Return(cur, nil).Maybe()
-	adapter.On("Query", From("transactions").Where(In("user_id", 15, 10))).Return(cur, nil).Maybe()
+	adapter.On("Begin").Return(nil).On("Commit").Return(nil).Twice()
 
-	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
-	cur.On("Next").Return(true).Times(3)
-	cur.MockScan(transactions[0].ID, transactions[0].BuyerID).Twice()
-	cur.MockScan(transactions[1].ID, transactions[1].BuyerID).Twice()
-	cur.MockScan(transactions[2].ID, transactions[2].BuyerID).Twice()
-	cur.On("Next").Return(false).Once()
+	err := repo.Transaction(context.TODO(), func(repo Repository) error {
+		assert.Equal(t, adapter, repo.(*repository).root)
 
-	assert.Nil(t, repo.Preload(context.TODO(), &addresses, "user.transactions"))
-	assert.Equal(t, transactions[:2], addresses[0].User.Transactions)
-	assert.Equal(t, []Transaction(nil), addresses[1].User.Transactions)
-	assert.Equal(t, transactions[2:], addresses[2].User.Transactions)
+		return repo.Transaction(context.TODO(), func(repo Repository) error {
+			assert.Equal(t, adapter, repo.(*repository).root)
+			return nil
+		})
+	})
 
+	assert.Nil(t, err)
 	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_belongsTo(t *testing.T) {
+func TestRepository_Transaction_warnSlow(t *testing.T) {
 	var (
-		adapter     = &testAdapter{}
-		repo        = repository{adapter: adapter}
-		user        = User{ID: 10, Name: "Del Piero"}
-		transaction = Transaction{BuyerID: 10}
-		cur         = &testCursor{}
+		logs    []string
+		adapter = &testAdapter{}
+		query   = From("users")
+		repo    = repository{adapter: adapter, logger: []Logger{func(statement string, duration time.Duration, err error) {
+			logs = append(logs, statement)
+		}}}
 	)
 
-	adapter.On("Query", From("users").Where(In("id", 10))).Return(cur, nil).Once()
+	adapter.On("Begin").Return(nil).Times(4)
+	adapter.On("Commit").Return(nil).Times(4)
+	adapter.On("Aggregate", query, "count", "*").Return(1, nil).Times(4)
 
-	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "name"}, nil).Once()
-	cur.On("Next").Return(true).Once()
-	cur.MockScan(user.ID, user.Name).Twice()
-	cur.On("Next").Return(false).Once()
+	fn := func(repo Repository) error {
+		repo.MustCount(context.TODO(), "users")
+		return repo.Transaction(context.TODO(), func(repo Repository) error {
+			repo.MustCount(context.TODO(), "users")
+			return nil
+		})
+	}
 
-	assert.Nil(t, repo.Preload(context.TODO(), &transaction, "buyer"))
-	assert.Equal(t, user, transaction.Buyer)
+	assert.Nil(t, repo.Transaction(context.TODO(), fn, WarnSlow(time.Hour, 1)))
+	assert.Equal(t, []string{"SLOW TRANSACTION: 2 statements"}, logs)
+
+	logs = nil
+	assert.Nil(t, repo.Transaction(context.TODO(), fn, WarnSlow(time.Hour, 2)))
+	assert.Nil(t, logs)
 
 	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_ptrBelongsTo(t *testing.T) {
+func TestRepository_Transaction_warnSlowDuration(t *testing.T) {
 	var (
+		logs    []string
 		adapter = &testAdapter{}
-		repo    = repository{adapter: adapter}
-		user    = User{ID: 10, Name: "Del Piero"}
-		address = Address{UserID: &user.ID}
-		cur     = &testCursor{}
+		repo    = repository{adapter: adapter, logger: []Logger{func(statement string, duration time.Duration, err error) {
+			logs = append(logs, statement)
+		}}}
 	)
 
-	adapter.On("Query", From("users").Where(In("id", 10))).Return(cur, nil).Once()
-
-	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "name"}, nil).Once()
-	cur.On("Next").Return(true).Once()
-	cur.MockScan(user.ID, user.Name).Twice()
-	cur.On("Next").Return(false).Once()
+	adapter.On("Begin").Return(nil).On("Rollback").Return(nil).Once()
 
-	assert.Nil(t, repo.Preload(context.TODO(), &address, "user"))
-	assert.Equal(t, user, *address.User)
+	err := repo.Transaction(context.TODO(), func(repo Repository) error {
+		time.Sleep(time.Millisecond)
+		return errors.New("error")
+	}, WarnSlow(time.Nanosecond, 0))
 
+	assert.Equal(t, errors.New("error"), err)
+	assert.Equal(t, []string{"SLOW TRANSACTION: 0 statements"}, logs)
 	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_nullBelongsTo(t *testing.T) {
+func TestRepository_Transaction_metadata(t *testing.T) {
 	var (
 		adapter = &testAdapter{}
 		repo    = repository{adapter: adapter}
-		address = Address{}
 	)
 
-	assert.Nil(t, repo.Preload(context.TODO(), &address, "user"))
-	assert.Nil(t, address.User)
+	adapter.On("Begin").Return(nil).On("Commit").Return(nil).Twice()
+
+	err := repo.Transaction(context.TODO(), func(repo Repository) error {
+		assert.Nil(t, repo.Get("actor"))
+		repo.Set("actor", 1)
+		assert.Equal(t, 1, repo.Get("actor"))
+		assert.Equal(t, 1, TransactionValue(adapter.transactionCtx, "actor"))
+
+		return repo.Transaction(context.TODO(), func(repo Repository) error {
+			assert.Equal(t, 1, repo.Get("actor"))
+			assert.Equal(t, 1, TransactionValue(adapter.transactionCtx, "actor"))
+			repo.Set("reason", "nested")
+			return nil
+		})
+	})
 
+	assert.Nil(t, err)
+	assert.Equal(t, "nested", TransactionValue(adapter.transactionCtx, "reason"))
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Preload_sliceBelongsTo(t *testing.T) {
-	var (
-		adapter      = &testAdapter{}
-		repo         = repository{adapter: adapter}
-		transactions = []Transaction{
-			{BuyerID: 10},
-			{BuyerID: 20},
-		}
-		users = []User{
-			{ID: 10, Name: "Del Piero"},
-			{ID: 20, Name: "Nedved"},
-		}
-		cur = &testCursor{}
-	)
-
-	adapter.On("Query", From("users").Where(In("id", 10, 20))).Return(cur, nil).Maybe()
-	adapter.On("Query", From("users").Where(In("id", 20, 10))).Return(cur, nil).Maybe()
-
-	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "name"}, nil).Once()
-	cur.On("Next").Return(true).Twice()
-	cur.MockScan(users[0].ID, users[0].Name).Twice()
-	cur.MockScan(users[1].ID, users[1].Name).Twice()
-	cur.On("Next").Return(false).Once()
+type contextAdapter struct {
+	*testAdapter
+	ctx context.Context
+}
 
-	assert.Nil(t, repo.Preload(context.TODO(), &transactions, "buyer"))
-	assert.Equal(t, users[0], transactions[0].Buyer)
-	assert.Equal(t, users[1], transactions[1].Buyer)
+func (ca *contextAdapter) Aggregate(ctx context.Context, query Query, aggregate string, field string, logger ...Logger) (int, error) {
+	ca.ctx = ctx
+	return ca.testAdapter.Aggregate(ctx, query, aggregate, field, logger...)
+}
 
-	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
+func (ca *contextAdapter) Begin(ctx context.Context) (Adapter, error) {
+	_, err := ca.testAdapter.Begin(ctx)
+	return ca, err
 }
 
-func TestRepository_Preload_ptrSliceBelongsTo(t *testing.T) {
+func TestRepository_Transaction_metadataContext(t *testing.T) {
 	var (
-		adapter = &testAdapter{}
+		adapter = &contextAdapter{testAdapter: &testAdapter{}}
 		repo    = repository{adapter: adapter}
-		users   = []User{
-			{ID: 10, Name: "Del Piero"},
-			{ID: 20, Name: "Nedved"},
-		}
-		addresses = []Address{
-			{UserID: &users[0].ID},
-			{UserID: &users[1].ID},
-		}
-		cur = &testCursor{}
 	)
 
-	adapter.On("Query", From("users").Where(In("id", 10, 20))).Return(cur, nil).Maybe()
-	adapter.On("Query", From("users").Where(In("id", 20, 10))).Return(cur, nil).Maybe()
-
-	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "name"}, nil).Once()
-	cur.On("Next").Return(true).Twice()
-	cur.MockScan(users[0].ID, users[0].Name).Twice()
-	cur.MockScan(users[1].ID, users[1].Name).Twice()
-	cur.On("Next").Return(false).Once()
+	adapter.On("Begin").Return(nil).On("Commit").Return(nil).Once()
+	adapter.On("Aggregate", From("users"), "count", "*").Return(1, nil).Once()
 
-	assert.Nil(t, repo.Preload(context.TODO(), &addresses, "user"))
-	assert.Equal(t, users[0], *addresses[0].User)
-	assert.Equal(t, users[1], *addresses[1].User)
+	err := repo.Transaction(context.TODO(), func(repo Repository) error {
+		repo.Set("actor", 1)
+		_, err := repo.Count(context.TODO(), "users")
+		return err
+	})
 
+	assert.Nil(t, err)
+	assert.Equal(t, 1, TransactionValue(adapter.ctx, "actor"))
 	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_emptySlice(t *testing.T) {
-	var (
-		repo      = repository{}
-		addresses = []Address{}
-	)
+func TestRepository_Set_outsideTransaction(t *testing.T) {
+	repo := repository{adapter: &testAdapter{}}
 
-	assert.Nil(t, repo.Preload(context.TODO(), &addresses, "user.transactions"))
+	assert.Nil(t, repo.Get("actor"))
+	assert.Nil(t, TransactionValue(context.TODO(), "actor"))
+	assert.Panics(t, func() {
+		repo.Set("actor", 1)
+	})
 }
 
-func TestQuery_Preload_notPointerPanic(t *testing.T) {
+func TestRepository_Transaction_retry(t *testing.T) {
 	var (
-		repo        = repository{}
-		transaction = Transaction{}
+		attempts int
+		adapter  = &testAdapter{}
+		repo     = repository{adapter: adapter}
 	)
 
-	assert.Panics(t, func() { repo.Preload(context.TODO(), transaction, "User") })
+	adapter.On("Begin").Return(nil).Times(3)
+	adapter.On("Rollback").Return(nil).Twice()
+	adapter.On("Commit").Return(nil).Once()
+
+	err := repo.Transaction(context.TODO(), func(repo Repository) error {
+		attempts++
+		if attempts < 3 {
+			return SerializationError{Err: errors.New("deadlock detected")}
+		}
+
+		return nil
+	}, Retry(3), RetryBackoff(time.Nanosecond, time.Nanosecond))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, attempts)
+	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Preload_queryError(t *testing.T) {
+func TestRepository_Transaction_retryExceeded(t *testing.T) {
 	var (
-		adapter     = &testAdapter{}
-		repo        = repository{adapter: adapter}
-		transaction = Transaction{BuyerID: 10}
-		cur         = &testCursor{}
-		err         = errors.New("error")
+		attempts int
+		adapter  = &testAdapter{}
+		repo     = repository{adapter: adapter}
+		retryErr = SerializationError{Err: errors.New("could not serialize access")}
 	)
 
-	adapter.On("Query", From("users").Where(In("id", 10))).Return(cur, err).Once()
+	adapter.On("Begin").Return(nil).Twice()
+	adapter.On("Rollback").Return(nil).Twice()
 
-	assert.Equal(t, err, repo.Preload(context.TODO(), &transaction, "buyer"))
+	err := repo.Transaction(context.TODO(), func(repo Repository) error {
+		attempts++
+		return retryErr
+	}, Retry(1), RetryBackoff(time.Nanosecond, time.Nanosecond))
 
+	assert.Equal(t, retryErr, err)
+	assert.Equal(t, 2, attempts)
 	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
 }
 
-func TestRepository_MustPreload(t *testing.T) {
+func TestRepository_Transaction_retryOtherError(t *testing.T) {
 	var (
-		adapter     = &testAdapter{}
-		repo        = repository{adapter: adapter}
-		transaction = Transaction{BuyerID: 10}
-		cur         = createCursor(0)
+		attempts int
+		adapter  = &testAdapter{}
+		repo     = repository{adapter: adapter}
 	)
 
-	adapter.On("Query", From("users").Where(In("id", 10))).Return(cur, nil).Once()
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
 
-	assert.NotPanics(t, func() {
-		repo.MustPreload(context.TODO(), &transaction, "buyer")
-	})
+	err := repo.Transaction(context.TODO(), func(repo Repository) error {
+		attempts++
+		return errors.New("error")
+	}, Retry(3))
 
+	assert.Equal(t, errors.New("error"), err)
+	assert.Equal(t, 1, attempts)
 	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
 }
 
-func TestRepository_Transaction(t *testing.T) {
-	adapter := &testAdapter{}
-	adapter.On("Begin").Return(nil).On("Commit").Return(nil).Once()
-
-	repo := repository{adapter: adapter}
+func TestRepository_Transaction_notSupported(t *testing.T) {
+	adapter := &testAdapter{unsupported: TransactionCapability}
 
-	err := repo.Transaction(context.TODO(), func(repo Repository) error {
-		assert.True(t, repo.(*repository).inTransaction)
+	err := repository{adapter: adapter}.Transaction(context.TODO(), func(r Repository) error {
 		return nil
 	})
 
-	assert.False(t, repo.inTransaction)
-	assert.Nil(t, err)
+	assert.Equal(t, NotSupportedError{Capability: TransactionCapability}, err)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Transaction_nestedNotSupported(t *testing.T) {
+	adapter := &testAdapter{unsupported: SavepointCapability}
+	adapter.On("Begin").Return(nil).On("Rollback").Return(nil).Once()
+
+	err := repository{adapter: adapter}.Transaction(context.TODO(), func(r Repository) error {
+		return r.Transaction(context.TODO(), func(r Repository) error {
+			return nil
+		})
+	})
 
+	assert.Equal(t, NotSupportedError{Capability: SavepointCapability}, err)
 	adapter.AssertExpectations(t)
 }
 
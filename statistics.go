@@ -0,0 +1,108 @@
+package rel
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// OperationStats holds statistics of an operation on a table.
+type OperationStats struct {
+	Table     string
+	Operation string
+	Count     int
+	Errors    int
+	TotalTime time.Duration
+	MaxTime   time.Duration
+}
+
+type operationKey struct {
+	table     string
+	operation string
+}
+
+// Statistics collects in-memory statistics of repository operations grouped by table and operation.
+// It's attached to repository as instrumenter, and intended for debug endpoint and load test.
+//
+// Example:
+//	stats := rel.NewStatistics()
+//	repo.Instrumentation(stats.Instrument)
+//
+//	// after running the load test.
+//	for _, s := range stats.Snapshot() {
+//		fmt.Println(s.Table, s.Operation, s.Count, s.TotalTime/time.Duration(s.Count), s.MaxTime)
+//	}
+type Statistics struct {
+	mutex      sync.Mutex
+	operations map[operationKey]*OperationStats
+}
+
+// NewStatistics returns empty statistics.
+func NewStatistics() *Statistics {
+	return &Statistics{
+		operations: make(map[operationKey]*OperationStats),
+	}
+}
+
+// Instrument is Instrumenter that records the operation, message is used as the table name.
+func (s *Statistics) Instrument(ctx context.Context, op string, message string) func(err error) {
+	start := time.Now()
+	return func(err error) {
+		s.record(message, op, time.Since(start), err)
+	}
+}
+
+func (s *Statistics) record(table string, operation string, duration time.Duration, err error) {
+	var (
+		key = operationKey{table: table, operation: operation}
+	)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stats, ok := s.operations[key]
+	if !ok {
+		stats = &OperationStats{Table: table, Operation: operation}
+		s.operations[key] = stats
+	}
+
+	stats.Count++
+	stats.TotalTime += duration
+	if duration > stats.MaxTime {
+		stats.MaxTime = duration
+	}
+
+	if err != nil {
+		stats.Errors++
+	}
+}
+
+// Snapshot returns copy of the current statistics ordered by table and operation.
+func (s *Statistics) Snapshot() []OperationStats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	result := make([]OperationStats, 0, len(s.operations))
+	for _, stats := range s.operations {
+		result = append(result, *stats)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Table != result[j].Table {
+			return result[i].Table < result[j].Table
+		}
+
+		return result[i].Operation < result[j].Operation
+	})
+
+	return result
+}
+
+// Reset clears the statistics.
+func (s *Statistics) Reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.operations = make(map[operationKey]*OperationStats)
+}
@@ -0,0 +1,66 @@
+package rel
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatistics(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		stats   = NewStatistics()
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		err     = errors.New("error")
+	)
+
+	repo.Instrumentation(stats.Instrument)
+
+	adapter.On("Aggregate", From("users"), "count", "*").Return(1, nil).Once()
+	adapter.On("Aggregate", From("users"), "count", "*").Return(0, err).Once()
+	adapter.On("Delete", From("users").Where(Eq("id", 10))).Return(1, nil).Once()
+	adapter.On("Aggregate", From("addresses"), "count", "*").Return(1, nil).Once()
+
+	_, _ = repo.Count(ctx, "users")
+	_, _ = repo.Count(ctx, "users")
+	_ = repo.Delete(ctx, &User{ID: 10})
+	_, _ = repo.Count(ctx, "addresses")
+
+	snapshot := stats.Snapshot()
+	assert.Len(t, snapshot, 3)
+
+	for i, expected := range []OperationStats{
+		{Table: "addresses", Operation: "aggregate", Count: 1},
+		{Table: "users", Operation: "aggregate", Count: 2, Errors: 1},
+		{Table: "users", Operation: "delete", Count: 1},
+	} {
+		assert.Equal(t, expected.Table, snapshot[i].Table)
+		assert.Equal(t, expected.Operation, snapshot[i].Operation)
+		assert.Equal(t, expected.Count, snapshot[i].Count)
+		assert.Equal(t, expected.Errors, snapshot[i].Errors)
+		assert.True(t, snapshot[i].MaxTime <= snapshot[i].TotalTime)
+	}
+
+	stats.Reset()
+	assert.Empty(t, stats.Snapshot())
+
+	adapter.AssertExpectations(t)
+}
+
+func TestStatistics_record(t *testing.T) {
+	var (
+		stats = NewStatistics()
+	)
+
+	stats.record("users", "query", time.Second, nil)
+	stats.record("users", "query", 3*time.Second, nil)
+	stats.record("users", "query", 2*time.Second, errors.New("error"))
+
+	assert.Equal(t, []OperationStats{
+		{Table: "users", Operation: "query", Count: 3, Errors: 1, TotalTime: 6 * time.Second, MaxTime: 3 * time.Second},
+	}, stats.Snapshot())
+}
@@ -102,7 +102,6 @@ func (s Structset) buildAssocMany(field string, mod *Modification) {
 	if !assoc.IsZero() {
 		var (
 			col, _ = assoc.Collection()
-			pField = col.PrimaryField()
 			mods   = make([]Modification, col.Len())
 		)
 
@@ -112,7 +111,6 @@ func (s Structset) buildAssocMany(field string, mod *Modification) {
 			)
 
 			mods[i] = Apply(doc, newStructset(doc, s.skipZero))
-			doc.SetValue(pField, nil) // reset id, since it'll be reinserted.
 		}
 
 		mod.SetAssoc(field, mods...)
@@ -0,0 +1,215 @@
+package rel
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+)
+
+var (
+	// DefaultRetryBackoff is the initial backoff used when retrying transaction.
+	DefaultRetryBackoff = 10 * time.Millisecond
+	// DefaultMaxRetryBackoff is the maximum backoff used when retrying transaction.
+	DefaultMaxRetryBackoff = time.Second
+)
+
+// Propagation defines how transaction behaves when it's started inside another transaction.
+type Propagation int
+
+const (
+	// PropagationNested starts nested transaction using savepoint, this is the default behaviour.
+	PropagationNested Propagation = iota
+	// PropagationRequired joins the ongoing transaction if any, otherwise starts a new transaction.
+	PropagationRequired
+	// PropagationRequiresNew always starts a new transaction that is independent from the ongoing transaction.
+	PropagationRequiresNew
+	// PropagationNotSupported executes the function outside of any transaction.
+	PropagationNotSupported
+)
+
+// TransactionOptions holds options of a transaction.
+type TransactionOptions struct {
+	Isolation sql.IsolationLevel
+	ReadOnly  bool
+
+	Propagation Propagation
+
+	Timeout          time.Duration
+	StatementTimeout time.Duration
+
+	SlowThreshold time.Duration
+	MaxStatements int
+
+	TwoPhaseCommitID string
+	Prepared         func(ctx context.Context) error
+
+	MaxRetries      int
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+}
+
+// Backoff returns exponential backoff duration of the given retry attempt, capped at MaxRetryBackoff.
+func (to TransactionOptions) Backoff(attempt int) time.Duration {
+	var (
+		base    = to.RetryBackoff
+		max     = to.MaxRetryBackoff
+		backoff time.Duration
+	)
+
+	if base <= 0 {
+		base = DefaultRetryBackoff
+	}
+
+	if max <= 0 {
+		max = DefaultMaxRetryBackoff
+	}
+
+	if attempt < 32 {
+		backoff = base << uint(attempt)
+	}
+
+	if backoff <= 0 || backoff > max {
+		backoff = max
+	}
+
+	return backoff
+}
+
+// TransactionOption configures transaction started by Transaction.
+type TransactionOption func(*TransactionOptions)
+
+// Isolation level of the transaction.
+func Isolation(level sql.IsolationLevel) TransactionOption {
+	return func(options *TransactionOptions) {
+		options.Isolation = level
+	}
+}
+
+// ReadOnly starts a read only transaction.
+// Any modification using repository inside the transaction will return ErrReadOnlyTransaction.
+func ReadOnly() TransactionOption {
+	return func(options *TransactionOptions) {
+		options.ReadOnly = true
+	}
+}
+
+// Propagate sets propagation behaviour of the transaction.
+func Propagate(propagation Propagation) TransactionOption {
+	return func(options *TransactionOptions) {
+		options.Propagation = propagation
+	}
+}
+
+// Timeout sets deadline of the transaction.
+// For sql adapters, the transaction will be rolled back once the deadline is exceeded.
+func Timeout(timeout time.Duration) TransactionOption {
+	return func(options *TransactionOptions) {
+		options.Timeout = timeout
+	}
+}
+
+// StatementTimeout limits execution time of every statement inside the transaction.
+// This option is ignored when not supported by adapter.
+func StatementTimeout(timeout time.Duration) TransactionOption {
+	return func(options *TransactionOptions) {
+		options.StatementTimeout = timeout
+	}
+}
+
+// WarnSlow logs a warning using repository's logger when transaction takes longer than threshold,
+// or executes more than maxStatements statements. Zero value disables the respective check.
+func WarnSlow(threshold time.Duration, maxStatements int) TransactionOption {
+	return func(options *TransactionOptions) {
+		options.SlowThreshold = threshold
+		options.MaxStatements = maxStatements
+	}
+}
+
+// TwoPhaseCommit prepares the transaction using the given id once transaction function succeed,
+// then calls prepared function before committing the prepared transaction.
+// This allows the commit to be coordinated with external resources such as message broker or other database,
+// the prepared transaction is rolled back when prepared function returns an error.
+func TwoPhaseCommit(id string, prepared func(ctx context.Context) error) TransactionOption {
+	return func(options *TransactionOptions) {
+		options.TwoPhaseCommitID = id
+		options.Prepared = prepared
+	}
+}
+
+// Retry re-runs the whole transaction up to maxRetries times when it fails with SerializationError,
+// which is returned by adapter on serialization failure or deadlock.
+func Retry(maxRetries int) TransactionOption {
+	return func(options *TransactionOptions) {
+		options.MaxRetries = maxRetries
+	}
+}
+
+// RetryBackoff sets the initial and maximum backoff between transaction retries.
+func RetryBackoff(base time.Duration, max time.Duration) TransactionOption {
+	return func(options *TransactionOptions) {
+		options.RetryBackoff = base
+		options.MaxRetryBackoff = max
+	}
+}
+
+type metadata struct {
+	mutex  sync.RWMutex
+	values map[string]interface{}
+}
+
+func (m *metadata) set(key string, value interface{}) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.values == nil {
+		m.values = make(map[string]interface{})
+	}
+
+	m.values[key] = value
+}
+
+func (m *metadata) get(key string) interface{} {
+	if m == nil {
+		return nil
+	}
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.values[key]
+}
+
+type metadataKey struct{}
+
+// TransactionValue returns value attached to the transaction using Repository.Set.
+// This function intended to be used by adapter, middleware and hooks that receive the context.
+func TransactionValue(ctx context.Context, key string) interface{} {
+	md, _ := ctx.Value(metadataKey{}).(*metadata)
+	return md.get(key)
+}
+
+type transactionOptionsKey struct{}
+
+// TransactionOptionsFrom returns options of the transaction being started.
+// This function intended to be used within adapter's Begin.
+func TransactionOptionsFrom(ctx context.Context) TransactionOptions {
+	options, _ := ctx.Value(transactionOptionsKey{}).(TransactionOptions)
+	return options
+}
+
+func applyTransactionOptions(opts []TransactionOption) TransactionOptions {
+	var (
+		options TransactionOptions
+	)
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return options
+}
+
+func withTransactionOptions(ctx context.Context, options TransactionOptions) context.Context {
+	return context.WithValue(ctx, transactionOptionsKey{}, options)
+}
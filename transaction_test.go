@@ -0,0 +1,39 @@
+package rel
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionOptionsFrom(t *testing.T) {
+	var (
+		ctx = context.TODO()
+	)
+
+	assert.Equal(t, TransactionOptions{}, TransactionOptionsFrom(ctx))
+	assert.Equal(t, TransactionOptions{}, TransactionOptionsFrom(withTransactionOptions(ctx, applyTransactionOptions(nil))))
+
+	ctx = withTransactionOptions(ctx, applyTransactionOptions([]TransactionOption{Isolation(sql.LevelReadCommitted), ReadOnly()}))
+	assert.Equal(t, TransactionOptions{Isolation: sql.LevelReadCommitted, ReadOnly: true}, TransactionOptionsFrom(ctx))
+}
+
+func TestTransactionOptions_Backoff(t *testing.T) {
+	var (
+		options = applyTransactionOptions([]TransactionOption{Retry(3)})
+	)
+
+	assert.Equal(t, 3, options.MaxRetries)
+	assert.Equal(t, DefaultRetryBackoff, options.Backoff(0))
+	assert.Equal(t, 4*DefaultRetryBackoff, options.Backoff(2))
+	assert.Equal(t, DefaultMaxRetryBackoff, options.Backoff(10))
+	assert.Equal(t, DefaultMaxRetryBackoff, options.Backoff(100))
+
+	options = applyTransactionOptions([]TransactionOption{RetryBackoff(time.Second, 3*time.Second)})
+	assert.Equal(t, time.Second, options.Backoff(0))
+	assert.Equal(t, 2*time.Second, options.Backoff(1))
+	assert.Equal(t, 3*time.Second, options.Backoff(2))
+}